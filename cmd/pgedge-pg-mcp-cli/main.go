@@ -37,6 +37,7 @@ func main() {
 	llmModel := flag.String("llm-model", "", "LLM model to use")
 	anthropicAPIKey := flag.String("anthropic-api-key", "", "API key for Anthropic")
 	openaiAPIKey := flag.String("openai-api-key", "", "API key for OpenAI")
+	openaiBaseURL := flag.String("openai-base-url", "", "OpenAI-compatible API endpoint (default: https://api.openai.com/v1)")
 	ollamaURL := flag.String("ollama-url", "", "Ollama server URL (default: http://localhost:11434)")
 	noColor := flag.Bool("no-color", false, "Disable colored output")
 
@@ -98,6 +99,9 @@ func main() {
 	if *openaiAPIKey != "" {
 		cfg.LLM.OpenAIAPIKey = *openaiAPIKey
 	}
+	if *openaiBaseURL != "" {
+		cfg.LLM.OpenAIBaseURL = *openaiBaseURL
+	}
 	if *ollamaURL != "" {
 		cfg.LLM.OllamaURL = *ollamaURL
 	}
@@ -0,0 +1,35 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"context"
+
+	"pgedge-postgres-mcp/internal/audit"
+	"pgedge-postgres-mcp/internal/config"
+)
+
+// openAuditLog opens the audit log destinations configured in cfg.Audit.
+// The audit table lives in audit.database, or the first database.
+func openAuditLog(ctx context.Context, cfg *config.Config) (*audit.Logger, error) {
+	opts := audit.Options{
+		File:  cfg.Audit.File,
+		Table: cfg.Audit.Table,
+	}
+	if opts.Table != "" {
+		db := &cfg.Databases[0]
+		if cfg.Audit.Database != "" {
+			db = cfg.GetDatabaseByName(cfg.Audit.Database)
+		}
+		opts.ConnString = db.BuildConnectionString()
+	}
+	return audit.Open(ctx, opts)
+}
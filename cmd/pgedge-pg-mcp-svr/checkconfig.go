@@ -0,0 +1,223 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/crypto"
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/definitions"
+)
+
+// configCheck is the outcome of one -check-config check
+type configCheck struct {
+	name    string
+	passed  bool
+	skipped bool
+	detail  string
+}
+
+// runConfigCheck loads and validates the configuration, checks that the
+// files it references exist and can be read, and connects to each configured
+// database, printing one line per check. It returns false if any check failed.
+func runConfigCheck(configPath string, cliFlags config.CLIFlags, execPath string, out io.Writer) bool {
+	var checks []configCheck
+
+	// Unlike a normal start, a file that exists but doesn't parse is an
+	// error rather than a silent fall back to the defaults
+	loadPath := ""
+	fileCheck := configCheck{name: "config file", skipped: true, detail: configPath + " not found, using defaults and environment"}
+	if config.ConfigFileExists(configPath) {
+		loadPath = configPath
+		cliFlags.ConfigFileSet = true
+		fileCheck = configCheck{name: "config file", passed: true, detail: configPath}
+	} else if cliFlags.ConfigFileSet {
+		fileCheck = configCheck{name: "config file", detail: configPath + " not found"}
+	}
+	checks = append(checks, fileCheck)
+
+	cfg, err := config.LoadConfig(loadPath, cliFlags)
+	if err != nil {
+		checks = append(checks, configCheck{name: "validate", detail: err.Error()})
+		return printConfigChecks(checks, out)
+	}
+	checks = append(checks, configCheck{name: "validate", passed: true, detail: "configuration is valid"})
+
+	if len(cfg.LLM.Examples) > 0 {
+		if _, err := llmExamples(&cfg.LLM); err != nil {
+			checks = append(checks, configCheck{name: "llm examples", detail: err.Error()})
+		} else {
+			checks = append(checks, configCheck{name: "llm examples", passed: true, detail: fmt.Sprintf("%d read-only examples", len(cfg.LLM.Examples))})
+		}
+	}
+
+	checks = append(checks, checkConfigFiles(cfg, execPath)...)
+
+	if len(cfg.Databases) == 0 {
+		checks = append(checks, configCheck{name: "database", detail: "no databases configured"})
+	}
+	for i := range cfg.Databases {
+		checks = append(checks, checkDatabaseConnection(&cfg.Databases[i]))
+	}
+
+	return printConfigChecks(checks, out)
+}
+
+// checkConfigFiles checks the TLS, token, user, secret and custom definition
+// files the configuration refers to, loading those the server would load
+func checkConfigFiles(cfg *config.Config, execPath string) []configCheck {
+	var checks []configCheck
+
+	if cfg.HTTP.Enabled && cfg.HTTP.TLS.Enabled {
+		checks = append(checks, checkReadableFile("tls cert", cfg.HTTP.TLS.CertFile))
+		checks = append(checks, checkReadableFile("tls key", cfg.HTTP.TLS.KeyFile))
+		if cfg.HTTP.TLS.ChainFile != "" {
+			checks = append(checks, checkReadableFile("tls chain", cfg.HTTP.TLS.ChainFile))
+		}
+	}
+
+	if cfg.HTTP.Enabled && cfg.HTTP.Auth.Enabled {
+		tokenFile := cfg.HTTP.Auth.TokenFile
+		if tokenFile == "" {
+			tokenFile = auth.GetDefaultTokenPath(execPath)
+		}
+		check := configCheck{name: "token file", detail: tokenFile}
+		if store, err := auth.LoadTokenStore(tokenFile); err != nil {
+			check.detail = err.Error()
+		} else {
+			check.passed = true
+			check.detail = fmt.Sprintf("%s (%d tokens)", tokenFile, len(store.Tokens))
+		}
+		checks = append(checks, check)
+
+		userFile := cfg.HTTP.Auth.UserFile
+		if userFile == "" {
+			userFile = auth.GetDefaultUserPath(execPath)
+		}
+		check = configCheck{name: "user file", detail: userFile}
+		if _, err := os.Stat(userFile); os.IsNotExist(err) {
+			check.skipped = true
+			check.detail = userFile + " not found, no users can log in"
+		} else if store, err := auth.LoadUserStore(userFile); err != nil {
+			check.detail = err.Error()
+		} else {
+			check.passed = true
+			check.detail = fmt.Sprintf("%s (%d users)", userFile, len(store.Users))
+		}
+		checks = append(checks, check)
+	}
+
+	secretFile := secretFilePath(cfg, execPath)
+	check := configCheck{name: "secret file", detail: secretFile}
+	if _, err := os.Stat(secretFile); os.IsNotExist(err) {
+		check.skipped = true
+		check.detail = secretFile + " not found, it is created when first needed"
+	} else if _, err := crypto.LoadKeyFromFile(secretFile); err != nil {
+		check.detail = err.Error()
+	} else {
+		check.passed = true
+	}
+	checks = append(checks, check)
+
+	if cfg.CustomDefinitionsPath != "" {
+		check := configCheck{name: "custom definitions", detail: cfg.CustomDefinitionsPath}
+		if _, err := definitions.LoadDefinitions(cfg.CustomDefinitionsPath); err != nil {
+			check.detail = err.Error()
+		} else {
+			check.passed = true
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// checkReadableFile checks that path names a regular file that can be opened
+func checkReadableFile(name, path string) configCheck {
+	check := configCheck{name: name, detail: path}
+	if path == "" {
+		check.detail = "not set"
+		return check
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		check.detail = err.Error()
+		return check
+	}
+	if info.IsDir() {
+		check.detail = path + " is a directory"
+		return check
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		check.detail = err.Error()
+		return check
+	}
+	//nolint:errcheck // Opened only to test readability
+	f.Close()
+
+	check.passed = true
+	return check
+}
+
+// checkDatabaseConnection connects to a database, which pings it
+func checkDatabaseConnection(dbCfg *config.NamedDatabaseConfig) configCheck {
+	check := configCheck{name: fmt.Sprintf("database[%s]", dbCfg.Name)}
+
+	client := database.NewClientWithConnectionString(dbCfg.BuildConnectionString(), dbCfg)
+	defer client.Close()
+
+	if err := client.Connect(); err != nil {
+		check.detail = err.Error()
+		return check
+	}
+	check.passed = true
+	check.detail = fmt.Sprintf("%s@%s:%d/%s", dbCfg.User, dbCfg.Host, dbCfg.Port, dbCfg.Database)
+	return check
+}
+
+// printConfigChecks prints one line per check and a summary, returning
+// whether all checks passed
+func printConfigChecks(checks []configCheck, out io.Writer) bool {
+	passed := true
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tRESULT\tDETAIL")
+	for _, c := range checks {
+		result := "PASS"
+		switch {
+		case c.skipped:
+			result = "SKIP"
+		case !c.passed:
+			result = "FAIL"
+			passed = false
+		}
+		// Errors can span several lines; keep each check on one
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.name, result, strings.Join(strings.Fields(c.detail), " "))
+	}
+	//nolint:errcheck // Output goes to the terminal; nothing to do on failure
+	tw.Flush()
+
+	if passed {
+		fmt.Fprintln(out, "\nConfiguration check passed")
+	} else {
+		fmt.Fprintln(out, "\nConfiguration check FAILED")
+	}
+	return passed
+}
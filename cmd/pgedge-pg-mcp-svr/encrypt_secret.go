@@ -0,0 +1,74 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	"pgedge-postgres-mcp/internal/config"
+
+	"golang.org/x/term"
+)
+
+// encryptSecretCommand handles the encrypt-secret command. It reads a
+// password or API key, prompting without echo when stdin is a terminal,
+// and prints it encrypted with the key in secretFile (created if missing)
+// in the enc: form the configuration file accepts.
+func encryptSecretCommand(secretFile string) error {
+	var secret string
+	if term.IsTerminal(int(syscall.Stdin)) {
+		fmt.Fprint(os.Stderr, "Enter secret: ")
+		secretBytes, err := term.ReadPassword(int(syscall.Stdin))
+		fmt.Fprintln(os.Stderr) // New line after secret input
+		if err != nil {
+			return fmt.Errorf("failed to read secret: %w", err)
+		}
+
+		fmt.Fprint(os.Stderr, "Confirm secret: ")
+		confirmBytes, err := term.ReadPassword(int(syscall.Stdin))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return fmt.Errorf("failed to read secret confirmation: %w", err)
+		}
+		if string(secretBytes) != string(confirmBytes) {
+			return fmt.Errorf("secrets do not match")
+		}
+		secret = string(secretBytes)
+	} else {
+		// Piped input, e.g. from a secrets manager; drop the final newline
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read secret: %w", err)
+		}
+		secret = strings.TrimRight(string(data), "\r\n")
+	}
+
+	if secret == "" {
+		return fmt.Errorf("secret is required")
+	}
+
+	key, err := loadEncryptionKey(secretFile, true)
+	if err != nil {
+		return err
+	}
+	encrypted, err := key.Encrypt(secret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	fmt.Println(config.EncryptedSecretPrefix + encrypted)
+	fmt.Fprintf(os.Stderr, "Encrypted with the key in %s; the server needs the same secret file to read it.\n", secretFile)
+	return nil
+}
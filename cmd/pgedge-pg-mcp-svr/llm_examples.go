@@ -0,0 +1,31 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"pgedge-postgres-mcp/internal/chat"
+	"pgedge-postgres-mcp/internal/config"
+)
+
+// llmExamples converts the configured few-shot examples for the LLM proxy,
+// rejecting any whose SQL is not a single read-only statement
+func llmExamples(llmCfg *config.LLMConfig) ([]chat.Example, error) {
+	examples := make([]chat.Example, len(llmCfg.Examples))
+	for i, ex := range llmCfg.Examples {
+		examples[i] = chat.Example{Question: ex.Question, SQL: ex.SQL}
+	}
+	if err := chat.ValidateExamples(examples); err != nil {
+		return nil, fmt.Errorf("llm.examples: %w", err)
+	}
+	return examples, nil
+}
@@ -0,0 +1,46 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"context"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// databaseReadiness returns the /ready check for the default database
+// client, which defaultClient returns on each check as a configuration
+// reload may replace it. With authentication enabled, connections are
+// opened per session on demand, so there is no shared connection to check
+// and the server is ready as soon as it is listening.
+func databaseReadiness(defaultClient func() *database.Client, perSession bool) func(ctx context.Context) mcp.ReadinessStatus {
+	return func(ctx context.Context) mcp.ReadinessStatus {
+		if perSession {
+			return mcp.ReadinessStatus{Ready: true, Connection: "per_session"}
+		}
+		client := defaultClient()
+		if client == nil || client.GetDefaultConnection() == "" {
+			return mcp.ReadinessStatus{Ready: true, Connection: "not_configured"}
+		}
+
+		status := mcp.ReadinessStatus{
+			Connection:     "unreachable",
+			MetadataLoaded: client.IsMetadataLoaded(),
+			Tables:         len(client.GetMetadata()),
+		}
+		if pool := client.GetPool(); pool != nil && pool.Ping(ctx) == nil {
+			status.Connection = "connected"
+		}
+		status.Ready = status.Connection == "connected" && status.MetadataLoaded
+		return status
+	}
+}
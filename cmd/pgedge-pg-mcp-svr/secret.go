@@ -0,0 +1,54 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/crypto"
+)
+
+// secretFilePath returns the configured secret file, or the default one
+func secretFilePath(cfg *config.Config, execPath string) string {
+	if cfg.SecretFile != "" {
+		return cfg.SecretFile
+	}
+	return config.GetDefaultSecretPath(execPath)
+}
+
+// loadEncryptionKey loads the encryption key from the secret file. If the
+// file does not exist, it is generated when create is set; otherwise nil is
+// returned with no error.
+func loadEncryptionKey(path string, create bool) (*crypto.EncryptionKey, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if !create {
+			return nil, nil
+		}
+		fmt.Fprintf(os.Stderr, "Generating new encryption key at %s\n", path)
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+		}
+		if err := key.SaveToFile(path); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "Encryption key saved successfully\n")
+		return key, nil
+	}
+
+	key, err := crypto.LoadKeyFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encryption key from %s: %w", path, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,192 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"pgedge-postgres-mcp/internal/chat"
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/embedding"
+	"pgedge-postgres-mcp/internal/llmproxy"
+)
+
+// smokeTestTimeout bounds each remote call made by the smoke test
+const smokeTestTimeout = 30 * time.Second
+
+// smokeStage is the outcome of one smoke test stage
+type smokeStage struct {
+	name    string
+	passed  bool
+	skipped bool
+	elapsed time.Duration
+	detail  string
+}
+
+// runSmokeTest checks every configured database (connect, load metadata,
+// run a trivial SELECT) and, when enabled, the LLM and embedding providers,
+// printing one line per stage. It returns false if any stage failed.
+func runSmokeTest(cfg *config.Config, out io.Writer) bool {
+	var stages []smokeStage
+
+	if len(cfg.Databases) == 0 {
+		stages = append(stages, smokeStage{name: "database", detail: "no databases configured"})
+	}
+	for i := range cfg.Databases {
+		stages = append(stages, smokeTestDatabase(&cfg.Databases[i])...)
+	}
+
+	if cfg.LLM.Enabled {
+		stages = append(stages, smokeTestLLM(&cfg.LLM))
+	} else {
+		stages = append(stages, smokeStage{name: "llm", skipped: true, detail: "llm.enabled is false"})
+	}
+
+	if cfg.Embedding.Enabled {
+		stages = append(stages, smokeTestEmbedding(&cfg.Embedding))
+	} else {
+		stages = append(stages, smokeStage{name: "embedding", skipped: true, detail: "embedding.enabled is false"})
+	}
+
+	passed := true
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tRESULT\tLATENCY\tDETAIL")
+	for _, s := range stages {
+		result, latency := "PASS", s.elapsed.Round(time.Millisecond).String()
+		switch {
+		case s.skipped:
+			result, latency = "SKIP", "-"
+		case !s.passed:
+			result = "FAIL"
+			passed = false
+		}
+		// Connection errors can span several lines; keep each stage on one
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.name, result, latency, strings.Join(strings.Fields(s.detail), " "))
+	}
+	//nolint:errcheck // Output goes to the terminal; nothing to do on failure
+	tw.Flush()
+
+	if passed {
+		fmt.Fprintln(out, "\nSmoke test passed")
+	} else {
+		fmt.Fprintln(out, "\nSmoke test FAILED")
+	}
+	return passed
+}
+
+// smokeTestDatabase connects to a database, loads its metadata and runs a
+// trivial SELECT, stopping at the first stage that fails
+func smokeTestDatabase(dbCfg *config.NamedDatabaseConfig) []smokeStage {
+	prefix := fmt.Sprintf("database[%s] ", dbCfg.Name)
+	client := database.NewClientWithConnectionString(dbCfg.BuildConnectionString(), dbCfg)
+	defer client.Close()
+
+	start := time.Now()
+	if err := client.Connect(); err != nil {
+		return []smokeStage{{name: prefix + "connect", elapsed: time.Since(start), detail: err.Error()}}
+	}
+	stages := []smokeStage{{name: prefix + "connect", passed: true, elapsed: time.Since(start),
+		detail: fmt.Sprintf("%s@%s:%d/%s", dbCfg.User, dbCfg.Host, dbCfg.Port, dbCfg.Database)}}
+
+	start = time.Now()
+	if err := client.LoadMetadata(); err != nil {
+		return append(stages, smokeStage{name: prefix + "metadata", elapsed: time.Since(start), detail: err.Error()})
+	}
+	stages = append(stages, smokeStage{name: prefix + "metadata", passed: true, elapsed: time.Since(start),
+		detail: fmt.Sprintf("%d tables/views", len(client.GetMetadata()))})
+
+	ctx, cancel := context.WithTimeout(context.Background(), smokeTestTimeout)
+	defer cancel()
+
+	start = time.Now()
+	var one int
+	if err := client.GetPool().QueryRow(ctx, "SELECT 1").Scan(&one); err != nil {
+		return append(stages, smokeStage{name: prefix + "select", elapsed: time.Since(start), detail: err.Error()})
+	}
+	return append(stages, smokeStage{name: prefix + "select", passed: true, elapsed: time.Since(start), detail: "SELECT 1"})
+}
+
+// smokeTestLLM sends a one-line prompt to the configured LLM
+func smokeTestLLM(llmCfg *config.LLMConfig) smokeStage {
+	stage := smokeStage{name: "llm"}
+
+	client, err := llmproxy.NewClient(&llmproxy.Config{
+		Provider:        llmCfg.Provider,
+		Model:           llmCfg.Model,
+		AnthropicAPIKey: llmCfg.AnthropicAPIKey,
+		OpenAIAPIKey:    llmCfg.OpenAIAPIKey,
+		OpenAIBaseURL:   llmCfg.OpenAIBaseURL,
+		OllamaURL:       llmCfg.OllamaURL,
+		MaxTokens:       16,
+		Temperature:     llmCfg.Temperature,
+	}, llmCfg.Provider, llmCfg.Model, false)
+	if err != nil {
+		stage.detail = err.Error()
+		return stage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), smokeTestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.Chat(ctx, []chat.Message{{Role: "user", Content: "Reply with the single word: ok"}}, []llmproxy.Tool{})
+	stage.elapsed = time.Since(start)
+	if err != nil {
+		stage.detail = err.Error()
+		return stage
+	}
+
+	stage.passed = true
+	stage.detail = fmt.Sprintf("%s/%s", llmCfg.Provider, llmCfg.Model)
+	return stage
+}
+
+// smokeTestEmbedding embeds a short text with the configured provider
+func smokeTestEmbedding(embCfg *config.EmbeddingConfig) smokeStage {
+	stage := smokeStage{name: "embedding"}
+
+	provider, err := embedding.NewProvider(embedding.Config{
+		Provider:     embCfg.Provider,
+		Model:        embCfg.Model,
+		VoyageAPIKey: embCfg.VoyageAPIKey,
+		OpenAIAPIKey: embCfg.OpenAIAPIKey,
+		OllamaURL:    embCfg.OllamaURL,
+	})
+	if err != nil {
+		stage.detail = err.Error()
+		return stage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), smokeTestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	vector, err := provider.Embed(ctx, "smoke test")
+	stage.elapsed = time.Since(start)
+	if err != nil {
+		stage.detail = err.Error()
+		return stage
+	}
+	if len(vector) == 0 {
+		stage.detail = "provider returned an empty vector"
+		return stage
+	}
+
+	stage.passed = true
+	stage.detail = fmt.Sprintf("%s/%s, %d dimensions", embCfg.Provider, embCfg.Model, len(vector))
+	return stage
+}
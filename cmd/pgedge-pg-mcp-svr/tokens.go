@@ -12,18 +12,25 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/logging"
 )
 
 // addTokenCommand handles the add-token command
 // database parameter specifies the database this token is bound to (empty = prompt or use first)
+// scopes limits what the token may do (empty = full access)
+// connStr, if set, is the token's own connection; its password is encrypted
+// with the key in secretFile
 // availableDatabases is the list of configured database names for interactive selection
-func addTokenCommand(tokenFile, annotation, database string, expiresIn time.Duration, availableDatabases []string) error {
+func addTokenCommand(tokenFile, annotation, database string, scopes []string, connStr, secretFile string, expiresIn time.Duration, availableDatabases []string) error {
 	// Load or create token store
 	var store *auth.TokenStore
 	var err error
@@ -38,6 +45,19 @@ func addTokenCommand(tokenFile, annotation, database string, expiresIn time.Dura
 		}
 	}
 
+	// Parse the token's own connection, encrypting its password
+	var connection *auth.TokenConnection
+	if connStr != "" {
+		key, err := loadEncryptionKey(secretFile, true)
+		if err != nil {
+			return err
+		}
+		connection, err = auth.ParseTokenConnection(connStr, key)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Generate token
 	token, err := auth.GenerateToken()
 	if err != nil {
@@ -124,9 +144,14 @@ func addTokenCommand(tokenFile, annotation, database string, expiresIn time.Dura
 	tokenID := fmt.Sprintf("token-%d", time.Now().Unix())
 
 	// Add token to store
-	if err := store.AddToken(tokenID, hash, annotation, expiresAt, database); err != nil {
+	if err := store.AddToken(tokenID, hash, annotation, expiresAt, database, scopes); err != nil {
 		return fmt.Errorf("failed to add token: %w", err)
 	}
+	if connection != nil {
+		if err := store.SetConnection(tokenID, connection); err != nil {
+			return fmt.Errorf("failed to add token: %w", err)
+		}
+	}
 
 	// Save token store
 	if err := auth.SaveTokenStore(tokenFile, store); err != nil {
@@ -148,6 +173,14 @@ func addTokenCommand(tokenFile, annotation, database string, expiresIn time.Dura
 	} else {
 		fmt.Println("Database: (first configured)")
 	}
+	if connection != nil {
+		fmt.Printf("Connection: %s\n", connection)
+	}
+	if len(scopes) > 0 {
+		fmt.Printf("Scopes: %s\n", strings.Join(scopes, ", "))
+	} else {
+		fmt.Println("Scopes: (full access)")
+	}
 	if expiresAt != nil {
 		fmt.Printf("Expires: %s\n", expiresAt.Format(time.RFC3339))
 	} else {
@@ -188,24 +221,133 @@ func removeTokenCommand(tokenFile, identifier string) error {
 	return nil
 }
 
-// listTokensCommand handles the list-tokens command
-func listTokensCommand(tokenFile string) error {
+// rotateTokenCommand handles the rotate-token command. The token keeps its
+// ID and settings; the old secret stays valid for grace.
+func rotateTokenCommand(tokenFile, identifier string, grace time.Duration) error {
+	store, err := auth.LoadTokenStore(tokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to load token file: %w", err)
+	}
+
+	token, err := auth.GenerateToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	tokenID, err := store.RotateToken(identifier, auth.HashToken(token), grace)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.SaveTokenStore(tokenFile, store); err != nil {
+		return fmt.Errorf("failed to save token file: %w", err)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Println("Token rotated successfully!")
+	fmt.Println(strings.Repeat("=", 70))
+	fmt.Printf("\nToken: %s\n", token)
+	fmt.Printf("ID:    %s\n", tokenID)
+	if grace > 0 {
+		fmt.Printf("Old secret valid until: %s\n", time.Now().Add(grace).Format(time.RFC3339))
+	} else {
+		fmt.Println("Old secret revoked immediately")
+	}
+	fmt.Println(strings.Repeat("=", 70))
+	fmt.Println("\nIMPORTANT: Save this token securely - it will not be shown again!")
+	fmt.Println(strings.Repeat("=", 70) + "\n")
+
+	return nil
+}
+
+// warnExpiringTokens logs a warning listing the tokens that expire within
+// window, so they can be rotated before clients are locked out
+func warnExpiringTokens(store *auth.TokenStore, window time.Duration) {
+	deadline := time.Now().Add(window)
+	var expiring []string
+	for _, token := range store.ListTokens() {
+		if !token.Expired && token.ExpiresAt != nil && token.ExpiresAt.Before(deadline) {
+			expiring = append(expiring, fmt.Sprintf("%s (%s)", token.ID, token.ExpiresAt.Format(time.RFC3339)))
+		}
+	}
+	if len(expiring) > 0 {
+		sort.Strings(expiring)
+		logging.Warn("tokens_expiring_soon", "count", len(expiring), "within", window.String(), "tokens", expiring)
+	}
+}
+
+// warnUnusedTokens logs a warning listing the tokens that haven't been used
+// (or, if never used, were created) more than days ago, so forgotten
+// credentials can be revoked
+func warnUnusedTokens(store *auth.TokenStore, days int) {
+	cutoff := time.Now().AddDate(0, 0, -days)
+	var unused []string
+	for _, token := range store.ListTokens() {
+		if token.Expired {
+			continue
+		}
+		switch {
+		case token.LastUsedAt == nil && token.CreatedAt.Before(cutoff):
+			unused = append(unused, fmt.Sprintf("%s (never used)", token.ID))
+		case token.LastUsedAt != nil && token.LastUsedAt.Before(cutoff):
+			unused = append(unused, fmt.Sprintf("%s (last used %s)", token.ID, token.LastUsedAt.Format(time.RFC3339)))
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		logging.Warn("tokens_unused", "count", len(unused), "days", days, "tokens", unused)
+	}
+}
+
+// tokenListEntry is one token in the -list-tokens -json output. Hashes are
+// shortened and connection passwords left out.
+type tokenListEntry struct {
+	ID         string     `json:"id"`
+	HashPrefix string     `json:"hash_prefix"`
+	Note       string     `json:"note"`
+	Database   string     `json:"database"`
+	Scopes     []string   `json:"scopes"`
+	Connection string     `json:"connection,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	Expired    bool       `json:"expired"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Calls      int64      `json:"calls"`
+	Denied     int64      `json:"denied"`
+}
+
+// listTokensCommand handles the list-tokens command, printing a table or,
+// with asJSON, a JSON array for scripts
+func listTokensCommand(tokenFile string, asJSON bool) error {
 	// Load token store
 	store, err := auth.LoadTokenStore(tokenFile)
 	if err != nil {
 		return fmt.Errorf("failed to load token file: %w", err)
 	}
 
+	// Last use and tool calls recorded by the server (absent until first use)
+	usageByID := map[string]*auth.TokenUsage{}
+	if usageStore, err := auth.LoadUsageStore(auth.UsageFilePath(tokenFile)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load token usage: %v\n", err)
+	} else {
+		store.SetUsageStore(usageStore)
+		usageByID = usageStore.UsageByTokenID(store)
+	}
+
 	tokens := store.ListTokens()
-	if len(tokens) == 0 {
+	if len(tokens) == 0 && !asJSON {
 		fmt.Println("No tokens found.")
 		return nil
 	}
 
+	if asJSON {
+		return writeTokensJSON(os.Stdout, tokens, usageByID)
+	}
+
 	fmt.Println("\nAPI Tokens:")
-	fmt.Println(strings.Repeat("=", 100))
-	fmt.Printf("%-20s %-14s %-15s %-18s %-10s %s\n", "ID", "Hash Prefix", "Database", "Expires", "Status", "Annotation")
-	fmt.Println(strings.Repeat("-", 100))
+	fmt.Println(strings.Repeat("=", 169))
+	fmt.Printf("%-20s %-14s %-15s %-12s %-18s %-10s %-17s %-8s %-7s %-22s %s\n", "ID", "Hash Prefix", "Database", "Scopes", "Expires", "Status", "Last Used", "Calls", "Denied", "Last Tool", "Annotation")
+	fmt.Println(strings.Repeat("-", 169))
 
 	for _, token := range tokens {
 		status := "Active"
@@ -218,6 +360,11 @@ func listTokensCommand(tokenFile string) error {
 			expiryStr = token.ExpiresAt.Format("2006-01-02 15:04")
 		}
 
+		lastUsed := "Never"
+		if token.LastUsedAt != nil {
+			lastUsed = token.LastUsedAt.Format("2006-01-02 15:04")
+		}
+
 		database := token.Database
 		if database == "" {
 			database = "(default)"
@@ -225,24 +372,79 @@ func listTokensCommand(tokenFile string) error {
 			database = database[:10] + "..."
 		}
 
+		scopes := "(all)"
+		if len(token.Scopes) > 0 {
+			scopes = strings.Join(token.Scopes, ",")
+		}
+
 		annotation := token.Annotation
 		if len(annotation) > 20 {
 			annotation = annotation[:17] + "..."
 		}
 
-		fmt.Printf("%-20s %-14s %-15s %-18s %-10s %s\n",
+		calls, denied, lastTool := "0", "0", "-"
+		if usage := usageByID[token.ID]; usage != nil {
+			calls = fmt.Sprintf("%d", usage.TotalCalls())
+			denied = fmt.Sprintf("%d", usage.Denied)
+			lastTool = usage.LastTool
+			if len(lastTool) > 21 {
+				lastTool = lastTool[:18] + "..."
+			}
+		}
+
+		fmt.Printf("%-20s %-14s %-15s %-12s %-18s %-10s %-17s %-8s %-7s %-22s %s\n",
 			token.ID,
 			token.HashPrefix,
 			database,
+			scopes,
 			expiryStr,
 			status,
+			lastUsed,
+			calls,
+			denied,
+			lastTool,
 			annotation)
 	}
-	fmt.Println(strings.Repeat("=", 100) + "\n")
+	fmt.Println(strings.Repeat("=", 169) + "\n")
 
 	return nil
 }
 
+// writeTokensJSON writes the tokens, sorted by ID, as an indented JSON array
+func writeTokensJSON(w io.Writer, tokens []*auth.TokenInfo, usageByID map[string]*auth.TokenUsage) error {
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+
+	entries := make([]tokenListEntry, 0, len(tokens))
+	for _, token := range tokens {
+		entry := tokenListEntry{
+			ID:         token.ID,
+			HashPrefix: token.HashPrefix,
+			Note:       token.Annotation,
+			Database:   token.Database,
+			Scopes:     token.Scopes,
+			CreatedAt:  token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+			Expired:    token.Expired,
+			LastUsedAt: token.LastUsedAt,
+		}
+		if entry.Scopes == nil {
+			entry.Scopes = []string{}
+		}
+		if token.Connection != nil {
+			entry.Connection = token.Connection.String()
+		}
+		if usage := usageByID[token.ID]; usage != nil {
+			entry.Calls = usage.TotalCalls()
+			entry.Denied = usage.Denied
+		}
+		entries = append(entries, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
 // parseDuration parses durations like "30d", "1y", "2w", "12h"
 func parseDuration(s string) (time.Duration, error) {
 	if len(s) < 2 {
@@ -273,3 +475,12 @@ func parseDuration(s string) (time.Duration, error) {
 		return 0, fmt.Errorf("invalid duration unit: %c (use h, d, w, m, or y)", unit)
 	}
 }
+
+// tokenPrefixes shortens token hashes for logging
+func tokenPrefixes(hashes []string) []string {
+	prefixes := make([]string, len(hashes))
+	for i, hash := range hashes {
+		prefixes[i] = logging.TokenPrefix(hash)
+	}
+	return prefixes
+}
@@ -0,0 +1,68 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// Build information, set with -ldflags "-X main.version=..." by release
+// builds (see .goreleaser-*.yaml)
+var (
+	version = ""
+	commit  = ""
+	date    = ""
+	builtBy = ""
+)
+
+// applyBuildVersion makes the version the binary was built as the one
+// reported to MCP clients
+func applyBuildVersion() {
+	if version != "" {
+		mcp.ServerVersion = version
+	}
+}
+
+// buildCommit returns the commit the binary was built from, falling back to
+// the VCS stamp Go embeds in builds from a git checkout
+func buildCommit() string {
+	if commit != "" {
+		return commit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}
+
+// printVersion prints the version, commit, build date and Go version
+func printVersion(out io.Writer) {
+	built := date
+	if built == "" {
+		built = "unknown"
+	}
+	fmt.Fprintf(out, "%s %s\n", mcp.ServerName, mcp.ServerVersion)
+	fmt.Fprintf(out, "  commit:     %s\n", buildCommit())
+	fmt.Fprintf(out, "  built:      %s\n", built)
+	if builtBy != "" {
+		fmt.Fprintf(out, "  built by:   %s\n", builtBy)
+	}
+	fmt.Fprintf(out, "  go version: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
@@ -0,0 +1,243 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package audit records every SQL statement the server runs (audit.enabled)
+// as JSON lines in a file and, optionally, as rows in a PostgreSQL table.
+// Records hold the statement text and its metadata, never result data.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/logging"
+)
+
+const (
+	// tableQueueSize bounds the records waiting to be inserted into the
+	// audit table; further records are kept in the file only
+	tableQueueSize = 1024
+
+	// tableInsertTimeout bounds each insert into the audit table
+	tableInsertTimeout = 5 * time.Second
+)
+
+// Record is the audit record of one SQL statement
+type Record struct {
+	Time       time.Time `json:"time"`                // When the statement started
+	Token      string    `json:"token,omitempty"`     // Prefix of the caller's token hash
+	Username   string    `json:"username,omitempty"`  // Session user, for user logins
+	ClientIP   string    `json:"client_ip,omitempty"` // Address of the HTTP client
+	Database   string    `json:"database"`
+	SQL        string    `json:"sql"`
+	DurationMs float64   `json:"duration_ms"`
+	Rows       int64     `json:"rows"`               // Rows returned or affected
+	Status     string    `json:"status"`             // ok or error
+	SQLState   string    `json:"sqlstate,omitempty"` // SQLSTATE of a failed statement
+}
+
+// Options configure where audit records are written
+type Options struct {
+	File       string // JSON lines file the records are appended to
+	Table      string // Table, as schema.table, the records are inserted into
+	ConnString string // Connection string of the database holding Table
+}
+
+// Logger writes audit records. It is a pgx.QueryTracer; install it with
+// database.SetQueryTracer before any connection pool is created.
+type Logger struct {
+	mu    sync.Mutex // Guards file and queue
+	file  *os.File
+	queue chan Record
+
+	pool   *pgxpool.Pool
+	insert string
+	done   chan struct{}
+}
+
+// Open opens the audit destinations in opts. The table must already exist
+// with the columns described in the documentation.
+func Open(ctx context.Context, opts Options) (*Logger, error) {
+	l := &Logger{}
+
+	if opts.File != "" {
+		file, err := os.OpenFile(opts.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit file: %w", err)
+		}
+		l.file = file
+	}
+
+	if opts.Table != "" {
+		if err := l.openTable(ctx, opts.Table, opts.ConnString); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// openTable connects to the database holding the audit table and checks
+// the table's columns. The pool has no tracer, so its inserts are not
+// audited themselves.
+func (l *Logger) openTable(ctx context.Context, table, connString string) error {
+	schema, name, ok := strings.Cut(table, ".")
+	if !ok {
+		return fmt.Errorf("audit table %q must be schema.table", table)
+	}
+	ident := pgx.Identifier{schema, name}.Sanitize()
+	columns := "logged_at, token, username, client_ip, database, statement, duration_ms, rows, status, sqlstate"
+
+	pool, err := pgxpool.New(ctx, connString)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the audit database: %w", err)
+	}
+	if _, err := pool.Exec(ctx, fmt.Sprintf("SELECT %s FROM %s LIMIT 0", columns, ident)); err != nil {
+		pool.Close()
+		return fmt.Errorf("audit table %s is not usable: %w", table, err)
+	}
+
+	l.pool = pool
+	l.insert = fmt.Sprintf("INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)", ident, columns)
+	l.queue = make(chan Record, tableQueueSize)
+	l.done = make(chan struct{})
+	go l.insertRecords()
+	return nil
+}
+
+// insertRecords inserts queued records into the audit table until the
+// queue is closed
+func (l *Logger) insertRecords() {
+	defer close(l.done)
+	for rec := range l.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), tableInsertTimeout)
+		_, err := l.pool.Exec(ctx, l.insert,
+			rec.Time, rec.Token, rec.Username, rec.ClientIP, rec.Database,
+			rec.SQL, rec.DurationMs, rec.Rows, rec.Status, rec.SQLState)
+		cancel()
+		if err != nil {
+			logging.Error("audit_insert_failed", "error", err)
+		}
+	}
+}
+
+// Write records rec in every audit destination. Records are inserted into
+// the table in the background; if the table falls too far behind, records
+// are dropped from it with a warning but still written to the file.
+func (l *Logger) Write(rec Record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		line, err := json.Marshal(rec)
+		if err == nil {
+			_, err = l.file.Write(append(line, '\n'))
+		}
+		if err != nil {
+			logging.Error("audit_write_failed", "error", err)
+		}
+	}
+
+	if l.queue != nil {
+		select {
+		case l.queue <- rec:
+		default:
+			logging.Warn("audit_record_dropped", "reason", "table queue full", "database", rec.Database)
+		}
+	}
+}
+
+// Close waits for queued records to be inserted and closes the audit
+// destinations. Statements traced after Close are not recorded.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	var err error
+	if l.file != nil {
+		err = l.file.Close()
+		l.file = nil
+	}
+	queued := l.queue != nil
+	if queued {
+		close(l.queue)
+		l.queue = nil
+	}
+	l.mu.Unlock()
+
+	if queued {
+		<-l.done
+	}
+	if l.pool != nil {
+		l.pool.Close()
+		l.pool = nil
+	}
+	return err
+}
+
+type traceKey struct{}
+
+type traceStart struct {
+	time time.Time
+	sql  string
+}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (l *Logger) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceStart{time: time.Now(), sql: data.SQL})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (l *Logger) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(traceKey{}).(traceStart)
+	if !ok {
+		return
+	}
+	l.Write(newRecord(ctx, conn.Config().Database, start, data))
+}
+
+// newRecord builds the record of a traced statement, taking the caller's
+// identity from ctx. The statement text is recorded in the form
+// query_logging.mode allows, and left empty when the mode is none.
+func newRecord(ctx context.Context, database string, start traceStart, data pgx.TraceQueryEndData) Record {
+	sql, _ := logging.LoggableQuery(start.sql)
+	rec := Record{
+		Time:       start.time.UTC(),
+		Username:   auth.GetUsernameFromContext(ctx),
+		ClientIP:   auth.GetIPAddressFromContext(ctx),
+		Database:   database,
+		SQL:        sql,
+		DurationMs: float64(time.Since(start.time).Microseconds()) / 1000,
+		Rows:       data.CommandTag.RowsAffected(),
+		Status:     "ok",
+	}
+	if tokenHash := auth.GetTokenHashFromContext(ctx); tokenHash != "" {
+		rec.Token = logging.TokenPrefix(tokenHash)
+	}
+	if data.Err != nil {
+		// Only the SQLSTATE: error messages can quote row values
+		rec.Status = "error"
+		var pgErr *pgconn.PgError
+		if errors.As(data.Err, &pgErr) {
+			rec.SQLState = pgErr.Code
+		}
+	}
+	return rec
+}
@@ -0,0 +1,123 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Audit Log Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/logging"
+)
+
+func TestNewRecord(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.TokenHashContextKey, "0123456789abcdef0123")
+	ctx = context.WithValue(ctx, auth.UsernameContextKey, "alice")
+	ctx = context.WithValue(ctx, auth.IPAddressContextKey, "10.0.0.5")
+	start := traceStart{time: time.Now().Add(-25 * time.Millisecond), sql: "SELECT * FROM orders WHERE id = $1"}
+
+	rec := newRecord(ctx, "shop", start, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 3")})
+	if rec.Token != "0123456789ab" || rec.Username != "alice" || rec.ClientIP != "10.0.0.5" {
+		t.Errorf("identity = %q/%q/%q", rec.Token, rec.Username, rec.ClientIP)
+	}
+	if rec.Database != "shop" || rec.SQL != start.sql || rec.Rows != 3 || rec.Status != "ok" {
+		t.Errorf("record = %+v", rec)
+	}
+	if rec.DurationMs < 25 {
+		t.Errorf("duration_ms = %v, want at least 25", rec.DurationMs)
+	}
+
+	pgErr := &pgconn.PgError{Code: "23505", Message: `duplicate key value violates unique constraint, Key (email)=(alice@example.com)`}
+	rec = newRecord(context.Background(), "shop", start, pgx.TraceQueryEndData{Err: fmt.Errorf("insert failed: %w", pgErr)})
+	if rec.Status != "error" || rec.SQLState != "23505" || rec.Token != "" {
+		t.Errorf("error record = %+v", rec)
+	}
+}
+
+func TestNewRecord_QueryLoggingMode(t *testing.T) {
+	defer logging.SetQueryLogMode("normalized")
+	start := traceStart{time: time.Now(), sql: "SELECT * FROM users WHERE email = 'alice@example.com'"}
+	end := pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")}
+
+	for mode, want := range map[string]string{
+		"full":       start.sql,
+		"normalized": "SELECT * FROM users WHERE email = $1",
+		"none":       "",
+	} {
+		logging.SetQueryLogMode(mode)
+		if rec := newRecord(context.Background(), "shop", start, end); rec.SQL != want {
+			t.Errorf("mode %s: sql = %q, want %q", mode, rec.SQL, want)
+		}
+	}
+}
+
+func TestLogger_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger, err := Open(context.Background(), Options{File: path})
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+
+	ctx := logger.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "DELETE FROM carts"})
+	start := ctx.Value(traceKey{}).(traceStart)
+	logger.Write(newRecord(ctx, "shop", start, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("DELETE 12")}))
+	logger.Write(Record{Database: "shop", SQL: "SELECT 1", Rows: 1, Status: "ok"})
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	// Records written after Close are ignored
+	logger.Write(Record{SQL: "SELECT 2"})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("audit file mode = %o, want 600", perm)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid audit line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0].SQL != "DELETE FROM carts" || records[0].Rows != 12 || records[0].Time.IsZero() {
+		t.Errorf("first record = %+v", records[0])
+	}
+}
+
+func TestOpen_BadTable(t *testing.T) {
+	if _, err := Open(context.Background(), Options{Table: "audit_log"}); err == nil {
+		t.Error("expected an error for a table without a schema")
+	}
+}
@@ -155,3 +155,30 @@ func (dac *DatabaseAccessChecker) GetAccessibleDatabases(ctx context.Context, da
 
 	return accessible
 }
+
+// HasScope reports whether the current request context may use the given
+// token scope. Only API tokens carry scopes: STDIO, --no-auth and session
+// users are unrestricted, as are tokens created without scopes. With auth
+// enabled, an API token that can't be found in the store, for example
+// because it was removed after the request was authenticated, has no
+// scopes.
+func (dac *DatabaseAccessChecker) HasScope(ctx context.Context, scope string) bool {
+	if dac.isSTDIO || !dac.authEnabled {
+		return true
+	}
+	if !IsAPITokenFromContext(ctx) {
+		return GetUsernameFromContext(ctx) != ""
+	}
+
+	tokenHash := GetTokenHashFromContext(ctx)
+	if tokenHash == "" || dac.tokenStore == nil {
+		return false
+	}
+
+	token := dac.tokenStore.GetTokenByHash(tokenHash)
+	if token == nil {
+		return false
+	}
+
+	return token.HasScope(scope)
+}
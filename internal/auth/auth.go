@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,6 +32,26 @@ type Token struct {
 	Annotation string     `yaml:"annotation"`         // User note/description
 	CreatedAt  time.Time  `yaml:"created_at"`         // When the token was created
 	Database   string     `yaml:"database,omitempty"` // Bound database name (empty = first configured database)
+	Scopes     []string   `yaml:"scopes,omitempty"`   // Granted scopes (empty = full access)
+
+	// Connection overrides the bound database's server, database and
+	// credentials for this token (nil = use the configured database)
+	Connection *TokenConnection `yaml:"connection,omitempty"`
+
+	// PreviousHash is the hash replaced by the last rotation. It is still
+	// accepted until PreviousExpiresAt so clients can switch over.
+	PreviousHash      string     `yaml:"previous_hash,omitempty"`
+	PreviousExpiresAt *time.Time `yaml:"previous_expires_at,omitempty"`
+}
+
+// matches reports whether hash is the token's current hash, or its previous
+// hash within the rotation grace window
+func (t *Token) matches(hash string, now time.Time) bool {
+	if t.Hash == hash {
+		return true
+	}
+	return t.PreviousHash != "" && t.PreviousHash == hash &&
+		t.PreviousExpiresAt != nil && now.Before(*t.PreviousExpiresAt)
 }
 
 // TokenStore manages API tokens
@@ -39,6 +60,7 @@ type TokenStore struct {
 	Tokens  map[string]*Token `yaml:"tokens"` // key is a unique identifier
 	path    string            // File path for auto-reloading
 	watcher *FileWatcher      // File watcher for auto-reloading
+	usage   *UsageStore       // Where token use is recorded (nil = not recorded)
 }
 
 // GenerateToken creates a new random API token
@@ -111,7 +133,9 @@ func (s *TokenStore) Reload() error {
 
 // SaveTokenStore saves tokens to a YAML file
 func SaveTokenStore(path string, store *TokenStore) error {
+	store.mu.Lock()
 	data, err := yaml.Marshal(store)
+	store.mu.Unlock()
 	if err != nil {
 		return fmt.Errorf("failed to marshal tokens: %w", err)
 	}
@@ -131,7 +155,7 @@ func SaveTokenStore(path string, store *TokenStore) error {
 }
 
 // AddToken adds a new token to the store
-func (s *TokenStore) AddToken(tokenID, hash, annotation string, expiresAt *time.Time, database string) error {
+func (s *TokenStore) AddToken(tokenID, hash, annotation string, expiresAt *time.Time, database string, scopes []string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -149,6 +173,7 @@ func (s *TokenStore) AddToken(tokenID, hash, annotation string, expiresAt *time.
 		Annotation: annotation,
 		CreatedAt:  time.Now(),
 		Database:   database,
+		Scopes:     scopes,
 	}
 
 	return nil
@@ -163,8 +188,9 @@ func (s *TokenStore) GetTokenByHash(hash string) *Token {
 		return nil
 	}
 
+	now := time.Now()
 	for _, token := range s.Tokens {
-		if token.Hash == hash {
+		if token.matches(hash, now) {
 			return token
 		}
 	}
@@ -198,6 +224,89 @@ func (s *TokenStore) RemoveToken(identifier string) (bool, error) {
 	return false, nil
 }
 
+// RotateToken gives the token with the given ID or hash prefix a new hash,
+// keeping its annotation, binding, scopes and expiry. The old hash remains
+// valid for grace (0 = revoked immediately). Returns the token's ID.
+func (s *TokenStore) RotateToken(identifier, newHash string, grace time.Duration) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := ""
+	if _, exists := s.Tokens[identifier]; exists {
+		id = identifier
+	} else if len(identifier) >= 8 {
+		for tokenID, token := range s.Tokens {
+			if strings.HasPrefix(token.Hash, identifier) {
+				id = tokenID
+				break
+			}
+		}
+	}
+	if id == "" {
+		return "", fmt.Errorf("token not found: %s", identifier)
+	}
+
+	token := s.Tokens[id]
+	token.PreviousHash = ""
+	token.PreviousExpiresAt = nil
+	if grace > 0 {
+		graceEnd := time.Now().Add(grace)
+		token.PreviousHash = token.Hash
+		token.PreviousExpiresAt = &graceEnd
+	}
+	token.Hash = newHash
+
+	return id, nil
+}
+
+// ExpireRotatedHashes forgets previous hashes whose rotation grace window
+// has ended. Returns the forgotten hashes (for connection cleanup).
+func (s *TokenStore) ExpireRotatedHashes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []string
+	now := time.Now()
+	for _, token := range s.Tokens {
+		if token.PreviousHash != "" && (token.PreviousExpiresAt == nil || !now.Before(*token.PreviousExpiresAt)) {
+			expired = append(expired, token.PreviousHash)
+			token.PreviousHash = ""
+			token.PreviousExpiresAt = nil
+		}
+	}
+
+	return expired
+}
+
+// SetUsageStore sets where MarkUsed records token use. The token file
+// itself is only written by the token management commands, so last-used
+// times are kept in the usage store instead.
+func (s *TokenStore) SetUsageStore(usage *UsageStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage = usage
+}
+
+// MarkUsed records that the token with the given hash authenticated a
+// request. Use of a rotated-out hash is recorded against the current one.
+func (s *TokenStore) MarkUsed(hash string) {
+	s.mu.RLock()
+	usage := s.usage
+	current := ""
+	now := time.Now()
+	for _, token := range s.Tokens {
+		if token.matches(hash, now) {
+			current = token.Hash
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	if current != "" {
+		usage.RecordAuth(current)
+	}
+}
+
 // ValidateToken checks if a token is valid (exists and not expired)
 func (s *TokenStore) ValidateToken(token string) (bool, error) {
 	s.mu.RLock()
@@ -211,7 +320,7 @@ func (s *TokenStore) ValidateToken(token string) (bool, error) {
 	now := time.Now()
 
 	for _, storedToken := range s.Tokens {
-		if storedToken.Hash == hash {
+		if storedToken.matches(hash, now) {
 			// Check if expired
 			if storedToken.ExpiresAt != nil && storedToken.ExpiresAt.Before(now) {
 				return false, fmt.Errorf("token has expired")
@@ -245,6 +354,9 @@ func (s *TokenStore) ListTokens() []*TokenInfo {
 			CreatedAt:  token.CreatedAt,
 			Expired:    expired,
 			Database:   token.Database,
+			Scopes:     token.Scopes,
+			Connection: token.Connection,
+			LastUsedAt: s.usage.LastUsed(token.Hash),
 		})
 	}
 
@@ -259,7 +371,10 @@ type TokenInfo struct {
 	Annotation string
 	CreatedAt  time.Time
 	Expired    bool
-	Database   string // Bound database name (empty = first configured database)
+	Database   string           // Bound database name (empty = first configured database)
+	Scopes     []string         // Granted scopes (empty = full access)
+	Connection *TokenConnection // Own database connection (nil = configured database)
+	LastUsedAt *time.Time       // When the token was last used, from the usage store (nil = never or not recorded)
 }
 
 // GetDefaultTokenPath returns the default token file path
@@ -99,7 +99,7 @@ func TestAddToken(t *testing.T) {
 		note := "Test token"
 		expiry := time.Now().Add(24 * time.Hour)
 
-		store.AddToken(tokenID, hash, note, &expiry, "")
+		store.AddToken(tokenID, hash, note, &expiry, "", nil)
 
 		if len(store.Tokens) != 1 {
 			t.Fatalf("Expected 1 token, got %d", len(store.Tokens))
@@ -129,7 +129,7 @@ func TestAddToken(t *testing.T) {
 		hash := "test-hash"
 		note := "Test token"
 
-		store.AddToken(tokenID, hash, note, nil, "")
+		store.AddToken(tokenID, hash, note, nil, "", nil)
 
 		token, exists := store.Tokens[tokenID]
 		if !exists {
@@ -147,7 +147,7 @@ func TestAddToken(t *testing.T) {
 		note := "Database-bound token"
 		database := "production"
 
-		store.AddToken(tokenID, hash, note, nil, database)
+		store.AddToken(tokenID, hash, note, nil, database, nil)
 
 		token, exists := store.Tokens[tokenID]
 		if !exists {
@@ -163,7 +163,7 @@ func TestRemoveToken(t *testing.T) {
 	t.Run("removes token by ID", func(t *testing.T) {
 		store := InitializeTokenStore()
 		tokenID := "token-123"
-		store.AddToken(tokenID, "hash", "note", nil, "")
+		store.AddToken(tokenID, "hash", "note", nil, "", nil)
 
 		removed, err := store.RemoveToken(tokenID)
 		if err != nil {
@@ -182,7 +182,7 @@ func TestRemoveToken(t *testing.T) {
 		store := InitializeTokenStore()
 		// Use a properly-sized hash (minimum 12 characters for display)
 		hash := "abcdef1234567890abcdef1234567890"
-		store.AddToken("token-123", hash, "note", nil, "")
+		store.AddToken("token-123", hash, "note", nil, "", nil)
 
 		// Use at least 8 characters for prefix matching
 		removed, err := store.RemoveToken("abcdef12")
@@ -212,8 +212,8 @@ func TestRemoveToken(t *testing.T) {
 	t.Run("prefix too short returns false", func(t *testing.T) {
 		store := InitializeTokenStore()
 		// Use properly-sized hashes
-		store.AddToken("token-1", "abc1234567890123456789012345678901234567890123456789012345678901", "note1", nil, "")
-		store.AddToken("token-2", "abc4567890123456789012345678901234567890123456789012345678901234", "note2", nil, "")
+		store.AddToken("token-1", "abc1234567890123456789012345678901234567890123456789012345678901", "note1", nil, "", nil)
+		store.AddToken("token-2", "abc4567890123456789012345678901234567890123456789012345678901234", "note2", nil, "", nil)
 
 		// Prefix less than 8 characters should return false
 		removed, err := store.RemoveToken("abc")
@@ -232,7 +232,7 @@ func TestValidateToken(t *testing.T) {
 		token := "test-token"
 		hash := HashToken(token)
 		expiry := time.Now().Add(24 * time.Hour)
-		store.AddToken("token-123", hash, "note", &expiry, "")
+		store.AddToken("token-123", hash, "note", &expiry, "", nil)
 
 		valid, err := store.ValidateToken(token)
 		if err != nil {
@@ -247,7 +247,7 @@ func TestValidateToken(t *testing.T) {
 		store := InitializeTokenStore()
 		token := "test-token"
 		hash := HashToken(token)
-		store.AddToken("token-123", hash, "note", nil, "")
+		store.AddToken("token-123", hash, "note", nil, "", nil)
 
 		valid, err := store.ValidateToken(token)
 		if err != nil {
@@ -263,7 +263,7 @@ func TestValidateToken(t *testing.T) {
 		token := "test-token"
 		hash := HashToken(token)
 		expiry := time.Now().Add(-1 * time.Hour) // Expired 1 hour ago
-		store.AddToken("token-123", hash, "note", &expiry, "")
+		store.AddToken("token-123", hash, "note", &expiry, "", nil)
 
 		valid, err := store.ValidateToken(token)
 		if err == nil {
@@ -276,7 +276,7 @@ func TestValidateToken(t *testing.T) {
 
 	t.Run("rejects invalid token", func(t *testing.T) {
 		store := InitializeTokenStore()
-		store.AddToken("token-123", HashToken("correct-token"), "note", nil, "")
+		store.AddToken("token-123", HashToken("correct-token"), "note", nil, "", nil)
 
 		valid, err := store.ValidateToken("wrong-token")
 		if err != nil {
@@ -306,10 +306,10 @@ func TestCleanupExpiredTokens(t *testing.T) {
 		expiredTime := time.Now().Add(-1 * time.Hour)
 		validTime := time.Now().Add(1 * time.Hour)
 
-		store.AddToken("expired-1", "hash1", "note1", &expiredTime, "")
-		store.AddToken("expired-2", "hash2", "note2", &expiredTime, "")
-		store.AddToken("valid-1", "hash3", "note3", &validTime, "")
-		store.AddToken("valid-2", "hash4", "note4", nil, "") // Never expires
+		store.AddToken("expired-1", "hash1", "note1", &expiredTime, "", nil)
+		store.AddToken("expired-2", "hash2", "note2", &expiredTime, "", nil)
+		store.AddToken("valid-1", "hash3", "note3", &validTime, "", nil)
+		store.AddToken("valid-2", "hash4", "note4", nil, "", nil) // Never expires
 
 		removed, hashes := store.CleanupExpiredTokens()
 		if removed != 2 {
@@ -333,8 +333,8 @@ func TestCleanupExpiredTokens(t *testing.T) {
 	t.Run("does nothing when no expired tokens", func(t *testing.T) {
 		store := InitializeTokenStore()
 		validTime := time.Now().Add(1 * time.Hour)
-		store.AddToken("valid-1", "hash1", "note1", &validTime, "")
-		store.AddToken("valid-2", "hash2", "note2", nil, "")
+		store.AddToken("valid-1", "hash1", "note1", &validTime, "", nil)
+		store.AddToken("valid-2", "hash2", "note2", nil, "", nil)
 
 		removed, hashes := store.CleanupExpiredTokens()
 		if removed != 0 {
@@ -356,8 +356,8 @@ func TestListTokens(t *testing.T) {
 		// Use properly-sized hashes (SHA256 produces 64 chars)
 		hash1 := "abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"
 		hash2 := "123456789012345678901234567890123456789012345678901234567890abcd"
-		store.AddToken("token-1", hash1, "First token", &expiry, "production")
-		store.AddToken("token-2", hash2, "Second token", nil, "")
+		store.AddToken("token-1", hash1, "First token", &expiry, "production", nil)
+		store.AddToken("token-2", hash2, "Second token", nil, "", nil)
 
 		tokens := store.ListTokens()
 		if len(tokens) != 2 {
@@ -398,8 +398,8 @@ func TestSaveAndLoadTokenStore(t *testing.T) {
 		expiry := time.Now().Add(24 * time.Hour)
 		hash1 := "abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"
 		hash2 := "123456789012345678901234567890123456789012345678901234567890abcd"
-		store.AddToken("token-123", hash1, "Test token", &expiry, "production")
-		store.AddToken("token-456", hash2, "Never expires", nil, "")
+		store.AddToken("token-123", hash1, "Test token", &expiry, "production", nil)
+		store.AddToken("token-456", hash2, "Never expires", nil, "", nil)
 
 		err := SaveTokenStore(tokenFile, store)
 		if err != nil {
@@ -452,7 +452,7 @@ func TestSaveAndLoadTokenStore(t *testing.T) {
 
 		store := InitializeTokenStore()
 		hash := "abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"
-		store.AddToken("token-123", hash, "note", nil, "")
+		store.AddToken("token-123", hash, "note", nil, "", nil)
 
 		err := SaveTokenStore(tokenFile, store)
 		if err != nil {
@@ -506,7 +506,7 @@ func TestTokenExpirationEdgeCases(t *testing.T) {
 		token := "test-token"
 		hash := HashToken(token)
 		expiry := time.Now().Add(1 * time.Second)
-		store.AddToken("token-123", hash, "note", &expiry, "")
+		store.AddToken("token-123", hash, "note", &expiry, "", nil)
 
 		// Should be valid now
 		valid, err := store.ValidateToken(token)
@@ -532,7 +532,7 @@ func TestTokenExpirationEdgeCases(t *testing.T) {
 		token := "test-token"
 		hash := HashToken(token)
 		expiry := time.Now()
-		store.AddToken("token-123", hash, "note", &expiry, "")
+		store.AddToken("token-123", hash, "note", &expiry, "", nil)
 
 		// Token expiring at current time should be treated as expired
 		valid, err := store.ValidateToken(token)
@@ -544,3 +544,144 @@ func TestTokenExpirationEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestRotateToken(t *testing.T) {
+	t.Run("old secret valid during grace window", func(t *testing.T) {
+		store := InitializeTokenStore()
+		expiry := time.Now().Add(30 * 24 * time.Hour)
+		store.AddToken("token-1", HashToken("old"), "Team A", &expiry, "sales", []string{ScopeRead})
+
+		id, err := store.RotateToken("token-1", HashToken("new"), time.Hour)
+		if err != nil || id != "token-1" {
+			t.Fatalf("RotateToken() = (%q, %v)", id, err)
+		}
+
+		for _, secret := range []string{"old", "new"} {
+			if valid, err := store.ValidateToken(secret); !valid || err != nil {
+				t.Errorf("ValidateToken(%q) = (%v, %v), want valid", secret, valid, err)
+			}
+		}
+
+		token := store.GetTokenByHash(HashToken("old"))
+		if token == nil || token.Annotation != "Team A" || token.Database != "sales" ||
+			len(token.Scopes) != 1 || token.ExpiresAt == nil || !token.ExpiresAt.Equal(expiry) {
+			t.Errorf("rotated token lost its settings: %+v", token)
+		}
+
+		// Nothing to retire until the window ends
+		if retired := store.ExpireRotatedHashes(); len(retired) != 0 {
+			t.Errorf("ExpireRotatedHashes() = %v, want none", retired)
+		}
+		past := time.Now().Add(-time.Minute)
+		token.PreviousExpiresAt = &past
+
+		if valid, _ := store.ValidateToken("old"); valid {
+			t.Error("old secret valid after the grace window")
+		}
+		retired := store.ExpireRotatedHashes()
+		if len(retired) != 1 || retired[0] != HashToken("old") || token.PreviousHash != "" {
+			t.Errorf("ExpireRotatedHashes() = %v, token = %+v", retired, token)
+		}
+	})
+
+	t.Run("zero grace revokes immediately", func(t *testing.T) {
+		store := InitializeTokenStore()
+		store.AddToken("token-1", HashToken("old"), "", nil, "", nil)
+
+		if _, err := store.RotateToken(HashToken("old")[:12], HashToken("new"), 0); err != nil {
+			t.Fatalf("RotateToken() by hash prefix error: %v", err)
+		}
+		if valid, _ := store.ValidateToken("old"); valid {
+			t.Error("old secret still valid")
+		}
+		if valid, _ := store.ValidateToken("new"); !valid {
+			t.Error("new secret not valid")
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		store := InitializeTokenStore()
+		if _, err := store.RotateToken("missing", "hash", time.Hour); err == nil {
+			t.Error("expected error for unknown token")
+		}
+	})
+}
+
+func TestMarkUsed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.yaml")
+	store := InitializeTokenStore()
+	if err := store.AddToken("ci", HashToken("ci-secret"), "", nil, "", nil); err != nil {
+		t.Fatalf("AddToken() error: %v", err)
+	}
+	if err := SaveTokenStore(path, store); err != nil {
+		t.Fatalf("SaveTokenStore() error: %v", err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	store, err = LoadTokenStore(path)
+	if err != nil {
+		t.Fatalf("LoadTokenStore() error: %v", err)
+	}
+
+	// Without a usage store, use is not recorded
+	store.MarkUsed(HashToken("ci-secret"))
+
+	usage := &UsageStore{Usage: make(map[string]*TokenUsage)}
+	store.SetUsageStore(usage)
+
+	store.MarkUsed(HashToken("unknown"))
+	if len(usage.Usage) != 0 {
+		t.Error("MarkUsed() of an unknown hash recorded usage")
+	}
+
+	store.MarkUsed(HashToken("ci-secret"))
+	first := usage.LastUsed(HashToken("ci-secret"))
+	if first == nil {
+		t.Fatal("MarkUsed() did not record the use")
+	}
+	if got := store.ListTokens()[0].LastUsedAt; got == nil || !got.Equal(*first) {
+		t.Errorf("ListTokens() LastUsedAt = %v, want %v", got, first)
+	}
+
+	// A second use within the resolution doesn't move the timestamp
+	store.MarkUsed(HashToken("ci-secret"))
+	if got := usage.LastUsed(HashToken("ci-secret")); !got.Equal(*first) {
+		t.Error("MarkUsed() updated the last use within lastAuthResolution")
+	}
+
+	stale := time.Now().Add(-2 * lastAuthResolution)
+	usage.Usage[HashToken("ci-secret")].LastAuthAt = stale
+	store.MarkUsed(HashToken("ci-secret"))
+	if !usage.LastUsed(HashToken("ci-secret")).After(stale) {
+		t.Error("MarkUsed() did not update a stale last use")
+	}
+
+	// The token file is never rewritten to record use
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Error("MarkUsed() changed the token file")
+	}
+}
+
+func TestMarkUsed_RotatedHash(t *testing.T) {
+	store := InitializeTokenStore()
+	if err := store.AddToken("ci", HashToken("old-secret"), "", nil, "", nil); err != nil {
+		t.Fatalf("AddToken() error: %v", err)
+	}
+	if _, err := store.RotateToken("ci", HashToken("new-secret"), time.Hour); err != nil {
+		t.Fatalf("RotateToken() error: %v", err)
+	}
+	usage := &UsageStore{Usage: make(map[string]*TokenUsage)}
+	store.SetUsageStore(usage)
+
+	// Use of the previous secret during the grace window counts for the token
+	store.MarkUsed(HashToken("old-secret"))
+	if usage.LastUsed(HashToken("new-secret")) == nil {
+		t.Error("use of the previous hash was not recorded against the current hash")
+	}
+}
@@ -0,0 +1,151 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/crypto"
+)
+
+// TokenConnection points a token at its own database server instead of the
+// one configured for its bound database. Empty fields keep the configured
+// value; pool, timeout and safety settings always come from the configuration.
+type TokenConnection struct {
+	Host     string `yaml:"host,omitempty"`
+	Port     int    `yaml:"port,omitempty"`
+	Database string `yaml:"database,omitempty"`
+	User     string `yaml:"user,omitempty"`
+	Password string `yaml:"password,omitempty"` // Encrypted with the server's secret file key
+	SSLMode  string `yaml:"sslmode,omitempty"`
+}
+
+// ParseTokenConnection parses a postgres:// URL into a TokenConnection,
+// encrypting any password with key
+func ParseTokenConnection(connStr string, key *crypto.EncryptionKey) (*TokenConnection, error) {
+	u, err := url.Parse(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection string: %w", err)
+	}
+	if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+		return nil, fmt.Errorf("connection string must start with postgres:// or postgresql://")
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("connection string has no host")
+	}
+
+	conn := &TokenConnection{
+		Host:     u.Hostname(),
+		Database: u.Path,
+		SSLMode:  u.Query().Get("sslmode"),
+	}
+	if len(conn.Database) > 0 && conn.Database[0] == '/' {
+		conn.Database = conn.Database[1:]
+	}
+	if port := u.Port(); port != "" {
+		conn.Port, err = strconv.Atoi(port)
+		if err != nil || conn.Port < 1 || conn.Port > 65535 {
+			return nil, fmt.Errorf("invalid port in connection string: %s", port)
+		}
+	}
+	if u.User != nil {
+		conn.User = u.User.Username()
+		if password, ok := u.User.Password(); ok && password != "" {
+			if key == nil {
+				return nil, fmt.Errorf("an encryption key is required to store a password")
+			}
+			conn.Password, err = key.Encrypt(password)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt password: %w", err)
+			}
+		}
+	}
+
+	return conn, nil
+}
+
+// String returns the connection as user@host:port/database, without the password
+func (c *TokenConnection) String() string {
+	s := c.Host
+	if c.User != "" {
+		s = c.User + "@" + s
+	}
+	if c.Port != 0 {
+		s += fmt.Sprintf(":%d", c.Port)
+	}
+	if c.Database != "" {
+		s += "/" + c.Database
+	}
+	return s
+}
+
+// Apply returns a copy of base with the token's connection settings laid
+// over it, decrypting the password with key
+func (c *TokenConnection) Apply(base *config.NamedDatabaseConfig, key *crypto.EncryptionKey) (*config.NamedDatabaseConfig, error) {
+	dbConfig := *base
+	if c.Host != "" {
+		dbConfig.Host = c.Host
+	}
+	if c.Port != 0 {
+		dbConfig.Port = c.Port
+	}
+	if c.Database != "" {
+		dbConfig.Database = c.Database
+	}
+	if c.User != "" {
+		dbConfig.User = c.User
+		// The configured password belongs to the configured user
+		dbConfig.Password = ""
+	}
+	if c.SSLMode != "" {
+		dbConfig.SSLMode = c.SSLMode
+	}
+	if c.Password != "" {
+		if key == nil {
+			return nil, fmt.Errorf("token connection has an encrypted password but no encryption key is loaded (check secret_file)")
+		}
+		password, err := key.Decrypt(c.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt token connection password: %w", err)
+		}
+		dbConfig.Password = password
+	}
+	return &dbConfig, nil
+}
+
+// SetConnection gives a token its own database connection (nil removes it)
+func (s *TokenStore) SetConnection(tokenID string, conn *TokenConnection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, exists := s.Tokens[tokenID]
+	if !exists {
+		return fmt.Errorf("token not found: %s", tokenID)
+	}
+	token.Connection = conn
+	return nil
+}
+
+// ConnectionResolver returns a function that gives the database settings a
+// token should use in place of base, or nil if the token has no connection
+// of its own. key decrypts stored passwords and may be nil if none are set.
+func (s *TokenStore) ConnectionResolver(key *crypto.EncryptionKey) func(tokenHash string, base *config.NamedDatabaseConfig) (*config.NamedDatabaseConfig, error) {
+	return func(tokenHash string, base *config.NamedDatabaseConfig) (*config.NamedDatabaseConfig, error) {
+		token := s.GetTokenByHash(tokenHash)
+		if token == nil || token.Connection == nil {
+			return nil, nil
+		}
+		return token.Connection.Apply(base, key)
+	}
+}
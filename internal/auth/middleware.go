@@ -38,6 +38,13 @@ const (
 	// HealthCheckPath is the path for the health check endpoint (bypasses authentication)
 	HealthCheckPath = "/health"
 
+	// ReadinessPath is the path for the readiness check endpoint (bypasses authentication)
+	ReadinessPath = "/ready"
+
+	// MetricsPath is the path for the Prometheus metrics endpoint (bypasses
+	// authentication so scrapers don't need a token)
+	MetricsPath = "/metrics"
+
 	// UserInfoPath is the path for the user info endpoint (bypasses auth to return auth status)
 	UserInfoPath = "/api/user/info"
 )
@@ -118,7 +125,7 @@ func AuthMiddleware(tokenStore *TokenStore, userStore *UserStore, enabled bool)
 
 			// Skip authentication for public endpoints (needed before login)
 			switch r.URL.Path {
-			case HealthCheckPath, UserInfoPath:
+			case HealthCheckPath, ReadinessPath, MetricsPath, UserInfoPath:
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -150,6 +157,7 @@ func AuthMiddleware(tokenStore *TokenStore, userStore *UserStore, enabled bool)
 			if err == nil && validAPIToken {
 				// Valid API token - use token hash for connection isolation
 				tokenHash := HashToken(token)
+				tokenStore.MarkUsed(tokenHash)
 				ctx := context.WithValue(r.Context(), TokenHashContextKey, tokenHash)
 				ctx = context.WithValue(ctx, IsAPITokenContextKey, true)
 				r = r.WithContext(ctx)
@@ -73,6 +73,42 @@ func TestAuthMiddleware_HealthCheck(t *testing.T) {
 	}
 }
 
+// TestAuthMiddleware_ReadinessCheck tests that the readiness endpoint bypasses auth
+func TestAuthMiddleware_ReadinessCheck(t *testing.T) {
+	tokenStore := &TokenStore{
+		Tokens: make(map[string]*Token),
+	}
+
+	handler := AuthMiddleware(tokenStore, nil, true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", ReadinessPath, nil))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status OK for readiness check, got %d", rr.Code)
+	}
+}
+
+// TestAuthMiddleware_Metrics tests that the metrics endpoint bypasses auth
+func TestAuthMiddleware_Metrics(t *testing.T) {
+	tokenStore := &TokenStore{
+		Tokens: make(map[string]*Token),
+	}
+
+	handler := AuthMiddleware(tokenStore, nil, true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", MetricsPath, nil))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status OK for metrics, got %d", rr.Code)
+	}
+}
+
 // TestAuthMiddleware_MissingAuthHeader tests rejection of requests without Authorization header
 func TestAuthMiddleware_MissingAuthHeader(t *testing.T) {
 	tokenStore := &TokenStore{
@@ -197,6 +233,8 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 			"test-token-id": tokenStruct,
 		},
 	}
+	usageStore := &UsageStore{Usage: make(map[string]*TokenUsage)}
+	tokenStore.SetUsageStore(usageStore)
 
 	middleware := AuthMiddleware(tokenStore, nil, true)
 
@@ -230,6 +268,10 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 	if ctxHash != tokenHash {
 		t.Errorf("Expected token hash %q, got %q", tokenHash, ctxHash)
 	}
+
+	if usageStore.LastUsed(tokenHash) == nil {
+		t.Error("Expected the authenticated token's use to be recorded")
+	}
 }
 
 // TestAuthMiddleware_ExpiredToken tests rejection of expired tokens
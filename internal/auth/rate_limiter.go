@@ -48,6 +48,15 @@ func NewRateLimiter(windowMinutes int, maxAttempts int, cleanupInterval ...time.
 	return rl
 }
 
+// SetLimits changes the window and attempt limit, keeping the attempts
+// already recorded. Used when the configuration is reloaded.
+func (rl *RateLimiter) SetLimits(windowMinutes int, maxAttempts int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.windowDuration = time.Duration(windowMinutes) * time.Minute
+	rl.maxAttempts = maxAttempts
+}
+
 // IsAllowed checks if an IP address is allowed to make an authentication attempt
 // Returns true if the IP has not exceeded the rate limit
 func (rl *RateLimiter) IsAllowed(ipAddress string) bool {
@@ -70,6 +70,25 @@ func TestRateLimiter_IsAllowed(t *testing.T) {
 	}
 }
 
+func TestRateLimiter_SetLimits(t *testing.T) {
+	rl := NewRateLimiter(1, 3)
+	defer rl.Stop()
+
+	ipAddress := "192.168.1.100"
+	for i := 0; i < 3; i++ {
+		rl.RecordFailedAttempt(ipAddress)
+	}
+	if rl.IsAllowed(ipAddress) {
+		t.Fatal("4th attempt should be blocked")
+	}
+
+	// Raising the limit keeps the recorded attempts
+	rl.SetLimits(1, 5)
+	if got := rl.GetRemainingAttempts(ipAddress); got != 2 {
+		t.Errorf("GetRemainingAttempts() = %d, want 2", got)
+	}
+}
+
 func TestRateLimiter_MultipleIPs(t *testing.T) {
 	rl := NewRateLimiter(1, 2) // 1 minute window, 2 attempts max
 	defer rl.Stop()
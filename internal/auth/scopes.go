@@ -0,0 +1,62 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Token scopes, from least to most privileged. Each scope includes the
+// ones below it, so a tune token can also read.
+const (
+	ScopeRead  = "read"  // Query data and inspect the schema and server
+	ScopeTune  = "tune"  // Also change server settings and cancel or terminate sessions
+	ScopeAdmin = "admin" // Also inspect other tokens
+)
+
+// scopeLevels ranks the scopes so a higher scope satisfies a lower one
+var scopeLevels = map[string]int{
+	ScopeRead:  1,
+	ScopeTune:  2,
+	ScopeAdmin: 3,
+}
+
+// ParseScopes parses a comma-separated scope list such as "read,tune".
+// An empty string returns no scopes, which grants full access.
+func ParseScopes(s string) ([]string, error) {
+	var scopes []string
+	for _, part := range strings.Split(s, ",") {
+		scope := strings.ToLower(strings.TrimSpace(part))
+		if scope == "" {
+			continue
+		}
+		if _, ok := scopeLevels[scope]; !ok {
+			return nil, fmt.Errorf("unknown token scope '%s' (use read, tune or admin)", scope)
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// HasScope reports whether the token is allowed to act with the given scope.
+// Tokens created without scopes keep full access.
+func (t *Token) HasScope(scope string) bool {
+	if len(t.Scopes) == 0 {
+		return true
+	}
+	for _, granted := range t.Scopes {
+		if scopeLevels[granted] >= scopeLevels[scope] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package auth
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"read", []string{"read"}, false},
+		{" Read , tune ", []string{"read", "tune"}, false},
+		{"admin,", []string{"admin"}, false},
+		{"write", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseScopes(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseScopes(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseScopes(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTokenHasScope(t *testing.T) {
+	tests := []struct {
+		scopes []string
+		scope  string
+		want   bool
+	}{
+		{nil, ScopeAdmin, true},
+		{[]string{ScopeRead}, ScopeRead, true},
+		{[]string{ScopeRead}, ScopeTune, false},
+		{[]string{ScopeTune}, ScopeRead, true},
+		{[]string{ScopeTune}, ScopeAdmin, false},
+		{[]string{ScopeRead, ScopeAdmin}, ScopeTune, true},
+	}
+
+	for _, tt := range tests {
+		token := &Token{Scopes: tt.scopes}
+		if got := token.HasScope(tt.scope); got != tt.want {
+			t.Errorf("Token{Scopes: %v}.HasScope(%q) = %v, want %v", tt.scopes, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestDatabaseAccessCheckerHasScope(t *testing.T) {
+	store := InitializeTokenStore()
+	if err := store.AddToken("reader", HashToken("r"), "", nil, "", []string{ScopeRead}); err != nil {
+		t.Fatalf("AddToken() error: %v", err)
+	}
+
+	readerCtx := context.WithValue(context.Background(), TokenHashContextKey, HashToken("r"))
+	readerCtx = context.WithValue(readerCtx, IsAPITokenContextKey, true)
+	sessionCtx := context.WithValue(context.Background(), UsernameContextKey, "alice")
+
+	checker := NewDatabaseAccessChecker(store, true, false)
+	if !checker.HasScope(readerCtx, ScopeRead) {
+		t.Error("read token denied the read scope")
+	}
+	if checker.HasScope(readerCtx, ScopeTune) {
+		t.Error("read token granted the tune scope")
+	}
+	if !checker.HasScope(sessionCtx, ScopeAdmin) {
+		t.Error("session user denied the admin scope")
+	}
+
+	if !NewDatabaseAccessChecker(store, false, false).HasScope(readerCtx, ScopeTune) {
+		t.Error("scopes enforced with auth disabled")
+	}
+	if !NewDatabaseAccessChecker(store, true, true).HasScope(readerCtx, ScopeTune) {
+		t.Error("scopes enforced in STDIO mode")
+	}
+}
+
+func TestDatabaseAccessCheckerHasScope_FailsClosed(t *testing.T) {
+	store := InitializeTokenStore()
+	if err := store.AddToken("admin", HashToken("a"), "", nil, "", nil); err != nil {
+		t.Fatalf("AddToken() error: %v", err)
+	}
+
+	adminCtx := context.WithValue(context.Background(), TokenHashContextKey, HashToken("a"))
+	adminCtx = context.WithValue(adminCtx, IsAPITokenContextKey, true)
+
+	checker := NewDatabaseAccessChecker(store, true, false)
+	if !checker.HasScope(adminCtx, ScopeAdmin) {
+		t.Fatal("unscoped token denied the admin scope")
+	}
+
+	// A token removed after the request was authenticated has no scopes
+	if removed, err := store.RemoveToken("admin"); err != nil || !removed {
+		t.Fatalf("RemoveToken() = %v, %v", removed, err)
+	}
+	if checker.HasScope(adminCtx, ScopeRead) {
+		t.Error("removed token granted the read scope")
+	}
+
+	noHashCtx := context.WithValue(context.Background(), IsAPITokenContextKey, true)
+	if checker.HasScope(noHashCtx, ScopeRead) {
+		t.Error("API token request without a hash granted the read scope")
+	}
+	if NewDatabaseAccessChecker(nil, true, false).HasScope(adminCtx, ScopeRead) {
+		t.Error("scope granted without a token store")
+	}
+	if checker.HasScope(context.Background(), ScopeRead) {
+		t.Error("unauthenticated request granted the read scope")
+	}
+	if !NewDatabaseAccessChecker(nil, false, false).HasScope(context.Background(), ScopeAdmin) {
+		t.Error("scope denied with auth disabled")
+	}
+}
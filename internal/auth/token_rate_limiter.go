@@ -0,0 +1,112 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package auth
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenRateLimiter limits the MCP requests each authenticated token can make
+// with a token bucket per token hash. A bucket holds up to a minute's worth
+// of requests and refills continuously, so short bursts are allowed but the
+// sustained rate can't exceed the limit.
+type TokenRateLimiter struct {
+	mu           sync.Mutex
+	buckets      map[string]*tokenBucket // token hash -> bucket
+	capacity     float64                 // Requests per minute, also the burst size
+	refillPerSec float64
+	now          func() time.Time
+}
+
+// tokenBucket is the remaining allowance of one token
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenRateLimiter creates a limiter allowing perMinute requests per
+// minute to each token
+func NewTokenRateLimiter(perMinute int) *TokenRateLimiter {
+	return &TokenRateLimiter{
+		buckets:      make(map[string]*tokenBucket),
+		capacity:     float64(perMinute),
+		refillPerSec: float64(perMinute) / 60,
+		now:          time.Now,
+	}
+}
+
+// SetRate changes the limit to perMinute requests per minute. Buckets keep
+// their remaining requests, capped at the new burst size.
+func (l *TokenRateLimiter) SetRate(perMinute int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for hash := range l.buckets {
+		l.refill(hash, now)
+	}
+	l.capacity = float64(perMinute)
+	l.refillPerSec = float64(perMinute) / 60
+	for _, bucket := range l.buckets {
+		bucket.tokens = math.Min(l.capacity, bucket.tokens)
+	}
+}
+
+// Allow takes one request from the token's bucket. If the bucket is empty
+// it returns false and how long until a request will be allowed.
+func (l *TokenRateLimiter) Allow(tokenHash string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	bucket := l.refill(tokenHash, now)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	wait := (1 - bucket.tokens) / l.refillPerSec
+	return false, time.Duration(math.Ceil(wait * float64(time.Second)))
+}
+
+// refill returns the token's bucket topped up for the time since it was last
+// used, creating a full one if needed. The caller must hold l.mu.
+func (l *TokenRateLimiter) refill(tokenHash string, now time.Time) *tokenBucket {
+	bucket, exists := l.buckets[tokenHash]
+	if !exists {
+		bucket = &tokenBucket{tokens: l.capacity, last: now}
+		l.buckets[tokenHash] = bucket
+		return bucket
+	}
+
+	bucket.tokens = math.Min(l.capacity, bucket.tokens+now.Sub(bucket.last).Seconds()*l.refillPerSec)
+	bucket.last = now
+	return bucket
+}
+
+// Cleanup forgets tokens whose buckets have refilled completely, since a
+// full bucket is the same as none. Returns the number removed.
+func (l *TokenRateLimiter) Cleanup() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	removed := 0
+	for hash := range l.buckets {
+		if l.refill(hash, now).tokens >= l.capacity {
+			delete(l.buckets, hash)
+			removed++
+		}
+	}
+	return removed
+}
@@ -0,0 +1,98 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenRateLimiter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewTokenRateLimiter(60)
+	limiter.now = func() time.Time { return now }
+
+	// A full bucket allows a burst of a minute's requests
+	for i := 0; i < 60; i++ {
+		if allowed, _ := limiter.Allow("hash-a"); !allowed {
+			t.Fatalf("request %d refused", i+1)
+		}
+	}
+	allowed, retryAfter := limiter.Allow("hash-a")
+	if allowed {
+		t.Fatal("request over the limit allowed")
+	}
+	if retryAfter != time.Second {
+		t.Errorf("retryAfter = %v, want 1s", retryAfter)
+	}
+
+	// Other tokens are unaffected
+	if allowed, _ := limiter.Allow("hash-b"); !allowed {
+		t.Error("hash-b refused")
+	}
+
+	// The bucket refills at the configured rate
+	now = now.Add(2 * time.Second)
+	for i := 0; i < 2; i++ {
+		if allowed, _ := limiter.Allow("hash-a"); !allowed {
+			t.Errorf("refilled request %d refused", i+1)
+		}
+	}
+	if allowed, _ := limiter.Allow("hash-a"); allowed {
+		t.Error("request beyond the refill allowed")
+	}
+}
+
+func TestTokenRateLimiterCleanup(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewTokenRateLimiter(10)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("hash-a")
+	now = now.Add(30 * time.Second)
+	limiter.Allow("hash-b")
+
+	// hash-a has refilled, hash-b has not
+	if removed := limiter.Cleanup(); removed != 1 {
+		t.Errorf("Cleanup() = %d, want 1", removed)
+	}
+	if _, exists := limiter.buckets["hash-b"]; !exists {
+		t.Error("hash-b bucket removed before refilling")
+	}
+}
+
+func TestTokenRateLimiterSetRate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewTokenRateLimiter(60)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 50; i++ {
+		limiter.Allow("hash-a")
+	}
+
+	// Lowering the limit caps the remaining burst at the new size
+	limiter.SetRate(6)
+	if got := limiter.buckets["hash-a"].tokens; got != 6 {
+		t.Errorf("tokens after SetRate(6) = %v, want 6", got)
+	}
+
+	// Raising it keeps what's left and refills at the new rate
+	limiter.SetRate(120)
+	now = now.Add(time.Second)
+	for i := 0; i < 8; i++ {
+		if allowed, _ := limiter.Allow("hash-a"); !allowed {
+			t.Fatalf("request %d refused", i+1)
+		}
+	}
+	if allowed, _ := limiter.Allow("hash-a"); allowed {
+		t.Error("request beyond the refill allowed")
+	}
+}
@@ -0,0 +1,343 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// maxToolsPerToken bounds the distinct tool names counted per token;
+	// further names are counted under otherToolsKey
+	maxToolsPerToken = 50
+	otherToolsKey    = "(other)"
+
+	// maxUsageEntries bounds the number of tokens tracked; the least
+	// recently used entry is dropped when the limit is reached
+	maxUsageEntries = 10000
+
+	// defaultUsageFlushInterval is how often pending usage is written out
+	defaultUsageFlushInterval = 30 * time.Second
+
+	// lastAuthResolution is how stale a token's LastAuthAt may get before
+	// a request updates it, so busy tokens don't keep the store dirty
+	lastAuthResolution = time.Minute
+)
+
+// TokenUsage is the tool-call summary recorded for one token
+type TokenUsage struct {
+	ToolCalls  map[string]int64 `yaml:"tool_calls"`             // Calls per tool name
+	Denied     int64            `yaml:"denied"`                 // Calls refused (disabled tool or no accessible database)
+	LastTool   string           `yaml:"last_tool"`              // Most recently called tool
+	LastCallAt time.Time        `yaml:"last_call_at"`           // When the last tool was called
+	LastAuthAt time.Time        `yaml:"last_auth_at,omitempty"` // When the token last authenticated any request, to the nearest lastAuthResolution
+}
+
+// LastUsed returns the later of the token's last authentication and last
+// tool call
+func (u *TokenUsage) LastUsed() time.Time {
+	if u.LastAuthAt.After(u.LastCallAt) {
+		return u.LastAuthAt
+	}
+	return u.LastCallAt
+}
+
+// TotalCalls returns the number of tool calls recorded for the token
+func (u *TokenUsage) TotalCalls() int64 {
+	var total int64
+	for _, n := range u.ToolCalls {
+		total += n
+	}
+	return total
+}
+
+// TopTools returns up to n tool names, most called first
+func (u *TokenUsage) TopTools(n int) []string {
+	names := make([]string, 0, len(u.ToolCalls))
+	for name := range u.ToolCalls {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if u.ToolCalls[names[i]] != u.ToolCalls[names[j]] {
+			return u.ToolCalls[names[i]] > u.ToolCalls[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > n {
+		names = names[:n]
+	}
+	return names
+}
+
+// UsageStore records per-token tool-call usage in memory and writes it to
+// disk in batches, so recording a call never waits on file I/O
+type UsageStore struct {
+	mu    sync.Mutex
+	Usage map[string]*TokenUsage `yaml:"usage"` // key is the token hash
+	path  string
+	dirty bool
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// UsageFilePath returns the usage file kept alongside a token file
+func UsageFilePath(tokenFile string) string {
+	ext := filepath.Ext(tokenFile)
+	return strings.TrimSuffix(tokenFile, ext) + "-usage.yaml"
+}
+
+// LoadUsageStore loads usage from a YAML file; a missing file gives an
+// empty store that will be created on the first flush
+func LoadUsageStore(path string) (*UsageStore, error) {
+	store := &UsageStore{
+		Usage: make(map[string]*TokenUsage),
+		path:  path,
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse usage file: %w", err)
+	}
+	if store.Usage == nil {
+		store.Usage = make(map[string]*TokenUsage)
+	}
+
+	return store, nil
+}
+
+// RecordCall records a tool call for a token. denied marks calls that
+// were refused before the tool ran.
+func (s *UsageStore) RecordCall(tokenHash, toolName string, denied bool) {
+	if s == nil || tokenHash == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := s.entry(tokenHash)
+	key := toolName
+	if _, counted := usage.ToolCalls[key]; !counted && len(usage.ToolCalls) >= maxToolsPerToken {
+		key = otherToolsKey
+	}
+	usage.ToolCalls[key]++
+	if denied {
+		usage.Denied++
+	}
+	usage.LastTool = toolName
+	usage.LastCallAt = time.Now()
+	s.dirty = true
+}
+
+// RecordAuth records that a token authenticated a request. LastAuthAt only
+// moves once it is lastAuthResolution old.
+func (s *UsageStore) RecordAuth(tokenHash string) {
+	if s == nil || tokenHash == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := s.entry(tokenHash)
+	now := time.Now()
+	if now.Sub(usage.LastAuthAt) >= lastAuthResolution {
+		usage.LastAuthAt = now
+		s.dirty = true
+	}
+}
+
+// LastUsed returns when a token was last used, or nil if it has no
+// recorded use
+func (s *UsageStore) LastUsed(tokenHash string) *time.Time {
+	usage := s.Get(tokenHash)
+	if usage == nil {
+		return nil
+	}
+	lastUsed := usage.LastUsed()
+	if lastUsed.IsZero() {
+		return nil
+	}
+	return &lastUsed
+}
+
+// entry returns the usage of a token, creating it if needed; callers hold
+// s.mu
+func (s *UsageStore) entry(tokenHash string) *TokenUsage {
+	usage, exists := s.Usage[tokenHash]
+	if !exists {
+		if len(s.Usage) >= maxUsageEntries {
+			s.evictOldest()
+		}
+		usage = &TokenUsage{ToolCalls: make(map[string]int64)}
+		s.Usage[tokenHash] = usage
+	}
+	if usage.ToolCalls == nil {
+		usage.ToolCalls = make(map[string]int64)
+	}
+	return usage
+}
+
+// evictOldest drops the least recently used entry; callers hold s.mu
+func (s *UsageStore) evictOldest() {
+	var oldestHash string
+	var oldest time.Time
+	for hash, usage := range s.Usage {
+		if oldestHash == "" || usage.LastUsed().Before(oldest) {
+			oldestHash = hash
+			oldest = usage.LastUsed()
+		}
+	}
+	delete(s.Usage, oldestHash)
+}
+
+// Get returns a copy of the usage recorded for a token, or nil
+func (s *UsageStore) Get(tokenHash string) *TokenUsage {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage, exists := s.Usage[tokenHash]
+	if !exists {
+		return nil
+	}
+
+	usageCopy := *usage
+	usageCopy.ToolCalls = make(map[string]int64, len(usage.ToolCalls))
+	for name, n := range usage.ToolCalls {
+		usageCopy.ToolCalls[name] = n
+	}
+	return &usageCopy
+}
+
+// UsageByTokenID returns the recorded usage of each token in the token
+// store, keyed by token ID; tokens with no recorded calls are omitted
+func (s *UsageStore) UsageByTokenID(tokens *TokenStore) map[string]*TokenUsage {
+	result := make(map[string]*TokenUsage)
+	if s == nil || tokens == nil {
+		return result
+	}
+
+	tokens.mu.RLock()
+	hashes := make(map[string]string, len(tokens.Tokens))
+	for id, token := range tokens.Tokens {
+		hashes[id] = token.Hash
+	}
+	tokens.mu.RUnlock()
+
+	for id, hash := range hashes {
+		if usage := s.Get(hash); usage != nil {
+			result[id] = usage
+		}
+	}
+	return result
+}
+
+// Remove drops the usage of tokens that no longer exist
+func (s *UsageStore) Remove(tokenHashes []string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, hash := range tokenHashes {
+		if _, exists := s.Usage[hash]; exists {
+			delete(s.Usage, hash)
+			s.dirty = true
+		}
+	}
+}
+
+// Flush writes the store to disk if anything changed since the last write
+func (s *UsageStore) Flush() error {
+	if s == nil || s.path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	data, err := yaml.Marshal(s)
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+
+	return nil
+}
+
+// StartFlushing writes pending usage to disk every interval (0 = default
+// of 30 seconds) until StopFlushing is called
+func (s *UsageStore) StartFlushing(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultUsageFlushInterval
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if err := s.Flush(); err != nil {
+					fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// StopFlushing stops the background writer and writes any pending usage
+func (s *UsageStore) StopFlushing() error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+	return s.Flush()
+}
@@ -0,0 +1,166 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUsageFilePath(t *testing.T) {
+	got := UsageFilePath("/etc/pgedge/pgedge-postgres-mcp-tokens.yaml")
+	want := "/etc/pgedge/pgedge-postgres-mcp-tokens-usage.yaml"
+	if got != want {
+		t.Errorf("UsageFilePath() = %q, want %q", got, want)
+	}
+}
+
+func TestUsageStoreRecordCall(t *testing.T) {
+	t.Run("counts calls and denials per token", func(t *testing.T) {
+		store, err := LoadUsageStore(filepath.Join(t.TempDir(), "usage.yaml"))
+		if err != nil {
+			t.Fatalf("Failed to load usage store: %v", err)
+		}
+
+		store.RecordCall("hash1", "query_database", false)
+		store.RecordCall("hash1", "query_database", false)
+		store.RecordCall("hash1", "get_schema_info", true)
+		store.RecordCall("hash2", "count_rows", false)
+
+		usage := store.Get("hash1")
+		if usage == nil {
+			t.Fatal("Expected usage for hash1")
+		}
+		if usage.TotalCalls() != 3 {
+			t.Errorf("TotalCalls() = %d, want 3", usage.TotalCalls())
+		}
+		if usage.ToolCalls["query_database"] != 2 {
+			t.Errorf("query_database calls = %d, want 2", usage.ToolCalls["query_database"])
+		}
+		if usage.Denied != 1 {
+			t.Errorf("Denied = %d, want 1", usage.Denied)
+		}
+		if usage.LastTool != "get_schema_info" {
+			t.Errorf("LastTool = %q, want get_schema_info", usage.LastTool)
+		}
+		if top := usage.TopTools(1); len(top) != 1 || top[0] != "query_database" {
+			t.Errorf("TopTools(1) = %v, want [query_database]", top)
+		}
+	})
+
+	t.Run("ignores calls without a token", func(t *testing.T) {
+		store, _ := LoadUsageStore("")
+		store.RecordCall("", "query_database", false)
+		if len(store.Usage) != 0 {
+			t.Errorf("Expected no usage, got %d entries", len(store.Usage))
+		}
+	})
+
+	t.Run("bounds distinct tool names per token", func(t *testing.T) {
+		store, _ := LoadUsageStore("")
+		for i := 0; i < maxToolsPerToken+5; i++ {
+			store.RecordCall("hash1", fmt.Sprintf("tool_%d", i), false)
+		}
+
+		usage := store.Get("hash1")
+		if len(usage.ToolCalls) != maxToolsPerToken+1 {
+			t.Errorf("Tracked %d tool names, want %d", len(usage.ToolCalls), maxToolsPerToken+1)
+		}
+		if usage.ToolCalls[otherToolsKey] != 5 {
+			t.Errorf("%s calls = %d, want 5", otherToolsKey, usage.ToolCalls[otherToolsKey])
+		}
+		if usage.TotalCalls() != int64(maxToolsPerToken+5) {
+			t.Errorf("TotalCalls() = %d, want %d", usage.TotalCalls(), maxToolsPerToken+5)
+		}
+	})
+
+	t.Run("Get returns a copy", func(t *testing.T) {
+		store, _ := LoadUsageStore("")
+		store.RecordCall("hash1", "query_database", false)
+
+		usage := store.Get("hash1")
+		usage.ToolCalls["query_database"] = 100
+
+		if store.Get("hash1").ToolCalls["query_database"] != 1 {
+			t.Error("Modifying the returned usage changed the store")
+		}
+	})
+}
+
+func TestUsageStoreFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.yaml")
+
+	store, err := LoadUsageStore(path)
+	if err != nil {
+		t.Fatalf("Failed to load usage store: %v", err)
+	}
+
+	// Nothing recorded, nothing written
+	if err := store.Flush(); err != nil {
+		t.Fatalf("Flush() error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("Expected no usage file before any calls were recorded")
+	}
+
+	store.RecordCall("hash1", "query_database", false)
+	store.RecordAuth("hash2")
+	store.StartFlushing(0)
+	if err := store.StopFlushing(); err != nil {
+		t.Fatalf("StopFlushing() error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected usage file to be written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Usage file permissions = %o, want 600", info.Mode().Perm())
+	}
+
+	reloaded, err := LoadUsageStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reload usage store: %v", err)
+	}
+	usage := reloaded.Get("hash1")
+	if usage == nil || usage.ToolCalls["query_database"] != 1 || usage.LastTool != "query_database" {
+		t.Errorf("Reloaded usage = %+v", usage)
+	}
+	if reloaded.LastUsed("hash2") == nil {
+		t.Error("Reloaded store lost the last authentication time")
+	}
+}
+
+func TestUsageByTokenID(t *testing.T) {
+	tokens := InitializeTokenStore()
+	if err := tokens.AddToken("token-1", HashToken("secret-1"), "reader", nil, "", nil); err != nil {
+		t.Fatalf("AddToken() error: %v", err)
+	}
+	if err := tokens.AddToken("token-2", HashToken("secret-2"), "unused", nil, "", nil); err != nil {
+		t.Fatalf("AddToken() error: %v", err)
+	}
+
+	usage, _ := LoadUsageStore("")
+	usage.RecordCall(HashToken("secret-1"), "query_database", false)
+	usage.RecordCall(HashToken("removed"), "query_database", false)
+
+	byID := usage.UsageByTokenID(tokens)
+	if len(byID) != 1 || byID["token-1"] == nil {
+		t.Errorf("UsageByTokenID() = %v, want only token-1", byID)
+	}
+
+	usage.Remove([]string{HashToken("secret-1")})
+	if usage.Get(HashToken("secret-1")) != nil {
+		t.Error("Expected usage to be removed")
+	}
+}
@@ -357,7 +357,7 @@ func (c *Client) initializeLLM() error {
 			c.config.LLM.AnthropicAPIKey, "", 0, 0, false)
 	case "openai":
 		tempClient = NewOpenAIClient(
-			c.config.LLM.OpenAIAPIKey, "", 0, 0, false)
+			c.config.LLM.OpenAIBaseURL, c.config.LLM.OpenAIAPIKey, "", 0, 0, false)
 	case "ollama":
 		tempClient = NewOllamaClient(
 			c.config.LLM.OllamaURL, "", false)
@@ -415,6 +415,7 @@ func (c *Client) initializeLLM() error {
 		)
 	case "openai":
 		c.llm = NewOpenAIClient(
+			c.config.LLM.OpenAIBaseURL,
 			c.config.LLM.OpenAIAPIKey,
 			c.config.LLM.Model,
 			c.config.LLM.MaxTokens,
@@ -873,6 +874,12 @@ func (c *Client) processQuery(ctx context.Context, query string) error {
 	// This allows the user to cancel with Escape key
 	reqCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	reqCtx = WithSystemPromptOptions(reqCtx, SystemPromptOptions{
+		PinPostgresDialect: c.config.LLM.PinPostgresDialect,
+		Append:             c.config.LLM.SystemPromptAppend,
+		Examples:           c.config.LLM.Examples,
+		MaxExamples:        c.config.LLM.MaxExamples,
+	})
 
 	// Start thinking animation
 	thinkingDone := make(chan struct{})
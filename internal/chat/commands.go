@@ -227,6 +227,7 @@ Settings:
   /show settings                       Show all current settings
   /list models                         List available models from current LLM provider
   /list databases                      List available database connections
+  /list databases dsn                  List them as connection strings (password redacted)
 
 Prompts:
   /prompt <name> [arg=value ...]       Execute an MCP prompt with optional arguments
@@ -638,7 +639,7 @@ func (c *Client) handleListCommand(ctx context.Context, args []string) bool {
 		return c.listModels(ctx)
 
 	case "databases":
-		return c.handleListDatabases(ctx)
+		return c.handleListDatabases(ctx, len(args) > 1 && args[1] == "dsn")
 
 	default:
 		c.ui.PrintError(fmt.Sprintf("Unknown list target: %s", what))
@@ -750,6 +751,7 @@ type DatabaseInfo struct {
 	Database string `json:"database"`
 	User     string `json:"user"`
 	SSLMode  string `json:"sslmode"`
+	DSN      string `json:"dsn,omitempty"` // Connection string with the password redacted
 }
 
 // ListDatabasesResponse is the response from GET /api/databases
@@ -808,7 +810,9 @@ func (c *Client) restoreDatabasePreference(ctx context.Context) {
 }
 
 // handleListDatabases handles /list databases command - lists available databases
-func (c *Client) handleListDatabases(ctx context.Context) bool {
+// With showDSN, each database is shown as a connection string with the
+// password redacted
+func (c *Client) handleListDatabases(ctx context.Context, showDSN bool) bool {
 	// Use the MCPClient interface method (works for both HTTP and STDIO modes)
 	databases, current, err := c.mcp.ListDatabases(ctx)
 	if err != nil {
@@ -827,6 +831,10 @@ func (c *Client) handleListDatabases(ctx context.Context) bool {
 		if db.Name == current {
 			currentMarker = " (current)"
 		}
+		if showDSN && db.DSN != "" {
+			fmt.Printf("  %s%s - %s\n", db.Name, currentMarker, db.DSN)
+			continue
+		}
 		fmt.Printf("  %s%s - %s@%s:%d/%s\n",
 			db.Name, currentMarker, db.User, db.Host, db.Port, db.Database)
 	}
@@ -48,15 +48,20 @@ type MCPConfig struct {
 
 // LLMConfig holds LLM provider configuration
 type LLMConfig struct {
-	Provider            string  `yaml:"provider"`               // anthropic, openai, or ollama
-	Model               string  `yaml:"model"`                  // Model to use
-	AnthropicAPIKey     string  `yaml:"anthropic_api_key"`      // API key for Anthropic (direct - discouraged, use api_key_file or env var)
-	AnthropicAPIKeyFile string  `yaml:"anthropic_api_key_file"` // Path to file containing Anthropic API key
-	OpenAIAPIKey        string  `yaml:"openai_api_key"`         // API key for OpenAI (direct - discouraged, use api_key_file or env var)
-	OpenAIAPIKeyFile    string  `yaml:"openai_api_key_file"`    // Path to file containing OpenAI API key
-	OllamaURL           string  `yaml:"ollama_url"`             // Ollama server URL
-	MaxTokens           int     `yaml:"max_tokens"`             // Max tokens for response
-	Temperature         float64 `yaml:"temperature"`            // Temperature for sampling
+	Provider            string    `yaml:"provider"`               // anthropic, openai, or ollama
+	Model               string    `yaml:"model"`                  // Model to use
+	AnthropicAPIKey     string    `yaml:"anthropic_api_key"`      // API key for Anthropic (direct - discouraged, use api_key_file or env var)
+	AnthropicAPIKeyFile string    `yaml:"anthropic_api_key_file"` // Path to file containing Anthropic API key
+	OpenAIAPIKey        string    `yaml:"openai_api_key"`         // API key for OpenAI (direct - discouraged, use api_key_file or env var)
+	OpenAIAPIKeyFile    string    `yaml:"openai_api_key_file"`    // Path to file containing OpenAI API key
+	OpenAIBaseURL       string    `yaml:"openai_base_url"`        // OpenAI-compatible endpoint (default: https://api.openai.com/v1)
+	OllamaURL           string    `yaml:"ollama_url"`             // Ollama server URL
+	MaxTokens           int       `yaml:"max_tokens"`             // Max tokens for response
+	Temperature         float64   `yaml:"temperature"`            // Temperature for sampling
+	SystemPromptAppend  string    `yaml:"system_prompt_append"`   // Domain context appended to the system prompt
+	PinPostgresDialect  bool      `yaml:"pin_postgres_dialect"`   // Add PostgreSQL dialect instructions to the system prompt
+	Examples            []Example `yaml:"examples"`               // Few-shot question/SQL examples for the system prompt
+	MaxExamples         int       `yaml:"max_examples"`           // How many examples to include (default: 3)
 }
 
 // UIConfig holds UI configuration
@@ -86,6 +91,7 @@ func LoadConfig(configPath string) (*Config, error) {
 			Model:           getEnvOrDefault("PGEDGE_LLM_MODEL", "claude-sonnet-4-5-20250929"),
 			AnthropicAPIKey: getEnvWithFallback("PGEDGE_ANTHROPIC_API_KEY", "ANTHROPIC_API_KEY"),
 			OpenAIAPIKey:    getEnvWithFallback("PGEDGE_OPENAI_API_KEY", "OPENAI_API_KEY"),
+			OpenAIBaseURL:   os.Getenv("PGEDGE_OPENAI_BASE_URL"),
 			OllamaURL:       getEnvOrDefault("PGEDGE_OLLAMA_URL", "http://localhost:11434"),
 			MaxTokens:       4096,
 			Temperature:     0.7,
@@ -207,7 +213,8 @@ func (c *Config) Validate() error {
 			c.LLM.Model = "claude-sonnet-4-5-20250929"
 		}
 	} else if c.LLM.Provider == "openai" {
-		if c.LLM.OpenAIAPIKey == "" {
+		// Custom endpoints may not require a key
+		if c.LLM.OpenAIAPIKey == "" && c.LLM.OpenAIBaseURL == "" {
 			return fmt.Errorf("PGEDGE_OPENAI_API_KEY environment variable or openai_api_key config is required for OpenAI")
 		}
 		if c.LLM.Model == "" {
@@ -222,6 +229,13 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.LLM.MaxExamples < 0 {
+		return fmt.Errorf("llm max_examples must not be negative")
+	}
+	if err := ValidateExamples(c.LLM.Examples); err != nil {
+		return fmt.Errorf("invalid llm examples: %w", err)
+	}
+
 	return nil
 }
 
@@ -231,7 +245,8 @@ func (c *Config) IsProviderConfigured(provider string) bool {
 	case "anthropic":
 		return c.LLM.AnthropicAPIKey != ""
 	case "openai":
-		return c.LLM.OpenAIAPIKey != ""
+		// A custom OpenAI-compatible endpoint may not need a key
+		return c.LLM.OpenAIAPIKey != "" || c.LLM.OpenAIBaseURL != ""
 	case "ollama":
 		// Ollama is configured if URL is set (defaults to localhost)
 		return c.LLM.OllamaURL != ""
@@ -247,6 +247,30 @@ func TestValidate_InvalidProvider(t *testing.T) {
 	}
 }
 
+func TestValidate_Examples(t *testing.T) {
+	cfg := &Config{
+		MCP: MCPConfig{
+			Mode:       "stdio",
+			ServerPath: "/path/to/server",
+		},
+		LLM: LLMConfig{
+			Provider:        "anthropic",
+			AnthropicAPIKey: "test-key",
+			Examples: []Example{
+				{Question: "How many orders are there?", SQL: "SELECT count(*) FROM orders"},
+			},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	cfg.LLM.Examples = append(cfg.LLM.Examples, Example{Question: "Clear the orders", SQL: "DELETE FROM orders"})
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for an example that deletes rows")
+	}
+}
+
 func TestValidate_MissingAPIKey(t *testing.T) {
 	cfg := &Config{
 		MCP: MCPConfig{
@@ -263,3 +287,23 @@ func TestValidate_MissingAPIKey(t *testing.T) {
 		t.Error("Expected validation error for missing API key for Anthropic")
 	}
 }
+
+func TestValidate_OpenAIBaseURLWithoutAPIKey(t *testing.T) {
+	cfg := &Config{
+		MCP: MCPConfig{
+			Mode:       "stdio",
+			ServerPath: "/path/to/server",
+		},
+		LLM: LLMConfig{
+			Provider:      "openai",
+			OpenAIBaseURL: "http://localhost:8000/v1",
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a custom OpenAI endpoint to validate without an API key, got: %v", err)
+	}
+	if !cfg.IsProviderConfigured("openai") {
+		t.Error("Expected openai to be configured when a base URL is set")
+	}
+}
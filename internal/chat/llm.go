@@ -199,7 +199,7 @@ When executing tools:
 	systemMessage := []map[string]interface{}{
 		{
 			"type": "text",
-			"text": systemContent,
+			"text": buildSystemPrompt(ctx, systemContent),
 		},
 	}
 
@@ -542,7 +542,7 @@ IMPORTANT INSTRUCTIONS:
 	ollamaMessages := []ollamaMessage{
 		{
 			Role:    "system",
-			Content: systemMessage,
+			Content: buildSystemPrompt(ctx, systemMessage),
 		},
 	}
 
@@ -809,8 +809,14 @@ func (c *ollamaClient) ListModels(ctx context.Context) ([]string, error) {
 	return models, nil
 }
 
-// openaiClient implements LLMClient for OpenAI GPT models
+// DefaultOpenAIBaseURL is the OpenAI API endpoint used when no base URL is
+// configured
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// openaiClient implements LLMClient for OpenAI GPT models and
+// OpenAI-compatible endpoints (vLLM, LM Studio, LiteLLM, etc.)
 type openaiClient struct {
+	baseURL     string
 	apiKey      string
 	model       string
 	maxTokens   int
@@ -819,9 +825,14 @@ type openaiClient struct {
 	client      *http.Client
 }
 
-// NewOpenAIClient creates a new OpenAI client
-func NewOpenAIClient(apiKey, model string, maxTokens int, temperature float64, debug bool) LLMClient {
+// NewOpenAIClient creates a new OpenAI client. baseURL selects an
+// OpenAI-compatible endpoint; empty means DefaultOpenAIBaseURL.
+func NewOpenAIClient(baseURL, apiKey, model string, maxTokens int, temperature float64, debug bool) LLMClient {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
 	return &openaiClient{
+		baseURL:     strings.TrimRight(baseURL, "/"),
 		apiKey:      apiKey,
 		model:       model,
 		maxTokens:   maxTokens,
@@ -921,7 +932,7 @@ func extractTextFromContent(content interface{}) string {
 func (c *openaiClient) Chat(ctx context.Context, messages []Message, tools interface{}) (LLMResponse, error) {
 	startTime := time.Now()
 	operation := "chat"
-	url := "https://api.openai.com/v1/chat/completions"
+	url := c.baseURL + "/chat/completions"
 
 	embedding.LogLLMCallDetails("openai", c.model, operation, url, len(messages))
 
@@ -966,7 +977,7 @@ When executing tools:
 	openaiMessages := make([]openaiMessage, 0, len(messages)+1)
 	openaiMessages = append(openaiMessages, openaiMessage{
 		Role:    "system",
-		Content: systemContent,
+		Content: buildSystemPrompt(ctx, systemContent),
 	})
 
 	for _, msg := range messages {
@@ -1142,7 +1153,10 @@ When executing tools:
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	// Self-hosted endpoints often run without authentication
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -1310,16 +1324,21 @@ When executing tools:
 }
 
 // ListModels returns available models from OpenAI
-// Filters out embedding, audio, and image models
+// Filters out embedding, audio, and image models. Model names on custom
+// endpoints don't follow OpenAI's naming, so only embedding models are
+// filtered there.
 func (c *openaiClient) ListModels(ctx context.Context) ([]string, error) {
-	url := "https://api.openai.com/v1/models"
+	url := c.baseURL + "/models"
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	// Self-hosted endpoints often run without authentication
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -1352,6 +1371,11 @@ func (c *openaiClient) ListModels(ctx context.Context) ([]string, error) {
 			continue
 		}
 
+		if c.baseURL != DefaultOpenAIBaseURL {
+			models = append(models, id)
+			continue
+		}
+
 		// Exclude audio/speech models
 		if strings.Contains(id, "whisper") ||
 			strings.Contains(id, "tts") ||
@@ -207,6 +207,56 @@ func TestOllamaClient_TextResponse(t *testing.T) {
 	}
 }
 
+func TestOpenAIClient_CustomBaseURL(t *testing.T) {
+	// Create test server standing in for a self-hosted OpenAI-compatible endpoint
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Expected no Authorization header without an API key, got %q", auth)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/models":
+			w.Write([]byte(`{"data": [{"id": "llama-3.1-8b-instruct"}, {"id": "nomic-embedding-v1"}]}`))
+		case "/v1/chat/completions":
+			json.NewEncoder(w).Encode(openaiResponse{
+				Model: "llama-3.1-8b-instruct",
+				Choices: []openaiChoice{{
+					Message:      openaiMessage{Role: "assistant", Content: "Hello from a local model"},
+					FinishReason: "stop",
+				}},
+			})
+		default:
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// Trailing slash should be tolerated
+	client := NewOpenAIClient(server.URL+"/v1/", "", "llama-3.1-8b-instruct", 1024, 0.2, false)
+	ctx := context.Background()
+
+	models, err := client.ListModels(ctx)
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(models) != 1 || models[0] != "llama-3.1-8b-instruct" {
+		t.Errorf("Expected only the chat model, got %v", models)
+	}
+
+	response, err := client.Chat(ctx, []Message{{Role: "user", Content: "Hello"}}, []mcp.Tool{})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if len(response.Content) != 1 {
+		t.Fatalf("Expected 1 content item, got %d", len(response.Content))
+	}
+	if text, ok := response.Content[0].(TextContent); !ok || text.Text != "Hello from a local model" {
+		t.Errorf("Unexpected response content: %#v", response.Content[0])
+	}
+}
+
 func TestFormatToolsForOllama(t *testing.T) {
 	client := &ollamaClient{}
 
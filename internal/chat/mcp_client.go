@@ -314,6 +314,8 @@ type httpClient struct {
 	requestID  int
 	mu         sync.Mutex
 	serverInfo mcp.Implementation
+
+	protocolVersion string // Negotiated in Initialize; sent with later requests
 }
 
 // NewHTTPClient creates a new HTTP-based MCP client
@@ -343,6 +345,9 @@ func (c *httpClient) Initialize(ctx context.Context) error {
 
 	// Store server info
 	c.serverInfo = result.ServerInfo
+	if mcp.IsSupportedProtocolVersion(result.ProtocolVersion) {
+		c.protocolVersion = result.ProtocolVersion
+	}
 
 	return nil
 }
@@ -464,6 +469,9 @@ func (c *httpClient) sendRequest(ctx context.Context, method string, params inte
 	if c.token != "" {
 		httpReq.Header.Set("Authorization", "Bearer "+c.token)
 	}
+	if c.protocolVersion != "" {
+		httpReq.Header.Set(mcp.ProtocolVersionHeader, c.protocolVersion)
+	}
 
 	resp, err := c.client.Do(httpReq)
 	if err != nil {
@@ -0,0 +1,118 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+)
+
+// DefaultMaxExamples is how many few-shot examples go into the system
+// prompt when no limit is configured
+const DefaultMaxExamples = 3
+
+// postgresDialectInstructions are added to the system prompt when the
+// PostgreSQL dialect is pinned, to stop models drifting into the SQL of
+// other databases
+const postgresDialectInstructions = `When writing SQL:
+- Write PostgreSQL SQL only; never use syntax from MySQL, SQL Server or Oracle
+- Use LIMIT/OFFSET, never TOP or ROWNUM
+- Quote identifiers with double quotes, never backticks or square brackets
+- Use single quotes for string literals
+- Use ILIKE for case-insensitive matching and || for string concatenation
+- Use PostgreSQL date functions and interval arithmetic (now(), date_trunc, interval '1 day')`
+
+// Example is a question and the SQL that answers it, shown to the model as
+// a few-shot demonstration
+type Example struct {
+	Question string `yaml:"question" json:"question"`
+	SQL      string `yaml:"sql" json:"sql"`
+}
+
+// ValidateExamples checks that every example has a question and a single
+// read-only statement, so the model is never shown a write to imitate
+func ValidateExamples(examples []Example) error {
+	for i, ex := range examples {
+		if strings.TrimSpace(ex.Question) == "" {
+			return fmt.Errorf("example %d: question is required", i+1)
+		}
+		if err := database.ValidateReadOnlySQL(ex.SQL); err != nil {
+			return fmt.Errorf("example %d (%q): %w", i+1, ex.Question, err)
+		}
+	}
+	return nil
+}
+
+// SystemPromptOptions customize the system prompt the LLM clients send
+type SystemPromptOptions struct {
+	PinPostgresDialect bool      // Add the PostgreSQL dialect instructions
+	Append             string    // Operator context appended to every request (llm.system_prompt_append)
+	Examples           []Example // Few-shot examples (llm.examples)
+	MaxExamples        int       // How many examples to include; 0 means DefaultMaxExamples
+	Hints              string    // Domain context for this request only
+}
+
+type systemPromptOptionsKey struct{}
+
+// WithSystemPromptOptions returns a context whose Chat calls use opts to
+// build the system prompt
+func WithSystemPromptOptions(ctx context.Context, opts SystemPromptOptions) context.Context {
+	return context.WithValue(ctx, systemPromptOptionsKey{}, opts)
+}
+
+// buildSystemPrompt returns base followed by the dialect instructions,
+// operator context, examples and request hints set on ctx, in that order
+func buildSystemPrompt(ctx context.Context, base string) string {
+	opts, ok := ctx.Value(systemPromptOptionsKey{}).(SystemPromptOptions)
+	if !ok {
+		return base
+	}
+
+	sections := []string{base}
+	if opts.PinPostgresDialect {
+		sections = append(sections, postgresDialectInstructions)
+	}
+	if text := strings.TrimSpace(opts.Append); text != "" {
+		sections = append(sections, "Context about this database:\n"+text)
+	}
+	if examples := formatExamples(opts.Examples, opts.MaxExamples); examples != "" {
+		sections = append(sections, examples)
+	}
+	if text := strings.TrimSpace(opts.Hints); text != "" {
+		sections = append(sections, "Context for this request:\n"+text)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// formatExamples renders the first limit examples (DefaultMaxExamples when
+// limit is not positive) as a prompt section
+func formatExamples(examples []Example, limit int) string {
+	if limit <= 0 {
+		limit = DefaultMaxExamples
+	}
+	if len(examples) > limit {
+		examples = examples[:limit]
+	}
+	if len(examples) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Examples of questions about this database and the SQL that answers them:")
+	for _, ex := range examples {
+		fmt.Fprintf(&sb, "\n\nQuestion: %s\nSQL:\n%s",
+			strings.TrimSpace(ex.Question), strings.TrimSpace(ex.SQL))
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - System Prompt Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package chat
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestBuildSystemPrompt(t *testing.T) {
+	const base = "You are a helpful PostgreSQL database assistant."
+
+	if got := buildSystemPrompt(context.Background(), base); got != base {
+		t.Errorf("prompt without options = %q, want the base prompt", got)
+	}
+
+	ctx := WithSystemPromptOptions(context.Background(), SystemPromptOptions{
+		Append: "  \n",
+		Hints:  "",
+	})
+	if got := buildSystemPrompt(ctx, base); got != base {
+		t.Errorf("blank options changed the prompt: %q", got)
+	}
+
+	ctx = WithSystemPromptOptions(context.Background(), SystemPromptOptions{
+		PinPostgresDialect: true,
+		Append:             "Amounts are stored in cents.",
+		Hints:              "Soft-deleted rows have deleted_at set.",
+	})
+	got := buildSystemPrompt(ctx, base)
+
+	dialect := strings.Index(got, "Write PostgreSQL SQL only")
+	appended := strings.Index(got, "Amounts are stored in cents.")
+	hints := strings.Index(got, "Soft-deleted rows have deleted_at set.")
+	if !strings.HasPrefix(got, base) || dialect < 0 || appended < 0 || hints < 0 {
+		t.Fatalf("prompt is missing a section:\n%s", got)
+	}
+	if dialect > appended || appended > hints {
+		t.Errorf("sections out of order (dialect %d, append %d, hints %d)", dialect, appended, hints)
+	}
+}
+
+func TestBuildSystemPrompt_Examples(t *testing.T) {
+	examples := []Example{
+		{Question: "Total revenue?", SQL: "SELECT sum(amount_cents) / 100.0 FROM orders"},
+		{Question: "Active customers?", SQL: "SELECT count(*) FROM customers WHERE deleted_at IS NULL"},
+		{Question: "Newest order?", SQL: "SELECT max(created_at) FROM orders"},
+		{Question: "Orders per day?", SQL: "SELECT date_trunc('day', created_at), count(*) FROM orders GROUP BY 1"},
+	}
+
+	got := buildSystemPrompt(WithSystemPromptOptions(context.Background(), SystemPromptOptions{
+		Examples: examples,
+		Hints:    "Only count paid orders.",
+	}), "base")
+	if n := strings.Count(got, "Question: "); n != DefaultMaxExamples {
+		t.Errorf("prompt has %d examples, want the default %d", n, DefaultMaxExamples)
+	}
+	if !strings.Contains(got, "SQL:\nSELECT sum(amount_cents) / 100.0 FROM orders") {
+		t.Errorf("first example missing:\n%s", got)
+	}
+	if strings.Index(got, "Question: ") > strings.Index(got, "Only count paid orders.") {
+		t.Error("examples should come before the request hints")
+	}
+
+	got = buildSystemPrompt(WithSystemPromptOptions(context.Background(), SystemPromptOptions{
+		Examples:    examples,
+		MaxExamples: 1,
+	}), "base")
+	if n := strings.Count(got, "Question: "); n != 1 {
+		t.Errorf("prompt has %d examples, want 1", n)
+	}
+}
+
+func TestValidateExamples(t *testing.T) {
+	valid := []Example{
+		{Question: "Total revenue?", SQL: "SELECT sum(amount) FROM orders"},
+		{Question: "Top customers?", SQL: "WITH t AS (SELECT customer_id, sum(amount) s FROM orders GROUP BY 1) SELECT * FROM t ORDER BY s DESC LIMIT 5"},
+	}
+	if err := ValidateExamples(valid); err != nil {
+		t.Errorf("ValidateExamples(valid) = %v", err)
+	}
+
+	for _, ex := range []Example{
+		{Question: "", SQL: "SELECT 1"},
+		{Question: "Clear orders", SQL: "DELETE FROM orders"},
+		{Question: "Two statements", SQL: "SELECT 1; SELECT 2"},
+		{Question: "No SQL", SQL: ""},
+	} {
+		if err := ValidateExamples([]Example{ex}); err == nil {
+			t.Errorf("ValidateExamples(%+v) accepted an invalid example", ex)
+		}
+	}
+}
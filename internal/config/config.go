@@ -12,9 +12,13 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -36,6 +40,28 @@ type Config struct {
 	// Knowledgebase configuration
 	Knowledgebase KnowledgebaseConfig `yaml:"knowledgebase"`
 
+	// Similarity search safety limits
+	SimilaritySearch SimilaritySearchConfig `yaml:"similarity_search"`
+
+	// execute_explain tool settings
+	Explain ExplainConfig `yaml:"explain"`
+
+	// get_table_sample tool settings
+	TableSample TableSampleConfig `yaml:"table_sample"`
+
+	// Sanitization of tool error messages returned to clients
+	ErrorSanitization ErrorSanitizationConfig `yaml:"error_sanitization"`
+
+	// How SQL text appears in server logs
+	QueryLogging QueryLoggingConfig `yaml:"query_logging"`
+
+	// Audit log of every SQL statement the server runs
+	Audit AuditConfig `yaml:"audit"`
+
+	// Minimum level of server log entries: debug, info, warn or error
+	// (default: error). PGEDGE_MCP_LOG_LEVEL takes priority.
+	LogLevel string `yaml:"log_level"`
+
 	// Built-in tools, resources, and prompts configuration
 	Builtins BuiltinsConfig `yaml:"builtins"`
 
@@ -60,19 +86,65 @@ type BuiltinsConfig struct {
 // All tools are enabled by default
 // Note: read_resource tool is always enabled as it's used to list resources
 type ToolsConfig struct {
-	QueryDatabase       *bool `yaml:"query_database"`       // Execute SQL queries (default: true)
-	GetSchemaInfo       *bool `yaml:"get_schema_info"`      // Get detailed schema information (default: true)
-	SimilaritySearch    *bool `yaml:"similarity_search"`    // Vector similarity search (default: true)
-	ExecuteExplain      *bool `yaml:"execute_explain"`      // Execute EXPLAIN queries (default: true)
-	GenerateEmbedding   *bool `yaml:"generate_embedding"`   // Generate text embeddings (default: true)
-	SearchKnowledgebase *bool `yaml:"search_knowledgebase"` // Search knowledgebase (default: true)
-	CountRows           *bool `yaml:"count_rows"`           // Count table rows (default: true)
+	QueryDatabase         *bool `yaml:"query_database"`          // Execute SQL queries (default: true)
+	GetSchemaInfo         *bool `yaml:"get_schema_info"`         // Get detailed schema information (default: true)
+	SimilaritySearch      *bool `yaml:"similarity_search"`       // Vector similarity search (default: true)
+	ExecuteExplain        *bool `yaml:"execute_explain"`         // Execute EXPLAIN queries (default: true)
+	GenerateEmbedding     *bool `yaml:"generate_embedding"`      // Generate text embeddings (default: true)
+	SearchKnowledgebase   *bool `yaml:"search_knowledgebase"`    // Search knowledgebase (default: true)
+	CountRows             *bool `yaml:"count_rows"`              // Count table rows (default: true)
+	TempFileUsage         *bool `yaml:"temp_file_usage"`         // Report temp file usage (default: true)
+	CheckVectorIndexes    *bool `yaml:"check_vector_indexes"`    // Check vector column index coverage (default: true)
+	LockWaitGraph         *bool `yaml:"lock_wait_graph"`         // Show lock waits-for graph (default: true)
+	IndexEfficiency       *bool `yaml:"index_efficiency"`        // Report index usage efficiency (default: true)
+	FindInvalidIndexes    *bool `yaml:"find_invalid_indexes"`    // Find invalid or unready indexes (default: true)
+	GetTableSample        *bool `yaml:"get_table_sample"`        // Preview rows from a table (default: true)
+	BackupReadiness       *bool `yaml:"backup_readiness"`        // Check archiving and backup configuration (default: true)
+	DescribeSchema        *bool `yaml:"describe_schema"`         // Describe all objects in one schema (default: true)
+	RelationLayout        *bool `yaml:"relation_layout"`         // Table layout, free space and fragmentation (default: true)
+	TokenUsage            *bool `yaml:"token_usage"`             // Per-token tool-call summary for admins (default: false)
+	PartitioningAdvisor   *bool `yaml:"partitioning_advisor"`    // Suggest range partitioning for large tables (default: true)
+	FindLargeValues       *bool `yaml:"find_large_values"`       // Find the largest values in a column (default: true)
+	LongRunningQueries    *bool `yaml:"long_running_queries"`    // List and cancel/terminate long-running queries (default: true)
+	RowcountAccuracy      *bool `yaml:"rowcount_accuracy"`       // Compare planner row estimates with exact counts (default: true)
+	SuggestIndexes        *bool `yaml:"suggest_indexes"`         // Suggest candidate indexes from scan statistics (default: true)
+	RefreshMetadata       *bool `yaml:"refresh_metadata"`        // Reload schema metadata without a restart (default: true)
+	TableMaintenance      *bool `yaml:"table_maintenance"`       // Recommend and run VACUUM/REINDEX (default: true)
+	DescribeTable         *bool `yaml:"describe_table"`          // Full detail of one table, like psql's \d+ (default: true)
+	RawSQLEnabled         *bool `yaml:"raw_sql_enabled"`         // execute_sql tool for hand-written read-only SQL (default: false)
+	SignalBackends        *bool `yaml:"signal_backends"`         // Let long_running_queries cancel and terminate sessions (default: false)
+	TestConnection        *bool `yaml:"test_connection"`         // Connect and ping a database without switching to it (default: true)
+	ShowCurrentConnection *bool `yaml:"show_current_connection"` // Report the database the tools are using (default: true)
+	ExportSchema          *bool `yaml:"export_schema"`           // Export the schema as ERD JSON or Graphviz DOT (default: true)
+	ListDatabases         *bool `yaml:"list_databases"`          // List the databases on the connected server (default: true)
+	ListRoles             *bool `yaml:"list_roles"`              // List roles and table privileges (default: true)
+
+	// Enabled, when set, limits the tools to the ones listed; Disabled
+	// removes tools. Both apply on top of the per-tool settings above.
+	Enabled  []string `yaml:"enabled"`
+	Disabled []string `yaml:"disabled"`
+}
+
+// builtinToolNames lists the tool names accepted in tools.enabled and
+// tools.disabled
+var builtinToolNames = []string{
+	"query_database", "execute_sql", "get_schema_info", "similarity_search",
+	"execute_explain", "generate_embedding", "search_knowledgebase",
+	"count_rows", "temp_file_usage", "check_vector_indexes", "lock_wait_graph",
+	"index_efficiency", "find_invalid_indexes", "get_table_sample",
+	"backup_readiness", "describe_schema", "describe_table", "relation_layout",
+	"token_usage", "partitioning_advisor", "find_large_values",
+	"long_running_queries", "rowcount_accuracy", "suggest_indexes",
+	"refresh_metadata", "table_maintenance", "test_connection", "show_current_connection",
+	"export_schema", "list_databases", "list_roles",
 }
 
 // ResourcesConfig holds configuration for enabling/disabling built-in resources
 // All resources are enabled by default
 type ResourcesConfig struct {
-	SystemInfo *bool `yaml:"system_info"` // pg://system_info (default: true)
+	SystemInfo     *bool `yaml:"system_info"`     // pg://system_info (default: true)
+	StatStatements *bool `yaml:"stat_statements"` // pg://stat_statements (default: true)
+	BlockingLocks  *bool `yaml:"blocking_locks"`  // pg://blocking_locks (default: true)
 }
 
 // PromptsConfig holds configuration for enabling/disabling built-in prompts
@@ -82,10 +154,27 @@ type PromptsConfig struct {
 	SetupSemanticSearch *bool `yaml:"setup_semantic_search"` // setup-semantic-search prompt (default: true)
 	DiagnoseQueryIssue  *bool `yaml:"diagnose_query_issue"`  // diagnose-query-issue prompt (default: true)
 	DesignSchema        *bool `yaml:"design_schema"`         // design-schema prompt (default: true)
+	SummarizeTable      *bool `yaml:"summarize_table"`       // summarize-table prompt (default: true)
+	FindSlowQueries     *bool `yaml:"find_slow_queries"`     // find-slow-queries prompt (default: true)
+	DiagnoseLocks       *bool `yaml:"diagnose_locks"`        // diagnose-locks prompt (default: true)
+}
+
+// IsSignalBackendsEnabled returns whether long_running_queries may cancel
+// and terminate sessions. That changes the state of the database server,
+// so it must be enabled explicitly.
+func (c *ToolsConfig) IsSignalBackendsEnabled() bool {
+	return c.SignalBackends != nil && *c.SignalBackends
 }
 
 // IsToolEnabled returns true if the specified tool is enabled (defaults to true if not set)
 func (c *ToolsConfig) IsToolEnabled(toolName string) bool {
+	if slices.Contains(c.Disabled, toolName) {
+		return false
+	}
+	if len(c.Enabled) > 0 && !slices.Contains(c.Enabled, toolName) {
+		return false
+	}
+
 	switch toolName {
 	case "query_database":
 		return c.QueryDatabase == nil || *c.QueryDatabase
@@ -101,6 +190,58 @@ func (c *ToolsConfig) IsToolEnabled(toolName string) bool {
 		return c.SearchKnowledgebase == nil || *c.SearchKnowledgebase
 	case "count_rows":
 		return c.CountRows == nil || *c.CountRows
+	case "temp_file_usage":
+		return c.TempFileUsage == nil || *c.TempFileUsage
+	case "check_vector_indexes":
+		return c.CheckVectorIndexes == nil || *c.CheckVectorIndexes
+	case "lock_wait_graph":
+		return c.LockWaitGraph == nil || *c.LockWaitGraph
+	case "index_efficiency":
+		return c.IndexEfficiency == nil || *c.IndexEfficiency
+	case "find_invalid_indexes":
+		return c.FindInvalidIndexes == nil || *c.FindInvalidIndexes
+	case "get_table_sample":
+		return c.GetTableSample == nil || *c.GetTableSample
+	case "backup_readiness":
+		return c.BackupReadiness == nil || *c.BackupReadiness
+	case "describe_schema":
+		return c.DescribeSchema == nil || *c.DescribeSchema
+	case "relation_layout":
+		return c.RelationLayout == nil || *c.RelationLayout
+	case "execute_sql":
+		// Runs caller-written SQL with no natural-language layer in front
+		// of it, so it must be enabled explicitly
+		return c.RawSQLEnabled != nil && *c.RawSQLEnabled
+	case "token_usage":
+		// Exposes every token's usage to any authenticated caller, so it
+		// must be enabled explicitly
+		return c.TokenUsage != nil && *c.TokenUsage
+	case "partitioning_advisor":
+		return c.PartitioningAdvisor == nil || *c.PartitioningAdvisor
+	case "find_large_values":
+		return c.FindLargeValues == nil || *c.FindLargeValues
+	case "long_running_queries":
+		return c.LongRunningQueries == nil || *c.LongRunningQueries
+	case "rowcount_accuracy":
+		return c.RowcountAccuracy == nil || *c.RowcountAccuracy
+	case "suggest_indexes":
+		return c.SuggestIndexes == nil || *c.SuggestIndexes
+	case "refresh_metadata":
+		return c.RefreshMetadata == nil || *c.RefreshMetadata
+	case "table_maintenance":
+		return c.TableMaintenance == nil || *c.TableMaintenance
+	case "describe_table":
+		return c.DescribeTable == nil || *c.DescribeTable
+	case "test_connection":
+		return c.TestConnection == nil || *c.TestConnection
+	case "show_current_connection":
+		return c.ShowCurrentConnection == nil || *c.ShowCurrentConnection
+	case "export_schema":
+		return c.ExportSchema == nil || *c.ExportSchema
+	case "list_databases":
+		return c.ListDatabases == nil || *c.ListDatabases
+	case "list_roles":
+		return c.ListRoles == nil || *c.ListRoles
 	default:
 		return true // Unknown tools are enabled by default
 	}
@@ -111,6 +252,10 @@ func (c *ResourcesConfig) IsResourceEnabled(resourceURI string) bool {
 	switch resourceURI {
 	case "pg://system_info":
 		return c.SystemInfo == nil || *c.SystemInfo
+	case "pg://stat_statements":
+		return c.StatStatements == nil || *c.StatStatements
+	case "pg://blocking_locks":
+		return c.BlockingLocks == nil || *c.BlockingLocks
 	default:
 		return true // Unknown resources are enabled by default
 	}
@@ -127,6 +272,12 @@ func (c *PromptsConfig) IsPromptEnabled(promptName string) bool {
 		return c.DiagnoseQueryIssue == nil || *c.DiagnoseQueryIssue
 	case "design-schema":
 		return c.DesignSchema == nil || *c.DesignSchema
+	case "summarize-table":
+		return c.SummarizeTable == nil || *c.SummarizeTable
+	case "find-slow-queries":
+		return c.FindSlowQueries == nil || *c.FindSlowQueries
+	case "diagnose-locks":
+		return c.DiagnoseLocks == nil || *c.DiagnoseLocks
 	default:
 		return true // Unknown prompts are enabled by default
 	}
@@ -138,6 +289,76 @@ type HTTPConfig struct {
 	Address string     `yaml:"address"`
 	TLS     TLSConfig  `yaml:"tls"`
 	Auth    AuthConfig `yaml:"auth"`
+
+	// SocketPath, if set, makes the server listen on this Unix domain socket
+	// (owner-only permissions) instead of Address
+	SocketPath string `yaml:"socket_path"`
+
+	// CORS lets browser clients on other origins call the server
+	CORS CORSConfig `yaml:"cors"`
+
+	// MetricsEnabled serves Prometheus metrics on /metrics (no authentication)
+	MetricsEnabled bool `yaml:"metrics_enabled"`
+
+	// StreamResults sends large tool results to the client as they are
+	// read from the database instead of collecting them first
+	StreamResults bool `yaml:"stream_results"`
+
+	// Timeouts, as durations such as "30s" or "5m"; "0" disables a timeout
+	ReadTimeout        string `yaml:"read_timeout"`         // Max time to read a request, including the body (default: 30s)
+	WriteTimeout       string `yaml:"write_timeout"`        // Max time to write a response (default: 5m)
+	IdleTimeout        string `yaml:"idle_timeout"`         // Max time a keep-alive connection may sit idle (default: 2m)
+	SessionIdleTimeout string `yaml:"session_idle_timeout"` // Close a token's database connections after this long unused (default: 30m)
+	ShutdownTimeout    string `yaml:"shutdown_timeout"`     // Max time to wait for requests in progress on SIGTERM/SIGINT (default: 30s)
+}
+
+// Default HTTP timeouts, used when the corresponding setting is not configured
+const (
+	DefaultHTTPReadTimeout        = 30 * time.Second
+	DefaultHTTPWriteTimeout       = 5 * time.Minute
+	DefaultHTTPIdleTimeout        = 2 * time.Minute
+	DefaultHTTPSessionIdleTimeout = 30 * time.Minute
+	DefaultHTTPShutdownTimeout    = 30 * time.Second
+)
+
+// GetReadTimeout returns the HTTP server read timeout
+func (c *HTTPConfig) GetReadTimeout() time.Duration {
+	return parseDurationOrDefault(c.ReadTimeout, DefaultHTTPReadTimeout)
+}
+
+// GetWriteTimeout returns the HTTP server write timeout
+func (c *HTTPConfig) GetWriteTimeout() time.Duration {
+	return parseDurationOrDefault(c.WriteTimeout, DefaultHTTPWriteTimeout)
+}
+
+// GetIdleTimeout returns how long keep-alive connections may stay idle
+func (c *HTTPConfig) GetIdleTimeout() time.Duration {
+	return parseDurationOrDefault(c.IdleTimeout, DefaultHTTPIdleTimeout)
+}
+
+// GetSessionIdleTimeout returns how long a token's database connections may
+// go unused before they are closed. Zero disables the idle session reaper.
+func (c *HTTPConfig) GetSessionIdleTimeout() time.Duration {
+	return parseDurationOrDefault(c.SessionIdleTimeout, DefaultHTTPSessionIdleTimeout)
+}
+
+// GetShutdownTimeout returns how long shutdown waits for requests in
+// progress before cancelling them. Zero cancels them immediately.
+func (c *HTTPConfig) GetShutdownTimeout() time.Duration {
+	return parseDurationOrDefault(c.ShutdownTimeout, DefaultHTTPShutdownTimeout)
+}
+
+// parseDurationOrDefault parses a duration setting, falling back to def if it
+// is unset or invalid (validateConfig rejects invalid values at load time)
+func parseDurationOrDefault(value string, def time.Duration) time.Duration {
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return def
+	}
+	return d
 }
 
 // AuthConfig holds authentication settings
@@ -148,6 +369,8 @@ type AuthConfig struct {
 	MaxFailedAttemptsBeforeLockout int    `yaml:"max_failed_attempts_before_lockout"` // Number of failed login attempts before account lockout (0 = disabled)
 	RateLimitWindowMinutes         int    `yaml:"rate_limit_window_minutes"`          // Time window in minutes for rate limiting (default: 15)
 	RateLimitMaxAttempts           int    `yaml:"rate_limit_max_attempts"`            // Maximum failed attempts per IP in the time window (default: 10)
+	RateLimit                      int    `yaml:"rate_limit"`                         // MCP requests per minute allowed to each token (default: 0, unlimited)
+	UnusedTokenWarningDays         int    `yaml:"unused_token_warning_days"`          // Warn at startup about tokens unused for this many days (default: 0, disabled)
 }
 
 // TLSConfig holds TLS/HTTPS settings
@@ -158,6 +381,15 @@ type TLSConfig struct {
 	ChainFile string `yaml:"chain_file"`
 }
 
+// CORSConfig holds cross-origin settings for browser clients. With no
+// allowed origins, no CORS headers are sent and only same-origin pages can
+// call the server.
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins"`   // Origins such as "https://app.example.com", or "*" for any
+	AllowedMethods   []string `yaml:"allowed_methods"`   // Methods allowed in preflight (default: GET, POST, OPTIONS)
+	AllowCredentials bool     `yaml:"allow_credentials"` // Allow cookies and Authorization headers from the browser
+}
+
 // NamedDatabaseConfig holds named database connection settings with access control
 type NamedDatabaseConfig struct {
 	Name             string   `yaml:"name"`                         // Unique name for this database connection (required)
@@ -167,27 +399,193 @@ type NamedDatabaseConfig struct {
 	User             string   `yaml:"user"`                         // Database user (required)
 	Password         string   `yaml:"password"`                     // Database password (optional, will use PGEDGE_DB_PASSWORD env var or .pgpass if not set)
 	SSLMode          string   `yaml:"sslmode"`                      // SSL mode: disable, require, verify-ca, verify-full (default: prefer)
+	ConnectionString string   `yaml:"connection_string,omitempty"`  // postgres:// URL or keyword/value string supplying the fields above
 	AvailableToUsers []string `yaml:"available_to_users,omitempty"` // List of usernames allowed to access this database (empty = all users)
 
+	// Token authentication settings
+	AuthMethod string `yaml:"auth_method,omitempty"` // How to authenticate: password, aws-iam or azure-ad (default: password)
+	AWSRegion  string `yaml:"aws_region,omitempty"`  // Region for aws-iam tokens (default: AWS_REGION or AWS_DEFAULT_REGION env var)
+
 	// Connection pool settings
 	PoolMaxConns        int    `yaml:"pool_max_conns"`          // Maximum number of connections (default: 4)
 	PoolMinConns        int    `yaml:"pool_min_conns"`          // Minimum number of connections (default: 0)
 	PoolMaxConnIdleTime string `yaml:"pool_max_conn_idle_time"` // Max time a connection can be idle before being closed (default: 30m)
+	PoolMaxConnLifetime string `yaml:"pool_max_conn_lifetime"`  // Max age of a connection before it is closed and replaced (default: 1h)
+	PoolingMode         string `yaml:"pooling_mode,omitempty"`  // Pooling mode of any pooler in front of the database: session or transaction (default: session)
+
+	// Session safety settings
+	ReadOnlySession *bool `yaml:"read_only_session,omitempty"` // Open connections with default_transaction_read_only=on (default: true)
+	ReadOnly        *bool `yaml:"read_only,omitempty"`         // Reject non-SELECT/WITH/EXPLAIN and multi-statement SQL in query_database (default: true)
+	ReplicaOnly     bool  `yaml:"replica_only,omitempty"`      // Refuse analytical queries unless the server is a standby (default: false)
+
+	// Result limits
+	MaxResultRows int    `yaml:"max_result_rows,omitempty"` // Maximum rows query_database collects per call (default: 1000)
+	QueryTimeout  string `yaml:"query_timeout,omitempty"`   // Statement timeout for query tools, e.g. "30s" (default: 30s)
+
+	MaxQueryTimeout string `yaml:"max_query_timeout,omitempty"` // Largest per-call timeout_seconds override (default: 5m, or query_timeout if longer)
+
+	// Query cost guard
+	MaxQueryCost    float64 `yaml:"max_query_cost,omitempty"`    // Estimated plan cost above which query_database does not run a query (default: 0, no limit)
+	QueryCostAction string  `yaml:"query_cost_action,omitempty"` // What to do above max_query_cost: block or warn (default: block)
+
+	// Schema context limits
+	MaxSchemaContextBytes int `yaml:"max_schema_context_bytes,omitempty"` // Maximum bytes of table rows get_schema_info returns per call (default: 0, unlimited)
+
+	// Sensitive column redaction
+	RedactColumns       []string `yaml:"redact_columns,omitempty"`        // Column patterns (column, table.column or schema.table.column, with * and ?) masked in query results
+	HideRedactedColumns bool     `yaml:"hide_redacted_columns,omitempty"` // Also leave redacted columns out of the schema metadata (default: false)
+
+	// Metadata refresh
+	MetadataRefreshInterval string `yaml:"metadata_refresh_interval,omitempty"` // How often to reload schema metadata in the background, e.g. "10m" (default: disabled)
+}
+
+// Pooling modes for NamedDatabaseConfig.PoolingMode
+const (
+	PoolingModeSession     = "session"     // Direct connections or a session-mode pooler
+	PoolingModeTransaction = "transaction" // A transaction-mode pooler such as PgBouncer
+)
+
+// Authentication methods for NamedDatabaseConfig.AuthMethod
+const (
+	AuthMethodPassword = "password" // Static password, PGEDGE_DB_PASSWORD or .pgpass
+	AuthMethodAWSIAM   = "aws-iam"  // Short-lived RDS/Aurora IAM authentication tokens
+	AuthMethodAzureAD  = "azure-ad" // Microsoft Entra ID (Azure AD) managed identity tokens
+)
+
+// Actions for NamedDatabaseConfig.QueryCostAction
+const (
+	QueryCostActionBlock = "block" // Refuse to run the query and return its plan
+	QueryCostActionWarn  = "warn"  // Run the query with a warning ahead of the results
+)
+
+// DefaultMaxResultRows is the query_database row cap used when
+// max_result_rows is not configured
+const DefaultMaxResultRows = 1000
+
+// DefaultQueryTimeout is the statement timeout used by query tools when
+// query_timeout is not configured
+const DefaultQueryTimeout = 30 * time.Second
+
+// GetQueryTimeout returns the statement timeout for query tools, falling back
+// to DefaultQueryTimeout if not set or invalid (validateConfig rejects
+// invalid values at load time).
+func (cfg *NamedDatabaseConfig) GetQueryTimeout() time.Duration {
+	if cfg.QueryTimeout == "" {
+		return DefaultQueryTimeout
+	}
+	timeout, err := time.ParseDuration(cfg.QueryTimeout)
+	if err != nil || timeout <= 0 {
+		return DefaultQueryTimeout
+	}
+	return timeout
+}
+
+// DefaultMaxQueryTimeout is the largest per-call timeout override query
+// tools accept when max_query_timeout is not configured
+const DefaultMaxQueryTimeout = 5 * time.Minute
+
+// GetMaxQueryTimeout returns the largest timeout a tool call may ask for.
+// If max_query_timeout is not set or invalid, it is DefaultMaxQueryTimeout,
+// or the query timeout when that is longer.
+func (cfg *NamedDatabaseConfig) GetMaxQueryTimeout() time.Duration {
+	if cfg.MaxQueryTimeout != "" {
+		timeout, err := time.ParseDuration(cfg.MaxQueryTimeout)
+		if err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+	return max(DefaultMaxQueryTimeout, cfg.GetQueryTimeout())
+}
+
+// GetMetadataRefreshInterval returns how often schema metadata should be
+// reloaded in the background, or 0 if periodic refresh is disabled or the
+// value is invalid (validateConfig rejects invalid values at load time).
+func (cfg *NamedDatabaseConfig) GetMetadataRefreshInterval() time.Duration {
+	if cfg.MetadataRefreshInterval == "" {
+		return 0
+	}
+	interval, err := time.ParseDuration(cfg.MetadataRefreshInterval)
+	if err != nil || interval <= 0 {
+		return 0
+	}
+	return interval
+}
+
+// GetMaxResultRows returns the maximum number of rows query_database collects
+// per call, falling back to DefaultMaxResultRows if not set.
+func (cfg *NamedDatabaseConfig) GetMaxResultRows() int {
+	if cfg.MaxResultRows <= 0 {
+		return DefaultMaxResultRows
+	}
+	return cfg.MaxResultRows
+}
+
+// GetQueryCostAction returns what query_database does with a query whose
+// estimated cost is above max_query_cost, falling back to
+// QueryCostActionBlock if not set.
+func (cfg *NamedDatabaseConfig) GetQueryCostAction() string {
+	if cfg.QueryCostAction == "" {
+		return QueryCostActionBlock
+	}
+	return cfg.QueryCostAction
+}
+
+// IsReadOnly returns whether query_database should reject SQL that isn't a
+// single SELECT, WITH or EXPLAIN statement. Defaults to true if not specified.
+func (cfg *NamedDatabaseConfig) IsReadOnly() bool {
+	if cfg.ReadOnly == nil {
+		return true
+	}
+	return *cfg.ReadOnly
+}
+
+// UsesTransactionPooling returns whether the database is reached through a
+// transaction-mode pooler, where session-level state can't be relied on
+// because consecutive transactions may run on different server connections.
+func (cfg *NamedDatabaseConfig) UsesTransactionPooling() bool {
+	return cfg.PoolingMode == PoolingModeTransaction
+}
+
+// UsesTokenAuth returns whether connections authenticate with short-lived
+// tokens obtained before each connection rather than a static password.
+func (cfg *NamedDatabaseConfig) UsesTokenAuth() bool {
+	return cfg.AuthMethod == AuthMethodAWSIAM || cfg.AuthMethod == AuthMethodAzureAD
+}
+
+// IsReadOnlySession returns whether connections should be opened with
+// default_transaction_read_only=on. Defaults to true if not specified.
+func (cfg *NamedDatabaseConfig) IsReadOnlySession() bool {
+	if cfg.ReadOnlySession == nil {
+		return true
+	}
+	return *cfg.ReadOnlySession
 }
 
 // BuildConnectionString creates a PostgreSQL connection string from NamedDatabaseConfig
 // If password is not set, pgx will automatically look it up from .pgpass file
 func (cfg *NamedDatabaseConfig) BuildConnectionString() string {
-	// Build connection string components
-	connStr := fmt.Sprintf("postgres://%s", cfg.User)
-
 	// Add password only if explicitly set
 	// If not set, pgx will use .pgpass file automatically
 	if cfg.Password != "" {
-		connStr += ":" + cfg.Password
+		return cfg.buildConnectionString(url.UserPassword(cfg.User, cfg.Password).String())
+	}
+	return cfg.buildConnectionString(url.User(cfg.User).String())
+}
+
+// RedactedConnectionString returns the connection string with the password
+// replaced by "***", for display
+func (cfg *NamedDatabaseConfig) RedactedConnectionString() string {
+	userInfo := url.User(cfg.User).String()
+	if cfg.Password != "" {
+		userInfo += ":***"
 	}
+	return cfg.buildConnectionString(userInfo)
+}
 
-	connStr += fmt.Sprintf("@%s:%d/%s", cfg.Host, cfg.Port, cfg.Database)
+// buildConnectionString builds the connection string with the given
+// user info, which must already be escaped for use in a URL
+func (cfg *NamedDatabaseConfig) buildConnectionString(userInfo string) string {
+	connStr := fmt.Sprintf("postgres://%s@%s:%d/%s", userInfo, cfg.Host, cfg.Port, cfg.Database)
 
 	// Add SSL mode
 	if cfg.SSLMode != "" {
@@ -207,20 +605,54 @@ type EmbeddingConfig struct {
 	OpenAIAPIKey     string `yaml:"openai_api_key"`      // API key for OpenAI (direct - discouraged, use api_key_file or env var)
 	OpenAIAPIKeyFile string `yaml:"openai_api_key_file"` // Path to file containing OpenAI API key
 	OllamaURL        string `yaml:"ollama_url"`          // URL for Ollama service (default: http://localhost:11434)
+
+	// TableModels maps "schema.table.column" vector columns to the model
+	// that produced them, for columns not made with the default model.
+	// API keys and the Ollama URL are shared with the default provider.
+	TableModels map[string]EmbeddingModelConfig `yaml:"table_models"`
+}
+
+// EmbeddingModelConfig names the embedding provider and model for a vector
+// column
+type EmbeddingModelConfig struct {
+	Provider string `yaml:"provider"` // "voyage", "openai", or "ollama"
+	Model    string `yaml:"model"`    // Provider-specific model name
+}
+
+// ModelFor returns the embedding provider and model for a vector column,
+// given as "schema.table.column", falling back to the default provider and
+// model when the column has no entry in table_models
+func (e *EmbeddingConfig) ModelFor(column string) EmbeddingModelConfig {
+	if m, ok := e.TableModels[column]; ok {
+		return m
+	}
+	return EmbeddingModelConfig{Provider: e.Provider, Model: e.Model}
 }
 
 // LLMConfig holds LLM configuration for web client chat proxy
 type LLMConfig struct {
-	Enabled             bool    `yaml:"enabled"`                // Whether LLM proxy is enabled (default: false)
-	Provider            string  `yaml:"provider"`               // "anthropic", "openai", or "ollama"
-	Model               string  `yaml:"model"`                  // Provider-specific model name
-	AnthropicAPIKey     string  `yaml:"anthropic_api_key"`      // API key for Anthropic (direct - discouraged, use api_key_file or env var instead)
-	AnthropicAPIKeyFile string  `yaml:"anthropic_api_key_file"` // Path to file containing Anthropic API key
-	OpenAIAPIKey        string  `yaml:"openai_api_key"`         // API key for OpenAI (direct - discouraged, use api_key_file or env var instead)
-	OpenAIAPIKeyFile    string  `yaml:"openai_api_key_file"`    // Path to file containing OpenAI API key
-	OllamaURL           string  `yaml:"ollama_url"`             // URL for Ollama service (default: http://localhost:11434)
-	MaxTokens           int     `yaml:"max_tokens"`             // Maximum tokens for LLM response (default: 4096)
-	Temperature         float64 `yaml:"temperature"`            // Temperature for LLM sampling (default: 0.7)
+	Enabled             bool         `yaml:"enabled"`                // Whether LLM proxy is enabled (default: false)
+	Provider            string       `yaml:"provider"`               // "anthropic", "openai", or "ollama"
+	Model               string       `yaml:"model"`                  // Provider-specific model name
+	AnthropicAPIKey     string       `yaml:"anthropic_api_key"`      // API key for Anthropic (direct - discouraged, use api_key_file or env var instead)
+	AnthropicAPIKeyFile string       `yaml:"anthropic_api_key_file"` // Path to file containing Anthropic API key
+	OpenAIAPIKey        string       `yaml:"openai_api_key"`         // API key for OpenAI (direct - discouraged, use api_key_file or env var instead)
+	OpenAIAPIKeyFile    string       `yaml:"openai_api_key_file"`    // Path to file containing OpenAI API key
+	OpenAIBaseURL       string       `yaml:"openai_base_url"`        // OpenAI-compatible endpoint (default: https://api.openai.com/v1)
+	OllamaURL           string       `yaml:"ollama_url"`             // URL for Ollama service (default: http://localhost:11434)
+	MaxTokens           int          `yaml:"max_tokens"`             // Maximum tokens for LLM response (default: 4096)
+	Temperature         float64      `yaml:"temperature"`            // Temperature for LLM sampling (default: 0.7)
+	SystemPromptAppend  string       `yaml:"system_prompt_append"`   // Domain context appended to the system prompt of every chat request
+	PinPostgresDialect  bool         `yaml:"pin_postgres_dialect"`   // Add PostgreSQL dialect instructions to the system prompt (default: false)
+	Examples            []LLMExample `yaml:"examples"`               // Few-shot question/SQL examples for the system prompt
+	MaxExamples         int          `yaml:"max_examples"`           // How many examples to include (default: 3)
+}
+
+// LLMExample is a question and the read-only SQL that answers it, shown to
+// the LLM as a few-shot demonstration
+type LLMExample struct {
+	Question string `yaml:"question"`
+	SQL      string `yaml:"sql"`
 }
 
 // KnowledgebaseConfig holds knowledgebase configuration
@@ -238,6 +670,133 @@ type KnowledgebaseConfig struct {
 	EmbeddingOllamaURL        string `yaml:"embedding_ollama_url"`          // URL for Ollama service (default: http://localhost:11434)
 }
 
+// SimilaritySearchConfig holds safety limits for the similarity_search tool
+type SimilaritySearchConfig struct {
+	MaxTopN          int `yaml:"max_top_n"`           // Upper bound for top_n (default: 100)
+	MaxTopNUnindexed int `yaml:"max_top_n_unindexed"` // Stricter bound when the vector column has no ANN index (default: 20)
+
+	// KeywordWeight is the default share of the hybrid search score given
+	// to the full-text rank, from 0 (vector only) to 1 (keyword only)
+	KeywordWeight float64 `yaml:"keyword_weight"` // default: 0.3
+
+	// TextSearchConfig is the text search configuration hybrid search
+	// matches and ranks text columns with. A GIN index built on the same
+	// configuration and columns serves the keyword half of the search.
+	TextSearchConfig string `yaml:"text_search_config"` // default: english
+}
+
+// DefaultTextSearchConfig is the text search configuration used by hybrid
+// similarity_search when similarity_search.text_search_config is not set
+const DefaultTextSearchConfig = "english"
+
+// GetTextSearchConfig returns the text search configuration for hybrid
+// search, falling back to DefaultTextSearchConfig if not set.
+func (cfg *SimilaritySearchConfig) GetTextSearchConfig() string {
+	if cfg.TextSearchConfig == "" {
+		return DefaultTextSearchConfig
+	}
+	return cfg.TextSearchConfig
+}
+
+// ExplainConfig holds settings for the execute_explain tool
+type ExplainConfig struct {
+	AnalyzeAllowed *bool    `yaml:"analyze_allowed"`  // Allow EXPLAIN ANALYZE, which executes the query (default: true)
+	MaxAnalyzeCost *float64 `yaml:"max_analyze_cost"` // Estimated plan cost above which ANALYZE is skipped unless forced; 0 disables (default: 1000000)
+}
+
+// DefaultMaxAnalyzeCost is the planner cost ceiling for EXPLAIN ANALYZE when
+// max_analyze_cost is not configured
+const DefaultMaxAnalyzeCost = 1000000.0
+
+// IsAnalyzeAllowed returns whether execute_explain may run EXPLAIN ANALYZE.
+// Defaults to true if not specified.
+func (c *ExplainConfig) IsAnalyzeAllowed() bool {
+	return c.AnalyzeAllowed == nil || *c.AnalyzeAllowed
+}
+
+// GetMaxAnalyzeCost returns the estimated cost above which execute_explain
+// skips ANALYZE, or 0 if there is no ceiling. Defaults to
+// DefaultMaxAnalyzeCost if not specified.
+func (c *ExplainConfig) GetMaxAnalyzeCost() float64 {
+	if c.MaxAnalyzeCost == nil {
+		return DefaultMaxAnalyzeCost
+	}
+	return *c.MaxAnalyzeCost
+}
+
+// Table sampling methods for get_table_sample
+const (
+	TableSampleAuto      = "auto"      // random() for small tables, TABLESAMPLE SYSTEM for large ones
+	TableSampleLimit     = "limit"     // First rows read, no randomness
+	TableSampleRandom    = "random"    // ORDER BY random(); exact but scans and sorts the table
+	TableSampleSystem    = "system"    // TABLESAMPLE SYSTEM; random pages, fast
+	TableSampleBernoulli = "bernoulli" // TABLESAMPLE BERNOULLI; random rows, reads every page
+)
+
+// TableSampleConfig holds settings for the get_table_sample tool
+type TableSampleConfig struct {
+	Method         string `yaml:"method"`           // Default sampling method (default: auto)
+	LargeTableRows *int64 `yaml:"large_table_rows"` // Estimated rows above which a table counts as large (default: 100000)
+}
+
+// DefaultLargeTableRows is the estimated row count above which
+// get_table_sample treats a table as large
+const DefaultLargeTableRows = 100000
+
+// GetMethod returns the default sampling method. Defaults to
+// TableSampleAuto if not specified.
+func (c *TableSampleConfig) GetMethod() string {
+	if c.Method == "" {
+		return TableSampleAuto
+	}
+	return c.Method
+}
+
+// GetLargeTableRows returns the estimated row count above which a table
+// counts as large. Defaults to DefaultLargeTableRows if not specified.
+func (c *TableSampleConfig) GetLargeTableRows() int64 {
+	if c.LargeTableRows == nil {
+		return DefaultLargeTableRows
+	}
+	return *c.LargeTableRows
+}
+
+// Error sanitization modes
+const (
+	ErrorSanitizationOff      = "off"      // Return tool errors unchanged
+	ErrorSanitizationStandard = "standard" // Redact data values, paths and credentials
+	ErrorSanitizationStrict   = "strict"   // Return only a generic message and correlation ID
+)
+
+// ErrorSanitizationConfig controls how much detail tool errors expose to
+// clients. Full error text is always logged server-side with a correlation ID.
+type ErrorSanitizationConfig struct {
+	Mode string `yaml:"mode"` // off, standard or strict (default: standard)
+}
+
+// Query logging modes
+const (
+	QueryLoggingFull       = "full"       // Log SQL as written
+	QueryLoggingNormalized = "normalized" // Replace literals with $n placeholders
+	QueryLoggingNone       = "none"       // Never log SQL text
+)
+
+// QueryLoggingConfig controls how SQL text appears in server logs. Queries
+// are always executed as written.
+type QueryLoggingConfig struct {
+	Mode string `yaml:"mode"` // full, normalized or none (default: normalized)
+}
+
+// AuditConfig configures the audit log of executed SQL. Each record holds
+// the statement text and its metadata (identity, timestamp, duration, row
+// count), never result data. Read only at startup.
+type AuditConfig struct {
+	Enabled  bool   `yaml:"enabled"`  // Record every SQL statement (default: false)
+	File     string `yaml:"file"`     // JSON lines file the records are appended to
+	Table    string `yaml:"table"`    // Optional table, as schema.table, the records are also inserted into
+	Database string `yaml:"database"` // Configured database holding the table (default: the first)
+}
+
 // LoadConfig loads configuration with proper priority:
 // 1. Command line flags (highest priority)
 // 2. Environment variables
@@ -268,6 +827,16 @@ func LoadConfig(configPath string, cliFlags CLIFlags) (*Config, error) {
 	// Override with command line flags (highest priority)
 	applyCLIFlags(cfg, cliFlags)
 
+	// Fill in database fields given as a connection string
+	if err := applyConnectionStrings(cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Replace file: and ${ENV} references in passwords and API keys
+	if err := resolveSecretReferences(cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	// Validate final configuration
 	if err := validateConfig(cfg); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -286,6 +855,8 @@ type CLIFlags struct {
 	HTTPEnabledSet bool
 	HTTPAddr       string
 	HTTPAddrSet    bool
+	HTTPSocket     string
+	HTTPSocketSet  bool
 
 	// TLS flags
 	TLSEnabled    bool
@@ -371,6 +942,18 @@ func defaultConfig() *Config {
 			EmbeddingVoyageAPIKey: "",                       // Must be provided if using Voyage
 			EmbeddingOpenAIAPIKey: "",                       // Must be provided if using OpenAI
 		},
+		SimilaritySearch: SimilaritySearchConfig{
+			MaxTopN:          100, // Prevent huge distance scans
+			MaxTopNUnindexed: 20,  // Unindexed searches compute distance for every row
+			KeywordWeight:    0.3, // Hybrid search leans on the vector ranking
+			TextSearchConfig: DefaultTextSearchConfig,
+		},
+		ErrorSanitization: ErrorSanitizationConfig{
+			Mode: ErrorSanitizationStandard, // Keep errors useful but strip data values
+		},
+		QueryLogging: QueryLoggingConfig{
+			Mode: QueryLoggingNormalized, // Keep query shape, drop literal values
+		},
 		SecretFile: "", // Will be set to default path if not specified
 	}
 }
@@ -399,6 +982,24 @@ func mergeConfig(dest, src *Config) {
 	if src.HTTP.Address != "" {
 		dest.HTTP.Address = src.HTTP.Address
 	}
+	if src.HTTP.SocketPath != "" {
+		dest.HTTP.SocketPath = src.HTTP.SocketPath
+	}
+	if len(src.HTTP.CORS.AllowedOrigins) > 0 {
+		dest.HTTP.CORS.AllowedOrigins = src.HTTP.CORS.AllowedOrigins
+	}
+	if len(src.HTTP.CORS.AllowedMethods) > 0 {
+		dest.HTTP.CORS.AllowedMethods = src.HTTP.CORS.AllowedMethods
+	}
+	if src.HTTP.CORS.AllowCredentials {
+		dest.HTTP.CORS.AllowCredentials = true
+	}
+	if src.HTTP.MetricsEnabled {
+		dest.HTTP.MetricsEnabled = true
+	}
+	if src.HTTP.StreamResults {
+		dest.HTTP.StreamResults = true
+	}
 
 	// TLS
 	if src.HTTP.TLS.Enabled {
@@ -432,6 +1033,27 @@ func mergeConfig(dest, src *Config) {
 	if src.HTTP.Auth.RateLimitMaxAttempts > 0 {
 		dest.HTTP.Auth.RateLimitMaxAttempts = src.HTTP.Auth.RateLimitMaxAttempts
 	}
+	if src.HTTP.Auth.RateLimit != 0 {
+		dest.HTTP.Auth.RateLimit = src.HTTP.Auth.RateLimit
+	}
+	if src.HTTP.Auth.UnusedTokenWarningDays != 0 {
+		dest.HTTP.Auth.UnusedTokenWarningDays = src.HTTP.Auth.UnusedTokenWarningDays
+	}
+	if src.HTTP.ReadTimeout != "" {
+		dest.HTTP.ReadTimeout = src.HTTP.ReadTimeout
+	}
+	if src.HTTP.WriteTimeout != "" {
+		dest.HTTP.WriteTimeout = src.HTTP.WriteTimeout
+	}
+	if src.HTTP.IdleTimeout != "" {
+		dest.HTTP.IdleTimeout = src.HTTP.IdleTimeout
+	}
+	if src.HTTP.SessionIdleTimeout != "" {
+		dest.HTTP.SessionIdleTimeout = src.HTTP.SessionIdleTimeout
+	}
+	if src.HTTP.ShutdownTimeout != "" {
+		dest.HTTP.ShutdownTimeout = src.HTTP.ShutdownTimeout
+	}
 
 	// Databases - if source has databases defined, use them (replace, don't merge)
 	if len(src.Databases) > 0 {
@@ -462,6 +1084,9 @@ func mergeConfig(dest, src *Config) {
 		if src.Embedding.OllamaURL != "" {
 			dest.Embedding.OllamaURL = src.Embedding.OllamaURL
 		}
+		if len(src.Embedding.TableModels) > 0 {
+			dest.Embedding.TableModels = src.Embedding.TableModels
+		}
 	}
 
 	// LLM - merge if any LLM fields are set
@@ -485,6 +1110,9 @@ func mergeConfig(dest, src *Config) {
 		if src.LLM.OpenAIAPIKeyFile != "" {
 			dest.LLM.OpenAIAPIKeyFile = src.LLM.OpenAIAPIKeyFile
 		}
+		if src.LLM.OpenAIBaseURL != "" {
+			dest.LLM.OpenAIBaseURL = src.LLM.OpenAIBaseURL
+		}
 		if src.LLM.OllamaURL != "" {
 			dest.LLM.OllamaURL = src.LLM.OllamaURL
 		}
@@ -494,6 +1122,16 @@ func mergeConfig(dest, src *Config) {
 		if src.LLM.Temperature != 0 {
 			dest.LLM.Temperature = src.LLM.Temperature
 		}
+		if src.LLM.SystemPromptAppend != "" {
+			dest.LLM.SystemPromptAppend = src.LLM.SystemPromptAppend
+		}
+		dest.LLM.PinPostgresDialect = src.LLM.PinPostgresDialect
+		if len(src.LLM.Examples) > 0 {
+			dest.LLM.Examples = src.LLM.Examples
+		}
+		if src.LLM.MaxExamples != 0 {
+			dest.LLM.MaxExamples = src.LLM.MaxExamples
+		}
 	}
 
 	// Knowledgebase - merge if any KB fields are set
@@ -525,6 +1163,52 @@ func mergeConfig(dest, src *Config) {
 		}
 	}
 
+	// Similarity search limits
+	if src.SimilaritySearch.MaxTopN > 0 {
+		dest.SimilaritySearch.MaxTopN = src.SimilaritySearch.MaxTopN
+	}
+	if src.SimilaritySearch.MaxTopNUnindexed > 0 {
+		dest.SimilaritySearch.MaxTopNUnindexed = src.SimilaritySearch.MaxTopNUnindexed
+	}
+	if src.SimilaritySearch.KeywordWeight != 0 {
+		dest.SimilaritySearch.KeywordWeight = src.SimilaritySearch.KeywordWeight
+	}
+	if src.SimilaritySearch.TextSearchConfig != "" {
+		dest.SimilaritySearch.TextSearchConfig = src.SimilaritySearch.TextSearchConfig
+	}
+
+	// Explain settings
+	if src.Explain.AnalyzeAllowed != nil {
+		dest.Explain.AnalyzeAllowed = src.Explain.AnalyzeAllowed
+	}
+	if src.Explain.MaxAnalyzeCost != nil {
+		dest.Explain.MaxAnalyzeCost = src.Explain.MaxAnalyzeCost
+	}
+
+	// Table sample settings
+	if src.TableSample.Method != "" {
+		dest.TableSample.Method = src.TableSample.Method
+	}
+	if src.TableSample.LargeTableRows != nil {
+		dest.TableSample.LargeTableRows = src.TableSample.LargeTableRows
+	}
+
+	// Error sanitization
+	if src.ErrorSanitization.Mode != "" {
+		dest.ErrorSanitization.Mode = src.ErrorSanitization.Mode
+	}
+
+	// Query logging
+	if src.QueryLogging.Mode != "" {
+		dest.QueryLogging.Mode = src.QueryLogging.Mode
+	}
+	if src.Audit.Enabled || src.Audit.File != "" || src.Audit.Table != "" {
+		dest.Audit = src.Audit
+	}
+	if src.LogLevel != "" {
+		dest.LogLevel = src.LogLevel
+	}
+
 	// Secret file
 	if src.SecretFile != "" {
 		dest.SecretFile = src.SecretFile
@@ -560,10 +1244,100 @@ func mergeConfig(dest, src *Config) {
 	if src.Builtins.Tools.SearchKnowledgebase != nil {
 		dest.Builtins.Tools.SearchKnowledgebase = src.Builtins.Tools.SearchKnowledgebase
 	}
+	if src.Builtins.Tools.CountRows != nil {
+		dest.Builtins.Tools.CountRows = src.Builtins.Tools.CountRows
+	}
+	if src.Builtins.Tools.TempFileUsage != nil {
+		dest.Builtins.Tools.TempFileUsage = src.Builtins.Tools.TempFileUsage
+	}
+	if src.Builtins.Tools.CheckVectorIndexes != nil {
+		dest.Builtins.Tools.CheckVectorIndexes = src.Builtins.Tools.CheckVectorIndexes
+	}
+	if src.Builtins.Tools.LockWaitGraph != nil {
+		dest.Builtins.Tools.LockWaitGraph = src.Builtins.Tools.LockWaitGraph
+	}
+	if src.Builtins.Tools.IndexEfficiency != nil {
+		dest.Builtins.Tools.IndexEfficiency = src.Builtins.Tools.IndexEfficiency
+	}
+	if src.Builtins.Tools.FindInvalidIndexes != nil {
+		dest.Builtins.Tools.FindInvalidIndexes = src.Builtins.Tools.FindInvalidIndexes
+	}
+	if src.Builtins.Tools.GetTableSample != nil {
+		dest.Builtins.Tools.GetTableSample = src.Builtins.Tools.GetTableSample
+	}
+	if src.Builtins.Tools.BackupReadiness != nil {
+		dest.Builtins.Tools.BackupReadiness = src.Builtins.Tools.BackupReadiness
+	}
+	if src.Builtins.Tools.DescribeSchema != nil {
+		dest.Builtins.Tools.DescribeSchema = src.Builtins.Tools.DescribeSchema
+	}
+	if src.Builtins.Tools.RelationLayout != nil {
+		dest.Builtins.Tools.RelationLayout = src.Builtins.Tools.RelationLayout
+	}
+	if src.Builtins.Tools.RawSQLEnabled != nil {
+		dest.Builtins.Tools.RawSQLEnabled = src.Builtins.Tools.RawSQLEnabled
+	}
+	if src.Builtins.Tools.SignalBackends != nil {
+		dest.Builtins.Tools.SignalBackends = src.Builtins.Tools.SignalBackends
+	}
+	if src.Builtins.Tools.Enabled != nil {
+		dest.Builtins.Tools.Enabled = src.Builtins.Tools.Enabled
+	}
+	if src.Builtins.Tools.Disabled != nil {
+		dest.Builtins.Tools.Disabled = src.Builtins.Tools.Disabled
+	}
+	if src.Builtins.Tools.TokenUsage != nil {
+		dest.Builtins.Tools.TokenUsage = src.Builtins.Tools.TokenUsage
+	}
+	if src.Builtins.Tools.PartitioningAdvisor != nil {
+		dest.Builtins.Tools.PartitioningAdvisor = src.Builtins.Tools.PartitioningAdvisor
+	}
+	if src.Builtins.Tools.FindLargeValues != nil {
+		dest.Builtins.Tools.FindLargeValues = src.Builtins.Tools.FindLargeValues
+	}
+	if src.Builtins.Tools.LongRunningQueries != nil {
+		dest.Builtins.Tools.LongRunningQueries = src.Builtins.Tools.LongRunningQueries
+	}
+	if src.Builtins.Tools.RowcountAccuracy != nil {
+		dest.Builtins.Tools.RowcountAccuracy = src.Builtins.Tools.RowcountAccuracy
+	}
+	if src.Builtins.Tools.SuggestIndexes != nil {
+		dest.Builtins.Tools.SuggestIndexes = src.Builtins.Tools.SuggestIndexes
+	}
+	if src.Builtins.Tools.RefreshMetadata != nil {
+		dest.Builtins.Tools.RefreshMetadata = src.Builtins.Tools.RefreshMetadata
+	}
+	if src.Builtins.Tools.TableMaintenance != nil {
+		dest.Builtins.Tools.TableMaintenance = src.Builtins.Tools.TableMaintenance
+	}
+	if src.Builtins.Tools.DescribeTable != nil {
+		dest.Builtins.Tools.DescribeTable = src.Builtins.Tools.DescribeTable
+	}
+	if src.Builtins.Tools.TestConnection != nil {
+		dest.Builtins.Tools.TestConnection = src.Builtins.Tools.TestConnection
+	}
+	if src.Builtins.Tools.ShowCurrentConnection != nil {
+		dest.Builtins.Tools.ShowCurrentConnection = src.Builtins.Tools.ShowCurrentConnection
+	}
+	if src.Builtins.Tools.ExportSchema != nil {
+		dest.Builtins.Tools.ExportSchema = src.Builtins.Tools.ExportSchema
+	}
+	if src.Builtins.Tools.ListDatabases != nil {
+		dest.Builtins.Tools.ListDatabases = src.Builtins.Tools.ListDatabases
+	}
+	if src.Builtins.Tools.ListRoles != nil {
+		dest.Builtins.Tools.ListRoles = src.Builtins.Tools.ListRoles
+	}
 	// Resources
 	if src.Builtins.Resources.SystemInfo != nil {
 		dest.Builtins.Resources.SystemInfo = src.Builtins.Resources.SystemInfo
 	}
+	if src.Builtins.Resources.StatStatements != nil {
+		dest.Builtins.Resources.StatStatements = src.Builtins.Resources.StatStatements
+	}
+	if src.Builtins.Resources.BlockingLocks != nil {
+		dest.Builtins.Resources.BlockingLocks = src.Builtins.Resources.BlockingLocks
+	}
 	// Prompts
 	if src.Builtins.Prompts.ExploreDatabase != nil {
 		dest.Builtins.Prompts.ExploreDatabase = src.Builtins.Prompts.ExploreDatabase
@@ -577,6 +1351,42 @@ func mergeConfig(dest, src *Config) {
 	if src.Builtins.Prompts.DesignSchema != nil {
 		dest.Builtins.Prompts.DesignSchema = src.Builtins.Prompts.DesignSchema
 	}
+	if src.Builtins.Prompts.SummarizeTable != nil {
+		dest.Builtins.Prompts.SummarizeTable = src.Builtins.Prompts.SummarizeTable
+	}
+	if src.Builtins.Prompts.FindSlowQueries != nil {
+		dest.Builtins.Prompts.FindSlowQueries = src.Builtins.Prompts.FindSlowQueries
+	}
+	if src.Builtins.Prompts.DiagnoseLocks != nil {
+		dest.Builtins.Prompts.DiagnoseLocks = src.Builtins.Prompts.DiagnoseLocks
+	}
+}
+
+// validateToolLists checks that tools.enabled and tools.disabled only name
+// known tools, so a typo can't leave a tool exposed
+func validateToolLists(tools *ToolsConfig) error {
+	for _, list := range []struct {
+		name  string
+		tools []string
+	}{
+		{"enabled", tools.Enabled},
+		{"disabled", tools.Disabled},
+	} {
+		for _, name := range list.tools {
+			if name == "read_resource" {
+				return fmt.Errorf("builtins.tools.%s: read_resource is always enabled and cannot be listed", list.name)
+			}
+			if !slices.Contains(builtinToolNames, name) {
+				return fmt.Errorf("builtins.tools.%s: unknown tool %q", list.name, name)
+			}
+		}
+	}
+	for _, name := range tools.Enabled {
+		if slices.Contains(tools.Disabled, name) {
+			return fmt.Errorf("builtins.tools: %q is in both enabled and disabled", name)
+		}
+	}
+	return nil
 }
 
 // setStringFromEnv sets a string config value from an environment variable if it exists
@@ -622,6 +1432,9 @@ func applyEnvironmentVariables(cfg *Config) {
 	// HTTP
 	setBoolFromEnv(&cfg.HTTP.Enabled, "PGEDGE_HTTP_ENABLED")
 	setStringFromEnv(&cfg.HTTP.Address, "PGEDGE_HTTP_ADDRESS")
+	setStringFromEnv(&cfg.HTTP.SocketPath, "PGEDGE_HTTP_SOCKET_PATH")
+	setBoolFromEnv(&cfg.HTTP.MetricsEnabled, "PGEDGE_HTTP_METRICS_ENABLED")
+	setBoolFromEnv(&cfg.HTTP.StreamResults, "PGEDGE_HTTP_STREAM_RESULTS")
 
 	// TLS
 	setBoolFromEnv(&cfg.HTTP.TLS.Enabled, "PGEDGE_TLS_ENABLED")
@@ -636,6 +1449,13 @@ func applyEnvironmentVariables(cfg *Config) {
 	setIntFromEnv(&cfg.HTTP.Auth.MaxFailedAttemptsBeforeLockout, "PGEDGE_AUTH_MAX_FAILED_ATTEMPTS_BEFORE_LOCKOUT")
 	setIntFromEnv(&cfg.HTTP.Auth.RateLimitWindowMinutes, "PGEDGE_AUTH_RATE_LIMIT_WINDOW_MINUTES")
 	setIntFromEnv(&cfg.HTTP.Auth.RateLimitMaxAttempts, "PGEDGE_AUTH_RATE_LIMIT_MAX_ATTEMPTS")
+	setIntFromEnv(&cfg.HTTP.Auth.RateLimit, "PGEDGE_AUTH_RATE_LIMIT")
+	setIntFromEnv(&cfg.HTTP.Auth.UnusedTokenWarningDays, "PGEDGE_AUTH_UNUSED_TOKEN_WARNING_DAYS")
+	setStringFromEnv(&cfg.HTTP.ReadTimeout, "PGEDGE_HTTP_READ_TIMEOUT")
+	setStringFromEnv(&cfg.HTTP.WriteTimeout, "PGEDGE_HTTP_WRITE_TIMEOUT")
+	setStringFromEnv(&cfg.HTTP.IdleTimeout, "PGEDGE_HTTP_IDLE_TIMEOUT")
+	setStringFromEnv(&cfg.HTTP.SessionIdleTimeout, "PGEDGE_HTTP_SESSION_IDLE_TIMEOUT")
+	setStringFromEnv(&cfg.HTTP.ShutdownTimeout, "PGEDGE_HTTP_SHUTDOWN_TIMEOUT")
 
 	// Database environment variables apply to the first database in the list
 	// If no databases configured yet, create a default one from env vars
@@ -731,6 +1551,7 @@ func applyEnvironmentVariables(cfg *Config) {
 		// Note: errors are silently ignored - file may not exist and that's ok
 	}
 	// 3. Direct config value (if set) is already in cfg.LLM.AnthropicAPIKey/OpenAIAPIKey from mergeConfig
+	setStringFromEnv(&cfg.LLM.OpenAIBaseURL, "PGEDGE_OPENAI_BASE_URL")
 	setStringFromEnv(&cfg.LLM.OllamaURL, "PGEDGE_OLLAMA_URL")
 	setIntFromEnv(&cfg.LLM.MaxTokens, "PGEDGE_LLM_MAX_TOKENS")
 	// Temperature is a float, but we'll handle it specially
@@ -789,6 +1610,9 @@ func applyCLIFlags(cfg *Config, flags CLIFlags) {
 	if flags.HTTPAddrSet {
 		cfg.HTTP.Address = flags.HTTPAddr
 	}
+	if flags.HTTPSocketSet {
+		cfg.HTTP.SocketPath = flags.HTTPSocket
+	}
 
 	// TLS
 	if flags.TLSEnabledSet {
@@ -858,12 +1682,50 @@ func applyCLIFlags(cfg *Config, flags CLIFlags) {
 }
 
 // validateConfig checks if the configuration is valid
+// validateAudit checks that an enabled audit log has somewhere to write
+// and that its table is schema-qualified in a configured database
+func validateAudit(cfg *Config) error {
+	audit := &cfg.Audit
+	if !audit.Enabled {
+		return nil
+	}
+	if audit.File == "" && audit.Table == "" {
+		return fmt.Errorf("audit.enabled requires audit.file, audit.table or both")
+	}
+	if audit.Table == "" {
+		if audit.Database != "" {
+			return fmt.Errorf("audit.database is only used with audit.table")
+		}
+		return nil
+	}
+	if parts := strings.Split(audit.Table, "."); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("audit.table %q must be schema.table", audit.Table)
+	}
+	if len(cfg.Databases) == 0 {
+		return fmt.Errorf("audit.table requires a configured database")
+	}
+	if audit.Database != "" && cfg.GetDatabaseByName(audit.Database) == nil {
+		return fmt.Errorf("audit.database %q is not a configured database", audit.Database)
+	}
+	return nil
+}
+
 func validateConfig(cfg *Config) error {
 	// TLS requires HTTP to be enabled
 	if cfg.HTTP.TLS.Enabled && !cfg.HTTP.Enabled {
 		return fmt.Errorf("TLS requires HTTP mode to be enabled")
 	}
 
+	// The Unix socket is an HTTP transport
+	if cfg.HTTP.SocketPath != "" && !cfg.HTTP.Enabled {
+		return fmt.Errorf("http.socket_path requires HTTP mode to be enabled")
+	}
+
+	// Browsers refuse credentialed responses to a wildcard origin
+	if cfg.HTTP.CORS.AllowCredentials && slices.Contains(cfg.HTTP.CORS.AllowedOrigins, "*") {
+		return fmt.Errorf("http.cors.allow_credentials cannot be used with allowed_origins \"*\"; list the origins instead")
+	}
+
 	// If HTTPS is enabled, cert and key are required
 	if cfg.HTTP.TLS.Enabled {
 		if cfg.HTTP.TLS.CertFile == "" {
@@ -881,6 +1743,111 @@ func validateConfig(cfg *Config) error {
 		}
 	}
 
+	if cfg.HTTP.Auth.RateLimit < 0 {
+		return fmt.Errorf("http.auth.rate_limit must not be negative (0 = unlimited)")
+	}
+	if cfg.HTTP.Auth.UnusedTokenWarningDays < 0 {
+		return fmt.Errorf("http.auth.unused_token_warning_days must not be negative (0 = disabled)")
+	}
+
+	// HTTP timeouts must be non-negative durations
+	for _, timeout := range []struct{ name, value string }{
+		{"read_timeout", cfg.HTTP.ReadTimeout},
+		{"write_timeout", cfg.HTTP.WriteTimeout},
+		{"idle_timeout", cfg.HTTP.IdleTimeout},
+		{"session_idle_timeout", cfg.HTTP.SessionIdleTimeout},
+		{"shutdown_timeout", cfg.HTTP.ShutdownTimeout},
+	} {
+		if timeout.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(timeout.value)
+		if err != nil {
+			return fmt.Errorf("invalid http.%s %q: %w", timeout.name, timeout.value, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("http.%s must not be negative", timeout.name)
+		}
+	}
+
+	// The EXPLAIN ANALYZE cost ceiling is disabled with 0, not a negative value
+	if cfg.Explain.MaxAnalyzeCost != nil && *cfg.Explain.MaxAnalyzeCost < 0 {
+		return fmt.Errorf("explain.max_analyze_cost must not be negative (use 0 to disable the ceiling)")
+	}
+
+	// Table sampling method must be known
+	switch cfg.TableSample.Method {
+	case "", TableSampleAuto, TableSampleLimit, TableSampleRandom, TableSampleSystem, TableSampleBernoulli:
+	default:
+		return fmt.Errorf("invalid table_sample.method %q (must be auto, limit, random, system or bernoulli)", cfg.TableSample.Method)
+	}
+	if cfg.TableSample.LargeTableRows != nil && *cfg.TableSample.LargeTableRows < 0 {
+		return fmt.Errorf("table_sample.large_table_rows must not be negative")
+	}
+
+	// Error sanitization mode must be known
+	switch cfg.ErrorSanitization.Mode {
+	case "", ErrorSanitizationOff, ErrorSanitizationStandard, ErrorSanitizationStrict:
+	default:
+		return fmt.Errorf("invalid error_sanitization mode %q (must be off, standard or strict)", cfg.ErrorSanitization.Mode)
+	}
+
+	// Query logging mode must be known
+	switch cfg.QueryLogging.Mode {
+	case "", QueryLoggingFull, QueryLoggingNormalized, QueryLoggingNone:
+	default:
+		return fmt.Errorf("invalid query_logging mode %q (must be full, normalized or none)", cfg.QueryLogging.Mode)
+	}
+
+	if err := validateAudit(cfg); err != nil {
+		return err
+	}
+
+	// Log level must be one the logging package understands
+	switch strings.ToLower(cfg.LogLevel) {
+	case "", "debug", "info", "warn", "warning", "error":
+	default:
+		return fmt.Errorf("invalid log_level %q (must be debug, info, warn or error)", cfg.LogLevel)
+	}
+
+	if err := validateToolLists(&cfg.Builtins.Tools); err != nil {
+		return err
+	}
+
+	// Example SQL is checked for being read-only at startup, where the SQL
+	// classifier is available
+	if cfg.LLM.MaxExamples < 0 {
+		return fmt.Errorf("llm.max_examples must not be negative")
+	}
+	for i, ex := range cfg.LLM.Examples {
+		if strings.TrimSpace(ex.Question) == "" || strings.TrimSpace(ex.SQL) == "" {
+			return fmt.Errorf("llm.examples[%d] needs both a question and sql", i)
+		}
+	}
+
+	if w := cfg.SimilaritySearch.KeywordWeight; w < 0 || w > 1 {
+		return fmt.Errorf("similarity_search.keyword_weight must be between 0 and 1")
+	}
+	if ts := cfg.SimilaritySearch.TextSearchConfig; ts != "" && !isTextSearchConfigName(ts) {
+		return fmt.Errorf("similarity_search.text_search_config %q must be a configuration name such as english or public.my_config", ts)
+	}
+
+	// Per-column embedding models need a known provider and a
+	// schema-qualified column
+	for column, m := range cfg.Embedding.TableModels {
+		if parts := strings.Split(column, "."); len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return fmt.Errorf("embedding.table_models: %q must be schema.table.column", column)
+		}
+		switch m.Provider {
+		case "voyage", "openai", "ollama":
+		default:
+			return fmt.Errorf("embedding.table_models.%s: invalid provider %q (must be voyage, openai or ollama)", column, m.Provider)
+		}
+		if m.Model == "" {
+			return fmt.Errorf("embedding.table_models.%s: model is required", column)
+		}
+	}
+
 	// Database configuration validation
 	// Validate each database in the list
 	seenNames := make(map[string]bool)
@@ -901,11 +1868,149 @@ func validateConfig(cfg *Config) error {
 		if db.User == "" {
 			return fmt.Errorf("database '%s': user is required (set via -db-user, PGEDGE_DB_USER, PGUSER env var, or config file)", db.Name)
 		}
+
+		// Pooling mode must be one we know how to handle
+		switch db.PoolingMode {
+		case "", PoolingModeSession, PoolingModeTransaction:
+		default:
+			return fmt.Errorf("database '%s': invalid pooling_mode %q (must be session or transaction)", db.Name, db.PoolingMode)
+		}
+
+		// Pool settings must be consistent
+		if db.PoolMaxConns < 0 || db.PoolMinConns < 0 {
+			return fmt.Errorf("database '%s': pool_max_conns and pool_min_conns must not be negative", db.Name)
+		}
+		if db.PoolMaxConns > 0 && db.PoolMinConns > db.PoolMaxConns {
+			return fmt.Errorf("database '%s': pool_min_conns (%d) must not exceed pool_max_conns (%d)", db.Name, db.PoolMinConns, db.PoolMaxConns)
+		}
+		for _, setting := range []struct{ name, value string }{
+			{"pool_max_conn_idle_time", db.PoolMaxConnIdleTime},
+			{"pool_max_conn_lifetime", db.PoolMaxConnLifetime},
+		} {
+			if setting.value == "" {
+				continue
+			}
+			d, err := time.ParseDuration(setting.value)
+			if err != nil {
+				return fmt.Errorf("database '%s': invalid %s %q: %w", db.Name, setting.name, setting.value, err)
+			}
+			if d <= 0 {
+				return fmt.Errorf("database '%s': %s must be positive", db.Name, setting.name)
+			}
+		}
+
+		// Token authentication sends the token as a password, so it needs
+		// an encrypted connection
+		switch db.AuthMethod {
+		case "", AuthMethodPassword:
+		case AuthMethodAWSIAM, AuthMethodAzureAD:
+			switch db.SSLMode {
+			case "require", "verify-ca", "verify-full":
+			default:
+				return fmt.Errorf("database '%s': auth_method %s requires sslmode require, verify-ca or verify-full", db.Name, db.AuthMethod)
+			}
+		default:
+			return fmt.Errorf("database '%s': invalid auth_method %q (must be password, aws-iam or azure-ad)", db.Name, db.AuthMethod)
+		}
+
+		// Query timeout must be a positive duration
+		if db.QueryTimeout != "" {
+			timeout, err := time.ParseDuration(db.QueryTimeout)
+			if err != nil {
+				return fmt.Errorf("database '%s': invalid query_timeout %q: %w", db.Name, db.QueryTimeout, err)
+			}
+			if timeout <= 0 {
+				return fmt.Errorf("database '%s': query_timeout must be positive", db.Name)
+			}
+		}
+		if db.MaxQueryTimeout != "" {
+			maxTimeout, err := time.ParseDuration(db.MaxQueryTimeout)
+			if err != nil {
+				return fmt.Errorf("database '%s': invalid max_query_timeout %q: %w", db.Name, db.MaxQueryTimeout, err)
+			}
+			if maxTimeout < db.GetQueryTimeout() {
+				return fmt.Errorf("database '%s': max_query_timeout must be at least query_timeout (%s)", db.Name, db.GetQueryTimeout())
+			}
+		}
+
+		if db.MaxQueryCost < 0 {
+			return fmt.Errorf("database '%s': max_query_cost must not be negative (use 0 to disable the guard)", db.Name)
+		}
+		switch db.QueryCostAction {
+		case "", QueryCostActionBlock, QueryCostActionWarn:
+		default:
+			return fmt.Errorf("database '%s': invalid query_cost_action %q (must be block or warn)", db.Name, db.QueryCostAction)
+		}
+		if db.QueryCostAction != "" && db.MaxQueryCost == 0 {
+			return fmt.Errorf("database '%s': query_cost_action requires max_query_cost", db.Name)
+		}
+
+		if db.MetadataRefreshInterval != "" {
+			interval, err := time.ParseDuration(db.MetadataRefreshInterval)
+			if err != nil {
+				return fmt.Errorf("database '%s': invalid metadata_refresh_interval %q: %w", db.Name, db.MetadataRefreshInterval, err)
+			}
+			if interval <= 0 {
+				return fmt.Errorf("database '%s': metadata_refresh_interval must be positive", db.Name)
+			}
+		}
+
+		if db.MaxSchemaContextBytes < 0 {
+			return fmt.Errorf("database '%s': max_schema_context_bytes must not be negative", db.Name)
+		}
+		for _, pattern := range db.RedactColumns {
+			if err := validateRedactPattern(pattern); err != nil {
+				return fmt.Errorf("database '%s': redact_columns: %w", db.Name, err)
+			}
+		}
+		if db.HideRedactedColumns && len(db.RedactColumns) == 0 {
+			return fmt.Errorf("database '%s': hide_redacted_columns requires redact_columns", db.Name)
+		}
 	}
 
 	return nil
 }
 
+// validateRedactPattern checks a redact_columns pattern: column,
+// table.column or schema.table.column, each part non-empty and a valid
+// path.Match pattern
+func validateRedactPattern(pattern string) error {
+	parts := strings.Split(pattern, ".")
+	if len(parts) > 3 {
+		return fmt.Errorf("%q must be column, table.column or schema.table.column", pattern)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return fmt.Errorf("%q has an empty name part", pattern)
+		}
+		if _, err := path.Match(part, ""); err != nil {
+			return fmt.Errorf("%q is not a valid pattern: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// isTextSearchConfigName reports whether name is an unquoted text search
+// configuration name, optionally schema-qualified, as it is written into
+// the hybrid search SQL
+func isTextSearchConfigName(name string) bool {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return false
+	}
+	for _, part := range parts {
+		if part == "" || (part[0] >= '0' && part[0] <= '9') {
+			return false
+		}
+		for _, r := range part {
+			if !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // readAPIKeyFromFile reads an API key from a file
 // Returns the key with whitespace trimmed, or empty string if file doesn't exist or is empty
 func readAPIKeyFromFile(filePath string) (string, error) {
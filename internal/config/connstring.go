@@ -0,0 +1,214 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// connStringParts are the settings a database's connection_string can set
+type connStringParts struct {
+	host     string
+	port     int
+	database string
+	user     string
+	password string
+	sslmode  string
+}
+
+// applyConnectionStrings fills in each database's host, port, database,
+// user, password and sslmode from its connection_string. Fields that are
+// set explicitly (including by environment variables and flags) take
+// precedence over the values in the connection string.
+func applyConnectionStrings(cfg *Config) error {
+	for i := range cfg.Databases {
+		db := &cfg.Databases[i]
+		if db.ConnectionString == "" {
+			continue
+		}
+
+		parts, err := parseConnectionString(db.ConnectionString)
+		if err != nil {
+			return fmt.Errorf("database '%s': invalid connection_string: %w", db.Name, err)
+		}
+
+		if db.Host == "" {
+			db.Host = parts.host
+		}
+		if db.Port == 0 {
+			db.Port = parts.port
+		}
+		if db.Database == "" {
+			db.Database = parts.database
+		}
+		if db.User == "" {
+			db.User = parts.user
+		}
+		if db.Password == "" {
+			db.Password = parts.password
+		}
+		if db.SSLMode == "" {
+			db.SSLMode = parts.sslmode
+		}
+
+		// Apply libpq's defaults for anything the URL left out
+		if db.Host == "" {
+			db.Host = "localhost"
+		}
+		if db.Port == 0 {
+			db.Port = 5432
+		}
+	}
+	return nil
+}
+
+// parseConnectionString parses a postgres:// or postgresql:// URL or a
+// keyword/value string such as "host=db port=5432 user=app". Only the
+// settings a database entry can hold are accepted; anything else is an
+// error rather than being silently dropped.
+func parseConnectionString(connStr string) (connStringParts, error) {
+	connStr = strings.TrimSpace(connStr)
+	if strings.HasPrefix(connStr, "postgres://") || strings.HasPrefix(connStr, "postgresql://") {
+		return parseConnectionURL(connStr)
+	}
+	if strings.Contains(connStr, "://") {
+		return connStringParts{}, fmt.Errorf("unsupported scheme (must be postgres:// or postgresql://)")
+	}
+
+	params, err := parseKeywordValues(connStr)
+	if err != nil {
+		return connStringParts{}, err
+	}
+	return connStringPartsFromParams(params)
+}
+
+// parseConnectionURL parses a connection URI
+func parseConnectionURL(connStr string) (connStringParts, error) {
+	u, err := url.Parse(connStr)
+	if err != nil {
+		// The error repeats the URL, password included
+		return connStringParts{}, fmt.Errorf("malformed URL")
+	}
+
+	params := make(map[string]string)
+	if u.User != nil {
+		params["user"] = u.User.Username()
+		if password, ok := u.User.Password(); ok {
+			params["password"] = password
+		}
+	}
+	if strings.Contains(u.Host, ",") {
+		return connStringParts{}, fmt.Errorf("multiple hosts are not supported")
+	}
+	if host := u.Hostname(); host != "" {
+		params["host"] = host
+	}
+	if port := u.Port(); port != "" {
+		params["port"] = port
+	}
+	if database := strings.TrimPrefix(u.Path, "/"); database != "" {
+		params["dbname"] = database
+	}
+
+	for key, values := range u.Query() {
+		if _, exists := params[key]; exists {
+			return connStringParts{}, fmt.Errorf("%s is given twice", key)
+		}
+		params[key] = values[len(values)-1]
+	}
+
+	return connStringPartsFromParams(params)
+}
+
+// parseKeywordValues parses keyword=value pairs separated by whitespace.
+// Values may be single-quoted, with \' and \\ escapes, as in libpq.
+func parseKeywordValues(connStr string) (map[string]string, error) {
+	params := make(map[string]string)
+	s := connStr
+	for {
+		s = strings.TrimLeft(s, " \t\n\r")
+		if s == "" {
+			return params, nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("expected keyword=value at %q", strings.Fields(s)[0])
+		}
+		key := strings.TrimSpace(s[:eq])
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		var value strings.Builder
+		quoted := strings.HasPrefix(s, "'")
+		if quoted {
+			s = s[1:]
+		}
+		closed := !quoted
+		for len(s) > 0 {
+			c := s[0]
+			if c == '\\' && len(s) > 1 {
+				value.WriteByte(s[1])
+				s = s[2:]
+				continue
+			}
+			if quoted && c == '\'' {
+				closed = true
+				s = s[1:]
+				break
+			}
+			if !quoted && strings.IndexByte(" \t\n\r", c) >= 0 {
+				break
+			}
+			value.WriteByte(c)
+			s = s[1:]
+		}
+		if !closed {
+			return nil, fmt.Errorf("unterminated quoted value for %s", key)
+		}
+
+		params[key] = value.String()
+	}
+}
+
+// connStringPartsFromParams converts libpq parameter names to the fields
+// of a database entry
+func connStringPartsFromParams(params map[string]string) (connStringParts, error) {
+	var parts connStringParts
+	for key, value := range params {
+		switch key {
+		case "host":
+			if strings.Contains(value, ",") {
+				return connStringParts{}, fmt.Errorf("multiple hosts are not supported")
+			}
+			parts.host = value
+		case "port":
+			port, err := strconv.Atoi(value)
+			if err != nil || port < 1 || port > 65535 {
+				return connStringParts{}, fmt.Errorf("invalid port %q", value)
+			}
+			parts.port = port
+		case "dbname":
+			parts.database = value
+		case "user":
+			parts.user = value
+		case "password":
+			parts.password = value
+		case "sslmode":
+			parts.sslmode = value
+		default:
+			return connStringParts{}, fmt.Errorf("unsupported parameter %q (only host, port, dbname, user, password and sslmode can be set)", key)
+		}
+	}
+	return parts, nil
+}
@@ -12,8 +12,9 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"sync"
+
+	"pgedge-postgres-mcp/internal/logging"
 )
 
 // ReloadableConfig wraps a Config with thread-safe access and reload capability
@@ -75,15 +76,11 @@ func (rc *ReloadableConfig) Reload() error {
 		callback(newConfig)
 	}
 
-	// Log successful reload
-	fmt.Fprintf(os.Stderr, "Configuration reloaded successfully from %s\n", rc.path)
-	fmt.Fprintf(os.Stderr, "  Databases: %d configured\n", len(newConfig.Databases))
-
-	// Log if databases changed
-	if len(oldConfig.Databases) != len(newConfig.Databases) {
-		fmt.Fprintf(os.Stderr, "  Database count changed: %d -> %d\n",
-			len(oldConfig.Databases), len(newConfig.Databases))
-	}
+	logging.Info("config_reloaded",
+		"path", rc.path,
+		"databases", len(newConfig.Databases),
+		"previous_databases", len(oldConfig.Databases),
+	)
 
 	return nil
 }
@@ -92,35 +89,59 @@ func (rc *ReloadableConfig) Reload() error {
 func (rc *ReloadableConfig) logRestartRequiredSettings(newConfig *Config) {
 	old := rc.config
 
-	// HTTP mode changes require restart
-	if old.HTTP.Enabled != newConfig.HTTP.Enabled {
-		fmt.Fprintf(os.Stderr, "  WARNING: http.enabled changed - requires restart\n")
-	}
-	if old.HTTP.Address != newConfig.HTTP.Address {
-		fmt.Fprintf(os.Stderr, "  WARNING: http.address changed - requires restart\n")
-	}
-
-	// TLS changes require restart
-	if old.HTTP.TLS.Enabled != newConfig.HTTP.TLS.Enabled {
-		fmt.Fprintf(os.Stderr, "  WARNING: http.tls.enabled changed - requires restart\n")
-	}
-	if old.HTTP.TLS.CertFile != newConfig.HTTP.TLS.CertFile {
-		fmt.Fprintf(os.Stderr, "  WARNING: http.tls.cert_file changed - requires restart\n")
-	}
-	if old.HTTP.TLS.KeyFile != newConfig.HTTP.TLS.KeyFile {
-		fmt.Fprintf(os.Stderr, "  WARNING: http.tls.key_file changed - requires restart\n")
+	for _, name := range restartRequiredChanges(old, newConfig) {
+		logging.Warn("config_restart_required", "setting", name)
 	}
 
 	// LLM/embedding provider changes are logged (may work but connections need reset)
 	if old.LLM.Provider != newConfig.LLM.Provider {
-		fmt.Fprintf(os.Stderr, "  NOTE: llm.provider changed to %s\n", newConfig.LLM.Provider)
+		logging.Info("config_setting_changed", "setting", "llm.provider", "value", newConfig.LLM.Provider)
 	}
 	if old.LLM.Model != newConfig.LLM.Model {
-		fmt.Fprintf(os.Stderr, "  NOTE: llm.model changed to %s\n", newConfig.LLM.Model)
+		logging.Info("config_setting_changed", "setting", "llm.model", "value", newConfig.LLM.Model)
 	}
 	if old.Embedding.Provider != newConfig.Embedding.Provider {
-		fmt.Fprintf(os.Stderr, "  NOTE: embedding.provider changed to %s\n", newConfig.Embedding.Provider)
+		logging.Info("config_setting_changed", "setting", "embedding.provider", "value", newConfig.Embedding.Provider)
+	}
+}
+
+// restartRequiredChanges returns the settings that differ between two
+// configurations but are only read at startup. The token list, embedding
+// settings, log level and rate limits are applied on reload, except that
+// turning the per-token rate limit on or off needs a restart. Changes to a
+// database's settings are applied by reconnecting its clients (see
+// database.ClientManager.UpdateDatabaseConfigs).
+func restartRequiredChanges(old, newConfig *Config) []string {
+	var changed []string
+	check := func(name string, differs bool) {
+		if differs {
+			changed = append(changed, name)
+		}
 	}
+
+	// HTTP listener
+	check("http.enabled", old.HTTP.Enabled != newConfig.HTTP.Enabled)
+	check("http.address", old.HTTP.Address != newConfig.HTTP.Address)
+	check("http.socket_path", old.HTTP.SocketPath != newConfig.HTTP.SocketPath)
+	check("http.stream_results", old.HTTP.StreamResults != newConfig.HTTP.StreamResults)
+
+	// TLS
+	check("http.tls.enabled", old.HTTP.TLS.Enabled != newConfig.HTTP.TLS.Enabled)
+	check("http.tls.cert_file", old.HTTP.TLS.CertFile != newConfig.HTTP.TLS.CertFile)
+	check("http.tls.key_file", old.HTTP.TLS.KeyFile != newConfig.HTTP.TLS.KeyFile)
+	check("http.tls.chain_file", old.HTTP.TLS.ChainFile != newConfig.HTTP.TLS.ChainFile)
+
+	// Authentication
+	check("http.auth.enabled", old.HTTP.Auth.Enabled != newConfig.HTTP.Auth.Enabled)
+	check("http.auth.token_file", old.HTTP.Auth.TokenFile != newConfig.HTTP.Auth.TokenFile)
+	check("http.auth.user_file", old.HTTP.Auth.UserFile != newConfig.HTTP.Auth.UserFile)
+	check("http.auth.rate_limit (enabling or disabling)",
+		(old.HTTP.Auth.RateLimit > 0) != (newConfig.HTTP.Auth.RateLimit > 0))
+
+	// Audit log
+	check("audit", old.Audit != newConfig.Audit)
+
+	return changed
 }
 
 // OnReload registers a callback to be called when configuration is reloaded
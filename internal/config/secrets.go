@@ -0,0 +1,148 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/crypto"
+)
+
+// secretFilePrefix marks a secret value that is read from a file
+const secretFilePrefix = "file:"
+
+// EncryptedSecretPrefix marks a secret value encrypted with the key in the
+// server's secret file
+const EncryptedSecretPrefix = "enc:"
+
+// envReferencePattern matches ${NAME} references in secret values
+var envReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveSecretReferences replaces references in the secret values of the
+// configuration (database passwords and API keys) with what they point to:
+//
+//   - file:/path reads the value from a file, without trailing whitespace
+//   - ${NAME} is replaced by the environment variable NAME, and may appear
+//     anywhere in the value
+//   - enc:<ciphertext> is decrypted with the key in the secret file, as
+//     produced by the server's -encrypt-secret option
+//
+// A reference that can't be resolved is an error, so a missing secret fails
+// at startup rather than as an authentication error later.
+func resolveSecretReferences(cfg *Config) error {
+	type secret struct {
+		name  string
+		value *string
+	}
+	secrets := []secret{
+		{"llm.anthropic_api_key", &cfg.LLM.AnthropicAPIKey},
+		{"llm.openai_api_key", &cfg.LLM.OpenAIAPIKey},
+		{"embedding.voyage_api_key", &cfg.Embedding.VoyageAPIKey},
+		{"embedding.openai_api_key", &cfg.Embedding.OpenAIAPIKey},
+		{"knowledgebase.embedding_voyage_api_key", &cfg.Knowledgebase.EmbeddingVoyageAPIKey},
+		{"knowledgebase.embedding_openai_api_key", &cfg.Knowledgebase.EmbeddingOpenAIAPIKey},
+	}
+	for i := range cfg.Databases {
+		secrets = append(secrets, secret{fmt.Sprintf("databases[%d].password", i), &cfg.Databases[i].Password})
+	}
+
+	var key *crypto.EncryptionKey
+	for _, s := range secrets {
+		if ciphertext, ok := strings.CutPrefix(*s.value, EncryptedSecretPrefix); ok {
+			// Only load the key when something is encrypted, so configs
+			// without enc: values don't need a secret file
+			if key == nil {
+				var err error
+				if key, err = loadSecretKey(cfg); err != nil {
+					return fmt.Errorf("%s: %w", s.name, err)
+				}
+			}
+			plaintext, err := key.Decrypt(ciphertext)
+			if err != nil {
+				return fmt.Errorf("%s: cannot decrypt %s value (was it encrypted with a different secret file?): %w", s.name, EncryptedSecretPrefix, err)
+			}
+			*s.value = plaintext
+			continue
+		}
+
+		resolved, err := resolveSecretReference(*s.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+		*s.value = resolved
+	}
+	return nil
+}
+
+// loadSecretKey loads the key used for enc: values from the configured
+// secret file, or the default one next to the server binary
+func loadSecretKey(cfg *Config) (*crypto.EncryptionKey, error) {
+	path := cfg.SecretFile
+	if path == "" {
+		execPath, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate the secret file: %w", err)
+		}
+		path = GetDefaultSecretPath(execPath)
+	}
+
+	key, err := crypto.LoadKeyFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s values need the secret file: %w", EncryptedSecretPrefix, err)
+	}
+	return key, nil
+}
+
+// resolveSecretReference resolves a single secret value; values without a
+// reference are returned unchanged
+func resolveSecretReference(value string) (string, error) {
+	if path, ok := strings.CutPrefix(value, secretFilePrefix); ok {
+		return readSecretFile(path)
+	}
+
+	var missing string
+	resolved := envReferencePattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envReferencePattern.FindStringSubmatch(ref)[1]
+		val, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+		return val
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s is not set", missing)
+	}
+	return resolved, nil
+}
+
+// readSecretFile reads a secret from path, expanding a leading ~
+func readSecretFile(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("%s reference has no path", secretFilePrefix)
+	}
+	if path[0] == '~' {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(homeDir, path[1:])
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), " \t\r\n"), nil
+}
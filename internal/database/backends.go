@@ -0,0 +1,65 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// serverBackends counts the open pool connections of every client by
+// backend PID. PIDs are only unique per database server, so connections to
+// two servers can share one; counting keeps the PID listed until both close.
+var serverBackends = struct {
+	mu   sync.Mutex
+	pids map[uint32]int
+}{pids: make(map[uint32]int)}
+
+// trackServerBackends records the backend PID of each connection the pool
+// opens, until the pool closes it
+func trackServerBackends(poolConfig *pgxpool.Config) {
+	poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		addServerBackend(conn.PgConn().PID())
+		return nil
+	}
+	poolConfig.BeforeClose = func(conn *pgx.Conn) {
+		removeServerBackend(conn.PgConn().PID())
+	}
+}
+
+func addServerBackend(pid uint32) {
+	serverBackends.mu.Lock()
+	defer serverBackends.mu.Unlock()
+	serverBackends.pids[pid]++
+}
+
+func removeServerBackend(pid uint32) {
+	serverBackends.mu.Lock()
+	defer serverBackends.mu.Unlock()
+	if serverBackends.pids[pid] <= 1 {
+		delete(serverBackends.pids, pid)
+		return
+	}
+	serverBackends.pids[pid]--
+}
+
+// IsServerBackend reports whether pid is the backend of a connection held
+// by one of the server's connection pools. A PID shared with a connection
+// to another database server also counts, which errs on the side of
+// leaving a session alone.
+func IsServerBackend(pid uint32) bool {
+	serverBackends.mu.Lock()
+	defer serverBackends.mu.Unlock()
+	return serverBackends.pids[pid] > 0
+}
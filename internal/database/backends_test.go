@@ -0,0 +1,50 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Server Backend Tracking Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestServerBackends(t *testing.T) {
+	const pid = 4242000
+
+	if IsServerBackend(pid) {
+		t.Fatalf("PID %d tracked before any connection", pid)
+	}
+
+	// Two connections, e.g. to different servers, with the same PID
+	addServerBackend(pid)
+	addServerBackend(pid)
+	removeServerBackend(pid)
+	if !IsServerBackend(pid) {
+		t.Error("PID should stay tracked while one connection is open")
+	}
+
+	removeServerBackend(pid)
+	if IsServerBackend(pid) {
+		t.Error("PID should not be tracked once its connections are closed")
+	}
+}
+
+func TestTrackServerBackends(t *testing.T) {
+	poolConfig, err := pgxpool.ParseConfig("postgres://localhost/test")
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+
+	trackServerBackends(poolConfig)
+	if poolConfig.AfterConnect == nil || poolConfig.BeforeClose == nil {
+		t.Error("expected AfterConnect and BeforeClose hooks to be set")
+	}
+}
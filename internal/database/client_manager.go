@@ -12,10 +12,12 @@ package database
 
 import (
 	"fmt"
-	"os"
+	"reflect"
 	"sync"
+	"time"
 
 	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/logging"
 )
 
 // ClientManager manages per-token, per-database clients for connection isolation
@@ -26,6 +28,13 @@ type ClientManager struct {
 	dbConfigs     map[string]*config.NamedDatabaseConfig // dbName -> config
 	currentDB     map[string]string                      // tokenHash -> current dbName
 	defaultDBName string                                 // name of default database (first configured)
+
+	// resolveConfig returns the settings a token uses in place of a
+	// configured database, or nil to use it as configured
+	resolveConfig func(tokenHash string, base *config.NamedDatabaseConfig) (*config.NamedDatabaseConfig, error)
+
+	usageMu  sync.Mutex
+	lastUsed map[string]time.Time // tokenHash -> last time one of its clients was handed out
 }
 
 // NewClientManager creates a new client manager with database configurations
@@ -34,6 +43,7 @@ func NewClientManager(databases []config.NamedDatabaseConfig) *ClientManager {
 		clients:   make(map[string]map[string]*Client),
 		dbConfigs: make(map[string]*config.NamedDatabaseConfig),
 		currentDB: make(map[string]string),
+		lastUsed:  make(map[string]time.Time),
 	}
 
 	// Store database configs
@@ -56,6 +66,7 @@ func NewClientManagerWithConfig(dbConfig *config.NamedDatabaseConfig) *ClientMan
 			clients:   make(map[string]map[string]*Client),
 			dbConfigs: make(map[string]*config.NamedDatabaseConfig),
 			currentDB: make(map[string]string),
+			lastUsed:  make(map[string]time.Time),
 		}
 	}
 
@@ -68,6 +79,7 @@ func NewClientManagerWithConfig(dbConfig *config.NamedDatabaseConfig) *ClientMan
 		clients:       make(map[string]map[string]*Client),
 		dbConfigs:     map[string]*config.NamedDatabaseConfig{name: dbConfig},
 		currentDB:     make(map[string]string),
+		lastUsed:      make(map[string]time.Time),
 		defaultDBName: name,
 	}
 }
@@ -93,6 +105,7 @@ func (cm *ClientManager) GetClientForDatabase(tokenHash, dbName string) (*Client
 	if dbName == "" {
 		dbName = cm.defaultDBName
 	}
+	cm.touch(tokenHash)
 
 	// Try to get existing client (read lock)
 	cm.mu.RLock()
@@ -103,12 +116,23 @@ func (cm *ClientManager) GetClientForDatabase(tokenHash, dbName string) (*Client
 		}
 	}
 	dbConfig := cm.dbConfigs[dbName]
+	resolveConfig := cm.resolveConfig
 	cm.mu.RUnlock()
 
 	if dbConfig == nil {
 		return nil, fmt.Errorf("database '%s' not configured", dbName)
 	}
 
+	if resolveConfig != nil {
+		override, err := resolveConfig(tokenHash, dbConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve connection for database '%s': %w", dbName, err)
+		}
+		if override != nil {
+			dbConfig = override
+		}
+	}
+
 	// Create new client (write lock)
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
@@ -130,6 +154,9 @@ func (cm *ClientManager) GetClientForDatabase(tokenHash, dbName string) (*Client
 		client.Close()
 		return nil, fmt.Errorf("failed to load metadata for database '%s': %w", dbName, err)
 	}
+	client.StartMetadataRefresh(dbConfig.GetMetadataRefreshInterval())
+
+	logging.Info("database_connected", "database", dbName, "token", logging.TokenPrefix(tokenHash), "tables", len(client.GetMetadata()))
 
 	// Ensure token's client map exists
 	if cm.clients[tokenHash] == nil {
@@ -140,6 +167,60 @@ func (cm *ClientManager) GetClientForDatabase(tokenHash, dbName string) (*Client
 	return client, nil
 }
 
+// SetConnectionResolver sets the function that lets a token connect with
+// its own settings instead of a configured database's. It applies to
+// clients created after the call.
+func (cm *ClientManager) SetConnectionResolver(resolve func(tokenHash string, base *config.NamedDatabaseConfig) (*config.NamedDatabaseConfig, error)) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.resolveConfig = resolve
+}
+
+// touch records that a token's clients are in use
+func (cm *ClientManager) touch(tokenHash string) {
+	cm.usageMu.Lock()
+	cm.lastUsed[tokenHash] = time.Now()
+	cm.usageMu.Unlock()
+}
+
+// RemoveIdleClients closes the clients of tokens that haven't used them for
+// at least maxIdle, so abandoned sessions don't hold connection pools open.
+// The "default" key used when authentication is disabled is never removed.
+// Returns the number of tokens whose clients were removed.
+func (cm *ClientManager) RemoveIdleClients(maxIdle time.Duration) int {
+	cutoff := time.Now().Add(-maxIdle)
+
+	// Last use is checked and the clients are removed under both locks.
+	// GetClientForDatabase touches a token before taking mu, so a client
+	// handed out concurrently is either seen as in use here or is created
+	// afresh after the removal, never closed while in use.
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.usageMu.Lock()
+	defer cm.usageMu.Unlock()
+
+	removed := 0
+	for tokenHash, lastUsed := range cm.lastUsed {
+		if tokenHash == "default" || !lastUsed.Before(cutoff) {
+			continue
+		}
+		if tokenClients, exists := cm.clients[tokenHash]; exists {
+			for _, client := range tokenClients {
+				client.Close()
+			}
+			delete(cm.clients, tokenHash)
+			delete(cm.currentDB, tokenHash)
+			removed++
+		}
+		delete(cm.lastUsed, tokenHash)
+	}
+
+	if removed > 0 {
+		logging.Info("token_connections_removed", "tokens", removed)
+	}
+	return removed
+}
+
 // countClients returns total number of client connections (internal use)
 func (cm *ClientManager) countClients() int {
 	count := 0
@@ -249,8 +330,19 @@ func (cm *ClientManager) GetDatabaseConfigs() []config.NamedDatabaseConfig {
 
 // UpdateDatabaseConfigs updates the database configurations
 // Used for SIGHUP config reload
-// Note: Existing connections are NOT closed - they will be reused if config matches
+// Clients of removed databases, and of databases whose settings changed,
+// are closed, so the next request reconnects with the new settings (access
+// mode, pool size, timeouts, row limits, redaction and the rest). Their
+// pools are closed after the lock is released, once in-flight queries
+// have finished.
 func (cm *ClientManager) UpdateDatabaseConfigs(databases []config.NamedDatabaseConfig) {
+	var closing []*Client
+	defer func() {
+		for _, client := range closing {
+			client.Close()
+		}
+	}()
+
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
@@ -265,20 +357,26 @@ func (cm *ClientManager) UpdateDatabaseConfigs(databases []config.NamedDatabaseC
 		}
 	}
 
-	// Find databases that were removed
-	for name := range cm.dbConfigs {
-		if _, exists := newConfigs[name]; !exists {
-			// Database removed - close all connections to it
-			for tokenHash, tokenClients := range cm.clients {
-				if client, exists := tokenClients[name]; exists {
-					client.Close()
-					delete(tokenClients, name)
-					fmt.Fprintf(os.Stderr, "Closed connection to removed database '%s' for token\n", name)
-				}
-				// Update currentDB if it was pointing to removed database
-				if cm.currentDB[tokenHash] == name {
-					cm.currentDB[tokenHash] = newDefaultName
-				}
+	for name, oldConfig := range cm.dbConfigs {
+		newConfig, exists := newConfigs[name]
+		if exists && reflect.DeepEqual(oldConfig, newConfig) {
+			continue
+		}
+
+		reason := "database removed"
+		if exists {
+			reason = "configuration changed"
+			logging.Info("database_config_changed", "database", name)
+		}
+		for tokenHash, tokenClients := range cm.clients {
+			if client, exists := tokenClients[name]; exists {
+				closing = append(closing, client)
+				delete(tokenClients, name)
+				logging.Info("database_connection_closed", "database", name, "token", logging.TokenPrefix(tokenHash), "reason", reason)
+			}
+			// Update currentDB if it was pointing to removed database
+			if !exists && cm.currentDB[tokenHash] == name {
+				cm.currentDB[tokenHash] = newDefaultName
 			}
 		}
 	}
@@ -286,7 +384,14 @@ func (cm *ClientManager) UpdateDatabaseConfigs(databases []config.NamedDatabaseC
 	cm.dbConfigs = newConfigs
 	cm.defaultDBName = newDefaultName
 
-	fmt.Fprintf(os.Stderr, "Updated database configurations: %d database(s)\n", len(databases))
+	logging.Info("database_configs_updated", "databases", len(databases))
+}
+
+// forget drops the usage record for a token
+func (cm *ClientManager) forget(tokenHash string) {
+	cm.usageMu.Lock()
+	delete(cm.lastUsed, tokenHash)
+	cm.usageMu.Unlock()
 }
 
 // RemoveClient removes and closes all database clients for a given token hash
@@ -303,19 +408,16 @@ func (cm *ClientManager) RemoveClient(tokenHash string) error {
 	// Close all connections for this token
 	for dbName, client := range tokenClients {
 		client.Close()
-		fmt.Fprintf(os.Stderr, "Closed connection to '%s' for removed token\n", dbName)
+		logging.Info("database_connection_closed", "database", dbName, "token", logging.TokenPrefix(tokenHash), "reason", "token removed")
 	}
 
 	// Remove from maps
 	delete(cm.clients, tokenHash)
 	delete(cm.currentDB, tokenHash)
+	cm.forget(tokenHash)
 
 	// Log with truncated hash for security
-	hashPreview := tokenHash
-	if len(tokenHash) > 12 {
-		hashPreview = tokenHash[:12]
-	}
-	fmt.Fprintf(os.Stderr, "Removed all database connections for token hash: %s...\n", hashPreview)
+	logging.Info("token_connections_removed", "token", logging.TokenPrefix(tokenHash))
 
 	return nil
 }
@@ -337,10 +439,11 @@ func (cm *ClientManager) RemoveClients(tokenHashes []string) error {
 			delete(cm.currentDB, tokenHash)
 			removedCount++
 		}
+		cm.forget(tokenHash)
 	}
 
 	if removedCount > 0 {
-		fmt.Fprintf(os.Stderr, "Removed connections for %d token(s)\n", removedCount)
+		logging.Info("token_connections_removed", "tokens", removedCount)
 	}
 
 	return nil
@@ -361,6 +464,10 @@ func (cm *ClientManager) CloseAll() error {
 	cm.clients = make(map[string]map[string]*Client)
 	cm.currentDB = make(map[string]string)
 
+	cm.usageMu.Lock()
+	cm.lastUsed = make(map[string]time.Time)
+	cm.usageMu.Unlock()
+
 	return nil
 }
 
@@ -414,6 +521,7 @@ func (cm *ClientManager) GetOrCreateClient(key string, autoConnect bool) (*Clien
 	}
 
 	dbName := cm.GetCurrentDatabase(key)
+	cm.touch(key)
 
 	// Try to get existing client (read lock)
 	cm.mu.RLock()
@@ -455,6 +563,7 @@ func (cm *ClientManager) GetOrCreateClient(key string, autoConnect bool) (*Clien
 		client.Close()
 		return nil, fmt.Errorf("failed to load metadata for database '%s': %w", dbName, err)
 	}
+	client.StartMetadataRefresh(dbConfig.GetMetadataRefreshInterval())
 
 	if cm.clients[key] == nil {
 		cm.clients[key] = make(map[string]*Client)
@@ -11,7 +11,10 @@
 package database
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"pgedge-postgres-mcp/internal/config"
 )
@@ -335,6 +338,33 @@ func TestClientManager_UpdateDatabaseConfigs(t *testing.T) {
 	}
 }
 
+func TestClientManager_UpdateDatabaseConfigs_ClosesChangedClients(t *testing.T) {
+	cm := NewClientManager([]config.NamedDatabaseConfig{
+		{Name: "db1", Host: "host1", Database: "test1", Port: 5432},
+		{Name: "db2", Host: "host2", Database: "test2", MaxResultRows: 100},
+	})
+	cm.clients["token1"] = map[string]*Client{
+		"db1": NewClient(cm.GetDatabaseConfig("db1")),
+		"db2": NewClient(cm.GetDatabaseConfig("db2")),
+	}
+
+	// db1 is unchanged, db2's row limit changed
+	cm.UpdateDatabaseConfigs([]config.NamedDatabaseConfig{
+		{Name: "db1", Host: "host1", Database: "test1", Port: 5432},
+		{Name: "db2", Host: "host2", Database: "test2", MaxResultRows: 10},
+	})
+
+	if _, exists := cm.clients["token1"]["db1"]; !exists {
+		t.Error("expected the client of the unchanged database to be kept")
+	}
+	if _, exists := cm.clients["token1"]["db2"]; exists {
+		t.Error("expected the client of the changed database to be closed")
+	}
+	if got := cm.GetDatabaseConfig("db2").GetMaxResultRows(); got != 10 {
+		t.Errorf("expected max_result_rows 10 after reload, got %d", got)
+	}
+}
+
 func TestClientManager_SetClient_Validation(t *testing.T) {
 	cm := NewClientManager([]config.NamedDatabaseConfig{
 		{Name: "db1", Host: "localhost", Port: 5432, Database: "test1"},
@@ -441,3 +471,77 @@ func TestClientManager_GetClientCount_Empty(t *testing.T) {
 		t.Errorf("expected 0 clients for empty manager, got %d", count)
 	}
 }
+
+func TestClientManager_RemoveIdleClients(t *testing.T) {
+	cm := NewClientManager([]config.NamedDatabaseConfig{{Name: "db1"}})
+
+	for _, key := range []string{"idle-token", "active-token", "default"} {
+		if err := cm.SetClient(key, NewClient(nil)); err != nil {
+			t.Fatalf("SetClient(%q) error: %v", key, err)
+		}
+	}
+	cm.lastUsed["idle-token"] = time.Now().Add(-time.Hour)
+	cm.lastUsed["active-token"] = time.Now()
+	cm.lastUsed["default"] = time.Now().Add(-time.Hour)
+
+	if removed := cm.RemoveIdleClients(30 * time.Minute); removed != 1 {
+		t.Errorf("expected 1 idle session removed, got %d", removed)
+	}
+	if count := cm.GetClientCount(); count != 2 {
+		t.Errorf("expected 2 clients left, got %d", count)
+	}
+	if _, exists := cm.clients["idle-token"]; exists {
+		t.Error("expected idle token's clients to be removed")
+	}
+	if _, exists := cm.lastUsed["idle-token"]; exists {
+		t.Error("expected idle token's usage record to be removed")
+	}
+
+	// Nothing else is idle
+	if removed := cm.RemoveIdleClients(30 * time.Minute); removed != 0 {
+		t.Errorf("expected no sessions removed, got %d", removed)
+	}
+}
+
+func TestClientManager_ConnectionResolverError(t *testing.T) {
+	cm := NewClientManager([]config.NamedDatabaseConfig{{Name: "db1", Host: "localhost", User: "app"}})
+
+	var gotBase *config.NamedDatabaseConfig
+	cm.SetConnectionResolver(func(tokenHash string, base *config.NamedDatabaseConfig) (*config.NamedDatabaseConfig, error) {
+		gotBase = base
+		return nil, fmt.Errorf("no key")
+	})
+
+	_, err := cm.GetClientForDatabase("token1", "db1")
+	if err == nil || !strings.Contains(err.Error(), "failed to resolve connection for database 'db1': no key") {
+		t.Errorf("GetClientForDatabase() error = %v", err)
+	}
+	if gotBase == nil || gotBase.Name != "db1" {
+		t.Errorf("resolver got base %+v, want db1", gotBase)
+	}
+	if count := cm.GetClientCount(); count != 0 {
+		t.Errorf("GetClientCount() = %d, want 0", count)
+	}
+}
+
+func TestClientManager_DatabaseStats(t *testing.T) {
+	cm := NewClientManager([]config.NamedDatabaseConfig{
+		{Name: "sales", Host: "localhost", Database: "sales"},
+		{Name: "analytics", Host: "localhost", Database: "analytics"},
+	})
+
+	// An unconnected client contributes no connections or tables
+	if err := cm.SetClient("default", NewClient(nil)); err != nil {
+		t.Fatalf("SetClient() error: %v", err)
+	}
+
+	stats := cm.DatabaseStats()
+	if len(stats) != 2 || stats[0].Name != "analytics" || stats[1].Name != "sales" {
+		t.Fatalf("DatabaseStats() = %+v, want analytics and sales", stats)
+	}
+	for _, s := range stats {
+		if s.AcquiredConns != 0 || s.IdleConns != 0 || s.Tables != 0 {
+			t.Errorf("DatabaseStats() for %s = %+v, want zeros", s.Name, s)
+		}
+	}
+}
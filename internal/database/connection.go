@@ -17,12 +17,15 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"pgedge-postgres-mcp/internal/config"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -40,6 +43,8 @@ type Client struct {
 	defaultConnStr string                      // current default connection string
 	initialConnStr string                      // original connection string from env
 	dbConfig       *config.NamedDatabaseConfig // database configuration for pool settings
+	credentials    CredentialProvider          // supplies passwords for new connections (nil = static password)
+	stopRefresh    chan struct{}               // closed to stop the periodic metadata refresh (nil = not running)
 	mu             sync.RWMutex
 }
 
@@ -62,6 +67,15 @@ func NewClientWithConnectionString(connStr string, dbConfig *config.NamedDatabas
 	return c
 }
 
+// SetCredentialProvider sets the provider asked for a password before each
+// new connection, overriding any provider implied by the configured
+// auth_method. It applies to pools created after the call.
+func (c *Client) SetCredentialProvider(provider CredentialProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.credentials = provider
+}
+
 // Connect establishes a connection to the default PostgreSQL database
 func (c *Client) Connect() error {
 	// If a connection string was already set (e.g., via NewClientWithConnectionString),
@@ -121,6 +135,11 @@ func (c *Client) ConnectTo(connStr string) error {
 		return fmt.Errorf("unable to parse connection string: %w", err)
 	}
 
+	// Apply pool configuration if available
+	if err := applyPoolSettings(poolConfig, c.dbConfig); err != nil {
+		return err
+	}
+
 	// Log connection details if debug logging is enabled
 	if GetLogLevel() >= LogLevelDebug {
 		poolConfigMap := make(map[string]interface{})
@@ -131,32 +150,23 @@ func (c *Client) ConnectTo(connStr string) error {
 		LogConnectionDetails(connStr, poolConfigMap)
 	}
 
-	// Apply pool configuration if available
-	if c.dbConfig != nil {
-		// Set pool size limits
-		if c.dbConfig.PoolMaxConns > 0 {
-			poolConfig.MaxConns = int32(c.dbConfig.PoolMaxConns)
-		}
-		if c.dbConfig.PoolMinConns > 0 {
-			poolConfig.MinConns = int32(c.dbConfig.PoolMinConns)
-		}
+	applySessionSettings(poolConfig, c.dbConfig)
 
-		// Set idle timeout
-		if c.dbConfig.PoolMaxConnIdleTime != "" {
-			idleTime, err := time.ParseDuration(c.dbConfig.PoolMaxConnIdleTime)
-			if err != nil {
-				return fmt.Errorf("invalid pool_max_conn_idle_time: %w", err)
-			}
-			poolConfig.MaxConnIdleTime = idleTime
+	// Token authentication: fetch a fresh password before each connection
+	credentials := c.credentials
+	if credentials == nil {
+		credentials, err = newCredentialProvider(c.dbConfig)
+		if err != nil {
+			return err
 		}
 	}
+	applyCredentialProvider(poolConfig, credentials)
+	trackServerBackends(poolConfig)
 
-	// Set read-only transaction mode for all connections
-	// This is enforced at the session level via default_transaction_read_only
-	if poolConfig.ConnConfig.RuntimeParams == nil {
-		poolConfig.ConnConfig.RuntimeParams = make(map[string]string)
+	if queryTracer != nil {
+		poolConfig.ConnConfig.Tracer = queryTracer
 	}
-	poolConfig.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	poolConfig.ConnConfig.OnNotice = dispatchNotice
 
 	// Create pool with configured settings
 	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
@@ -186,6 +196,71 @@ func (c *Client) ConnectTo(connStr string) error {
 	return nil
 }
 
+// applyPoolSettings applies the configured pool sizing and connection
+// lifetimes, leaving pgxpool's defaults for anything not set. Each
+// ClientManager client gets its own pool, so these limits apply per token
+// (or per user session) in HTTP mode.
+func applyPoolSettings(poolConfig *pgxpool.Config, dbConfig *config.NamedDatabaseConfig) error {
+	if dbConfig == nil {
+		return nil
+	}
+
+	// Set pool size limits
+	if dbConfig.PoolMaxConns > 0 {
+		poolConfig.MaxConns = int32(dbConfig.PoolMaxConns)
+	}
+	if dbConfig.PoolMinConns > 0 {
+		poolConfig.MinConns = int32(dbConfig.PoolMinConns)
+	}
+
+	// Set idle timeout
+	if dbConfig.PoolMaxConnIdleTime != "" {
+		idleTime, err := time.ParseDuration(dbConfig.PoolMaxConnIdleTime)
+		if err != nil {
+			return fmt.Errorf("invalid pool_max_conn_idle_time: %w", err)
+		}
+		poolConfig.MaxConnIdleTime = idleTime
+	}
+
+	// Set maximum connection age, so connections are recycled after
+	// failovers and server-side memory growth is bounded
+	if dbConfig.PoolMaxConnLifetime != "" {
+		lifetime, err := time.ParseDuration(dbConfig.PoolMaxConnLifetime)
+		if err != nil {
+			return fmt.Errorf("invalid pool_max_conn_lifetime: %w", err)
+		}
+		poolConfig.MaxConnLifetime = lifetime
+	}
+
+	return nil
+}
+
+// applySessionSettings configures session-level state for new connections.
+//
+// By default, read-only transaction mode is enforced at the session level via
+// default_transaction_read_only, as a second line of defense behind the
+// per-tool SET TRANSACTION READ ONLY. A tool that needs to write must opt
+// out per transaction with SET TRANSACTION READ WRITE.
+//
+// Behind a transaction-mode pooler, consecutive transactions may run on
+// different server connections, so no session state is set up at all: the
+// startup parameter would be rejected or dropped by the pooler, and cached
+// prepared statements would not exist on the next server connection. Tools
+// apply their settings per transaction (SET TRANSACTION, SET LOCAL) instead.
+func applySessionSettings(poolConfig *pgxpool.Config, dbConfig *config.NamedDatabaseConfig) {
+	if dbConfig != nil && dbConfig.UsesTransactionPooling() {
+		poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
+		return
+	}
+
+	if dbConfig == nil || dbConfig.IsReadOnlySession() {
+		if poolConfig.ConnConfig.RuntimeParams == nil {
+			poolConfig.ConnConfig.RuntimeParams = make(map[string]string)
+		}
+		poolConfig.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	}
+}
+
 // addApplicationName adds application_name parameter to a PostgreSQL connection string
 func addApplicationName(connStr, appName string) (string, error) {
 	// Parse the connection string
@@ -225,6 +300,93 @@ func (c *Client) SetDefaultConnection(connStr string) error {
 	return nil
 }
 
+// DatabaseName returns the configured name of this client's database, or
+// "" for a client created without a configuration
+func (c *Client) DatabaseName() string {
+	if c.dbConfig == nil {
+		return ""
+	}
+	return c.dbConfig.Name
+}
+
+// IsReadOnly returns whether SQL submitted through this client must pass the
+// read-only statement classifier. Clients without a configuration are read-only.
+func (c *Client) IsReadOnly() bool {
+	return c.dbConfig == nil || c.dbConfig.IsReadOnly()
+}
+
+// IsReplicaOnly returns whether analytical tools must refuse to run unless
+// this client's server is a hot standby
+func (c *Client) IsReplicaOnly() bool {
+	return c.dbConfig != nil && c.dbConfig.ReplicaOnly
+}
+
+// MaxResultRows returns the maximum number of rows query_database collects
+// per call for this client's database
+func (c *Client) MaxResultRows() int {
+	if c.dbConfig == nil {
+		return config.DefaultMaxResultRows
+	}
+	return c.dbConfig.GetMaxResultRows()
+}
+
+// QueryTimeout returns the statement timeout for query tools on this
+// client's database
+func (c *Client) QueryTimeout() time.Duration {
+	if c.dbConfig == nil {
+		return config.DefaultQueryTimeout
+	}
+	return c.dbConfig.GetQueryTimeout()
+}
+
+// MaxQueryTimeout returns the largest per-call timeout query tools accept
+// on this client's database
+func (c *Client) MaxQueryTimeout() time.Duration {
+	if c.dbConfig == nil {
+		return config.DefaultMaxQueryTimeout
+	}
+	return c.dbConfig.GetMaxQueryTimeout()
+}
+
+// MaxQueryCost returns the estimated plan cost above which query_database
+// does not run a query on this client's database, or 0 for no limit
+func (c *Client) MaxQueryCost() float64 {
+	if c.dbConfig == nil {
+		return 0
+	}
+	return c.dbConfig.MaxQueryCost
+}
+
+// BlocksCostlyQueries returns whether query_database refuses queries above
+// MaxQueryCost, rather than running them with a warning
+func (c *Client) BlocksCostlyQueries() bool {
+	return c.dbConfig == nil || c.dbConfig.GetQueryCostAction() == config.QueryCostActionBlock
+}
+
+// MaxSchemaContextBytes returns the size limit for get_schema_info output on
+// this client's database, or 0 for no limit
+func (c *Client) MaxSchemaContextBytes() int {
+	if c.dbConfig == nil || c.dbConfig.MaxSchemaContextBytes < 0 {
+		return 0
+	}
+	return c.dbConfig.MaxSchemaContextBytes
+}
+
+// Redactor returns the redactor for this client's redact_columns patterns,
+// or nil if none are configured
+func (c *Client) Redactor() *ColumnRedactor {
+	if c.dbConfig == nil {
+		return nil
+	}
+	return NewColumnRedactor(c.dbConfig.RedactColumns)
+}
+
+// HideRedactedColumns returns whether redacted columns are left out of
+// this client's schema metadata
+func (c *Client) HideRedactedColumns() bool {
+	return c.dbConfig != nil && c.dbConfig.HideRedactedColumns
+}
+
 // GetDefaultConnection returns the current default connection string
 func (c *Client) GetDefaultConnection() string {
 	c.mu.RLock()
@@ -237,6 +399,8 @@ func (c *Client) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.stopMetadataRefresh()
+
 	for _, conn := range c.connections {
 		if conn.Pool != nil {
 			conn.Pool.Close()
@@ -405,9 +569,17 @@ func (c *Client) LoadMetadataFor(connStr string) error {
 	}
 	defer rows.Close()
 
+	// Redacted columns can be left out of the schema as well as masked
+	var hidden *ColumnRedactor
+	if c.HideRedactedColumns() {
+		hidden = c.Redactor()
+	}
+
 	newMetadata := make(map[string]TableInfo)
 	schemaSet := make(map[string]bool)
 	columnCount := 0
+	tableHasVector := make(map[string]bool)
+	vectorTableCount, vectorColumnCount := 0, 0
 
 	for rows.Next() {
 		var schemaName, tableName, tableType, tableDesc, columnName, dataType, isNullable, columnDesc string
@@ -437,18 +609,14 @@ func (c *Client) LoadMetadataFor(connStr string) error {
 			}
 		}
 
-		if columnName != "" {
+		if columnName != "" && !hidden.Matches(schemaName, tableName, columnName) {
 			// Detect vector columns and extract dimensions
-			isVector := false
-			dimensions := 0
-			if typeName.Valid && typeName.String == "vector" {
-				isVector = true
-				// Parse dimensions from data_type (e.g., "vector(1536)")
-				re := regexp.MustCompile(`vector\((\d+)\)`)
-				if matches := re.FindStringSubmatch(dataType); len(matches) > 1 {
-					if dim, err := strconv.Atoi(matches[1]); err == nil {
-						dimensions = dim
-					}
+			isVector, dimensions := ParseVectorType(typeName.String, dataType)
+			if isVector {
+				vectorColumnCount++
+				if !tableHasVector[key] {
+					tableHasVector[key] = true
+					vectorTableCount++
 				}
 			}
 
@@ -478,6 +646,17 @@ func (c *Client) LoadMetadataFor(connStr string) error {
 		return err
 	}
 
+	if err := loadKeys(ctx, conn.Pool, newMetadata); err != nil {
+		duration := time.Since(startTime)
+		LogMetadataLoad(connStr, 0, duration, err)
+		return err
+	}
+	if err := loadForeignKeys(ctx, conn.Pool, newMetadata); err != nil {
+		duration := time.Since(startTime)
+		LogMetadataLoad(connStr, 0, duration, err)
+		return err
+	}
+
 	// Update metadata atomically
 	c.mu.Lock()
 	conn.Metadata = newMetadata
@@ -486,6 +665,7 @@ func (c *Client) LoadMetadataFor(connStr string) error {
 
 	duration := time.Since(startTime)
 	LogMetadataLoad(connStr, len(newMetadata), duration, nil)
+	LogVectorMetadata(connStr, vectorTableCount, vectorColumnCount)
 
 	// Log detailed metadata info if debug logging is enabled
 	if GetLogLevel() >= LogLevelDebug {
@@ -495,6 +675,153 @@ func (c *Client) LoadMetadataFor(connStr string) error {
 	return nil
 }
 
+// keyQuery lists the primary key and unique indexes outside the system
+// schemas, covering unique constraints and plain unique indexes alike.
+// Partial and expression indexes are left out since they don't identify
+// rows on their own, as are the INCLUDE columns of covering indexes.
+const keyQuery = `
+	SELECT
+		n.nspname AS schema_name,
+		c.relname AS table_name,
+		i.indisprimary AS is_primary,
+		ARRAY(
+			SELECT a.attname::text
+			FROM unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+			JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+			WHERE k.ord <= i.indnkeyatts
+			ORDER BY k.ord
+		) AS columns
+	FROM pg_index i
+	JOIN pg_class c ON c.oid = i.indrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_class ic ON ic.oid = i.indexrelid
+	WHERE i.indisunique
+		AND i.indpred IS NULL
+		AND i.indexprs IS NULL
+		AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+	ORDER BY n.nspname, c.relname, ic.relname
+`
+
+// loadKeys adds the primary key and unique column sets of each table to the
+// metadata. A unique constraint and an index over the same columns are
+// listed once.
+func loadKeys(ctx context.Context, pool *pgxpool.Pool, metadata map[string]TableInfo) error {
+	rows, err := pool.Query(ctx, keyQuery)
+	if err != nil {
+		return fmt.Errorf("failed to query keys: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var schemaName, tableName string
+		var isPrimary bool
+		var columns []string
+		if err := rows.Scan(&schemaName, &tableName, &isPrimary, &columns); err != nil {
+			return fmt.Errorf("failed to scan key: %w", err)
+		}
+
+		key := schemaName + "." + tableName
+		table, exists := metadata[key]
+		if !exists {
+			continue
+		}
+		if isPrimary {
+			table.PrimaryKey = columns
+		} else {
+			// Column names can't contain NUL, so this can't collide
+			id := key + "\x00" + strings.Join(columns, "\x00")
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			table.UniqueKeys = append(table.UniqueKeys, columns)
+		}
+		metadata[key] = table
+	}
+	return rows.Err()
+}
+
+// foreignKeyQuery lists every foreign key constraint outside the system
+// schemas with its column lists in constraint order
+const foreignKeyQuery = `
+	SELECT
+		n.nspname AS schema_name,
+		c.relname AS table_name,
+		con.conname AS constraint_name,
+		ARRAY(
+			SELECT a.attname::text
+			FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+			JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+			ORDER BY k.ord
+		) AS columns,
+		fn.nspname AS referenced_schema,
+		fc.relname AS referenced_table,
+		ARRAY(
+			SELECT a.attname::text
+			FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+			JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+			ORDER BY k.ord
+		) AS referenced_columns
+	FROM pg_constraint con
+	JOIN pg_class c ON c.oid = con.conrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_class fc ON fc.oid = con.confrelid
+	JOIN pg_namespace fn ON fn.oid = fc.relnamespace
+	WHERE con.contype = 'f'
+		AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+	ORDER BY n.nspname, c.relname, con.conname
+`
+
+// loadForeignKeys adds the foreign key constraints of each table to the
+// metadata. They are read separately from the column query because a
+// multi-column constraint is one entry, not one per column.
+func loadForeignKeys(ctx context.Context, pool *pgxpool.Pool, metadata map[string]TableInfo) error {
+	rows, err := pool.Query(ctx, foreignKeyQuery)
+	if err != nil {
+		return fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schemaName, tableName string
+		var fk ForeignKeyInfo
+		if err := rows.Scan(&schemaName, &tableName, &fk.ConstraintName, &fk.Columns,
+			&fk.ReferencedSchema, &fk.ReferencedTable, &fk.ReferencedColumns); err != nil {
+			return fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+
+		key := schemaName + "." + tableName
+		table, exists := metadata[key]
+		if !exists {
+			continue
+		}
+		table.ForeignKeys = append(table.ForeignKeys, fk)
+		metadata[key] = table
+	}
+	return rows.Err()
+}
+
+// vectorTypeRe matches the format_type output of a pgvector dense vector
+// column, which is schema-qualified when the extension's schema isn't on the
+// search_path (e.g. "vector(1536)", "extensions.halfvec(768)")
+var vectorTypeRe = regexp.MustCompile(`(?:^|\.)"?(?:vector|halfvec)"?\((\d+)\)$`)
+
+// ParseVectorType reports whether a column of the given type name is a
+// pgvector vector or halfvec column, and its dimensions parsed from the
+// format_type output (0 if the column has no fixed dimension)
+func ParseVectorType(typeName, dataType string) (bool, int) {
+	if typeName != "vector" && typeName != "halfvec" {
+		return false, 0
+	}
+	if matches := vectorTypeRe.FindStringSubmatch(dataType); len(matches) > 1 {
+		if dim, err := strconv.Atoi(matches[1]); err == nil {
+			return true, dim
+		}
+	}
+	return true, 0
+}
+
 // GetMetadata returns a copy of the metadata map for the default connection
 func (c *Client) GetMetadata() map[string]TableInfo {
 	c.mu.RLock()
@@ -521,6 +848,24 @@ func (c *Client) GetMetadataFor(connStr string) map[string]TableInfo {
 	return result
 }
 
+// DiffTables returns the sorted keys ("schema.table") of tables present in
+// after but not before, and in before but not after
+func DiffTables(before, after map[string]TableInfo) (added, removed []string) {
+	for key := range after {
+		if _, exists := before[key]; !exists {
+			added = append(added, key)
+		}
+	}
+	for key := range before {
+		if _, exists := after[key]; !exists {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
 // IsMetadataLoaded returns whether metadata has been loaded for the default connection
 func (c *Client) IsMetadataLoaded() bool {
 	c.mu.RLock()
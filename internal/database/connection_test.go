@@ -11,7 +11,14 @@
 package database
 
 import (
+	"strings"
 	"testing"
+	"time"
+
+	"pgedge-postgres-mcp/internal/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func TestNewClient(t *testing.T) {
@@ -266,3 +273,136 @@ func TestClose(t *testing.T) {
 		t.Errorf("After Close(), connections map has %d entries, want 0", len(client.connections))
 	}
 }
+
+func TestApplySessionSettings(t *testing.T) {
+	readOnlyOff := false
+
+	tests := []struct {
+		name             string
+		dbConfig         *config.NamedDatabaseConfig
+		expectReadOnly   bool
+		expectedExecMode pgx.QueryExecMode
+	}{
+		{"nil config", nil, true, pgx.QueryExecModeCacheStatement},
+		{"session pooling", &config.NamedDatabaseConfig{PoolingMode: config.PoolingModeSession}, true, pgx.QueryExecModeCacheStatement},
+		{"read-only session disabled", &config.NamedDatabaseConfig{ReadOnlySession: &readOnlyOff}, false, pgx.QueryExecModeCacheStatement},
+		{"transaction pooling", &config.NamedDatabaseConfig{PoolingMode: config.PoolingModeTransaction}, false, pgx.QueryExecModeExec},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolConfig, err := pgxpool.ParseConfig("postgres://localhost/test")
+			if err != nil {
+				t.Fatalf("ParseConfig() error: %v", err)
+			}
+
+			applySessionSettings(poolConfig, tt.dbConfig)
+
+			_, readOnly := poolConfig.ConnConfig.RuntimeParams["default_transaction_read_only"]
+			if readOnly != tt.expectReadOnly {
+				t.Errorf("default_transaction_read_only set = %v, want %v", readOnly, tt.expectReadOnly)
+			}
+			if poolConfig.ConnConfig.DefaultQueryExecMode != tt.expectedExecMode {
+				t.Errorf("DefaultQueryExecMode = %v, want %v", poolConfig.ConnConfig.DefaultQueryExecMode, tt.expectedExecMode)
+			}
+		})
+	}
+}
+
+func TestDiffTables(t *testing.T) {
+	before := map[string]TableInfo{"public.a": {}, "public.b": {}, "sales.c": {}}
+	after := map[string]TableInfo{"public.a": {}, "sales.c": {}, "sales.d": {}, "public.e": {}}
+
+	added, removed := DiffTables(before, after)
+	if strings.Join(added, ",") != "public.e,sales.d" {
+		t.Errorf("added = %v, want [public.e sales.d]", added)
+	}
+	if strings.Join(removed, ",") != "public.b" {
+		t.Errorf("removed = %v, want [public.b]", removed)
+	}
+
+	added, removed = DiffTables(after, after)
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("identical maps: added = %v, removed = %v", added, removed)
+	}
+}
+
+func TestApplyPoolSettings(t *testing.T) {
+	poolConfig, err := pgxpool.ParseConfig("postgres://localhost/test")
+	if err != nil {
+		t.Fatalf("ParseConfig() error: %v", err)
+	}
+	defaultLifetime := poolConfig.MaxConnLifetime
+
+	if err := applyPoolSettings(poolConfig, &config.NamedDatabaseConfig{PoolMaxConns: 8}); err != nil {
+		t.Fatalf("applyPoolSettings() error: %v", err)
+	}
+	if poolConfig.MaxConns != 8 || poolConfig.MaxConnLifetime != defaultLifetime {
+		t.Errorf("MaxConns = %d, MaxConnLifetime = %v; want 8 and the pgxpool default", poolConfig.MaxConns, poolConfig.MaxConnLifetime)
+	}
+
+	err = applyPoolSettings(poolConfig, &config.NamedDatabaseConfig{
+		PoolMinConns:        2,
+		PoolMaxConnIdleTime: "5m",
+		PoolMaxConnLifetime: "45m",
+	})
+	if err != nil {
+		t.Fatalf("applyPoolSettings() error: %v", err)
+	}
+	if poolConfig.MinConns != 2 || poolConfig.MaxConnIdleTime != 5*time.Minute || poolConfig.MaxConnLifetime != 45*time.Minute {
+		t.Errorf("MinConns = %d, MaxConnIdleTime = %v, MaxConnLifetime = %v", poolConfig.MinConns, poolConfig.MaxConnIdleTime, poolConfig.MaxConnLifetime)
+	}
+
+	if err := applyPoolSettings(poolConfig, &config.NamedDatabaseConfig{PoolMaxConnLifetime: "forever"}); err == nil {
+		t.Error("expected error for invalid pool_max_conn_lifetime")
+	}
+}
+
+func TestParseVectorType(t *testing.T) {
+	tests := []struct {
+		typeName   string
+		dataType   string
+		wantVector bool
+		wantDims   int
+	}{
+		{"vector", "vector(1536)", true, 1536},
+		{"halfvec", "halfvec(768)", true, 768},
+		{"vector", "extensions.vector(384)", true, 384},
+		{"halfvec", `"my schema".halfvec(3)`, true, 3},
+		{"vector", "vector", true, 0},
+		{"sparsevec", "sparsevec(1000)", false, 0},
+		{"_vector", "vector(3)[]", false, 0},
+		{"text", "text", false, 0},
+		{"", "", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dataType, func(t *testing.T) {
+			isVector, dims := ParseVectorType(tt.typeName, tt.dataType)
+			if isVector != tt.wantVector || dims != tt.wantDims {
+				t.Errorf("ParseVectorType(%q, %q) = (%v, %d), want (%v, %d)",
+					tt.typeName, tt.dataType, isVector, dims, tt.wantVector, tt.wantDims)
+			}
+		})
+	}
+}
+
+func TestQueryCostSettings(t *testing.T) {
+	client := NewClient(nil)
+	if client.MaxQueryCost() != 0 || !client.BlocksCostlyQueries() {
+		t.Error("client without configuration should have no cost limit and block by default")
+	}
+
+	client = NewClient(&config.NamedDatabaseConfig{Name: "main", MaxQueryCost: 50000})
+	if client.MaxQueryCost() != 50000 {
+		t.Errorf("MaxQueryCost() = %v, want 50000", client.MaxQueryCost())
+	}
+	if !client.BlocksCostlyQueries() {
+		t.Error("query_cost_action should default to block")
+	}
+
+	client = NewClient(&config.NamedDatabaseConfig{Name: "main", MaxQueryCost: 50000, QueryCostAction: config.QueryCostActionWarn})
+	if client.BlocksCostlyQueries() {
+		t.Error("BlocksCostlyQueries() = true with query_cost_action warn")
+	}
+}
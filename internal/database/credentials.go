@@ -0,0 +1,298 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pgedge-postgres-mcp/internal/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CredentialProvider supplies the password for a new database connection.
+// It is called before every connection the pool opens, so implementations
+// can return short-lived tokens instead of a static password.
+type CredentialProvider interface {
+	Password(ctx context.Context, host string, port uint16, user string) (string, error)
+}
+
+// newCredentialProvider returns the provider for the configured auth_method,
+// or nil when connections use a static password
+func newCredentialProvider(dbConfig *config.NamedDatabaseConfig) (CredentialProvider, error) {
+	if dbConfig == nil {
+		return nil, nil
+	}
+
+	switch dbConfig.AuthMethod {
+	case "", config.AuthMethodPassword:
+		return nil, nil
+	case config.AuthMethodAWSIAM:
+		region := dbConfig.AWSRegion
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		if region == "" {
+			region = os.Getenv("AWS_DEFAULT_REGION")
+		}
+		if region == "" {
+			return nil, fmt.Errorf("auth_method %s requires aws_region or the AWS_REGION environment variable", dbConfig.AuthMethod)
+		}
+		return &awsIAMProvider{region: region}, nil
+	case config.AuthMethodAzureAD:
+		return &azureADProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown auth_method %q", dbConfig.AuthMethod)
+	}
+}
+
+// applyCredentialProvider makes the pool ask provider for a password before
+// each new connection
+func applyCredentialProvider(poolConfig *pgxpool.Config, provider CredentialProvider) {
+	if provider == nil {
+		return
+	}
+	poolConfig.BeforeConnect = func(ctx context.Context, connConfig *pgx.ConnConfig) error {
+		password, err := provider.Password(ctx, connConfig.Host, connConfig.Port, connConfig.User)
+		if err != nil {
+			return fmt.Errorf("unable to obtain database credentials: %w", err)
+		}
+		connConfig.Password = password
+		return nil
+	}
+}
+
+// awsIAMTokenLifetime is how long RDS accepts a generated token
+const awsIAMTokenLifetime = 15 * time.Minute
+
+// awsIAMProvider generates RDS/Aurora IAM authentication tokens, which are
+// SigV4 presigned rds-db:connect requests. Credentials are read from the
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// environment variables on every call so that rotated credentials are
+// picked up.
+type awsIAMProvider struct {
+	region string
+	now    func() time.Time // for tests
+}
+
+// Password returns a fresh IAM authentication token
+func (p *awsIAMProvider) Password(ctx context.Context, host string, port uint16, user string) (string, error) {
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return "", fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set for IAM authentication")
+	}
+
+	now := time.Now
+	if p.now != nil {
+		now = p.now
+	}
+	return buildRDSAuthToken(host, port, user, p.region, accessKey, secretKey,
+		os.Getenv("AWS_SESSION_TOKEN"), now().UTC()), nil
+}
+
+// buildRDSAuthToken presigns an rds-db connect request for user at
+// host:port, as the AWS SDKs' BuildAuthToken does
+func buildRDSAuthToken(host string, port uint16, user, region, accessKey, secretKey, sessionToken string, now time.Time) string {
+	const service = "rds-db"
+	endpoint := host + ":" + strconv.Itoa(int(port))
+	date := now.Format("20060102")
+	amzDate := now.Format("20060102T150405Z")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+
+	params := map[string]string{
+		"Action":              "connect",
+		"DBUser":              user,
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    accessKey + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.Itoa(int(awsIAMTokenLifetime.Seconds())),
+		"X-Amz-SignedHeaders": "host",
+	}
+	if sessionToken != "" {
+		params["X-Amz-Security-Token"] = sessionToken
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = awsURIEncode(k) + "=" + awsURIEncode(params[k])
+	}
+	query := strings.Join(pairs, "&")
+
+	emptyHash := sha256.Sum256(nil)
+	canonicalRequest := strings.Join([]string{
+		"GET",
+		"/",
+		query,
+		"host:" + endpoint + "\n",
+		"host",
+		hex.EncodeToString(emptyHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	return endpoint + "/?" + query + "&X-Amz-Signature=" + signature
+}
+
+// awsURIEncode percent-encodes s as SigV4 requires: everything except
+// unreserved characters, with spaces as %20
+func awsURIEncode(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// azureDatabaseResource is the token audience for Azure Database for
+// PostgreSQL
+const azureDatabaseResource = "https://ossrdbms-aad.database.windows.net"
+
+// azureIMDSEndpoint is the instance metadata service token endpoint used
+// by VMs and AKS pods with a managed identity
+const azureIMDSEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// azureTokenRefreshMargin is how long before expiry a cached token is
+// replaced, so that it can't expire during connection setup
+const azureTokenRefreshMargin = 5 * time.Minute
+
+// azureADProvider obtains Microsoft Entra ID access tokens from the
+// managed identity endpoint: IDENTITY_ENDPOINT/IDENTITY_HEADER on App
+// Service and Container Apps, otherwise the instance metadata service.
+// AZURE_CLIENT_ID selects a user-assigned identity. Tokens are cached
+// until shortly before they expire.
+type azureADProvider struct {
+	endpoint   string // overrides the endpoint, for tests
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Password returns a cached or freshly fetched access token
+func (p *azureADProvider) Password(ctx context.Context, host string, port uint16, user string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Until(p.expires) > azureTokenRefreshMargin {
+		return p.token, nil
+	}
+
+	token, expires, err := p.fetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	p.token, p.expires = token, expires
+	return token, nil
+}
+
+// fetchToken requests a new access token from the managed identity endpoint
+func (p *azureADProvider) fetchToken(ctx context.Context) (string, time.Time, error) {
+	endpoint := p.endpoint
+	header, headerValue := "Metadata", "true"
+	apiVersion := "2018-02-01"
+	if endpoint == "" {
+		if identityEndpoint := os.Getenv("IDENTITY_ENDPOINT"); identityEndpoint != "" {
+			endpoint = identityEndpoint
+			header, headerValue = "X-IDENTITY-HEADER", os.Getenv("IDENTITY_HEADER")
+			apiVersion = "2019-08-01"
+		} else {
+			endpoint = azureIMDSEndpoint
+		}
+	}
+
+	query := url.Values{}
+	query.Set("api-version", apiVersion)
+	query.Set("resource", azureDatabaseResource)
+	if clientID := os.Getenv("AZURE_CLIENT_ID"); clientID != "" {
+		query.Set("client_id", clientID)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set(header, headerValue)
+
+	client := p.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("managed identity token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("managed identity endpoint returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tokenResp struct {
+		AccessToken string          `json:"access_token"`
+		ExpiresOn   json.RawMessage `json:"expires_on"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("managed identity endpoint returned no access token")
+	}
+
+	// expires_on is Unix seconds, as a string or a number depending on the
+	// endpoint; if it can't be read, refetch on the next connection
+	expires := time.Now()
+	if seconds, err := strconv.ParseInt(strings.Trim(string(tokenResp.ExpiresOn), `"`), 10, 64); err == nil {
+		expires = time.Unix(seconds, 0)
+	}
+
+	return tokenResp.AccessToken, expires, nil
+}
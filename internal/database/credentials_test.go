@@ -0,0 +1,197 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pgedge-postgres-mcp/internal/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type staticCredentials struct {
+	password string
+	err      error
+}
+
+func (s staticCredentials) Password(ctx context.Context, host string, port uint16, user string) (string, error) {
+	return s.password, s.err
+}
+
+func TestNewCredentialProvider(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+
+	provider, err := newCredentialProvider(&config.NamedDatabaseConfig{})
+	if err != nil || provider != nil {
+		t.Errorf("password auth: got (%v, %v), want no provider", provider, err)
+	}
+
+	if _, err := newCredentialProvider(&config.NamedDatabaseConfig{AuthMethod: config.AuthMethodAWSIAM}); err == nil {
+		t.Error("aws-iam without a region: expected error")
+	}
+
+	provider, err = newCredentialProvider(&config.NamedDatabaseConfig{AuthMethod: config.AuthMethodAWSIAM, AWSRegion: "us-east-1"})
+	if err != nil {
+		t.Fatalf("aws-iam: unexpected error: %v", err)
+	}
+	if p, ok := provider.(*awsIAMProvider); !ok || p.region != "us-east-1" {
+		t.Errorf("aws-iam: got %#v", provider)
+	}
+
+	provider, err = newCredentialProvider(&config.NamedDatabaseConfig{AuthMethod: config.AuthMethodAzureAD})
+	if _, ok := provider.(*azureADProvider); err != nil || !ok {
+		t.Errorf("azure-ad: got (%#v, %v)", provider, err)
+	}
+}
+
+func TestApplyCredentialProvider(t *testing.T) {
+	poolConfig, err := pgxpool.ParseConfig("postgres://app@db.example.com:5432/postgres?sslmode=require")
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+
+	applyCredentialProvider(poolConfig, nil)
+	if poolConfig.BeforeConnect != nil {
+		t.Fatal("BeforeConnect set without a provider")
+	}
+
+	applyCredentialProvider(poolConfig, staticCredentials{password: "token-1"})
+	connConfig := poolConfig.ConnConfig.Copy()
+	if err := poolConfig.BeforeConnect(context.Background(), connConfig); err != nil {
+		t.Fatalf("BeforeConnect: %v", err)
+	}
+	if connConfig.Password != "token-1" {
+		t.Errorf("Password = %q, want token-1", connConfig.Password)
+	}
+
+	applyCredentialProvider(poolConfig, staticCredentials{err: fmt.Errorf("no identity")})
+	err = poolConfig.BeforeConnect(context.Background(), &pgx.ConnConfig{})
+	if err == nil || !strings.Contains(err.Error(), "no identity") {
+		t.Errorf("BeforeConnect error = %v, want provider error", err)
+	}
+}
+
+func TestBuildRDSAuthToken(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+
+	token := buildRDSAuthToken("mydb.abc.us-east-1.rds.amazonaws.com", 5432, "iam user",
+		"us-east-1", "AKIDEXAMPLE", "secret", "", now)
+
+	for _, want := range []string{
+		"mydb.abc.us-east-1.rds.amazonaws.com:5432/?Action=connect&DBUser=iam%20user&",
+		"X-Amz-Credential=AKIDEXAMPLE%2F20250314%2Fus-east-1%2Frds-db%2Faws4_request",
+		"X-Amz-Date=20250314T092653Z",
+		"X-Amz-Expires=900",
+		"X-Amz-SignedHeaders=host&X-Amz-Signature=",
+	} {
+		if !strings.Contains(token, want) {
+			t.Errorf("token %q does not contain %q", token, want)
+		}
+	}
+	if strings.Contains(token, "X-Amz-Security-Token") {
+		t.Error("token contains a security token without a session token")
+	}
+
+	// Signing is deterministic and covers every input
+	if again := buildRDSAuthToken("mydb.abc.us-east-1.rds.amazonaws.com", 5432, "iam user",
+		"us-east-1", "AKIDEXAMPLE", "secret", "", now); again != token {
+		t.Error("token is not deterministic")
+	}
+	if other := buildRDSAuthToken("mydb.abc.us-east-1.rds.amazonaws.com", 5432, "iam user",
+		"us-east-1", "AKIDEXAMPLE", "other", "", now); other[strings.LastIndex(other, "="):] == token[strings.LastIndex(token, "="):] {
+		t.Error("signature does not depend on the secret key")
+	}
+
+	withSession := buildRDSAuthToken("db", 5432, "app", "eu-west-1", "AKID", "secret", "tok/en+1", now)
+	if !strings.Contains(withSession, "X-Amz-Security-Token=tok%2Fen%2B1&") {
+		t.Errorf("token %q does not carry the encoded session token", withSession)
+	}
+}
+
+func TestAWSIAMProviderRequiresCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	p := &awsIAMProvider{region: "us-east-1"}
+	if _, err := p.Password(context.Background(), "db", 5432, "app"); err == nil {
+		t.Error("expected error without AWS credentials")
+	}
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	token, err := p.Password(context.Background(), "db", 5432, "app")
+	if err != nil || !strings.HasPrefix(token, "db:5432/?Action=connect&DBUser=app&") {
+		t.Errorf("Password() = (%q, %v)", token, err)
+	}
+}
+
+func TestAzureADProvider(t *testing.T) {
+	t.Setenv("AZURE_CLIENT_ID", "client-123")
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Metadata") != "true" {
+			t.Errorf("missing Metadata header")
+		}
+		if got := r.URL.Query().Get("resource"); got != azureDatabaseResource {
+			t.Errorf("resource = %q", got)
+		}
+		if got := r.URL.Query().Get("client_id"); got != "client-123" {
+			t.Errorf("client_id = %q", got)
+		}
+		expires := time.Now().Add(time.Hour).Unix()
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_on":"%d"}`, requests, expires)
+	}))
+	defer server.Close()
+
+	p := &azureADProvider{endpoint: server.URL}
+	for i := 0; i < 2; i++ {
+		token, err := p.Password(context.Background(), "db", 5432, "app")
+		if err != nil {
+			t.Fatalf("Password() error: %v", err)
+		}
+		if token != "token-1" {
+			t.Errorf("Password() = %q, want cached token-1", token)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("token endpoint called %d times, want 1", requests)
+	}
+
+	// A token close to expiry is replaced
+	p.expires = time.Now().Add(time.Minute)
+	if token, _ := p.Password(context.Background(), "db", 5432, "app"); token != "token-2" {
+		t.Errorf("Password() = %q, want refreshed token-2", token)
+	}
+}
+
+func TestAzureADProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	p := &azureADProvider{endpoint: server.URL}
+	_, err := p.Password(context.Background(), "db", 5432, "app")
+	if err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Errorf("Password() error = %v, want status 400", err)
+	}
+}
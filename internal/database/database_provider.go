@@ -49,6 +49,7 @@ func (p *StdioDatabaseProvider) ListDatabases(ctx context.Context) ([]mcp.Databa
 			Database: cfg.Database,
 			User:     cfg.User,
 			SSLMode:  cfg.SSLMode,
+			DSN:      cfg.RedactedConnectionString(),
 		})
 	}
 
@@ -137,6 +138,7 @@ func (p *HTTPDatabaseProvider) ListDatabases(ctx context.Context) ([]mcp.Databas
 			Database: cfg.Database,
 			User:     cfg.User,
 			SSLMode:  cfg.SSLMode,
+			DSN:      cfg.RedactedConnectionString(),
 		})
 	}
 
@@ -133,6 +133,14 @@ func LogMetadataLoad(connStr string, tableCount int, duration time.Duration, err
 	}
 }
 
+// LogVectorMetadata logs how many tables and columns can be used for
+// similarity search
+func LogVectorMetadata(connStr string, vectorTableCount, vectorColumnCount int) {
+	sanitized := SanitizeConnStr(connStr)
+	globalLogger.Info("Vector metadata loaded: connection=%s, vector_table_count=%d, vector_column_count=%d",
+		sanitized, vectorTableCount, vectorColumnCount)
+}
+
 // LogMetadataDetails logs detailed metadata loading information
 func LogMetadataDetails(connStr string, schemaCount, tableCount, columnCount int) {
 	sanitized := SanitizeConnStr(connStr)
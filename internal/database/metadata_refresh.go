@@ -0,0 +1,81 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"time"
+
+	"pgedge-postgres-mcp/internal/logging"
+)
+
+// StartMetadataRefresh reloads the metadata of every connection the client
+// holds every interval, until Close is called. It does nothing if interval
+// is zero or a refresh loop is already running. Each client refreshes
+// independently, so per-token clients never share a reload.
+func (c *Client) StartMetadataRefresh(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	if c.stopRefresh != nil {
+		c.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	c.stopRefresh = stop
+	c.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				c.RefreshMetadata()
+			}
+		}
+	}()
+}
+
+// stopMetadataRefresh ends the refresh loop, if one is running. The caller
+// must hold c.mu.
+func (c *Client) stopMetadataRefresh() {
+	if c.stopRefresh != nil {
+		close(c.stopRefresh)
+		c.stopRefresh = nil
+	}
+}
+
+// RefreshMetadata reloads the metadata of every connection the client
+// holds, logging the tables added and removed when the table set changes.
+// LoadMetadataFor swaps each map under the client mutex, so readers see
+// either the old or the new metadata, never a partial load.
+func (c *Client) RefreshMetadata() {
+	for _, connStr := range c.ListConnections() {
+		before := c.GetMetadataFor(connStr)
+		if err := c.LoadMetadataFor(connStr); err != nil {
+			logging.Warn("metadata_refresh_failed", "connection", SanitizeConnStr(connStr), "error", err.Error())
+			continue
+		}
+
+		added, removed := DiffTables(before, c.GetMetadataFor(connStr))
+		if len(added) > 0 || len(removed) > 0 {
+			logging.Info("metadata_tables_changed",
+				"connection", SanitizeConnStr(connStr),
+				"added", added,
+				"removed", removed,
+			)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartMetadataRefresh(t *testing.T) {
+	client := NewTestClient("postgres://localhost/test", nil)
+
+	client.StartMetadataRefresh(0)
+	if client.stopRefresh != nil {
+		t.Fatal("refresh started with a zero interval")
+	}
+
+	client.StartMetadataRefresh(time.Hour)
+	stop := client.stopRefresh
+	if stop == nil {
+		t.Fatal("refresh not started")
+	}
+
+	// A second start keeps the running loop
+	client.StartMetadataRefresh(time.Hour)
+	if client.stopRefresh != stop {
+		t.Error("second StartMetadataRefresh replaced the running loop")
+	}
+
+	client.Close()
+	if client.stopRefresh != nil {
+		t.Error("Close did not stop the refresh loop")
+	}
+	select {
+	case <-stop:
+	default:
+		t.Error("stop channel not closed")
+	}
+}
@@ -0,0 +1,56 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// noticeHandlers maps a connection to the function collecting its notices;
+// notices on connections without one are discarded
+var noticeHandlers sync.Map // *pgconn.PgConn -> func(*pgconn.Notice)
+
+// dispatchNotice is the OnNotice handler of every pool connection
+func dispatchNotice(conn *pgconn.PgConn, notice *pgconn.Notice) {
+	if handler, ok := noticeHandlers.Load(conn); ok {
+		handler.(func(*pgconn.Notice))(notice)
+	}
+}
+
+// CaptureNotices collects the notices the server sends on conn, such as
+// the output of VACUUM VERBOSE, until the returned function is called. That
+// function stops collecting and returns each notice as psql prints it.
+func CaptureNotices(conn *pgxpool.Conn) func() []string {
+	var mu sync.Mutex
+	var notices []string
+
+	pgConn := conn.Conn().PgConn()
+	noticeHandlers.Store(pgConn, func(notice *pgconn.Notice) {
+		text := notice.Severity + ": " + notice.Message
+		if notice.Detail != "" {
+			text += "\n" + strings.TrimRight(notice.Detail, "\n")
+		}
+		mu.Lock()
+		notices = append(notices, text)
+		mu.Unlock()
+	})
+
+	return func() []string {
+		noticeHandlers.Delete(pgConn)
+		mu.Lock()
+		defer mu.Unlock()
+		return notices
+	}
+}
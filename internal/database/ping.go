@@ -0,0 +1,84 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pgedge-postgres-mcp/internal/config"
+)
+
+// PingResult describes a successful connection test
+type PingResult struct {
+	ServerVersion  string        // server_version reported by the server
+	ConnectTime    time.Duration // time to open and authenticate the connection
+	RoundTripTime  time.Duration // time for a single ping once connected
+	CurrentUser    string        // role the connection authenticated as
+	CurrentDB      string        // database the connection is attached to
+	InRecoveryMode bool          // true when the server is a standby
+}
+
+// Ping opens a single connection with connStr, pings it and reads the
+// server version, then closes it. It doesn't use or change any pool, so it
+// can check a database without switching to it. dbConfig, if given,
+// supplies the auth_method used to obtain the password.
+func Ping(ctx context.Context, connStr string, dbConfig *config.NamedDatabaseConfig) (*PingResult, error) {
+	enhancedConnStr, err := addApplicationName(connStr, "pgEdge Natural Language Agent")
+	if err != nil {
+		return nil, fmt.Errorf("unable to enhance connection string: %w", err)
+	}
+
+	connConfig, err := pgx.ParseConfig(enhancedConnStr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse connection string: %w", err)
+	}
+
+	credentials, err := newCredentialProvider(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	if credentials != nil {
+		password, err := credentials.Password(ctx, connConfig.Host, connConfig.Port, connConfig.User)
+		if err != nil {
+			return nil, fmt.Errorf("unable to obtain database credentials: %w", err)
+		}
+		connConfig.Password = password
+	}
+
+	start := time.Now()
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		LogConnection(connStr, time.Since(start), err)
+		return nil, fmt.Errorf("unable to connect: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	result := &PingResult{ConnectTime: time.Since(start)}
+
+	start = time.Now()
+	if err := conn.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("unable to ping database: %w", err)
+	}
+	result.RoundTripTime = time.Since(start)
+
+	err = conn.QueryRow(ctx,
+		"SELECT current_setting('server_version'), current_user, current_database(), pg_is_in_recovery()",
+	).Scan(&result.ServerVersion, &result.CurrentUser, &result.CurrentDB, &result.InRecoveryMode)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read server version: %w", err)
+	}
+
+	return result, nil
+}
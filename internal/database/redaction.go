@@ -0,0 +1,156 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// RedactedValue replaces the values of redacted columns in tool results
+const RedactedValue = "[redacted]"
+
+// ColumnRedactor matches columns against the redact_columns patterns of a
+// database. A nil ColumnRedactor matches nothing.
+type ColumnRedactor struct {
+	// patterns holds each pattern as schema, table and column parts,
+	// lowercased, with omitted leading parts set to "*"
+	patterns [][3]string
+}
+
+// NewColumnRedactor returns a redactor for patterns of the form column,
+// table.column or schema.table.column, where each part may use the * and ?
+// wildcards of path.Match and matching ignores case. It returns nil when
+// there are no patterns.
+func NewColumnRedactor(patterns []string) *ColumnRedactor {
+	if len(patterns) == 0 {
+		return nil
+	}
+	r := &ColumnRedactor{}
+	for _, p := range patterns {
+		parts := strings.Split(strings.ToLower(p), ".")
+		if len(parts) > 3 {
+			continue // rejected when the configuration is validated
+		}
+		var pattern [3]string
+		for i := range pattern {
+			pattern[i] = "*"
+		}
+		copy(pattern[3-len(parts):], parts)
+		r.patterns = append(r.patterns, pattern)
+	}
+	return r
+}
+
+// Matches reports whether the column schema.table.column is redacted
+func (r *ColumnRedactor) Matches(schema, table, column string) bool {
+	if r == nil {
+		return false
+	}
+	name := [3]string{strings.ToLower(schema), strings.ToLower(table), strings.ToLower(column)}
+	for _, pattern := range r.patterns {
+		matched := true
+		for i := range pattern {
+			if ok, _ := path.Match(pattern[i], name[i]); !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ResultColumns returns which columns of the result of sql are redacted,
+// or nil if none can be. A column is redacted when the table column it
+// comes from matches a pattern, however it is aliased, or when its result
+// name matches a pattern that applies to every table.
+//
+// sql is described with an unnamed prepared statement and its source
+// columns are looked up on tx, so call this before running sql, while tx
+// has no rows open.
+func (r *ColumnRedactor) ResultColumns(ctx context.Context, tx pgx.Tx, sql string) ([]bool, error) {
+	if r == nil {
+		return nil, nil
+	}
+	sd, err := tx.Conn().Prepare(ctx, "", sql)
+	if err != nil {
+		return nil, err
+	}
+	return r.redactedFields(ctx, tx, sd.Fields)
+}
+
+// redactedFields returns which of fields are redacted, looking up the
+// table columns they come from on q
+func (r *ColumnRedactor) redactedFields(ctx context.Context, q interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}, fields []pgconn.FieldDescription) ([]bool, error) {
+	redacted := make([]bool, len(fields))
+
+	var relids []uint32
+	var attnums []int16
+	var positions []int
+	for i, fd := range fields {
+		redacted[i] = r.Matches("", "", fd.Name)
+		if fd.TableOID != 0 && fd.TableAttributeNumber > 0 {
+			relids = append(relids, fd.TableOID)
+			attnums = append(attnums, int16(fd.TableAttributeNumber))
+			positions = append(positions, i)
+		}
+	}
+	if len(relids) == 0 {
+		return redacted, nil
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT f.ord::int, n.nspname, c.relname, a.attname
+		FROM unnest($1::oid[], $2::int2[]) WITH ORDINALITY AS f(relid, attnum, ord)
+		JOIN pg_class c ON c.oid = f.relid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_attribute a ON a.attrelid = f.relid AND a.attnum = f.attnum`,
+		relids, attnums)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up result columns for redaction: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ord int
+		var schema, table, column string
+		if err := rows.Scan(&ord, &schema, &table, &column); err != nil {
+			return nil, fmt.Errorf("failed to look up result columns for redaction: %w", err)
+		}
+		if r.Matches(schema, table, column) {
+			redacted[positions[ord-1]] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to look up result columns for redaction: %w", err)
+	}
+	return redacted, nil
+}
+
+// RedactRow replaces the values of redacted columns in row, NULLs
+// included, with RedactedValue
+func RedactRow(row []interface{}, redacted []bool) {
+	for i := range row {
+		if i < len(redacted) && redacted[i] {
+			row[i] = RedactedValue
+		}
+	}
+}
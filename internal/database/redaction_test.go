@@ -0,0 +1,104 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Column Redaction Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"pgedge-postgres-mcp/internal/config"
+)
+
+func TestColumnRedactor_Matches(t *testing.T) {
+	r := NewColumnRedactor([]string{"ssn", "users.email", "hr.*.salary", "*.*.password*"})
+
+	tests := []struct {
+		schema, table, column string
+		want                  bool
+	}{
+		{"public", "customers", "ssn", true},
+		{"public", "customers", "SSN", true},
+		{"public", "users", "email", true},
+		{"sales", "users", "email", true},
+		{"public", "contacts", "email", false},
+		{"hr", "employees", "salary", true},
+		{"public", "employees", "salary", false},
+		{"auth", "accounts", "password_hash", true},
+		{"public", "customers", "name", false},
+		// Result columns without a source table match global patterns only
+		{"", "", "ssn", true},
+		{"", "", "email", false},
+		{"", "", "password", true},
+	}
+	for _, tt := range tests {
+		if got := r.Matches(tt.schema, tt.table, tt.column); got != tt.want {
+			t.Errorf("Matches(%q, %q, %q) = %v, want %v", tt.schema, tt.table, tt.column, got, tt.want)
+		}
+	}
+
+	var none *ColumnRedactor
+	if none.Matches("public", "users", "ssn") {
+		t.Error("nil redactor should match nothing")
+	}
+	if NewColumnRedactor(nil) != nil {
+		t.Error("NewColumnRedactor(nil) should return nil")
+	}
+}
+
+func TestColumnRedactor_ResultColumnsByName(t *testing.T) {
+	r := NewColumnRedactor([]string{"ssn", "users.email"})
+
+	// Computed columns have no source table, so only their names are
+	// checked and no lookup query is needed
+	fields := []pgconn.FieldDescription{{Name: "id"}, {Name: "ssn"}, {Name: "email"}}
+	redacted, err := r.redactedFields(context.Background(), nil, fields)
+	if err != nil {
+		t.Fatalf("redactedFields() error: %v", err)
+	}
+	if redacted[0] || !redacted[1] || redacted[2] {
+		t.Errorf("redactedFields() = %v, want [false true false]", redacted)
+	}
+
+	// Without patterns nothing is redacted and the query is not described
+	var none *ColumnRedactor
+	redacted, err = none.ResultColumns(context.Background(), nil, "SELECT ssn FROM people")
+	if err != nil || redacted != nil {
+		t.Errorf("nil redactor ResultColumns() = %v, %v", redacted, err)
+	}
+}
+
+func TestRedactRow(t *testing.T) {
+	row := []interface{}{int64(1), "123-45-6789", nil, "Alice"}
+	RedactRow(row, []bool{false, true, true})
+	if row[0] != int64(1) || row[1] != RedactedValue || row[2] != RedactedValue || row[3] != "Alice" {
+		t.Errorf("RedactRow() = %v", row)
+	}
+}
+
+func TestClient_Redactor(t *testing.T) {
+	if NewClient(nil).Redactor() != nil {
+		t.Error("client without configuration should have no redactor")
+	}
+
+	client := NewClient(&config.NamedDatabaseConfig{
+		Name:                "main",
+		RedactColumns:       []string{"ssn"},
+		HideRedactedColumns: true,
+	})
+	if !client.Redactor().Matches("public", "people", "ssn") {
+		t.Error("configured redact_columns not applied")
+	}
+	if !client.HideRedactedColumns() {
+		t.Error("HideRedactedColumns() = false, want true")
+	}
+}
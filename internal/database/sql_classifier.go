@@ -0,0 +1,374 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// readOnlyStatementTypes lists the leading keywords accepted by ValidateReadOnlySQL
+var readOnlyStatementTypes = map[string]bool{
+	"SELECT":  true,
+	"WITH":    true,
+	"EXPLAIN": true,
+}
+
+// dataModifyingKeywords are statement keywords that write data when they
+// appear as the body of a CTE or as the target of EXPLAIN
+var dataModifyingKeywords = map[string]bool{
+	"INSERT": true,
+	"UPDATE": true,
+	"DELETE": true,
+	"MERGE":  true,
+}
+
+// explainOptionKeywords are the unparenthesized options EXPLAIN accepts
+// before the statement being explained
+var explainOptionKeywords = map[string]bool{
+	"ANALYZE": true,
+	"ANALYSE": true,
+	"VERBOSE": true,
+}
+
+// sqlToken is a lexical token from a SQL statement. String literals, quoted
+// identifiers and comments are never emitted, so keywords inside them can't
+// confuse the classifier.
+type sqlToken struct {
+	text  string // upper-cased keyword/identifier, or the punctuation character
+	depth int    // parenthesis nesting depth at the token
+}
+
+// tokenizeSQL splits SQL into statements of keyword and punctuation tokens.
+// Statements are separated by top-level semicolons; empty statements are dropped.
+func tokenizeSQL(sql string) [][]sqlToken {
+	statements, _ := splitSQL(sql)
+	return statements
+}
+
+// statementSpan is the rune range of a statement in the SQL it came from,
+// excluding its terminating semicolon
+type statementSpan struct {
+	start, end int
+}
+
+// splitSQL is tokenizeSQL that also returns where each statement is in sql
+func splitSQL(sql string) ([][]sqlToken, []statementSpan) {
+	var statements [][]sqlToken
+	var spans []statementSpan
+	var current []sqlToken
+	depth := 0
+	start := 0
+
+	runes := []rune(sql)
+	n := len(runes)
+	for i := 0; i < n; i++ {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			continue
+
+		case r == '-' && i+1 < n && runes[i+1] == '-':
+			// Line comment
+			for i < n && runes[i] != '\n' {
+				i++
+			}
+
+		case r == '/' && i+1 < n && runes[i+1] == '*':
+			// Block comment (PostgreSQL allows nesting)
+			nest := 1
+			i += 2
+			for i < n && nest > 0 {
+				if runes[i] == '/' && i+1 < n && runes[i+1] == '*' {
+					nest++
+					i++
+				} else if runes[i] == '*' && i+1 < n && runes[i+1] == '/' {
+					nest--
+					i++
+				}
+				i++
+			}
+			i--
+
+		case r == '\'':
+			// String literal; '' is an escaped quote. E'' strings also allow \'
+			escapes := i > 0 && (runes[i-1] == 'E' || runes[i-1] == 'e') &&
+				(i == 1 || !(unicode.IsLetter(runes[i-2]) || unicode.IsDigit(runes[i-2]) || runes[i-2] == '_'))
+			i++
+			for i < n {
+				if escapes && runes[i] == '\\' {
+					i += 2
+					continue
+				}
+				if runes[i] == '\'' {
+					if i+1 < n && runes[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+
+		case r == '"':
+			// Quoted identifier
+			i++
+			for i < n {
+				if runes[i] == '"' {
+					if i+1 < n && runes[i+1] == '"' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			current = append(current, sqlToken{text: `"`, depth: depth})
+
+		case r == '$' && dollarQuoteTag(runes, i) != "":
+			// Dollar-quoted string: skip to the matching closing tag
+			tag := []rune(dollarQuoteTag(runes, i))
+			i += len(tag)
+			for i < n && !hasRunePrefix(runes[i:], tag) {
+				i++
+			}
+			i += len(tag) - 1
+
+		case r == '(':
+			current = append(current, sqlToken{text: "(", depth: depth})
+			depth++
+
+		case r == ')':
+			if depth > 0 {
+				depth--
+			}
+			current = append(current, sqlToken{text: ")", depth: depth})
+
+		case r == ';' && depth == 0:
+			if len(current) > 0 {
+				statements = append(statements, current)
+				spans = append(spans, statementSpan{start, i})
+			}
+			current = nil
+			start = i + 1
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i+1 < n && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1]) || runes[i+1] == '_' || runes[i+1] == '$') {
+				i++
+			}
+			// An E/e immediately followed by a quote is an escape string prefix
+			if i+1 < n && runes[i+1] == '\'' && i == start && (r == 'E' || r == 'e') {
+				continue
+			}
+			current = append(current, sqlToken{text: strings.ToUpper(string(runes[start : i+1])), depth: depth})
+
+		default:
+			current = append(current, sqlToken{text: string(r), depth: depth})
+		}
+	}
+
+	if len(current) > 0 {
+		statements = append(statements, current)
+		spans = append(spans, statementSpan{start, n})
+	}
+
+	return statements, spans
+}
+
+// dollarQuoteTag returns the dollar-quote opening tag ($$ or $tag$) starting
+// at position i, or "" if there isn't one (e.g. a $1 parameter placeholder)
+func dollarQuoteTag(runes []rune, i int) string {
+	j := i + 1
+	for j < len(runes) && (unicode.IsLetter(runes[j]) || runes[j] == '_' || (j > i+1 && unicode.IsDigit(runes[j]))) {
+		j++
+	}
+	if j < len(runes) && runes[j] == '$' {
+		return string(runes[i : j+1])
+	}
+	return ""
+}
+
+// hasRunePrefix reports whether s begins with prefix
+func hasRunePrefix(s, prefix []rune) bool {
+	if len(s) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// StatementType returns the leading keyword of a single SQL statement
+// (e.g. "SELECT", "INSERT"), ignoring comments and leading parentheses.
+// Returns "" if the statement contains no keyword.
+func StatementType(sql string) string {
+	statements := tokenizeSQL(sql)
+	if len(statements) == 0 {
+		return ""
+	}
+	return leadingKeyword(statements[0])
+}
+
+// TrimStatement returns a single SQL statement without its terminating
+// semicolon, so it can be embedded in a larger statement (e.g. as a
+// subquery). Anything after the semicolon is dropped; comments before it
+// are kept, so a line comment may end the returned text. ok is false if sql
+// isn't exactly one statement.
+func TrimStatement(sql string) (stmt string, ok bool) {
+	statements, spans := splitSQL(sql)
+	if len(statements) != 1 {
+		return "", false
+	}
+	return strings.TrimSpace(string([]rune(sql)[spans[0].start:spans[0].end])), true
+}
+
+// leadingKeyword returns the first keyword token of a statement
+func leadingKeyword(tokens []sqlToken) string {
+	for _, tok := range tokens {
+		if tok.text == "(" {
+			continue
+		}
+		return tok.text
+	}
+	return ""
+}
+
+// ValidateReadOnlySQL checks that sql is a single read-only statement.
+// It rejects multiple statements, statements that don't start with
+// SELECT/WITH/EXPLAIN, data-modifying CTEs, SELECT ... INTO, and EXPLAIN of
+// a data-modifying statement. The error names the offending statement type.
+func ValidateReadOnlySQL(sql string) error {
+	statements := tokenizeSQL(sql)
+	if len(statements) == 0 {
+		return fmt.Errorf("no SQL statement found")
+	}
+	if len(statements) > 1 {
+		return fmt.Errorf("multiple SQL statements are not allowed (found %d); submit one statement at a time", len(statements))
+	}
+
+	return validateReadOnlyTokens(statements[0])
+}
+
+// validateReadOnlyTokens applies the read-only rules to a tokenized statement
+func validateReadOnlyTokens(tokens []sqlToken) error {
+	stmtType := leadingKeyword(tokens)
+	if !readOnlyStatementTypes[stmtType] {
+		return fmt.Errorf("%s statements are not allowed in read-only mode; only SELECT, WITH and EXPLAIN are permitted", describeStatementType(stmtType))
+	}
+
+	switch stmtType {
+	case "EXPLAIN":
+		return validateExplainTarget(tokens)
+	case "WITH":
+		if kw := findDataModifyingCTE(tokens); kw != "" {
+			return fmt.Errorf("data-modifying %s inside WITH is not allowed in read-only mode", kw)
+		}
+	}
+
+	// SELECT ... INTO creates a table
+	for i, tok := range tokens {
+		if tok.text == "INTO" && tok.depth == 0 && i > 0 {
+			return fmt.Errorf("SELECT INTO statements are not allowed in read-only mode")
+		}
+	}
+
+	return nil
+}
+
+// validateExplainTarget checks the statement being explained, since
+// EXPLAIN ANALYZE executes it
+func validateExplainTarget(tokens []sqlToken) error {
+	i := 1
+	// Skip a parenthesized option list: EXPLAIN (ANALYZE, BUFFERS) ...
+	if i < len(tokens) && tokens[i].text == "(" {
+		for i < len(tokens) && !(tokens[i].text == ")" && tokens[i].depth == 0) {
+			i++
+		}
+		i++
+	}
+	// Skip legacy unparenthesized options: EXPLAIN ANALYZE VERBOSE ...
+	for i < len(tokens) && explainOptionKeywords[tokens[i].text] {
+		i++
+	}
+
+	if i >= len(tokens) {
+		return fmt.Errorf("EXPLAIN requires a statement to explain")
+	}
+
+	target := tokens[i:]
+	if kw := leadingKeyword(target); dataModifyingKeywords[kw] {
+		return fmt.Errorf("EXPLAIN of %s statements is not allowed in read-only mode", kw)
+	}
+
+	return validateReadOnlyTokens(rebaseDepth(target))
+}
+
+// rebaseDepth shifts token depths so the first token is at depth 0
+func rebaseDepth(tokens []sqlToken) []sqlToken {
+	if len(tokens) == 0 {
+		return tokens
+	}
+	base := tokens[0].depth
+	out := make([]sqlToken, len(tokens))
+	for i, tok := range tokens {
+		out[i] = sqlToken{text: tok.text, depth: tok.depth - base}
+	}
+	return out
+}
+
+// DataModifyingCTE returns the data-modifying keyword (INSERT, UPDATE,
+// DELETE or MERGE) used in a WITH statement, either as the body of a CTE or
+// as its main statement, or "" if the statement doesn't modify data or isn't
+// a WITH statement.
+func DataModifyingCTE(sql string) string {
+	statements := tokenizeSQL(sql)
+	if len(statements) == 0 || leadingKeyword(statements[0]) != "WITH" {
+		return ""
+	}
+	return findDataModifyingCTE(statements[0])
+}
+
+// findDataModifyingCTE returns the data-modifying keyword used as the body
+// of a CTE (e.g. WITH d AS (DELETE ...)), or "" if none is found
+func findDataModifyingCTE(tokens []sqlToken) string {
+	for i := 1; i < len(tokens); i++ {
+		if dataModifyingKeywords[tokens[i].text] && tokens[i-1].text == "(" {
+			return tokens[i].text
+		}
+	}
+	// The main statement of a WITH query can itself be data-modifying:
+	// WITH x AS (SELECT ...) DELETE FROM t USING x ...
+	for i, tok := range tokens {
+		if tok.depth != 0 || !dataModifyingKeywords[tok.text] {
+			continue
+		}
+		// Row locking clauses (FOR UPDATE, FOR NO KEY UPDATE) aren't writes
+		if tok.text == "UPDATE" && i > 0 && (tokens[i-1].text == "FOR" || tokens[i-1].text == "KEY") {
+			continue
+		}
+		return tok.text
+	}
+	return ""
+}
+
+// describeStatementType makes an empty or punctuation statement type readable
+func describeStatementType(stmtType string) string {
+	if stmtType == "" || !unicode.IsLetter([]rune(stmtType)[0]) {
+		return "Unrecognized"
+	}
+	return stmtType
+}
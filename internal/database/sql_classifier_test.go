@@ -0,0 +1,129 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateReadOnlySQL(t *testing.T) {
+	tests := []struct {
+		name        string
+		sql         string
+		expectError string // empty means the statement must be accepted
+	}{
+		{"simple select", "SELECT * FROM users", ""},
+		{"select with trailing semicolon", "SELECT 1;", ""},
+		{"lowercase select", "select id from orders where status = 'open'", ""},
+		{"parenthesized select", "(SELECT 1) UNION (SELECT 2)", ""},
+		{"leading comment", "-- list users\nSELECT * FROM users", ""},
+		{"block comment", "/* outer /* nested */ */ SELECT 1", ""},
+		{"read-only CTE", "WITH recent AS (SELECT * FROM orders) SELECT count(*) FROM recent", ""},
+		{"explain select", "EXPLAIN SELECT * FROM users", ""},
+		{"explain analyze options", "EXPLAIN (ANALYZE, BUFFERS) SELECT * FROM users", ""},
+		{"keyword in string literal", "SELECT 'DELETE FROM users; DROP TABLE x' AS s", ""},
+		{"keyword in quoted identifier", `SELECT "delete" FROM t`, ""},
+		{"keyword in dollar quote", "SELECT $tag$; DROP TABLE users; $tag$", ""},
+		{"escape string", `SELECT E'it\'s; DROP TABLE x'`, ""},
+		{"select for update in CTE query", "WITH x AS (SELECT 1) SELECT * FROM t FOR UPDATE", ""},
+
+		{"insert", "INSERT INTO users (name) VALUES ('x')", "INSERT statements are not allowed"},
+		{"update", "UPDATE users SET name = 'x'", "UPDATE statements are not allowed"},
+		{"delete", "DELETE FROM users", "DELETE statements are not allowed"},
+		{"drop", "DROP TABLE users", "DROP statements are not allowed"},
+		{"truncate after comment", "/* harmless */ TRUNCATE users", "TRUNCATE statements are not allowed"},
+		{"stacked queries", "SELECT 1; DROP TABLE users", "multiple SQL statements"},
+		{"stacked selects", "SELECT 1; SELECT 2", "multiple SQL statements"},
+		{"data-modifying CTE", "WITH d AS (DELETE FROM users RETURNING *) SELECT * FROM d", "data-modifying DELETE inside WITH"},
+		{"CTE with modifying main statement", "WITH x AS (SELECT 1) UPDATE t SET a = 1", "data-modifying UPDATE inside WITH"},
+		{"explain analyze delete", "EXPLAIN ANALYZE DELETE FROM users", "EXPLAIN of DELETE"},
+		{"explain options insert", "EXPLAIN (ANALYZE TRUE) INSERT INTO t VALUES (1)", "EXPLAIN of INSERT"},
+		{"select into", "SELECT * INTO new_table FROM users", "SELECT INTO"},
+		{"empty", "   -- nothing\n", "no SQL statement"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReadOnlySQL(tt.sql)
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("ValidateReadOnlySQL(%q) unexpected error: %v", tt.sql, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("ValidateReadOnlySQL(%q) expected error containing %q, got nil", tt.sql, tt.expectError)
+			}
+			if !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("ValidateReadOnlySQL(%q) error = %q, want it to contain %q", tt.sql, err.Error(), tt.expectError)
+			}
+		})
+	}
+}
+
+func TestStatementType(t *testing.T) {
+	tests := map[string]string{
+		"SELECT 1":                   "SELECT",
+		"  insert into t values (1)": "INSERT",
+		"-- comment\nWITH x AS (SELECT 1) SELECT * FROM x": "WITH",
+		"((SELECT 1))": "SELECT",
+		"":             "",
+	}
+
+	for sql, expected := range tests {
+		if got := StatementType(sql); got != expected {
+			t.Errorf("StatementType(%q) = %q, want %q", sql, got, expected)
+		}
+	}
+}
+
+func TestTrimStatement(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+		ok   bool
+	}{
+		{"SELECT 1", "SELECT 1", true},
+		{"SELECT 1;", "SELECT 1", true},
+		{"SELECT 1; -- done\n", "SELECT 1", true},
+		{";; SELECT 1 ;;", "SELECT 1", true},
+		{"SELECT 1 -- note", "SELECT 1 -- note", true},
+		{"SELECT ';' AS s; /* c */", "SELECT ';' AS s", true},
+		{"SELECT 1; SELECT 2", "", false},
+		{"-- nothing", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := TrimStatement(tt.sql)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("TrimStatement(%q) = %q, %v; want %q, %v", tt.sql, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDataModifyingCTE(t *testing.T) {
+	tests := map[string]string{
+		"WITH x AS (SELECT 1) SELECT * FROM x":                                     "",
+		"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d":                    "DELETE",
+		"with i as (insert into t values (1) returning id) select id from i":       "INSERT",
+		"WITH x AS (SELECT * FROM t FOR UPDATE) SELECT * FROM x":                   "",
+		"WITH x AS (SELECT 'DELETE' AS s) SELECT s FROM x":                         "",
+		"WITH x AS (SELECT id FROM t) UPDATE t SET v = 1 FROM x WHERE t.id = x.id": "UPDATE",
+		"DELETE FROM t": "",
+	}
+
+	for sql, expected := range tests {
+		if got := DataModifyingCTE(sql); got != expected {
+			t.Errorf("DataModifyingCTE(%q) = %q, want %q", sql, got, expected)
+		}
+	}
+}
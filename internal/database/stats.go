@@ -0,0 +1,111 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package database
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// queryTracer, if set, traces the queries of every connection pool
+var queryTracer pgx.QueryTracer
+
+// SetQueryTracer sets the tracers for the queries of connection pools
+// created after the call, such as the metrics query timer and the audit
+// log. Call it once at startup with all of them.
+func SetQueryTracer(tracers ...pgx.QueryTracer) {
+	switch len(tracers) {
+	case 0:
+		queryTracer = nil
+	case 1:
+		queryTracer = tracers[0]
+	default:
+		queryTracer = multiQueryTracer(tracers)
+	}
+}
+
+// multiQueryTracer passes each query to several tracers in turn
+type multiQueryTracer []pgx.QueryTracer
+
+// TraceQueryStart implements pgx.QueryTracer
+func (m multiQueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	for _, tracer := range m {
+		ctx = tracer.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (m multiQueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	for _, tracer := range m {
+		tracer.TraceQueryEnd(ctx, conn, data)
+	}
+}
+
+// DatabaseStats summarizes the connection pools and loaded metadata of one
+// configured database across all tokens
+type DatabaseStats struct {
+	Name          string
+	AcquiredConns int32 // Connections in use
+	IdleConns     int32 // Connections open and idle
+	Tables        int   // Tables and views in the loaded metadata (largest of any client)
+}
+
+// DatabaseStats returns statistics for each configured database, sorted by name
+func (cm *ClientManager) DatabaseStats() []DatabaseStats {
+	cm.mu.RLock()
+	byName := make(map[string]*DatabaseStats, len(cm.dbConfigs))
+	for name := range cm.dbConfigs {
+		byName[name] = &DatabaseStats{Name: name}
+	}
+	for _, tokenClients := range cm.clients {
+		for dbName, client := range tokenClients {
+			stats, exists := byName[dbName]
+			if !exists {
+				stats = &DatabaseStats{Name: dbName}
+				byName[dbName] = stats
+			}
+			acquired, idle, tables := client.stats()
+			stats.AcquiredConns += acquired
+			stats.IdleConns += idle
+			stats.Tables = max(stats.Tables, tables)
+		}
+	}
+	cm.mu.RUnlock()
+
+	result := make([]DatabaseStats, 0, len(byName))
+	for _, stats := range byName {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// stats returns the client's pooled connections and the number of tables in
+// its default connection's metadata
+func (c *Client) stats() (acquired, idle int32, tables int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for connStr, conn := range c.connections {
+		if conn.Pool != nil {
+			poolStat := conn.Pool.Stat()
+			acquired += poolStat.AcquiredConns()
+			idle += poolStat.IdleConns()
+		}
+		if connStr == c.defaultConnStr {
+			tables = len(conn.Metadata)
+		}
+	}
+	return acquired, idle, tables
+}
@@ -10,10 +10,18 @@
 
 package database
 
+import "pgedge-postgres-mcp/internal/config"
+
 // NewTestClient creates a database client for testing with mock data
 // This allows tests in other packages to create clients with predetermined metadata
 func NewTestClient(connStr string, metadata map[string]TableInfo) *Client {
-	client := NewClient(nil)
+	return NewTestClientWithConfig(connStr, metadata, nil)
+}
+
+// NewTestClientWithConfig creates a mock-data test client that also carries
+// per-database settings
+func NewTestClientWithConfig(connStr string, metadata map[string]TableInfo, dbConfig *config.NamedDatabaseConfig) *Client {
+	client := NewClient(dbConfig)
 
 	// Add mock connection info
 	client.connections[connStr] = &ConnectionInfo{
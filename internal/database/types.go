@@ -17,6 +17,20 @@ type TableInfo struct {
 	TableType   string // 'TABLE', 'VIEW', or 'MATERIALIZED VIEW'
 	Description string
 	Columns     []ColumnInfo
+	PrimaryKey  []string         // Primary key columns in key order, empty if none
+	UniqueKeys  [][]string       // Column sets of unique constraints and indexes, excluding the primary key
+	ForeignKeys []ForeignKeyInfo // Foreign key constraints on this table, sorted by name
+}
+
+// ForeignKeyInfo describes a foreign key constraint. Columns and
+// ReferencedColumns are in constraint order, so Columns[i] references
+// ReferencedColumns[i].
+type ForeignKeyInfo struct {
+	ConstraintName    string
+	Columns           []string
+	ReferencedSchema  string
+	ReferencedTable   string
+	ReferencedColumns []string
 }
 
 // ColumnInfo contains information about a database column
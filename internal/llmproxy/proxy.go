@@ -26,9 +26,15 @@ type Config struct {
 	Model           string
 	AnthropicAPIKey string
 	OpenAIAPIKey    string
+	OpenAIBaseURL   string // OpenAI-compatible endpoint; empty means api.openai.com
 	OllamaURL       string
 	MaxTokens       int
 	Temperature     float64
+
+	SystemPromptAppend string         // Domain context appended to every system prompt
+	PinPostgresDialect bool           // Add PostgreSQL dialect instructions to the system prompt
+	Examples           []chat.Example // Few-shot question/SQL examples
+	MaxExamples        int            // How many examples to include; 0 means the default
 }
 
 // Message represents a message in the chat conversation
@@ -83,6 +89,7 @@ type ChatRequest struct {
 	Provider string    `json:"provider,omitempty"` // Override default provider
 	Model    string    `json:"model,omitempty"`    // Override default model
 	Debug    bool      `json:"debug,omitempty"`    // Enable debug mode for token usage
+	Hints    string    `json:"hints,omitempty"`    // Domain context for this request's system prompt
 }
 
 // ChatResponse represents the response body for POST /api/llm/chat
@@ -110,10 +117,14 @@ func HandleProviders(w http.ResponseWriter, r *http.Request, config *Config) {
 		})
 	}
 
-	if config.OpenAIAPIKey != "" {
+	if config.OpenAIAPIKey != "" || config.OpenAIBaseURL != "" {
+		display := "OpenAI"
+		if config.OpenAIBaseURL != "" {
+			display = "OpenAI-compatible"
+		}
 		providers = append(providers, ProviderInfo{
 			Name:      "openai",
-			Display:   "OpenAI",
+			Display:   display,
 			IsDefault: config.Provider == "openai",
 		})
 	}
@@ -160,11 +171,11 @@ func HandleModels(w http.ResponseWriter, r *http.Request, config *Config) {
 		}
 		client = chat.NewAnthropicClient(config.AnthropicAPIKey, config.Model, config.MaxTokens, config.Temperature, false)
 	case "openai":
-		if config.OpenAIAPIKey == "" {
+		if config.OpenAIAPIKey == "" && config.OpenAIBaseURL == "" {
 			http.Error(w, "OpenAI API key not configured", http.StatusBadRequest)
 			return
 		}
-		client = chat.NewOpenAIClient(config.OpenAIAPIKey, config.Model, config.MaxTokens, config.Temperature, false)
+		client = chat.NewOpenAIClient(config.OpenAIBaseURL, config.OpenAIAPIKey, config.Model, config.MaxTokens, config.Temperature, false)
 	case "ollama":
 		if config.OllamaURL == "" {
 			http.Error(w, "Ollama URL not configured", http.StatusBadRequest)
@@ -201,6 +212,30 @@ func HandleModels(w http.ResponseWriter, r *http.Request, config *Config) {
 	}
 }
 
+// NewClient creates an LLM client for provider and model using the keys and
+// URLs in config
+func NewClient(config *Config, provider, model string, debug bool) (chat.LLMClient, error) {
+	switch provider {
+	case "anthropic":
+		if config.AnthropicAPIKey == "" {
+			return nil, fmt.Errorf("Anthropic API key not configured")
+		}
+		return chat.NewAnthropicClient(config.AnthropicAPIKey, model, config.MaxTokens, config.Temperature, debug), nil
+	case "openai":
+		if config.OpenAIAPIKey == "" && config.OpenAIBaseURL == "" {
+			return nil, fmt.Errorf("OpenAI API key not configured")
+		}
+		return chat.NewOpenAIClient(config.OpenAIBaseURL, config.OpenAIAPIKey, model, config.MaxTokens, config.Temperature, debug), nil
+	case "ollama":
+		if config.OllamaURL == "" {
+			return nil, fmt.Errorf("Ollama URL not configured")
+		}
+		return chat.NewOllamaClient(config.OllamaURL, model, debug), nil
+	default:
+		return nil, fmt.Errorf("Unsupported provider: %s", provider)
+	}
+}
+
 // HandleChat handles POST /api/llm/chat
 func HandleChat(w http.ResponseWriter, r *http.Request, config *Config) {
 	if r.Method != http.MethodPost {
@@ -234,28 +269,9 @@ func HandleChat(w http.ResponseWriter, r *http.Request, config *Config) {
 	}
 
 	// Create LLM client with debug mode from request
-	var client chat.LLMClient
-	switch provider {
-	case "anthropic":
-		if config.AnthropicAPIKey == "" {
-			http.Error(w, "Anthropic API key not configured", http.StatusBadRequest)
-			return
-		}
-		client = chat.NewAnthropicClient(config.AnthropicAPIKey, model, config.MaxTokens, config.Temperature, req.Debug)
-	case "openai":
-		if config.OpenAIAPIKey == "" {
-			http.Error(w, "OpenAI API key not configured", http.StatusBadRequest)
-			return
-		}
-		client = chat.NewOpenAIClient(config.OpenAIAPIKey, model, config.MaxTokens, config.Temperature, req.Debug)
-	case "ollama":
-		if config.OllamaURL == "" {
-			http.Error(w, "Ollama URL not configured", http.StatusBadRequest)
-			return
-		}
-		client = chat.NewOllamaClient(config.OllamaURL, model, req.Debug)
-	default:
-		http.Error(w, fmt.Sprintf("Unsupported provider: %s", provider), http.StatusBadRequest)
+	client, err := NewClient(config, provider, model, req.Debug)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -271,7 +287,13 @@ func HandleChat(w http.ResponseWriter, r *http.Request, config *Config) {
 
 	// Call LLM - pass tools as []interface{} to avoid import cycle
 	// The chat client will access tool fields which are structurally identical to mcp.Tool
-	ctx := context.Background()
+	ctx := chat.WithSystemPromptOptions(context.Background(), chat.SystemPromptOptions{
+		PinPostgresDialect: config.PinPostgresDialect,
+		Append:             config.SystemPromptAppend,
+		Examples:           config.Examples,
+		MaxExamples:        config.MaxExamples,
+		Hints:              req.Hints,
+	})
 	llmResponse, err := client.Chat(ctx, chatMessages, req.Tools)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("LLM error: %v", err), http.StatusInternalServerError)
@@ -15,6 +15,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -98,6 +99,30 @@ func TestHandleProviders_NoProviders(t *testing.T) {
 	}
 }
 
+func TestHandleProviders_OpenAICompatible(t *testing.T) {
+	config := &Config{
+		Provider:      "openai",
+		OpenAIBaseURL: "http://localhost:8000/v1",
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/llm/providers", nil)
+	w := httptest.NewRecorder()
+
+	HandleProviders(w, req, config)
+
+	var response ProvidersResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(response.Providers) != 1 {
+		t.Fatalf("expected 1 provider, got %d", len(response.Providers))
+	}
+	if response.Providers[0].Name != "openai" || response.Providers[0].Display != "OpenAI-compatible" {
+		t.Errorf("unexpected provider: %+v", response.Providers[0])
+	}
+}
+
 func TestHandleProviders_AllProviders(t *testing.T) {
 	config := &Config{
 		Provider:        "openai",
@@ -367,6 +392,51 @@ func TestHandleChat_OverrideProvider(t *testing.T) {
 	}
 }
 
+func TestHandleChat_SystemPromptContext(t *testing.T) {
+	var systemPrompt string
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode Ollama request: %v", err)
+		}
+		if len(req.Messages) > 0 && req.Messages[0].Role == "system" {
+			systemPrompt = req.Messages[0].Content
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"test","message":{"role":"assistant","content":"ok"},"done":true}`))
+	}))
+	defer ollama.Close()
+
+	config := &Config{
+		Provider:           "ollama",
+		Model:              "test",
+		OllamaURL:          ollama.URL,
+		SystemPromptAppend: "Amounts are stored in cents.",
+		PinPostgresDialect: true,
+	}
+	bodyBytes, _ := json.Marshal(ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Total sales?"}},
+		Hints:    "Soft-deleted rows have deleted_at set.",
+	})
+
+	w := httptest.NewRecorder()
+	HandleChat(w, httptest.NewRequest(http.MethodPost, "/api/llm/chat", bytes.NewReader(bodyBytes)), config)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	for _, want := range []string{"Amounts are stored in cents.", "Soft-deleted rows have deleted_at set.", "Write PostgreSQL SQL only"} {
+		if !strings.Contains(systemPrompt, want) {
+			t.Errorf("system prompt missing %q:\n%s", want, systemPrompt)
+		}
+	}
+}
+
 // Test struct serialization
 func TestConfigStruct(t *testing.T) {
 	config := Config{
@@ -557,3 +627,20 @@ func TestModelsResponseStruct(t *testing.T) {
 		t.Errorf("expected 2 models, got %d", len(decoded.Models))
 	}
 }
+
+func TestNewClient(t *testing.T) {
+	config := &Config{AnthropicAPIKey: "test-key", OllamaURL: "http://localhost:11434"}
+
+	for _, provider := range []string{"anthropic", "ollama"} {
+		if _, err := NewClient(config, provider, "model", false); err != nil {
+			t.Errorf("NewClient(%q) returned error: %v", provider, err)
+		}
+	}
+
+	if _, err := NewClient(config, "openai", "model", false); err == nil || err.Error() != "OpenAI API key not configured" {
+		t.Errorf("NewClient(openai) error = %v, want 'OpenAI API key not configured'", err)
+	}
+	if _, err := NewClient(config, "unknown", "model", false); err == nil || err.Error() != "Unsupported provider: unknown" {
+		t.Errorf("NewClient(unknown) error = %v, want 'Unsupported provider: unknown'", err)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -29,30 +30,57 @@ const (
 )
 
 var (
-	// currentLevel is the minimum log level to output
+	// currentLevel is the minimum log level to output. It can change while
+	// the server runs (on a configuration reload), so it is atomic.
 	// Default to ERROR to avoid cluttering CLI output with operational logs
-	currentLevel = LevelError
+	currentLevel atomic.Int32
 
-	// Environment variable to control log level
-	envLogLevel = "PGEDGE_MCP_LOG_LEVEL"
+	// envLevelSet records that the level came from the environment, which
+	// takes priority over the configuration file
+	envLevelSet bool
+
+	// Environment variables to control log level, most specific first
+	envLogLevels = []string{"PGEDGE_MCP_LOG_LEVEL", "PGEDGE_LOG_LEVEL"}
 )
 
 func init() {
+	currentLevel.Store(int32(LevelError))
+
 	// Read log level from environment
-	if level := os.Getenv(envLogLevel); level != "" {
-		switch strings.ToLower(level) {
-		case "debug":
-			currentLevel = LevelDebug
-		case "info":
-			currentLevel = LevelInfo
-		case "warn", "warning":
-			currentLevel = LevelWarn
-		case "error":
-			currentLevel = LevelError
+	for _, name := range envLogLevels {
+		if level, ok := ParseLevel(os.Getenv(name)); ok {
+			currentLevel.Store(int32(level))
+			envLevelSet = true
+			break
 		}
 	}
 }
 
+// ParseLevel converts a level name (debug, info, warn/warning, error) to a
+// LogLevel, case-insensitively. It returns false for unknown names.
+func ParseLevel(name string) (LogLevel, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, true
+	case "info":
+		return LevelInfo, true
+	case "warn", "warning":
+		return LevelWarn, true
+	case "error":
+		return LevelError, true
+	}
+	return LevelError, false
+}
+
+// TokenPrefix shortens a token hash for use as a log field, so entries can
+// be correlated with -list-tokens output without logging the full hash
+func TokenPrefix(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
 // levelString returns the string representation of a log level
 func (l LogLevel) String() string {
 	switch l {
@@ -79,10 +107,14 @@ type logEntry struct {
 
 // log writes a structured log message if the level is enabled
 func log(level LogLevel, message string, keyvals ...interface{}) {
-	if level < currentLevel {
+	if level < GetLevel() {
 		return
 	}
+	write(level, message, keyvals...)
+}
 
+// write writes a structured log message unconditionally
+func write(level LogLevel, message string, keyvals ...interface{}) {
 	entry := logEntry{
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Level:     level.String(),
@@ -94,7 +126,15 @@ func log(level LogLevel, message string, keyvals ...interface{}) {
 	for i := 0; i < len(keyvals); i += 2 {
 		if i+1 < len(keyvals) {
 			key := fmt.Sprintf("%v", keyvals[i])
-			entry.Fields[key] = keyvals[i+1]
+			value := keyvals[i+1]
+			if sql, ok := value.(SQL); ok {
+				text, loggable := LoggableQuery(string(sql))
+				if !loggable {
+					continue
+				}
+				value = text
+			}
+			entry.Fields[key] = value
 		}
 	}
 
@@ -129,12 +169,27 @@ func Error(message string, keyvals ...interface{}) {
 	log(LevelError, message, keyvals...)
 }
 
+// Write logs a message at the given level regardless of the minimum
+// level, for entries the operator has enabled with a dedicated switch
+func Write(level LogLevel, message string, keyvals ...interface{}) {
+	write(level, message, keyvals...)
+}
+
 // SetLevel sets the minimum log level to output
 func SetLevel(level LogLevel) {
-	currentLevel = level
+	currentLevel.Store(int32(level))
+}
+
+// SetConfiguredLevel sets the log level named in the configuration file,
+// unless an environment variable set it. Empty or unknown names leave the
+// level unchanged.
+func SetConfiguredLevel(name string) {
+	if level, ok := ParseLevel(name); ok && !envLevelSet {
+		SetLevel(level)
+	}
 }
 
 // GetLevel returns the current minimum log level
 func GetLevel() LogLevel {
-	return currentLevel
+	return LogLevel(currentLevel.Load())
 }
@@ -278,3 +278,61 @@ func TestLogWithOddNumberOfKeyValues(t *testing.T) {
 		t.Error("key2 should not exist without a value")
 	}
 }
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected LogLevel
+		ok       bool
+	}{
+		{"debug", LevelDebug, true},
+		{"INFO", LevelInfo, true},
+		{" warning ", LevelWarn, true},
+		{"warn", LevelWarn, true},
+		{"error", LevelError, true},
+		{"", LevelError, false},
+		{"verbose", LevelError, false},
+	}
+
+	for _, tt := range tests {
+		level, ok := ParseLevel(tt.name)
+		if level != tt.expected || ok != tt.ok {
+			t.Errorf("ParseLevel(%q) = (%v, %v), want (%v, %v)", tt.name, level, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestSetConfiguredLevel(t *testing.T) {
+	originalLevel, originalEnv := GetLevel(), envLevelSet
+	defer func() {
+		SetLevel(originalLevel)
+		envLevelSet = originalEnv
+	}()
+
+	envLevelSet = false
+	SetLevel(LevelError)
+	SetConfiguredLevel("info")
+	if got := GetLevel(); got != LevelInfo {
+		t.Errorf("GetLevel() = %v, want %v", got, LevelInfo)
+	}
+	SetConfiguredLevel("")
+	if got := GetLevel(); got != LevelInfo {
+		t.Errorf("empty level changed GetLevel() to %v", got)
+	}
+
+	// The environment takes priority over the configuration file
+	envLevelSet = true
+	SetConfiguredLevel("debug")
+	if got := GetLevel(); got != LevelInfo {
+		t.Errorf("GetLevel() = %v, want %v (set by the environment)", got, LevelInfo)
+	}
+}
+
+func TestTokenPrefix(t *testing.T) {
+	if got := TokenPrefix("0123456789abcdef0123"); got != "0123456789ab" {
+		t.Errorf("TokenPrefix() = %q, want 0123456789ab", got)
+	}
+	if got := TokenPrefix("short"); got != "short" {
+		t.Errorf("TokenPrefix() = %q, want short", got)
+	}
+}
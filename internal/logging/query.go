@@ -0,0 +1,226 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package logging
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// Query text logging modes (see config.QueryLoggingConfig)
+const (
+	queryLogFull       = "full"
+	queryLogNormalized = "normalized"
+	queryLogNone       = "none"
+)
+
+// queryLogMode holds the current query text logging mode
+var queryLogMode atomic.Value
+
+func init() {
+	queryLogMode.Store(queryLogNormalized)
+}
+
+// SetQueryLogMode sets how SQL text appears in logs: "full" as written,
+// "normalized" with literals replaced by placeholders, or "none" to omit
+// it. Unknown values select "normalized".
+func SetQueryLogMode(mode string) {
+	switch mode {
+	case queryLogFull, queryLogNone:
+	default:
+		mode = queryLogNormalized
+	}
+	queryLogMode.Store(mode)
+}
+
+// LoggableQuery returns the form of sql that may be logged under the
+// current mode, and false if query text must not be logged at all
+func LoggableQuery(sql string) (string, bool) {
+	switch queryLogMode.Load().(string) {
+	case queryLogFull:
+		return sql, true
+	case queryLogNone:
+		return "", false
+	default:
+		return NormalizeSQL(sql), true
+	}
+}
+
+// SQL marks a log field value as SQL text. The logger writes it in the
+// form LoggableQuery allows, and drops the field when query text must not
+// be logged.
+type SQL string
+
+// NormalizeSQL replaces string, dollar-quoted, bit-string and numeric
+// literals with $n placeholders numbered after the highest parameter
+// already in the query, as pg_stat_statements does. Comments are removed
+// and runs of whitespace collapsed, since either may carry sensitive text.
+// Identifiers, keywords and operators are kept.
+func NormalizeSQL(sql string) string {
+	type piece struct {
+		text    string
+		literal bool
+	}
+	var pieces []piece
+	maxParam := 0
+	space := false
+
+	emit := func(text string, literal bool) {
+		if space && len(pieces) > 0 {
+			pieces = append(pieces, piece{text: " "})
+		}
+		space = false
+		pieces = append(pieces, piece{text: text, literal: literal})
+	}
+
+	runes := []rune(sql)
+	n := len(runes)
+	for i := 0; i < n; i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+
+		case r == '-' && i+1 < n && runes[i+1] == '-':
+			for i < n && runes[i] != '\n' {
+				i++
+			}
+			space = true
+
+		case r == '/' && i+1 < n && runes[i+1] == '*':
+			nest := 1
+			i += 2
+			for i < n && nest > 0 {
+				if runes[i] == '/' && i+1 < n && runes[i+1] == '*' {
+					nest++
+					i++
+				} else if runes[i] == '*' && i+1 < n && runes[i+1] == '/' {
+					nest--
+					i++
+				}
+				i++
+			}
+			i--
+			space = true
+
+		case r == '\'':
+			i = skipQuoted(runes, i, '\'', false)
+			emit("", true)
+
+		case r == '"':
+			end := skipQuoted(runes, i, '"', false)
+			emit(string(runes[i:min(end+1, n)]), false)
+			i = end
+
+		case r == '$' && i+1 < n && unicode.IsDigit(runes[i+1]):
+			j := i + 1
+			for j < n && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			if p, err := strconv.Atoi(string(runes[i+1 : j])); err == nil && p > maxParam {
+				maxParam = p
+			}
+			emit(string(runes[i:j]), false)
+			i = j - 1
+
+		case r == '$' && dollarTag(runes, i) != "":
+			tag := []rune(dollarTag(runes, i))
+			i += len(tag)
+			for i < n && !strings.HasPrefix(string(runes[i:min(i+len(tag), n)]), string(tag)) {
+				i++
+			}
+			i += len(tag) - 1
+			emit("", true)
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < n && unicode.IsDigit(runes[i+1])):
+			for i+1 < n && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.' || runes[i+1] == '_' ||
+				((runes[i+1] == 'e' || runes[i+1] == 'E') && i+2 < n &&
+					(unicode.IsDigit(runes[i+2]) || runes[i+2] == '-' || runes[i+2] == '+'))) {
+				if runes[i+1] == 'e' || runes[i+1] == 'E' {
+					i++
+				}
+				i++
+			}
+			emit("", true)
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i+1 < n && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1]) || runes[i+1] == '_' || runes[i+1] == '$') {
+				i++
+			}
+			word := string(runes[start : i+1])
+			// E'', B'', X'' and U&'' prefixes belong to the literal that follows
+			if i+1 < n && runes[i+1] == '\'' && strings.Contains("eEbBxXnN", word) && len(word) == 1 {
+				i = skipQuoted(runes, i+1, '\'', word == "e" || word == "E")
+				emit("", true)
+				continue
+			}
+			if (word == "U" || word == "u") && i+2 < n && runes[i+1] == '&' && runes[i+2] == '\'' {
+				i = skipQuoted(runes, i+2, '\'', false)
+				emit("", true)
+				continue
+			}
+			emit(word, false)
+
+		default:
+			emit(string(r), false)
+		}
+	}
+
+	var sb strings.Builder
+	next := maxParam
+	for _, p := range pieces {
+		if p.literal {
+			next++
+			sb.WriteString("$" + strconv.Itoa(next))
+		} else {
+			sb.WriteString(p.text)
+		}
+	}
+	return sb.String()
+}
+
+// skipQuoted returns the index of the quote closing the quoted text that
+// starts at runes[start], treating a doubled quote (and, for escape
+// strings, a backslash) as an escape
+func skipQuoted(runes []rune, start int, quote rune, backslashEscapes bool) int {
+	i := start + 1
+	for i < len(runes) {
+		switch {
+		case backslashEscapes && runes[i] == '\\':
+			i += 2
+			continue
+		case runes[i] == quote:
+			if i+1 < len(runes) && runes[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i
+		}
+		i++
+	}
+	return len(runes) - 1
+}
+
+// dollarTag returns the dollar-quote opening tag ($$ or $tag$) at position
+// i, or "" if there isn't one
+func dollarTag(runes []rune, i int) string {
+	j := i + 1
+	for j < len(runes) && (unicode.IsLetter(runes[j]) || runes[j] == '_' || (j > i+1 && unicode.IsDigit(runes[j]))) {
+		j++
+	}
+	if j < len(runes) && runes[j] == '$' {
+		return string(runes[i : j+1])
+	}
+	return ""
+}
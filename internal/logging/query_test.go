@@ -0,0 +1,111 @@
+/*-------------------------------------------------------------------------
+*
+ * pgEdge Natural Language Agent
+*
+* Portions copyright (c) 2025, pgEdge, Inc.
+* This software is released under The PostgreSQL License
+*
+*-------------------------------------------------------------------------
+*/
+
+package logging
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"strings and numbers", "SELECT * FROM users WHERE email = 'a@example.com' AND id > 42",
+			"SELECT * FROM users WHERE email = $1 AND id > $2"},
+		{"doubled quotes", "SELECT 'it''s' AS s", "SELECT $1 AS s"},
+		{"escape string", `SELECT E'line\'s\n' AS s`, "SELECT $1 AS s"},
+		{"bit, hex and unicode strings", "SELECT B'101', X'1F', U&'d\\0061t'", "SELECT $1, $2, $3"},
+		{"dollar quoted", "SELECT $tag$secret$tag$, $$x$$", "SELECT $1, $2"},
+		{"numbers after parameters", "SELECT * FROM t WHERE a = $2 AND b = 1.5e-3 AND c = .5",
+			"SELECT * FROM t WHERE a = $2 AND b = $3 AND c = $4"},
+		{"identifiers keep digits", `SELECT t1.col2, "Weird 'name'" FROM t1`, `SELECT t1.col2, "Weird 'name'" FROM t1`},
+		{"comments and whitespace", "SELECT 1 -- token abc\n /* secret */ FROM\n\t t", "SELECT $1 FROM t"},
+		{"operators and casts", "SELECT data->>'key', '5'::int FROM t LIMIT 10", "SELECT data->>$1, $2::int FROM t LIMIT $3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeSQL(tt.sql); got != tt.want {
+				t.Errorf("NormalizeSQL(%q) = %q, want %q", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggableQuery(t *testing.T) {
+	defer SetQueryLogMode("")
+
+	sql := "SELECT * FROM users WHERE email = 'a@example.com'"
+
+	SetQueryLogMode("full")
+	if got, ok := LoggableQuery(sql); !ok || got != sql {
+		t.Errorf("full: LoggableQuery() = %q, %v", got, ok)
+	}
+
+	SetQueryLogMode("normalized")
+	if got, ok := LoggableQuery(sql); !ok || got != "SELECT * FROM users WHERE email = $1" {
+		t.Errorf("normalized: LoggableQuery() = %q, %v", got, ok)
+	}
+
+	SetQueryLogMode("none")
+	if got, ok := LoggableQuery(sql); ok || got != "" {
+		t.Errorf("none: LoggableQuery() = %q, %v", got, ok)
+	}
+
+	SetQueryLogMode("bogus")
+	if _, ok := LoggableQuery(sql); !ok {
+		t.Error("unknown mode should fall back to normalized")
+	}
+}
+
+func TestLogSQLField(t *testing.T) {
+	originalStderr := os.Stderr
+	originalLevel := GetLevel()
+	SetLevel(LevelDebug)
+	defer func() {
+		SetLevel(originalLevel)
+		SetQueryLogMode("")
+		os.Stderr = originalStderr
+	}()
+
+	logEntryFor := func(mode string) logEntry {
+		SetQueryLogMode(mode)
+		r, w, _ := os.Pipe()
+		os.Stderr = w
+		Info("query_executed", "query", SQL("SELECT * FROM users WHERE email = 'a@example.com'"), "rows", 1)
+		w.Close()
+		output, _ := io.ReadAll(r)
+		os.Stderr = originalStderr
+
+		var entry logEntry
+		if err := json.Unmarshal(output, &entry); err != nil {
+			t.Fatalf("Failed to parse log output: %v", err)
+		}
+		return entry
+	}
+
+	if entry := logEntryFor("normalized"); entry.Fields["query"] != "SELECT * FROM users WHERE email = $1" {
+		t.Errorf("normalized: query field = %v", entry.Fields["query"])
+	}
+
+	entry := logEntryFor("none")
+	if _, exists := entry.Fields["query"]; exists {
+		t.Errorf("none: query field should be omitted, got %v", entry.Fields["query"])
+	}
+	if entry.Fields["rows"] != float64(1) {
+		t.Errorf("none: other fields should be kept, got %v", entry.Fields)
+	}
+}
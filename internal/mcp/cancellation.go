@@ -0,0 +1,102 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/logging"
+)
+
+// CancelledParams are the parameters of a notifications/cancelled
+// notification
+type CancelledParams struct {
+	RequestID interface{} `json:"requestId"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// inFlightRequests tracks the tool calls in progress so a
+// notifications/cancelled can cancel one. Over HTTP, request IDs are only
+// unique per client, so calls are keyed by token as well as ID.
+type inFlightRequests struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+// requestKey identifies a request by the caller's token and its ID. The ID
+// type is part of the key: 1 and "1" are different JSON-RPC IDs.
+func requestKey(ctx context.Context, id interface{}) string {
+	return fmt.Sprintf("%s/%T:%v", auth.GetTokenHashFromContext(ctx), id, id)
+}
+
+// start registers a request and returns the context to run it with, and a
+// function to call when it has finished
+func (r *inFlightRequests) start(ctx context.Context, id interface{}) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	if id == nil {
+		return ctx, cancel
+	}
+
+	key := requestKey(ctx, id)
+	r.mu.Lock()
+	if r.cancels == nil {
+		r.cancels = make(map[string]context.CancelFunc)
+	}
+	r.cancels[key] = cancel
+	r.mu.Unlock()
+
+	return ctx, func() {
+		r.mu.Lock()
+		delete(r.cancels, key)
+		r.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancel cancels the request with the given ID from the same caller,
+// reporting whether it was still in progress
+func (r *inFlightRequests) cancel(ctx context.Context, id interface{}) bool {
+	key := requestKey(ctx, id)
+	r.mu.Lock()
+	cancel, ok := r.cancels[key]
+	delete(r.cancels, key)
+	r.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// handleCancelled handles a notifications/cancelled notification. Unknown
+// or finished requests are ignored, as the MCP specification requires,
+// since the response may already be on its way.
+func (s *Server) handleCancelled(ctx context.Context, req JSONRPCRequest) {
+	var params CancelledParams
+	paramsBytes, err := json.Marshal(req.Params)
+	if err == nil {
+		err = json.Unmarshal(paramsBytes, &params)
+	}
+	if err != nil || params.RequestID == nil {
+		return
+	}
+
+	if s.inFlight.cancel(ctx, params.RequestID) {
+		logging.Info("request_cancelled",
+			"request_id", params.RequestID,
+			"reason", params.Reason,
+		)
+	}
+}
@@ -0,0 +1,93 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Request Cancellation Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestInFlightRequests(t *testing.T) {
+	var inFlight inFlightRequests
+
+	ctx, done := inFlight.start(context.Background(), float64(1))
+	otherCtx, otherDone := inFlight.start(context.Background(), "1")
+	defer otherDone()
+
+	if inFlight.cancel(context.Background(), float64(2)) {
+		t.Error("cancel of an unknown ID reported success")
+	}
+	if !inFlight.cancel(context.Background(), float64(1)) {
+		t.Fatal("cancel of an in-flight request reported failure")
+	}
+	if ctx.Err() == nil {
+		t.Error("cancelled request's context is not done")
+	}
+	if otherCtx.Err() != nil {
+		t.Error("request with ID \"1\" was cancelled along with ID 1")
+	}
+
+	// A finished request can no longer be cancelled
+	done()
+	_, done = inFlight.start(context.Background(), float64(3))
+	done()
+	if inFlight.cancel(context.Background(), float64(3)) {
+		t.Error("cancel of a finished request reported success")
+	}
+}
+
+func TestHandleHTTPRequest_CancelToolCall(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	tools := &mockToolProvider{
+		executeFunc: func(ctx context.Context, name string, args map[string]interface{}) (ToolResponse, error) {
+			close(started)
+			select {
+			case <-ctx.Done():
+				close(cancelled)
+				return NewToolError("query cancelled")
+			case <-time.After(5 * time.Second):
+				return NewToolSuccess("finished")
+			}
+		},
+	}
+	server := NewServer(tools)
+
+	post := func(req JSONRPCRequest) {
+		body, _ := json.Marshal(req)
+		server.handleHTTPRequest(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/mcp/v1", bytes.NewReader(body)))
+	}
+
+	go post(JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      7,
+		Method:  "tools/call",
+		Params:  map[string]interface{}{"name": "slow_tool"},
+	})
+	<-started
+
+	post(JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "notifications/cancelled",
+		Params:  map[string]interface{}{"requestId": 7, "reason": "user aborted"},
+	})
+
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("tool call was not cancelled")
+	}
+}
@@ -0,0 +1,91 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// CORSConfig controls which browser origins may call the HTTP server
+type CORSConfig struct {
+	AllowedOrigins   []string // Exact origins, or "*" for any (empty = CORS disabled)
+	AllowedMethods   []string // Methods allowed in preflight (empty = GET, POST, OPTIONS)
+	AllowCredentials bool     // Send Access-Control-Allow-Credentials
+}
+
+// defaultCORSMethods are allowed in preflight when none are configured
+var defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodOptions}
+
+// corsPreflightMaxAge is how long, in seconds, browsers may cache a preflight
+const corsPreflightMaxAge = "600"
+
+// corsMiddleware adds CORS headers to responses for allowed origins and
+// answers preflight requests itself, since they carry no credentials and
+// would otherwise be refused by the auth middleware. Requests from other
+// origins pass through without CORS headers, so browsers block the response.
+func corsMiddleware(cfg CORSConfig, next http.Handler) http.Handler {
+	if len(cfg.AllowedOrigins) == 0 {
+		return next
+	}
+
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	allowMethods := strings.Join(methods, ", ")
+	anyOrigin := slices.Contains(cfg.AllowedOrigins, "*")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		allowed := anyOrigin || slices.Contains(cfg.AllowedOrigins, origin)
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if !allowed {
+			if preflight {
+				http.Error(w, "Origin not allowed", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if anyOrigin && !cfg.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+			allowHeaders := r.Header.Get("Access-Control-Request-Headers")
+			if allowHeaders == "" {
+				allowHeaders = "Authorization, Content-Type"
+			}
+			w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+			w.Header().Set("Access-Control-Max-Age", corsPreflightMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
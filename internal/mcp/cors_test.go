@@ -0,0 +1,100 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	reached := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(cfg CORSConfig, method, origin string, preflight bool) *httptest.ResponseRecorder {
+		reached = false
+		req := httptest.NewRequest(method, "/mcp/v1", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+		}
+		rr := httptest.NewRecorder()
+		corsMiddleware(cfg, next).ServeHTTP(rr, req)
+		return rr
+	}
+
+	webUI := CORSConfig{AllowedOrigins: []string{"https://ui.example.com"}, AllowCredentials: true}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		rr := serve(CORSConfig{}, http.MethodPost, "https://ui.example.com", false)
+		if !reached || rr.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("reached=%v, headers=%v", reached, rr.Header())
+		}
+	})
+
+	t.Run("allowed origin", func(t *testing.T) {
+		rr := serve(webUI, http.MethodPost, "https://ui.example.com", false)
+		if !reached {
+			t.Error("request not passed on")
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://ui.example.com" {
+			t.Errorf("Access-Control-Allow-Origin = %q", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+			t.Errorf("Access-Control-Allow-Credentials = %q", got)
+		}
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		rr := serve(webUI, http.MethodOptions, "https://ui.example.com", true)
+		if reached {
+			t.Error("preflight passed on to the handler")
+		}
+		if rr.Code != http.StatusNoContent {
+			t.Errorf("status = %d, want 204", rr.Code)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, OPTIONS" {
+			t.Errorf("Access-Control-Allow-Methods = %q", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Headers"); got != "authorization, content-type" {
+			t.Errorf("Access-Control-Allow-Headers = %q", got)
+		}
+	})
+
+	t.Run("other origin", func(t *testing.T) {
+		rr := serve(webUI, http.MethodPost, "https://evil.example.com", false)
+		if !reached || rr.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("reached=%v, headers=%v", reached, rr.Header())
+		}
+		rr = serve(webUI, http.MethodOptions, "https://evil.example.com", true)
+		if reached || rr.Code != http.StatusForbidden {
+			t.Errorf("preflight from other origin: reached=%v, status=%d", reached, rr.Code)
+		}
+	})
+
+	t.Run("wildcard origin", func(t *testing.T) {
+		cfg := CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"POST"}}
+		rr := serve(cfg, http.MethodOptions, "https://any.example.com", true)
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Methods"); got != "POST" {
+			t.Errorf("Access-Control-Allow-Methods = %q", got)
+		}
+	})
+}
@@ -14,17 +14,25 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"math"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/logging"
 )
 
 // HTTPConfig holds configuration for HTTP/HTTPS server mode
 type HTTPConfig struct {
 	Addr          string                         // Server address (e.g., ":8080")
+	SocketPath    string                         // Unix domain socket to listen on instead of Addr (optional)
 	TLSEnable     bool                           // Enable HTTPS
 	CertFile      string                         // Path to TLS certificate file
 	KeyFile       string                         // Path to TLS key file
@@ -34,21 +42,64 @@ type HTTPConfig struct {
 	UserStore     *auth.UserStore                // User store for session token authentication
 	SetupHandlers func(mux *http.ServeMux) error // Optional callback to add custom handlers before auth middleware
 	Debug         bool                           // Enable debug logging
+	ReadTimeout   time.Duration                  // Max time to read a request, including the body (0 = no timeout)
+	WriteTimeout  time.Duration                  // Max time to write a response (0 = no timeout)
+	IdleTimeout   time.Duration                  // Max time a keep-alive connection may sit idle (0 = use ReadTimeout)
+
+	// ReadinessCheck reports whether the server can serve tool calls; /ready
+	// returns 503 while it reports not ready. Nil means always ready.
+	ReadinessCheck func(ctx context.Context) ReadinessStatus
+
+	// TokenRateLimiter limits the MCP requests each authenticated token can
+	// make. Nil means unlimited.
+	TokenRateLimiter *auth.TokenRateLimiter
+
+	// AccessChecker checks token scopes for methods other than tool calls,
+	// such as logging/setLevel. Nil means unrestricted.
+	AccessChecker *auth.DatabaseAccessChecker
+
+	// CORS allows browser clients on other origins (disabled by default)
+	CORS CORSConfig
+
+	// StreamResults sends tools/call responses with chunked encoding as the
+	// tool produces them, for tools that support it
+	StreamResults bool
 }
 
+// ReadinessStatus describes the database state reported by /ready
+type ReadinessStatus struct {
+	Ready          bool   `json:"-"`
+	Connection     string `json:"connection"`      // connected, unreachable, per_session or not_configured
+	MetadataLoaded bool   `json:"metadata_loaded"` // Whether schema metadata has been loaded
+	Tables         int    `json:"tables"`          // Number of tables and views in the loaded metadata
+}
+
+// rateLimitErrorCode is the JSON-RPC error code returned when a token is
+// over http.auth.rate_limit (in the implementation-defined server error range)
+const rateLimitErrorCode = -32029
+
+// readinessCheckTimeout bounds the readiness check so a hung database
+// can't stall orchestrator probes
+const readinessCheckTimeout = 5 * time.Second
+
 // RunHTTP starts the MCP server in HTTP/HTTPS mode
 func (s *Server) RunHTTP(config *HTTPConfig) error {
 	if config == nil {
 		return fmt.Errorf("HTTP config is required")
 	}
 
-	// Store debug flag for use in handlers
+	// Store debug flag and readiness check for use in handlers
 	s.debug = config.Debug
+	s.readinessCheck = config.ReadinessCheck
+	s.tokenRateLimiter = config.TokenRateLimiter
+	s.accessChecker = config.AccessChecker
+	s.streamResults = config.StreamResults
 
 	// Create HTTP handler
 	mux := http.NewServeMux()
 	mux.HandleFunc("/mcp/v1", s.handleHTTPRequest)
 	mux.HandleFunc("/health", s.handleHealthCheck)
+	mux.HandleFunc("/ready", s.handleReadinessCheck)
 
 	// Call custom handler setup if provided (allows main.go to add LLM proxy endpoints)
 	if config.SetupHandlers != nil {
@@ -63,25 +114,113 @@ func (s *Server) RunHTTP(config *HTTPConfig) error {
 		handler = auth.AuthMiddleware(config.TokenStore, config.UserStore, true)(handler)
 	}
 
+	// CORS goes outside auth so preflight requests, which carry no
+	// credentials, are answered
+	handler = corsMiddleware(config.CORS, handler)
+
 	// Configure server
 	httpServer := &http.Server{
-		Addr:    config.Addr,
-		Handler: handler,
+		Addr:              config.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: config.ReadTimeout,
+		ReadTimeout:       config.ReadTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+
+	s.httpServerMu.Lock()
+	s.httpServer = httpServer
+	s.httpServerMu.Unlock()
+	if s.isClosing() {
+		return nil
+	}
+
+	// Listen on the Unix socket if one is configured, otherwise on Addr
+	var listener net.Listener
+	if config.SocketPath != "" {
+		var err error
+		listener, err = listenUnixSocket(config.SocketPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				fmt.Fprintf(os.Stderr, "WARNING: Failed to close socket listener: %v\n", err)
+			}
+		}()
 	}
 
 	// Start server with or without TLS
+	var err error
 	if config.TLSEnable {
 		// Load TLS configuration
-		tlsConfig, err := s.loadTLSConfig(config)
-		if err != nil {
-			return fmt.Errorf("failed to load TLS config: %w", err)
+		tlsConfig, tlsErr := s.loadTLSConfig(config)
+		if tlsErr != nil {
+			return fmt.Errorf("failed to load TLS config: %w", tlsErr)
 		}
 		httpServer.TLSConfig = tlsConfig
 
-		return httpServer.ListenAndServeTLS(config.CertFile, config.KeyFile)
+		if listener != nil {
+			err = httpServer.ServeTLS(listener, config.CertFile, config.KeyFile)
+		} else {
+			err = httpServer.ListenAndServeTLS(config.CertFile, config.KeyFile)
+		}
+	} else if listener != nil {
+		err = httpServer.Serve(listener)
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+
+	// Serving stops as soon as Shutdown starts; wait for it to finish
+	// draining requests in progress
+	if errors.Is(err, http.ErrServerClosed) {
+		<-s.drained
+		return nil
+	}
+	return err
+}
+
+// staleSocketDialTimeout bounds the check for a server still listening on
+// an existing socket file
+const staleSocketDialTimeout = time.Second
+
+// listenUnixSocket listens on a Unix domain socket that only the server's
+// user can connect to. A socket left behind by a previous run is replaced;
+// a socket another process is still accepting connections on, or any other
+// kind of file at path, is an error.
+func listenUnixSocket(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("cannot listen on %s: file exists and is not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, staleSocketDialTimeout); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("cannot listen on %s: another process is accepting connections on it", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	}
+
+	// Create the socket with owner-only permissions, so there is no window
+	// in which other users can connect before it is restricted
+	var listener net.Listener
+	err := withOwnerOnlyUmask(func() error {
+		var err error
+		listener, err = net.Listen("unix", path)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on socket %s: %w", path, err)
+	}
+
+	// Drop the execute bits the umask leaves, which sockets don't use
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to set permissions on socket %s: %w", path, err)
 	}
 
-	return httpServer.ListenAndServe()
+	return listener, nil
 }
 
 // loadTLSConfig loads TLS certificates and creates a TLS configuration
@@ -120,6 +259,13 @@ func (s *Server) handleHTTPRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Clients send the negotiated protocol revision after initialize
+	version := r.Header.Get(ProtocolVersionHeader)
+	if version != "" && !IsSupportedProtocolVersion(version) {
+		http.Error(w, fmt.Sprintf("Unsupported %s: %s", ProtocolVersionHeader, version), http.StatusBadRequest)
+		return
+	}
+
 	// Extract IP address and add to context
 	ipAddress := auth.ExtractIPAddress(r)
 	ctx := context.WithValue(r.Context(), auth.IPAddressContextKey, ipAddress)
@@ -143,19 +289,60 @@ func (s *Server) handleHTTPRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Refuse the request if the token is over its rate limit
+	if s.tokenRateLimiter != nil {
+		if tokenHash := auth.GetTokenHashFromContext(ctx); tokenHash != "" {
+			if allowed, retryAfter := s.tokenRateLimiter.Allow(tokenHash); !allowed {
+				retrySeconds := int(math.Ceil(retryAfter.Seconds()))
+				logging.Warn("token_rate_limited", "token", logging.TokenPrefix(tokenHash), "method", req.Method, "retry_after_seconds", retrySeconds)
+				w.Header().Set("Retry-After", strconv.Itoa(retrySeconds))
+				sendHTTPError(w, req.ID, rateLimitErrorCode, "Rate limit exceeded",
+					map[string]interface{}{
+						"retry_after_seconds": retrySeconds,
+						"hint":                fmt.Sprintf("Too many requests for this token; retry in %d seconds", retrySeconds),
+					})
+				return
+			}
+		}
+	}
+
 	// Debug logging: log incoming request
 	if s.debug {
 		fmt.Fprintf(os.Stderr, "[DEBUG] Incoming request: method=%s id=%v ip=%s\n", req.Method, req.ID, ipAddress)
 		if req.Params != nil {
-			if paramsJSON, err := json.Marshal(req.Params); err == nil {
+			if paramsJSON, err := json.Marshal(debugParams(req.Method, req.Params)); err == nil {
 				fmt.Fprintf(os.Stderr, "[DEBUG] Request params: %s\n", string(paramsJSON))
 			}
 		}
 	}
 
+	// Notifications get no JSON-RPC response
+	if req.ID == nil && acceptsNotifications(version) {
+		s.handleRequestHTTP(ctx, req)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// Let tools stream large results straight to the client
+	var stream *httpResultStream
+	if s.streamResults && req.Method == "tools/call" {
+		stream = newHTTPResultStream(w, req.ID)
+		ctx = WithResultStream(ctx, stream)
+	}
+
 	// Handle the request and capture the response (pass context with IP address)
 	response := s.handleRequestHTTP(ctx, req)
 
+	// Once part of a result has been streamed, the rest of the response
+	// has to follow it
+	if stream != nil && stream.started {
+		stream.finish(response)
+		if s.debug {
+			fmt.Fprintf(os.Stderr, "[DEBUG] Outgoing response streamed: %d bytes\n", stream.bytes)
+		}
+		return
+	}
+
 	// Debug logging: log outgoing response
 	if s.debug {
 		if responseJSON, err := json.Marshal(response); err == nil {
@@ -184,8 +371,27 @@ func (s *Server) handleRequestHTTP(ctx context.Context, req JSONRPCRequest) JSON
 			ID:      req.ID,
 			Result:  json.RawMessage(`{}`),
 		}
+	case "logging/setLevel":
+		if rpcErr := s.checkSetLevelScope(ctx); rpcErr != nil {
+			return createErrorResponse(req.ID, rpcErr.Code, rpcErr.Message, rpcErr.Data)
+		}
+		if rpcErr := setLogLevel(req.Params); rpcErr != nil {
+			return createErrorResponse(req.ID, rpcErr.Code, rpcErr.Message, rpcErr.Data)
+		}
+		return JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Result:  json.RawMessage(`{}`),
+		}
 	case "tools/list":
 		return s.handleToolsListHTTP(req)
+	case "notifications/cancelled":
+		s.handleCancelled(ctx, req)
+		return JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Result:  json.RawMessage(`{}`),
+		}
 	case "tools/call":
 		return s.handleToolCallHTTP(ctx, req)
 	case "resources/list":
@@ -208,8 +414,23 @@ func (s *Server) handleRequestHTTP(ctx context.Context, req JSONRPCRequest) JSON
 // HTTP-specific handlers that return responses instead of sending them
 
 func (s *Server) handleInitializeHTTP(req JSONRPCRequest) JSONRPCResponse {
+	var params InitializeParams
+	paramsJSON, err := json.Marshal(req.Params)
+	if err != nil {
+		return createErrorResponse(req.ID, -32602, "Invalid params", err.Error())
+	}
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		return createErrorResponse(req.ID, -32602, "Invalid params", err.Error())
+	}
+
+	protocolVersion, ok := NegotiateProtocolVersion(params.ProtocolVersion)
+	if !ok {
+		return createErrorResponse(req.ID, -32602, "Unsupported protocol version", unsupportedProtocolVersionData(params.ProtocolVersion))
+	}
+
 	capabilities := map[string]interface{}{
-		"tools": map[string]interface{}{},
+		"tools":   map[string]interface{}{},
+		"logging": map[string]interface{}{},
 	}
 
 	// Add resources capability if resource provider is set
@@ -223,7 +444,7 @@ func (s *Server) handleInitializeHTTP(req JSONRPCRequest) JSONRPCResponse {
 	}
 
 	result := InitializeResult{
-		ProtocolVersion: ProtocolVersion,
+		ProtocolVersion: protocolVersion,
 		Capabilities:    capabilities,
 		ServerInfo: Implementation{
 			Name:    ServerName,
@@ -263,7 +484,14 @@ func (s *Server) handleToolCallHTTP(ctx context.Context, req JSONRPCRequest) JSO
 	}
 
 	// Pass context for per-token connection isolation
-	response, err := s.tools.Execute(ctx, params.Name, params.Arguments)
+	ctx, done := s.inFlight.start(ctx, req.ID)
+	defer done()
+
+	response, err := s.executeTool(ctx, params.Name, params.Arguments)
+	var invalidParams *InvalidParamsError
+	if errors.As(err, &invalidParams) {
+		return createErrorResponse(req.ID, -32602, "Invalid params", err.Error())
+	}
 	if err != nil {
 		return createErrorResponse(req.ID, -32603, "Internal error", err.Error())
 	}
@@ -440,8 +668,69 @@ func (s *Server) handleHealthCheck(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleReadinessCheck reports whether the server is ready for traffic:
+// 200 once the database is connected and its metadata loaded, 503 until
+// then. Unlike /health, this fails while the database is unreachable.
+func (s *Server) handleReadinessCheck(w http.ResponseWriter, r *http.Request) {
+	status := ReadinessStatus{Ready: true, Connection: "not_configured"}
+	if s.readinessCheck != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+		defer cancel()
+		status = s.readinessCheck(ctx)
+	}
+
+	response := struct {
+		Status string `json:"status"`
+		ReadinessStatus
+		UptimeSeconds int64 `json:"uptime_seconds"`
+	}{
+		Status:          "ready",
+		ReadinessStatus: status,
+		UptimeSeconds:   int64(time.Since(s.startTime).Seconds()),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Ready {
+		response.Status = "not_ready"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: Failed to write readiness check response: %v\n", err)
+	}
+}
+
 // Helper functions
 
+// debugParams returns request params for debug logging, with the query
+// argument of a tool call masked according to the query logging mode
+func debugParams(method string, params interface{}) interface{} {
+	if method != "tools/call" {
+		return params
+	}
+	p, ok := params.(map[string]interface{})
+	if !ok {
+		return params
+	}
+	args, ok := p["arguments"].(map[string]interface{})
+	if !ok {
+		return params
+	}
+	query, ok := args["query"].(string)
+	if !ok {
+		return params
+	}
+
+	masked := maps.Clone(args)
+	if text, loggable := logging.LoggableQuery(query); loggable {
+		masked["query"] = text
+	} else {
+		delete(masked, "query")
+	}
+	p = maps.Clone(p)
+	p["arguments"] = masked
+	return p
+}
+
 func sendHTTPError(w http.ResponseWriter, id interface{}, code int, message string, data interface{}) {
 	response := createErrorResponse(id, code, message, data)
 	w.Header().Set("Content-Type", "application/json")
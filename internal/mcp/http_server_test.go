@@ -15,9 +15,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/logging"
 )
 
 func TestHandleHealthCheck(t *testing.T) {
@@ -60,6 +66,58 @@ func TestHandleHealthCheck(t *testing.T) {
 	}
 }
 
+func TestHandleReadinessCheck(t *testing.T) {
+	tests := []struct {
+		name           string
+		check          func(ctx context.Context) ReadinessStatus
+		expectedCode   int
+		expectedStatus string
+	}{
+		{"no check", nil, http.StatusOK, "ready"},
+		{
+			"metadata loading",
+			func(ctx context.Context) ReadinessStatus {
+				return ReadinessStatus{Connection: "connected"}
+			},
+			http.StatusServiceUnavailable, "not_ready",
+		},
+		{
+			"ready",
+			func(ctx context.Context) ReadinessStatus {
+				return ReadinessStatus{Ready: true, Connection: "connected", MetadataLoaded: true, Tables: 12}
+			},
+			http.StatusOK, "ready",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(&mockToolProvider{})
+			server.readinessCheck = tt.check
+
+			w := httptest.NewRecorder()
+			server.handleReadinessCheck(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			var response map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to parse response: %v", err)
+			}
+			if response["status"] != tt.expectedStatus {
+				t.Errorf("expected status %q, got %v", tt.expectedStatus, response["status"])
+			}
+			for _, field := range []string{"connection", "metadata_loaded", "tables", "uptime_seconds"} {
+				if _, ok := response[field]; !ok {
+					t.Errorf("response missing %q: %s", field, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
 func TestHandleHTTPRequest_MethodNotAllowed(t *testing.T) {
 	tools := &mockToolProvider{}
 	server := NewServer(tools)
@@ -935,3 +993,151 @@ func TestRunHTTP_NilConfig(t *testing.T) {
 		t.Error("expected error for nil config")
 	}
 }
+
+func TestDebugParamsMasksQuery(t *testing.T) {
+	defer logging.SetQueryLogMode("")
+
+	params := map[string]interface{}{
+		"name":      "query_database",
+		"arguments": map[string]interface{}{"query": "SELECT * FROM users WHERE email = 'a@example.com'", "limit": float64(10)},
+	}
+
+	logging.SetQueryLogMode("normalized")
+	masked := debugParams("tools/call", params).(map[string]interface{})
+	if got := masked["arguments"].(map[string]interface{})["query"]; got != "SELECT * FROM users WHERE email = $1" {
+		t.Errorf("normalized: query = %v", got)
+	}
+	if original := params["arguments"].(map[string]interface{})["query"]; original != "SELECT * FROM users WHERE email = 'a@example.com'" {
+		t.Errorf("debugParams modified the request params: %v", original)
+	}
+
+	logging.SetQueryLogMode("none")
+	masked = debugParams("tools/call", params).(map[string]interface{})
+	args := masked["arguments"].(map[string]interface{})
+	if _, exists := args["query"]; exists {
+		t.Errorf("none: query should be omitted, got %v", args["query"])
+	}
+	if args["limit"] != float64(10) {
+		t.Errorf("none: other arguments should be kept, got %v", args)
+	}
+
+	logging.SetQueryLogMode("full")
+	masked = debugParams("tools/call", params).(map[string]interface{})
+	if got := masked["arguments"].(map[string]interface{})["query"]; got != "SELECT * FROM users WHERE email = 'a@example.com'" {
+		t.Errorf("full: query = %v", got)
+	}
+}
+
+func TestHandleHTTPRequest_TokenRateLimit(t *testing.T) {
+	server := NewServer(&mockToolProvider{})
+	server.tokenRateLimiter = auth.NewTokenRateLimiter(2)
+
+	send := func(tokenHash string) JSONRPCResponse {
+		body, _ := json.Marshal(JSONRPCRequest{JSONRPC: "2.0", ID: 7, Method: "tools/list"})
+		req := httptest.NewRequest(http.MethodPost, "/mcp/v1", bytes.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), auth.TokenHashContextKey, tokenHash))
+		w := httptest.NewRecorder()
+		server.handleHTTPRequest(w, req)
+
+		var response JSONRPCResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if response.Error != nil && w.Header().Get("Retry-After") == "" {
+			t.Error("rate limited response has no Retry-After header")
+		}
+		return response
+	}
+
+	for i := 0; i < 2; i++ {
+		if response := send("token-a"); response.Error != nil {
+			t.Fatalf("request %d: unexpected error: %v", i+1, response.Error)
+		}
+	}
+
+	response := send("token-a")
+	if response.Error == nil || response.Error.Code != rateLimitErrorCode {
+		t.Fatalf("expected rate limit error, got %+v", response)
+	}
+	data, ok := response.Error.Data.(map[string]interface{})
+	if !ok || data["retry_after_seconds"] != float64(30) {
+		t.Errorf("error data = %+v, want retry_after_seconds 30", response.Error.Data)
+	}
+
+	// Other tokens have their own allowance
+	if response := send("token-b"); response.Error != nil {
+		t.Errorf("token-b: unexpected error: %v", response.Error)
+	}
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.sock")
+
+	listener, err := listenUnixSocket(path)
+	if err != nil {
+		t.Fatalf("listenUnixSocket() error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("socket not created: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket permissions = %o, want 600", perm)
+	}
+
+	// Requests over the socket reach the handler
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})}
+	go func() { _ = server.Serve(listener) }()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://unix/health")
+	if err != nil {
+		t.Fatalf("request over socket failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if err := server.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	// A socket left behind is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("net.Listen() error: %v", err)
+	}
+	unixListener, ok := stale.(*net.UnixListener)
+	if !ok {
+		t.Fatalf("net.Listen() returned %T", stale)
+	}
+	unixListener.SetUnlinkOnClose(false)
+	_ = stale.Close()
+	listener, err = listenUnixSocket(path)
+	if err != nil {
+		t.Fatalf("listenUnixSocket() over stale socket: %v", err)
+	}
+
+	// A socket another process is still listening on is left alone
+	if _, err := listenUnixSocket(path); err == nil {
+		t.Error("expected error listening over a live socket")
+	}
+	if _, err := os.Lstat(path); err != nil {
+		t.Errorf("live socket was removed: %v", err)
+	}
+	_ = listener.Close()
+
+	// Other files are left alone
+	file := filepath.Join(t.TempDir(), "not-a-socket")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listenUnixSocket(file); err == nil {
+		t.Error("expected error listening over a regular file")
+	}
+}
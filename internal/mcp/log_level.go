@@ -0,0 +1,96 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/logging"
+)
+
+// permissionDeniedErrorCode is the JSON-RPC error code returned when a
+// token lacks the scope a method requires (in the implementation-defined
+// server error range)
+const permissionDeniedErrorCode = -32003
+
+// SetLevelParams are the parameters of a logging/setLevel request
+type SetLevelParams struct {
+	Level string `json:"level"`
+}
+
+// mcpLogLevels maps the syslog-style levels MCP clients send to the
+// server's log levels. The server has no levels above error, so critical,
+// alert and emergency log errors only.
+var mcpLogLevels = map[string]logging.LogLevel{
+	"debug":     logging.LevelDebug,
+	"info":      logging.LevelInfo,
+	"notice":    logging.LevelInfo,
+	"warning":   logging.LevelWarn,
+	"error":     logging.LevelError,
+	"critical":  logging.LevelError,
+	"alert":     logging.LevelError,
+	"emergency": logging.LevelError,
+}
+
+// checkSetLevelScope returns the JSON-RPC error to send when the request's
+// token may not change the log level, which affects every client and so
+// needs the admin scope, or nil if it may
+func (s *Server) checkSetLevelScope(ctx context.Context) *RPCError {
+	if s.accessChecker == nil || s.accessChecker.HasScope(ctx, auth.ScopeAdmin) {
+		return nil
+	}
+	return &RPCError{
+		Code:    permissionDeniedErrorCode,
+		Message: "Permission denied",
+		Data:    fmt.Sprintf("logging/setLevel requires the '%s' scope, which this token does not have", auth.ScopeAdmin),
+	}
+}
+
+// setLogLevel handles a logging/setLevel request, changing the server's
+// log level for every client. It returns the JSON-RPC error to send, or
+// nil on success.
+func setLogLevel(params interface{}) *RPCError {
+	var p SetLevelParams
+	paramsJSON, err := json.Marshal(params)
+	if err == nil {
+		err = json.Unmarshal(paramsJSON, &p)
+	}
+	if err != nil {
+		return &RPCError{Code: -32602, Message: "Invalid params", Data: err.Error()}
+	}
+
+	level, ok := mcpLogLevels[p.Level]
+	if !ok {
+		return &RPCError{
+			Code:    -32602,
+			Message: "Invalid params",
+			Data:    fmt.Sprintf("unknown log level %q (use debug, info, notice, warning, error, critical, alert or emergency)", p.Level),
+		}
+	}
+
+	previous := logging.GetLevel()
+	logging.SetLevel(level)
+	// Written whatever the level, so the change shows up in the log
+	logging.Write(logging.LevelInfo, "log_level_changed",
+		"from", previous.String(),
+		"to", level.String(),
+	)
+	return nil
+}
+
+// toolCallLoggingActive reports whether tool calls are logged: always with
+// PGEDGE_LOG_TOOL_CALLS, and otherwise while the log level is debug
+func (s *Server) toolCallLoggingActive() bool {
+	return s.logToolCalls || logging.GetLevel() == logging.LevelDebug
+}
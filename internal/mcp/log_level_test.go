@@ -0,0 +1,137 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Log Level Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/logging"
+)
+
+func TestSetLogLevel(t *testing.T) {
+	defer logging.SetLevel(logging.GetLevel())
+
+	tests := []struct {
+		level string
+		want  logging.LogLevel
+	}{
+		{"debug", logging.LevelDebug},
+		{"notice", logging.LevelInfo},
+		{"warning", logging.LevelWarn},
+		{"emergency", logging.LevelError},
+	}
+	for _, tt := range tests {
+		if rpcErr := setLogLevel(map[string]interface{}{"level": tt.level}); rpcErr != nil {
+			t.Fatalf("setLogLevel(%s) error: %+v", tt.level, rpcErr)
+		}
+		if got := logging.GetLevel(); got != tt.want {
+			t.Errorf("setLogLevel(%s) level = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+
+	logging.SetLevel(logging.LevelWarn)
+	for _, params := range []interface{}{
+		map[string]interface{}{"level": "verbose"},
+		map[string]interface{}{},
+		map[string]interface{}{"level": 3},
+	} {
+		rpcErr := setLogLevel(params)
+		if rpcErr == nil || rpcErr.Code != -32602 {
+			t.Errorf("setLogLevel(%v) = %+v, want invalid params", params, rpcErr)
+		}
+	}
+	if got := logging.GetLevel(); got != logging.LevelWarn {
+		t.Errorf("level changed by an invalid request: %v", got)
+	}
+}
+
+func TestHandleRequestHTTP_SetLevel(t *testing.T) {
+	defer logging.SetLevel(logging.GetLevel())
+	server := NewServer(&mockToolProvider{})
+
+	response := server.handleRequestHTTP(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "logging/setLevel",
+		Params:  map[string]interface{}{"level": "debug"},
+	})
+	if response.Error != nil {
+		t.Fatalf("unexpected error: %+v", response.Error)
+	}
+	if logging.GetLevel() != logging.LevelDebug {
+		t.Errorf("level = %v, want debug", logging.GetLevel())
+	}
+	if !server.toolCallLoggingActive() {
+		t.Error("tool calls should be logged at debug level")
+	}
+
+	logging.SetLevel(logging.LevelWarn)
+	if server.toolCallLoggingActive() {
+		t.Error("tool calls should not be logged at warning level")
+	}
+}
+
+func TestHandleInitializeHTTP_LoggingCapability(t *testing.T) {
+	server := NewServer(&mockToolProvider{})
+	response := server.handleInitializeHTTP(JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "initialize"})
+
+	result, ok := response.Result.(InitializeResult)
+	if !ok {
+		t.Fatalf("unexpected result type %T", response.Result)
+	}
+	if _, ok := result.Capabilities["logging"]; !ok {
+		t.Error("logging capability not advertised")
+	}
+}
+
+func TestHandleRequestHTTP_SetLevelRequiresAdmin(t *testing.T) {
+	defer logging.SetLevel(logging.GetLevel())
+	logging.SetLevel(logging.LevelInfo)
+
+	store := auth.InitializeTokenStore()
+	if err := store.AddToken("reader", auth.HashToken("r"), "", nil, "", []string{auth.ScopeRead}); err != nil {
+		t.Fatalf("AddToken() error: %v", err)
+	}
+	if err := store.AddToken("admin", auth.HashToken("a"), "", nil, "", []string{auth.ScopeAdmin}); err != nil {
+		t.Fatalf("AddToken() error: %v", err)
+	}
+	server := NewServer(&mockToolProvider{})
+	server.accessChecker = auth.NewDatabaseAccessChecker(store, true, false)
+
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "logging/setLevel",
+		Params:  map[string]interface{}{"level": "debug"},
+	}
+	tokenContext := func(token string) context.Context {
+		ctx := context.WithValue(context.Background(), auth.TokenHashContextKey, auth.HashToken(token))
+		return context.WithValue(ctx, auth.IsAPITokenContextKey, true)
+	}
+
+	response := server.handleRequestHTTP(tokenContext("r"), request)
+	if response.Error == nil || response.Error.Code != permissionDeniedErrorCode {
+		t.Fatalf("read token: error = %+v, want permission denied", response.Error)
+	}
+	if logging.GetLevel() != logging.LevelInfo {
+		t.Errorf("read token changed the level to %v", logging.GetLevel())
+	}
+
+	response = server.handleRequestHTTP(tokenContext("a"), request)
+	if response.Error != nil {
+		t.Fatalf("admin token: unexpected error: %+v", response.Error)
+	}
+	if logging.GetLevel() != logging.LevelDebug {
+		t.Errorf("level = %v, want debug", logging.GetLevel())
+	}
+}
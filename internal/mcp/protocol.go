@@ -0,0 +1,99 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+// LatestProtocolVersion is the newest MCP protocol revision the server
+// implements
+const LatestProtocolVersion = "2025-06-18"
+
+// SupportedProtocolVersions lists the MCP protocol revisions the server
+// accepts, newest first. Revisions are dates, so they sort as strings.
+// 2025-03-26 is left out: it requires servers to accept JSON-RPC batches,
+// which the server doesn't implement and 2025-06-18 removed again, so a
+// client asking for it is answered with 2024-11-05.
+var SupportedProtocolVersions = []string{
+	LatestProtocolVersion,
+	ProtocolVersion,
+}
+
+// ProtocolVersionHeader is the HTTP header clients send with the
+// negotiated protocol revision on every request after initialize
+const ProtocolVersionHeader = "MCP-Protocol-Version"
+
+// NegotiateProtocolVersion picks the protocol revision to use with a
+// client that asked for requested in initialize: the newest supported
+// revision that is no newer than the client's, since a client supports the
+// revisions before its own. A client that doesn't say gets ProtocolVersion,
+// the revision clients used before negotiation.
+// It returns false when every supported revision is newer than the
+// client's, or requested isn't a revision date.
+func NegotiateProtocolVersion(requested string) (string, bool) {
+	if requested == "" {
+		return ProtocolVersion, true
+	}
+	if !isProtocolRevision(requested) {
+		return "", false
+	}
+	for _, version := range SupportedProtocolVersions {
+		if version <= requested {
+			return version, true
+		}
+	}
+	return "", false
+}
+
+// IsSupportedProtocolVersion reports whether version is one of the
+// supported protocol revisions
+func IsSupportedProtocolVersion(version string) bool {
+	for _, supported := range SupportedProtocolVersions {
+		if version == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsNotifications reports whether HTTP requests made under protocol
+// revision version that carry only a notification are answered with
+// 202 Accepted and no body, as the Streamable HTTP transport of 2025-03-26
+// and later requires. Earlier clients, and those that don't send the
+// MCP-Protocol-Version header, get an empty JSON-RPC result.
+func acceptsNotifications(version string) bool {
+	return version != "" && version >= "2025-03-26"
+}
+
+// unsupportedProtocolVersionData is the error data for an initialize
+// request whose protocol version can't be served, in the form the MCP
+// specification gives
+func unsupportedProtocolVersionData(requested string) map[string]interface{} {
+	return map[string]interface{}{
+		"supported": SupportedProtocolVersions,
+		"requested": requested,
+	}
+}
+
+// isProtocolRevision reports whether s has the YYYY-MM-DD form of an MCP
+// protocol revision
+func isProtocolRevision(s string) bool {
+	if len(s) != len("2006-01-02") {
+		return false
+	}
+	for i, c := range s {
+		if i == 4 || i == 7 {
+			if c != '-' {
+				return false
+			}
+		} else if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
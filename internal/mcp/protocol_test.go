@@ -0,0 +1,168 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Protocol Version Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+)
+
+func TestSupportedProtocolVersionsOrder(t *testing.T) {
+	if SupportedProtocolVersions[0] != LatestProtocolVersion {
+		t.Errorf("newest supported version = %s, want LatestProtocolVersion %s", SupportedProtocolVersions[0], LatestProtocolVersion)
+	}
+	if !sort.SliceIsSorted(SupportedProtocolVersions, func(i, j int) bool {
+		return SupportedProtocolVersions[i] > SupportedProtocolVersions[j]
+	}) {
+		t.Errorf("SupportedProtocolVersions not newest first: %v", SupportedProtocolVersions)
+	}
+}
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      string
+		ok        bool
+	}{
+		{"", ProtocolVersion, true},
+		{"2024-11-05", "2024-11-05", true},
+		{"2025-03-26", "2024-11-05", true}, // Batching isn't implemented
+		{"2025-06-18", "2025-06-18", true},
+		{"2025-11-25", "2025-06-18", true},
+		{"2099-01-01", LatestProtocolVersion, true},
+		{"2024-10-07", "", false},
+		{"1.0.0", "", false},
+		{"2025-06-18x", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NegotiateProtocolVersion(tt.requested)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NegotiateProtocolVersion(%q) = %q, %v; want %q, %v", tt.requested, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHandleInitializeHTTP_ProtocolVersion(t *testing.T) {
+	server := NewServer(&mockToolProvider{})
+
+	for _, tt := range []struct {
+		requested string
+		want      string
+		code      int
+	}{
+		{"2024-11-05", "2024-11-05", 0},
+		{"2025-06-18", "2025-06-18", 0},
+		{"2099-01-01", LatestProtocolVersion, 0},
+		{"2024-01-01", "", -32602},
+	} {
+		response := server.handleInitializeHTTP(JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "initialize",
+			Params:  map[string]interface{}{"protocolVersion": tt.requested},
+		})
+
+		if tt.code != 0 {
+			if response.Error == nil || response.Error.Code != tt.code {
+				t.Errorf("initialize(%s) error = %+v, want code %d", tt.requested, response.Error, tt.code)
+			}
+			continue
+		}
+		if response.Error != nil {
+			t.Fatalf("initialize(%s) unexpected error: %+v", tt.requested, response.Error)
+		}
+		result, ok := response.Result.(InitializeResult)
+		if !ok {
+			t.Fatalf("unexpected result type %T", response.Result)
+		}
+		if result.ProtocolVersion != tt.want {
+			t.Errorf("initialize(%s) protocolVersion = %s, want %s", tt.requested, result.ProtocolVersion, tt.want)
+		}
+	}
+}
+
+func TestHandleHTTPRequest_UnsupportedProtocolVersionHeader(t *testing.T) {
+	server := NewServer(&mockToolProvider{})
+
+	body, _ := json.Marshal(JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp/v1", bytes.NewReader(body))
+	req.Header.Set(ProtocolVersionHeader, "2023-01-01")
+	w := httptest.NewRecorder()
+	server.handleHTTPRequest(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp/v1", bytes.NewReader(body))
+	req.Header.Set(ProtocolVersionHeader, "2025-03-26")
+	w = httptest.NewRecorder()
+	server.handleHTTPRequest(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d for an unimplemented revision", w.Code, http.StatusBadRequest)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp/v1", bytes.NewReader(body))
+	req.Header.Set(ProtocolVersionHeader, "2025-06-18")
+	w = httptest.NewRecorder()
+	server.handleHTTPRequest(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp/v1", bytes.NewReader(body))
+	req.Header.Set(ProtocolVersionHeader, "2024-11-05")
+	w = httptest.NewRecorder()
+	server.handleHTTPRequest(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestHandleHTTPRequest_NotificationAccepted(t *testing.T) {
+	server := NewServer(&mockToolProvider{})
+
+	body, _ := json.Marshal(JSONRPCRequest{JSONRPC: "2.0", Method: "notifications/initialized"})
+
+	// 2025-06-18 clients get 202 Accepted with no body
+	req := httptest.NewRequest(http.MethodPost, "/mcp/v1", bytes.NewReader(body))
+	req.Header.Set(ProtocolVersionHeader, "2025-06-18")
+	w := httptest.NewRecorder()
+	server.handleHTTPRequest(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusAccepted)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("body = %q, want empty", w.Body.String())
+	}
+
+	// 2024-11-05 clients still get an empty JSON-RPC result
+	for _, version := range []string{"2024-11-05", ""} {
+		req = httptest.NewRequest(http.MethodPost, "/mcp/v1", bytes.NewReader(body))
+		if version != "" {
+			req.Header.Set(ProtocolVersionHeader, version)
+		}
+		w = httptest.NewRecorder()
+		server.handleHTTPRequest(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("version %q: status = %d, want %d", version, w.Code, http.StatusOK)
+		}
+		var response JSONRPCResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Errorf("version %q: failed to parse response: %v", version, err)
+		}
+	}
+}
@@ -14,16 +14,27 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"sync"
+	"time"
+
+	"pgedge-postgres-mcp/internal/auth"
 )
 
 const (
+	// ProtocolVersion is the MCP protocol revision the server and the chat
+	// client implement
 	ProtocolVersion = "2024-11-05"
 	ServerName      = "pgedge-postgres-mcp"
-	ServerVersion   = "1.0.0-beta1"
 )
 
+// ServerVersion is reported to clients in the initialize response and by
+// /health. Release builds replace it with the version they were built as.
+var ServerVersion = "1.0.0-beta1"
+
 // ToolProvider is an interface for listing and executing tools
 type ToolProvider interface {
 	List() []Tool
@@ -50,6 +61,7 @@ type DatabaseInfo struct {
 	Database string `json:"database"`
 	User     string `json:"user"`
 	SSLMode  string `json:"sslmode"`
+	DSN      string `json:"dsn,omitempty"` // Connection string with the password redacted
 }
 
 // DatabaseProvider is an interface for managing database connections
@@ -67,12 +79,37 @@ type Server struct {
 	prompts   PromptProvider
 	databases DatabaseProvider
 	debug     bool // Enable debug logging for HTTP mode
+
+	logToolCalls bool // Log every tools/call with its duration (PGEDGE_LOG_TOOL_CALLS)
+
+	readinessCheck func(ctx context.Context) ReadinessStatus // Reports readiness for /ready (nil = always ready)
+	startTime      time.Time                                 // When the server was created, for uptime reporting
+
+	tokenRateLimiter *auth.TokenRateLimiter      // Per-token limit on HTTP MCP requests (nil = unlimited)
+	accessChecker    *auth.DatabaseAccessChecker // Token scope checks for HTTP methods (nil = unrestricted)
+	streamResults    bool                        // Stream tools/call results over HTTP
+
+	inFlight  inFlightRequests // Tool calls that notifications/cancelled can cancel
+	toolCalls sync.WaitGroup   // stdio tool calls in progress
+
+	// Graceful shutdown state (see Shutdown)
+	httpServerMu sync.Mutex
+	httpServer   *http.Server // Set while RunHTTP is serving
+	closeOnce    sync.Once
+	closing      chan struct{} // Closed when Shutdown starts
+	drained      chan struct{} // Closed when Shutdown has finished
+	stopped      chan struct{} // Closed when the stdio loop in Run exits
 }
 
 // NewServer creates a new MCP server
 func NewServer(tools ToolProvider) *Server {
 	return &Server{
-		tools: tools,
+		tools:        tools,
+		startTime:    time.Now(),
+		logToolCalls: toolCallLoggingEnabled(),
+		closing:      make(chan struct{}),
+		drained:      make(chan struct{}),
+		stopped:      make(chan struct{}),
 	}
 }
 
@@ -93,29 +130,52 @@ func (s *Server) SetDatabaseProvider(databases DatabaseProvider) {
 
 // Run starts the stdio server loop
 func (s *Server) Run() error {
+	defer close(s.stopped)
+	defer s.toolCalls.Wait()
+
+	// Read stdin in the background so Shutdown can stop the loop while it
+	// waits for input
 	scanner := bufio.NewScanner(os.Stdin)
 	scanner.Buffer(make([]byte, 0, ScannerInitialBufferSize), ScannerMaxBufferSize)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
+	lines := make(chan string)
+	scanDone := make(chan error, 1)
+	go func() {
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-s.closing:
+				return
+			}
 		}
-
-		var req JSONRPCRequest
-		if err := json.Unmarshal([]byte(line), &req); err != nil {
-			sendError(nil, -32700, "Parse error", err.Error())
-			continue
+		scanDone <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-s.closing:
+			return nil
+		case err := <-scanDone:
+			if err != nil {
+				return fmt.Errorf("scanner error: %w", err)
+			}
+			return nil
+		case line := <-lines:
+			if s.isClosing() {
+				return nil
+			}
+			if line == "" {
+				continue
+			}
+
+			var req JSONRPCRequest
+			if err := json.Unmarshal([]byte(line), &req); err != nil {
+				sendError(nil, -32700, "Parse error", err.Error())
+				continue
+			}
+
+			s.handleRequest(req)
 		}
-
-		s.handleRequest(req)
 	}
-
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scanner error: %w", err)
-	}
-
-	return nil
 }
 
 func (s *Server) handleRequest(req JSONRPCRequest) {
@@ -124,10 +184,24 @@ func (s *Server) handleRequest(req JSONRPCRequest) {
 		s.handleInitialize(req)
 	case "notifications/initialized":
 		// Client notification - no response needed
+	case "notifications/cancelled":
+		s.handleCancelled(context.Background(), req)
+	case "logging/setLevel":
+		if rpcErr := setLogLevel(req.Params); rpcErr != nil {
+			sendError(req.ID, rpcErr.Code, rpcErr.Message, rpcErr.Data)
+			return
+		}
+		sendResponse(req.ID, map[string]interface{}{})
 	case "tools/list":
 		s.handleToolsList(req)
 	case "tools/call":
-		s.handleToolCall(req)
+		// Run tool calls in the background so the loop can read a
+		// notifications/cancelled for them
+		s.toolCalls.Add(1)
+		go func() {
+			defer s.toolCalls.Done()
+			s.handleToolCall(req)
+		}()
 	case "resources/list":
 		s.handleResourcesList(req)
 	case "resources/read":
@@ -159,14 +233,15 @@ func (s *Server) handleInitialize(req JSONRPCRequest) {
 		return
 	}
 
-	// Accept the client's protocol version for compatibility
-	protocolVersion := params.ProtocolVersion
-	if protocolVersion == "" {
-		protocolVersion = ProtocolVersion
+	protocolVersion, ok := NegotiateProtocolVersion(params.ProtocolVersion)
+	if !ok {
+		sendError(req.ID, -32602, "Unsupported protocol version", unsupportedProtocolVersionData(params.ProtocolVersion))
+		return
 	}
 
 	capabilities := map[string]interface{}{
-		"tools": map[string]interface{}{},
+		"tools":   map[string]interface{}{},
+		"logging": map[string]interface{}{},
 	}
 
 	// Add resources capability if resource provider is set
@@ -214,7 +289,19 @@ func (s *Server) handleToolCall(req JSONRPCRequest) {
 	}
 
 	// For stdio mode, use background context (no authentication)
-	response, err := s.tools.Execute(context.Background(), params.Name, params.Arguments)
+	ctx, done := s.inFlight.start(context.Background(), req.ID)
+	defer done()
+
+	response, err := s.executeTool(ctx, params.Name, params.Arguments)
+	if ctx.Err() != nil {
+		// Cancelled by the client, which expects no response
+		return
+	}
+	var invalidParams *InvalidParamsError
+	if errors.As(err, &invalidParams) {
+		sendError(req.ID, -32602, "Invalid params", err.Error())
+		return
+	}
 	if err != nil {
 		sendError(req.ID, -32603, "Tool execution error", err.Error())
 		return
@@ -387,6 +474,10 @@ func (s *Server) handleSelectDatabase(req JSONRPCRequest) {
 	sendResponse(req.ID, result)
 }
 
+// stdoutMu keeps responses from concurrent stdio tool calls from
+// interleaving
+var stdoutMu sync.Mutex
+
 func sendResponse(id, result interface{}) {
 	resp := JSONRPCResponse{
 		JSONRPC: "2.0",
@@ -399,6 +490,8 @@ func sendResponse(id, result interface{}) {
 		fmt.Fprintf(os.Stderr, "ERROR: Failed to marshal response: %v\n", err)
 		return
 	}
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
 	fmt.Println(string(data))
 	_ = os.Stdout.Sync()
 }
@@ -419,6 +512,8 @@ func sendError(id interface{}, code int, message string, data interface{}) {
 		fmt.Fprintf(os.Stderr, "ERROR: Failed to marshal error response: %v\n", err)
 		return
 	}
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
 	fmt.Println(string(respData))
 	_ = os.Stdout.Sync()
 }
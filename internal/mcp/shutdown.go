@@ -0,0 +1,70 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"context"
+)
+
+// Shutdown stops the server accepting new requests and waits until the
+// requests in progress have finished or ctx is done, whichever is first.
+// In HTTP mode, requests still running when ctx is done are cancelled.
+// Run or RunHTTP returns nil once Shutdown has finished.
+func (s *Server) Shutdown(ctx context.Context) error {
+	first := false
+	s.closeOnce.Do(func() {
+		close(s.closing)
+		first = true
+	})
+	if !first {
+		// Already shutting down; wait for that to finish
+		select {
+		case <-s.drained:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer close(s.drained)
+
+	s.httpServerMu.Lock()
+	httpServer := s.httpServer
+	s.httpServerMu.Unlock()
+
+	if httpServer == nil {
+		// stdio mode: wait for the loop to finish the tool calls in
+		// progress and exit
+		select {
+		case <-s.stopped:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		// Grace period over: close the remaining connections, which
+		// cancels their request contexts and so their queries
+		_ = httpServer.Close()
+		return err
+	}
+	return nil
+}
+
+// isClosing reports whether Shutdown has been called
+func (s *Server) isClosing() bool {
+	select {
+	case <-s.closing:
+		return true
+	default:
+		return false
+	}
+}
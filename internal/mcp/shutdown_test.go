@@ -0,0 +1,163 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startSocketServer runs s.RunHTTP on a Unix socket with an extra /slow
+// handler that blocks until release is closed or its request is cancelled
+func startSocketServer(t *testing.T, s *Server, started chan<- struct{}, release <-chan struct{}) (*http.Client, <-chan error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mcp.sock")
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- s.RunHTTP(&HTTPConfig{
+			SocketPath: path,
+			SetupHandlers: func(mux *http.ServeMux) error {
+				mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+					started <- struct{}{}
+					select {
+					case <-release:
+						_, _ = w.Write([]byte("done"))
+					case <-r.Context().Done():
+					}
+				})
+				return nil
+			},
+		})
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+
+	// Wait for the socket to accept connections
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := client.Get("http://unix/health")
+		if err == nil {
+			_ = resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return client, runErr
+}
+
+func TestShutdown_HTTPWaitsForRequests(t *testing.T) {
+	s := NewServer(nil)
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	client, runErr := startSocketServer(t, s, started, release)
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := client.Get("http://unix/slow")
+		if err != nil {
+			body <- "error: " + err.Error()
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+		data, _ := io.ReadAll(resp.Body)
+		body <- string(data)
+	}()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- s.Shutdown(context.Background()) }()
+
+	// The request in progress finishes before Shutdown returns
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("Shutdown() returned %v with a request in progress", err)
+	default:
+	}
+	close(release)
+
+	if got := <-body; got != "done" {
+		t.Errorf("in-flight request got %q, want \"done\"", got)
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("Shutdown() error: %v", err)
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("RunHTTP() error after Shutdown: %v", err)
+	}
+}
+
+func TestShutdown_HTTPTimeoutCancelsRequests(t *testing.T) {
+	s := NewServer(nil)
+	started := make(chan struct{}, 1)
+	client, runErr := startSocketServer(t, s, started, make(chan struct{}))
+
+	go func() {
+		if resp, err := client.Get("http://unix/slow"); err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want deadline exceeded", err)
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("RunHTTP() error after Shutdown: %v", err)
+	}
+}
+
+func TestShutdown_Stdio(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error: %v", err)
+	}
+	defer func() { _ = w.Close() }()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	s := NewServer(nil)
+	runErr := make(chan error, 1)
+	go func() { runErr <- s.Run() }()
+
+	// Run is waiting for input; Shutdown stops it
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown() error: %v", err)
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("Run() error after Shutdown: %v", err)
+	}
+
+	// A second Shutdown returns straight away
+	if err := s.Shutdown(ctx); err != nil {
+		t.Errorf("second Shutdown() error: %v", err)
+	}
+}
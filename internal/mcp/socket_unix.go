@@ -0,0 +1,32 @@
+//go:build !windows
+
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"sync"
+	"syscall"
+)
+
+// umaskMu serializes umask changes; the umask is process-wide
+var umaskMu sync.Mutex
+
+// withOwnerOnlyUmask runs fn with a umask that denies group and other
+// users access to any file fn creates
+func withOwnerOnlyUmask(fn func() error) error {
+	umaskMu.Lock()
+	defer umaskMu.Unlock()
+
+	old := syscall.Umask(0077)
+	defer syscall.Umask(old)
+	return fn()
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+// withOwnerOnlyUmask runs fn. Windows has no umask; a Unix socket there
+// gets the ACL of the directory it is created in.
+func withOwnerOnlyUmask(fn func() error) error {
+	return fn()
+}
@@ -0,0 +1,136 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ResultStream receives the text of a tool's result while the tool is still
+// running, so large results don't have to be held in memory. Text written
+// to the stream comes first in the result; the text of the ToolResponse the
+// tool returns is appended after it.
+type ResultStream interface {
+	WriteText(text string) error
+}
+
+type resultStreamKey struct{}
+
+// WithResultStream returns a context carrying stream
+func WithResultStream(ctx context.Context, stream ResultStream) context.Context {
+	return context.WithValue(ctx, resultStreamKey{}, stream)
+}
+
+// ResultStreamFromContext returns the result stream for the current tool
+// call, or nil if the transport doesn't stream results
+func ResultStreamFromContext(ctx context.Context) ResultStream {
+	stream, _ := ctx.Value(resultStreamKey{}).(ResultStream)
+	return stream
+}
+
+// httpResultStream writes a tools/call response as it is produced. The body
+// is the same JSON-RPC response a buffered call returns, with a single text
+// content item, but it is sent with chunked encoding and flushed after each
+// write, so clients need no changes to read it.
+type httpResultStream struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	id      interface{}
+	started bool
+	bytes   int
+}
+
+func newHTTPResultStream(w http.ResponseWriter, id interface{}) *httpResultStream {
+	return &httpResultStream{w: w, rc: http.NewResponseController(w), id: id}
+}
+
+// WriteText sends text as part of the result's text content, starting the
+// response on the first call
+func (s *httpResultStream) WriteText(text string) error {
+	if !s.started {
+		s.started = true
+		s.w.Header().Set("Content-Type", "application/json")
+		s.w.WriteHeader(http.StatusOK)
+
+		prefix := `{"jsonrpc":"2.0",`
+		if s.id != nil {
+			id, err := json.Marshal(s.id)
+			if err != nil {
+				return err
+			}
+			prefix += `"id":` + string(id) + `,`
+		}
+		prefix += `"result":{"content":[{"type":"text","text":"`
+		if err := s.write(prefix); err != nil {
+			return err
+		}
+	}
+
+	if err := s.write(jsonStringBody(text)); err != nil {
+		return err
+	}
+	// Writers that can't flush still deliver the data, just later
+	_ = s.rc.Flush() //nolint:errcheck // flushing is best effort
+	return nil
+}
+
+// finish appends the text of the final response and closes the JSON
+// document. A tool error, or a call that failed after output had been
+// streamed, marks the result as an error.
+func (s *httpResultStream) finish(response JSONRPCResponse) {
+	var tail strings.Builder
+	isError := false
+	switch {
+	case response.Error != nil:
+		isError = true
+		tail.WriteString("\n\nError: " + response.Error.Message)
+		if response.Error.Data != nil {
+			tail.WriteString(fmt.Sprintf(": %v", response.Error.Data))
+		}
+	default:
+		if result, ok := response.Result.(ToolResponse); ok {
+			isError = result.IsError
+			for _, item := range result.Content {
+				tail.WriteString(item.Text)
+			}
+		}
+	}
+
+	end := jsonStringBody(tail.String()) + `"}]`
+	if isError {
+		end += `,"isError":true`
+	}
+	end += "}}\n"
+	if err := s.write(end); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Failed to finish streamed response: %v\n", err)
+	}
+}
+
+func (s *httpResultStream) write(text string) error {
+	n, err := s.w.Write([]byte(text))
+	s.bytes += n
+	return err
+}
+
+// jsonStringBody returns text encoded as a JSON string, without the quotes
+func jsonStringBody(text string) string {
+	encoded, err := json.Marshal(text)
+	if err != nil {
+		// Marshaling a string can't fail
+		return ""
+	}
+	return string(encoded[1 : len(encoded)-1])
+}
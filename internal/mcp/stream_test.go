@@ -0,0 +1,126 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// streamedToolCall makes a tools/call request to a server with result
+// streaming enabled and decodes the tool result
+func streamedToolCall(t *testing.T, execute func(ctx context.Context) (ToolResponse, error)) (ToolResponse, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	server := NewServer(&mockToolProvider{
+		executeFunc: func(ctx context.Context, name string, args map[string]interface{}) (ToolResponse, error) {
+			return execute(ctx)
+		},
+	})
+	server.streamResults = true
+
+	body, _ := json.Marshal(JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      7,
+		Method:  "tools/call",
+		Params:  map[string]interface{}{"name": "query_database"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/mcp/v1", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleHTTPRequest(w, req)
+
+	var response struct {
+		JSONRPC string       `json:"jsonrpc"`
+		ID      int          `json:"id"`
+		Result  ToolResponse `json:"result"`
+		Error   *RPCError    `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v\n%s", err, w.Body.String())
+	}
+	if response.JSONRPC != "2.0" || response.ID != 7 {
+		t.Errorf("unexpected envelope: %s", w.Body.String())
+	}
+	if response.Error != nil {
+		t.Fatalf("unexpected JSON-RPC error: %v", response.Error)
+	}
+	return response.Result, w
+}
+
+func TestHTTPResultStreaming(t *testing.T) {
+	result, w := streamedToolCall(t, func(ctx context.Context) (ToolResponse, error) {
+		stream := ResultStreamFromContext(ctx)
+		if stream == nil {
+			t.Fatal("expected a result stream in the context")
+		}
+		for _, text := range []string{"Results:\n", "id\tnote\n1\t\"quoted\" <b>", "\n2\ttab\\t"} {
+			if err := stream.WriteText(text); err != nil {
+				t.Fatalf("WriteText failed: %v", err)
+			}
+		}
+		return NewToolSuccess("\n\n(2 rows)")
+	})
+
+	if !w.Flushed {
+		t.Error("expected the streamed response to be flushed")
+	}
+	want := "Results:\nid\tnote\n1\t\"quoted\" <b>\n2\ttab\\t\n\n(2 rows)"
+	if len(result.Content) != 1 || result.Content[0].Type != "text" || result.Content[0].Text != want {
+		t.Errorf("Content = %+v, want one text item %q", result.Content, want)
+	}
+	if result.IsError {
+		t.Error("expected a successful result")
+	}
+}
+
+func TestHTTPResultStreamingErrors(t *testing.T) {
+	// A tool error after rows were sent marks the result as an error
+	result, _ := streamedToolCall(t, func(ctx context.Context) (ToolResponse, error) {
+		_ = ResultStreamFromContext(ctx).WriteText("id\n1")
+		return NewToolError("\n\nquery timed out")
+	})
+	if !result.IsError || result.Content[0].Text != "id\n1\n\nquery timed out" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// So does a failed call
+	result, _ = streamedToolCall(t, func(ctx context.Context) (ToolResponse, error) {
+		_ = ResultStreamFromContext(ctx).WriteText("id\n1")
+		return ToolResponse{}, errors.New("connection lost")
+	})
+	if !result.IsError || result.Content[0].Text != "id\n1\n\nError: Internal error: connection lost" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// A tool that never writes to the stream gets a normal response
+	result, _ = streamedToolCall(t, func(ctx context.Context) (ToolResponse, error) {
+		return NewToolSuccess("small result")
+	})
+	if result.Content[0].Text != "small result" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestResultStreamFromContext(t *testing.T) {
+	if stream := ResultStreamFromContext(context.Background()); stream != nil {
+		t.Errorf("expected no stream, got %v", stream)
+	}
+
+	stream := newHTTPResultStream(httptest.NewRecorder(), 1)
+	if got := ResultStreamFromContext(WithResultStream(context.Background(), stream)); got != stream {
+		t.Error("expected the stream stored in the context")
+	}
+}
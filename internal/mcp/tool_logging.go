@@ -0,0 +1,84 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/metrics"
+)
+
+// envLogToolCalls enables a log entry for every tools/call
+const envLogToolCalls = "PGEDGE_LOG_TOOL_CALLS"
+
+// toolCallLoggingEnabled reports whether PGEDGE_LOG_TOOL_CALLS is set to a
+// true value
+func toolCallLoggingEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv(envLogToolCalls))
+	return err == nil && enabled
+}
+
+// executeTool runs a tool through the tool provider, recording its metrics
+// and, when tool call logging is enabled, logging the call with its
+// duration and outcome. The provider checks the arguments against the
+// tool's InputSchema once it has checked the tool is enabled and allowed,
+// and returns an *InvalidParamsError when they don't match.
+func (s *Server) executeTool(ctx context.Context, name string, args map[string]interface{}) (ToolResponse, error) {
+	start := time.Now()
+	response, err := s.tools.Execute(ctx, name, args)
+	duration := time.Since(start)
+
+	metrics.ObserveToolCall(name, toolCallStatus(response, err), duration)
+	if s.toolCallLoggingActive() {
+		logging.Write(logging.LevelInfo, "tool_call", toolCallFields(ctx, name, args, duration, response, err)...)
+	}
+	return response, err
+}
+
+// toolCallStatus classifies a tool call's outcome as success, tool_error
+// (the tool reported an error) or error (the call failed)
+func toolCallStatus(response ToolResponse, err error) string {
+	switch {
+	case err != nil:
+		return "error"
+	case response.IsError:
+		return "tool_error"
+	default:
+		return "success"
+	}
+}
+
+// toolCallFields builds the log fields for a tool call. Only argument
+// names are logged: values can hold SQL, search text or credentials.
+func toolCallFields(ctx context.Context, name string, args map[string]interface{}, duration time.Duration, response ToolResponse, err error) []interface{} {
+	argKeys := make([]string, 0, len(args))
+	for key := range args {
+		argKeys = append(argKeys, key)
+	}
+	sort.Strings(argKeys)
+
+	fields := []interface{}{
+		"tool", name,
+		"arg_keys", argKeys,
+		"duration_ms", duration.Milliseconds(),
+		"status", toolCallStatus(response, err),
+	}
+	if tokenHash := auth.GetTokenHashFromContext(ctx); tokenHash != "" {
+		fields = append(fields, "token", logging.TokenPrefix(tokenHash))
+	}
+	return fields
+}
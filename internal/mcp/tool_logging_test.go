@@ -0,0 +1,86 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"pgedge-postgres-mcp/internal/auth"
+)
+
+func TestToolCallLoggingEnabled(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"", false},
+		{"true", true},
+		{"1", true},
+		{"false", false},
+		{"yes", false},
+	}
+
+	for _, tt := range tests {
+		t.Setenv(envLogToolCalls, tt.value)
+		if got := toolCallLoggingEnabled(); got != tt.expected {
+			t.Errorf("toolCallLoggingEnabled() with %q = %v, want %v", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestToolCallFields(t *testing.T) {
+	args := map[string]interface{}{"query": "SELECT secret FROM t", "limit": 10}
+	ctx := context.WithValue(context.Background(), auth.TokenHashContextKey, "0123456789abcdef")
+
+	fields := toolCallFields(ctx, "query_database", args, 1500*time.Millisecond, ToolResponse{}, nil)
+	got := make(map[string]interface{})
+	for i := 0; i+1 < len(fields); i += 2 {
+		got[fields[i].(string)] = fields[i+1]
+	}
+
+	if got["tool"] != "query_database" || got["status"] != "success" || got["duration_ms"] != int64(1500) {
+		t.Errorf("unexpected fields: %v", got)
+	}
+	if !reflect.DeepEqual(got["arg_keys"], []string{"limit", "query"}) {
+		t.Errorf("arg_keys = %v, want [limit query]", got["arg_keys"])
+	}
+	if got["token"] != "0123456789ab" {
+		t.Errorf("token = %v, want hash prefix", got["token"])
+	}
+	for _, value := range fields {
+		if value == "SELECT secret FROM t" {
+			t.Error("argument value was logged")
+		}
+	}
+
+	fields = toolCallFields(context.Background(), "x", nil, 0, ToolResponse{IsError: true}, nil)
+	if fields[7] != "tool_error" || len(fields) != 8 {
+		t.Errorf("tool error fields = %v", fields)
+	}
+	fields = toolCallFields(context.Background(), "x", nil, 0, ToolResponse{}, errors.New("boom"))
+	if fields[7] != "error" {
+		t.Errorf("error fields = %v", fields)
+	}
+}
+
+func TestExecuteToolPassesThrough(t *testing.T) {
+	server := NewServer(&mockToolProvider{})
+	server.logToolCalls = true
+
+	response, err := server.executeTool(context.Background(), "test_tool", map[string]interface{}{"a": 1})
+	if err != nil || response.IsError || response.Content[0].Text != "executed" {
+		t.Errorf("executeTool() = (%+v, %v)", response, err)
+	}
+}
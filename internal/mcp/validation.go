@@ -0,0 +1,180 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// InvalidParamsError reports tool arguments that don't match the tool's
+// InputSchema. The server answers it with a JSON-RPC invalid params error
+// instead of calling the tool.
+type InvalidParamsError struct {
+	Tool     string
+	Problems []string
+}
+
+func (e *InvalidParamsError) Error() string {
+	return fmt.Sprintf("invalid arguments for tool '%s': %s", e.Tool, strings.Join(e.Problems, "; "))
+}
+
+// ValidateArguments checks args against a tool's InputSchema: every
+// required argument must be present and not an empty string, and arguments
+// with a declared type or enum must match it. Arguments the schema doesn't
+// declare are left to the tool, as are null values for optional arguments,
+// which tools treat as not given. It returns one message per problem, sorted, or nil.
+func ValidateArguments(schema InputSchema, args map[string]interface{}) []string {
+	var problems []string
+
+	for _, name := range schema.Required {
+		if value, ok := args[name]; !ok || value == nil || value == "" {
+			problems = append(problems, fmt.Sprintf("missing required argument '%s'", name))
+		}
+	}
+
+	for name, value := range args {
+		if value == nil {
+			continue
+		}
+		property, ok := schema.Properties[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if schemaType, ok := property["type"].(string); ok && !matchesSchemaType(value, schemaType) {
+			problems = append(problems, fmt.Sprintf("argument '%s' must be %s, got %s",
+				name, schemaTypeName(schemaType), jsonTypeName(value)))
+			continue
+		}
+
+		if allowed := enumValues(property["enum"]); allowed != nil && !containsValue(allowed, value) {
+			quoted := make([]string, len(allowed))
+			for i, v := range allowed {
+				quoted[i] = fmt.Sprintf("%v", v)
+			}
+			problems = append(problems, fmt.Sprintf("argument '%s' must be one of: %s (got %v)",
+				name, strings.Join(quoted, ", "), value))
+		}
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+// matchesSchemaType reports whether value, as decoded from JSON, has the
+// given JSON Schema type. Unknown types match anything.
+func matchesSchemaType(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		f, ok := toFloat(value)
+		return ok && f == math.Trunc(f) && !math.IsInf(f, 0)
+	case "array":
+		switch value.(type) {
+		case []interface{}, []string:
+			return true
+		}
+		return false
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	default:
+		return true
+	}
+}
+
+// toFloat converts a JSON number to float64; in-process callers may pass
+// Go integer types
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// schemaTypeName describes a JSON Schema type for an error message
+func schemaTypeName(schemaType string) string {
+	switch schemaType {
+	case "integer", "array", "object":
+		return "an " + schemaType
+	default:
+		return "a " + schemaType
+	}
+}
+
+// jsonTypeName names the JSON type of a decoded value
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}, []string:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if _, ok := toFloat(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// enumValues returns a property's enum, which tools declare as []string;
+// schemas decoded from JSON have []interface{}
+func enumValues(enum interface{}) []interface{} {
+	switch e := enum.(type) {
+	case []string:
+		values := make([]interface{}, len(e))
+		for i, v := range e {
+			values[i] = v
+		}
+		return values
+	case []interface{}:
+		return e
+	}
+	return nil
+}
+
+// containsValue reports whether value is one of the allowed enum values
+func containsValue(allowed []interface{}, value interface{}) bool {
+	for _, v := range allowed {
+		if v == value {
+			return true
+		}
+		if a, ok := toFloat(v); ok {
+			if b, ok := toFloat(value); ok && a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
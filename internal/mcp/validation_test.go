@@ -0,0 +1,154 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Tool Argument Validation Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var validationTestSchema = InputSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"query":   map[string]interface{}{"type": "string"},
+		"limit":   map[string]interface{}{"type": "integer"},
+		"weight":  map[string]interface{}{"type": "number"},
+		"verbose": map[string]interface{}{"type": "boolean"},
+		"columns": map[string]interface{}{"type": "array"},
+		"format":  map[string]interface{}{"type": "string", "enum": []string{"tsv", "csv"}},
+	},
+	Required: []string{"query"},
+}
+
+func TestValidateArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want []string
+	}{
+		{
+			name: "valid",
+			args: map[string]interface{}{
+				"query": "SELECT 1", "limit": float64(10), "weight": 0.5,
+				"verbose": true, "columns": []interface{}{"a"}, "format": "csv",
+			},
+		},
+		{
+			name: "undeclared and null optional arguments are ignored",
+			args: map[string]interface{}{"query": "SELECT 1", "extra": 1, "limit": nil},
+		},
+		{
+			name: "Go integers from in-process callers",
+			args: map[string]interface{}{"query": "SELECT 1", "limit": 10},
+		},
+		{
+			name: "missing required",
+			args: map[string]interface{}{},
+			want: []string{"missing required argument 'query'"},
+		},
+		{
+			name: "null required",
+			args: map[string]interface{}{"query": nil},
+			want: []string{"missing required argument 'query'"},
+		},
+		{
+			name: "empty required string",
+			args: map[string]interface{}{"query": ""},
+			want: []string{"missing required argument 'query'"},
+		},
+		{
+			name: "wrong types",
+			args: map[string]interface{}{"query": 42.0, "limit": 2.5, "verbose": "yes"},
+			want: []string{
+				"argument 'limit' must be an integer, got number",
+				"argument 'query' must be a string, got number",
+				"argument 'verbose' must be a boolean, got string",
+			},
+		},
+		{
+			name: "enum",
+			args: map[string]interface{}{"query": "SELECT 1", "format": "xml"},
+			want: []string{"argument 'format' must be one of: tsv, csv (got xml)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateArguments(validationTestSchema, tt.args)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ValidateArguments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateArguments_DecodedSchema(t *testing.T) {
+	// Schemas that went through JSON have []interface{} enums
+	var schema InputSchema
+	if err := json.Unmarshal([]byte(`{"type":"object","properties":{"n":{"type":"integer","enum":[1,2]}}}`), &schema); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got := ValidateArguments(schema, map[string]interface{}{"n": float64(2)}); got != nil {
+		t.Errorf("ValidateArguments() = %q, want none", got)
+	}
+	if got := ValidateArguments(schema, map[string]interface{}{"n": float64(3)}); len(got) != 1 {
+		t.Errorf("ValidateArguments() = %q, want one problem", got)
+	}
+}
+
+func TestHandleToolCallHTTP_InvalidParams(t *testing.T) {
+	tools := &mockToolProvider{
+		tools: []Tool{{Name: "query_tool", InputSchema: validationTestSchema}},
+		executeFunc: func(ctx context.Context, name string, args map[string]interface{}) (ToolResponse, error) {
+			return ToolResponse{}, &InvalidParamsError{Tool: name, Problems: ValidateArguments(validationTestSchema, args)}
+		},
+	}
+	server := NewServer(tools)
+
+	rpcReq := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params: map[string]interface{}{
+			"name":      "query_tool",
+			"arguments": map[string]interface{}{"format": "xml"},
+		},
+	}
+
+	body, _ := json.Marshal(rpcReq)
+	req := httptest.NewRequest(http.MethodPost, "/mcp/v1", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	server.handleHTTPRequest(w, req)
+
+	var response JSONRPCResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.Error == nil {
+		t.Fatal("expected error response")
+	}
+	if response.Error.Code != -32602 {
+		t.Errorf("expected invalid params code -32602, got %d", response.Error.Code)
+	}
+	data, _ := response.Error.Data.(string)
+	for _, want := range []string{"query_tool", "missing required argument 'query'", "argument 'format' must be one of"} {
+		if !strings.Contains(data, want) {
+			t.Errorf("error data %q missing %q", data, want)
+		}
+	}
+}
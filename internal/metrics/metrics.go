@@ -0,0 +1,179 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+// Package metrics exposes server metrics in the Prometheus format on
+// /metrics (http.metrics_enabled). Nothing is recorded until Enable is called.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// maxToolLabels bounds the distinct tool names recorded, since clients
+// can call any name; further names are recorded as otherToolLabel
+const maxToolLabels = 100
+
+const otherToolLabel = "(other)"
+
+var (
+	enabled  atomic.Bool
+	registry = prometheus.NewRegistry()
+
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pgedge_mcp_tool_calls_total",
+		Help: "Tool calls by tool name and status (success, tool_error or error).",
+	}, []string{"tool", "status"})
+
+	toolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pgedge_mcp_tool_call_duration_seconds",
+		Help:    "Time taken by tool calls.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 4, 8), // 5ms to ~80s
+	}, []string{"tool"})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pgedge_mcp_db_query_duration_seconds",
+		Help:    "Time taken by database queries, by PostgreSQL database name.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 4, 8), // 1ms to ~16s
+	}, []string{"database"})
+
+	toolLabelsMu sync.Mutex
+	toolLabels   = make(map[string]struct{})
+)
+
+func init() {
+	registry.MustRegister(
+		toolCalls,
+		toolDuration,
+		queryDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Enable starts recording metrics
+func Enable() {
+	enabled.Store(true)
+}
+
+// Handler returns the /metrics HTTP handler
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveToolCall records a finished tool call
+func ObserveToolCall(tool, status string, duration time.Duration) {
+	if !enabled.Load() {
+		return
+	}
+	tool = toolLabel(tool)
+	toolCalls.WithLabelValues(tool, status).Inc()
+	toolDuration.WithLabelValues(tool).Observe(duration.Seconds())
+}
+
+// toolLabel returns the label to record tool under, keeping the number of
+// distinct names bounded
+func toolLabel(tool string) string {
+	toolLabelsMu.Lock()
+	defer toolLabelsMu.Unlock()
+
+	if _, seen := toolLabels[tool]; seen {
+		return tool
+	}
+	if len(toolLabels) >= maxToolLabels {
+		return otherToolLabel
+	}
+	toolLabels[tool] = struct{}{}
+	return tool
+}
+
+// RegisterTokenCount reports the number of API tokens returned by count
+func RegisterTokenCount(count func() int) {
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "pgedge_mcp_tokens",
+		Help: "Number of API tokens in the token file.",
+	}, func() float64 {
+		return float64(count())
+	}))
+}
+
+// DatabaseStats is the state of one configured database's connection
+// pools and metadata
+type DatabaseStats struct {
+	Name          string
+	AcquiredConns int32
+	IdleConns     int32
+	Tables        int
+}
+
+// RegisterDatabaseStats reports the connection pools and loaded metadata
+// returned by stats, which is called on each scrape
+func RegisterDatabaseStats(stats func() []DatabaseStats) {
+	registry.MustRegister(&databaseCollector{stats: stats})
+}
+
+var (
+	poolConnectionsDesc = prometheus.NewDesc(
+		"pgedge_mcp_db_pool_connections",
+		"Connections in the database pools, by configured database and state (acquired or idle).",
+		[]string{"database", "state"}, nil)
+
+	metadataTablesDesc = prometheus.NewDesc(
+		"pgedge_mcp_metadata_tables",
+		"Tables and views in the loaded schema metadata, by configured database.",
+		[]string{"database"}, nil)
+)
+
+// databaseCollector reads pool and metadata statistics at scrape time
+type databaseCollector struct {
+	stats func() []DatabaseStats
+}
+
+// Describe implements prometheus.Collector
+func (c *databaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolConnectionsDesc
+	ch <- metadataTablesDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *databaseCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, stats := range c.stats() {
+		ch <- prometheus.MustNewConstMetric(poolConnectionsDesc, prometheus.GaugeValue, float64(stats.AcquiredConns), stats.Name, "acquired")
+		ch <- prometheus.MustNewConstMetric(poolConnectionsDesc, prometheus.GaugeValue, float64(stats.IdleConns), stats.Name, "idle")
+		ch <- prometheus.MustNewConstMetric(metadataTablesDesc, prometheus.GaugeValue, float64(stats.Tables), stats.Name)
+	}
+}
+
+// QueryTracer times database queries; install it with database.SetQueryTracer
+type QueryTracer struct{}
+
+type queryStartKey struct{}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, time.Now())
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, _ pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(time.Time)
+	if !ok || !enabled.Load() {
+		return
+	}
+	queryDuration.WithLabelValues(conn.Config().Database).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,82 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scrape returns the /metrics output
+func scrape(t *testing.T) string {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rr.Body)
+	if err != nil {
+		t.Fatalf("reading metrics: %v", err)
+	}
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	// Nothing is recorded until metrics are enabled
+	ObserveToolCall("query_database", "success", time.Second)
+	if strings.Contains(scrape(t), `tool="query_database"`) {
+		t.Error("tool call recorded before Enable()")
+	}
+
+	Enable()
+	ObserveToolCall("query_database", "success", 20*time.Millisecond)
+	ObserveToolCall("query_database", "tool_error", 5*time.Millisecond)
+
+	RegisterTokenCount(func() int { return 3 })
+	RegisterDatabaseStats(func() []DatabaseStats {
+		return []DatabaseStats{{Name: "main", AcquiredConns: 2, IdleConns: 5, Tables: 42}}
+	})
+
+	out := scrape(t)
+	for _, want := range []string{
+		`pgedge_mcp_tool_calls_total{status="success",tool="query_database"} 1`,
+		`pgedge_mcp_tool_calls_total{status="tool_error",tool="query_database"} 1`,
+		`pgedge_mcp_tool_call_duration_seconds_count{tool="query_database"} 2`,
+		`pgedge_mcp_tokens 3`,
+		`pgedge_mcp_db_pool_connections{database="main",state="acquired"} 2`,
+		`pgedge_mcp_db_pool_connections{database="main",state="idle"} 5`,
+		`pgedge_mcp_metadata_tables{database="main"} 42`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+}
+
+func TestToolLabelLimit(t *testing.T) {
+	toolLabelsMu.Lock()
+	toolLabels = make(map[string]struct{})
+	toolLabelsMu.Unlock()
+
+	for i := 0; i < maxToolLabels; i++ {
+		if got := toolLabel(fmt.Sprintf("tool_%d", i)); got != fmt.Sprintf("tool_%d", i) {
+			t.Fatalf("toolLabel(tool_%d) = %q", i, got)
+		}
+	}
+	if got := toolLabel("one_too_many"); got != otherToolLabel {
+		t.Errorf("toolLabel() past the limit = %q, want %q", got, otherToolLabel)
+	}
+	if got := toolLabel("tool_0"); got != "tool_0" {
+		t.Errorf("toolLabel() of a known tool = %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package prompts
+
+import (
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// DiagnoseLocks creates a prompt for diagnosing lock contention
+func DiagnoseLocks() Prompt {
+	return Prompt{
+		Definition: mcp.Prompt{
+			Name:        "diagnose-locks",
+			Description: "Diagnose lock contention: find the sessions blocking others, what they are doing, and how to resolve it safely.",
+			Arguments:   []mcp.PromptArgument{},
+		},
+		Handler: func(args map[string]string) mcp.PromptResult {
+			return mcp.PromptResult{
+				Description: "Diagnosing lock contention",
+				Messages: []mcp.PromptMessage{
+					{
+						Role: "user",
+						Content: mcp.ContentItem{
+							Type: "text",
+							Text: `Queries on this database seem to be stuck waiting. Find out what is blocking them.
+
+<fresh_data_required>
+Make fresh tool calls; locks change from second to second, so earlier results
+in this conversation are out of date.
+</fresh_data_required>
+
+<workflow>
+Step 1: Who waits for whom
+- Call: lock_wait_graph()
+- Identify the head blocker of each tree: the session holding locks that
+  is not waiting itself
+- Report any cycles separately; PostgreSQL will end one of those sessions
+  with a deadlock error after deadlock_timeout
+
+Step 2: What the head blockers are doing
+- Call: long_running_queries(min_duration_seconds=0)
+- For each head blocker, note its state, how long its transaction has been
+  open, and its query
+- A head blocker that is "idle in transaction" is usually an application
+  that opened a transaction and never committed it
+
+Step 3: If nothing is blocked
+- Say so. The slowness is not lock contention; suggest the
+  find-slow-queries prompt instead
+</workflow>
+
+<resolution>
+Explain the options, least disruptive first:
+1. Wait, if the head blocker is doing legitimate work that will finish soon
+2. Cancel the head blocker's query: long_running_queries(action="cancel",
+   pid=..., confirm=true)
+3. Terminate its session, which rolls back its transaction:
+   long_running_queries(action="terminate", pid=..., confirm=true)
+
+NEVER cancel or terminate a session without the user confirming the PID
+first. Also suggest how to prevent a recurrence, such as
+idle_in_transaction_session_timeout or lock_timeout.
+</resolution>`,
+						},
+					},
+				},
+			}
+		},
+	}
+}
@@ -0,0 +1,94 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package prompts
+
+import (
+	"fmt"
+	"strconv"
+
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// FindSlowQueries creates a prompt for finding the slowest queries and
+// what would make them faster
+func FindSlowQueries() Prompt {
+	return Prompt{
+		Definition: mcp.Prompt{
+			Name:        "find-slow-queries",
+			Description: "Find the slowest queries, both running now and historically from pg_stat_statements, explain why they are slow, and suggest indexes or rewrites.",
+			Arguments: []mcp.PromptArgument{
+				{
+					Name:        "min_duration_seconds",
+					Description: "Report running queries that have been running at least this long (default: 5)",
+					Required:    false,
+				},
+			},
+		},
+		Handler: func(args map[string]string) mcp.PromptResult {
+			minDuration := 5
+			if value, err := strconv.Atoi(args["min_duration_seconds"]); err == nil && value > 0 {
+				minDuration = value
+			}
+
+			return mcp.PromptResult{
+				Description: "Finding slow queries",
+				Messages: []mcp.PromptMessage{
+					{
+						Role: "user",
+						Content: mcp.ContentItem{
+							Type: "text",
+							Text: fmt.Sprintf(`Find the slowest queries on this database and tell me how to make them faster.
+
+<fresh_data_required>
+Make fresh tool calls; query activity changes constantly, so earlier results
+in this conversation are out of date.
+</fresh_data_required>
+
+<workflow>
+Step 1: Queries running now
+- Call: long_running_queries(min_duration_seconds=%[1]d)
+- Note any query that is still running, how long it has run, and what it
+  is waiting on
+- Do NOT cancel or terminate anything unless the user asks
+
+Step 2: Historically slow statements
+- Call: read_resource(uri="pg://stat_statements?order_by=mean_time&limit=10")
+- If pg_stat_statements is not installed, say so and continue with what
+  Step 1 found
+- Look at both the mean time (slow each time) and the total time (called
+  often enough to matter)
+
+Step 3: Why they are slow (up to 3 queries)
+- For the worst SELECT statements, call: execute_explain(query="...")
+- Do NOT use analyze=true on statements that modify data
+- Look for sequential scans of large tables, bad row estimates, sorts that
+  spill to disk and nested loops over many rows
+
+Step 4: Index suggestions
+- Call: suggest_indexes()
+- Match its suggestions to the slow queries found above
+</workflow>
+
+<report_format>
+For each slow query:
+- The statement (shortened if long) and its mean and total time
+- The cause, with the plan line that shows it
+- The fix: a CREATE INDEX CONCURRENTLY statement, a query rewrite, or
+  ANALYZE if the statistics are stale
+Finish with the fixes ordered by expected benefit.
+</report_format>`, minDuration),
+						},
+					},
+				},
+			}
+		},
+	}
+}
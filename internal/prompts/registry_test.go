@@ -454,3 +454,77 @@ func TestPromptArgumentVariations(t *testing.T) {
 		t.Error("Expected prompt text to be generated")
 	}
 }
+
+func TestSummarizeTablePrompt(t *testing.T) {
+	prompt := SummarizeTable()
+
+	if prompt.Definition.Name != "summarize-table" {
+		t.Errorf("Expected name 'summarize-table', got %q", prompt.Definition.Name)
+	}
+	if len(prompt.Definition.Arguments) != 2 || !prompt.Definition.Arguments[0].Required {
+		t.Errorf("Expected required table_name and optional schema_name, got %+v", prompt.Definition.Arguments)
+	}
+
+	result := prompt.Handler(map[string]string{"table_name": "orders", "schema_name": "sales"})
+	text := result.Messages[0].Content.Text
+	for _, want := range []string{
+		`describe_table(schema_name="sales", table_name="orders")`,
+		`get_table_sample(table_name="sales.orders", limit=5)`,
+		`table_maintenance(schema_name="sales", table_name="orders")`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Prompt text missing %q", want)
+		}
+	}
+
+	// The schema defaults to public
+	result = prompt.Handler(map[string]string{"table_name": "orders"})
+	if result.Description != "Summarizing table public.orders" {
+		t.Errorf("Description = %q", result.Description)
+	}
+}
+
+func TestFindSlowQueriesPrompt(t *testing.T) {
+	prompt := FindSlowQueries()
+
+	if prompt.Definition.Name != "find-slow-queries" {
+		t.Errorf("Expected name 'find-slow-queries', got %q", prompt.Definition.Name)
+	}
+
+	text := prompt.Handler(map[string]string{}).Messages[0].Content.Text
+	if !strings.Contains(text, "long_running_queries(min_duration_seconds=5)") {
+		t.Error("Expected the default threshold of 5 seconds")
+	}
+	for _, tool := range []string{"pg://stat_statements", "execute_explain", "suggest_indexes"} {
+		if !strings.Contains(text, tool) {
+			t.Errorf("Prompt text missing %q", tool)
+		}
+	}
+
+	text = prompt.Handler(map[string]string{"min_duration_seconds": "30"}).Messages[0].Content.Text
+	if !strings.Contains(text, "long_running_queries(min_duration_seconds=30)") {
+		t.Error("Expected the given threshold")
+	}
+	text = prompt.Handler(map[string]string{"min_duration_seconds": "soon"}).Messages[0].Content.Text
+	if !strings.Contains(text, "long_running_queries(min_duration_seconds=5)") {
+		t.Error("Expected an invalid threshold to fall back to the default")
+	}
+}
+
+func TestDiagnoseLocksPrompt(t *testing.T) {
+	prompt := DiagnoseLocks()
+
+	if prompt.Definition.Name != "diagnose-locks" {
+		t.Errorf("Expected name 'diagnose-locks', got %q", prompt.Definition.Name)
+	}
+	if len(prompt.Definition.Arguments) != 0 {
+		t.Errorf("Expected 0 arguments, got %d", len(prompt.Definition.Arguments))
+	}
+
+	text := prompt.Handler(map[string]string{}).Messages[0].Content.Text
+	for _, want := range []string{"lock_wait_graph()", "long_running_queries(min_duration_seconds=0)", "confirm=true"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Prompt text missing %q", want)
+		}
+	}
+}
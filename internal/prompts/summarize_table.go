@@ -0,0 +1,95 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package prompts
+
+import (
+	"fmt"
+
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// SummarizeTable creates a prompt for summarizing one table's structure,
+// contents and health
+func SummarizeTable() Prompt {
+	return Prompt{
+		Definition: mcp.Prompt{
+			Name:        "summarize-table",
+			Description: "Summarize one table: what it holds, its columns and keys, a sample of its rows, its size, and whether it needs maintenance.",
+			Arguments: []mcp.PromptArgument{
+				{
+					Name:        "table_name",
+					Description: "Name of the table to summarize",
+					Required:    true,
+				},
+				{
+					Name:        "schema_name",
+					Description: "Schema of the table (default: public)",
+					Required:    false,
+				},
+			},
+		},
+		Handler: func(args map[string]string) mcp.PromptResult {
+			tableName := args["table_name"]
+			if tableName == "" {
+				tableName = "[table name]"
+			}
+			schemaName := args["schema_name"]
+			if schemaName == "" {
+				schemaName = "public"
+			}
+
+			return mcp.PromptResult{
+				Description: fmt.Sprintf("Summarizing table %s.%s", schemaName, tableName),
+				Messages: []mcp.PromptMessage{
+					{
+						Role: "user",
+						Content: mcp.ContentItem{
+							Type: "text",
+							Text: fmt.Sprintf(`Summarize the table %[1]s.%[2]s for me.
+
+<fresh_data_required>
+Make fresh tool calls; do not rely on earlier results in this conversation.
+The table may have changed since, or a different database may be selected.
+</fresh_data_required>
+
+<workflow>
+Step 1: Structure
+- Call: describe_table(schema_name="%[1]s", table_name="%[2]s")
+- Note the columns and types, primary and unique keys, foreign keys,
+  indexes, the estimated row count and the table size
+- If the table is not found, call describe_schema(schema_name="%[1]s")
+  to find the closest name and ask the user before continuing
+
+Step 2: Contents
+- Call: get_table_sample(table_name="%[1]s.%[2]s", limit=5)
+- Infer what each row represents and what the main columns mean
+
+Step 3: Health (one call)
+- Call: table_maintenance(schema_name="%[1]s", table_name="%[2]s")
+- Do NOT set execute=true; only report the recommendation
+</workflow>
+
+<summary_format>
+- Purpose: one or two sentences on what the table stores
+- Columns: the important columns and what they hold (skip obvious ones)
+- Relationships: the tables it references and what references it
+- Size: estimated rows and total size
+- Health: dead rows, statistics freshness, and any recommended maintenance
+</summary_format>
+
+Keep to these three tool calls unless the user asks for more detail.`, schemaName, tableName),
+						},
+					},
+				},
+			}
+		},
+	}
+}
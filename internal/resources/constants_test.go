@@ -28,6 +28,8 @@ func TestURIConstants(t *testing.T) {
 		expected string
 	}{
 		{"URISystemInfo", URISystemInfo, "pg://system_info"},
+		{"URIStatStatements", URIStatStatements, "pg://stat_statements"},
+		{"URIBlockingLocks", URIBlockingLocks, "pg://blocking_locks"},
 	}
 
 	for _, tt := range tests {
@@ -41,7 +43,7 @@ func TestURIConstants(t *testing.T) {
 
 func TestURIFormat(t *testing.T) {
 	// All resource URIs should follow pg:// scheme
-	uris := []string{URISystemInfo}
+	uris := []string{URISystemInfo, URIStatStatements, URIBlockingLocks}
 
 	for _, uri := range uris {
 		if !strings.HasPrefix(uri, "pg://") {
@@ -13,6 +13,7 @@ package resources
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"pgedge-postgres-mcp/internal/auth"
 	"pgedge-postgres-mcp/internal/config"
@@ -64,6 +65,24 @@ func (r *ContextAwareRegistry) List() []mcp.Resource {
 		})
 	}
 
+	if r.cfg.Builtins.Resources.IsResourceEnabled(URIStatStatements) {
+		resources = append(resources, mcp.Resource{
+			URI:         URIStatStatements,
+			Name:        "PostgreSQL Top Statements",
+			Description: "Returns the slowest statements recorded by pg_stat_statements. Append ?limit=N (1-100, default 10) and order_by=total_time|mean_time to the URI.",
+			MimeType:    "application/json",
+		})
+	}
+
+	if r.cfg.Builtins.Resources.IsResourceEnabled(URIBlockingLocks) {
+		resources = append(resources, mcp.Resource{
+			URI:         URIBlockingLocks,
+			Name:        "PostgreSQL Blocking Locks",
+			Description: "Returns sessions waiting on locks as blocking trees (blocker → blocked), with the lock mode, relation and wait time of each waiter.",
+			MimeType:    "application/json",
+		})
+	}
+
 	// Add custom resources
 	for _, customRes := range r.customResources {
 		resources = append(resources, customRes.definition)
@@ -106,8 +125,12 @@ func (r *ContextAwareRegistry) Read(ctx context.Context, uri string) (mcp.Resour
 		}, nil
 	}
 
+	// Built-in resources may take query parameters, e.g.
+	// pg://stat_statements?limit=20
+	baseURI, rawQuery, _ := strings.Cut(uri, "?")
+
 	// Check if the built-in resource is enabled
-	if uri == URISystemInfo && !r.cfg.Builtins.Resources.IsResourceEnabled(uri) {
+	if !r.cfg.Builtins.Resources.IsResourceEnabled(baseURI) {
 		return mcp.ResourceContent{
 			URI: uri,
 			Contents: []mcp.ContentItem{
@@ -121,9 +144,17 @@ func (r *ContextAwareRegistry) Read(ctx context.Context, uri string) (mcp.Resour
 
 	// Create resource handler with the correct client
 	var resource Resource
-	switch uri {
+	switch baseURI {
 	case URISystemInfo:
 		resource = PGSystemInfoResource(dbClient)
+	case URIStatStatements:
+		limit, orderBy, err := ParseStatStatementsParams(rawQuery)
+		if err != nil {
+			return mcp.NewResourceError(uri, fmt.Sprintf("Invalid %s parameters: %v", URIStatStatements, err))
+		}
+		resource = PGStatStatementsResource(dbClient, limit, orderBy)
+	case URIBlockingLocks:
+		resource = PGBlockingLocksResource(dbClient)
 	default:
 		return mcp.ResourceContent{
 			URI: uri,
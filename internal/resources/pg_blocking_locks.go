@@ -0,0 +1,230 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// maxLockQueryLength truncates the query text shown per session
+const maxLockQueryLength = 200
+
+// BlockingLocks is the JSON document returned by pg://blocking_locks
+type BlockingLocks struct {
+	WaitingSessions  int            `json:"waiting_sessions"`
+	InvolvedSessions int            `json:"involved_sessions"`
+	Message          string         `json:"message,omitempty"`
+	BlockingTrees    []*LockSession `json:"blocking_trees,omitempty"`
+}
+
+// LockSession is a backend in a blocking tree. Blocked lists the sessions
+// waiting on it; a session waiting on several blockers appears under each.
+type LockSession struct {
+	PID                int            `json:"pid"`
+	User               string         `json:"user,omitempty"`
+	State              string         `json:"state,omitempty"`
+	TransactionSeconds float64        `json:"transaction_seconds"`
+	LockMode           string         `json:"lock_mode,omitempty"`
+	LockTarget         string         `json:"lock_target,omitempty"`
+	WaitSeconds        float64        `json:"wait_seconds,omitempty"`
+	Query              string         `json:"query,omitempty"`
+	Cycle              bool           `json:"cycle,omitempty"`
+	Blocked            []*LockSession `json:"blocked,omitempty"`
+}
+
+// lockSessionRow is a session read from pg_stat_activity and pg_locks
+type lockSessionRow struct {
+	LockSession
+	blockedBy []int
+}
+
+// PGBlockingLocksResource creates a resource showing which sessions are
+// waiting for locks held by which others, as blocking trees
+func PGBlockingLocksResource(dbClient *database.Client) Resource {
+	return Resource{
+		Definition: mcp.Resource{
+			URI:  URIBlockingLocks,
+			Name: "PostgreSQL Blocking Locks",
+			Description: `Sessions waiting on locks, arranged as blocking trees (blocker → blocked).
+
+<usecase>
+Use when queries hang or pile up:
+- Find the head blocker that other sessions are queued behind
+- See which relation and lock mode each waiter wants, and for how long
+</usecase>
+
+<provided_info>
+Returns JSON with one tree per head blocker (a session that blocks others
+but is not waiting itself). Each session has pid, user, state,
+transaction_seconds and query; waiting sessions also have lock_mode,
+lock_target (relation, or lock type for non-relation locks) and
+wait_seconds. A session seen again in its own chain is marked cycle=true.
+</provided_info>
+
+<important>
+Query text of other users' sessions is only visible to superusers or
+members of pg_read_all_stats. The result is a snapshot.
+</important>`,
+			MimeType: "application/json",
+		},
+		Handler: func() (mcp.ResourceContent, error) {
+			if !dbClient.IsMetadataLoaded() {
+				return mcp.NewResourceError(URIBlockingLocks, mcp.DatabaseNotReadyErrorShort)
+			}
+
+			pool := dbClient.GetPool()
+			if pool == nil {
+				return mcp.ResourceContent{}, fmt.Errorf("no connection pool available")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), dbClient.QueryTimeout())
+			defer cancel()
+
+			// pg_locks.waitstart only exists from PostgreSQL 14, so read it
+			// through to_jsonb to keep the query valid on older servers
+			rows, err := pool.Query(ctx, `
+				WITH waiting AS (
+					SELECT pid, pg_blocking_pids(pid) AS blockers
+					FROM pg_stat_activity
+					WHERE cardinality(pg_blocking_pids(pid)) > 0
+				),
+				involved AS (
+					SELECT pid FROM waiting
+					UNION
+					SELECT unnest(blockers) FROM waiting
+				)
+				SELECT a.pid, COALESCE(a.usename, ''), COALESCE(a.state, ''), COALESCE(a.query, ''),
+				       COALESCE(EXTRACT(EPOCH FROM now() - COALESCE(a.xact_start, a.query_start))::float8, 0),
+				       COALESCE(l.mode, ''),
+				       COALESCE(l.relation::regclass::text, l.locktype, ''),
+				       CASE WHEN l.mode IS NULL THEN 0 ELSE COALESCE(EXTRACT(EPOCH FROM now() -
+				           COALESCE((to_jsonb(l) ->> 'waitstart')::timestamptz, a.state_change))::float8, 0) END,
+				       COALESCE(w.blockers, '{}')
+				FROM involved i
+				JOIN pg_stat_activity a ON a.pid = i.pid
+				LEFT JOIN waiting w ON w.pid = a.pid
+				LEFT JOIN LATERAL (
+					SELECT *
+					FROM pg_locks
+					WHERE pid = a.pid AND NOT granted
+					LIMIT 1
+				) l ON true`)
+			if err != nil {
+				return mcp.ResourceContent{}, fmt.Errorf("failed to query blocked sessions: %w", err)
+			}
+			defer rows.Close()
+
+			sessions := make(map[int]*lockSessionRow)
+			for rows.Next() {
+				var s lockSessionRow
+				var blockers []int32
+				if err := rows.Scan(&s.PID, &s.User, &s.State, &s.Query, &s.TransactionSeconds,
+					&s.LockMode, &s.LockTarget, &s.WaitSeconds, &blockers); err != nil {
+					return mcp.ResourceContent{}, fmt.Errorf("failed to scan session: %w", err)
+				}
+				s.Query = truncateLockQuery(s.Query)
+				for _, b := range blockers {
+					s.blockedBy = append(s.blockedBy, int(b))
+				}
+				sessions[s.PID] = &s
+			}
+			if err := rows.Err(); err != nil {
+				return mcp.ResourceContent{}, fmt.Errorf("error iterating rows: %w", err)
+			}
+
+			result := BlockingLocks{InvolvedSessions: len(sessions)}
+			for _, s := range sessions {
+				if len(s.blockedBy) > 0 {
+					result.WaitingSessions++
+				}
+			}
+			if len(sessions) == 0 {
+				result.Message = "No sessions are currently waiting on locks."
+			}
+			result.BlockingTrees = buildBlockingTrees(sessions)
+
+			jsonData, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return mcp.ResourceContent{}, fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			return mcp.NewResourceSuccess(URIBlockingLocks, "application/json", string(jsonData))
+		},
+	}
+}
+
+// buildBlockingTrees arranges sessions into trees rooted at sessions that
+// block others without waiting themselves. Sessions only reachable through
+// a cycle are rooted at the lowest PID not yet shown.
+func buildBlockingTrees(sessions map[int]*lockSessionRow) []*LockSession {
+	pids := make([]int, 0, len(sessions))
+	for pid := range sessions {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+
+	waiters := make(map[int][]int)
+	for _, pid := range pids {
+		for _, blocker := range sessions[pid].blockedBy {
+			waiters[blocker] = append(waiters[blocker], pid)
+		}
+	}
+
+	shown := make(map[int]bool)
+	var build func(pid int, onPath map[int]bool) *LockSession
+	build = func(pid int, onPath map[int]bool) *LockSession {
+		row, ok := sessions[pid]
+		if !ok {
+			return &LockSession{PID: pid}
+		}
+		node := row.LockSession
+		node.Blocked = nil
+		if onPath[pid] {
+			return &LockSession{PID: pid, Cycle: true}
+		}
+		shown[pid] = true
+		onPath[pid] = true
+		for _, w := range waiters[pid] {
+			node.Blocked = append(node.Blocked, build(w, onPath))
+		}
+		delete(onPath, pid)
+		return &node
+	}
+
+	var trees []*LockSession
+	for _, pid := range pids {
+		if len(sessions[pid].blockedBy) == 0 {
+			trees = append(trees, build(pid, make(map[int]bool)))
+		}
+	}
+	for _, pid := range pids {
+		if !shown[pid] {
+			trees = append(trees, build(pid, make(map[int]bool)))
+		}
+	}
+	return trees
+}
+
+// truncateLockQuery collapses whitespace and shortens query text
+func truncateLockQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	runes := []rune(query)
+	if len(runes) > maxLockQueryLength {
+		return string(runes[:maxLockQueryLength]) + "..."
+	}
+	return query
+}
@@ -0,0 +1,86 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package resources
+
+import (
+	"strings"
+	"testing"
+)
+
+func lockRow(pid int, blockedBy ...int) *lockSessionRow {
+	return &lockSessionRow{LockSession: LockSession{PID: pid}, blockedBy: blockedBy}
+}
+
+func TestBuildBlockingTrees(t *testing.T) {
+	t.Run("chain", func(t *testing.T) {
+		// 100 holds the lock, 200 waits on 100, 300 waits on 200
+		trees := buildBlockingTrees(map[int]*lockSessionRow{
+			100: lockRow(100),
+			200: lockRow(200, 100),
+			300: lockRow(300, 200),
+		})
+		if len(trees) != 1 || trees[0].PID != 100 {
+			t.Fatalf("expected one tree rooted at 100, got %+v", trees)
+		}
+		if len(trees[0].Blocked) != 1 || trees[0].Blocked[0].PID != 200 {
+			t.Fatalf("expected 200 under 100, got %+v", trees[0].Blocked)
+		}
+		if b := trees[0].Blocked[0].Blocked; len(b) != 1 || b[0].PID != 300 {
+			t.Errorf("expected 300 under 200, got %+v", b)
+		}
+	})
+
+	t.Run("two head blockers", func(t *testing.T) {
+		trees := buildBlockingTrees(map[int]*lockSessionRow{
+			100: lockRow(100),
+			150: lockRow(150),
+			200: lockRow(200, 100, 150),
+		})
+		if len(trees) != 2 {
+			t.Fatalf("expected two trees, got %d", len(trees))
+		}
+		for _, tree := range trees {
+			if len(tree.Blocked) != 1 || tree.Blocked[0].PID != 200 {
+				t.Errorf("expected 200 under %d, got %+v", tree.PID, tree.Blocked)
+			}
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		trees := buildBlockingTrees(map[int]*lockSessionRow{
+			100: lockRow(100, 200),
+			200: lockRow(200, 100),
+		})
+		if len(trees) != 1 || trees[0].PID != 100 {
+			t.Fatalf("expected one tree rooted at 100, got %+v", trees)
+		}
+		back := trees[0].Blocked[0].Blocked
+		if len(back) != 1 || back[0].PID != 100 || !back[0].Cycle {
+			t.Errorf("expected cycle marker back to 100, got %+v", back)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		if trees := buildBlockingTrees(map[int]*lockSessionRow{}); len(trees) != 0 {
+			t.Errorf("expected no trees, got %d", len(trees))
+		}
+	})
+}
+
+func TestTruncateLockQuery(t *testing.T) {
+	if got := truncateLockQuery("SELECT  *\n\tFROM t"); got != "SELECT * FROM t" {
+		t.Errorf("truncateLockQuery() = %q", got)
+	}
+	long := truncateLockQuery(strings.Repeat("x", maxLockQueryLength+10))
+	if len([]rune(long)) != maxLockQueryLength+3 || !strings.HasSuffix(long, "...") {
+		t.Errorf("expected truncation to %d characters plus ellipsis, got %d", maxLockQueryLength, len(long))
+	}
+}
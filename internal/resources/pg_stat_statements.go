@@ -0,0 +1,217 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+const (
+	// DefaultStatStatementsLimit is the number of statements returned when
+	// the URI has no limit parameter
+	DefaultStatStatementsLimit = 10
+	// MaxStatStatementsLimit caps the limit parameter
+	MaxStatStatementsLimit = 100
+	// maxStatementTextLength truncates long normalized query texts
+	maxStatementTextLength = 1000
+)
+
+// Orderings accepted by the order_by parameter of pg://stat_statements
+const (
+	StatStatementsOrderTotal = "total_time"
+	StatStatementsOrderMean  = "mean_time"
+)
+
+// StatStatements is the JSON document returned by pg://stat_statements
+type StatStatements struct {
+	Available  bool            `json:"available"`
+	Message    string          `json:"message,omitempty"`
+	OrderBy    string          `json:"order_by,omitempty"`
+	Limit      int             `json:"limit,omitempty"`
+	Statements []StatStatement `json:"statements,omitempty"`
+}
+
+// StatStatement is a single pg_stat_statements entry
+type StatStatement struct {
+	Calls         int64   `json:"calls"`
+	TotalExecTime float64 `json:"total_exec_time_ms"`
+	MeanExecTime  float64 `json:"mean_exec_time_ms"`
+	Rows          int64   `json:"rows"`
+	Query         string  `json:"query"`
+}
+
+// ParseStatStatementsParams reads the limit and order_by query parameters of
+// a pg://stat_statements URI, applying defaults for missing values
+func ParseStatStatementsParams(rawQuery string) (limit int, orderBy string, err error) {
+	params, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid query parameters: %w", err)
+	}
+
+	limit = DefaultStatStatementsLimit
+	if v := params.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > MaxStatStatementsLimit {
+			return 0, "", fmt.Errorf("limit must be an integer between 1 and %d", MaxStatStatementsLimit)
+		}
+	}
+
+	orderBy = StatStatementsOrderTotal
+	if v := params.Get("order_by"); v != "" {
+		if v != StatStatementsOrderTotal && v != StatStatementsOrderMean {
+			return 0, "", fmt.Errorf("order_by must be '%s' or '%s'", StatStatementsOrderTotal, StatStatementsOrderMean)
+		}
+		orderBy = v
+	}
+
+	return limit, orderBy, nil
+}
+
+// PGStatStatementsResource creates a resource listing the slowest statements
+// recorded by pg_stat_statements
+func PGStatStatementsResource(dbClient *database.Client, limit int, orderBy string) Resource {
+	return Resource{
+		Definition: mcp.Resource{
+			URI:  URIStatStatements,
+			Name: "PostgreSQL Top Statements",
+			Description: `Slowest statements recorded by the pg_stat_statements extension.
+
+<usecase>
+Use for:
+- Finding the queries that consume the most database time
+- Spotting individually slow queries (order_by=mean_time)
+- Choosing which queries to examine with execute_explain
+</usecase>
+
+<provided_info>
+Returns JSON with, per normalized statement: calls, total_exec_time_ms,
+mean_exec_time_ms, rows and the normalized query text (constants replaced by
+$1, $2, ...).
+</provided_info>
+
+<parameters>
+Append query parameters to the URI:
+- limit: number of statements, 1-100 (default: 10)
+- order_by: total_time (default) or mean_time
+Example: pg://stat_statements?limit=20&order_by=mean_time
+</parameters>
+
+<important>
+If pg_stat_statements is not installed or not readable, the resource returns
+available=false with instructions instead of an error.
+</important>`,
+			MimeType: "application/json",
+		},
+		Handler: func() (mcp.ResourceContent, error) {
+			if !dbClient.IsMetadataLoaded() {
+				return mcp.NewResourceError(URIStatStatements, mcp.DatabaseNotReadyErrorShort)
+			}
+
+			pool := dbClient.GetPool()
+			if pool == nil {
+				return mcp.ResourceContent{}, fmt.Errorf("no connection pool available")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), dbClient.QueryTimeout())
+			defer cancel()
+
+			result := StatStatements{OrderBy: orderBy, Limit: limit}
+
+			// Look the view up without referencing it directly, so a missing
+			// extension doesn't raise an error. PostgreSQL 12 and older name
+			// the timing columns total_time and mean_time.
+			var readable, hasExecTime bool
+			err := pool.QueryRow(ctx, `
+				SELECT
+					COALESCE(has_table_privilege(to_regclass('pg_stat_statements'), 'SELECT'), false),
+					EXISTS (
+						SELECT 1 FROM pg_attribute
+						WHERE attrelid = to_regclass('pg_stat_statements')
+						AND attname = 'total_exec_time'
+					)`).Scan(&readable, &hasExecTime)
+			if err != nil {
+				return mcp.ResourceContent{}, fmt.Errorf("failed to check for pg_stat_statements: %w", err)
+			}
+
+			if !readable {
+				result.Message = "pg_stat_statements is not installed in this database or is not readable by the current user. " +
+					"Add it to shared_preload_libraries, restart PostgreSQL, run CREATE EXTENSION pg_stat_statements, " +
+					"and grant pg_read_all_stats to the connecting role."
+				return statStatementsContent(result)
+			}
+
+			totalCol, meanCol := "total_exec_time", "mean_exec_time"
+			if !hasExecTime {
+				totalCol, meanCol = "total_time", "mean_time"
+			}
+			orderCol := totalCol
+			if orderBy == StatStatementsOrderMean {
+				orderCol = meanCol
+			}
+
+			query := fmt.Sprintf(`
+				SELECT calls, %s, %s, rows, query
+				FROM pg_stat_statements
+				WHERE query IS NOT NULL
+				ORDER BY %s DESC
+				LIMIT $1`, totalCol, meanCol, orderCol)
+
+			rows, err := pool.Query(ctx, query, limit)
+			if err != nil {
+				// Most often the library is not in shared_preload_libraries
+				result.Message = fmt.Sprintf("pg_stat_statements is installed but could not be read: %v", err)
+				return statStatementsContent(result)
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				var s StatStatement
+				if err := rows.Scan(&s.Calls, &s.TotalExecTime, &s.MeanExecTime, &s.Rows, &s.Query); err != nil {
+					return mcp.ResourceContent{}, fmt.Errorf("failed to scan statement: %w", err)
+				}
+				if len(s.Query) > maxStatementTextLength {
+					cut := maxStatementTextLength
+					for cut > 0 && !utf8.RuneStart(s.Query[cut]) {
+						cut--
+					}
+					s.Query = s.Query[:cut] + "..."
+				}
+				result.Statements = append(result.Statements, s)
+			}
+			if err := rows.Err(); err != nil {
+				return mcp.ResourceContent{}, fmt.Errorf("error iterating rows: %w", err)
+			}
+
+			result.Available = true
+			if len(result.Statements) == 0 {
+				result.Message = "pg_stat_statements has not recorded any statements yet."
+			}
+			return statStatementsContent(result)
+		},
+	}
+}
+
+// statStatementsContent marshals the result as the resource's JSON content
+func statStatementsContent(result StatStatements) (mcp.ResourceContent, error) {
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.ResourceContent{}, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return mcp.NewResourceSuccess(URIStatStatements, "application/json", string(jsonData))
+}
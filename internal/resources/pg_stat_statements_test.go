@@ -0,0 +1,53 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package resources
+
+import (
+	"testing"
+)
+
+func TestParseStatStatementsParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		rawQuery    string
+		wantLimit   int
+		wantOrderBy string
+		wantErr     bool
+	}{
+		{"defaults", "", DefaultStatStatementsLimit, StatStatementsOrderTotal, false},
+		{"limit", "limit=25", 25, StatStatementsOrderTotal, false},
+		{"mean time", "order_by=mean_time", DefaultStatStatementsLimit, StatStatementsOrderMean, false},
+		{"both", "limit=5&order_by=total_time", 5, StatStatementsOrderTotal, false},
+		{"limit too large", "limit=1000", 0, "", true},
+		{"limit zero", "limit=0", 0, "", true},
+		{"limit not a number", "limit=ten", 0, "", true},
+		{"unknown order", "order_by=calls", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, orderBy, err := ParseStatStatementsParams(tt.rawQuery)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q", tt.rawQuery)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if limit != tt.wantLimit || orderBy != tt.wantOrderBy {
+				t.Errorf("got limit=%d order_by=%q, want limit=%d order_by=%q",
+					limit, orderBy, tt.wantLimit, tt.wantOrderBy)
+			}
+		})
+	}
+}
@@ -15,4 +15,8 @@ package resources
 const (
 	// System Information Resources
 	URISystemInfo = "pg://system_info"
+
+	// Statistics Resources
+	URIStatStatements = "pg://stat_statements"
+	URIBlockingLocks  = "pg://blocking_locks"
 )
@@ -26,6 +26,7 @@ type ScoredChunk struct {
 type VectorSearchResult struct {
 	RowData       map[string]interface{} // All row data
 	Distance      float64                // Combined/weighted distance score
+	Score         float64                // Hybrid search score, higher is better (0 for vector-only search)
 	VectorWeights map[string]float64     // Weight per vector column used
 }
 
@@ -44,9 +45,17 @@ type SearchConfig struct {
 	OverlapTokens   int     // Overlap between chunks
 	Lambda          float64 // MMR diversity parameter (0=max diversity, 1=max relevance)
 	MaxOutputTokens int     // Maximum total tokens to return
-	DistanceMetric  string  // "cosine", "l2", or "inner_product"
+	DistanceMetric  string  // "cosine", "l2", "inner_product" or "l1"
+	Mode            string  // "vector" or "hybrid" (vector distance fused with full-text rank)
+	KeywordWeight   float64 // Share of the hybrid score given to the full-text rank (0-1)
 }
 
+// Search modes
+const (
+	SearchModeVector = "vector"
+	SearchModeHybrid = "hybrid"
+)
+
 // DefaultSearchConfig returns default configuration
 func DefaultSearchConfig() SearchConfig {
 	return SearchConfig{
@@ -56,5 +65,7 @@ func DefaultSearchConfig() SearchConfig {
 		Lambda:          0.6,
 		MaxOutputTokens: 1000,
 		DistanceMetric:  "cosine",
+		Mode:            SearchModeVector,
+		KeywordWeight:   0.3,
 	}
 }
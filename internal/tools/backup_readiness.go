@@ -0,0 +1,390 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// staleArchiveAge is how long since the last archived WAL file before
+	// archiving is reported as possibly stalled
+	staleArchiveAge = 24 * time.Hour
+
+	// inactiveSlotRetainedWarning is the WAL an inactive replication slot
+	// may hold back before it is flagged
+	inactiveSlotRetainedWarning = 1024 * 1024 * 1024
+)
+
+// Backup readiness check results
+const (
+	backupCheckPass = "pass"
+	backupCheckWarn = "warn"
+	backupCheckFail = "fail"
+)
+
+// placeholderArchiveCommands are archive_command values that report success
+// without storing the WAL file anywhere
+var placeholderArchiveCommands = map[string]bool{
+	"/bin/true":     true,
+	"/usr/bin/true": true,
+	"true":          true,
+	":":             true,
+	"exit 0":        true,
+}
+
+// backupState is the server state inspected by backup_readiness
+type backupState struct {
+	walLevel       string
+	archiveMode    string
+	archiveCommand string
+	archiveLibrary string
+	maxWalSenders  int
+
+	archivedCount    int64
+	lastArchivedTime *time.Time
+	failedCount      int64
+	lastFailedTime   *time.Time
+	lastFailedWal    string
+
+	inRecovery bool
+	slots      []replicationSlotState
+}
+
+// replicationSlotState describes one replication slot
+type replicationSlotState struct {
+	name          string
+	slotType      string
+	active        bool
+	retainedBytes *int64
+}
+
+// backupCheck is the outcome of one readiness check
+type backupCheck struct {
+	name   string
+	status string
+	detail string
+	fix    string // What to do about a warn/fail result
+}
+
+// BackupReadinessTool creates the backup_readiness tool
+func BackupReadinessTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "backup_readiness",
+			Description: `Check whether the server is set up for physical backups and point-in-time recovery (pgBackRest, pg_basebackup, Barman). Read-only.
+
+<usecase>
+Use when:
+- Asked "are our backups working?" or "can we do point-in-time recovery?"
+- Reviewing a server before relying on it in production
+- Following up on backup advice with a concrete check
+</usecase>
+
+<what_it_returns>
+- A readiness score (percentage of checks passed; warnings count half)
+- One row per check with pass/warn/fail and details:
+  wal_level, archive_mode, archive_command (including the '/bin/true'
+  placeholder), max_wal_senders, WAL archiving health from pg_stat_archiver,
+  and replication slots that are inactive and retaining WAL
+- A list of gaps with what to change
+</what_it_returns>
+
+<important>
+- Only checks server configuration and archiver statistics; it cannot verify
+  that backups exist or can be restored
+- Settings changes it suggests need a configuration reload or restart
+- Reading archive_command may require superuser or pg_read_all_settings
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+
+			var state backupState
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				return readBackupState(ctx, tx, &state)
+			})
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Error checking backup readiness: %v", err))
+			}
+
+			checks := evaluateBackupReadiness(&state, time.Now())
+			score := backupReadinessScore(checks)
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(fmt.Sprintf("Backup readiness: %d%%\n", score))
+			if state.inRecovery {
+				sb.WriteString("Note: this server is a standby; archiving normally runs on the primary unless archive_mode is 'always'.\n")
+			}
+			sb.WriteString("\n")
+
+			rows := make([][]interface{}, len(checks))
+			for i, c := range checks {
+				rows[i] = []interface{}{c.name, c.status, c.detail}
+			}
+			sb.WriteString(FormatResultsAsTSV([]string{"check", "status", "detail"}, rows))
+			sb.WriteString("\n")
+
+			var gaps []string
+			for _, c := range checks {
+				if c.status != backupCheckPass && c.fix != "" {
+					gaps = append(gaps, fmt.Sprintf("[%s] %s: %s", c.status, c.name, c.fix))
+				}
+			}
+			if len(gaps) > 0 {
+				sb.WriteString("\n<gaps>\n")
+				for _, g := range gaps {
+					sb.WriteString("- " + g + "\n")
+				}
+				sb.WriteString("</gaps>\n")
+			} else {
+				sb.WriteString("\nNo gaps found. Remember to test restores regularly; this check cannot verify backups exist.\n")
+			}
+
+			logging.Info("backup_readiness_executed",
+				"score", score,
+				"gaps", len(gaps),
+				"in_recovery", state.inRecovery,
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// readBackupState loads the settings, archiver statistics and replication
+// slots that backup readiness depends on
+func readBackupState(ctx context.Context, tx pgx.Tx, state *backupState) error {
+	err := tx.QueryRow(ctx, `
+		SELECT current_setting('wal_level'),
+		       current_setting('archive_mode'),
+		       COALESCE(current_setting('archive_command', true), ''),
+		       COALESCE(current_setting('archive_library', true), ''),
+		       current_setting('max_wal_senders')::int,
+		       pg_is_in_recovery()`).Scan(
+		&state.walLevel, &state.archiveMode, &state.archiveCommand, &state.archiveLibrary,
+		&state.maxWalSenders, &state.inRecovery)
+	if err != nil {
+		return fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		SELECT archived_count, last_archived_time, failed_count, last_failed_time,
+		       COALESCE(last_failed_wal, '')
+		FROM pg_stat_archiver`).Scan(
+		&state.archivedCount, &state.lastArchivedTime, &state.failedCount,
+		&state.lastFailedTime, &state.lastFailedWal)
+	if err != nil {
+		return fmt.Errorf("failed to read pg_stat_archiver: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT slot_name, slot_type, active,
+		       CASE WHEN pg_is_in_recovery() OR restart_lsn IS NULL THEN NULL
+		            ELSE pg_wal_lsn_diff(pg_current_wal_lsn(), restart_lsn)::bigint
+		       END
+		FROM pg_replication_slots
+		ORDER BY slot_name`)
+	if err != nil {
+		return fmt.Errorf("failed to read replication slots: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var slot replicationSlotState
+		if err := rows.Scan(&slot.name, &slot.slotType, &slot.active, &slot.retainedBytes); err != nil {
+			return fmt.Errorf("failed to scan replication slot: %w", err)
+		}
+		state.slots = append(state.slots, slot)
+	}
+	return rows.Err()
+}
+
+// evaluateBackupReadiness turns the inspected state into readiness checks
+func evaluateBackupReadiness(state *backupState, now time.Time) []backupCheck {
+	var checks []backupCheck
+
+	// wal_level
+	walLevel := backupCheck{name: "wal_level", status: backupCheckPass,
+		detail: fmt.Sprintf("wal_level = %s", state.walLevel)}
+	if state.walLevel == "minimal" {
+		walLevel.status = backupCheckFail
+		walLevel.fix = "set wal_level = replica (or logical); minimal WAL cannot be archived or used for base backups (restart required)"
+	}
+	checks = append(checks, walLevel)
+
+	// archive_mode
+	archiving := state.archiveMode == "on" || state.archiveMode == "always"
+	archiveMode := backupCheck{name: "archive_mode", status: backupCheckPass,
+		detail: fmt.Sprintf("archive_mode = %s", state.archiveMode)}
+	if !archiving {
+		archiveMode.status = backupCheckFail
+		archiveMode.fix = "set archive_mode = on so completed WAL files are archived for point-in-time recovery (restart required)"
+	}
+	checks = append(checks, archiveMode)
+
+	// archive_command / archive_library
+	command := strings.TrimSpace(state.archiveCommand)
+	archiveCmd := backupCheck{name: "archive_command", status: backupCheckPass}
+	switch {
+	case state.archiveLibrary != "":
+		archiveCmd.detail = fmt.Sprintf("archive_library = %s", state.archiveLibrary)
+	case command == "":
+		archiveCmd.status = backupCheckFail
+		archiveCmd.detail = "archive_command is not set"
+		archiveCmd.fix = "set archive_command to your backup tool's push command (e.g. pgbackrest --stanza=<name> archive-push %p)"
+	case placeholderArchiveCommands[command]:
+		archiveCmd.status = backupCheckFail
+		archiveCmd.detail = fmt.Sprintf("archive_command = '%s' is a placeholder; WAL is discarded", command)
+		archiveCmd.fix = "replace the placeholder with a command that actually stores WAL (e.g. pgbackrest --stanza=<name> archive-push %p)"
+	default:
+		archiveCmd.detail = fmt.Sprintf("archive_command = '%s'", command)
+	}
+	checks = append(checks, archiveCmd)
+
+	// max_wal_senders (pg_basebackup and pgBackRest backup-from-standby use replication connections)
+	walSenders := backupCheck{name: "max_wal_senders", status: backupCheckPass,
+		detail: fmt.Sprintf("max_wal_senders = %d", state.maxWalSenders)}
+	if state.maxWalSenders == 0 {
+		walSenders.status = backupCheckFail
+		walSenders.fix = "set max_wal_senders > 0 so pg_basebackup can stream a base backup (restart required)"
+	}
+	checks = append(checks, walSenders)
+
+	// WAL archiving health
+	checks = append(checks, evaluateArchiver(state, archiving, now))
+
+	// Replication slots
+	checks = append(checks, evaluateReplicationSlots(state.slots))
+
+	return checks
+}
+
+// evaluateArchiver checks pg_stat_archiver for recent successful archiving
+func evaluateArchiver(state *backupState, archiving bool, now time.Time) backupCheck {
+	check := backupCheck{name: "wal_archiving", status: backupCheckPass}
+
+	if !archiving {
+		check.status = backupCheckFail
+		check.detail = "archiving is disabled"
+		return check
+	}
+
+	if state.lastArchivedTime == nil {
+		check.status = backupCheckWarn
+		check.detail = fmt.Sprintf("no WAL file archived since stats reset (%d failures)", state.failedCount)
+		check.fix = "force a WAL switch with SELECT pg_switch_wal() and confirm the file reaches the archive"
+		if state.failedCount > 0 {
+			check.status = backupCheckFail
+			check.fix = "archive_command is failing; check the server log for the archiver's error"
+		}
+		return check
+	}
+
+	check.detail = fmt.Sprintf("%d archived, last at %s; %d failed",
+		state.archivedCount, state.lastArchivedTime.Format(time.RFC3339), state.failedCount)
+
+	if state.lastFailedTime != nil && state.lastFailedTime.After(*state.lastArchivedTime) {
+		check.status = backupCheckFail
+		check.detail += fmt.Sprintf(", last failure at %s (%s)", state.lastFailedTime.Format(time.RFC3339), state.lastFailedWal)
+		check.fix = "the most recent archive attempt failed; WAL is accumulating in pg_wal until archive_command succeeds. Check the server log for the error"
+		return check
+	}
+
+	if now.Sub(*state.lastArchivedTime) > staleArchiveAge {
+		check.status = backupCheckWarn
+		check.fix = fmt.Sprintf("no WAL archived in over %s; on a busy server this suggests archiving has stalled, on an idle one set archive_timeout so recovery points stay recent",
+			staleArchiveAge)
+	}
+	return check
+}
+
+// evaluateReplicationSlots flags inactive slots, which make the server keep
+// WAL indefinitely
+func evaluateReplicationSlots(slots []replicationSlotState) backupCheck {
+	check := backupCheck{name: "replication_slots", status: backupCheckPass}
+
+	if len(slots) == 0 {
+		check.detail = "no replication slots"
+		return check
+	}
+
+	var inactive []string
+	heavy := false
+	for _, slot := range slots {
+		if slot.active {
+			continue
+		}
+		desc := fmt.Sprintf("%s (%s)", slot.name, slot.slotType)
+		if slot.retainedBytes != nil {
+			desc = fmt.Sprintf("%s (%s, retaining %s)", slot.name, slot.slotType, formatBytes(*slot.retainedBytes))
+			if *slot.retainedBytes >= inactiveSlotRetainedWarning {
+				heavy = true
+			}
+		}
+		inactive = append(inactive, desc)
+	}
+
+	if len(inactive) == 0 {
+		check.detail = fmt.Sprintf("%d slot(s), all active", len(slots))
+		return check
+	}
+
+	check.status = backupCheckWarn
+	check.detail = fmt.Sprintf("%d slot(s), inactive: %s", len(slots), strings.Join(inactive, ", "))
+	check.fix = "inactive slots keep WAL in pg_wal until their consumer returns; drop slots that are no longer used with pg_drop_replication_slot()"
+	if heavy {
+		check.fix = "inactive slots are retaining over 1 GB of WAL and can fill the disk; " + check.fix
+	}
+	return check
+}
+
+// backupReadinessScore is the percentage of checks passed, counting
+// warnings as half
+func backupReadinessScore(checks []backupCheck) int {
+	if len(checks) == 0 {
+		return 0
+	}
+	points := 0
+	for _, c := range checks {
+		switch c.status {
+		case backupCheckPass:
+			points += 2
+		case backupCheckWarn:
+			points++
+		}
+	}
+	return points * 100 / (2 * len(checks))
+}
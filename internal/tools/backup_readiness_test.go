@@ -0,0 +1,158 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Backup Readiness Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackupReadinessToolDefinition(t *testing.T) {
+	tool := BackupReadinessTool(nil)
+
+	if tool.Definition.Name != "backup_readiness" {
+		t.Errorf("Tool name = %v, want backup_readiness", tool.Definition.Name)
+	}
+
+	if len(tool.Definition.InputSchema.Properties) != 0 {
+		t.Errorf("Expected no properties, got %d", len(tool.Definition.InputSchema.Properties))
+	}
+}
+
+func findBackupCheck(t *testing.T, checks []backupCheck, name string) backupCheck {
+	t.Helper()
+	for _, c := range checks {
+		if c.name == name {
+			return c
+		}
+	}
+	t.Fatalf("check %q not found", name)
+	return backupCheck{}
+}
+
+func TestEvaluateBackupReadiness(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-5 * time.Minute)
+	old := now.Add(-48 * time.Hour)
+
+	ready := func() *backupState {
+		return &backupState{
+			walLevel:         "replica",
+			archiveMode:      "on",
+			archiveCommand:   "pgbackrest --stanza=main archive-push %p",
+			maxWalSenders:    10,
+			archivedCount:    42,
+			lastArchivedTime: &recent,
+		}
+	}
+
+	t.Run("fully configured", func(t *testing.T) {
+		checks := evaluateBackupReadiness(ready(), now)
+		for _, c := range checks {
+			if c.status != backupCheckPass {
+				t.Errorf("check %s = %s (%s), want pass", c.name, c.status, c.detail)
+			}
+		}
+		if score := backupReadinessScore(checks); score != 100 {
+			t.Errorf("score = %d, want 100", score)
+		}
+	})
+
+	t.Run("placeholder archive_command", func(t *testing.T) {
+		state := ready()
+		state.archiveCommand = "/bin/true"
+		c := findBackupCheck(t, evaluateBackupReadiness(state, now), "archive_command")
+		if c.status != backupCheckFail {
+			t.Errorf("archive_command = %s, want fail", c.status)
+		}
+	})
+
+	t.Run("archive_library replaces archive_command", func(t *testing.T) {
+		state := ready()
+		state.archiveCommand = ""
+		state.archiveLibrary = "basic_archive"
+		c := findBackupCheck(t, evaluateBackupReadiness(state, now), "archive_command")
+		if c.status != backupCheckPass {
+			t.Errorf("archive_command = %s, want pass", c.status)
+		}
+	})
+
+	t.Run("archiving disabled", func(t *testing.T) {
+		state := ready()
+		state.archiveMode = "off"
+		state.walLevel = "minimal"
+		checks := evaluateBackupReadiness(state, now)
+		for _, name := range []string{"archive_mode", "wal_level", "wal_archiving"} {
+			if c := findBackupCheck(t, checks, name); c.status != backupCheckFail {
+				t.Errorf("%s = %s, want fail", name, c.status)
+			}
+		}
+	})
+
+	t.Run("last archive attempt failed", func(t *testing.T) {
+		state := ready()
+		failed := now.Add(-time.Minute)
+		state.failedCount = 3
+		state.lastFailedTime = &failed
+		c := findBackupCheck(t, evaluateBackupReadiness(state, now), "wal_archiving")
+		if c.status != backupCheckFail {
+			t.Errorf("wal_archiving = %s, want fail", c.status)
+		}
+	})
+
+	t.Run("stale archive", func(t *testing.T) {
+		state := ready()
+		state.lastArchivedTime = &old
+		c := findBackupCheck(t, evaluateBackupReadiness(state, now), "wal_archiving")
+		if c.status != backupCheckWarn {
+			t.Errorf("wal_archiving = %s, want warn", c.status)
+		}
+	})
+
+	t.Run("inactive replication slot", func(t *testing.T) {
+		state := ready()
+		retained := int64(2 * 1024 * 1024 * 1024)
+		state.slots = []replicationSlotState{
+			{name: "standby1", slotType: "physical", active: true},
+			{name: "old_standby", slotType: "physical", active: false, retainedBytes: &retained},
+		}
+		c := findBackupCheck(t, evaluateBackupReadiness(state, now), "replication_slots")
+		if c.status != backupCheckWarn {
+			t.Errorf("replication_slots = %s, want warn", c.status)
+		}
+	})
+}
+
+func TestBackupReadinessScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		expected int
+	}{
+		{"no checks", nil, 0},
+		{"all pass", []string{backupCheckPass, backupCheckPass}, 100},
+		{"all fail", []string{backupCheckFail, backupCheckFail}, 0},
+		{"warn counts half", []string{backupCheckPass, backupCheckWarn}, 75},
+		{"mixed", []string{backupCheckPass, backupCheckWarn, backupCheckFail, backupCheckFail}, 37},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := make([]backupCheck, len(tt.statuses))
+			for i, s := range tt.statuses {
+				checks[i] = backupCheck{status: s}
+			}
+			if got := backupReadinessScore(checks); got != tt.expected {
+				t.Errorf("backupReadinessScore() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// largeVectorTableRows is the estimated row count above which a missing
+// vector index is reported as a warning rather than informational
+const largeVectorTableRows = 10000
+
+// vectorColumnIndexes holds the ANN index coverage for a single vector column
+type vectorColumnIndexes struct {
+	Schema    string
+	Table     string
+	Column    string
+	TypeName  string
+	EstRows   int64
+	Indexes   []string        // "index_name (method, metric)"
+	Metrics   map[string]bool // metrics covered by an HNSW/IVFFlat index
+	HasANNIdx bool
+}
+
+// CheckVectorIndexesTool creates the check_vector_indexes tool
+func CheckVectorIndexesTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "check_vector_indexes",
+			Description: `Check whether vector columns have an HNSW or IVFFlat index matching the distance metric.
+
+<usecase>
+Use before similarity_search on a large table, or when vector search is slow.
+Without a matching ANN index, every similarity search is a sequential scan
+that computes the distance to every row.
+</usecase>
+
+<what_it_returns>
+For each vector/halfvec/sparsevec column:
+- Estimated row count
+- Existing HNSW/IVFFlat indexes and the metric each opclass supports
+- Status: ok, missing (no ANN index), or metric_mismatch (index exists but
+  not for the requested metric)
+- CREATE INDEX statements to close any gaps
+</what_it_returns>
+
+<important>
+- The index opclass must match the query operator: vector_cosine_ops for
+  cosine (<=>), vector_l2_ops for l2 (<->), vector_ip_ops for inner product (<#>)
+- Run suggested CREATE INDEX CONCURRENTLY statements outside this server;
+  it only has read-only access
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"schema_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Only check tables in this schema (default: all user schemas)",
+					},
+					"distance_metric": map[string]interface{}{
+						"type":        "string",
+						"description": "Distance metric the index must support: 'cosine', 'l2', 'inner_product' or 'l1' (default: 'cosine')",
+						"default":     "cosine",
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			schemaName := ValidateOptionalStringParam(args, "schema_name", "")
+
+			metric := "cosine"
+			if val, ok := args["distance_metric"].(string); ok && val != "" {
+				metric = normalizeDistanceMetric(val)
+				if metric == "" {
+					return mcp.NewToolError(fmt.Sprintf("Unsupported distance_metric %q: use 'cosine', 'l2', 'inner_product' or 'l1'", val))
+				}
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+			columns := make(map[string]*vectorColumnIndexes)
+
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				rows, err := tx.Query(ctx, `
+					SELECT n.nspname, c.relname, a.attname, a.attnum, t.typname,
+					       GREATEST(c.reltuples, 0)::bigint,
+					       COALESCE(ic.relname, ''), COALESCE(am.amname, ''),
+					       COALESCE(i.indkey::int2[], '{}'),
+					       COALESCE(ARRAY(SELECT opc.opcname::text
+					                      FROM unnest(i.indclass::oid[]) WITH ORDINALITY AS k(opcoid, ord)
+					                      JOIN pg_opclass opc ON opc.oid = k.opcoid
+					                      ORDER BY k.ord), '{}')
+					FROM pg_attribute a
+					JOIN pg_class c ON c.oid = a.attrelid AND c.relkind IN ('r', 'p', 'm')
+					JOIN pg_namespace n ON n.oid = c.relnamespace
+					JOIN pg_type t ON t.oid = a.atttypid
+					LEFT JOIN pg_index i ON i.indrelid = c.oid AND a.attnum = ANY(i.indkey)
+					LEFT JOIN pg_class ic ON ic.oid = i.indexrelid
+					LEFT JOIN pg_am am ON am.oid = ic.relam
+					WHERE t.typname IN ('vector', 'halfvec', 'sparsevec')
+					  AND a.attnum > 0 AND NOT a.attisdropped
+					  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+					  AND ($1 = '' OR n.nspname = $1)
+					ORDER BY n.nspname, c.relname, a.attname, ic.relname`, schemaName)
+				if err != nil {
+					return fmt.Errorf("failed to query vector columns: %w", err)
+				}
+				defer rows.Close()
+
+				for rows.Next() {
+					var schema, table, column, typeName, indexName, method string
+					var attnum int16
+					var estRows int64
+					var indkey []int16
+					var opclasses []string
+					if err := rows.Scan(&schema, &table, &column, &attnum, &typeName, &estRows, &indexName, &method,
+						&indkey, &opclasses); err != nil {
+						return fmt.Errorf("failed to scan vector column: %w", err)
+					}
+					opclass := indexColumnOpclass(indkey, opclasses, attnum)
+
+					key := schema + "." + table + "." + column
+					col, ok := columns[key]
+					if !ok {
+						col = &vectorColumnIndexes{
+							Schema:   schema,
+							Table:    table,
+							Column:   column,
+							TypeName: typeName,
+							EstRows:  estRows,
+							Metrics:  make(map[string]bool),
+						}
+						columns[key] = col
+					}
+
+					if indexName == "" {
+						continue
+					}
+					opMetric := opclassMetric(opclass)
+					col.Indexes = append(col.Indexes, fmt.Sprintf("%s (%s, %s)", indexName, method, opMetricLabel(opMetric, opclass)))
+					if method == "hnsw" || method == "ivfflat" {
+						col.HasANNIdx = true
+						if opMetric != "" {
+							col.Metrics[opMetric] = true
+						}
+					}
+				}
+				return rows.Err()
+			})
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Error checking vector indexes: %v", err))
+			}
+
+			keys := make([]string, 0, len(columns))
+			for k := range columns {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+
+			if len(keys) == 0 {
+				sb.WriteString("No vector columns found")
+				if schemaName != "" {
+					sb.WriteString(fmt.Sprintf(" in schema '%s'", schemaName))
+				}
+				sb.WriteString(".\n")
+				return mcp.NewToolSuccess(sb.String())
+			}
+
+			sb.WriteString(fmt.Sprintf("Vector index coverage (metric: %s):\n", metric))
+			results := make([][]interface{}, 0, len(keys))
+			var warnings, fixes []string
+			for _, k := range keys {
+				col := columns[k]
+				status := vectorIndexStatus(col, metric)
+				indexes := strings.Join(col.Indexes, "; ")
+				results = append(results, []interface{}{col.Schema, col.Table, col.Column, col.TypeName, col.EstRows, indexes, status})
+
+				if status == "ok" {
+					continue
+				}
+				qualified := fmt.Sprintf("%s.%s", quoteIdentifier(col.Schema), quoteIdentifier(col.Table))
+				if col.EstRows >= largeVectorTableRows {
+					warnings = append(warnings, fmt.Sprintf("%s.%s (~%d rows): similarity search will use a sequential scan",
+						qualified, quoteIdentifier(col.Column), col.EstRows))
+				}
+				if opclass := vectorOpclassFor(col.TypeName, metric); opclass != "" {
+					fixes = append(fixes, fmt.Sprintf("CREATE INDEX CONCURRENTLY ON %s USING hnsw (%s %s);",
+						qualified, quoteIdentifier(col.Column), opclass))
+				}
+			}
+			sb.WriteString(FormatResultsAsTSV(
+				[]string{"schema", "table", "column", "type", "est_rows", "indexes", "status"},
+				results))
+			sb.WriteString("\n")
+
+			if len(warnings) > 0 {
+				sb.WriteString("\n<warnings>\n")
+				for _, w := range warnings {
+					sb.WriteString(fmt.Sprintf("⚠️  %s\n", w))
+				}
+				sb.WriteString("</warnings>\n")
+			}
+
+			if len(fixes) > 0 {
+				sb.WriteString("\n<recommendations>\n")
+				sb.WriteString("Create a matching ANN index (run as a user with write access):\n")
+				for _, f := range fixes {
+					sb.WriteString(f + "\n")
+				}
+				sb.WriteString("</recommendations>\n")
+			}
+
+			logging.Info("check_vector_indexes_executed",
+				"schema", schemaName,
+				"metric", metric,
+				"columns", len(keys),
+				"gaps", len(fixes),
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// normalizeDistanceMetric maps user-facing metric names to canonical names,
+// returning "" for unsupported metrics
+func normalizeDistanceMetric(metric string) string {
+	switch strings.ToLower(metric) {
+	case "cosine":
+		return "cosine"
+	case "l2", "euclidean":
+		return "l2"
+	case "inner_product", "inner", "ip":
+		return "inner_product"
+	case "l1", "manhattan", "taxicab":
+		return "l1"
+	default:
+		return ""
+	}
+}
+
+// indexColumnOpclass returns the opclass an index uses for a column, given
+// the index's key columns (pg_index.indkey) and its opclass names in the
+// same order (pg_index.indclass). INCLUDE columns have no opclass, so "" is
+// returned for them and for columns not in the index.
+func indexColumnOpclass(indkey []int16, opclasses []string, attnum int16) string {
+	for i, key := range indkey {
+		if key == attnum {
+			if i < len(opclasses) {
+				return opclasses[i]
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// opclassMetric returns the distance metric supported by a pgvector opclass,
+// or "" if the opclass isn't a recognized vector opclass
+func opclassMetric(opclass string) string {
+	switch {
+	case strings.HasSuffix(opclass, "_cosine_ops"):
+		return "cosine"
+	case strings.HasSuffix(opclass, "_l2_ops"):
+		return "l2"
+	case strings.HasSuffix(opclass, "_ip_ops"):
+		return "inner_product"
+	case strings.HasSuffix(opclass, "_l1_ops"):
+		return "l1"
+	default:
+		return ""
+	}
+}
+
+// opMetricLabel describes an index's metric for display, falling back to the opclass name
+func opMetricLabel(metric, opclass string) string {
+	if metric != "" {
+		return metric
+	}
+	return opclass
+}
+
+// vectorOpclassFor returns the pgvector opclass for a column type and metric
+func vectorOpclassFor(typeName, metric string) string {
+	suffix := map[string]string{
+		"cosine":        "_cosine_ops",
+		"l2":            "_l2_ops",
+		"inner_product": "_ip_ops",
+		"l1":            "_l1_ops",
+	}[metric]
+	if suffix == "" {
+		return ""
+	}
+	switch typeName {
+	case "vector", "halfvec", "sparsevec":
+		return typeName + suffix
+	default:
+		return ""
+	}
+}
+
+// vectorIndexStatus classifies a column's index coverage for a metric
+func vectorIndexStatus(col *vectorColumnIndexes, metric string) string {
+	switch {
+	case !col.HasANNIdx:
+		return "missing"
+	case !col.Metrics[metric]:
+		return "metric_mismatch"
+	default:
+		return "ok"
+	}
+}
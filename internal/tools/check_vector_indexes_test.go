@@ -0,0 +1,143 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Check Vector Indexes Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"testing"
+)
+
+func TestCheckVectorIndexesToolDefinition(t *testing.T) {
+	tool := CheckVectorIndexesTool(nil)
+
+	if tool.Definition.Name != "check_vector_indexes" {
+		t.Errorf("Tool name = %v, want check_vector_indexes", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"schema_name", "distance_metric"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+}
+
+func TestCheckVectorIndexesInvalidMetric(t *testing.T) {
+	tool := CheckVectorIndexesTool(nil)
+
+	response, err := tool.Handler(map[string]interface{}{"distance_metric": "hamming"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !response.IsError {
+		t.Error("Expected error response for unsupported metric")
+	}
+}
+
+func TestNormalizeDistanceMetric(t *testing.T) {
+	tests := map[string]string{
+		"cosine":        "cosine",
+		"COSINE":        "cosine",
+		"euclidean":     "l2",
+		"l2":            "l2",
+		"inner":         "inner_product",
+		"inner_product": "inner_product",
+		"l1":            "l1",
+		"manhattan":     "l1",
+		"hamming":       "",
+	}
+
+	for input, expected := range tests {
+		if got := normalizeDistanceMetric(input); got != expected {
+			t.Errorf("normalizeDistanceMetric(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestIndexColumnOpclass(t *testing.T) {
+	// An index on (id, embedding) INCLUDE (title)
+	indkey := []int16{1, 3, 2}
+	opclasses := []string{"int4_ops", "vector_cosine_ops"}
+
+	tests := map[int16]string{
+		1: "int4_ops",          // first column
+		3: "vector_cosine_ops", // later column
+		2: "",                  // INCLUDE column
+		4: "",                  // not in the index
+	}
+	for attnum, want := range tests {
+		if got := indexColumnOpclass(indkey, opclasses, attnum); got != want {
+			t.Errorf("indexColumnOpclass(attnum %d) = %q, want %q", attnum, got, want)
+		}
+	}
+
+	// A single-column HNSW index
+	if got := indexColumnOpclass([]int16{5}, []string{"vector_l2_ops"}, 5); got != "vector_l2_ops" {
+		t.Errorf("indexColumnOpclass() for the only column = %q, want vector_l2_ops", got)
+	}
+}
+
+func TestOpclassMetric(t *testing.T) {
+	tests := map[string]string{
+		"vector_cosine_ops":  "cosine",
+		"halfvec_l2_ops":     "l2",
+		"vector_ip_ops":      "inner_product",
+		"sparsevec_l1_ops":   "l1",
+		"bit_hamming_ops":    "",
+		"int4_ops":           "",
+		"vector_l2_ops_typo": "",
+	}
+
+	for input, expected := range tests {
+		if got := opclassMetric(input); got != expected {
+			t.Errorf("opclassMetric(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestVectorOpclassFor(t *testing.T) {
+	tests := []struct {
+		typeName string
+		metric   string
+		expected string
+	}{
+		{"vector", "cosine", "vector_cosine_ops"},
+		{"halfvec", "l2", "halfvec_l2_ops"},
+		{"sparsevec", "inner_product", "sparsevec_ip_ops"},
+		{"vector", "unknown", ""},
+		{"text", "cosine", ""},
+	}
+
+	for _, tt := range tests {
+		if got := vectorOpclassFor(tt.typeName, tt.metric); got != tt.expected {
+			t.Errorf("vectorOpclassFor(%q, %q) = %q, want %q", tt.typeName, tt.metric, got, tt.expected)
+		}
+	}
+}
+
+func TestVectorIndexStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		col      vectorColumnIndexes
+		expected string
+	}{
+		{"no index", vectorColumnIndexes{Metrics: map[string]bool{}}, "missing"},
+		{"btree only", vectorColumnIndexes{Indexes: []string{"idx (btree, )"}, Metrics: map[string]bool{}}, "missing"},
+		{"wrong metric", vectorColumnIndexes{HasANNIdx: true, Metrics: map[string]bool{"l2": true}}, "metric_mismatch"},
+		{"matching", vectorColumnIndexes{HasANNIdx: true, Metrics: map[string]bool{"cosine": true}}, "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vectorIndexStatus(&tt.col, "cosine"); got != tt.expected {
+				t.Errorf("vectorIndexStatus() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
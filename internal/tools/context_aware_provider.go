@@ -31,14 +31,17 @@ type ContextAwareProvider struct {
 	resourceReg       *resources.ContextAwareRegistry
 	authEnabled       bool
 	fallbackClient    *database.Client            // Used when auth is disabled
-	cfg               *config.Config              // Server configuration (for embedding settings)
+	cfg               *config.Config              // Server configuration (for embedding settings); replaced on reload
 	userStore         *auth.UserStore             // User store for authentication
 	userFilePath      string                      // Path to user file for persisting updates
 	rateLimiter       *auth.RateLimiter           // Rate limiter for authentication attempts
 	maxFailedAttempts int                         // Maximum failed attempts before account lockout
 	accessChecker     *auth.DatabaseAccessChecker // Database access control checker
+	tokenStore        *auth.TokenStore            // Token store (for the token_usage tool)
+	usageStore        *auth.UsageStore            // Per-token tool-call usage (nil = not recorded)
 
 	// Cache of registries per client to avoid re-creating tools on every Execute()
+	// mu also guards cfg and baseRegistry, which UpdateEmbeddingConfig replaces
 	mu               sync.RWMutex
 	clientRegistries map[*database.Client]*Registry
 
@@ -63,6 +66,11 @@ func (p *ContextAwareProvider) registerStatelessTools(registry *Registry) {
 		p.cfg.Builtins.Tools.IsToolEnabled("search_knowledgebase") {
 		registry.Register("search_knowledgebase", SearchKnowledgebaseTool(p.cfg.Knowledgebase.DatabasePath, p.cfg))
 	}
+
+	// Connection test tool (opens its own connection, so needs no client)
+	if p.cfg.Builtins.Tools.IsToolEnabled("test_connection") {
+		registry.Register("test_connection", TestConnectionTool(p.clientManager, p.accessChecker))
+	}
 }
 
 // registerDatabaseTools registers all database-dependent tools
@@ -70,6 +78,9 @@ func (p *ContextAwareProvider) registerDatabaseTools(registry *Registry, client
 	if p.cfg.Builtins.Tools.IsToolEnabled("query_database") {
 		registry.Register("query_database", QueryDatabaseTool(client))
 	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("execute_sql") {
+		registry.Register("execute_sql", ExecuteSQLTool(client))
+	}
 	if p.cfg.Builtins.Tools.IsToolEnabled("get_schema_info") {
 		registry.Register("get_schema_info", GetSchemaInfoTool(client))
 	}
@@ -77,11 +88,74 @@ func (p *ContextAwareProvider) registerDatabaseTools(registry *Registry, client
 		registry.Register("similarity_search", SimilaritySearchTool(client, p.cfg))
 	}
 	if p.cfg.Builtins.Tools.IsToolEnabled("execute_explain") {
-		registry.Register("execute_explain", ExecuteExplainTool(client))
+		registry.Register("execute_explain", ExecuteExplainTool(client, p.cfg))
 	}
 	if p.cfg.Builtins.Tools.IsToolEnabled("count_rows") {
 		registry.Register("count_rows", CountRowsTool(client))
 	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("temp_file_usage") {
+		registry.Register("temp_file_usage", TempFileUsageTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("check_vector_indexes") {
+		registry.Register("check_vector_indexes", CheckVectorIndexesTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("lock_wait_graph") {
+		registry.Register("lock_wait_graph", LockWaitGraphTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("index_efficiency") {
+		registry.Register("index_efficiency", IndexEfficiencyTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("find_invalid_indexes") {
+		registry.Register("find_invalid_indexes", FindInvalidIndexesTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("get_table_sample") {
+		registry.Register("get_table_sample", GetTableSampleTool(client, p.cfg))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("backup_readiness") {
+		registry.Register("backup_readiness", BackupReadinessTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("describe_schema") {
+		registry.Register("describe_schema", DescribeSchemaTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("describe_table") {
+		registry.Register("describe_table", DescribeTableTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("relation_layout") {
+		registry.Register("relation_layout", RelationLayoutTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("partitioning_advisor") {
+		registry.Register("partitioning_advisor", PartitioningAdvisorTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("find_large_values") {
+		registry.Register("find_large_values", FindLargeValuesTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("long_running_queries") {
+		registry.Register("long_running_queries", LongRunningQueriesTool(client, p.cfg))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("rowcount_accuracy") {
+		registry.Register("rowcount_accuracy", RowcountAccuracyTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("suggest_indexes") {
+		registry.Register("suggest_indexes", SuggestIndexesTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("refresh_metadata") {
+		registry.Register("refresh_metadata", RefreshMetadataTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("table_maintenance") {
+		registry.Register("table_maintenance", TableMaintenanceTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("show_current_connection") {
+		registry.Register("show_current_connection", ShowCurrentConnectionTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("export_schema") {
+		registry.Register("export_schema", ExportSchemaTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("list_databases") {
+		registry.Register("list_databases", ListDatabasesTool(client))
+	}
+	if p.cfg.Builtins.Tools.IsToolEnabled("list_roles") {
+		registry.Register("list_roles", ListRolesTool(client))
+	}
 }
 
 // NewContextAwareProvider creates a new context-aware tool provider
@@ -116,6 +190,61 @@ func NewContextAwareProvider(clientManager *database.ClientManager, resourceReg
 	return provider
 }
 
+// SetUsageStore enables per-token recording of tool calls and registers
+// the token_usage tool when it is enabled in the configuration
+func (p *ContextAwareProvider) SetUsageStore(tokenStore *auth.TokenStore, usageStore *auth.UsageStore) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.tokenStore = tokenStore
+	p.usageStore = usageStore
+	p.registerTokenUsageTool(p.baseRegistry)
+}
+
+// registerTokenUsageTool registers token_usage if usage is recorded and the
+// tool is enabled
+func (p *ContextAwareProvider) registerTokenUsageTool(registry *Registry) {
+	if p.usageStore != nil && p.cfg.Builtins.Tools.IsToolEnabled("token_usage") {
+		registry.Register("token_usage", TokenUsageTool(p.tokenStore, p.usageStore))
+	}
+}
+
+// UpdateEmbeddingConfig applies reloaded embedding settings. Tools keep the
+// configuration they were created with, so the provider switches to a copy
+// with the new settings and recreates its tools; calls already running
+// finish with the old settings.
+func (p *ContextAwareProvider) UpdateEmbeddingConfig(embeddingCfg config.EmbeddingConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cfg := *p.cfg
+	cfg.Embedding = embeddingCfg
+	p.cfg = &cfg
+
+	baseRegistry := NewRegistry()
+	p.registerStatelessTools(baseRegistry)
+	p.registerDatabaseTools(baseRegistry, nil)
+	p.registerTokenUsageTool(baseRegistry)
+	p.baseRegistry = baseRegistry
+	p.clientRegistries = make(map[*database.Client]*Registry)
+}
+
+// current returns the configuration and base registry in use
+func (p *ContextAwareProvider) current() (*config.Config, *Registry) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.cfg, p.baseRegistry
+}
+
+// recordUsage records a tool call against the calling token, if usage is
+// being recorded
+func (p *ContextAwareProvider) recordUsage(ctx context.Context, name string, denied bool) {
+	if p.usageStore == nil {
+		return
+	}
+	p.usageStore.RecordCall(auth.GetTokenHashFromContext(ctx), name, denied)
+}
+
 // resourceReaderAdapter adapts ContextAwareRegistry to the ResourceReader interface
 // This provides backward compatibility for the read_resource tool
 type resourceReaderAdapter struct {
@@ -141,7 +270,8 @@ func (p *ContextAwareProvider) createResourceAdapter() ResourceReader {
 
 // GetBaseRegistry returns the base registry for adding additional tools
 func (p *ContextAwareProvider) GetBaseRegistry() *Registry {
-	return p.baseRegistry
+	_, baseRegistry := p.current()
+	return baseRegistry
 }
 
 // RegisterTools initializes tool registrations
@@ -158,7 +288,8 @@ func (p *ContextAwareProvider) RegisterTools(ctx context.Context) error {
 // List returns all registered tool definitions
 // Hidden tools (like authenticate_user) are not included as they're in a separate registry
 func (p *ContextAwareProvider) List() []mcp.Tool {
-	return p.baseRegistry.List()
+	_, baseRegistry := p.current()
+	return baseRegistry.List()
 }
 
 // getOrCreateRegistryForClient returns a cached registry for the given client
@@ -166,7 +297,8 @@ func (p *ContextAwareProvider) List() []mcp.Tool {
 func (p *ContextAwareProvider) getOrCreateRegistryForClient(client *database.Client) *Registry {
 	if client == nil {
 		// No client available - return base registry only
-		return p.baseRegistry
+		_, baseRegistry := p.current()
+		return baseRegistry
 	}
 
 	// Fast path: check if registry already exists (read lock)
@@ -219,9 +351,12 @@ func (p *ContextAwareProvider) Execute(ctx context.Context, name string, args ma
 		}
 	}
 
+	cfg, baseRegistry := p.current()
+
 	// Check if this tool is enabled in the builtins configuration
 	// read_resource is always enabled as it's used to list resources
-	if name != "read_resource" && !p.cfg.Builtins.Tools.IsToolEnabled(name) {
+	if name != "read_resource" && !cfg.Builtins.Tools.IsToolEnabled(name) {
+		p.recordUsage(ctx, name, true)
 		return mcp.ToolResponse{
 			Content: []mcp.ContentItem{
 				{
@@ -241,15 +376,27 @@ func (p *ContextAwareProvider) Execute(ctx context.Context, name string, args ma
 		}
 	}
 
+	// Check the token's scopes before running anything
+	if p.accessChecker != nil {
+		if scope := requiredScope(name, args); !p.accessChecker.HasScope(ctx, scope) {
+			p.recordUsage(ctx, name, true)
+			return permissionDenied(name, scope), nil
+		}
+	}
+
 	// Check if this is a stateless tool that doesn't require a database client
 	statelessTools := map[string]bool{
 		"read_resource":      true, // Resource access tool
 		"generate_embedding": true, // Embedding generation doesn't need database
+		"token_usage":        true, // Reads the token and usage stores
+		"test_connection":    true, // Opens its own short-lived connection
 	}
 
 	if statelessTools[name] {
+		p.recordUsage(ctx, name, false)
 		// Execute from base registry (no database client needed)
-		return p.baseRegistry.Execute(ctx, name, args)
+		response, err := baseRegistry.Execute(ctx, name, args)
+		return sanitizeToolError(cfg.ErrorSanitization.Mode, name, response), err
 	}
 
 	// Get the appropriate database client for this request
@@ -257,7 +404,8 @@ func (p *ContextAwareProvider) Execute(ctx context.Context, name string, args ma
 	if err != nil {
 		// Log the error for debugging
 		fmt.Fprintf(os.Stderr, "ERROR: Failed to get database client for tool '%s': %v\n", name, err)
-		return mcp.ToolResponse{
+		p.recordUsage(ctx, name, true)
+		return sanitizeToolError(cfg.ErrorSanitization.Mode, name, mcp.ToolResponse{
 			Content: []mcp.ContentItem{
 				{
 					Type: "text",
@@ -265,15 +413,18 @@ func (p *ContextAwareProvider) Execute(ctx context.Context, name string, args ma
 				},
 			},
 			IsError: true,
-		}, nil // Don't return error, just error response
+		}), nil // Don't return error, just error response
 	}
 
 	// Get the cached registry for this client (or create if first use)
 	// This avoids re-creating all tools on every request
 	registry := p.getOrCreateRegistryForClient(dbClient)
+	p.recordUsage(ctx, name, false)
 
-	// Execute the tool using the client-specific registry
-	return registry.Execute(ctx, name, args)
+	// Execute the tool using the client-specific registry, sanitizing any
+	// error text before it reaches the client
+	response, err := registry.Execute(ctx, name, args)
+	return sanitizeToolError(cfg.ErrorSanitization.Mode, name, response), err
 }
 
 // getClient returns the appropriate database client based on authentication state
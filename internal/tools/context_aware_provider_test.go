@@ -12,6 +12,8 @@ package tools
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
 
@@ -68,7 +70,7 @@ func TestContextAwareProvider_List(t *testing.T) {
 		// List tools - should return all tools
 		tools := provider.List()
 
-		// Should have all 7 tools (no filtering)
+		// Should have all tools (no filtering)
 		expectedTools := []string{
 			"read_resource",
 			"generate_embedding",
@@ -77,6 +79,28 @@ func TestContextAwareProvider_List(t *testing.T) {
 			"similarity_search",
 			"execute_explain",
 			"count_rows",
+			"temp_file_usage",
+			"check_vector_indexes",
+			"lock_wait_graph",
+			"index_efficiency",
+			"find_invalid_indexes",
+			"get_table_sample",
+			"backup_readiness",
+			"describe_schema",
+			"relation_layout",
+			"partitioning_advisor",
+			"find_large_values",
+			"long_running_queries",
+			"rowcount_accuracy",
+			"suggest_indexes",
+			"refresh_metadata",
+			"table_maintenance",
+			"describe_table",
+			"test_connection",
+			"show_current_connection",
+			"export_schema",
+			"list_databases",
+			"list_roles",
 		}
 
 		if len(tools) != len(expectedTools) {
@@ -97,6 +121,63 @@ func TestContextAwareProvider_List(t *testing.T) {
 	})
 }
 
+// TestContextAwareProvider_ExecuteSQLOptIn tests that execute_sql is only
+// listed when raw SQL is enabled
+func TestContextAwareProvider_ExecuteSQLOptIn(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		clientManager := database.NewClientManagerWithConfig(nil)
+		cfg := &config.Config{}
+		cfg.Builtins.Tools.RawSQLEnabled = &enabled
+		resourceReg := resources.NewContextAwareRegistry(clientManager, false, nil, cfg)
+		provider := NewContextAwareProvider(clientManager, resourceReg, false, database.NewClient(nil), cfg, nil, "", nil, 0, nil)
+		if err := provider.RegisterTools(context.TODO()); err != nil {
+			t.Fatalf("RegisterTools failed: %v", err)
+		}
+
+		listed := false
+		for _, tool := range provider.List() {
+			if tool.Name == "execute_sql" {
+				listed = true
+			}
+		}
+		if listed != enabled {
+			t.Errorf("raw_sql_enabled=%t: execute_sql listed = %t", enabled, listed)
+		}
+		clientManager.CloseAll()
+	}
+}
+
+// TestContextAwareProvider_ToolLists tests that tools removed by the
+// enabled/disabled lists are neither listed nor callable, even with
+// arguments that don't match their schema
+func TestContextAwareProvider_ToolLists(t *testing.T) {
+	clientManager := database.NewClientManagerWithConfig(nil)
+	defer clientManager.CloseAll()
+
+	cfg := &config.Config{}
+	cfg.Builtins.Tools.Enabled = []string{"query_database", "get_schema_info", "count_rows"}
+	cfg.Builtins.Tools.Disabled = []string{"count_rows"}
+	resourceReg := resources.NewContextAwareRegistry(clientManager, false, nil, cfg)
+	provider := NewContextAwareProvider(clientManager, resourceReg, false, database.NewClient(nil), cfg, nil, "", nil, 0, nil)
+
+	var names []string
+	for _, tool := range provider.List() {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	if want := []string{"get_schema_info", "query_database", "read_resource"}; !slices.Equal(names, want) {
+		t.Errorf("Listed tools = %v, want %v", names, want)
+	}
+
+	response, err := provider.Execute(context.Background(), "count_rows", map[string]interface{}{"table_name": "orders"})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !response.IsError || !strings.Contains(response.Content[0].Text, "Tool 'count_rows' is not available") {
+		t.Errorf("Expected not available error, got %+v", response)
+	}
+}
+
 // TestContextAwareProvider_Execute_NoAuth tests execution without authentication
 func TestContextAwareProvider_Execute_NoAuth(t *testing.T) {
 	// This test doesn't require database connection, testing read_resource tool
@@ -282,3 +363,153 @@ func TestContextAwareProvider_RegisterTools_WithContext(t *testing.T) {
 		t.Error("Expected tools to be registered")
 	}
 }
+
+// TestContextAwareProvider_UsageRecording tests per-token tool-call recording
+func TestContextAwareProvider_UsageRecording(t *testing.T) {
+	clientManager := database.NewClientManagerWithConfig(nil)
+	defer clientManager.CloseAll()
+
+	fallbackClient := database.NewClient(nil)
+	enabled := true
+	disabled := false
+	cfg := &config.Config{}
+	cfg.Builtins.Tools.TokenUsage = &enabled
+	cfg.Builtins.Tools.CountRows = &disabled
+	resourceReg := resources.NewContextAwareRegistry(clientManager, true, nil, cfg)
+
+	provider := NewContextAwareProvider(clientManager, resourceReg, true, fallbackClient, cfg, nil, "", nil, 0, nil)
+
+	tokenStore := auth.InitializeTokenStore()
+	if err := tokenStore.AddToken("token-1", "token-hash-1", "reader", nil, "", nil); err != nil {
+		t.Fatalf("AddToken failed: %v", err)
+	}
+	usageStore, err := auth.LoadUsageStore("")
+	if err != nil {
+		t.Fatalf("LoadUsageStore failed: %v", err)
+	}
+	provider.SetUsageStore(tokenStore, usageStore)
+
+	ctx := context.WithValue(context.Background(), auth.TokenHashContextKey, "token-hash-1")
+
+	if _, err := provider.Execute(ctx, "read_resource", map[string]interface{}{"uri": "test://test"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := provider.Execute(ctx, "count_rows", map[string]interface{}{"table": "t"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	usage := usageStore.Get("token-hash-1")
+	if usage == nil {
+		t.Fatal("Expected usage to be recorded")
+	}
+	if usage.ToolCalls["read_resource"] != 1 || usage.ToolCalls["count_rows"] != 1 {
+		t.Errorf("ToolCalls = %v", usage.ToolCalls)
+	}
+	if usage.Denied != 1 {
+		t.Errorf("Denied = %d, want 1 (disabled count_rows)", usage.Denied)
+	}
+
+	// token_usage is registered and reports the calls
+	response, err := provider.Execute(ctx, "token_usage", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Execute token_usage failed: %v", err)
+	}
+	if response.IsError || !strings.Contains(response.Content[0].Text, "token-1\treader\t") {
+		t.Errorf("Unexpected token_usage output: %+v", response)
+	}
+}
+
+// TestContextAwareProvider_UpdateEmbeddingConfig tests that reloaded
+// embedding settings reach the tools
+func TestContextAwareProvider_UpdateEmbeddingConfig(t *testing.T) {
+	clientManager := database.NewClientManagerWithConfig(nil)
+	defer clientManager.CloseAll()
+
+	cfg := &config.Config{}
+	resourceReg := resources.NewContextAwareRegistry(clientManager, false, nil, cfg)
+	provider := NewContextAwareProvider(clientManager, resourceReg, false, nil, cfg, nil, "", nil, 0, nil)
+
+	args := map[string]interface{}{"text": "hello"}
+	response, err := provider.Execute(context.Background(), "generate_embedding", args)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !response.IsError || !strings.Contains(response.Content[0].Text, "not enabled") {
+		t.Fatalf("Expected embedding disabled error, got: %+v", response)
+	}
+
+	provider.UpdateEmbeddingConfig(config.EmbeddingConfig{Enabled: true, Provider: "unknown"})
+
+	response, err = provider.Execute(context.Background(), "generate_embedding", args)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !response.IsError || !strings.Contains(response.Content[0].Text, "unsupported embedding provider: unknown") {
+		t.Errorf("Expected the new provider to be used, got: %+v", response)
+	}
+	if cfg.Embedding.Enabled {
+		t.Error("UpdateEmbeddingConfig modified the original configuration")
+	}
+	if len(provider.List()) == 0 {
+		t.Error("Expected tools to be registered after the update")
+	}
+}
+
+// TestContextAwareProvider_TokenScopes tests that token scopes gate tool calls
+func TestContextAwareProvider_TokenScopes(t *testing.T) {
+	clientManager := database.NewClientManagerWithConfig(nil)
+	defer clientManager.CloseAll()
+
+	enabled := true
+	cfg := &config.Config{}
+	cfg.Builtins.Tools.TokenUsage = &enabled
+	resourceReg := resources.NewContextAwareRegistry(clientManager, true, nil, cfg)
+
+	tokenStore := auth.InitializeTokenStore()
+	if err := tokenStore.AddToken("token-1", "token-hash-1", "analyst", nil, "", []string{auth.ScopeRead}); err != nil {
+		t.Fatalf("AddToken failed: %v", err)
+	}
+	accessChecker := auth.NewDatabaseAccessChecker(tokenStore, true, false)
+	provider := NewContextAwareProvider(clientManager, resourceReg, true, nil, cfg, nil, "", nil, 0, accessChecker)
+	usageStore, err := auth.LoadUsageStore("")
+	if err != nil {
+		t.Fatalf("LoadUsageStore failed: %v", err)
+	}
+	provider.SetUsageStore(tokenStore, usageStore)
+
+	ctx := context.WithValue(context.Background(), auth.TokenHashContextKey, "token-hash-1")
+	ctx = context.WithValue(ctx, auth.IsAPITokenContextKey, true)
+
+	denied := []struct {
+		tool  string
+		args  map[string]interface{}
+		scope string
+	}{
+		{"long_running_queries", map[string]interface{}{"action": "terminate", "pid": 42.0, "confirm": true}, "tune"},
+		{"long_running_queries", map[string]interface{}{"action": "terminate", "pid": "42"}, "tune"}, // Invalid pid
+		{"token_usage", map[string]interface{}{}, "admin"},
+	}
+	for _, tt := range denied {
+		response, err := provider.Execute(ctx, tt.tool, tt.args)
+		if err != nil {
+			t.Fatalf("Execute %s failed: %v", tt.tool, err)
+		}
+		want := fmt.Sprintf("Permission denied: '%s' requires the '%s' scope", tt.tool, tt.scope)
+		if !response.IsError || !strings.Contains(response.Content[0].Text, want) {
+			t.Errorf("%s: expected %q, got %+v", tt.tool, want, response)
+		}
+	}
+
+	// Reading is allowed; the call gets past the scope check
+	response, err := provider.Execute(ctx, "read_resource", map[string]interface{}{"uri": "test://test"})
+	if err != nil {
+		t.Fatalf("Execute read_resource failed: %v", err)
+	}
+	if len(response.Content) > 0 && strings.Contains(response.Content[0].Text, "Permission denied") {
+		t.Errorf("read_resource denied for a read token: %+v", response)
+	}
+
+	if usage := usageStore.Get("token-hash-1"); usage == nil || usage.Denied != 3 {
+		t.Errorf("Expected 3 denied calls, got %+v", usage)
+	}
+}
@@ -11,7 +11,6 @@
 package tools
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
@@ -111,7 +110,7 @@ Use count_rows to efficiently determine data volume:
 			}
 
 			// Execute in a read-only transaction
-			ctx := context.Background()
+			ctx := requestContext(args)
 			tx, err := pool.Begin(ctx)
 			if err != nil {
 				return mcp.NewToolError(fmt.Sprintf("Failed to begin transaction: %v", err))
@@ -134,6 +133,10 @@ Use count_rows to efficiently determine data volume:
 				return mcp.NewToolError(fmt.Sprintf("Failed to set transaction read-only: %v", err))
 			}
 
+			if err := checkReplica(ctx, dbClient, tx); err != nil {
+				return mcp.NewToolError(err.Error())
+			}
+
 			var count int64
 			err = tx.QueryRow(ctx, sqlQuery).Scan(&count)
 			if err != nil {
@@ -0,0 +1,45 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"encoding/csv"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/tsv"
+)
+
+// FormatResultsAsCSV converts query results to RFC 4180 CSV with a header
+// row. NULLs become empty fields; values containing commas, quotes or
+// newlines are quoted. Like FormatResultsAsTSV, there is no trailing newline.
+func FormatResultsAsCSV(columnNames []string, results [][]interface{}) string {
+	if len(columnNames) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+
+	_ = w.Write(columnNames) //nolint:errcheck // strings.Builder never errors
+	record := make([]string, len(columnNames))
+	for _, row := range results {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) {
+				record[i] = tsv.StringValue(row[i])
+			}
+		}
+		_ = w.Write(record) //nolint:errcheck // strings.Builder never errors
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
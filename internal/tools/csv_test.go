@@ -0,0 +1,61 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - CSV Formatting Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"testing"
+)
+
+func TestFormatResultsAsCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		columns  []string
+		results  [][]interface{}
+		expected string
+	}{
+		{
+			name:     "no columns",
+			columns:  nil,
+			expected: "",
+		},
+		{
+			name:     "header only",
+			columns:  []string{"id", "name"},
+			expected: "id,name",
+		},
+		{
+			name:     "simple values",
+			columns:  []string{"id", "name", "active"},
+			results:  [][]interface{}{{int64(1), "alice", true}},
+			expected: "id,name,active\n1,alice,true",
+		},
+		{
+			name:     "NULL is an empty field",
+			columns:  []string{"id", "email"},
+			results:  [][]interface{}{{int64(2), nil}},
+			expected: "id,email\n2,",
+		},
+		{
+			name:     "commas, quotes and newlines are quoted",
+			columns:  []string{"note"},
+			results:  [][]interface{}{{"a,b"}, {`say "hi"`}, {"line1\nline2"}},
+			expected: "note\n\"a,b\"\n\"say \"\"hi\"\"\"\n\"line1\nline2\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatResultsAsCSV(tt.columns, tt.results); got != tt.expected {
+				t.Errorf("FormatResultsAsCSV() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// defaultDescribeSchemaObjects is the number of objects listed per
+	// section when no max_objects is given
+	defaultDescribeSchemaObjects = 100
+
+	// maxDescribeSchemaObjects caps max_objects
+	maxDescribeSchemaObjects = 500
+
+	// summaryColumnsPerTable is how many columns the brief column summary
+	// lists before collapsing the rest into a count
+	summaryColumnsPerTable = 12
+)
+
+// schemaRoutine is a function, procedure or aggregate in the described schema
+type schemaRoutine struct {
+	name      string
+	kind      string
+	arguments string
+	returns   string
+	language  string
+}
+
+// schemaSequence is a sequence in the described schema
+type schemaSequence struct {
+	name     string
+	dataType string
+	ownedBy  string
+}
+
+// DescribeSchemaTool creates the describe_schema tool
+func DescribeSchemaTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "describe_schema",
+			Description: `Describe everything in one schema in a single call: tables and views with brief column summaries, functions, sequences and object counts.
+
+<usecase>
+Use when:
+- Starting work in a schema and wanting a complete, focused overview
+- Finding which functions or sequences exist alongside the tables
+- You would otherwise call get_schema_info many times for one schema
+</usecase>
+
+<what_it_returns>
+- Object counts (tables, views, materialized views, functions, procedures,
+  aggregates, sequences)
+- Tables and views: type, a brief column summary (name, type, PK marker) and
+  description
+- Functions/procedures/aggregates: kind, arguments, return type, language
+- Sequences: data type and the column that owns them, if any
+</what_it_returns>
+
+<important>
+- Each section lists at most max_objects entries (default 100, maximum 500);
+  counts always cover the whole schema
+- Column summaries are brief; use get_schema_info(schema_name=..., table_name=...)
+  for full column details (nullability, defaults, foreign keys)
+- Functions installed by extensions (e.g. pgvector) are counted separately
+  and not listed
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"schema_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Schema to describe",
+					},
+					"max_objects": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum entries listed per section (default: 100, maximum: 500)",
+						"default":     defaultDescribeSchemaObjects,
+						"minimum":     1,
+						"maximum":     maxDescribeSchemaObjects,
+					},
+				},
+				Required: []string{"schema_name"},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			schemaName, _ := args["schema_name"].(string)
+
+			maxObjects := defaultDescribeSchemaObjects
+			if val, ok := args["max_objects"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'max_objects' must be a positive integer")
+				}
+				maxObjects = int(val)
+			}
+			if maxObjects > maxDescribeSchemaObjects {
+				maxObjects = maxDescribeSchemaObjects
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+
+			var exists bool
+			var routines []schemaRoutine
+			var sequences []schemaSequence
+			var extensionRoutines int
+
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				if err := tx.QueryRow(ctx,
+					"SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1)", schemaName).Scan(&exists); err != nil {
+					return fmt.Errorf("failed to check schema: %w", err)
+				}
+				if !exists {
+					return nil
+				}
+
+				var err error
+				routines, extensionRoutines, err = readSchemaRoutines(ctx, tx, schemaName)
+				if err != nil {
+					return err
+				}
+				sequences, err = readSchemaSequences(ctx, tx, schemaName)
+				return err
+			})
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Error describing schema '%s': %v", schemaName, err))
+			}
+
+			if !exists {
+				var errMsg strings.Builder
+				errMsg.WriteString(fmt.Sprintf("Schema '%s' not found.\n\n", schemaName))
+				errMsg.WriteString("<available_schemas>\n")
+				for _, name := range metadataSchemaNames(dbClient.GetMetadata()) {
+					errMsg.WriteString(name + "\n")
+				}
+				errMsg.WriteString("</available_schemas>\n")
+				return mcp.NewToolError(errMsg.String())
+			}
+
+			relations := schemaRelations(dbClient.GetMetadata(), schemaName)
+
+			counts := make(map[string]int)
+			for _, rel := range relations {
+				counts[rel.TableType]++
+			}
+			for _, r := range routines {
+				counts[r.kind]++
+			}
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(fmt.Sprintf("Schema: %s\n", schemaName))
+			sb.WriteString(fmt.Sprintf("Objects: %d tables, %d views, %d materialized views, %d functions, %d procedures, %d aggregates, %d sequences\n",
+				counts["TABLE"], counts["VIEW"], counts["MATERIALIZED VIEW"],
+				counts["function"], counts["procedure"], counts["aggregate"], len(sequences)))
+			if extensionRoutines > 0 {
+				sb.WriteString(fmt.Sprintf("(%d extension-owned functions not listed)\n", extensionRoutines))
+			}
+
+			// Tables and views
+			sb.WriteString("\n<tables_and_views>\n")
+			if len(relations) == 0 {
+				sb.WriteString("None\n")
+			} else {
+				shown := relations
+				if len(shown) > maxObjects {
+					shown = shown[:maxObjects]
+				}
+				rows := make([][]interface{}, len(shown))
+				for i, rel := range shown {
+					rows[i] = []interface{}{rel.TableName, rel.TableType, summarizeColumns(rel.Columns), rel.Description}
+				}
+				sb.WriteString(FormatResultsAsTSV([]string{"name", "type", "columns", "description"}, rows))
+				sb.WriteString("\n")
+				writeOmitted(&sb, len(relations), len(shown))
+			}
+			sb.WriteString("</tables_and_views>\n")
+
+			// Functions, procedures and aggregates
+			sb.WriteString("\n<functions>\n")
+			if len(routines) == 0 {
+				sb.WriteString("None\n")
+			} else {
+				shown := routines
+				if len(shown) > maxObjects {
+					shown = shown[:maxObjects]
+				}
+				rows := make([][]interface{}, len(shown))
+				for i, r := range shown {
+					rows[i] = []interface{}{r.name, r.kind, r.arguments, r.returns, r.language}
+				}
+				sb.WriteString(FormatResultsAsTSV([]string{"name", "kind", "arguments", "returns", "language"}, rows))
+				sb.WriteString("\n")
+				writeOmitted(&sb, len(routines), len(shown))
+			}
+			sb.WriteString("</functions>\n")
+
+			// Sequences
+			sb.WriteString("\n<sequences>\n")
+			if len(sequences) == 0 {
+				sb.WriteString("None\n")
+			} else {
+				shown := sequences
+				if len(shown) > maxObjects {
+					shown = shown[:maxObjects]
+				}
+				rows := make([][]interface{}, len(shown))
+				for i, s := range shown {
+					rows[i] = []interface{}{s.name, s.dataType, s.ownedBy}
+				}
+				sb.WriteString(FormatResultsAsTSV([]string{"name", "data_type", "owned_by"}, rows))
+				sb.WriteString("\n")
+				writeOmitted(&sb, len(sequences), len(shown))
+			}
+			sb.WriteString("</sequences>\n")
+
+			if len(relations) > 0 {
+				sb.WriteString(fmt.Sprintf("\nFull column details: → get_schema_info(schema_name=%q, table_name=\"...\")\n", schemaName))
+			}
+
+			logging.Info("describe_schema_executed",
+				"schema", schemaName,
+				"relations", len(relations),
+				"routines", len(routines),
+				"sequences", len(sequences),
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// readSchemaRoutines lists the schema's functions, procedures and
+// aggregates, skipping (but counting) those that belong to extensions
+func readSchemaRoutines(ctx context.Context, tx pgx.Tx, schemaName string) ([]schemaRoutine, int, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT p.proname,
+		       CASE p.prokind WHEN 'p' THEN 'procedure' WHEN 'a' THEN 'aggregate'
+		                      ELSE 'function' END,
+		       pg_get_function_identity_arguments(p.oid),
+		       COALESCE(pg_get_function_result(p.oid), ''),
+		       l.lanname,
+		       EXISTS (SELECT 1 FROM pg_depend d
+		               WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e')
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		JOIN pg_language l ON l.oid = p.prolang
+		WHERE n.nspname = $1
+		ORDER BY p.proname, pg_get_function_identity_arguments(p.oid)`, schemaName)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query functions: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []schemaRoutine
+	extensionOwned := 0
+	for rows.Next() {
+		var r schemaRoutine
+		var fromExtension bool
+		if err := rows.Scan(&r.name, &r.kind, &r.arguments, &r.returns, &r.language, &fromExtension); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan function: %w", err)
+		}
+		if fromExtension {
+			extensionOwned++
+			continue
+		}
+		routines = append(routines, r)
+	}
+	return routines, extensionOwned, rows.Err()
+}
+
+// readSchemaSequences lists the schema's sequences and the columns that own
+// them (serial and identity columns)
+func readSchemaSequences(ctx context.Context, tx pgx.Tx, schemaName string) ([]schemaSequence, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.relname,
+		       format_type(s.seqtypid, NULL),
+		       COALESCE(t.relname || '.' || a.attname, '')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_sequence s ON s.seqrelid = c.oid
+		LEFT JOIN pg_depend d ON d.classid = 'pg_class'::regclass AND d.objid = c.oid
+		                     AND d.refclassid = 'pg_class'::regclass AND d.deptype IN ('a', 'i')
+		LEFT JOIN pg_class t ON t.oid = d.refobjid
+		LEFT JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE c.relkind = 'S' AND n.nspname = $1
+		ORDER BY c.relname`, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sequences: %w", err)
+	}
+	defer rows.Close()
+
+	var sequences []schemaSequence
+	for rows.Next() {
+		var s schemaSequence
+		if err := rows.Scan(&s.name, &s.dataType, &s.ownedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan sequence: %w", err)
+		}
+		sequences = append(sequences, s)
+	}
+	return sequences, rows.Err()
+}
+
+// schemaRelations returns the tables and views of one schema from the
+// metadata, sorted by name
+func schemaRelations(metadata map[string]database.TableInfo, schemaName string) []database.TableInfo {
+	var relations []database.TableInfo
+	for _, table := range metadata {
+		if table.SchemaName == schemaName {
+			relations = append(relations, table)
+		}
+	}
+	sort.Slice(relations, func(i, j int) bool {
+		return relations[i].TableName < relations[j].TableName
+	})
+	return relations
+}
+
+// metadataSchemaNames returns the sorted, distinct schema names in the metadata
+func metadataSchemaNames(metadata map[string]database.TableInfo) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, table := range metadata {
+		if !seen[table.SchemaName] {
+			seen[table.SchemaName] = true
+			names = append(names, table.SchemaName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// summarizeColumns renders a brief "name type [PK]" list of a table's
+// columns, collapsing any beyond summaryColumnsPerTable into a count
+func summarizeColumns(columns []database.ColumnInfo) string {
+	parts := make([]string, 0, summaryColumnsPerTable+1)
+	for i, col := range columns {
+		if i == summaryColumnsPerTable {
+			parts = append(parts, fmt.Sprintf("(+%d more)", len(columns)-summaryColumnsPerTable))
+			break
+		}
+		part := col.ColumnName + " " + col.DataType
+		if col.IsPrimaryKey {
+			part += " PK"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeOmitted notes how many entries of a section were left out
+func writeOmitted(sb *strings.Builder, total, shown int) {
+	if total > shown {
+		sb.WriteString(fmt.Sprintf("... %d more not shown (raise max_objects to see them)\n", total-shown))
+	}
+}
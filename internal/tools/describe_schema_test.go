@@ -0,0 +1,100 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Describe Schema Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/database"
+)
+
+func TestDescribeSchemaToolDefinition(t *testing.T) {
+	tool := DescribeSchemaTool(nil)
+
+	if tool.Definition.Name != "describe_schema" {
+		t.Errorf("Tool name = %v, want describe_schema", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"schema_name", "max_objects"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+
+	if len(tool.Definition.InputSchema.Required) != 1 || tool.Definition.InputSchema.Required[0] != "schema_name" {
+		t.Errorf("Required = %v, want [schema_name]", tool.Definition.InputSchema.Required)
+	}
+}
+
+func TestDescribeSchemaToolValidation(t *testing.T) {
+	tool := DescribeSchemaTool(nil)
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"missing schema_name", map[string]interface{}{}},
+		{"empty schema_name", map[string]interface{}{"schema_name": ""}},
+		{"zero max_objects", map[string]interface{}{"schema_name": "public", "max_objects": float64(0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if registeredToolError(t, tool, tt.args) == "" {
+				t.Error("Expected error response")
+			}
+		})
+	}
+}
+
+func TestSchemaRelations(t *testing.T) {
+	metadata := map[string]database.TableInfo{
+		"public.orders":    {SchemaName: "public", TableName: "orders", TableType: "TABLE"},
+		"public.customers": {SchemaName: "public", TableName: "customers", TableType: "TABLE"},
+		"public.active":    {SchemaName: "public", TableName: "active", TableType: "VIEW"},
+		"billing.invoices": {SchemaName: "billing", TableName: "invoices", TableType: "TABLE"},
+	}
+
+	relations := schemaRelations(metadata, "public")
+	if len(relations) != 3 {
+		t.Fatalf("Expected 3 relations, got %d", len(relations))
+	}
+	for i, want := range []string{"active", "customers", "orders"} {
+		if relations[i].TableName != want {
+			t.Errorf("relations[%d] = %s, want %s", i, relations[i].TableName, want)
+		}
+	}
+
+	names := metadataSchemaNames(metadata)
+	if len(names) != 2 || names[0] != "billing" || names[1] != "public" {
+		t.Errorf("metadataSchemaNames() = %v, want [billing public]", names)
+	}
+}
+
+func TestSummarizeColumns(t *testing.T) {
+	cols := []database.ColumnInfo{
+		{ColumnName: "id", DataType: "integer", IsPrimaryKey: true},
+		{ColumnName: "email", DataType: "text"},
+	}
+	if got := summarizeColumns(cols); got != "id integer PK, email text" {
+		t.Errorf("summarizeColumns() = %q", got)
+	}
+
+	var wide []database.ColumnInfo
+	for i := 0; i < summaryColumnsPerTable+3; i++ {
+		wide = append(wide, database.ColumnInfo{ColumnName: fmt.Sprintf("c%d", i), DataType: "text"})
+	}
+	got := summarizeColumns(wide)
+	if want := "(+3 more)"; got[len(got)-len(want):] != want {
+		t.Errorf("summarizeColumns() = %q, want suffix %q", got, want)
+	}
+}
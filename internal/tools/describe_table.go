@@ -0,0 +1,435 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// describedTable is what describe_table reports about one relation
+type describedTable struct {
+	oid          uint32
+	kind         string
+	description  string
+	reltuples    float64
+	totalBytes   int64
+	tableBytes   int64
+	indexBytes   int64
+	definition   string // View and materialized view query
+	columns      [][]interface{}
+	constraints  [][]interface{}
+	indexes      [][]interface{}
+	triggers     [][]interface{}
+	partitionKey string
+	stats        [][]interface{} // nil unless include_stats was requested
+}
+
+// describeStatsValuesLength caps the most_common_vals text per column
+const describeStatsValuesLength = 80
+
+// relkindNames maps pg_class.relkind to the names psql uses
+var relkindNames = map[string]string{
+	"r": "table",
+	"p": "partitioned table",
+	"v": "view",
+	"m": "materialized view",
+	"f": "foreign table",
+}
+
+// constraintTypeNames maps pg_constraint.contype to a readable name
+var constraintTypeNames = map[string]string{
+	"p": "primary key",
+	"f": "foreign key",
+	"u": "unique",
+	"c": "check",
+	"x": "exclusion",
+	"t": "trigger",
+	"n": "not null",
+}
+
+// DescribeTableTool creates the describe_table tool
+func DescribeTableTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "describe_table",
+			Description: `Describe one table in full detail, like psql's \d+: columns, constraints, indexes, triggers, size and row estimate.
+
+<usecase>
+Use when:
+- A question is about one specific table and you need its exact structure
+- Writing a query or migration that must respect defaults, constraints or
+  existing indexes
+- Checking which triggers fire on a table or how large it is
+</usecase>
+
+<what_it_returns>
+- Type (table, partitioned table, view, materialized view or foreign
+  table), description, row estimate (pg_class.reltuples) and size (total,
+  table and indexes)
+- Columns: type, nullability, default, identity/generated, description
+- Constraints (primary key, foreign key, unique, check, exclusion) with
+  their definitions
+- Indexes with their definitions, sizes and whether they are valid
+- Triggers with their definitions and whether they are enabled
+- For views and materialized views, the view query
+- With include_stats=true: per-column n_distinct, null fraction, most
+  common values and correlation from pg_stats
+</what_it_returns>
+
+<important>
+- Reads the live catalogs, so it reflects changes made since the server
+  started
+- The row estimate comes from the last VACUUM/ANALYZE; use count_rows for
+  an exact count
+- Column statistics exist only after ANALYZE (or autovacuum) has run on
+  the table; the output says when they are missing
+- For an overview of a whole schema use describe_schema instead
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"schema_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Schema of the table (default: public)",
+						"default":     "public",
+					},
+					"table_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Table, view or materialized view to describe",
+					},
+					"include_stats": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Include per-column statistics from pg_stats (n_distinct, null_frac, most_common_vals, correlation). Default: false",
+						"default":     false,
+					},
+				},
+				Required: []string{"table_name"},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			tableName, _ := args["table_name"].(string)
+			schemaName := ValidateOptionalStringParam(args, "schema_name", "public")
+			includeStats := ValidateBoolParam(args, "include_stats", false)
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+
+			table := &describedTable{}
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				if err := readDescribedTable(ctx, tx, table, schemaName, tableName); err != nil {
+					return err
+				}
+				// Plain views have no statistics of their own
+				if !includeStats || table.kind == "v" {
+					return nil
+				}
+				var err error
+				table.stats, err = readColumnStats(ctx, tx, table.oid)
+				return err
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				return mcp.NewToolError(fmt.Sprintf("Table '%s.%s' not found. Use describe_schema(schema_name=%q) to list its tables.",
+					schemaName, tableName, schemaName))
+			}
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Error describing table '%s.%s': %v", schemaName, tableName, err))
+			}
+			redactColumnStats(table.stats, dbClient.Redactor(), schemaName, tableName)
+
+			logging.Info("describe_table_executed",
+				"schema", schemaName,
+				"table", tableName,
+				"columns", len(table.columns),
+				"indexes", len(table.indexes),
+				"include_stats", includeStats,
+			)
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(formatDescribedTable(table, schemaName, tableName))
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// readDescribedTable fills in table from the catalogs. It returns
+// pgx.ErrNoRows if there is no such table, view or materialized view.
+func readDescribedTable(ctx context.Context, tx pgx.Tx, table *describedTable, schemaName, tableName string) error {
+	err := tx.QueryRow(ctx, `
+		SELECT c.oid, c.relkind::text, COALESCE(obj_description(c.oid, 'pg_class'), ''),
+		       c.reltuples::float8,
+		       pg_total_relation_size(c.oid), pg_relation_size(c.oid), pg_indexes_size(c.oid),
+		       CASE WHEN c.relkind IN ('v', 'm') THEN pg_get_viewdef(c.oid, true) ELSE '' END,
+		       CASE WHEN c.relkind = 'p' THEN pg_get_partkeydef(c.oid) ELSE '' END
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('r', 'p', 'v', 'm', 'f')`,
+		schemaName, tableName).Scan(&table.oid, &table.kind, &table.description, &table.reltuples,
+		&table.totalBytes, &table.tableBytes, &table.indexBytes, &table.definition, &table.partitionKey)
+	if err != nil {
+		return err
+	}
+
+	table.columns, err = queryDescribeRows(ctx, tx, `
+		SELECT a.attname,
+		       format_type(a.atttypid, a.atttypmod),
+		       CASE WHEN a.attnotnull THEN 'NO' ELSE 'YES' END,
+		       CASE WHEN a.attgenerated = 's' THEN ''
+		            ELSE COALESCE(pg_get_expr(d.adbin, d.adrelid), '') END,
+		       CASE a.attidentity WHEN 'a' THEN 'generated always as identity'
+		                          WHEN 'd' THEN 'generated by default as identity'
+		                          ELSE CASE WHEN a.attgenerated = 's'
+		                                    THEN 'generated always as (' || pg_get_expr(d.adbin, d.adrelid) || ') stored'
+		                                    ELSE '' END END,
+		       COALESCE(col_description(a.attrelid, a.attnum), '')
+		FROM pg_attribute a
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum`, 6, table.oid)
+	if err != nil {
+		return fmt.Errorf("failed to query columns: %w", err)
+	}
+
+	table.constraints, err = queryDescribeRows(ctx, tx, `
+		SELECT conname, contype::text, pg_get_constraintdef(oid, true)
+		FROM pg_constraint
+		WHERE conrelid = $1
+		ORDER BY CASE contype WHEN 'p' THEN 0 WHEN 'u' THEN 1 WHEN 'f' THEN 2 ELSE 3 END, conname`, 3, table.oid)
+	if err != nil {
+		return fmt.Errorf("failed to query constraints: %w", err)
+	}
+	for _, row := range table.constraints {
+		if name, ok := constraintTypeNames[row[1].(string)]; ok {
+			row[1] = name
+		}
+	}
+
+	table.indexes, err = queryDescribeRows(ctx, tx, `
+		SELECT ic.relname,
+		       pg_get_indexdef(i.indexrelid),
+		       pg_size_pretty(pg_relation_size(i.indexrelid)),
+		       CASE WHEN i.indisvalid THEN 'valid' ELSE 'INVALID' END
+		FROM pg_index i
+		JOIN pg_class ic ON ic.oid = i.indexrelid
+		WHERE i.indrelid = $1
+		ORDER BY i.indisprimary DESC, ic.relname`, 4, table.oid)
+	if err != nil {
+		return fmt.Errorf("failed to query indexes: %w", err)
+	}
+
+	table.triggers, err = queryDescribeRows(ctx, tx, `
+		SELECT tgname,
+		       pg_get_triggerdef(oid, true),
+		       CASE tgenabled WHEN 'D' THEN 'disabled' WHEN 'R' THEN 'replica only'
+		                      WHEN 'A' THEN 'always' ELSE 'enabled' END
+		FROM pg_trigger
+		WHERE tgrelid = $1 AND NOT tgisinternal
+		ORDER BY tgname`, 3, table.oid)
+	if err != nil {
+		return fmt.Errorf("failed to query triggers: %w", err)
+	}
+
+	return nil
+}
+
+// redactColumnStats replaces the most common values of columns matching
+// redact_columns, as they are values taken from the table
+func redactColumnStats(stats [][]interface{}, redactor *database.ColumnRedactor, schemaName, tableName string) {
+	for _, row := range stats {
+		if name, ok := row[0].(string); ok && redactor.Matches(schemaName, tableName, name) {
+			row[3] = database.RedactedValue
+		}
+	}
+}
+
+// readColumnStats reads the planner statistics of a table's columns in
+// column order. For inheritance and partitioned parents it prefers the
+// statistics that include the children.
+func readColumnStats(ctx context.Context, tx pgx.Tx, oid uint32) ([][]interface{}, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT ON (a.attnum) a.attname, s.n_distinct::float8, s.null_frac::float8,
+		       s.most_common_vals::text, s.correlation::float8
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_stats s ON s.schemaname = n.nspname AND s.tablename = c.relname AND s.attname = a.attname
+		WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum, s.inherited DESC`, oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query column statistics: %w", err)
+	}
+	defer rows.Close()
+
+	stats := [][]interface{}{}
+	for rows.Next() {
+		var name string
+		var nDistinct, nullFrac float64
+		var mostCommon *string
+		var correlation *float64
+		if err := rows.Scan(&name, &nDistinct, &nullFrac, &mostCommon, &correlation); err != nil {
+			return nil, fmt.Errorf("failed to scan column statistics: %w", err)
+		}
+		stats = append(stats, []interface{}{
+			name,
+			formatNDistinct(nDistinct),
+			strconv.FormatFloat(nullFrac, 'f', 3, 64),
+			truncateStatsValues(mostCommon),
+			formatCorrelation(correlation),
+		})
+	}
+	return stats, rows.Err()
+}
+
+// formatNDistinct renders pg_stats.n_distinct, which is a count when
+// positive and minus the fraction of rows when negative
+func formatNDistinct(nDistinct float64) string {
+	if nDistinct < 0 {
+		if nDistinct == -1 {
+			return "all rows distinct"
+		}
+		return fmt.Sprintf("%.1f%% of rows", -nDistinct*100)
+	}
+	return strconv.FormatFloat(nDistinct, 'f', 0, 64)
+}
+
+// formatCorrelation renders pg_stats.correlation, which is NULL for types
+// without a sort order
+func formatCorrelation(correlation *float64) string {
+	if correlation == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*correlation, 'f', 2, 64)
+}
+
+// truncateStatsValues shortens the most_common_vals array text, which can
+// be long for wide columns
+func truncateStatsValues(values *string) string {
+	if values == nil {
+		return ""
+	}
+	runes := []rune(*values)
+	if len(runes) <= describeStatsValuesLength {
+		return *values
+	}
+	return string(runes[:describeStatsValuesLength]) + "..."
+}
+
+// queryDescribeRows runs a catalog query returning width text columns
+func queryDescribeRows(ctx context.Context, tx pgx.Tx, sql string, width int, oid uint32) ([][]interface{}, error) {
+	rows, err := tx.Query(ctx, sql, oid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result [][]interface{}
+	for rows.Next() {
+		values := make([]string, width)
+		dest := make([]interface{}, width)
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make([]interface{}, width)
+		for i, v := range values {
+			row[i] = v
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// formatDescribedTable renders the description in sections
+func formatDescribedTable(table *describedTable, schemaName, tableName string) string {
+	kind := relkindNames[table.kind]
+	if kind == "" {
+		kind = table.kind
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Table: %s.%s\n", schemaName, tableName))
+	sb.WriteString(fmt.Sprintf("Type: %s\n", kind))
+	if table.description != "" {
+		sb.WriteString(fmt.Sprintf("Description: %s\n", table.description))
+	}
+	if table.partitionKey != "" {
+		sb.WriteString(fmt.Sprintf("Partition key: %s\n", table.partitionKey))
+	}
+	if table.kind != "v" {
+		// reltuples is -1 until the first VACUUM/ANALYZE on PostgreSQL 14+
+		if table.reltuples < 0 {
+			sb.WriteString("Estimated rows: unknown (never analyzed)\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("Estimated rows: %.0f\n", table.reltuples))
+		}
+		sb.WriteString(fmt.Sprintf("Size: %s total (table %s, indexes %s)\n",
+			formatBytes(table.totalBytes), formatBytes(table.tableBytes), formatBytes(table.indexBytes)))
+	}
+
+	writeSection := func(tag string, columns []string, rows [][]interface{}) {
+		sb.WriteString(fmt.Sprintf("\n<%s>\n", tag))
+		if len(rows) == 0 {
+			sb.WriteString("None\n")
+		} else {
+			sb.WriteString(FormatResultsAsTSV(columns, rows))
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("</%s>\n", tag))
+	}
+
+	writeSection("columns", []string{"name", "type", "nullable", "default", "generated", "description"}, table.columns)
+	if table.kind != "v" {
+		writeSection("constraints", []string{"name", "type", "definition"}, table.constraints)
+		writeSection("indexes", []string{"name", "definition", "size", "status"}, table.indexes)
+	}
+	writeSection("triggers", []string{"name", "definition", "enabled"}, table.triggers)
+
+	if table.stats != nil {
+		sb.WriteString("\n<column_stats>\n")
+		if len(table.stats) == 0 {
+			sb.WriteString(fmt.Sprintf("No statistics: the table has not been analyzed yet. Run ANALYZE %s to collect them.\n",
+				pgx.Identifier{schemaName, tableName}.Sanitize()))
+		} else {
+			sb.WriteString(FormatResultsAsTSV([]string{"name", "n_distinct", "null_frac", "most_common_vals", "correlation"}, table.stats))
+			sb.WriteString("\n")
+		}
+		sb.WriteString("</column_stats>\n")
+	}
+
+	if table.definition != "" {
+		sb.WriteString("\n<view_definition>\n")
+		sb.WriteString(strings.TrimSpace(table.definition))
+		sb.WriteString("\n</view_definition>\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,170 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Describe Table Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/database"
+)
+
+func TestDescribeTableToolDefinition(t *testing.T) {
+	tool := DescribeTableTool(nil)
+
+	if tool.Definition.Name != "describe_table" {
+		t.Errorf("Tool name = %v, want describe_table", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"schema_name", "table_name", "include_stats"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+
+	if len(tool.Definition.InputSchema.Required) != 1 || tool.Definition.InputSchema.Required[0] != "table_name" {
+		t.Errorf("Required = %v, want [table_name]", tool.Definition.InputSchema.Required)
+	}
+}
+
+func TestDescribeTableToolValidation(t *testing.T) {
+	tool := DescribeTableTool(nil)
+
+	for _, args := range []map[string]interface{}{
+		{},
+		{"table_name": ""},
+		{"table_name": 42},
+	} {
+		if registeredToolError(t, tool, args) == "" {
+			t.Errorf("Expected error response for %v", args)
+		}
+	}
+}
+
+func TestFormatDescribedTable(t *testing.T) {
+	table := &describedTable{
+		kind:        "r",
+		description: "Customer orders",
+		reltuples:   1200,
+		totalBytes:  3 * 1024 * 1024,
+		tableBytes:  2 * 1024 * 1024,
+		indexBytes:  1024 * 1024,
+		columns: [][]interface{}{
+			{"id", "bigint", "NO", "", "generated always as identity", ""},
+			{"status", "text", "YES", "'new'::text", "", "Order status"},
+		},
+		constraints: [][]interface{}{
+			{"orders_pkey", "primary key", "PRIMARY KEY (id)"},
+		},
+		indexes: [][]interface{}{
+			{"orders_pkey", "CREATE UNIQUE INDEX orders_pkey ON public.orders USING btree (id)", "64 kB", "valid"},
+		},
+	}
+
+	out := formatDescribedTable(table, "public", "orders")
+	for _, want := range []string{
+		"Table: public.orders\n",
+		"Type: table\n",
+		"Description: Customer orders\n",
+		"Estimated rows: 1200\n",
+		"Size: 3.0 MB total",
+		"status\ttext\tYES\t'new'::text\t\tOrder status",
+		"orders_pkey\tprimary key\tPRIMARY KEY (id)",
+		"<triggers>\nNone\n</triggers>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+
+	// Views have no size, constraints or indexes, but show their query
+	view := &describedTable{
+		kind:       "v",
+		definition: " SELECT id FROM orders;",
+		columns:    [][]interface{}{{"id", "bigint", "YES", "", "", ""}},
+	}
+	out = formatDescribedTable(view, "public", "order_ids")
+	if strings.Contains(out, "Size:") || strings.Contains(out, "<indexes>") {
+		t.Errorf("View output has table-only sections:\n%s", out)
+	}
+	if !strings.Contains(out, "<view_definition>\nSELECT id FROM orders;\n</view_definition>") {
+		t.Errorf("View output missing definition:\n%s", out)
+	}
+
+	// reltuples is -1 before the first ANALYZE
+	table.reltuples = -1
+	out = formatDescribedTable(table, "public", "orders")
+	if !strings.Contains(out, "Estimated rows: unknown") {
+		t.Errorf("Expected unknown row estimate:\n%s", out)
+	}
+	if strings.Contains(out, "<column_stats>") {
+		t.Errorf("Column statistics shown without include_stats:\n%s", out)
+	}
+
+	// Requested statistics that don't exist yet point at ANALYZE
+	table.stats = [][]interface{}{}
+	if out := formatDescribedTable(table, "public", "orders"); !strings.Contains(out, `Run ANALYZE "public"."orders"`) {
+		t.Errorf("Expected missing statistics note:\n%s", out)
+	}
+
+	table.stats = [][]interface{}{{"status", "4", "0.000", "{new,paid,shipped,cancelled}", "0.31"}}
+	if out := formatDescribedTable(table, "public", "orders"); !strings.Contains(out, "status\t4\t0.000\t{new,paid,shipped,cancelled}\t0.31") {
+		t.Errorf("Expected column statistics:\n%s", out)
+	}
+}
+
+func TestFormatColumnStats(t *testing.T) {
+	for nDistinct, want := range map[float64]string{
+		42:    "42",
+		-1:    "all rows distinct",
+		-0.25: "25.0% of rows",
+	} {
+		if got := formatNDistinct(nDistinct); got != want {
+			t.Errorf("formatNDistinct(%v) = %q, want %q", nDistinct, got, want)
+		}
+	}
+
+	if got := formatCorrelation(nil); got != "" {
+		t.Errorf("formatCorrelation(nil) = %q, want empty", got)
+	}
+	correlation := -0.987
+	if got := formatCorrelation(&correlation); got != "-0.99" {
+		t.Errorf("formatCorrelation() = %q, want -0.99", got)
+	}
+
+	long := "{" + strings.Repeat("x", describeStatsValuesLength) + "}"
+	if got := truncateStatsValues(&long); len(got) != describeStatsValuesLength+3 || !strings.HasSuffix(got, "...") {
+		t.Errorf("truncateStatsValues() = %q", got)
+	}
+	if got := truncateStatsValues(nil); got != "" {
+		t.Errorf("truncateStatsValues(nil) = %q, want empty", got)
+	}
+}
+
+func TestRedactColumnStats(t *testing.T) {
+	stats := [][]interface{}{
+		{"email", "-1", "0.000", "{a@example.com,b@example.com}", "0.01"},
+		{"status", "4", "0.000", "{new,paid}", "0.31"},
+	}
+	redactColumnStats(stats, database.NewColumnRedactor([]string{"orders.email"}), "public", "orders")
+	if stats[0][3] != database.RedactedValue {
+		t.Errorf("Expected email most_common_vals to be redacted, got %v", stats[0][3])
+	}
+	if stats[1][3] != "{new,paid}" {
+		t.Errorf("Expected status most_common_vals to be kept, got %v", stats[1][3])
+	}
+
+	// A nil redactor leaves the statistics alone
+	redactColumnStats(stats, nil, "public", "orders")
+	if stats[1][3] != "{new,paid}" {
+		t.Errorf("Expected nil redactor to keep values, got %v", stats[1][3])
+	}
+}
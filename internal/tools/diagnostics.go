@@ -0,0 +1,77 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"pgedge-postgres-mcp/internal/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// executeReadOnly runs fn inside a READ ONLY transaction on the given pool.
+// The transaction is committed if fn succeeds and rolled back otherwise.
+// Diagnostic tools use this so they never hold a writable transaction.
+// The database's replica_only setting is checked in the same transaction,
+// before fn runs.
+func executeReadOnly(ctx context.Context, dbClient *database.Client, pool *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback(ctx) //nolint:errcheck // Best effort cleanup on panic
+			panic(r)
+		}
+		if !committed {
+			_ = tx.Rollback(ctx) //nolint:errcheck // rollback in defer after commit is expected to fail
+		}
+	}()
+
+	if _, err := tx.Exec(ctx, "SET TRANSACTION READ ONLY"); err != nil {
+		return fmt.Errorf("failed to set transaction read-only: %w", err)
+	}
+
+	if err := checkReplica(ctx, dbClient, tx); err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	return nil
+}
+
+// formatBytes renders a byte count using binary units (e.g. "12.5 MB")
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d bytes", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "kMGTP"[exp])
+}
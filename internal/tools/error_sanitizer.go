@@ -0,0 +1,103 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+var (
+	// Key (email)=(bob@example.com) in unique/foreign key violation details
+	keyValueDetailRegex = regexp.MustCompile(`Key \(([^)]*)\)=\((.*?)\)( already exists| is not present| is still referenced|$)`)
+
+	// Failing row contains (1, secret, ...) in not-null/check violation details
+	failingRowRegex = regexp.MustCompile(`Failing row contains \(.*\)`)
+
+	// Absolute filesystem paths, e.g. /var/lib/postgresql/data/base/16384
+	filePathRegex = regexp.MustCompile(`(^|[\s"'(=:])(/[\w.\-]+){2,}/?`)
+
+	// Connection URIs, which may embed credentials
+	connURIRegex = regexp.MustCompile(`postgres(?:ql)?://[^\s"']+`)
+
+	// password=... in keyword/value connection strings
+	passwordParamRegex = regexp.MustCompile(`(?i)(password\s*=\s*)('[^']*'|\S+)`)
+)
+
+// sanitizeToolError rewrites an error response according to the configured
+// sanitization mode. The original text is logged with a correlation ID so it
+// can still be found from the client-facing message.
+func sanitizeToolError(mode, toolName string, response mcp.ToolResponse) mcp.ToolResponse {
+	if !response.IsError || mode == config.ErrorSanitizationOff {
+		return response
+	}
+
+	correlationID := newCorrelationID()
+	original := toolResponseText(response)
+	logging.Error("tool_error",
+		"tool", toolName,
+		"correlation_id", correlationID,
+		"error", original,
+	)
+
+	var text string
+	if mode == config.ErrorSanitizationStrict {
+		text = fmt.Sprintf("The %s tool failed. Details were logged on the server with correlation ID %s; "+
+			"quote this ID when asking for support.", toolName, correlationID)
+	} else {
+		text = fmt.Sprintf("%s\n\n(correlation ID: %s)", redactErrorText(original), correlationID)
+	}
+
+	return mcp.ToolResponse{
+		Content: []mcp.ContentItem{{Type: "text", Text: text}},
+		IsError: true,
+	}
+}
+
+// redactErrorText removes data values, filesystem paths and credentials from
+// error text while keeping the error's structure intact
+func redactErrorText(text string) string {
+	text = keyValueDetailRegex.ReplaceAllString(text, "Key ($1)=(<redacted>)$3")
+	text = failingRowRegex.ReplaceAllString(text, "Failing row contains (<redacted>)")
+	text = connURIRegex.ReplaceAllStringFunc(text, database.SanitizeConnStr)
+	text = passwordParamRegex.ReplaceAllString(text, "${1}<redacted>")
+	text = filePathRegex.ReplaceAllString(text, "$1<path>")
+	return text
+}
+
+// toolResponseText joins the text content of a tool response
+func toolResponseText(response mcp.ToolResponse) string {
+	parts := make([]string, 0, len(response.Content))
+	for _, item := range response.Content {
+		if item.Text != "" {
+			parts = append(parts, item.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// newCorrelationID returns a short random identifier for matching a client
+// error to its server log entry
+func newCorrelationID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "unavailable"
+	}
+	return hex.EncodeToString(b)
+}
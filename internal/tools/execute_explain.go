@@ -12,17 +12,21 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
 
+	"pgedge-postgres-mcp/internal/config"
 	"pgedge-postgres-mcp/internal/database"
 	"pgedge-postgres-mcp/internal/logging"
 	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
 )
 
 // ExecuteExplainTool creates the execute_explain tool for query performance analysis
-func ExecuteExplainTool(dbClient *database.Client) Tool {
+func ExecuteExplainTool(dbClient *database.Client, cfg *config.Config) Tool {
 	return Tool{
 		Definition: mcp.Tool{
 			Name: "execute_explain",
@@ -64,7 +68,13 @@ DO NOT use for:
 
 <safety>
 IMPORTANT: This tool executes the query with EXPLAIN ANALYZE within a
-READ ONLY transaction to prevent side effects. However, be cautious with:
+READ ONLY transaction that is always rolled back, so side effects are
+discarded. ANALYZE is skipped, and only the estimated plan is shown, when:
+- The server disables ANALYZE entirely
+- The query has a data-modifying CTE (WITH ... AS (DELETE ...))
+- The estimated cost is above the server's ceiling; set force_analyze=true
+  to run it anyway
+However, be cautious with:
 - Queries that lock resources
 - Very long-running queries
 - Queries on production systems during peak load
@@ -74,11 +84,11 @@ READ ONLY transaction to prevent side effects. However, be cautious with:
 				Properties: map[string]interface{}{
 					"query": map[string]interface{}{
 						"type":        "string",
-						"description": "The SQL query to analyze (SELECT queries only)",
+						"description": "The SQL query to analyze (SELECT or WITH ... SELECT queries only)",
 					},
 					"analyze": map[string]interface{}{
 						"type":        "boolean",
-						"description": "Run EXPLAIN ANALYZE (executes query) vs plain EXPLAIN (planning only). Default: true",
+						"description": "Run EXPLAIN ANALYZE (executes query, then rolls back) vs plain EXPLAIN (planning only). Ignored if the server disables ANALYZE. Default: true",
 						"default":     true,
 					},
 					"buffers": map[string]interface{}{
@@ -92,6 +102,12 @@ READ ONLY transaction to prevent side effects. However, be cautious with:
 						"description": "Output format: 'text' for human-readable (default), 'json' for structured data",
 						"default":     "text",
 					},
+					"force_analyze": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Run ANALYZE even when the estimated cost is above the server's ceiling. Does not override a server that disables ANALYZE or the data-modifying CTE check. Default: false",
+						"default":     false,
+					},
+					"timeout_seconds": timeoutSecondsProperty(dbClient),
 				},
 				Required: []string{"query"},
 			},
@@ -107,6 +123,7 @@ READ ONLY transaction to prevent side effects. However, be cautious with:
 			analyze := true
 			buffers := true
 			format := "text"
+			forceAnalyze := false
 
 			if val, ok := args["analyze"].(bool); ok {
 				analyze = val
@@ -117,51 +134,59 @@ READ ONLY transaction to prevent side effects. However, be cautious with:
 			if val, ok := args["format"].(string); ok {
 				format = val
 			}
+			if val, ok := args["force_analyze"].(bool); ok {
+				forceAnalyze = val
+			}
 
-			// Validate query is a SELECT
-			trimmedQuery := strings.TrimSpace(query)
-			if !strings.HasPrefix(strings.ToUpper(trimmedQuery), "SELECT") {
-				return mcp.NewToolError("Only SELECT queries are supported. EXPLAIN ANALYZE executes the query, which could have side effects for INSERT/UPDATE/DELETE/DDL statements.")
+			// The server can forbid ANALYZE, since it executes the query
+			analyzeDowngraded := false
+			if analyze && cfg != nil && !cfg.Explain.IsAnalyzeAllowed() {
+				analyze = false
+				analyzeDowngraded = true
 			}
 
-			// Build EXPLAIN command
-			var explainCmd strings.Builder
-			explainCmd.WriteString("EXPLAIN (")
+			// Validate query is a SELECT (optionally with CTEs)
+			stmtType := database.StatementType(query)
+			if stmtType != "SELECT" && stmtType != "WITH" {
+				return mcp.NewToolError("Only SELECT queries (including WITH ... SELECT) are supported. EXPLAIN ANALYZE executes the query, which could have side effects for INSERT/UPDATE/DELETE/DDL statements.")
+			}
 
-			var options []string
+			// A data-modifying CTE would run its write under ANALYZE; only
+			// show the estimated plan for those
+			var skipReason string
 			if analyze {
-				options = append(options, "ANALYZE TRUE")
-			}
-			if buffers {
-				options = append(options, "BUFFERS TRUE")
-			}
-			if format == "json" {
-				options = append(options, "FORMAT JSON")
+				if kw := database.DataModifyingCTE(query); kw != "" {
+					analyze = false
+					skipReason = fmt.Sprintf("the query contains a data-modifying %s, which EXPLAIN ANALYZE would execute", kw)
+				}
 			}
 
-			explainCmd.WriteString(strings.Join(options, ", "))
-			explainCmd.WriteString(") ")
-			explainCmd.WriteString(query)
+			costCeiling := config.DefaultMaxAnalyzeCost
+			if cfg != nil {
+				costCeiling = cfg.Explain.GetMaxAnalyzeCost()
+			}
 
-			explainQuery := explainCmd.String()
+			timeout, errResp := resolveQueryTimeout(dbClient, args)
+			if errResp != nil {
+				return *errResp, nil
+			}
 
 			// Get database connection
 			connStr := dbClient.GetDefaultConnection()
 			pool := dbClient.GetPoolFor(connStr)
 
-			ctx := context.Background()
+			ctx, cancel := withQueryTimeout(requestContext(args), timeout)
+			defer cancel()
 
-			// Execute EXPLAIN in a READ ONLY transaction
+			// Execute EXPLAIN in a READ ONLY transaction that is always rolled
+			// back, so any side effects of EXPLAIN ANALYZE (e.g. a volatile
+			// function called from the SELECT) are discarded
 			tx, err := pool.Begin(ctx)
 			if err != nil {
 				return mcp.NewToolError(fmt.Sprintf("Failed to begin transaction: %v", err))
 			}
-
-			committed := false
 			defer func() {
-				if !committed {
-					_ = tx.Rollback(ctx) //nolint:errcheck // rollback in defer after commit is expected to fail
-				}
+				_ = tx.Rollback(ctx) //nolint:errcheck // Rollback also runs after errors that already aborted the transaction
 			}()
 
 			// Set transaction to read-only
@@ -170,9 +195,38 @@ READ ONLY transaction to prevent side effects. However, be cautious with:
 				return mcp.NewToolError(fmt.Sprintf("Failed to set transaction to read-only: %v", err))
 			}
 
+			if err := checkReplica(ctx, dbClient, tx); err != nil {
+				return mcp.NewToolError(err.Error())
+			}
+
+			// EXPLAIN ANALYZE runs the query, so it is subject to the query timeout
+			if err := setStatementTimeout(ctx, tx, timeout); err != nil {
+				return mcp.NewToolError(err.Error())
+			}
+
+			// Check the planner's estimate before running an expensive query
+			var estimatedCost float64
+			if analyze && costCeiling > 0 && !forceAnalyze {
+				estimatedCost, err = estimatePlanCost(ctx, tx, query)
+				if err != nil {
+					return mcp.NewToolError(fmt.Sprintf("Error estimating query cost: %v", err))
+				}
+				if estimatedCost > costCeiling {
+					analyze = false
+					skipReason = fmt.Sprintf("the estimated cost (%.0f) is above the server's ceiling of %.0f (explain.max_analyze_cost); "+
+						"call again with force_analyze=true to execute it anyway", estimatedCost, costCeiling)
+				}
+			}
+
+			explainQuery := buildExplainQuery(query, analyze, buffers, format)
+
 			// Execute EXPLAIN
 			rows, err := tx.Query(ctx, explainQuery)
 			if err != nil {
+				if isQueryTimeout(ctx, err) {
+					logging.Warn("execute_explain_timeout", "timeout", timeout.String(), "query", logging.SQL(query))
+					return mcp.NewToolError(fmt.Sprintf("%s\n\nQuery: %s", queryTimeoutMessage(timeout), explainQuery))
+				}
 				return mcp.NewToolError(fmt.Sprintf("Error executing EXPLAIN: %v\n\nQuery: %s", err, explainQuery))
 			}
 			defer rows.Close()
@@ -188,20 +242,31 @@ READ ONLY transaction to prevent side effects. However, be cautious with:
 			}
 
 			if err := rows.Err(); err != nil {
+				if isQueryTimeout(ctx, err) {
+					logging.Warn("execute_explain_timeout", "timeout", timeout.String(), "query", logging.SQL(query))
+					return mcp.NewToolError(fmt.Sprintf("%s\n\nQuery: %s", queryTimeoutMessage(timeout), explainQuery))
+				}
 				return mcp.NewToolError(fmt.Sprintf("Error iterating EXPLAIN output: %v", err))
 			}
 
-			// Commit the read-only transaction
-			if err := tx.Commit(ctx); err != nil {
-				return mcp.NewToolError(fmt.Sprintf("Failed to commit transaction: %v", err))
+			// Discard anything the query did before the connection is reused
+			rows.Close()
+			if err := tx.Rollback(ctx); err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Failed to roll back transaction: %v", err))
 			}
-			committed = true
 
 			// Format the output
 			var result strings.Builder
 			sanitizedConn := database.SanitizeConnStr(connStr)
 			result.WriteString(fmt.Sprintf("Database: %s\n\n", sanitizedConn))
 			result.WriteString(fmt.Sprintf("Query:\n%s\n\n", query))
+			if analyzeDowngraded {
+				result.WriteString("Note: EXPLAIN ANALYZE is disabled on this server (explain.analyze_allowed: false); " +
+					"showing the estimated plan without executing the query.\n\n")
+			} else if skipReason != "" {
+				result.WriteString(fmt.Sprintf("Note: ANALYZE was skipped because %s. "+
+					"Showing the estimated plan without executing the query.\n\n", skipReason))
+			}
 			result.WriteString("Execution Plan:\n")
 			result.WriteString(strings.Repeat("=", 80))
 			result.WriteString("\n")
@@ -223,8 +288,12 @@ READ ONLY transaction to prevent side effects. However, be cautious with:
 
 			// Log execution metrics
 			logging.Info("execute_explain_executed",
+				"query", logging.SQL(query),
 				"query_length", len(query),
 				"analyze", analyze,
+				"analyze_downgraded", analyzeDowngraded,
+				"analyze_skipped", skipReason != "",
+				"estimated_cost", estimatedCost,
 				"buffers", buffers,
 				"format", format,
 				"output_lines", len(explainOutput),
@@ -235,6 +304,52 @@ READ ONLY transaction to prevent side effects. However, be cautious with:
 	}
 }
 
+// buildExplainQuery wraps query in an EXPLAIN with the requested options
+func buildExplainQuery(query string, analyze, buffers bool, format string) string {
+	var options []string
+	if analyze {
+		options = append(options, "ANALYZE TRUE")
+	}
+	if buffers {
+		options = append(options, "BUFFERS TRUE")
+	}
+	if format == "json" {
+		options = append(options, "FORMAT JSON")
+	}
+	// An empty option list is a syntax error
+	if len(options) == 0 {
+		return "EXPLAIN " + query
+	}
+	return "EXPLAIN (" + strings.Join(options, ", ") + ") " + query
+}
+
+// estimatePlanCost returns the planner's total estimated cost for query,
+// using a plain EXPLAIN that doesn't execute it
+func estimatePlanCost(ctx context.Context, tx pgx.Tx, query string) (float64, error) {
+	var planJSON string
+	if err := tx.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+query).Scan(&planJSON); err != nil {
+		return 0, err
+	}
+	return parsePlanCost(planJSON)
+}
+
+// parsePlanCost extracts the top-level "Total Cost" from EXPLAIN (FORMAT JSON)
+// output
+func parsePlanCost(planJSON string) (float64, error) {
+	var plans []struct {
+		Plan struct {
+			TotalCost float64 `json:"Total Cost"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(planJSON), &plans); err != nil {
+		return 0, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if len(plans) == 0 {
+		return 0, fmt.Errorf("EXPLAIN returned no plan")
+	}
+	return plans[0].Plan.TotalCost, nil
+}
+
 // analyzeExplainOutput extracts key metrics and provides recommendations
 func analyzeExplainOutput(explainText string) string {
 	var analysis strings.Builder
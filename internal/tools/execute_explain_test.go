@@ -16,7 +16,7 @@ import (
 )
 
 func TestExecuteExplainToolDefinition(t *testing.T) {
-	tool := ExecuteExplainTool(nil)
+	tool := ExecuteExplainTool(nil, nil)
 
 	if tool.Definition.Name != "execute_explain" {
 		t.Errorf("Tool name = %v, want execute_explain", tool.Definition.Name)
@@ -63,7 +63,7 @@ func TestExecuteExplainToolDefinition(t *testing.T) {
 }
 
 func TestExecuteExplainValidation(t *testing.T) {
-	tool := ExecuteExplainTool(nil)
+	tool := ExecuteExplainTool(nil, nil)
 
 	tests := []struct {
 		name        string
@@ -210,7 +210,7 @@ func TestAnalyzeExplainOutput(t *testing.T) {
 
 func TestExecuteExplainToolResponseFormat(t *testing.T) {
 	// This test verifies the tool definition format
-	tool := ExecuteExplainTool(nil)
+	tool := ExecuteExplainTool(nil, nil)
 
 	// Verify tool definition structure
 	if tool.Definition.Name != "execute_explain" {
@@ -233,7 +233,7 @@ func TestExecuteExplainToolResponseFormat(t *testing.T) {
 }
 
 func TestExecuteExplainBooleanDefaults(t *testing.T) {
-	tool := ExecuteExplainTool(nil)
+	tool := ExecuteExplainTool(nil, nil)
 
 	// Test that boolean parameters have proper defaults
 	schema := tool.Definition.InputSchema
@@ -263,7 +263,7 @@ func TestExecuteExplainBooleanDefaults(t *testing.T) {
 func TestExecuteExplainToolRegistration(t *testing.T) {
 	// Verify that execute_explain tool can be registered
 	registry := NewRegistry()
-	tool := ExecuteExplainTool(nil)
+	tool := ExecuteExplainTool(nil, nil)
 
 	registry.Register("execute_explain", tool)
 
@@ -279,7 +279,7 @@ func TestExecuteExplainToolRegistration(t *testing.T) {
 
 func TestExecuteExplainReturnsToolResponse(t *testing.T) {
 	// Test that validation errors return proper tool responses without requiring DB
-	tool := ExecuteExplainTool(nil)
+	tool := ExecuteExplainTool(nil, nil)
 
 	// Test with missing query (validation error, no DB needed)
 	response, _ := tool.Handler(map[string]interface{}{})
@@ -308,7 +308,7 @@ func TestExecuteExplainReturnsToolResponse(t *testing.T) {
 func TestExecuteExplainToolResponse(t *testing.T) {
 	// Test that execute_explain properly uses mcp.NewToolError and mcp.NewToolSuccess
 	// This is tested implicitly through the validation tests above
-	tool := ExecuteExplainTool(nil)
+	tool := ExecuteExplainTool(nil, nil)
 
 	// Test validation error response
 	response, _ := tool.Handler(map[string]interface{}{})
@@ -322,3 +322,42 @@ func TestExecuteExplainToolResponse(t *testing.T) {
 		t.Error("Non-SELECT query should return error response")
 	}
 }
+
+func TestBuildExplainQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		analyze  bool
+		buffers  bool
+		format   string
+		expected string
+	}{
+		{"all options", true, true, "json", "EXPLAIN (ANALYZE TRUE, BUFFERS TRUE, FORMAT JSON) SELECT 1"},
+		{"analyze skipped", false, true, "text", "EXPLAIN (BUFFERS TRUE) SELECT 1"},
+		{"no options", false, false, "text", "EXPLAIN SELECT 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildExplainQuery("SELECT 1", tt.analyze, tt.buffers, tt.format); got != tt.expected {
+				t.Errorf("buildExplainQuery() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePlanCost(t *testing.T) {
+	planJSON := `[{"Plan": {"Node Type": "Seq Scan", "Startup Cost": 0.00, "Total Cost": 18334.00, "Plan Rows": 1000000}}]`
+	cost, err := parsePlanCost(planJSON)
+	if err != nil {
+		t.Fatalf("parsePlanCost() error: %v", err)
+	}
+	if cost != 18334.00 {
+		t.Errorf("parsePlanCost() = %v, want 18334", cost)
+	}
+
+	for _, bad := range []string{"", "[]", "not json"} {
+		if _, err := parsePlanCost(bad); err == nil {
+			t.Errorf("parsePlanCost(%q) expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// ExecuteSQLTool creates the execute_sql tool, which runs caller-written SQL
+// as-is. It is only registered when builtins.tools.raw_sql_enabled is set.
+func ExecuteSQLTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "execute_sql",
+			Description: `Run a hand-written, read-only SQL statement exactly as given and return the rows as JSON.
+
+<usecase>
+Use execute_sql when the user supplies the SQL themselves and wants it run
+verbatim, for example a query they have already written and tested.
+</usecase>
+
+<when_not_to_use>
+- Questions in natural language → use query_database
+- Natural language content search → use similarity_search
+</when_not_to_use>
+
+<what_it_returns>
+- The SQL that ran
+- A JSON array with one object per row; NULLs are null
+- A note when the server's row cap truncated the results
+</what_it_returns>
+
+<important>
+- Only a single SELECT, WITH or EXPLAIN statement is accepted; anything that
+  could modify data is rejected before it reaches the database
+- The statement runs in a READ-ONLY transaction with the same query timeout
+  and row cap as query_database
+- No LIMIT is added; include one in the SQL for large tables
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"sql": map[string]interface{}{
+						"type":        "string",
+						"description": "The SQL statement to run, unchanged",
+					},
+					"max_rows": map[string]interface{}{
+						"type":        "integer",
+						"description": "Hard cap on rows returned for this call. Can lower but not raise the server's max_result_rows setting (default: 1000).",
+						"minimum":     1,
+					},
+					"timeout_seconds": timeoutSecondsProperty(dbClient),
+				},
+				Required: []string{"sql"},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			sqlQuery, _ := args["sql"].(string)
+			sqlQuery = strings.TrimSpace(sqlQuery)
+
+			// Unlike query_database this applies whatever the database's
+			// access mode, since the SQL comes straight from the caller
+			if err := database.ValidateReadOnlySQL(sqlQuery); err != nil {
+				logging.Warn("execute_sql_rejected", "reason", err.Error(), "query", logging.SQL(sqlQuery))
+				return mcp.NewToolError(fmt.Sprintf("Query rejected: %v\n\nSQL Query:\n%s", err, sqlQuery))
+			}
+
+			maxRows := dbClient.MaxResultRows()
+			if val, ok := args["max_rows"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'max_rows' must be a positive integer")
+				}
+				if int(val) < maxRows {
+					maxRows = int(val)
+				}
+			}
+
+			timeout, errResp := resolveQueryTimeout(dbClient, args)
+			if errResp != nil {
+				return *errResp, nil
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx, cancel := withQueryTimeout(requestContext(args), timeout)
+			defer cancel()
+
+			var columnNames []string
+			var results [][]interface{}
+			hitRowCap := false
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				if err := setStatementTimeout(ctx, tx, timeout); err != nil {
+					return err
+				}
+
+				// Columns matching redact_columns are masked in every row
+				redacted, err := dbClient.Redactor().ResultColumns(ctx, tx, sqlQuery)
+				if err != nil {
+					return err
+				}
+
+				rows, err := tx.Query(ctx, sqlQuery)
+				if err != nil {
+					return err
+				}
+				defer rows.Close()
+
+				for _, fd := range rows.FieldDescriptions() {
+					columnNames = append(columnNames, fd.Name)
+				}
+				for rows.Next() {
+					if len(results) >= maxRows {
+						hitRowCap = true
+						break
+					}
+					values, err := rows.Values()
+					if err != nil {
+						return fmt.Errorf("error reading row: %w", err)
+					}
+					database.RedactRow(values, redacted)
+					results = append(results, values)
+				}
+				rows.Close()
+				return rows.Err()
+			})
+			if err != nil {
+				if errResp := noReplicaResponse(err); errResp != nil {
+					return *errResp, nil
+				}
+				if isQueryTimeout(ctx, err) {
+					logging.Warn("execute_sql_timeout", "timeout", timeout.String(), "query", logging.SQL(sqlQuery))
+					return mcp.NewToolError(fmt.Sprintf("SQL Query:\n%s\n\n%s", sqlQuery, queryTimeoutMessage(timeout)))
+				}
+				return mcp.NewToolError(fmt.Sprintf("SQL Query:\n%s\n\nError executing query: %v", sqlQuery, err))
+			}
+
+			resultsJSON, err := FormatResultsAsJSON(columnNames, results)
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Failed to encode results as JSON: %v", err))
+			}
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(fmt.Sprintf("SQL Query:\n%s\n\n", sqlQuery))
+			sb.WriteString(fmt.Sprintf("Results (%d rows):\n%s", len(results), resultsJSON))
+			if hitRowCap {
+				sb.WriteString(fmt.Sprintf("\n\nResults truncated at %d rows (query returned more). Add a WHERE clause or LIMIT to the SQL.", maxRows))
+			}
+
+			logging.Info("execute_sql_executed",
+				"query", logging.SQL(sqlQuery),
+				"query_length", len(sqlQuery),
+				"rows_returned", len(results),
+				"hit_row_cap", hitRowCap,
+				"max_rows", maxRows,
+				"estimated_tokens", len(resultsJSON)/4,
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
@@ -0,0 +1,66 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Execute SQL Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExecuteSQLToolDefinition(t *testing.T) {
+	tool := ExecuteSQLTool(nil)
+
+	if tool.Definition.Name != "execute_sql" {
+		t.Errorf("Tool name = %v, want execute_sql", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"sql", "max_rows", "timeout_seconds"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+
+	if len(tool.Definition.InputSchema.Required) != 1 || tool.Definition.InputSchema.Required[0] != "sql" {
+		t.Errorf("Required = %v, want [sql]", tool.Definition.InputSchema.Required)
+	}
+}
+
+func TestExecuteSQLRejectsMutations(t *testing.T) {
+	tool := ExecuteSQLTool(nil)
+
+	tests := []struct {
+		name    string
+		sql     interface{}
+		wantErr string
+	}{
+		{"missing", nil, "sql"},
+		{"insert", "INSERT INTO orders VALUES (1)", "INSERT statements are not allowed"},
+		{"multiple statements", "SELECT 1; DROP TABLE orders", "multiple SQL statements"},
+		{"modifying CTE", "WITH d AS (DELETE FROM orders RETURNING *) SELECT * FROM d", "data-modifying DELETE"},
+		{"select into", "SELECT * INTO copy FROM orders", "SELECT INTO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]interface{}{}
+			if tt.sql != nil {
+				args["sql"] = tt.sql
+			}
+			text := registeredToolError(t, tool, args)
+			if text == "" {
+				t.Fatal("Expected error response")
+			}
+			if !strings.Contains(text, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// erdSchema is the JSON form of export_schema's output
+type erdSchema struct {
+	Tables        []erdTable        `json:"tables"`
+	Relationships []erdRelationship `json:"relationships"`
+}
+
+type erdTable struct {
+	Schema      string      `json:"schema"`
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Description string      `json:"description,omitempty"`
+	Columns     []erdColumn `json:"columns"`
+	PrimaryKey  []string    `json:"primary_key,omitempty"`
+	UniqueKeys  [][]string  `json:"unique_keys,omitempty"`
+}
+
+type erdColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Nullable    bool   `json:"nullable"`
+	PrimaryKey  bool   `json:"primary_key,omitempty"`
+	Unique      bool   `json:"unique,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type erdRelationship struct {
+	Name        string   `json:"name"`
+	FromSchema  string   `json:"from_schema"`
+	FromTable   string   `json:"from_table"`
+	FromColumns []string `json:"from_columns"`
+	ToSchema    string   `json:"to_schema"`
+	ToTable     string   `json:"to_table"`
+	ToColumns   []string `json:"to_columns"`
+}
+
+// ExportSchemaTool creates the export_schema tool
+func ExportSchemaTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "export_schema",
+			Description: `Export the schema as an entity-relationship diagram: JSON, or Graphviz DOT to render with "dot -Tpng".
+
+<usecase>
+Use when:
+- The user wants a diagram of the database or one schema
+- Documenting a schema or onboarding someone to it
+- Another program needs the tables, columns and foreign keys in a
+  structured form
+</usecase>
+
+<when_not_to_use>
+- Understanding tables to write a query → use get_schema_info
+- Full detail of one table → use describe_table
+</when_not_to_use>
+
+<what_it_returns>
+- json: tables with their columns, types, nullability, primary and unique
+  keys, plus the foreign key relationships between them
+- dot: a Graphviz digraph with one node per table listing its columns
+  (PK/FK marked) and one edge per foreign key
+</what_it_returns>
+
+<important>
+- Built from the schema metadata loaded at startup; run refresh_metadata
+  first if tables were changed since
+- Large databases produce large output; use schema_name to export one
+  schema. Foreign keys to tables outside the export are left out
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"json", "dot"},
+						"description": "Output format: json or dot (Graphviz) (default: json)",
+						"default":     "json",
+					},
+					"schema_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Only export this schema (default: all schemas)",
+					},
+					"include_views": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Include views and materialized views (default: false)",
+						"default":     false,
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			format := ValidateOptionalStringParam(args, "format", "json")
+			if format != "json" && format != "dot" {
+				return mcp.NewToolError("Parameter 'format' must be json or dot")
+			}
+			schemaName := ValidateOptionalStringParam(args, "schema_name", "")
+			includeViews := ValidateBoolParam(args, "include_views", false)
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			schema := buildERDSchema(dbClient.GetMetadataFor(connStr), schemaName, includeViews)
+			if len(schema.Tables) == 0 {
+				if schemaName != "" {
+					return mcp.NewToolError(fmt.Sprintf("No tables found in schema %q", schemaName))
+				}
+				return mcp.NewToolError("No tables found")
+			}
+
+			var output string
+			if format == "dot" {
+				output = formatERDAsDOT(schema)
+			} else {
+				encoded, err := json.MarshalIndent(schema, "", "  ")
+				if err != nil {
+					return mcp.NewToolError(fmt.Sprintf("Failed to encode schema as JSON: %v", err))
+				}
+				output = string(encoded)
+			}
+
+			logging.Info("export_schema_executed",
+				"format", format,
+				"schema", schemaName,
+				"tables", len(schema.Tables),
+				"relationships", len(schema.Relationships),
+				"estimated_tokens", len(output)/4,
+			)
+
+			return mcp.NewToolSuccess(output)
+		},
+	}
+}
+
+// buildERDSchema collects the tables to export, sorted by schema and name,
+// and the foreign keys whose both ends are among them
+func buildERDSchema(metadata map[string]database.TableInfo, schemaName string, includeViews bool) erdSchema {
+	var tables []database.TableInfo
+	for _, table := range metadata {
+		if schemaName != "" && table.SchemaName != schemaName {
+			continue
+		}
+		if !includeViews && table.TableType != "TABLE" {
+			continue
+		}
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i].SchemaName != tables[j].SchemaName {
+			return tables[i].SchemaName < tables[j].SchemaName
+		}
+		return tables[i].TableName < tables[j].TableName
+	})
+
+	exported := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		exported[table.SchemaName+"."+table.TableName] = true
+	}
+
+	schema := erdSchema{
+		Tables:        make([]erdTable, 0, len(tables)),
+		Relationships: []erdRelationship{},
+	}
+	for _, table := range tables {
+		t := erdTable{
+			Schema:      table.SchemaName,
+			Name:        table.TableName,
+			Type:        strings.ToLower(table.TableType),
+			Description: table.Description,
+			Columns:     make([]erdColumn, 0, len(table.Columns)),
+			PrimaryKey:  table.PrimaryKey,
+			UniqueKeys:  table.UniqueKeys,
+		}
+		for _, col := range table.Columns {
+			t.Columns = append(t.Columns, erdColumn{
+				Name:        col.ColumnName,
+				Type:        col.DataType,
+				Nullable:    col.IsNullable == "YES",
+				PrimaryKey:  col.IsPrimaryKey,
+				Unique:      col.IsUnique,
+				Description: col.Description,
+			})
+		}
+		schema.Tables = append(schema.Tables, t)
+
+		for _, fk := range table.ForeignKeys {
+			if !exported[fk.ReferencedSchema+"."+fk.ReferencedTable] ||
+				len(fk.Columns) == 0 || len(fk.ReferencedColumns) == 0 {
+				continue
+			}
+			schema.Relationships = append(schema.Relationships, erdRelationship{
+				Name:        fk.ConstraintName,
+				FromSchema:  table.SchemaName,
+				FromTable:   table.TableName,
+				FromColumns: fk.Columns,
+				ToSchema:    fk.ReferencedSchema,
+				ToTable:     fk.ReferencedTable,
+				ToColumns:   fk.ReferencedColumns,
+			})
+		}
+	}
+	return schema
+}
+
+// formatERDAsDOT renders the schema as a Graphviz digraph. Each table is an
+// HTML-like table node with a port per column, so foreign key edges join
+// the referencing and referenced columns.
+func formatERDAsDOT(schema erdSchema) string {
+	fkColumns := make(map[string]bool)
+	for _, rel := range schema.Relationships {
+		for _, col := range rel.FromColumns {
+			fkColumns[rel.FromSchema+"."+rel.FromTable+"."+col] = true
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph schema {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=plaintext, fontname=\"Helvetica\", fontsize=10];\n")
+	sb.WriteString("  edge [arrowhead=crow, arrowtail=none, fontname=\"Helvetica\", fontsize=8];\n")
+
+	for _, table := range schema.Tables {
+		sb.WriteString(fmt.Sprintf("\n  %s [label=<\n", dotID(table.Schema+"."+table.Name)))
+		sb.WriteString("    <table border=\"0\" cellborder=\"1\" cellspacing=\"0\" cellpadding=\"4\">\n")
+		header := dotHTML(table.Schema + "." + table.Name)
+		if table.Type != "table" {
+			header += " <i>(" + dotHTML(table.Type) + ")</i>"
+		}
+		sb.WriteString(fmt.Sprintf("      <tr><td colspan=\"2\" bgcolor=\"lightgrey\"><b>%s</b></td></tr>\n", header))
+		for _, col := range table.Columns {
+			var marks []string
+			if col.PrimaryKey {
+				marks = append(marks, "PK")
+			}
+			if fkColumns[table.Schema+"."+table.Name+"."+col.Name] {
+				marks = append(marks, "FK")
+			}
+			name := dotHTML(col.Name)
+			if col.PrimaryKey {
+				name = "<u>" + name + "</u>"
+			}
+			if len(marks) > 0 {
+				name += " " + strings.Join(marks, ",")
+			}
+			colType := dotHTML(col.Type)
+			if !col.Nullable {
+				colType += " NOT NULL"
+			}
+			sb.WriteString(fmt.Sprintf("      <tr><td port=%s align=\"left\">%s</td><td align=\"left\">%s</td></tr>\n",
+				dotID(col.Name), name, colType))
+		}
+		sb.WriteString("    </table>\n  >];\n")
+	}
+
+	if len(schema.Relationships) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, rel := range schema.Relationships {
+		// Multi-column keys are drawn from their first column
+		from := dotID(rel.FromSchema+"."+rel.FromTable) + ":" + dotID(rel.FromColumns[0])
+		to := dotID(rel.ToSchema+"."+rel.ToTable) + ":" + dotID(rel.ToColumns[0])
+		sb.WriteString(fmt.Sprintf("  %s -> %s [label=%s];\n", from, to, dotID(rel.Name)))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// dotID quotes an identifier for DOT
+func dotID(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// dotHTML escapes text for a DOT HTML-like label
+func dotHTML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
@@ -0,0 +1,175 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Export Schema Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/database"
+)
+
+func exportSchemaTestMetadata() map[string]database.TableInfo {
+	return map[string]database.TableInfo{
+		"public.orders": {
+			SchemaName: "public",
+			TableName:  "orders",
+			TableType:  "TABLE",
+			Columns: []database.ColumnInfo{
+				{ColumnName: "id", DataType: "bigint", IsNullable: "NO", IsPrimaryKey: true},
+				{ColumnName: "customer_id", DataType: "bigint", IsNullable: "NO"},
+				{ColumnName: "note", DataType: "text", IsNullable: "YES"},
+			},
+			PrimaryKey: []string{"id"},
+			ForeignKeys: []database.ForeignKeyInfo{
+				{
+					ConstraintName:    "orders_customer_id_fkey",
+					Columns:           []string{"customer_id"},
+					ReferencedSchema:  "public",
+					ReferencedTable:   "customers",
+					ReferencedColumns: []string{"id"},
+				},
+				{
+					ConstraintName:    "orders_region_fkey",
+					Columns:           []string{"note"},
+					ReferencedSchema:  "ref",
+					ReferencedTable:   "regions",
+					ReferencedColumns: []string{"code"},
+				},
+			},
+		},
+		"public.customers": {
+			SchemaName: "public",
+			TableName:  "customers",
+			TableType:  "TABLE",
+			Columns: []database.ColumnInfo{
+				{ColumnName: "id", DataType: "bigint", IsNullable: "NO", IsPrimaryKey: true},
+				{ColumnName: "email", DataType: "text", IsNullable: "NO", IsUnique: true},
+			},
+			PrimaryKey: []string{"id"},
+			UniqueKeys: [][]string{{"email"}},
+		},
+		"public.order_totals": {
+			SchemaName: "public",
+			TableName:  "order_totals",
+			TableType:  "VIEW",
+			Columns: []database.ColumnInfo{
+				{ColumnName: "total", DataType: "numeric", IsNullable: "YES"},
+			},
+		},
+		"ref.regions": {
+			SchemaName: "ref",
+			TableName:  "regions",
+			TableType:  "TABLE",
+			Columns: []database.ColumnInfo{
+				{ColumnName: "code", DataType: "text", IsNullable: "NO", IsPrimaryKey: true},
+			},
+		},
+	}
+}
+
+func TestExportSchemaToolDefinition(t *testing.T) {
+	tool := ExportSchemaTool(nil)
+
+	if tool.Definition.Name != "export_schema" {
+		t.Errorf("Tool name = %v, want export_schema", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"format", "schema_name", "include_views"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+
+	if len(tool.Definition.InputSchema.Required) != 0 {
+		t.Errorf("Required = %v, want none", tool.Definition.InputSchema.Required)
+	}
+}
+
+func TestExportSchemaToolValidation(t *testing.T) {
+	tool := ExportSchemaTool(nil)
+
+	response, err := tool.Handler(map[string]interface{}{"format": "svg"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !response.IsError || !strings.Contains(response.Content[0].Text, "must be json or dot") {
+		t.Errorf("Expected format error, got %+v", response)
+	}
+}
+
+func TestBuildERDSchema(t *testing.T) {
+	schema := buildERDSchema(exportSchemaTestMetadata(), "", false)
+
+	var names []string
+	for _, table := range schema.Tables {
+		names = append(names, table.Schema+"."+table.Name)
+	}
+	if got := strings.Join(names, ","); got != "public.customers,public.orders,ref.regions" {
+		t.Errorf("Tables = %s", got)
+	}
+	if len(schema.Relationships) != 2 {
+		t.Fatalf("Relationships = %+v, want 2", schema.Relationships)
+	}
+
+	// Restricting to one schema drops the foreign key into ref
+	schema = buildERDSchema(exportSchemaTestMetadata(), "public", true)
+	if len(schema.Tables) != 3 {
+		t.Errorf("Tables = %d, want 3 with views", len(schema.Tables))
+	}
+	if len(schema.Relationships) != 1 || schema.Relationships[0].Name != "orders_customer_id_fkey" {
+		t.Errorf("Relationships = %+v, want only orders_customer_id_fkey", schema.Relationships)
+	}
+
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for _, want := range []string{
+		`"name":"email","type":"text","nullable":false,"unique":true`,
+		`"from_table":"orders","from_columns":["customer_id"],"to_schema":"public","to_table":"customers","to_columns":["id"]`,
+		`"type":"view"`,
+	} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("JSON missing %s:\n%s", want, encoded)
+		}
+	}
+}
+
+func TestFormatERDAsDOT(t *testing.T) {
+	out := formatERDAsDOT(buildERDSchema(exportSchemaTestMetadata(), "public", true))
+
+	for _, want := range []string{
+		"digraph schema {\n",
+		`"public.orders" [label=<`,
+		`<td port="id" align="left"><u>id</u> PK</td><td align="left">bigint NOT NULL</td>`,
+		`<td port="customer_id" align="left">customer_id FK</td>`,
+		`<b>public.order_totals <i>(view)</i></b>`,
+		`"public.orders":"customer_id" -> "public.customers":"id" [label="orders_customer_id_fkey"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT missing %q:\n%s", want, out)
+		}
+	}
+	if !strings.HasSuffix(out, "}\n") {
+		t.Errorf("DOT not closed:\n%s", out)
+	}
+}
+
+func TestDOTEscaping(t *testing.T) {
+	if got := dotID(`a"b\c`); got != `"a\"b\\c"` {
+		t.Errorf("dotID = %s", got)
+	}
+	if got := dotHTML(`x<y & "z">`); got != "x&lt;y &amp; &quot;z&quot;&gt;" {
+		t.Errorf("dotHTML = %s", got)
+	}
+}
@@ -0,0 +1,229 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Suggested actions for an invalid index
+const (
+	invalidIndexWait    = "wait"    // A concurrent build is still running
+	invalidIndexDrop    = "drop"    // Leftover copy from an interrupted REINDEX CONCURRENTLY
+	invalidIndexReindex = "reindex" // Rebuild in place (keeps constraints working)
+	invalidIndexRebuild = "rebuild" // Drop and recreate from the definition
+)
+
+// FindInvalidIndexesTool creates the find_invalid_indexes tool
+func FindInvalidIndexesTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "find_invalid_indexes",
+			Description: `Find indexes left INVALID or not ready, typically by an interrupted CREATE INDEX CONCURRENTLY or REINDEX CONCURRENTLY. Read-only: suggests cleanup SQL but never runs it.
+
+<usecase>
+Use when:
+- A concurrent index build failed, was cancelled, or the server restarted during one
+- Cleaning up wasted space from indexes the planner never uses
+- A query is not using an index that "should" exist
+</usecase>
+
+<what_it_returns>
+Per invalid index:
+- Schema, table, index name and size
+- Status: invalid (built but not valid) or not_ready (build did not finish)
+- Whether a build is still in progress (from pg_stat_progress_create_index)
+- Suggested action (wait, drop, reindex or rebuild) and the SQL to run it
+</what_it_returns>
+
+<important>
+- This tool does NOT modify anything; review and run the suggested SQL yourself
+- Invalid indexes are not used by queries but are still maintained on every write
+- An index being built concurrently is also invalid until the build finishes;
+  those are reported with action "wait" and must not be dropped
+- REINDEX CONCURRENTLY leaves *_ccnew / *_ccold indexes behind when it fails
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"schema_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Only report indexes in this schema (default: all user schemas)",
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			schemaName := ValidateOptionalStringParam(args, "schema_name", "")
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+
+			var results [][]interface{}
+			var statements []string
+			counts := make(map[string]int)
+
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				rows, err := tx.Query(ctx, `
+					SELECT n.nspname, t.relname, i.relname,
+					       ix.indisready,
+					       pg_relation_size(i.oid),
+					       pg_get_indexdef(i.oid),
+					       EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = i.oid),
+					       EXISTS (SELECT 1 FROM pg_stat_progress_create_index p WHERE p.index_relid = i.oid)
+					FROM pg_index ix
+					JOIN pg_class i ON i.oid = ix.indexrelid
+					JOIN pg_class t ON t.oid = ix.indrelid
+					JOIN pg_namespace n ON n.oid = i.relnamespace
+					WHERE (NOT ix.indisvalid OR NOT ix.indisready)
+					  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+					  AND n.nspname NOT LIKE 'pg_toast%'
+					  AND ($1 = '' OR n.nspname = $1)
+					ORDER BY n.nspname, t.relname, i.relname`, schemaName)
+				if err != nil {
+					return fmt.Errorf("failed to query invalid indexes: %w", err)
+				}
+				defer rows.Close()
+
+				for rows.Next() {
+					var schema, table, index, indexDef string
+					var ready, backsConstraint, inProgress bool
+					var size int64
+					if err := rows.Scan(&schema, &table, &index, &ready, &size, &indexDef,
+						&backsConstraint, &inProgress); err != nil {
+						return fmt.Errorf("failed to scan invalid index: %w", err)
+					}
+
+					status := "invalid"
+					if !ready {
+						status = "not_ready"
+					}
+
+					action := invalidIndexAction(index, inProgress, backsConstraint)
+					counts[action]++
+					if sql := invalidIndexSQL(schema, index, indexDef, action); sql != "" {
+						statements = append(statements, sql)
+					}
+
+					results = append(results, []interface{}{
+						schema, table, index, status, inProgress, formatBytes(size), action,
+					})
+				}
+				return rows.Err()
+			})
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Error finding invalid indexes: %v", err))
+			}
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+
+			if len(results) == 0 {
+				sb.WriteString("No invalid indexes found")
+				if schemaName != "" {
+					sb.WriteString(fmt.Sprintf(" in schema '%s'", schemaName))
+				}
+				sb.WriteString(".\n")
+				return mcp.NewToolSuccess(sb.String())
+			}
+
+			sb.WriteString(fmt.Sprintf("Invalid indexes (%d):\n", len(results)))
+			sb.WriteString(FormatResultsAsTSV(
+				[]string{"schema", "table", "index", "status", "build_in_progress", "size", "action"},
+				results))
+			sb.WriteString("\n")
+
+			if counts[invalidIndexWait] > 0 {
+				sb.WriteString(fmt.Sprintf("\n%d index(es) are still being built concurrently; do not drop them. Run this tool again after the build finishes.\n",
+					counts[invalidIndexWait]))
+			}
+
+			if len(statements) > 0 {
+				sb.WriteString("\nSuggested cleanup SQL (NOT executed; review before running, one statement at a time):\n")
+				sb.WriteString("<suggested_sql>\n")
+				for _, stmt := range statements {
+					sb.WriteString(stmt + "\n")
+				}
+				sb.WriteString("</suggested_sql>\n")
+				sb.WriteString("\nCONCURRENTLY statements cannot run inside a transaction block.\n")
+			}
+
+			logging.Info("find_invalid_indexes_executed",
+				"schema", schemaName,
+				"invalid_indexes", len(results),
+				"in_progress", counts[invalidIndexWait],
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// invalidIndexAction picks the cleanup action for an invalid index
+func invalidIndexAction(indexName string, inProgress, backsConstraint bool) string {
+	switch {
+	case inProgress:
+		return invalidIndexWait
+	case isReindexLeftover(indexName):
+		return invalidIndexDrop
+	case backsConstraint:
+		// Dropping would remove the constraint's index; rebuild it in place
+		return invalidIndexReindex
+	default:
+		return invalidIndexRebuild
+	}
+}
+
+// isReindexLeftover reports whether the index name is one of the transient
+// copies REINDEX CONCURRENTLY creates (name_ccnew, name_ccold, name_ccnew1, ...)
+func isReindexLeftover(indexName string) bool {
+	i := strings.LastIndex(indexName, "_cc")
+	if i < 0 {
+		return false
+	}
+	suffix := strings.TrimRight(indexName[i+3:], "0123456789")
+	return suffix == "new" || suffix == "old"
+}
+
+// invalidIndexSQL returns the suggested SQL for an action, or "" when the
+// index should be left alone
+func invalidIndexSQL(schema, index, indexDef, action string) string {
+	qualified := quoteIdentifier(schema) + "." + quoteIdentifier(index)
+	switch action {
+	case invalidIndexDrop:
+		return fmt.Sprintf("DROP INDEX CONCURRENTLY %s;", qualified)
+	case invalidIndexReindex:
+		return fmt.Sprintf("REINDEX INDEX CONCURRENTLY %s;", qualified)
+	case invalidIndexRebuild:
+		// pg_get_indexdef omits CONCURRENTLY; add it so the rebuild doesn't
+		// block writes
+		createSQL := strings.Replace(indexDef, " INDEX ", " INDEX CONCURRENTLY ", 1)
+		return fmt.Sprintf("DROP INDEX CONCURRENTLY %s;\n%s;", qualified, createSQL)
+	default:
+		return ""
+	}
+}
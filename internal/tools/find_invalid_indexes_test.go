@@ -0,0 +1,77 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Find Invalid Indexes Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"testing"
+)
+
+func TestFindInvalidIndexesToolDefinition(t *testing.T) {
+	tool := FindInvalidIndexesTool(nil)
+
+	if tool.Definition.Name != "find_invalid_indexes" {
+		t.Errorf("Tool name = %v, want find_invalid_indexes", tool.Definition.Name)
+	}
+
+	if _, exists := tool.Definition.InputSchema.Properties["schema_name"]; !exists {
+		t.Error("Missing property: schema_name")
+	}
+}
+
+func TestInvalidIndexAction(t *testing.T) {
+	tests := []struct {
+		name            string
+		index           string
+		inProgress      bool
+		backsConstraint bool
+		expected        string
+	}{
+		{"build in progress", "orders_customer_idx", true, false, invalidIndexWait},
+		{"in-progress reindex copy", "orders_pkey_ccnew", true, true, invalidIndexWait},
+		{"failed reindex copy", "orders_customer_idx_ccnew", false, false, invalidIndexDrop},
+		{"numbered reindex copy", "orders_customer_idx_ccold2", false, false, invalidIndexDrop},
+		{"failed constraint index", "orders_email_key", false, true, invalidIndexReindex},
+		{"failed concurrent build", "orders_customer_idx", false, false, invalidIndexRebuild},
+		{"name containing _cc", "users_cc_number_idx", false, false, invalidIndexRebuild},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := invalidIndexAction(tt.index, tt.inProgress, tt.backsConstraint); got != tt.expected {
+				t.Errorf("invalidIndexAction() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInvalidIndexSQL(t *testing.T) {
+	def := "CREATE INDEX orders_customer_idx ON public.orders USING btree (customer_id)"
+
+	tests := []struct {
+		name     string
+		action   string
+		expected string
+	}{
+		{"wait", invalidIndexWait, ""},
+		{"drop", invalidIndexDrop, `DROP INDEX CONCURRENTLY "public"."orders_customer_idx";`},
+		{"reindex", invalidIndexReindex, `REINDEX INDEX CONCURRENTLY "public"."orders_customer_idx";`},
+		{"rebuild", invalidIndexRebuild, `DROP INDEX CONCURRENTLY "public"."orders_customer_idx";` + "\n" +
+			"CREATE INDEX CONCURRENTLY orders_customer_idx ON public.orders USING btree (customer_id);"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := invalidIndexSQL("public", "orders_customer_idx", def, tt.action); got != tt.expected {
+				t.Errorf("invalidIndexSQL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// defaultLargeValuesLimit is the number of rows reported when no limit is given
+	defaultLargeValuesLimit = 10
+
+	// maxLargeValuesLimit caps the rows a single call may report
+	maxLargeValuesLimit = 100
+
+	// maxLargeValuesScanRows bounds the scan; larger tables are read through
+	// TABLESAMPLE SYSTEM sized to about this many rows
+	maxLargeValuesScanRows = 1000000
+
+	// toastCandidateBytes is the stored size above which a value is
+	// normally compressed or moved out of line by TOAST
+	toastCandidateBytes = 2000
+)
+
+// FindLargeValuesTool creates the find_large_values tool
+func FindLargeValuesTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "find_large_values",
+			Description: `Find the rows with the largest values in a text, bytea, JSON or array column.
+
+<usecase>
+Use when:
+- Investigating TOAST bloat or a table much larger than its row count suggests
+- Finding the outsized documents or blobs that make some reads slow
+- Checking how many values are large enough to be TOASTed
+</usecase>
+
+<what_it_returns>
+The row identifiers (primary key columns, or ctid without a primary key) of
+the largest values in the column, with each value's stored size (after
+compression, from pg_column_size) and raw size (octet_length), plus how many
+values were scanned and how many exceed 2000 bytes.
+</what_it_returns>
+
+<important>
+- Only variable-length column types are accepted (text, varchar, bytea,
+  json, jsonb, xml, arrays, vector)
+- Tables with more than about 1,000,000 estimated rows are sampled with
+  TABLESAMPLE SYSTEM, so the results are the largest values found in the
+  sample rather than in the whole table
+- The scan is cancelled at the database's query_timeout
+- Values themselves are never returned; use query_database with the row
+  identifiers to inspect them
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"table_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Table to scan, as 'schema.table' or 'table' (public schema)",
+					},
+					"column_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Variable-length column to measure (text, varchar, bytea, json, jsonb, xml, array or vector)",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of rows to report (default: 10, maximum: 100)",
+						"default":     defaultLargeValuesLimit,
+						"minimum":     1,
+						"maximum":     maxLargeValuesLimit,
+					},
+				},
+				Required: []string{"table_name", "column_name"},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			tableName, _ := args["table_name"].(string)
+			columnName, _ := args["column_name"].(string)
+
+			limit := defaultLargeValuesLimit
+			if val, ok := args["limit"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'limit' must be a positive integer")
+				}
+				limit = int(val)
+			}
+			if limit > maxLargeValuesLimit {
+				limit = maxLargeValuesLimit
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			metadata := dbClient.GetMetadata()
+			tableInfo, err := findTableInMetadataMap(metadata, tableName)
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Table '%s' not found.\n\n<available_tables>\n%s</available_tables>",
+					tableName, listAvailableTables(metadata)))
+			}
+			if tableInfo.TableType == "VIEW" {
+				return mcp.NewToolError(fmt.Sprintf("%s.%s is a view; find_large_values only scans tables and materialized views",
+					tableInfo.SchemaName, tableInfo.TableName))
+			}
+
+			var column *database.ColumnInfo
+			var keyCols []string
+			for i := range tableInfo.Columns {
+				if tableInfo.Columns[i].ColumnName == columnName {
+					column = &tableInfo.Columns[i]
+				}
+				if tableInfo.Columns[i].IsPrimaryKey {
+					keyCols = append(keyCols, tableInfo.Columns[i].ColumnName)
+				}
+			}
+			if column == nil {
+				return mcp.NewToolError(fmt.Sprintf("Column '%s' not found in %s.%s",
+					columnName, tableInfo.SchemaName, tableInfo.TableName))
+			}
+			if !isLargeValueType(column.DataType) {
+				return mcp.NewToolError(fmt.Sprintf("Column '%s' has type %s, which is fixed-length and cannot hold large values. "+
+					"Choose a text, varchar, bytea, json, jsonb, xml, array or vector column.", columnName, column.DataType))
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			qualifiedName := quoteIdentifier(tableInfo.SchemaName) + "." + quoteIdentifier(tableInfo.TableName)
+
+			idCols := keyCols
+			if len(idCols) == 0 {
+				idCols = []string{"ctid"}
+			}
+
+			timeout := dbClient.QueryTimeout()
+			ctx, cancel := withQueryTimeout(requestContext(args), timeout)
+			defer cancel()
+
+			var results [][]interface{}
+			var scanned, overThreshold int64
+			estimatedRows := -1.0
+			samplePct := 100.0
+			err = executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				if err := setStatementTimeout(ctx, tx, timeout); err != nil {
+					return err
+				}
+
+				if err := tx.QueryRow(ctx, "SELECT reltuples::float8 FROM pg_class WHERE oid = $1::regclass",
+					qualifiedName).Scan(&estimatedRows); err != nil {
+					return fmt.Errorf("failed to estimate table size: %w", err)
+				}
+				samplePct = largeValuesScanPercent(estimatedRows)
+
+				rows, err := tx.Query(ctx, buildLargeValuesQuery(qualifiedName, idCols, columnName, column.DataType, samplePct, limit))
+				if err != nil {
+					return err
+				}
+				defer rows.Close()
+
+				for rows.Next() {
+					values, err := rows.Values()
+					if err != nil {
+						return fmt.Errorf("failed to read row: %w", err)
+					}
+					// The trailing window counts are the same on every row
+					n := len(values)
+					scanned, _ = values[n-2].(int64)
+					overThreshold, _ = values[n-1].(int64)
+					results = append(results, values[:n-2])
+				}
+				return rows.Err()
+			})
+			if err != nil {
+				if isQueryTimeout(ctx, err) {
+					return mcp.NewToolError(queryTimeoutMessage(timeout))
+				}
+				return mcp.NewToolError(fmt.Sprintf("Error scanning %s.%s: %v", tableInfo.SchemaName, tableInfo.TableName, err))
+			}
+
+			redactLargeValueIDs(results, dbClient.Redactor(), tableInfo.SchemaName, tableInfo.TableName, idCols)
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(fmt.Sprintf("Table: %s.%s\n", tableInfo.SchemaName, tableInfo.TableName))
+			sb.WriteString(fmt.Sprintf("Column: %s (%s)\n", columnName, column.DataType))
+			if samplePct < 100 {
+				sb.WriteString(fmt.Sprintf("Scanned: %d non-null values from TABLESAMPLE SYSTEM (%s) of about %.0f rows\n",
+					scanned, strconv.FormatFloat(samplePct, 'f', -1, 64), estimatedRows))
+			} else {
+				sb.WriteString(fmt.Sprintf("Scanned: %d non-null values (whole table)\n", scanned))
+			}
+			sb.WriteString(fmt.Sprintf("Values over %d bytes stored (TOAST candidates): %d\n\n", toastCandidateBytes, overThreshold))
+
+			if len(results) == 0 {
+				sb.WriteString("No non-null values found.\n")
+			} else {
+				header := append(append([]string{}, idCols...), "stored_bytes", "raw_bytes")
+				sb.WriteString(fmt.Sprintf("Largest values (%d):\n", len(results)))
+				sb.WriteString(FormatResultsAsTSV(header, results))
+				sb.WriteString("\n")
+				if len(keyCols) == 0 {
+					sb.WriteString("\nNote: the table has no primary key, so rows are identified by ctid, which changes when a row is updated or the table is rewritten.\n")
+				}
+			}
+
+			logging.Info("find_large_values_executed",
+				"table", tableInfo.SchemaName+"."+tableInfo.TableName,
+				"column", columnName,
+				"limit", limit,
+				"sample_percent", samplePct,
+				"scanned", scanned,
+				"rows", len(results),
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// isLargeValueType reports whether a column type (as printed by
+// format_type) is variable-length and so can hold values large enough to
+// be TOASTed
+func isLargeValueType(dataType string) bool {
+	if strings.HasSuffix(dataType, "[]") {
+		return true
+	}
+	base, _, _ := strings.Cut(dataType, "(")
+	switch base {
+	case "text", "character varying", "bytea", "json", "jsonb", "xml", "tsvector",
+		"vector", "halfvec", "sparsevec":
+		return true
+	}
+	return false
+}
+
+// largeValuesScanPercent returns the TABLESAMPLE SYSTEM percentage that
+// keeps a scan to about maxLargeValuesScanRows rows, or 100 to scan the
+// whole table
+func largeValuesScanPercent(estimatedRows float64) float64 {
+	if estimatedRows <= maxLargeValuesScanRows {
+		return 100
+	}
+	return roundUpPercent(maxLargeValuesScanRows / estimatedRows * 100)
+}
+
+// redactLargeValueIDs replaces the values of key columns matching
+// redact_columns in rows of idCols followed by the two size columns
+func redactLargeValueIDs(rows [][]interface{}, redactor *database.ColumnRedactor, schemaName, tableName string, idCols []string) {
+	redacted := make([]bool, len(idCols))
+	masked := false
+	for i, col := range idCols {
+		redacted[i] = redactor.Matches(schemaName, tableName, col)
+		masked = masked || redacted[i]
+	}
+	if !masked {
+		return
+	}
+	for _, row := range rows {
+		database.RedactRow(row, redacted)
+	}
+}
+
+// buildLargeValuesQuery builds the query returning the identifier columns,
+// stored and raw sizes of the largest values in a column, followed by the
+// count of non-null values scanned and the count over toastCandidateBytes.
+// qualifiedName must already be quoted; idCols and column are quoted here.
+func buildLargeValuesQuery(qualifiedName string, idCols []string, column, dataType string, samplePct float64, limit int) string {
+	selectList := make([]string, len(idCols))
+	for i, col := range idCols {
+		if col == "ctid" {
+			selectList[i] = "ctid::text AS ctid"
+		} else {
+			selectList[i] = quoteIdentifier(col)
+		}
+	}
+
+	col := quoteIdentifier(column)
+	rawSize := fmt.Sprintf("octet_length(%s::text)", col)
+	if dataType == "bytea" {
+		rawSize = fmt.Sprintf("octet_length(%s)", col)
+	}
+
+	from := qualifiedName
+	if samplePct < 100 {
+		from += fmt.Sprintf(" TABLESAMPLE SYSTEM (%s)", strconv.FormatFloat(samplePct, 'f', -1, 64))
+	}
+
+	return fmt.Sprintf(`SELECT %s, pg_column_size(%s) AS stored_bytes, %s AS raw_bytes,
+	count(*) OVER () AS scanned,
+	count(*) FILTER (WHERE pg_column_size(%s) > %d) OVER () AS over_threshold
+FROM %s
+WHERE %s IS NOT NULL
+ORDER BY pg_column_size(%s) DESC
+LIMIT %d`, strings.Join(selectList, ", "), col, rawSize, col, toastCandidateBytes, from, col, col, limit)
+}
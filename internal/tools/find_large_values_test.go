@@ -0,0 +1,139 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Find Large Values Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/database"
+)
+
+func TestFindLargeValuesToolDefinition(t *testing.T) {
+	tool := FindLargeValuesTool(nil)
+
+	if tool.Definition.Name != "find_large_values" {
+		t.Errorf("Tool name = %v, want find_large_values", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"table_name", "column_name", "limit"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+}
+
+func TestFindLargeValuesValidation(t *testing.T) {
+	connStr := "postgres://localhost/test"
+	client := database.NewTestClient(connStr, map[string]database.TableInfo{
+		"public.documents": {
+			SchemaName: "public",
+			TableName:  "documents",
+			TableType:  "TABLE",
+			Columns: []database.ColumnInfo{
+				{ColumnName: "id", DataType: "integer", IsPrimaryKey: true},
+				{ColumnName: "body", DataType: "jsonb"},
+			},
+		},
+		"public.recent_documents": {
+			SchemaName: "public",
+			TableName:  "recent_documents",
+			TableType:  "VIEW",
+			Columns:    []database.ColumnInfo{{ColumnName: "body", DataType: "jsonb"}},
+		},
+	})
+	tool := FindLargeValuesTool(client)
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"missing column", map[string]interface{}{"table_name": "documents"}, "column_name"},
+		{"invalid limit", map[string]interface{}{"table_name": "documents", "column_name": "body", "limit": float64(0)}, "limit"},
+		{"unknown table", map[string]interface{}{"table_name": "nope", "column_name": "body"}, "not found"},
+		{"unknown column", map[string]interface{}{"table_name": "documents", "column_name": "nope"}, "not found"},
+		{"fixed-length column", map[string]interface{}{"table_name": "documents", "column_name": "id"}, "fixed-length"},
+		{"view", map[string]interface{}{"table_name": "recent_documents", "column_name": "body"}, "is a view"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := registeredToolError(t, tool, tt.args)
+			if text == "" {
+				t.Fatal("Expected error response")
+			}
+			if !strings.Contains(text, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, text)
+			}
+		})
+	}
+}
+
+func TestIsLargeValueType(t *testing.T) {
+	for _, dataType := range []string{"text", "character varying(255)", "bytea", "json", "jsonb", "xml",
+		"integer[]", "vector(1536)", "halfvec(768)"} {
+		if !isLargeValueType(dataType) {
+			t.Errorf("isLargeValueType(%q) = false, want true", dataType)
+		}
+	}
+	for _, dataType := range []string{"integer", "bigint", "timestamp with time zone", "uuid", "boolean", "numeric(10,2)"} {
+		if isLargeValueType(dataType) {
+			t.Errorf("isLargeValueType(%q) = true, want false", dataType)
+		}
+	}
+}
+
+func TestLargeValuesScanPercent(t *testing.T) {
+	if got := largeValuesScanPercent(-1); got != 100 {
+		t.Errorf("largeValuesScanPercent(-1) = %v, want 100", got)
+	}
+	if got := largeValuesScanPercent(500000); got != 100 {
+		t.Errorf("largeValuesScanPercent(500000) = %v, want 100", got)
+	}
+	if got := largeValuesScanPercent(4000000); got != 25 {
+		t.Errorf("largeValuesScanPercent(4000000) = %v, want 25", got)
+	}
+}
+
+func TestBuildLargeValuesQuery(t *testing.T) {
+	query := buildLargeValuesQuery(`"public"."documents"`, []string{"id"}, "body", "jsonb", 100, 10)
+	for _, want := range []string{`SELECT "id", pg_column_size("body")`, `octet_length("body"::text)`,
+		`WHERE "body" IS NOT NULL`, "LIMIT 10"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("Query missing %q:\n%s", want, query)
+		}
+	}
+	if strings.Contains(query, "TABLESAMPLE") {
+		t.Errorf("Unexpected TABLESAMPLE in full scan:\n%s", query)
+	}
+
+	query = buildLargeValuesQuery(`"public"."files"`, []string{"ctid"}, "data", "bytea", 2.5, 5)
+	for _, want := range []string{"ctid::text AS ctid", `octet_length("data")`, "TABLESAMPLE SYSTEM (2.5)"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("Query missing %q:\n%s", want, query)
+		}
+	}
+}
+
+func TestRedactLargeValueIDs(t *testing.T) {
+	rows := [][]interface{}{
+		{"123-45-6789", int32(7), int64(9000), int64(12000)},
+	}
+	redactor := database.NewColumnRedactor([]string{"people.ssn"})
+	redactLargeValueIDs(rows, redactor, "public", "people", []string{"ssn", "id"})
+	if rows[0][0] != database.RedactedValue {
+		t.Errorf("Expected ssn key to be redacted, got %v", rows[0][0])
+	}
+	if rows[0][1] != int32(7) || rows[0][2] != int64(9000) || rows[0][3] != int64(12000) {
+		t.Errorf("Expected other columns to be kept, got %v", rows[0])
+	}
+}
@@ -11,7 +11,6 @@
 package tools
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -70,7 +69,7 @@ func GenerateEmbeddingTool(cfg *config.Config) Tool {
 			}
 
 			// Generate embedding
-			ctx := context.Background()
+			ctx := requestContext(args)
 			vector, err := provider.Embed(ctx, text)
 			if err != nil {
 				return mcp.NewToolError(fmt.Sprintf("Failed to generate embedding: %v", err))
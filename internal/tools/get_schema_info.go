@@ -11,13 +11,21 @@
 package tools
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"pgedge-postgres-mcp/internal/database"
 	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
 )
 
+// defaultSchemaInfoPageSize is the number of tables returned per page of
+// detailed or compact output
+const defaultSchemaInfoPageSize = 50
+
 // GetSchemaInfoTool creates the get_schema_info tool
 func GetSchemaInfoTool(dbClient *database.Client) Tool {
 	return Tool{
@@ -51,6 +59,10 @@ Returns comprehensive information in TSV format (one row per column):
 - Column names, data types, nullable status
 - Primary key (is_pk) and unique constraint (is_unique) indicators
 - Foreign key references (fk_ref) in format "schema.table.column"
+- A Keys section after the rows listing each table's PRIMARY KEY and UNIQUE
+  column sets, so composite keys are visible as a whole
+- A Foreign Keys section listing each table's constraints as
+  "(columns) -> schema.table(columns)", including multi-column keys
 - Index membership (is_indexed) for query optimization hints
 - Identity columns (identity): "a" for ALWAYS, "d" for BY DEFAULT, empty if not identity
 - Default values for columns (default)
@@ -65,8 +77,19 @@ Returns comprehensive information in TSV format (one row per column):
 - table_name="users" (with schema_name): Get columns for specific table only
 - vector_tables_only=true: Show only tables with pgvector columns (reduces output 10x)
 - compact=true: Return table names only (no column details)
+- include_indexes=true: Add an Indexes section with each table's index names,
+  access method, indexed columns, uniqueness and partial index predicate
 </filtering_options>
 
+<pagination>
+Detailed and compact output is paged by table, sorted by schema then table:
+- limit: tables per page (default 50)
+- offset: tables to skip (default 0)
+A footer gives the total table count and the call for the next page. If the
+server sets a size limit, a page may end early with a [TRUNCATED] marker; the
+footer's next-page call continues from the first table left out.
+</pagination>
+
 <auto_summary_mode>
 When called without filters on databases with >10 tables, automatically returns
 a compact summary showing:
@@ -84,6 +107,7 @@ filter to get full details for specific schemas.
 ✓ "Show me tables with vector columns" → get_schema_info(vector_tables_only=true)
 ✓ "What's in the public schema?" → get_schema_info(schema_name="public")
 ✓ "Show me the users table structure" → get_schema_info(schema_name="public", table_name="users")
+✓ "Which filters on orders can use an index?" → get_schema_info(schema_name="public", table_name="orders", include_indexes=true)
 ✓ Before writing: "SELECT * FROM users..." → get_schema_info() first to confirm 'users' table exists
 </examples>
 
@@ -123,6 +147,23 @@ To avoid rate limits when calling this tool:
 						"description": "Optional: if true, return table names only (no column details). Use for quick overview.",
 						"default":     false,
 					},
+					"include_indexes": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Optional: if true, also list each table's indexes with their columns and uniqueness. Ignored with compact=true.",
+						"default":     false,
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Optional: maximum number of tables to return per page (default: 50).",
+						"default":     defaultSchemaInfoPageSize,
+						"minimum":     1,
+					},
+					"offset": map[string]interface{}{
+						"type":        "integer",
+						"description": "Optional: number of tables to skip, for fetching the next page (default: 0).",
+						"default":     0,
+						"minimum":     0,
+					},
 				},
 			},
 		},
@@ -157,6 +198,30 @@ To avoid rate limits when calling this tool:
 				compactMode = false
 			}
 
+			includeIndexes := false
+			if val, ok := args["include_indexes"].(bool); ok {
+				includeIndexes = val && !compactMode
+			}
+
+			// Paging; asking for a specific page also turns off auto-summary
+			limit := defaultSchemaInfoPageSize
+			offset := 0
+			pageRequested := false
+			if val, ok := args["limit"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'limit' must be a positive integer")
+				}
+				limit = int(val)
+				pageRequested = true
+			}
+			if val, ok := args["offset"].(float64); ok {
+				if val < 0 {
+					return mcp.NewToolError("Parameter 'offset' must be a non-negative integer")
+				}
+				offset = int(val)
+				pageRequested = true
+			}
+
 			// Check if metadata is loaded
 			if !dbClient.IsMetadataLoaded() {
 				return mcp.NewToolError(mcp.DatabaseNotReadyError)
@@ -173,7 +238,7 @@ To avoid rate limits when calling this tool:
 				vectorTables []string
 			}
 			schemaMap := make(map[string]*schemaStats)
-			totalMatched := 0
+			var matched []database.TableInfo
 
 			for _, table := range metadata {
 				// Filter by schema if requested
@@ -186,25 +251,31 @@ To avoid rate limits when calling this tool:
 					continue
 				}
 
-				// Check for vector columns
-				hasVectorColumn := false
-				for i := range table.Columns {
-					if table.Columns[i].IsVectorColumn {
-						hasVectorColumn = true
-						break
-					}
-				}
-
 				// Filter for vector tables only if requested
-				if vectorTablesOnly && !hasVectorColumn {
+				if vectorTablesOnly && !tableHasVectorColumn(table) {
 					continue
 				}
 
-				totalMatched++
+				matched = append(matched, table)
+			}
+
+			// Map iteration order is random; sort so output and pages are stable
+			sort.Slice(matched, func(i, j int) bool {
+				if matched[i].SchemaName != matched[j].SchemaName {
+					return matched[i].SchemaName < matched[j].SchemaName
+				}
+				return matched[i].TableName < matched[j].TableName
+			})
+			totalMatched := len(matched)
+
+			var schemaNames []string
+			for _, table := range matched {
+				hasVectorColumn := tableHasVectorColumn(table)
 
 				// Track stats per schema
 				if schemaMap[table.SchemaName] == nil {
 					schemaMap[table.SchemaName] = &schemaStats{}
+					schemaNames = append(schemaNames, table.SchemaName)
 				}
 				schemaMap[table.SchemaName].tableNames = append(
 					schemaMap[table.SchemaName].tableNames, table.TableName)
@@ -216,7 +287,38 @@ To avoid rate limits when calling this tool:
 
 			// Auto-summary mode: when no filters applied and many tables
 			autoSummary := schemaName == "" && tableName == "" && !vectorTablesOnly && !compactMode &&
-				totalMatched > summaryThreshold
+				!includeIndexes && !pageRequested && totalMatched > summaryThreshold
+
+			// Select the requested page of tables for detailed/compact output
+			if totalMatched > 0 && offset >= totalMatched {
+				return mcp.NewToolError(fmt.Sprintf("Parameter 'offset' (%d) is past the last table; %d table(s) match", offset, totalMatched))
+			}
+			end := offset + limit
+			if end > totalMatched {
+				end = totalMatched
+			}
+			page := matched[offset:end]
+
+			// Indexes aren't part of the loaded metadata, so read them for
+			// this page only
+			var indexes map[string][]tableIndex
+			if includeIndexes && len(page) > 0 {
+				connStr := dbClient.GetDefaultConnection()
+				pool := dbClient.GetPoolFor(connStr)
+				if pool == nil {
+					return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+				}
+
+				ctx := requestContext(args)
+				err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+					var err error
+					indexes, err = readTableIndexes(ctx, tx, page)
+					return err
+				})
+				if err != nil {
+					return mcp.NewToolError(fmt.Sprintf("Error reading indexes: %v", err))
+				}
+			}
 
 			var sb strings.Builder
 
@@ -228,7 +330,8 @@ To avoid rate limits when calling this tool:
 					totalMatched, len(schemaMap)))
 
 				// List schemas with their tables
-				for schema, stats := range schemaMap {
+				for _, schema := range schemaNames {
+					stats := schemaMap[schema]
 					sb.WriteString(fmt.Sprintf("Schema '%s': %d tables\n",
 						schema, len(stats.tableNames)))
 
@@ -256,106 +359,76 @@ To avoid rate limits when calling this tool:
 				sb.WriteString("<next_steps>\n")
 				sb.WriteString("To reduce token usage and get detailed info:\n\n")
 				sb.WriteString("1. Get details for a specific schema:\n")
-				for schema := range schemaMap {
+				for _, schema := range schemaNames {
 					sb.WriteString(fmt.Sprintf("   → get_schema_info(schema_name=%q)\n", schema))
 				}
 				sb.WriteString("\n2. Get only vector-enabled tables:\n")
 				sb.WriteString("   → get_schema_info(vector_tables_only=true)\n\n")
 				sb.WriteString("3. Get compact view (names only):\n")
-				sb.WriteString("   → get_schema_info(compact=true)\n")
+				sb.WriteString("   → get_schema_info(compact=true)\n\n")
+				sb.WriteString(fmt.Sprintf("4. Page through full details, %d tables at a time:\n", limit))
+				sb.WriteString(fmt.Sprintf("   → get_schema_info(limit=%d, offset=0)\n", limit))
 				sb.WriteString("</next_steps>\n")
 			} else {
 				// Standard output modes: TSV format
 				if compactMode {
 					// Compact mode: table names only (no column details)
 					sb.WriteString("schema\ttable\ttype\ttable_desc\n")
-
-					for _, table := range metadata {
-						// Filter by schema if requested
-						if schemaName != "" && table.SchemaName != schemaName {
-							continue
-						}
-
-						// Filter by table if requested
-						if tableName != "" && table.TableName != tableName {
-							continue
-						}
-
-						// Filter for vector tables only if requested
-						if vectorTablesOnly {
-							hasVectorColumn := false
-							for i := range table.Columns {
-								if table.Columns[i].IsVectorColumn {
-									hasVectorColumn = true
-									break
-								}
-							}
-							if !hasVectorColumn {
-								continue
-							}
-						}
-
-						sb.WriteString(BuildTSVRow(
-							table.SchemaName,
-							table.TableName,
-							table.TableType,
-							table.Description,
-						))
-						sb.WriteString("\n")
-					}
 				} else {
 					// Full mode: one row per column with all details
 					sb.WriteString("schema\ttable\ttype\ttable_desc\tcolumn\tdata_type\tnullable\tcol_desc\tis_pk\tis_unique\tfk_ref\tis_indexed\tidentity\tdefault\tis_vector\tvector_dims\n")
+				}
 
-					for _, table := range metadata {
-						// Filter by schema if requested
-						if schemaName != "" && table.SchemaName != schemaName {
-							continue
-						}
-
-						// Filter by table if requested
-						if tableName != "" && table.TableName != tableName {
-							continue
-						}
-
-						// Filter for vector tables only if requested
-						if vectorTablesOnly {
-							hasVectorColumn := false
-							for i := range table.Columns {
-								if table.Columns[i].IsVectorColumn {
-									hasVectorColumn = true
-									break
-								}
-							}
-							if !hasVectorColumn {
-								continue
-							}
-						}
-
-						// Output one row per column
-						for i := range table.Columns {
-							col := &table.Columns[i]
-							sb.WriteString(BuildTSVRow(
-								table.SchemaName,
-								table.TableName,
-								table.TableType,
-								table.Description,
-								col.ColumnName,
-								col.DataType,
-								col.IsNullable,
-								col.Description,
-								fmt.Sprintf("%t", col.IsPrimaryKey),
-								fmt.Sprintf("%t", col.IsUnique),
-								col.ForeignKeyRef,
-								fmt.Sprintf("%t", col.IsIndexed),
-								col.IsIdentity,
-								col.DefaultValue,
-								fmt.Sprintf("%t", col.IsVectorColumn),
-								fmt.Sprintf("%d", col.VectorDimensions),
-							))
-							sb.WriteString("\n")
-						}
+				// Stop at whole tables once the configured size limit is
+				// reached, always keeping at least one so paging makes progress
+				maxBytes := dbClient.MaxSchemaContextBytes()
+				written := 0
+				var keys, foreignKeys, tableIndexes strings.Builder
+				for _, table := range page {
+					rows := schemaInfoTableRows(table, compactMode)
+					tableKeys, fks, idxs := "", "", ""
+					if !compactMode {
+						tableKeys = schemaInfoKeys(table)
+						fks = schemaInfoForeignKeys(table)
+						idxs = schemaInfoIndexes(table, indexes[table.SchemaName+"."+table.TableName])
+					}
+					if maxBytes > 0 && written > 0 &&
+						sb.Len()+keys.Len()+foreignKeys.Len()+tableIndexes.Len()+
+							len(rows)+len(tableKeys)+len(fks)+len(idxs) > maxBytes {
+						break
 					}
+					sb.WriteString(rows)
+					keys.WriteString(tableKeys)
+					foreignKeys.WriteString(fks)
+					tableIndexes.WriteString(idxs)
+					written++
+				}
+				if keys.Len() > 0 {
+					sb.WriteString("\nKeys:\n")
+					sb.WriteString(keys.String())
+				}
+				if foreignKeys.Len() > 0 {
+					sb.WriteString("\nForeign Keys:\n")
+					sb.WriteString(foreignKeys.String())
+				}
+				if tableIndexes.Len() > 0 {
+					sb.WriteString("\nIndexes:\n")
+					sb.WriteString(tableIndexes.String())
+				}
+				if written < len(page) {
+					sb.WriteString(fmt.Sprintf("\n[TRUNCATED: schema context limit of %d bytes (max_schema_context_bytes) reached after %d of %d table(s) on this page]\n",
+						maxBytes, written, len(page)))
+					end = offset + written
+				}
+			}
+
+			// Tell the caller where this page sits and how to get the next one
+			if !autoSummary && (offset > 0 || end < totalMatched) {
+				sb.WriteString(fmt.Sprintf("\nShowing tables %d-%d of %d (sorted by schema, table).\n",
+					offset+1, end, totalMatched))
+				if end < totalMatched {
+					sb.WriteString(fmt.Sprintf("Next page: → get_schema_info(%s)\n",
+						schemaInfoPageArgs(schemaName, tableName, vectorTablesOnly, compactMode, includeIndexes, limit, end)))
 				}
 			}
 
@@ -452,3 +525,196 @@ To avoid rate limits when calling this tool:
 		},
 	}
 }
+
+// schemaInfoTableRows renders the TSV rows for one table: a single row in
+// compact mode, otherwise one row per column
+func schemaInfoTableRows(table database.TableInfo, compact bool) string {
+	if compact {
+		return BuildTSVRow(
+			table.SchemaName,
+			table.TableName,
+			table.TableType,
+			table.Description,
+		) + "\n"
+	}
+
+	var sb strings.Builder
+	for i := range table.Columns {
+		col := &table.Columns[i]
+		sb.WriteString(BuildTSVRow(
+			table.SchemaName,
+			table.TableName,
+			table.TableType,
+			table.Description,
+			col.ColumnName,
+			col.DataType,
+			col.IsNullable,
+			col.Description,
+			fmt.Sprintf("%t", col.IsPrimaryKey),
+			fmt.Sprintf("%t", col.IsUnique),
+			col.ForeignKeyRef,
+			fmt.Sprintf("%t", col.IsIndexed),
+			col.IsIdentity,
+			col.DefaultValue,
+			fmt.Sprintf("%t", col.IsVectorColumn),
+			fmt.Sprintf("%d", col.VectorDimensions),
+		))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// schemaInfoKeys renders a table's primary key and unique column sets for
+// the Keys section under the table name, or nothing if the table has none
+func schemaInfoKeys(table database.TableInfo) string {
+	if len(table.PrimaryKey) == 0 && len(table.UniqueKeys) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(table.SchemaName + "." + table.TableName + "\n")
+	if len(table.PrimaryKey) > 0 {
+		sb.WriteString(fmt.Sprintf("  PRIMARY KEY (%s)\n", strings.Join(table.PrimaryKey, ", ")))
+	}
+	for _, columns := range table.UniqueKeys {
+		sb.WriteString(fmt.Sprintf("  UNIQUE (%s)\n", strings.Join(columns, ", ")))
+	}
+	return sb.String()
+}
+
+// schemaInfoForeignKeys renders a table's foreign keys for the Foreign Keys
+// section, one "(columns) -> schema.table(columns)" line per constraint
+// under the table name, or nothing if the table has none
+func schemaInfoForeignKeys(table database.TableInfo) string {
+	if len(table.ForeignKeys) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(table.SchemaName + "." + table.TableName + "\n")
+	for _, fk := range table.ForeignKeys {
+		sb.WriteString(fmt.Sprintf("  (%s) -> %s.%s(%s)\n",
+			strings.Join(fk.Columns, ", "),
+			fk.ReferencedSchema, fk.ReferencedTable,
+			strings.Join(fk.ReferencedColumns, ", ")))
+	}
+	return sb.String()
+}
+
+// tableIndex is one index of a table, as listed with include_indexes
+type tableIndex struct {
+	name      string
+	method    string
+	columns   []string // key columns or expressions, in index order
+	unique    bool
+	primary   bool
+	predicate string // WHERE clause of a partial index, empty otherwise
+}
+
+// readTableIndexes returns the indexes of the given tables keyed by
+// "schema.table", each table's indexes sorted by name
+func readTableIndexes(ctx context.Context, tx pgx.Tx, tables []database.TableInfo) (map[string][]tableIndex, error) {
+	schemas := make([]string, len(tables))
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		schemas[i] = table.SchemaName
+		names[i] = table.TableName
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT
+			n.nspname,
+			c.relname,
+			ic.relname,
+			am.amname,
+			ARRAY(
+				SELECT pg_get_indexdef(i.indexrelid, k, true)
+				FROM generate_series(1, i.indnkeyatts) AS k
+			),
+			i.indisunique,
+			i.indisprimary,
+			COALESCE(pg_get_expr(i.indpred, i.indrelid, true), '')
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_class ic ON ic.oid = i.indexrelid
+		JOIN pg_am am ON am.oid = ic.relam
+		JOIN unnest($1::text[], $2::text[]) AS t(schema_name, table_name)
+			ON t.schema_name = n.nspname AND t.table_name = c.relname
+		ORDER BY n.nspname, c.relname, ic.relname`, schemas, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := make(map[string][]tableIndex)
+	for rows.Next() {
+		var schemaName, tableName string
+		var idx tableIndex
+		if err := rows.Scan(&schemaName, &tableName, &idx.name, &idx.method, &idx.columns,
+			&idx.unique, &idx.primary, &idx.predicate); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		key := schemaName + "." + tableName
+		indexes[key] = append(indexes[key], idx)
+	}
+	return indexes, rows.Err()
+}
+
+// schemaInfoIndexes renders a table's indexes for the Indexes section, one
+// "name method (columns) [UNIQUE|PRIMARY KEY] [WHERE ...]" line per index
+// under the table name, or nothing if the table has none
+func schemaInfoIndexes(table database.TableInfo, indexes []tableIndex) string {
+	if len(indexes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(table.SchemaName + "." + table.TableName + "\n")
+	for _, idx := range indexes {
+		sb.WriteString(fmt.Sprintf("  %s %s (%s)", idx.name, idx.method, strings.Join(idx.columns, ", ")))
+		if idx.primary {
+			sb.WriteString(" PRIMARY KEY")
+		} else if idx.unique {
+			sb.WriteString(" UNIQUE")
+		}
+		if idx.predicate != "" {
+			sb.WriteString(" WHERE " + idx.predicate)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// tableHasVectorColumn reports whether a table has any pgvector column
+func tableHasVectorColumn(table database.TableInfo) bool {
+	for i := range table.Columns {
+		if table.Columns[i].IsVectorColumn {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaInfoPageArgs formats the get_schema_info arguments for another page
+// of the same listing
+func schemaInfoPageArgs(schemaName, tableName string, vectorTablesOnly, compact, includeIndexes bool, limit, offset int) string {
+	var args []string
+	if schemaName != "" {
+		args = append(args, fmt.Sprintf("schema_name=%q", schemaName))
+	}
+	if tableName != "" {
+		args = append(args, fmt.Sprintf("table_name=%q", tableName))
+	}
+	if vectorTablesOnly {
+		args = append(args, "vector_tables_only=true")
+	}
+	if compact {
+		args = append(args, "compact=true")
+	}
+	if includeIndexes {
+		args = append(args, "include_indexes=true")
+	}
+	args = append(args, fmt.Sprintf("limit=%d", limit), fmt.Sprintf("offset=%d", offset))
+	return strings.Join(args, ", ")
+}
@@ -11,9 +11,11 @@
 package tools
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
+	"pgedge-postgres-mcp/internal/config"
 	"pgedge-postgres-mcp/internal/database"
 )
 
@@ -612,3 +614,264 @@ func TestGetSchemaInfoTool(t *testing.T) {
 		}
 	})
 }
+
+func TestGetSchemaInfoPagination(t *testing.T) {
+	// 60 tables across two schemas, inserted in map (random) order
+	metadata := make(map[string]database.TableInfo)
+	for i := 0; i < 60; i++ {
+		schema := "app"
+		if i%2 == 1 {
+			schema = "billing"
+		}
+		name := fmt.Sprintf("t%02d", i)
+		metadata[schema+"."+name] = database.TableInfo{
+			SchemaName: schema,
+			TableName:  name,
+			TableType:  "TABLE",
+			Columns:    []database.ColumnInfo{{ColumnName: "id", DataType: "integer", IsNullable: "NO"}},
+		}
+	}
+	tool := GetSchemaInfoTool(createMockClient(metadata))
+
+	callText := func(t *testing.T, args map[string]interface{}) string {
+		t.Helper()
+		response, err := tool.Handler(args)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.IsError {
+			t.Fatalf("Unexpected error response: %s", response.Content[0].Text)
+		}
+		return response.Content[0].Text
+	}
+
+	t.Run("first page defaults to 50 tables in sorted order", func(t *testing.T) {
+		content := callText(t, map[string]interface{}{"compact": true})
+
+		lines := strings.Split(content, "\n")
+		var rows []string
+		for _, line := range lines {
+			if strings.HasPrefix(line, "app\t") || strings.HasPrefix(line, "billing\t") {
+				rows = append(rows, line)
+			}
+		}
+		if len(rows) != 50 {
+			t.Fatalf("Expected 50 rows, got %d", len(rows))
+		}
+		if !strings.HasPrefix(rows[0], "app\tt00\t") || !strings.HasPrefix(rows[29], "app\tt58\t") ||
+			!strings.HasPrefix(rows[30], "billing\tt01\t") {
+			t.Errorf("Rows not sorted by schema then table: %q, %q, %q", rows[0], rows[29], rows[30])
+		}
+		if !strings.Contains(content, "Showing tables 1-50 of 60") {
+			t.Error("Expected page footer with total count")
+		}
+		if !strings.Contains(content, "get_schema_info(compact=true, limit=50, offset=50)") {
+			t.Error("Expected next page call in footer")
+		}
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		content := callText(t, map[string]interface{}{"compact": true, "offset": float64(50)})
+
+		if !strings.Contains(content, "Showing tables 51-60 of 60") {
+			t.Error("Expected last page footer")
+		}
+		if strings.Contains(content, "Next page") {
+			t.Error("Did not expect a next page on the last page")
+		}
+	})
+
+	t.Run("explicit limit disables auto-summary", func(t *testing.T) {
+		content := callText(t, map[string]interface{}{"limit": float64(5)})
+
+		if strings.Contains(content, "Database Schema Summary") {
+			t.Error("Expected detailed output, not the summary")
+		}
+		if !strings.Contains(content, "Showing tables 1-5 of 60") {
+			t.Error("Expected footer for 5-table page")
+		}
+	})
+
+	t.Run("size limit ends the page at a whole table", func(t *testing.T) {
+		// Header (29 bytes) plus three 15-byte compact rows fit exactly
+		limited := GetSchemaInfoTool(database.NewTestClientWithConfig("postgres://localhost/test", metadata,
+			&config.NamedDatabaseConfig{MaxSchemaContextBytes: 74}))
+		response, err := limited.Handler(map[string]interface{}{"compact": true})
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		content := response.Content[0].Text
+
+		if !strings.Contains(content, "[TRUNCATED") {
+			t.Error("Expected truncation marker")
+		}
+		if !strings.Contains(content, "Showing tables 1-3 of 60") {
+			t.Errorf("Expected page to end after 3 tables, got:\n%s", content)
+		}
+		if !strings.Contains(content, "get_schema_info(compact=true, limit=50, offset=3)") {
+			t.Error("Expected next page call to resume after the last table shown")
+		}
+	})
+
+	t.Run("invalid paging arguments", func(t *testing.T) {
+		for _, args := range []map[string]interface{}{
+			{"limit": float64(0)},
+			{"offset": float64(-1)},
+			{"offset": float64(60)},
+		} {
+			response, err := tool.Handler(args)
+			if err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if !response.IsError {
+				t.Errorf("Expected error response for %v", args)
+			}
+		}
+	})
+}
+
+func TestGetSchemaInfoForeignKeys(t *testing.T) {
+	metadata := map[string]database.TableInfo{
+		"public.customers": {
+			SchemaName: "public",
+			TableName:  "customers",
+			TableType:  "TABLE",
+			Columns:    []database.ColumnInfo{{ColumnName: "id", DataType: "integer", IsNullable: "NO", IsPrimaryKey: true}},
+		},
+		"public.order_lines": {
+			SchemaName: "public",
+			TableName:  "order_lines",
+			TableType:  "TABLE",
+			Columns: []database.ColumnInfo{
+				{ColumnName: "order_id", DataType: "integer", IsNullable: "NO"},
+				{ColumnName: "region", DataType: "text", IsNullable: "NO"},
+			},
+			ForeignKeys: []database.ForeignKeyInfo{{
+				ConstraintName:    "order_lines_order_fkey",
+				Columns:           []string{"order_id", "region"},
+				ReferencedSchema:  "sales",
+				ReferencedTable:   "orders",
+				ReferencedColumns: []string{"id", "region"},
+			}},
+		},
+	}
+	tool := GetSchemaInfoTool(createMockClient(metadata))
+
+	response, err := tool.Handler(map[string]interface{}{"schema_name": "public"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	content := response.Content[0].Text
+
+	want := "\nForeign Keys:\npublic.order_lines\n  (order_id, region) -> sales.orders(id, region)\n"
+	if !strings.Contains(content, want) {
+		t.Errorf("Expected Foreign Keys section %q, got:\n%s", want, content)
+	}
+	if strings.Contains(content, "public.customers\n") {
+		t.Error("Tables without foreign keys should not be listed in the Foreign Keys section")
+	}
+
+	// Compact output has no column details, so no foreign keys either
+	response, err = tool.Handler(map[string]interface{}{"schema_name": "public", "compact": true})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if strings.Contains(response.Content[0].Text, "Foreign Keys") {
+		t.Error("Compact output should not include a Foreign Keys section")
+	}
+}
+
+func TestGetSchemaInfoKeys(t *testing.T) {
+	metadata := map[string]database.TableInfo{
+		"public.order_lines": {
+			SchemaName: "public",
+			TableName:  "order_lines",
+			TableType:  "TABLE",
+			Columns: []database.ColumnInfo{
+				{ColumnName: "order_id", DataType: "integer", IsNullable: "NO", IsPrimaryKey: true},
+				{ColumnName: "line_no", DataType: "integer", IsNullable: "NO", IsPrimaryKey: true},
+				{ColumnName: "sku", DataType: "text", IsNullable: "NO", IsUnique: true},
+				{ColumnName: "region", DataType: "text", IsNullable: "NO", IsUnique: true},
+			},
+			PrimaryKey: []string{"order_id", "line_no"},
+			UniqueKeys: [][]string{{"sku", "region"}},
+		},
+		"public.notes": {
+			SchemaName: "public",
+			TableName:  "notes",
+			TableType:  "TABLE",
+			Columns:    []database.ColumnInfo{{ColumnName: "body", DataType: "text", IsNullable: "YES"}},
+		},
+	}
+	tool := GetSchemaInfoTool(createMockClient(metadata))
+
+	response, err := tool.Handler(map[string]interface{}{"schema_name": "public"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	content := response.Content[0].Text
+
+	want := "\nKeys:\npublic.order_lines\n  PRIMARY KEY (order_id, line_no)\n  UNIQUE (sku, region)\n"
+	if !strings.Contains(content, want) {
+		t.Errorf("Expected Keys section %q, got:\n%s", want, content)
+	}
+	if strings.Contains(content, "public.notes\n") {
+		t.Error("Tables without keys should not be listed in the Keys section")
+	}
+	if strings.Contains(content, "Foreign Keys") {
+		t.Error("Did not expect a Foreign Keys section without foreign keys")
+	}
+}
+
+func TestGetSchemaInfoIncludeIndexes(t *testing.T) {
+	metadata := map[string]database.TableInfo{
+		"public.orders": {
+			SchemaName: "public",
+			TableName:  "orders",
+			TableType:  "TABLE",
+			Columns:    []database.ColumnInfo{{ColumnName: "id", DataType: "integer", IsNullable: "NO"}},
+		},
+	}
+	tool := GetSchemaInfoTool(createMockClient(metadata))
+
+	t.Run("requires a connection pool", func(t *testing.T) {
+		response, err := tool.Handler(map[string]interface{}{"include_indexes": true})
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if !response.IsError || !strings.Contains(response.Content[0].Text, "Connection pool not found") {
+			t.Errorf("Expected connection pool error, got: %s", response.Content[0].Text)
+		}
+	})
+
+	t.Run("ignored in compact mode", func(t *testing.T) {
+		response, err := tool.Handler(map[string]interface{}{"include_indexes": true, "compact": true})
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.IsError {
+			t.Errorf("Unexpected error response: %s", response.Content[0].Text)
+		}
+	})
+}
+
+func TestSchemaInfoIndexes(t *testing.T) {
+	table := database.TableInfo{SchemaName: "public", TableName: "orders"}
+
+	if got := schemaInfoIndexes(table, nil); got != "" {
+		t.Errorf("Expected no output without indexes, got %q", got)
+	}
+
+	got := schemaInfoIndexes(table, []tableIndex{
+		{name: "orders_pkey", method: "btree", columns: []string{"id"}, unique: true, primary: true},
+		{name: "orders_open_idx", method: "btree", columns: []string{"customer_id", "created_at"}, predicate: "status = 'open'::text"},
+		{name: "orders_ref_key", method: "btree", columns: []string{"lower(ref)"}, unique: true},
+	})
+	want := "public.orders\n" +
+		"  orders_pkey btree (id) PRIMARY KEY\n" +
+		"  orders_open_idx btree (customer_id, created_at) WHERE status = 'open'::text\n" +
+		"  orders_ref_key btree (lower(ref)) UNIQUE\n"
+	if got != want {
+		t.Errorf("schemaInfoIndexes() =\n%s\nwant:\n%s", got, want)
+	}
+}
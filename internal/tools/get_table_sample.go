@@ -0,0 +1,410 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// defaultTableSampleLimit is the number of rows returned when no limit is given
+	defaultTableSampleLimit = 10
+
+	// maxTableSampleLimit caps the rows a single sample may return
+	maxTableSampleLimit = 100
+
+	// maxListedTables caps the table names listed when a table isn't found
+	maxListedTables = 50
+
+	// tableSampleOversample is how many times more rows than requested a
+	// TABLESAMPLE percentage aims for, so LIMIT is usually reached
+	tableSampleOversample = 3
+)
+
+// GetTableSampleTool creates the get_table_sample tool
+func GetTableSampleTool(dbClient *database.Client, cfg *config.Config) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "get_table_sample",
+			Description: `Return a few rows from a table as JSON for a quick look at the data.
+
+<usecase>
+Use when:
+- Getting a feel for a table's contents before writing a query
+- Checking value formats (dates, codes, JSON shapes) in specific columns
+- Confirming which columns are populated
+</usecase>
+
+<what_it_returns>
+A JSON array with one object per row, keys in column order, and the sampling
+method used. By default (method "auto") small tables are sampled with
+ORDER BY random() and large tables with TABLESAMPLE SYSTEM, which reads a few
+random pages instead of the whole table.
+</what_it_returns>
+
+<important>
+- The table must exist in the loaded schema metadata (use get_schema_info)
+- Unqualified table names are looked up in the public schema
+- limit defaults to 10 and is capped at 100
+- method "random" on a large table scans and sorts the whole table
+- Views can only be sampled with a plain LIMIT
+- For filtered or ordered data, use query_database instead
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"table_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Table to sample, as 'schema.table' or 'table' (public schema)",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of rows to return (default: 10, maximum: 100)",
+						"default":     defaultTableSampleLimit,
+						"minimum":     1,
+						"maximum":     maxTableSampleLimit,
+					},
+					"columns": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Columns to return (default: all columns)",
+					},
+					"method": map[string]interface{}{
+						"type": "string",
+						"enum": []string{config.TableSampleAuto, config.TableSampleLimit, config.TableSampleRandom,
+							config.TableSampleSystem, config.TableSampleBernoulli},
+						"description": "Sampling method: 'auto' picks by table size, 'limit' returns the first rows read, " +
+							"'random' is an exact random sample, 'system'/'bernoulli' use TABLESAMPLE (default: server setting, usually 'auto')",
+					},
+				},
+				Required: []string{"table_name"},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			tableName, _ := args["table_name"].(string)
+
+			limit := defaultTableSampleLimit
+			if val, ok := args["limit"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'limit' must be a positive integer")
+				}
+				limit = int(val)
+			}
+			limitCapped := false
+			if limit > maxTableSampleLimit {
+				limit = maxTableSampleLimit
+				limitCapped = true
+			}
+
+			method := config.TableSampleAuto
+			largeTableRows := int64(config.DefaultLargeTableRows)
+			if cfg != nil {
+				method = cfg.TableSample.GetMethod()
+				largeTableRows = cfg.TableSample.GetLargeTableRows()
+			}
+			if val, ok := args["method"].(string); ok && val != "" {
+				method = val
+			}
+			switch method {
+			case config.TableSampleAuto, config.TableSampleLimit, config.TableSampleRandom,
+				config.TableSampleSystem, config.TableSampleBernoulli:
+			default:
+				return mcp.NewToolError(fmt.Sprintf("Invalid method '%s': must be auto, limit, random, system or bernoulli", method))
+			}
+
+			var requestedCols []string
+			if raw, ok := args["columns"]; ok {
+				list, ok := raw.([]interface{})
+				if !ok {
+					return mcp.NewToolError("Parameter 'columns' must be an array of column names")
+				}
+				for _, v := range list {
+					s, ok := v.(string)
+					if !ok || s == "" {
+						return mcp.NewToolError("Parameter 'columns' must be an array of column names")
+					}
+					requestedCols = append(requestedCols, s)
+				}
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			metadata := dbClient.GetMetadata()
+			tableInfo, err := findTableInMetadataMap(metadata, tableName)
+			if err != nil {
+				var errMsg strings.Builder
+				errMsg.WriteString(fmt.Sprintf("Table '%s' not found.\n\n", tableName))
+				errMsg.WriteString("<current_connection>\n")
+				errMsg.WriteString(fmt.Sprintf("Connected to: %s\n", database.SanitizeConnStr(connStr)))
+				errMsg.WriteString("</current_connection>\n\n")
+				errMsg.WriteString("<available_tables>\n")
+				errMsg.WriteString(listAvailableTables(metadata))
+				errMsg.WriteString("</available_tables>\n\n")
+				errMsg.WriteString("<next_steps>\n")
+				errMsg.WriteString("1. If the table is in a different schema, use a qualified name:\n")
+				errMsg.WriteString(fmt.Sprintf("   → get_table_sample(table_name=\"schema_name.%s\")\n\n", tableName))
+				errMsg.WriteString("2. Browse the schema:\n")
+				errMsg.WriteString("   → get_schema_info()\n")
+				errMsg.WriteString("</next_steps>\n")
+				return mcp.NewToolError(errMsg.String())
+			}
+
+			columns, missing := resolveSampleColumns(tableInfo, requestedCols)
+			if len(missing) > 0 {
+				available := make([]string, len(tableInfo.Columns))
+				for i, col := range tableInfo.Columns {
+					available[i] = col.ColumnName
+				}
+				return mcp.NewToolError(fmt.Sprintf("Column(s) not found in %s.%s: %s\n\nAvailable columns: %s",
+					tableInfo.SchemaName, tableInfo.TableName, strings.Join(missing, ", "), strings.Join(available, ", ")))
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			quotedCols := make([]string, len(columns))
+			for i, col := range columns {
+				quotedCols[i] = quoteIdentifier(col)
+			}
+			qualifiedName := quoteIdentifier(tableInfo.SchemaName) + "." + quoteIdentifier(tableInfo.TableName)
+
+			timeout := dbClient.QueryTimeout()
+			ctx, cancel := withQueryTimeout(requestContext(args), timeout)
+			defer cancel()
+
+			var results [][]interface{}
+			var notes []string
+			estimatedRows := -1.0
+			err = executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				if err := setStatementTimeout(ctx, tx, timeout); err != nil {
+					return err
+				}
+
+				// reltuples is -1 for views and tables never vacuumed or analyzed
+				if err := tx.QueryRow(ctx, "SELECT reltuples::float8 FROM pg_class WHERE oid = $1::regclass",
+					qualifiedName).Scan(&estimatedRows); err != nil {
+					return fmt.Errorf("failed to estimate table size: %w", err)
+				}
+
+				var note string
+				method, note = chooseSampleMethod(method, tableInfo.TableType, estimatedRows, largeTableRows)
+				if note != "" {
+					notes = append(notes, note)
+				}
+
+				query := buildSampleQuery(quotedCols, qualifiedName, method, limit, estimatedRows)
+
+				// Columns matching redact_columns are masked in every row,
+				// whether they match by this relation's name or, for a
+				// view, by the table the column comes from
+				redactor := dbClient.Redactor()
+				redacted, err := redactor.ResultColumns(ctx, tx, query)
+				if err != nil {
+					return err
+				}
+				for i := range redacted {
+					redacted[i] = redacted[i] || redactor.Matches(tableInfo.SchemaName, tableInfo.TableName, columns[i])
+				}
+
+				rows, err := tx.Query(ctx, query)
+				if err != nil {
+					return err
+				}
+				defer rows.Close()
+
+				for rows.Next() {
+					values, err := rows.Values()
+					if err != nil {
+						return fmt.Errorf("failed to read row: %w", err)
+					}
+					database.RedactRow(values, redacted)
+					results = append(results, values)
+				}
+				return rows.Err()
+			})
+			if err != nil {
+				if isQueryTimeout(ctx, err) {
+					return mcp.NewToolError(queryTimeoutMessage(timeout))
+				}
+				return mcp.NewToolError(fmt.Sprintf("Error sampling table %s.%s: %v", tableInfo.SchemaName, tableInfo.TableName, err))
+			}
+
+			rowsJSON, err := FormatResultsAsJSON(columns, results)
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Failed to encode rows as JSON: %v", err))
+			}
+
+			if limitCapped {
+				notes = append(notes, fmt.Sprintf("limit reduced to the maximum of %d rows.", maxTableSampleLimit))
+			}
+			if len(results) < limit && (method == config.TableSampleSystem || method == config.TableSampleBernoulli) &&
+				estimatedRows > float64(len(results)) {
+				notes = append(notes, "TABLESAMPLE returned fewer rows than requested; call again for a different sample.")
+			}
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(fmt.Sprintf("Table: %s.%s\n", tableInfo.SchemaName, tableInfo.TableName))
+			sb.WriteString(fmt.Sprintf("Method: %s\n\n", method))
+			for _, note := range notes {
+				sb.WriteString("Note: " + note + "\n")
+			}
+			if len(notes) > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(fmt.Sprintf("Sample rows (%d):\n", len(results)))
+			sb.WriteString(rowsJSON)
+			sb.WriteString("\n")
+
+			logging.Info("get_table_sample_executed",
+				"table", tableInfo.SchemaName+"."+tableInfo.TableName,
+				"columns", len(columns),
+				"limit", limit,
+				"method", method,
+				"estimated_rows", int64(estimatedRows),
+				"rows", len(results),
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// chooseSampleMethod resolves the sampling method for a table from the
+// requested method and the table's estimated row count (negative if
+// unknown), with a note for the caller when the choice needs explaining
+func chooseSampleMethod(method, tableType string, estimatedRows float64, largeTableRows int64) (string, string) {
+	isView := tableType == "VIEW"
+	isLarge := estimatedRows > float64(largeTableRows)
+
+	switch method {
+	case config.TableSampleAuto:
+		switch {
+		case isView:
+			return config.TableSampleLimit, ""
+		case estimatedRows < 0:
+			return config.TableSampleLimit, "no row estimate for this table (run ANALYZE); returning the first rows read instead of a random sample."
+		case isLarge:
+			return config.TableSampleSystem, ""
+		default:
+			return config.TableSampleRandom, ""
+		}
+	case config.TableSampleSystem, config.TableSampleBernoulli:
+		if isView {
+			return config.TableSampleLimit, "TABLESAMPLE is not supported on views; returning the first rows read."
+		}
+	case config.TableSampleRandom:
+		if isLarge {
+			return method, fmt.Sprintf("an exact random sample of about %.0f rows scans and sorts the whole table; "+
+				"method 'system' is much faster on large tables.", estimatedRows)
+		}
+	}
+	return method, ""
+}
+
+// buildSampleQuery builds the SELECT for a sample of a table using the
+// given method. quotedCols and qualifiedName must already be quoted.
+func buildSampleQuery(quotedCols []string, qualifiedName, method string, limit int, estimatedRows float64) string {
+	selectList := strings.Join(quotedCols, ", ")
+
+	switch method {
+	case config.TableSampleRandom:
+		return fmt.Sprintf("SELECT %s FROM %s ORDER BY random() LIMIT %d", selectList, qualifiedName, limit)
+	case config.TableSampleSystem, config.TableSampleBernoulli:
+		return fmt.Sprintf("SELECT %s FROM %s TABLESAMPLE %s (%s) LIMIT %d", selectList, qualifiedName,
+			strings.ToUpper(method), strconv.FormatFloat(samplePercent(limit, estimatedRows), 'f', -1, 64), limit)
+	default:
+		return fmt.Sprintf("SELECT %s FROM %s LIMIT %d", selectList, qualifiedName, limit)
+	}
+}
+
+// samplePercent returns the TABLESAMPLE percentage expected to yield
+// several times the requested rows, or 100 without a row estimate
+func samplePercent(limit int, estimatedRows float64) float64 {
+	if estimatedRows <= 0 {
+		return 100
+	}
+	return roundUpPercent(float64(limit*tableSampleOversample) / estimatedRows * 100)
+}
+
+// roundUpPercent caps a TABLESAMPLE percentage at 100 and rounds it up to 4
+// significant digits to keep the SQL readable
+func roundUpPercent(pct float64) float64 {
+	if pct > 100 {
+		return 100
+	}
+	scale := math.Pow(10, 3-math.Floor(math.Log10(pct)))
+	return math.Ceil(pct*scale) / scale
+}
+
+// resolveSampleColumns returns the columns to select, defaulting to all of
+// the table's columns, and any requested columns the table doesn't have
+func resolveSampleColumns(tableInfo database.TableInfo, requested []string) (columns, missing []string) {
+	if len(requested) == 0 {
+		for _, col := range tableInfo.Columns {
+			columns = append(columns, col.ColumnName)
+		}
+		return columns, nil
+	}
+
+	known := make(map[string]bool, len(tableInfo.Columns))
+	for _, col := range tableInfo.Columns {
+		known[col.ColumnName] = true
+	}
+	for _, name := range requested {
+		if known[name] {
+			columns = append(columns, name)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	return columns, missing
+}
+
+// listAvailableTables lists the tables in the metadata, one per line, sorted
+// and capped at maxListedTables
+func listAvailableTables(metadata map[string]database.TableInfo) string {
+	if len(metadata) == 0 {
+		return "No tables found in the loaded metadata.\n"
+	}
+
+	names := make([]string, 0, len(metadata))
+	for _, table := range metadata {
+		names = append(names, table.SchemaName+"."+table.TableName)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for i, name := range names {
+		if i == maxListedTables {
+			sb.WriteString(fmt.Sprintf("... and %d more (use get_schema_info to see all)\n", len(names)-maxListedTables))
+			break
+		}
+		sb.WriteString(name + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,212 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Get Table Sample Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/database"
+)
+
+func sampleTestClient() *database.Client {
+	return database.NewTestClient("postgres://localhost/test", map[string]database.TableInfo{
+		"public.users": {
+			SchemaName: "public",
+			TableName:  "users",
+			Columns: []database.ColumnInfo{
+				{ColumnName: "id"},
+				{ColumnName: "email"},
+				{ColumnName: "created_at"},
+			},
+		},
+		"sales.orders": {
+			SchemaName: "sales",
+			TableName:  "orders",
+			Columns:    []database.ColumnInfo{{ColumnName: "id"}},
+		},
+	})
+}
+
+func TestGetTableSampleToolDefinition(t *testing.T) {
+	tool := GetTableSampleTool(nil, nil)
+
+	if tool.Definition.Name != "get_table_sample" {
+		t.Errorf("Tool name = %v, want get_table_sample", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"table_name", "limit", "columns", "method"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+
+	if len(tool.Definition.InputSchema.Required) != 1 || tool.Definition.InputSchema.Required[0] != "table_name" {
+		t.Errorf("Required = %v, want [table_name]", tool.Definition.InputSchema.Required)
+	}
+}
+
+func TestGetTableSampleValidation(t *testing.T) {
+	tool := GetTableSampleTool(nil, nil)
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"missing table_name", map[string]interface{}{}},
+		{"non-positive limit", map[string]interface{}{"table_name": "users", "limit": float64(0)}},
+		{"columns not an array", map[string]interface{}{"table_name": "users", "columns": "id"}},
+		{"non-string column", map[string]interface{}{"table_name": "users", "columns": []interface{}{float64(1)}}},
+		{"unknown method", map[string]interface{}{"table_name": "users", "method": "shuffle"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if registeredToolError(t, tool, tt.args) == "" {
+				t.Error("Expected error response")
+			}
+		})
+	}
+}
+
+func TestGetTableSampleUnknownTable(t *testing.T) {
+	tool := GetTableSampleTool(sampleTestClient(), nil)
+
+	response, err := tool.Handler(map[string]interface{}{"table_name": "orders"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !response.IsError {
+		t.Fatal("Expected error response for unknown table")
+	}
+
+	text := response.Content[0].Text
+	for _, want := range []string{"Table 'orders' not found", "public.users", "sales.orders"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected error to contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestGetTableSampleUnknownColumn(t *testing.T) {
+	tool := GetTableSampleTool(sampleTestClient(), nil)
+
+	response, err := tool.Handler(map[string]interface{}{
+		"table_name": "public.users",
+		"columns":    []interface{}{"email", "password"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !response.IsError {
+		t.Fatal("Expected error response for unknown column")
+	}
+
+	text := response.Content[0].Text
+	if !strings.Contains(text, "password") || !strings.Contains(text, "Available columns: id, email, created_at") {
+		t.Errorf("Unexpected error text:\n%s", text)
+	}
+}
+
+func TestResolveSampleColumns(t *testing.T) {
+	table := database.TableInfo{
+		Columns: []database.ColumnInfo{{ColumnName: "id"}, {ColumnName: "name"}},
+	}
+
+	columns, missing := resolveSampleColumns(table, nil)
+	if strings.Join(columns, ",") != "id,name" || len(missing) != 0 {
+		t.Errorf("default columns = %v, missing = %v", columns, missing)
+	}
+
+	columns, missing = resolveSampleColumns(table, []string{"name", "age"})
+	if strings.Join(columns, ",") != "name" || strings.Join(missing, ",") != "age" {
+		t.Errorf("requested columns = %v, missing = %v", columns, missing)
+	}
+}
+
+func TestChooseSampleMethod(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		tableType     string
+		estimatedRows float64
+		expected      string
+		expectNote    bool
+	}{
+		{"auto small table", config.TableSampleAuto, "TABLE", 5000, config.TableSampleRandom, false},
+		{"auto large table", config.TableSampleAuto, "TABLE", 5e6, config.TableSampleSystem, false},
+		{"auto without estimate", config.TableSampleAuto, "TABLE", -1, config.TableSampleLimit, true},
+		{"auto view", config.TableSampleAuto, "VIEW", -1, config.TableSampleLimit, false},
+		{"system on view", config.TableSampleSystem, "VIEW", -1, config.TableSampleLimit, true},
+		{"bernoulli on materialized view", config.TableSampleBernoulli, "MATERIALIZED VIEW", 5e6, config.TableSampleBernoulli, false},
+		{"random on large table warns", config.TableSampleRandom, "TABLE", 5e6, config.TableSampleRandom, true},
+		{"random on small table", config.TableSampleRandom, "TABLE", 5000, config.TableSampleRandom, false},
+		{"explicit limit", config.TableSampleLimit, "TABLE", 5e6, config.TableSampleLimit, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, note := chooseSampleMethod(tt.method, tt.tableType, tt.estimatedRows, config.DefaultLargeTableRows)
+			if got != tt.expected {
+				t.Errorf("chooseSampleMethod() = %q, want %q", got, tt.expected)
+			}
+			if (note != "") != tt.expectNote {
+				t.Errorf("chooseSampleMethod() note = %q, expectNote %v", note, tt.expectNote)
+			}
+		})
+	}
+}
+
+func TestBuildSampleQuery(t *testing.T) {
+	cols := []string{`"id"`, `"email"`}
+	table := `"public"."users"`
+
+	tests := []struct {
+		method   string
+		expected string
+	}{
+		{config.TableSampleLimit, `SELECT "id", "email" FROM "public"."users" LIMIT 10`},
+		{config.TableSampleRandom, `SELECT "id", "email" FROM "public"."users" ORDER BY random() LIMIT 10`},
+		{config.TableSampleSystem, `SELECT "id", "email" FROM "public"."users" TABLESAMPLE SYSTEM (0.003) LIMIT 10`},
+		{config.TableSampleBernoulli, `SELECT "id", "email" FROM "public"."users" TABLESAMPLE BERNOULLI (0.003) LIMIT 10`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			if got := buildSampleQuery(cols, table, tt.method, 10, 1e6); got != tt.expected {
+				t.Errorf("buildSampleQuery() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSamplePercent(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         int
+		estimatedRows float64
+		expected      float64
+	}{
+		{"unknown size", 10, -1, 100},
+		{"small table", 10, 20, 100},
+		{"large table", 10, 1e6, 0.003},
+		{"rounded up", 10, 7e5, 0.004286},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := samplePercent(tt.limit, tt.estimatedRows); got != tt.expected {
+				t.Errorf("samplePercent() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// selectiveTuplesPerScan is the average index entries read per scan at or
+	// below which an index is considered selective
+	selectiveTuplesPerScan = 10
+
+	// heapHeavyFetchesPerScan is the average heap fetches per scan above
+	// which an index is flagged as causing heavy heap access
+	heapHeavyFetchesPerScan = 1000
+
+	// lowIndexCacheHitRatio flags indexes whose blocks are mostly read from disk
+	lowIndexCacheHitRatio = 0.90
+
+	// minIndexBlocksForCacheRatio avoids flagging the cache ratio of barely
+	// used indexes
+	minIndexBlocksForCacheRatio = 1000
+)
+
+// IndexEfficiencyTool creates the index_efficiency tool
+func IndexEfficiencyTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "index_efficiency",
+			Description: `Report how effectively each index is used: scans, entries read vs heap rows fetched, and cache hit ratio.
+
+<usecase>
+Use when:
+- Deciding whether an index is actually helping queries
+- Investigating high I/O on a table that "has an index"
+- Looking for unused indexes that only add write overhead
+</usecase>
+
+<what_it_returns>
+Per index (most scanned first):
+- idx_scan, idx_tup_read, idx_tup_fetch and their per-scan averages
+- Index cache hit ratio from pg_statio_user_indexes
+- Index size
+- Assessment: unused, selective (few entries per scan - good),
+  heap_heavy (many heap fetches per scan), or broad (many entries per scan)
+- Warnings for indexes with a low cache hit ratio
+</what_it_returns>
+
+<important>
+- Statistics are cumulative since the last stats reset
+- idx_tup_fetch only counts heap fetches from plain index scans; bitmap
+  scans and index-only scans that skip the heap do not add to it
+- Unused indexes may still enforce uniqueness or back a constraint
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"schema_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Only report indexes in this schema (default: all user schemas)",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of indexes to return. Default: 20",
+						"default":     20,
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			schemaName := ValidateOptionalStringParam(args, "schema_name", "")
+
+			limit := 20
+			if val, ok := args["limit"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'limit' must be a positive integer")
+				}
+				limit = int(val)
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+
+			var results [][]interface{}
+			var warnings []string
+			counts := make(map[string]int)
+
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				rows, err := tx.Query(ctx, `
+					SELECT s.schemaname, s.relname, s.indexrelname,
+					       s.idx_scan, s.idx_tup_read, s.idx_tup_fetch,
+					       COALESCE(io.idx_blks_hit, 0), COALESCE(io.idx_blks_read, 0),
+					       pg_relation_size(s.indexrelid)
+					FROM pg_stat_user_indexes s
+					JOIN pg_statio_user_indexes io ON io.indexrelid = s.indexrelid
+					WHERE ($1 = '' OR s.schemaname = $1)
+					ORDER BY s.idx_scan DESC, s.schemaname, s.relname, s.indexrelname
+					LIMIT $2`, schemaName, limit)
+				if err != nil {
+					return fmt.Errorf("failed to query index statistics: %w", err)
+				}
+				defer rows.Close()
+
+				for rows.Next() {
+					var schema, table, index string
+					var scans, tupRead, tupFetch, blksHit, blksRead, size int64
+					if err := rows.Scan(&schema, &table, &index, &scans, &tupRead, &tupFetch,
+						&blksHit, &blksRead, &size); err != nil {
+						return fmt.Errorf("failed to scan index statistics: %w", err)
+					}
+
+					assessment := classifyIndexEfficiency(scans, tupRead, tupFetch)
+					counts[assessment]++
+
+					hitRatio := "n/a"
+					if total := blksHit + blksRead; total > 0 {
+						ratio := float64(blksHit) / float64(total)
+						hitRatio = fmt.Sprintf("%.1f%%", ratio*100)
+						if total >= minIndexBlocksForCacheRatio && ratio < lowIndexCacheHitRatio {
+							warnings = append(warnings, fmt.Sprintf("%s.%s: index cache hit ratio %s; most index blocks are read from disk",
+								schema, index, hitRatio))
+						}
+					}
+
+					results = append(results, []interface{}{
+						schema, table, index, scans, tupRead, tupFetch,
+						perScan(tupRead, scans), perScan(tupFetch, scans),
+						hitRatio, formatBytes(size), assessment,
+					})
+				}
+				return rows.Err()
+			})
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Error reading index statistics: %v", err))
+			}
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+
+			if len(results) == 0 {
+				sb.WriteString("No user indexes found")
+				if schemaName != "" {
+					sb.WriteString(fmt.Sprintf(" in schema '%s'", schemaName))
+				}
+				sb.WriteString(".\n")
+				return mcp.NewToolSuccess(sb.String())
+			}
+
+			sb.WriteString("Index efficiency (since stats reset, most scanned first):\n")
+			sb.WriteString(FormatResultsAsTSV(
+				[]string{"schema", "table", "index", "idx_scan", "tup_read", "tup_fetch",
+					"read_per_scan", "fetch_per_scan", "cache_hit", "size", "assessment"},
+				results))
+			sb.WriteString("\n")
+
+			if len(warnings) > 0 {
+				sb.WriteString("\n<warnings>\n")
+				for _, w := range warnings {
+					sb.WriteString(fmt.Sprintf("⚠️  %s\n", w))
+				}
+				sb.WriteString("</warnings>\n")
+			}
+
+			var recs []string
+			if counts["heap_heavy"] > 0 {
+				recs = append(recs, fmt.Sprintf("%d index(es) cause many heap fetches per scan; consider a covering index (INCLUDE columns) so queries can use index-only scans, and make sure VACUUM keeps the visibility map current", counts["heap_heavy"]))
+			}
+			if counts["broad"] > 0 {
+				recs = append(recs, fmt.Sprintf("%d index(es) read many entries per scan; check with execute_explain whether a more selective or multicolumn index fits the queries", counts["broad"]))
+			}
+			if counts["unused"] > 0 {
+				recs = append(recs, fmt.Sprintf("%d index(es) have never been scanned; if they don't enforce a constraint, they only add write and storage overhead", counts["unused"]))
+			}
+			if len(recs) > 0 {
+				sb.WriteString("\n<recommendations>\n")
+				for _, r := range recs {
+					sb.WriteString("- " + r + "\n")
+				}
+				sb.WriteString("</recommendations>\n")
+			}
+
+			logging.Info("index_efficiency_executed",
+				"schema", schemaName,
+				"indexes", len(results),
+				"heap_heavy", counts["heap_heavy"],
+				"unused", counts["unused"],
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// classifyIndexEfficiency assesses an index from its scan and tuple counters
+func classifyIndexEfficiency(scans, tupRead, tupFetch int64) string {
+	switch {
+	case scans == 0:
+		return "unused"
+	case tupFetch/scans > heapHeavyFetchesPerScan:
+		return "heap_heavy"
+	case tupRead/scans <= selectiveTuplesPerScan:
+		return "selective"
+	default:
+		return "broad"
+	}
+}
+
+// perScan formats a per-scan average, or "-" for indexes never scanned
+func perScan(count, scans int64) string {
+	if scans == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", float64(count)/float64(scans))
+}
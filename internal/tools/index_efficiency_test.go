@@ -0,0 +1,73 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Index Efficiency Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"testing"
+)
+
+func TestIndexEfficiencyToolDefinition(t *testing.T) {
+	tool := IndexEfficiencyTool(nil)
+
+	if tool.Definition.Name != "index_efficiency" {
+		t.Errorf("Tool name = %v, want index_efficiency", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"schema_name", "limit"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+}
+
+func TestIndexEfficiencyInvalidLimit(t *testing.T) {
+	tool := IndexEfficiencyTool(nil)
+
+	response, err := tool.Handler(map[string]interface{}{"limit": float64(-1)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !response.IsError {
+		t.Error("Expected error response for negative limit")
+	}
+}
+
+func TestClassifyIndexEfficiency(t *testing.T) {
+	tests := []struct {
+		name     string
+		scans    int64
+		tupRead  int64
+		tupFetch int64
+		expected string
+	}{
+		{"never scanned", 0, 0, 0, "unused"},
+		{"point lookups", 1000, 1000, 1000, "selective"},
+		{"range scans with heap fetches", 10, 50000, 50000, "heap_heavy"},
+		{"bitmap range scans", 10, 50000, 0, "broad"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyIndexEfficiency(tt.scans, tt.tupRead, tt.tupFetch); got != tt.expected {
+				t.Errorf("classifyIndexEfficiency() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPerScan(t *testing.T) {
+	if got := perScan(10, 0); got != "-" {
+		t.Errorf("perScan(10, 0) = %q, want -", got)
+	}
+	if got := perScan(25, 10); got != "2.5" {
+		t.Errorf("perScan(25, 10) = %q, want 2.5", got)
+	}
+}
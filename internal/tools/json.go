@@ -0,0 +1,76 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"pgedge-postgres-mcp/internal/tsv"
+)
+
+// FormatResultsAsJSON converts query results to a JSON array with one object
+// per row. Keys follow the column order of the result; NULLs become null.
+// Booleans, numbers and strings keep their JSON types, and other values use
+// the same text representation as FormatResultsAsTSV.
+func FormatResultsAsJSON(columnNames []string, results [][]interface{}) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for r, row := range results {
+		if r > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n  {")
+		for i, col := range columnNames {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			key, err := json.Marshal(col)
+			if err != nil {
+				return "", err
+			}
+			var val interface{}
+			if i < len(row) {
+				val = jsonValue(row[i])
+			}
+			encoded, err := json.Marshal(val)
+			if err != nil {
+				return "", err
+			}
+			sb.Write(key)
+			sb.WriteString(": ")
+			sb.Write(encoded)
+		}
+		sb.WriteString("}")
+	}
+	if len(results) > 0 {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("]")
+	return sb.String(), nil
+}
+
+// jsonValue maps a database value to one encoding/json renders sensibly.
+// Values are normalized first (see tsv.Normalize), so numerics keep their
+// exact digits as strings rather than becoming floats.
+func jsonValue(v interface{}) interface{} {
+	switch val := tsv.Normalize(v).(type) {
+	case nil, bool, string,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64, []interface{}, map[string]interface{}:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339)
+	default:
+		return tsv.StringValue(val)
+	}
+}
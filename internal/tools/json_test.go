@@ -0,0 +1,62 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - JSON Formatting Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestFormatResultsAsJSON(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		columns  []string
+		results  [][]interface{}
+		expected string
+	}{
+		{
+			name:     "no rows",
+			columns:  []string{"id"},
+			results:  nil,
+			expected: "[]",
+		},
+		{
+			name:    "keeps column order and types",
+			columns: []string{"name", "id", "active", "created", "note"},
+			results: [][]interface{}{
+				{"Alice", int64(1), true, created, nil},
+				{"Bob \"B\"", int64(2), false, created, []byte("raw")},
+			},
+			expected: "[\n" +
+				`  {"name": "Alice", "id": 1, "active": true, "created": "2025-01-02T03:04:05Z", "note": null},` + "\n" +
+				`  {"name": "Bob \"B\"", "id": 2, "active": false, "created": "2025-01-02T03:04:05Z", "note": "\\x726177"}` + "\n" +
+				"]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatResultsAsJSON(tt.columns, tt.results)
+			if err != nil {
+				t.Fatalf("FormatResultsAsJSON() error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("FormatResultsAsJSON() =\n%s\nwant\n%s", got, tt.expected)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Errorf("FormatResultsAsJSON() produced invalid JSON: %s", got)
+			}
+		})
+	}
+}
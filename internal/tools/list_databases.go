@@ -0,0 +1,147 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// clusterDatabase is one row of list_databases output
+type clusterDatabase struct {
+	name        string
+	owner       string
+	encoding    string
+	size        *int64 // nil when the caller may not connect to it
+	allowConn   bool
+	isConnected bool
+}
+
+// ListDatabasesTool creates the list_databases tool, which lists the
+// databases on the server the current connection points at
+func ListDatabasesTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "list_databases",
+			Description: `List the databases on the PostgreSQL server of the current connection.
+
+<usecase>
+Use when:
+- The user asks what other databases exist on this server
+- Looking for the database that holds some data before switching to it
+</usecase>
+
+<what_it_returns>
+For each database except templates: its name, owner, encoding and size,
+and which one the other tools are currently connected to.
+</what_it_returns>
+
+<important>
+- Sizes are only shown for databases the connected role may connect to
+- Listing a database does not connect to it; use test_connection to check
+  one, then query_database with "set default database to <connection
+  string>" to switch
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			connStr := dbClient.GetDefaultConnection()
+			if connStr == "" {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx, cancel := withQueryTimeout(requestContext(args), dbClient.QueryTimeout())
+			defer cancel()
+
+			var databases []clusterDatabase
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				rows, err := tx.Query(ctx, `
+					SELECT d.datname,
+					       pg_get_userbyid(d.datdba),
+					       pg_encoding_to_char(d.encoding),
+					       CASE WHEN has_database_privilege(d.oid, 'CONNECT')
+					            THEN pg_database_size(d.oid) END,
+					       d.datallowconn,
+					       d.datname = current_database()
+					FROM pg_database d
+					WHERE NOT d.datistemplate
+					ORDER BY d.datname`)
+				if err != nil {
+					return err
+				}
+				defer rows.Close()
+
+				for rows.Next() {
+					var db clusterDatabase
+					if err := rows.Scan(&db.name, &db.owner, &db.encoding, &db.size, &db.allowConn, &db.isConnected); err != nil {
+						return err
+					}
+					databases = append(databases, db)
+				}
+				return rows.Err()
+			})
+			if err != nil {
+				if isQueryTimeout(ctx, err) {
+					return mcp.NewToolError(queryTimeoutMessage(dbClient.QueryTimeout()))
+				}
+				return mcp.NewToolError(fmt.Sprintf("Database: %s\n\nFailed to list databases: %v", database.SanitizeConnStr(connStr), err))
+			}
+
+			logging.Info("list_databases_executed", "databases", len(databases))
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Connected to: %s\n\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(formatClusterDatabases(databases))
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// formatClusterDatabases renders the databases as TSV, with a note on
+// switching between them
+func formatClusterDatabases(databases []clusterDatabase) string {
+	results := make([][]interface{}, 0, len(databases))
+	for _, db := range databases {
+		size := "(no access)"
+		if db.size != nil {
+			size = formatBytes(*db.size)
+		}
+		connected := ""
+		if db.isConnected {
+			connected = "yes"
+		}
+		allowConn := "yes"
+		if !db.allowConn {
+			allowConn = "no"
+		}
+		results = append(results, []interface{}{db.name, db.owner, db.encoding, size, allowConn, connected})
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Databases (%d):\n", len(databases)))
+	sb.WriteString(FormatResultsAsTSV([]string{"database", "owner", "encoding", "size", "allows_connections", "connected"}, results))
+	sb.WriteString("\n\nTo switch, check the database with test_connection, then call query_database with " +
+		"\"set default database to <connection string>\" naming the new database.")
+	return sb.String()
+}
@@ -0,0 +1,63 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - List Databases Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/database"
+)
+
+func TestListDatabasesToolDefinition(t *testing.T) {
+	tool := ListDatabasesTool(nil)
+
+	if tool.Definition.Name != "list_databases" {
+		t.Errorf("Tool name = %v, want list_databases", tool.Definition.Name)
+	}
+	if len(tool.Definition.InputSchema.Properties) != 0 {
+		t.Errorf("Properties = %v, want none", tool.Definition.InputSchema.Properties)
+	}
+}
+
+func TestListDatabasesToolNotConnected(t *testing.T) {
+	tool := ListDatabasesTool(database.NewClient(nil))
+
+	response, err := tool.Handler(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !response.IsError {
+		t.Error("Expected error response without a connection")
+	}
+}
+
+func TestFormatClusterDatabases(t *testing.T) {
+	size := int64(8 * 1024 * 1024)
+	out := formatClusterDatabases([]clusterDatabase{
+		{name: "app", owner: "app_owner", encoding: "UTF8", size: &size, allowConn: true, isConnected: true},
+		{name: "hr", owner: "postgres", encoding: "UTF8", allowConn: true},
+		{name: "old", owner: "postgres", encoding: "SQL_ASCII", allowConn: false},
+	})
+
+	for _, want := range []string{
+		"Databases (3):\n",
+		"database\towner\tencoding\tsize\tallows_connections\tconnected\n",
+		"app\tapp_owner\tUTF8\t" + formatBytes(size) + "\tyes\tyes\n",
+		"hr\tpostgres\tUTF8\t(no access)\tyes\t\n",
+		"old\tpostgres\tSQL_ASCII\t(no access)\tno\t",
+		"test_connection",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// roleInfo is one role from pg_roles
+type roleInfo struct {
+	name        string
+	superuser   bool
+	login       bool
+	createDB    bool
+	createRole  bool
+	replication bool
+	bypassRLS   bool
+	connLimit   int32
+	validUntil  *time.Time
+	memberOf    []string
+}
+
+// tableGrant holds one grantee's privileges on a table
+type tableGrant struct {
+	grantee    string
+	privileges []string // privilege types; WITH GRANT OPTION ones are marked with *
+	grantors   []string
+}
+
+// ListRolesTool creates the list_roles tool for auditing roles and table
+// privileges
+func ListRolesTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "list_roles",
+			Description: `List database roles and their attributes, and optionally who has which privileges on a table.
+
+<usecase>
+Use when:
+- Auditing who can log in, who is a superuser, or who can create databases
+  and roles
+- Checking which roles can read or modify a specific table
+- Investigating a "permission denied" error
+</usecase>
+
+<what_it_returns>
+- Each role's superuser, login, createdb, createrole, replication and
+  bypassrls attributes, connection limit, password expiry and the roles it
+  is a member of
+- With table_name: the table's owner and the privileges granted on it, per
+  grantee, from information_schema.table_privileges
+</what_it_returns>
+
+<important>
+- Passwords are never shown; pg_roles masks them
+- information_schema.table_privileges only lists grants the connected role
+  can see: those made to or by it, a role it belongs to, or PUBLIC
+- Built-in pg_* roles are hidden unless include_system_roles=true
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"include_system_roles": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Include the built-in pg_* roles (default: false)",
+						"default":     false,
+					},
+					"schema_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Schema of table_name (default: public)",
+						"default":     "public",
+					},
+					"table_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Also list the privileges granted on this table",
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			includeSystem := ValidateBoolParam(args, "include_system_roles", false)
+			schemaName := ValidateOptionalStringParam(args, "schema_name", "public")
+			tableName := ValidateOptionalStringParam(args, "table_name", "")
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx, cancel := withQueryTimeout(requestContext(args), dbClient.QueryTimeout())
+			defer cancel()
+
+			var roles []roleInfo
+			var tableOwner string
+			var grants []tableGrant
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				var err error
+				roles, err = readRoles(ctx, tx, includeSystem)
+				if err != nil || tableName == "" {
+					return err
+				}
+				tableOwner, grants, err = readTableGrants(ctx, tx, schemaName, tableName)
+				return err
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				return mcp.NewToolError(fmt.Sprintf("Table '%s.%s' not found. Use describe_schema(schema_name=%q) to list its tables.",
+					schemaName, tableName, schemaName))
+			}
+			if err != nil {
+				if isQueryTimeout(ctx, err) {
+					return mcp.NewToolError(queryTimeoutMessage(dbClient.QueryTimeout()))
+				}
+				return mcp.NewToolError(fmt.Sprintf("Error reading roles: %v", err))
+			}
+
+			logging.Info("list_roles_executed",
+				"roles", len(roles),
+				"include_system_roles", includeSystem,
+				"table", tableName,
+				"grantees", len(grants),
+			)
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(formatRoles(roles))
+			if tableName != "" {
+				sb.WriteString("\n\n")
+				sb.WriteString(formatTableGrants(schemaName, tableName, tableOwner, grants))
+			}
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// readRoles reads the roles in pg_roles with the roles each is a member of
+func readRoles(ctx context.Context, tx pgx.Tx, includeSystem bool) ([]roleInfo, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT r.rolname, r.rolsuper, r.rolcanlogin, r.rolcreatedb,
+		       r.rolcreaterole, r.rolreplication, r.rolbypassrls,
+		       r.rolconnlimit, r.rolvaliduntil,
+		       ARRAY(SELECT g.rolname
+		             FROM pg_auth_members m
+		             JOIN pg_roles g ON g.oid = m.roleid
+		             WHERE m.member = r.oid
+		             ORDER BY g.rolname)
+		FROM pg_roles r
+		WHERE $1 OR r.rolname !~ '^pg_'
+		ORDER BY r.rolname`, includeSystem)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []roleInfo
+	for rows.Next() {
+		var r roleInfo
+		if err := rows.Scan(&r.name, &r.superuser, &r.login, &r.createDB,
+			&r.createRole, &r.replication, &r.bypassRLS,
+			&r.connLimit, &r.validUntil, &r.memberOf); err != nil {
+			return nil, err
+		}
+		roles = append(roles, r)
+	}
+	return roles, rows.Err()
+}
+
+// readTableGrants reads the owner of a table and the privileges granted on
+// it, grouped by grantee. It returns pgx.ErrNoRows if the table does not
+// exist.
+func readTableGrants(ctx context.Context, tx pgx.Tx, schemaName, tableName string) (string, []tableGrant, error) {
+	var owner string
+	err := tx.QueryRow(ctx, `
+		SELECT pg_get_userbyid(c.relowner)
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2
+		  AND c.relkind IN ('r', 'p', 'v', 'm', 'f')`, schemaName, tableName).Scan(&owner)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT grantee,
+		       array_agg(privilege_type || CASE WHEN is_grantable = 'YES' THEN '*' ELSE '' END
+		                 ORDER BY privilege_type),
+		       array_agg(DISTINCT grantor::text)
+		FROM information_schema.table_privileges
+		WHERE table_schema = $1 AND table_name = $2
+		GROUP BY grantee
+		ORDER BY grantee`, schemaName, tableName)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+
+	var grants []tableGrant
+	for rows.Next() {
+		var g tableGrant
+		if err := rows.Scan(&g.grantee, &g.privileges, &g.grantors); err != nil {
+			return "", nil, err
+		}
+		grants = append(grants, g)
+	}
+	return owner, grants, rows.Err()
+}
+
+// formatRoles renders the roles as TSV
+func formatRoles(roles []roleInfo) string {
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+
+	results := make([][]interface{}, 0, len(roles))
+	for _, r := range roles {
+		connLimit := "unlimited"
+		if r.connLimit >= 0 {
+			connLimit = fmt.Sprintf("%d", r.connLimit)
+		}
+		validUntil := ""
+		if r.validUntil != nil {
+			validUntil = r.validUntil.UTC().Format(time.RFC3339)
+		}
+		results = append(results, []interface{}{
+			r.name, yesNo(r.superuser), yesNo(r.login), yesNo(r.createDB),
+			yesNo(r.createRole), yesNo(r.replication), yesNo(r.bypassRLS),
+			connLimit, validUntil, strings.Join(r.memberOf, ", "),
+		})
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Roles (%d):\n", len(roles)))
+	sb.WriteString(FormatResultsAsTSV([]string{
+		"role", "superuser", "login", "createdb", "createrole", "replication",
+		"bypassrls", "connection_limit", "valid_until", "member_of",
+	}, results))
+	return sb.String()
+}
+
+// formatTableGrants renders the privileges granted on a table as TSV
+func formatTableGrants(schemaName, tableName, owner string, grants []tableGrant) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Privileges on %s.%s (owner: %s):\n", schemaName, tableName, owner))
+	if len(grants) == 0 {
+		sb.WriteString("No grants visible to the connected role.")
+		return sb.String()
+	}
+
+	results := make([][]interface{}, 0, len(grants))
+	for _, g := range grants {
+		results = append(results, []interface{}{g.grantee, strings.Join(g.privileges, ", "), strings.Join(g.grantors, ", ")})
+	}
+	sb.WriteString(FormatResultsAsTSV([]string{"grantee", "privileges", "granted_by"}, results))
+	sb.WriteString("\n\n* = WITH GRANT OPTION. The owner holds all privileges implicitly.")
+	return sb.String()
+}
@@ -0,0 +1,89 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - List Roles Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pgedge-postgres-mcp/internal/database"
+)
+
+func TestListRolesToolDefinition(t *testing.T) {
+	tool := ListRolesTool(nil)
+
+	if tool.Definition.Name != "list_roles" {
+		t.Errorf("Tool name = %v, want list_roles", tool.Definition.Name)
+	}
+	for _, prop := range []string{"include_system_roles", "schema_name", "table_name"} {
+		if _, ok := tool.Definition.InputSchema.Properties[prop]; !ok {
+			t.Errorf("missing property %q", prop)
+		}
+	}
+	if len(tool.Definition.InputSchema.Required) != 0 {
+		t.Errorf("Required = %v, want none", tool.Definition.InputSchema.Required)
+	}
+}
+
+func TestListRolesToolNotConnected(t *testing.T) {
+	tool := ListRolesTool(database.NewClient(nil))
+
+	response, err := tool.Handler(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !response.IsError {
+		t.Error("Expected error response without a connection")
+	}
+}
+
+func TestFormatRoles(t *testing.T) {
+	expiry := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	out := formatRoles([]roleInfo{
+		{name: "postgres", superuser: true, login: true, createDB: true, createRole: true, replication: true, bypassRLS: true, connLimit: -1},
+		{name: "report", login: true, connLimit: 5, validUntil: &expiry, memberOf: []string{"pg_read_all_data", "readers"}},
+	})
+
+	for _, want := range []string{
+		"Roles (2):\n",
+		"role\tsuperuser\tlogin\tcreatedb\tcreaterole\treplication\tbypassrls\tconnection_limit\tvalid_until\tmember_of\n",
+		"postgres\tyes\tyes\tyes\tyes\tyes\tyes\tunlimited\t\t\n",
+		"report\tno\tyes\tno\tno\tno\tno\t5\t2026-01-01T00:00:00Z\tpg_read_all_data, readers",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatTableGrants(t *testing.T) {
+	out := formatTableGrants("public", "orders", "app_owner", []tableGrant{
+		{grantee: "reporting", privileges: []string{"SELECT"}, grantors: []string{"app_owner"}},
+		{grantee: "writer", privileges: []string{"INSERT*", "SELECT", "UPDATE"}, grantors: []string{"app_owner"}},
+	})
+	for _, want := range []string{
+		"Privileges on public.orders (owner: app_owner):\n",
+		"grantee\tprivileges\tgranted_by\n",
+		"reporting\tSELECT\tapp_owner\n",
+		"writer\tINSERT*, SELECT, UPDATE\tapp_owner",
+		"WITH GRANT OPTION",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+
+	out = formatTableGrants("public", "orders", "app_owner", nil)
+	if !strings.Contains(out, "No grants visible") {
+		t.Errorf("expected a note when no grants are visible, got:\n%s", out)
+	}
+}
@@ -0,0 +1,385 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// lockGraphQueryLength is the maximum query text length shown per node
+const lockGraphQueryLength = 120
+
+// lockWaitNode is a backend taking part in lock contention, either waiting
+// for a lock, holding one that others wait for, or both
+type lockWaitNode struct {
+	PID       int
+	User      string
+	State     string
+	Query     string
+	Duration  float64 // seconds since the transaction (or query) started
+	WaitingOn string  // "mode on target" for the lock being waited for
+	BlockedBy []int
+}
+
+// LockWaitGraphTool creates the lock_wait_graph tool
+func LockWaitGraphTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "lock_wait_graph",
+			Description: `Build the waits-for graph of blocked sessions and detect lock cycles.
+
+<usecase>
+Use during lock contention incidents:
+- Find the head blocker(s) that everything else is queued behind
+- See the full chain of who waits for whom, not just direct blockers
+- Spot cycles (potential deadlocks) before deadlock_timeout resolves them
+</usecase>
+
+<what_it_returns>
+- Each blocking tree, rooted at a session that holds locks but isn't waiting
+- For each session: PID, user, state, transaction duration, the lock it is
+  waiting for, and its (truncated) query text
+- Any cycles in the graph, listed separately
+- With format='mermaid', a Mermaid flowchart of the same graph
+</what_it_returns>
+
+<important>
+- Uses pg_blocking_pids(), so it reflects both heavyweight lock conflicts
+  and waits queued behind other waiters
+- Query text of other users' sessions is only visible to superusers or
+  members of pg_read_all_stats
+- The graph is a snapshot; contention can change between calls
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"format": map[string]interface{}{
+						"type":        "string",
+						"description": "Output format: 'tree' (indented text) or 'mermaid' (flowchart). Default: 'tree'",
+						"default":     "tree",
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			format := strings.ToLower(ValidateOptionalStringParam(args, "format", "tree"))
+			if format != "tree" && format != "mermaid" {
+				return mcp.NewToolError(fmt.Sprintf("Unsupported format %q: use 'tree' or 'mermaid'", format))
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+			nodes := make(map[int]*lockWaitNode)
+
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				rows, err := tx.Query(ctx, `
+					WITH waiting AS (
+						SELECT pid, pg_blocking_pids(pid) AS blockers
+						FROM pg_stat_activity
+						WHERE cardinality(pg_blocking_pids(pid)) > 0
+					),
+					involved AS (
+						SELECT pid FROM waiting
+						UNION
+						SELECT unnest(blockers) FROM waiting
+					)
+					SELECT a.pid, COALESCE(a.usename, ''), COALESCE(a.state, ''), COALESCE(a.query, ''),
+					       COALESCE(EXTRACT(EPOCH FROM now() - COALESCE(a.xact_start, a.query_start))::float8, 0),
+					       COALESCE(l.mode || ' on ' || COALESCE(l.relation::regclass::text, l.locktype), ''),
+					       COALESCE(w.blockers, '{}')
+					FROM involved i
+					JOIN pg_stat_activity a ON a.pid = i.pid
+					LEFT JOIN waiting w ON w.pid = a.pid
+					LEFT JOIN LATERAL (
+						SELECT mode, relation, locktype
+						FROM pg_locks
+						WHERE pid = a.pid AND NOT granted
+						LIMIT 1
+					) l ON true
+					ORDER BY a.pid`)
+				if err != nil {
+					return fmt.Errorf("failed to query blocked sessions: %w", err)
+				}
+				defer rows.Close()
+
+				for rows.Next() {
+					var node lockWaitNode
+					var blockers []int32
+					if err := rows.Scan(&node.PID, &node.User, &node.State, &node.Query,
+						&node.Duration, &node.WaitingOn, &blockers); err != nil {
+						return fmt.Errorf("failed to scan session: %w", err)
+					}
+					for _, b := range blockers {
+						node.BlockedBy = append(node.BlockedBy, int(b))
+					}
+					nodes[node.PID] = &node
+				}
+				return rows.Err()
+			})
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Error building lock wait graph: %v", err))
+			}
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+
+			if len(nodes) == 0 {
+				sb.WriteString("No sessions are currently waiting on locks.\n")
+				return mcp.NewToolSuccess(sb.String())
+			}
+
+			cycles := findLockCycles(nodes)
+
+			waiting := 0
+			for _, n := range nodes {
+				if len(n.BlockedBy) > 0 {
+					waiting++
+				}
+			}
+			sb.WriteString(fmt.Sprintf("%d session(s) waiting, %d session(s) involved, %d cycle(s)\n\n",
+				waiting, len(nodes), len(cycles)))
+
+			if format == "mermaid" {
+				sb.WriteString(renderLockMermaid(nodes))
+			} else {
+				sb.WriteString(renderLockTree(nodes))
+			}
+
+			if len(cycles) > 0 {
+				sb.WriteString("\n<warnings>\n")
+				for _, cycle := range cycles {
+					pids := make([]string, 0, len(cycle)+1)
+					for _, pid := range cycle {
+						pids = append(pids, fmt.Sprintf("%d", pid))
+					}
+					pids = append(pids, pids[0])
+					sb.WriteString(fmt.Sprintf("⚠️  Lock cycle (potential deadlock): %s\n", strings.Join(pids, " -> ")))
+				}
+				sb.WriteString("PostgreSQL cancels one participant after deadlock_timeout; a cycle that persists usually\n")
+				sb.WriteString("involves a waiter queued behind another waiter rather than a true deadlock.\n")
+				sb.WriteString("</warnings>\n")
+			}
+
+			logging.Info("lock_wait_graph_executed",
+				"format", format,
+				"sessions", len(nodes),
+				"waiting", waiting,
+				"cycles", len(cycles),
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// findLockCycles returns each cycle in the waits-for graph once, as a list of
+// PIDs starting from the lowest PID in the cycle
+func findLockCycles(nodes map[int]*lockWaitNode) [][]int {
+	const (
+		unvisited = iota
+		inPath
+		done
+	)
+
+	state := make(map[int]int, len(nodes))
+	seen := make(map[string]bool)
+	var cycles [][]int
+	var path []int
+
+	var visit func(pid int)
+	visit = func(pid int) {
+		state[pid] = inPath
+		path = append(path, pid)
+
+		if node, ok := nodes[pid]; ok {
+			for _, next := range node.BlockedBy {
+				switch state[next] {
+				case inPath:
+					// Back edge: the cycle is the path from next to here
+					start := 0
+					for path[start] != next {
+						start++
+					}
+					cycle := normalizeCycle(path[start:])
+					key := fmt.Sprint(cycle)
+					if !seen[key] {
+						seen[key] = true
+						cycles = append(cycles, cycle)
+					}
+				case unvisited:
+					visit(next)
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[pid] = done
+	}
+
+	for _, pid := range sortedLockPIDs(nodes) {
+		if state[pid] == unvisited {
+			visit(pid)
+		}
+	}
+
+	return cycles
+}
+
+// normalizeCycle rotates a cycle so it starts at its lowest PID
+func normalizeCycle(cycle []int) []int {
+	minIdx := 0
+	for i, pid := range cycle {
+		if pid < cycle[minIdx] {
+			minIdx = i
+		}
+	}
+	out := make([]int, 0, len(cycle))
+	out = append(out, cycle[minIdx:]...)
+	out = append(out, cycle[:minIdx]...)
+	return out
+}
+
+// renderLockTree renders the graph as indented trees, each rooted at a
+// session that blocks others without waiting itself. Sessions that are only
+// reachable through a cycle are rendered from the lowest PID in the cycle.
+func renderLockTree(nodes map[int]*lockWaitNode) string {
+	waiters := lockWaiters(nodes)
+	rendered := make(map[int]bool)
+	var sb strings.Builder
+
+	var render func(pid int, depth int, onPath map[int]bool)
+	render = func(pid int, depth int, onPath map[int]bool) {
+		indent := strings.Repeat("    ", depth)
+		prefix := ""
+		if depth > 0 {
+			prefix = "└─ waits: "
+		}
+		if onPath[pid] {
+			sb.WriteString(fmt.Sprintf("%s%sPID %d (cycle)\n", indent, prefix, pid))
+			return
+		}
+		rendered[pid] = true
+		sb.WriteString(fmt.Sprintf("%s%s%s\n", indent, prefix, describeLockNode(nodes[pid])))
+
+		onPath[pid] = true
+		for _, w := range waiters[pid] {
+			render(w, depth+1, onPath)
+		}
+		delete(onPath, pid)
+	}
+
+	for _, pid := range sortedLockPIDs(nodes) {
+		if len(nodes[pid].BlockedBy) == 0 {
+			render(pid, 0, make(map[int]bool))
+			sb.WriteString("\n")
+		}
+	}
+	// Anything left is only part of a cycle (or blocked by a cycle)
+	for _, pid := range sortedLockPIDs(nodes) {
+		if !rendered[pid] {
+			render(pid, 0, make(map[int]bool))
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// renderLockMermaid renders the graph as a Mermaid flowchart where an edge
+// A --> B means A waits for B
+func renderLockMermaid(nodes map[int]*lockWaitNode) string {
+	var sb strings.Builder
+	sb.WriteString("```mermaid\nflowchart LR\n")
+	for _, pid := range sortedLockPIDs(nodes) {
+		node := nodes[pid]
+		lines := []string{fmt.Sprintf("PID %d · %s · %.1fs", pid, node.User, node.Duration)}
+		if node.WaitingOn != "" {
+			lines = append(lines, "waiting: "+node.WaitingOn)
+		}
+		lines = append(lines, truncateLockQuery(node.Query))
+		for i, line := range lines {
+			lines[i] = escapeMermaidLabel(line)
+		}
+		sb.WriteString(fmt.Sprintf("    p%d[\"%s\"]\n", pid, strings.Join(lines, "<br/>")))
+	}
+	for _, pid := range sortedLockPIDs(nodes) {
+		for _, blocker := range nodes[pid].BlockedBy {
+			sb.WriteString(fmt.Sprintf("    p%d -->|waits for| p%d\n", pid, blocker))
+		}
+	}
+	sb.WriteString("```\n")
+	return sb.String()
+}
+
+// lockWaiters maps each blocking PID to the sorted PIDs waiting on it
+func lockWaiters(nodes map[int]*lockWaitNode) map[int][]int {
+	waiters := make(map[int][]int)
+	for _, pid := range sortedLockPIDs(nodes) {
+		for _, blocker := range nodes[pid].BlockedBy {
+			waiters[blocker] = append(waiters[blocker], pid)
+		}
+	}
+	return waiters
+}
+
+// describeLockNode formats a single session for the tree view
+func describeLockNode(node *lockWaitNode) string {
+	desc := fmt.Sprintf("PID %d [%s, %s, %.1fs]", node.PID, node.User, node.State, node.Duration)
+	if node.WaitingOn != "" {
+		desc += " waiting for " + node.WaitingOn
+	}
+	return desc + ": " + truncateLockQuery(node.Query)
+}
+
+// truncateLockQuery collapses whitespace and shortens query text for display
+func truncateLockQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" {
+		return "<no query text>"
+	}
+	runes := []rune(query)
+	if len(runes) > lockGraphQueryLength {
+		return string(runes[:lockGraphQueryLength]) + "..."
+	}
+	return query
+}
+
+// escapeMermaidLabel makes text safe inside a quoted Mermaid node label
+func escapeMermaidLabel(label string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(label)
+}
+
+// sortedLockPIDs returns the graph's PIDs in ascending order so output is stable
+func sortedLockPIDs(nodes map[int]*lockWaitNode) []int {
+	pids := make([]int, 0, len(nodes))
+	for pid := range nodes {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	return pids
+}
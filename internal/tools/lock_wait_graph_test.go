@@ -0,0 +1,122 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Lock Wait Graph Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLockWaitGraphToolDefinition(t *testing.T) {
+	tool := LockWaitGraphTool(nil)
+
+	if tool.Definition.Name != "lock_wait_graph" {
+		t.Errorf("Tool name = %v, want lock_wait_graph", tool.Definition.Name)
+	}
+
+	if _, exists := tool.Definition.InputSchema.Properties["format"]; !exists {
+		t.Error("Missing property: format")
+	}
+}
+
+func TestLockWaitGraphInvalidFormat(t *testing.T) {
+	tool := LockWaitGraphTool(nil)
+
+	response, err := tool.Handler(map[string]interface{}{"format": "graphviz"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !response.IsError {
+		t.Error("Expected error response for unsupported format")
+	}
+}
+
+// chainNodes builds 100 <- 200 <- 300 (300 waits for 200, which waits for 100)
+func chainNodes() map[int]*lockWaitNode {
+	return map[int]*lockWaitNode{
+		100: {PID: 100, User: "app", State: "idle in transaction", Query: "UPDATE t SET x = 1"},
+		200: {PID: 200, User: "app", State: "active", Query: "UPDATE t SET x = 2", WaitingOn: "RowExclusiveLock on t", BlockedBy: []int{100}},
+		300: {PID: 300, User: "report", State: "active", Query: "SELECT * FROM t", WaitingOn: "AccessShareLock on t", BlockedBy: []int{200}},
+	}
+}
+
+func TestFindLockCycles(t *testing.T) {
+	if cycles := findLockCycles(chainNodes()); len(cycles) != 0 {
+		t.Errorf("Expected no cycles in a chain, got %v", cycles)
+	}
+
+	nodes := map[int]*lockWaitNode{
+		10: {PID: 10, BlockedBy: []int{30}},
+		20: {PID: 20, BlockedBy: []int{10}},
+		30: {PID: 30, BlockedBy: []int{20}},
+		40: {PID: 40, BlockedBy: []int{30}},
+	}
+	cycles := findLockCycles(nodes)
+	if len(cycles) != 1 {
+		t.Fatalf("Expected 1 cycle, got %v", cycles)
+	}
+	if !reflect.DeepEqual(cycles[0], []int{10, 30, 20}) {
+		t.Errorf("Unexpected cycle: %v", cycles[0])
+	}
+}
+
+func TestRenderLockTree(t *testing.T) {
+	tree := renderLockTree(chainNodes())
+
+	lines := strings.Split(strings.TrimSpace(tree), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d:\n%s", len(lines), tree)
+	}
+	if !strings.HasPrefix(lines[0], "PID 100 ") {
+		t.Errorf("Expected head blocker first, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[2], "        └─ waits: PID 300 ") {
+		t.Errorf("Expected PID 300 nested two levels deep, got %q", lines[2])
+	}
+}
+
+func TestRenderLockTree_Cycle(t *testing.T) {
+	nodes := map[int]*lockWaitNode{
+		1: {PID: 1, BlockedBy: []int{2}},
+		2: {PID: 2, BlockedBy: []int{1}},
+	}
+
+	tree := renderLockTree(nodes)
+	if !strings.Contains(tree, "PID 1 (cycle)") {
+		t.Errorf("Expected cycle marker, got:\n%s", tree)
+	}
+}
+
+func TestRenderLockMermaid(t *testing.T) {
+	nodes := chainNodes()
+	nodes[300].Query = `SELECT "x" FROM t WHERE a < 1`
+
+	out := renderLockMermaid(nodes)
+	for _, want := range []string{"flowchart LR", "p300 -->|waits for| p200", "p200 -->|waits for| p100", "#quot;x#quot;", "a #lt; 1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTruncateLockQuery(t *testing.T) {
+	if got := truncateLockQuery("SELECT  1\n  FROM t"); got != "SELECT 1 FROM t" {
+		t.Errorf("Unexpected whitespace handling: %q", got)
+	}
+	if got := truncateLockQuery(""); got != "<no query text>" {
+		t.Errorf("Unexpected empty query text: %q", got)
+	}
+	long := truncateLockQuery(strings.Repeat("x", 500))
+	if len(long) != lockGraphQueryLength+3 {
+		t.Errorf("Expected truncated length %d, got %d", lockGraphQueryLength+3, len(long))
+	}
+}
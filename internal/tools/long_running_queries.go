@@ -0,0 +1,304 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// defaultLongRunningSeconds is the minimum query duration listed when
+	// min_duration_seconds is not given
+	defaultLongRunningSeconds = 60
+
+	// defaultLongRunningLimit is the number of queries listed when no limit is given
+	defaultLongRunningLimit = 20
+
+	// longRunningQueryLength is the maximum query text length listed
+	longRunningQueryLength = 200
+)
+
+// Actions accepted by long_running_queries
+const (
+	longRunningActionList      = "list"
+	longRunningActionCancel    = "cancel"
+	longRunningActionTerminate = "terminate"
+)
+
+// LongRunningQueriesTool creates the long_running_queries tool. cancel and
+// terminate are refused unless builtins.tools.signal_backends is enabled.
+func LongRunningQueriesTool(dbClient *database.Client, cfg *config.Config) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "long_running_queries",
+			Description: `List queries running longer than a threshold, and cancel or terminate one.
+
+<usecase>
+Use during incidents:
+- Find the queries that have been running for a long time
+- Find sessions left idle in a transaction, holding locks and snapshots
+- Cancel a runaway query (action="cancel") or, if that fails, end its
+  session (action="terminate")
+</usecase>
+
+<what_it_returns>
+With action="list" (the default): PID, user, database, application, state,
+duration, wait event and query text of each non-idle session whose current
+query started more than min_duration_seconds ago, longest first.
+With action="cancel" or "terminate": the target session and whether
+pg_cancel_backend / pg_terminate_backend succeeded.
+</what_it_returns>
+
+<important>
+- cancel and terminate are only available when the server enables them
+  (builtins.tools.signal_backends)
+- cancel and terminate require pid and confirm=true; always list first and
+  confirm the PID with the user before acting
+- cancel stops the current query only; terminate closes the whole session
+  and rolls back its transaction
+- The server refuses to act on its own connections
+- Signalling another role's backend requires superuser or pg_signal_backend
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"action": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{longRunningActionList, longRunningActionCancel, longRunningActionTerminate},
+						"description": "What to do: 'list' (default), 'cancel' the query of pid, or 'terminate' the session of pid",
+						"default":     longRunningActionList,
+					},
+					"min_duration_seconds": map[string]interface{}{
+						"type":        "number",
+						"description": "List queries running at least this long (default: 60)",
+						"default":     defaultLongRunningSeconds,
+						"minimum":     0,
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of queries to list (default: 20)",
+						"default":     defaultLongRunningLimit,
+						"minimum":     1,
+					},
+					"pid": map[string]interface{}{
+						"type":        "integer",
+						"description": "Backend PID to cancel or terminate (required for those actions)",
+					},
+					"confirm": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Must be true to cancel or terminate; guards against accidental calls",
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			action := ValidateOptionalStringParam(args, "action", longRunningActionList)
+			switch action {
+			case longRunningActionList, longRunningActionCancel, longRunningActionTerminate:
+			default:
+				return mcp.NewToolError(fmt.Sprintf("Invalid action '%s': must be list, cancel or terminate", action))
+			}
+			signalsEnabled := cfg != nil && cfg.Builtins.Tools.IsSignalBackendsEnabled()
+			if action != longRunningActionList && !signalsEnabled {
+				return mcp.NewToolError(fmt.Sprintf("Refusing to %s: cancelling and terminating sessions is disabled on this server "+
+					"(builtins.tools.signal_backends: false)", action))
+			}
+
+			minSeconds := float64(defaultLongRunningSeconds)
+			if val, ok := args["min_duration_seconds"].(float64); ok {
+				if val < 0 {
+					return mcp.NewToolError("Parameter 'min_duration_seconds' must not be negative")
+				}
+				minSeconds = val
+			}
+
+			limit := defaultLongRunningLimit
+			if val, ok := args["limit"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'limit' must be a positive integer")
+				}
+				limit = int(val)
+			}
+
+			pid := 0
+			if action != longRunningActionList {
+				val, ok := args["pid"].(float64)
+				if !ok || val < 1 || val != float64(int(val)) {
+					return mcp.NewToolError(fmt.Sprintf("Parameter 'pid' must be a backend PID to %s", action))
+				}
+				pid = int(val)
+
+				if confirm, _ := args["confirm"].(bool); !confirm {
+					return mcp.NewToolError(fmt.Sprintf("Refusing to %s PID %d without confirm=true. "+
+						"Check the PID with action=\"list\" and confirm with the user first.", action, pid))
+				}
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+
+			if action == longRunningActionList {
+				return listLongRunningQueries(ctx, dbClient, pool, connStr, minSeconds, limit, signalsEnabled)
+			}
+			return signalBackend(ctx, dbClient, pool, connStr, action, pid)
+		},
+	}
+}
+
+// listLongRunningQueries lists non-idle client sessions whose current query
+// started at least minSeconds ago, longest first. signalsEnabled says
+// whether to point the caller at cancel and terminate.
+func listLongRunningQueries(ctx context.Context, dbClient *database.Client, pool *pgxpool.Pool, connStr string, minSeconds float64, limit int, signalsEnabled bool) (mcp.ToolResponse, error) {
+	var results [][]interface{}
+	err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT pid, COALESCE(usename, ''), COALESCE(datname, ''), COALESCE(application_name, ''),
+			       COALESCE(state, ''), date_trunc('second', now() - query_start)::text,
+			       COALESCE(wait_event_type || ':' || wait_event, ''), COALESCE(query, '')
+			FROM pg_stat_activity
+			WHERE backend_type = 'client backend'
+			  AND state <> 'idle'
+			  AND pid <> pg_backend_pid()
+			  AND query_start < now() - make_interval(secs => $1)
+			ORDER BY query_start
+			LIMIT $2`, minSeconds, limit)
+		if err != nil {
+			return fmt.Errorf("failed to query pg_stat_activity: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var pid int
+			var user, db, app, state, duration, wait, query string
+			if err := rows.Scan(&pid, &user, &db, &app, &state, &duration, &wait, &query); err != nil {
+				return fmt.Errorf("failed to scan session: %w", err)
+			}
+			results = append(results, []interface{}{pid, user, db, app, state, duration, wait, truncateActivityQuery(query)})
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return mcp.NewToolError(fmt.Sprintf("Error listing long-running queries: %v", err))
+	}
+
+	logging.Info("long_running_queries_executed",
+		"action", longRunningActionList,
+		"min_duration_seconds", minSeconds,
+		"queries", len(results),
+	)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+	if len(results) == 0 {
+		sb.WriteString(fmt.Sprintf("No queries have been running for more than %g seconds.\n", minSeconds))
+		return mcp.NewToolSuccess(sb.String())
+	}
+
+	sb.WriteString(fmt.Sprintf("Queries running longer than %g seconds (%d):\n", minSeconds, len(results)))
+	sb.WriteString(FormatResultsAsTSV(
+		[]string{"pid", "user", "database", "application", "state", "duration", "wait_event", "query"},
+		results))
+	if signalsEnabled {
+		sb.WriteString("\n\nTo stop one, call long_running_queries with action=\"cancel\" (or \"terminate\"), the pid, and confirm=true.\n")
+	} else {
+		sb.WriteString("\n")
+	}
+	return mcp.NewToolSuccess(sb.String())
+}
+
+// errOwnBackend is returned when asked to signal one of the server's own connections
+var errOwnBackend = errors.New("own backend")
+
+// signalBackend cancels or terminates a backend after checking that it
+// exists and isn't one of this server's own connections: the backend
+// running the check, or any connection the server's pools hold
+func signalBackend(ctx context.Context, dbClient *database.Client, pool *pgxpool.Pool, connStr, action string, pid int) (mcp.ToolResponse, error) {
+	var user, state, query string
+	var signalled bool
+	err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+		if uint32(pid) == tx.Conn().PgConn().PID() || database.IsServerBackend(uint32(pid)) {
+			return errOwnBackend
+		}
+		err := tx.QueryRow(ctx, `
+			SELECT COALESCE(usename, ''), COALESCE(state, ''), COALESCE(query, '')
+			FROM pg_stat_activity
+			WHERE pid = $1`, pid).Scan(&user, &state, &query)
+		if err != nil {
+			return err
+		}
+
+		fn := "pg_cancel_backend"
+		if action == longRunningActionTerminate {
+			fn = "pg_terminate_backend"
+		}
+		return tx.QueryRow(ctx, fmt.Sprintf("SELECT %s($1)", fn), pid).Scan(&signalled)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return mcp.NewToolError(fmt.Sprintf("No session with PID %d (it may already have finished)", pid))
+	}
+	if errors.Is(err, errOwnBackend) {
+		return mcp.NewToolError(fmt.Sprintf("Refusing to %s PID %d: it is one of this server's own connections", action, pid))
+	}
+	if err != nil {
+		return mcp.NewToolError(fmt.Sprintf("Failed to %s PID %d: %v", action, pid, err))
+	}
+
+	logging.Info("long_running_queries_executed",
+		"action", action,
+		"pid", pid,
+		"target_user", user,
+		"signalled", signalled,
+	)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+	sb.WriteString(fmt.Sprintf("Target: PID %d (user %s, state %s)\n", pid, user, state))
+	sb.WriteString(fmt.Sprintf("Query: %s\n\n", truncateActivityQuery(query)))
+	switch {
+	case !signalled:
+		sb.WriteString(fmt.Sprintf("The %s request was not delivered: the session may have ended in the meantime.\n", action))
+	case action == longRunningActionCancel:
+		sb.WriteString("Cancel request sent. The query stops at its next interrupt check; the session stays connected.\n")
+	default:
+		sb.WriteString("Terminate request sent. The session is closed and its open transaction rolled back.\n")
+	}
+	return mcp.NewToolSuccess(sb.String())
+}
+
+// truncateActivityQuery collapses whitespace and shortens query text
+func truncateActivityQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	runes := []rune(query)
+	if len(runes) > longRunningQueryLength {
+		return string(runes[:longRunningQueryLength]) + "..."
+	}
+	return query
+}
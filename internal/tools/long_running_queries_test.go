@@ -0,0 +1,96 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Long Running Queries Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/config"
+)
+
+func TestLongRunningQueriesToolDefinition(t *testing.T) {
+	tool := LongRunningQueriesTool(nil, nil)
+
+	if tool.Definition.Name != "long_running_queries" {
+		t.Errorf("Tool name = %v, want long_running_queries", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"action", "min_duration_seconds", "limit", "pid", "confirm"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+}
+
+func TestLongRunningQueriesValidation(t *testing.T) {
+	enabled := true
+	cfg := &config.Config{}
+	cfg.Builtins.Tools.SignalBackends = &enabled
+	tool := LongRunningQueriesTool(nil, cfg)
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"unknown action", map[string]interface{}{"action": "kill"}, "Invalid action"},
+		{"negative duration", map[string]interface{}{"min_duration_seconds": float64(-1)}, "min_duration_seconds"},
+		{"invalid limit", map[string]interface{}{"limit": float64(0)}, "limit"},
+		{"cancel without pid", map[string]interface{}{"action": "cancel", "confirm": true}, "pid"},
+		{"fractional pid", map[string]interface{}{"action": "cancel", "pid": float64(12.5), "confirm": true}, "pid"},
+		{"cancel without confirm", map[string]interface{}{"action": "cancel", "pid": float64(1234)}, "confirm=true"},
+		{"terminate with confirm false", map[string]interface{}{"action": "terminate", "pid": float64(1234), "confirm": false}, "confirm=true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := tool.Handler(tt.args)
+			if err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if !response.IsError {
+				t.Fatal("Expected error response")
+			}
+			if !strings.Contains(response.Content[0].Text, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, response.Content[0].Text)
+			}
+		})
+	}
+}
+
+func TestLongRunningQueriesSignalsDisabled(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{}
+	cfg.Builtins.Tools.SignalBackends = &disabled
+
+	for _, tool := range []Tool{LongRunningQueriesTool(nil, nil), LongRunningQueriesTool(nil, cfg)} {
+		for _, action := range []string{"cancel", "terminate"} {
+			response, err := tool.Handler(map[string]interface{}{"action": action, "pid": float64(1234), "confirm": true})
+			if err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if !response.IsError || !strings.Contains(response.Content[0].Text, "signal_backends") {
+				t.Errorf("%s: expected disabled error, got %+v", action, response)
+			}
+		}
+	}
+}
+
+func TestTruncateActivityQuery(t *testing.T) {
+	if got := truncateActivityQuery("SELECT *\n  FROM orders"); got != "SELECT * FROM orders" {
+		t.Errorf("truncateActivityQuery() = %q", got)
+	}
+	long := truncateActivityQuery(strings.Repeat("x", longRunningQueryLength+1))
+	if len([]rune(long)) != longRunningQueryLength+3 {
+		t.Errorf("Expected truncation to %d characters plus ellipsis, got %d", longRunningQueryLength, len(long))
+	}
+}
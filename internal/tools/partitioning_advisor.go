@@ -0,0 +1,489 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// defaultPartitionMinSizeMB is the total table size below which
+	// partitioning is not considered
+	defaultPartitionMinSizeMB = 1024
+
+	// maxAdvisorStatements caps the pg_stat_statements entries scanned for
+	// range filters, most called first
+	maxAdvisorStatements = 1000
+
+	// strongTimeCorrelation is the pg_stats correlation at or above which a
+	// time column is treated as following insert order (append-only data)
+	strongTimeCorrelation = 0.9
+)
+
+// partitionTimeColumn describes a date/time column of a candidate table
+type partitionTimeColumn struct {
+	name        string
+	dataType    string
+	correlation float64 // From pg_stats; 0 when unknown
+	rangeCalls  int64   // Calls of statements with a range filter on the column
+}
+
+// partitionCandidate describes a large, unpartitioned table
+type partitionCandidate struct {
+	schema, table string
+	totalBytes    int64
+	rows          float64
+	timeColumns   []partitionTimeColumn
+	primaryKey    []string
+}
+
+// advisorStatement is a pg_stat_statements entry
+type advisorStatement struct {
+	query string
+	calls int64
+}
+
+// PartitioningAdvisorTool creates the partitioning_advisor tool
+func PartitioningAdvisorTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "partitioning_advisor",
+			Description: `Find large tables that would benefit from range partitioning on a date/time column and suggest a key and DDL. Read-only; advisory.
+
+<usecase>
+Use when:
+- Large time-series or event tables are slow to query or maintain
+- Planning retention (dropping old data by partition instead of DELETE)
+- Reviewing which tables are already partitioned
+</usecase>
+
+<what_it_returns>
+- Tables already partitioned, with strategy and partition count
+- Large unpartitioned tables with their date/time columns, the number of
+  statement calls that filter each column by range (from
+  pg_stat_statements when installed) and how closely it follows insert
+  order
+- For each table where partitioning would help: the suggested key and
+  example DDL for a monthly range-partitioned copy
+</what_it_returns>
+
+<important>
+- Nothing is executed; the DDL is an example to adapt
+- Migrating data into the partitioned table must be planned separately
+- Primary keys and unique constraints on a partitioned table must include
+  the partition key
+- Without pg_stat_statements, suggestions rely on column statistics only
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"schema_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Only consider tables in this schema (default: all user schemas)",
+					},
+					"min_size_mb": map[string]interface{}{
+						"type":        "integer",
+						"description": "Only consider tables at least this large, including indexes and TOAST. Default: 1024",
+						"default":     defaultPartitionMinSizeMB,
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of candidate tables to analyze, largest first. Default: 10",
+						"default":     10,
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			schemaName := ValidateOptionalStringParam(args, "schema_name", "")
+
+			minSizeMB := defaultPartitionMinSizeMB
+			if val, ok := args["min_size_mb"].(float64); ok {
+				if val < 0 {
+					return mcp.NewToolError("Parameter 'min_size_mb' must not be negative")
+				}
+				minSizeMB = int(val)
+			}
+
+			limit := 10
+			if val, ok := args["limit"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'limit' must be a positive integer")
+				}
+				limit = int(val)
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+
+			var partitioned [][]interface{}
+			var candidates []partitionCandidate
+			haveStatements := false
+
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				var err error
+				partitioned, err = readPartitionedTables(ctx, tx, schemaName)
+				if err != nil {
+					return err
+				}
+
+				candidates, err = readPartitionCandidates(ctx, tx, schemaName, int64(minSizeMB)*1024*1024, limit)
+				if err != nil {
+					return err
+				}
+				if len(candidates) == 0 {
+					return nil
+				}
+
+				statements, ok, err := readAdvisorStatements(ctx, tx)
+				if err != nil {
+					return err
+				}
+				haveStatements = ok
+				for i := range candidates {
+					for j := range candidates[i].timeColumns {
+						col := &candidates[i].timeColumns[j]
+						col.rangeCalls = countRangeFilterCalls(statements, candidates[i].table, col.name)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Error analyzing partitioning: %v", err))
+			}
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+
+			if len(partitioned) > 0 {
+				sb.WriteString("Partitioned tables:\n")
+				sb.WriteString(FormatResultsAsTSV([]string{"schema", "table", "strategy", "partitions", "total_size"}, partitioned))
+				sb.WriteString("\n\n")
+			}
+
+			if len(candidates) == 0 {
+				sb.WriteString(fmt.Sprintf("No unpartitioned tables of %d MB or more found", minSizeMB))
+				if schemaName != "" {
+					sb.WriteString(fmt.Sprintf(" in schema '%s'", schemaName))
+				}
+				sb.WriteString("; partitioning is unlikely to help.\n")
+				return mcp.NewToolSuccess(sb.String())
+			}
+
+			if !haveStatements {
+				sb.WriteString("Note: pg_stat_statements is not installed or not readable, so range filters in the " +
+					"workload could not be checked; suggestions rely on column statistics only.\n\n")
+			}
+
+			var rows [][]interface{}
+			var recs []string
+			now := time.Now()
+			recommended := 0
+			for _, c := range candidates {
+				var colDescs []string
+				for _, col := range c.timeColumns {
+					colDescs = append(colDescs, fmt.Sprintf("%s %s (range calls: %d, correlation: %.2f)",
+						col.name, col.dataType, col.rangeCalls, col.correlation))
+				}
+				if len(colDescs) == 0 {
+					colDescs = append(colDescs, "none")
+				}
+
+				key, ok := choosePartitionKey(c.timeColumns)
+				assessment := "no date/time column"
+				suggested := ""
+				if ok {
+					suggested = key.name
+					assessment = "recommended"
+					recommended++
+
+					var sbRec strings.Builder
+					sbRec.WriteString(fmt.Sprintf("%s.%s (%s): range partition by %s; %s\n",
+						c.schema, c.table, formatBytes(c.totalBytes), key.name, partitionKeyReason(key)))
+					if len(c.primaryKey) > 0 && !slices.Contains(c.primaryKey, key.name) {
+						sbRec.WriteString(fmt.Sprintf("  The primary key (%s) must be extended to include %s.\n",
+							strings.Join(c.primaryKey, ", "), key.name))
+					}
+					sbRec.WriteString(buildPartitionDDL(c.schema, c.table, key.name, now))
+					recs = append(recs, sbRec.String())
+				} else if len(c.timeColumns) > 0 {
+					assessment = "no time-based access seen"
+				}
+
+				rows = append(rows, []interface{}{
+					c.schema, c.table, formatBytes(c.totalBytes), fmt.Sprintf("%.0f", math.Max(c.rows, 0)),
+					strings.Join(colDescs, "; "), suggested, assessment,
+				})
+			}
+
+			sb.WriteString("Large unpartitioned tables (largest first):\n")
+			sb.WriteString(FormatResultsAsTSV(
+				[]string{"schema", "table", "total_size", "rows", "time_columns", "suggested_key", "assessment"},
+				rows))
+			sb.WriteString("\n")
+
+			if len(recs) > 0 {
+				sb.WriteString("\n<recommendations>\n")
+				for _, r := range recs {
+					sb.WriteString(r + "\n")
+				}
+				sb.WriteString("Size partitions so each holds roughly 1-50 GB; use daily or weekly ranges for very " +
+					"high insert rates. Move data in batches (or with logical replication) and attach the old table " +
+					"as a partition only if its rows fit one range.\n")
+				sb.WriteString("</recommendations>\n")
+			}
+
+			logging.Info("partitioning_advisor_executed",
+				"schema", schemaName,
+				"candidates", len(candidates),
+				"recommended", recommended,
+				"pg_stat_statements", haveStatements,
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// readPartitionedTables lists partitioned tables with their strategy,
+// partition count and total size
+func readPartitionedTables(ctx context.Context, tx pgx.Tx, schemaName string) ([][]interface{}, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT n.nspname, c.relname,
+		       CASE pt.partstrat WHEN 'r' THEN 'range' WHEN 'l' THEN 'list' WHEN 'h' THEN 'hash' ELSE pt.partstrat::text END,
+		       (SELECT count(*) FROM pg_inherits i WHERE i.inhparent = c.oid),
+		       COALESCE((SELECT sum(pg_total_relation_size(i.inhrelid)) FROM pg_inherits i WHERE i.inhparent = c.oid), 0)::bigint
+		FROM pg_partitioned_table pt
+		JOIN pg_class c ON c.oid = pt.partrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE NOT c.relispartition
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND ($1 = '' OR n.nspname = $1)
+		ORDER BY n.nspname, c.relname`, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query partitioned tables: %w", err)
+	}
+	defer rows.Close()
+
+	var result [][]interface{}
+	for rows.Next() {
+		var schema, table, strategy string
+		var partitions, size int64
+		if err := rows.Scan(&schema, &table, &strategy, &partitions, &size); err != nil {
+			return nil, fmt.Errorf("failed to scan partitioned table: %w", err)
+		}
+		result = append(result, []interface{}{schema, table, strategy, partitions, formatBytes(size)})
+	}
+	return result, rows.Err()
+}
+
+// readPartitionCandidates lists unpartitioned tables of at least minBytes
+// with their date/time columns and primary key
+func readPartitionCandidates(ctx context.Context, tx pgx.Tx, schemaName string, minBytes int64, limit int) ([]partitionCandidate, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.oid, n.nspname, c.relname, pg_total_relation_size(c.oid), c.reltuples::float8
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind = 'r'
+		  AND NOT c.relispartition
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND n.nspname NOT LIKE 'pg_toast%'
+		  AND ($1 = '' OR n.nspname = $1)
+		  AND pg_total_relation_size(c.oid) >= $2
+		ORDER BY pg_total_relation_size(c.oid) DESC
+		LIMIT $3`, schemaName, minBytes, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+
+	var oids []uint32
+	var candidates []partitionCandidate
+	for rows.Next() {
+		var oid uint32
+		var c partitionCandidate
+		if err := rows.Scan(&oid, &c.schema, &c.table, &c.totalBytes, &c.rows); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan table: %w", err)
+		}
+		oids = append(oids, oid)
+		candidates = append(candidates, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, oid := range oids {
+		colRows, err := tx.Query(ctx, `
+			SELECT a.attname, format_type(a.atttypid, a.atttypmod), COALESCE(s.correlation, 0)::float8
+			FROM pg_attribute a
+			JOIN pg_class c ON c.oid = a.attrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			LEFT JOIN pg_stats s ON s.schemaname = n.nspname AND s.tablename = c.relname AND s.attname = a.attname
+			WHERE a.attrelid = $1
+			  AND a.attnum > 0
+			  AND NOT a.attisdropped
+			  AND a.atttypid IN ('date'::regtype, 'timestamp'::regtype, 'timestamptz'::regtype)
+			ORDER BY a.attnum`, oid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query columns of %s.%s: %w", candidates[i].schema, candidates[i].table, err)
+		}
+		for colRows.Next() {
+			var col partitionTimeColumn
+			if err := colRows.Scan(&col.name, &col.dataType, &col.correlation); err != nil {
+				colRows.Close()
+				return nil, fmt.Errorf("failed to scan column: %w", err)
+			}
+			candidates[i].timeColumns = append(candidates[i].timeColumns, col)
+		}
+		colRows.Close()
+		if err := colRows.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := tx.QueryRow(ctx, `
+			SELECT COALESCE(array_agg(a.attname ORDER BY k.ord), '{}')
+			FROM pg_index i
+			CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, ord)
+			JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+			WHERE i.indrelid = $1 AND i.indisprimary`, oid).Scan(&candidates[i].primaryKey); err != nil {
+			return nil, fmt.Errorf("failed to query primary key of %s.%s: %w", candidates[i].schema, candidates[i].table, err)
+		}
+	}
+
+	return candidates, nil
+}
+
+// readAdvisorStatements returns the most called pg_stat_statements entries.
+// ok is false when the view isn't installed or readable.
+func readAdvisorStatements(ctx context.Context, tx pgx.Tx) (statements []advisorStatement, ok bool, err error) {
+	var readable bool
+	if err := tx.QueryRow(ctx, `
+		SELECT COALESCE(has_table_privilege(to_regclass('pg_stat_statements'), 'SELECT'), false)`).Scan(&readable); err != nil {
+		return nil, false, fmt.Errorf("failed to check for pg_stat_statements: %w", err)
+	}
+	if !readable {
+		return nil, false, nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT query, calls FROM pg_stat_statements
+		WHERE query IS NOT NULL
+		ORDER BY calls DESC
+		LIMIT $1`, maxAdvisorStatements)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query pg_stat_statements: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s advisorStatement
+		if err := rows.Scan(&s.query, &s.calls); err != nil {
+			return nil, false, fmt.Errorf("failed to scan statement: %w", err)
+		}
+		statements = append(statements, s)
+	}
+	return statements, true, rows.Err()
+}
+
+// countRangeFilterCalls sums the calls of statements that reference the
+// table and compare the column with <, <=, >, >= or BETWEEN
+func countRangeFilterCalls(statements []advisorStatement, table, column string) int64 {
+	tableRe := regexp.MustCompile(`(?i)(^|[^\w$])"?` + regexp.QuoteMeta(table) + `"?($|[^\w$])`)
+	rangeRe := regexp.MustCompile(`(?i)(^|[^\w$])"?` + regexp.QuoteMeta(column) + `"?\s*(<=|>=|<|>|between\s)`)
+
+	var calls int64
+	for _, s := range statements {
+		if tableRe.MatchString(s.query) && rangeRe.MatchString(s.query) {
+			calls += s.calls
+		}
+	}
+	return calls
+}
+
+// choosePartitionKey picks the date/time column to partition by: the one
+// most often filtered by range, or, without range filters, one that closely
+// follows insert order. ok is false when no column qualifies.
+func choosePartitionKey(columns []partitionTimeColumn) (partitionTimeColumn, bool) {
+	var best partitionTimeColumn
+	found := false
+	for _, col := range columns {
+		if col.rangeCalls == 0 && math.Abs(col.correlation) < strongTimeCorrelation {
+			continue
+		}
+		if !found || col.rangeCalls > best.rangeCalls ||
+			(col.rangeCalls == best.rangeCalls && math.Abs(col.correlation) > math.Abs(best.correlation)) {
+			best = col
+			found = true
+		}
+	}
+	return best, found
+}
+
+// partitionKeyReason explains why a column was chosen as the key
+func partitionKeyReason(col partitionTimeColumn) string {
+	var reasons []string
+	if col.rangeCalls > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d statement calls filter it by range, so queries can skip whole partitions", col.rangeCalls))
+	}
+	if math.Abs(col.correlation) >= strongTimeCorrelation {
+		reasons = append(reasons, "it follows insert order, so old data can be dropped a partition at a time")
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// buildPartitionDDL returns example DDL for a monthly range-partitioned copy
+// of a table, with partitions for the current and next month and a default
+func buildPartitionDDL(schema, table, column string, now time.Time) string {
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	next := month.AddDate(0, 1, 0)
+
+	qualified := func(name string) string {
+		return quoteIdentifier(schema) + "." + quoteIdentifier(name)
+	}
+	newTable := table + "_partitioned"
+
+	var sb strings.Builder
+	sb.WriteString("```sql\n")
+	sb.WriteString(fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)\n    PARTITION BY RANGE (%s);\n",
+		qualified(newTable), qualified(table), quoteIdentifier(column)))
+	for _, start := range []time.Time{month, next} {
+		end := start.AddDate(0, 1, 0)
+		sb.WriteString(fmt.Sprintf("CREATE TABLE %s PARTITION OF %s\n    FOR VALUES FROM ('%s') TO ('%s');\n",
+			qualified(fmt.Sprintf("%s_%s", table, start.Format("2006_01"))), qualified(newTable),
+			start.Format("2006-01-02"), end.Format("2006-01-02")))
+	}
+	sb.WriteString(fmt.Sprintf("CREATE TABLE %s PARTITION OF %s DEFAULT;\n",
+		qualified(table+"_default"), qualified(newTable)))
+	sb.WriteString("```\n")
+	return sb.String()
+}
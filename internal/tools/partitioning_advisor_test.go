@@ -0,0 +1,120 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Partitioning Advisor Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPartitioningAdvisorToolDefinition(t *testing.T) {
+	tool := PartitioningAdvisorTool(nil)
+
+	if tool.Definition.Name != "partitioning_advisor" {
+		t.Errorf("Tool name = %v, want partitioning_advisor", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"schema_name", "min_size_mb", "limit"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+}
+
+func TestPartitioningAdvisorValidation(t *testing.T) {
+	tool := PartitioningAdvisorTool(nil)
+
+	for name, args := range map[string]map[string]interface{}{
+		"negative min_size_mb": {"min_size_mb": float64(-1)},
+		"zero limit":           {"limit": float64(0)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			response, err := tool.Handler(args)
+			if err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if !response.IsError {
+				t.Error("Expected error response")
+			}
+		})
+	}
+}
+
+func TestCountRangeFilterCalls(t *testing.T) {
+	statements := []advisorStatement{
+		{query: "SELECT * FROM events WHERE created_at >= $1 AND created_at < $2", calls: 100},
+		{query: `SELECT count(*) FROM public."events" WHERE "created_at" BETWEEN $1 AND $2`, calls: 20},
+		{query: "SELECT * FROM events WHERE id = $1", calls: 5000},
+		{query: "SELECT * FROM events_archive WHERE created_at > $1", calls: 7},
+		{query: "SELECT * FROM orders WHERE created_at > $1", calls: 3},
+		{query: "SELECT * FROM events WHERE updated_created_at > $1", calls: 9},
+	}
+
+	if got := countRangeFilterCalls(statements, "events", "created_at"); got != 120 {
+		t.Errorf("countRangeFilterCalls(events, created_at) = %d, want 120", got)
+	}
+	if got := countRangeFilterCalls(statements, "orders", "created_at"); got != 3 {
+		t.Errorf("countRangeFilterCalls(orders, created_at) = %d, want 3", got)
+	}
+	if got := countRangeFilterCalls(nil, "events", "created_at"); got != 0 {
+		t.Errorf("countRangeFilterCalls() without statements = %d, want 0", got)
+	}
+}
+
+func TestChoosePartitionKey(t *testing.T) {
+	t.Run("prefers range-filtered column", func(t *testing.T) {
+		key, ok := choosePartitionKey([]partitionTimeColumn{
+			{name: "created_at", correlation: 0.99},
+			{name: "event_time", correlation: 0.5, rangeCalls: 40},
+		})
+		if !ok || key.name != "event_time" {
+			t.Errorf("choosePartitionKey() = %q, %v; want event_time", key.name, ok)
+		}
+	})
+
+	t.Run("falls back to insert-ordered column", func(t *testing.T) {
+		key, ok := choosePartitionKey([]partitionTimeColumn{
+			{name: "birth_date", correlation: 0.1},
+			{name: "created_at", correlation: 0.97},
+		})
+		if !ok || key.name != "created_at" {
+			t.Errorf("choosePartitionKey() = %q, %v; want created_at", key.name, ok)
+		}
+	})
+
+	t.Run("no qualifying column", func(t *testing.T) {
+		if _, ok := choosePartitionKey([]partitionTimeColumn{{name: "birth_date", correlation: 0.1}}); ok {
+			t.Error("Expected no partition key")
+		}
+		if _, ok := choosePartitionKey(nil); ok {
+			t.Error("Expected no partition key without columns")
+		}
+	})
+}
+
+func TestBuildPartitionDDL(t *testing.T) {
+	ddl := buildPartitionDDL("public", "events", "created_at", time.Date(2025, 12, 15, 10, 0, 0, 0, time.UTC))
+
+	for _, want := range []string{
+		`CREATE TABLE "public"."events_partitioned" (LIKE "public"."events" INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`,
+		`PARTITION BY RANGE ("created_at");`,
+		`CREATE TABLE "public"."events_2025_12" PARTITION OF "public"."events_partitioned"`,
+		`FOR VALUES FROM ('2025-12-01') TO ('2026-01-01');`,
+		`CREATE TABLE "public"."events_2026_01" PARTITION OF "public"."events_partitioned"`,
+		`FOR VALUES FROM ('2026-01-01') TO ('2026-02-01');`,
+		`CREATE TABLE "public"."events_default" PARTITION OF "public"."events_partitioned" DEFAULT;`,
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("DDL missing %q:\n%s", want, ddl)
+		}
+	}
+}
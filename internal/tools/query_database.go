@@ -15,6 +15,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/jackc/pgx/v5"
+
 	"pgedge-postgres-mcp/internal/database"
 	"pgedge-postgres-mcp/internal/logging"
 	"pgedge-postgres-mcp/internal/mcp"
@@ -56,8 +58,17 @@ DO NOT use for:
 
 <important>
 - All queries run in READ-ONLY transactions (no data modifications possible)
-- Results are limited to prevent excessive token usage
-- Results are returned in TSV (tab-separated values) format for efficiency
+- Queries are cancelled after the server's query timeout (default 30s);
+  pass timeout_seconds for known-heavy analytical queries
+- Only a single SELECT, WITH or EXPLAIN statement is accepted per call
+- Results are limited to prevent excessive token usage; the server also caps
+  the total rows collected per call and notes when results were truncated
+- Results are returned in TSV (tab-separated values) format for efficiency;
+  pass format='csv' for RFC 4180 CSV when values contain tabs or newlines
+- Pass dry_run=true to validate the query and see the exact SQL that would
+  run, without running it (e.g. so a user can review it first)
+- Pass explain=true to include the estimated plan and any warnings (e.g.
+  sequential scans) ahead of the results
 </important>
 
 <rate_limit_awareness>
@@ -77,11 +88,33 @@ To avoid rate limits (30,000 input tokens/minute):
 					},
 					"limit": map[string]interface{}{
 						"type":        "integer",
-						"description": "Maximum number of rows to return (default: 100, max: 1000). Automatically appended to query if not already present. Use higher limits only when necessary to avoid excessive token usage.",
+						"description": "Maximum number of rows to return (default: 100, max: 1000). Applied to the query's result, on top of any LIMIT in the query. Use higher limits only when necessary to avoid excessive token usage.",
 						"default":     100,
 						"minimum":     1,
 						"maximum":     1000,
 					},
+					"max_rows": map[string]interface{}{
+						"type":        "integer",
+						"description": "Hard cap on rows collected for this call, applied after fetching (useful when the query has its own LIMIT). Can lower but not raise the server's max_result_rows setting (default: 1000).",
+						"minimum":     1,
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"tsv", "csv"},
+						"description": "Result format: 'tsv' (default, most compact) or 'csv' (quoted fields, header row, NULL as empty field)",
+						"default":     "tsv",
+					},
+					"timeout_seconds": timeoutSecondsProperty(dbClient),
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Validate the query and return the exact SQL that would run (with LIMIT/OFFSET applied) without executing it. Use when the user wants to review SQL before it runs.",
+						"default":     false,
+					},
+					"explain": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Run a plain EXPLAIN (planning only, not ANALYZE) first and include the estimated plan, cost and warnings in the response",
+						"default":     false,
+					},
 					"offset": map[string]interface{}{
 						"type":        "integer",
 						"description": "Number of rows to skip before returning results (for pagination). Use with limit to page through large result sets. Example: offset=100 with limit=100 returns rows 101-200.",
@@ -98,6 +131,12 @@ To avoid rate limits (30,000 input tokens/minute):
 				return mcp.NewToolError("Missing or invalid 'query' parameter")
 			}
 
+			format := strings.ToLower(ValidateOptionalStringParam(args, "format", "tsv"))
+			if format != "tsv" && format != "csv" {
+				return mcp.NewToolError(fmt.Sprintf("Unsupported format %q: use 'tsv' or 'csv'", format))
+			}
+			explain := ValidateBoolParam(args, "explain", false)
+
 			// Parse query for connection string and intent
 			queryCtx := database.ParseQueryForConnection(query)
 
@@ -150,6 +189,16 @@ To avoid rate limits (30,000 input tokens/minute):
 			// Use the cleaned query as SQL
 			sqlQuery := strings.TrimSpace(queryCtx.CleanedQuery)
 
+			// Reject anything that isn't a single read-only statement before
+			// it reaches the database (the READ ONLY transaction below is the
+			// second line of defense)
+			if dbClient.IsReadOnly() {
+				if err := database.ValidateReadOnlySQL(sqlQuery); err != nil {
+					logging.Warn("query_database_rejected", "reason", err.Error(), "query", logging.SQL(sqlQuery))
+					return mcp.NewToolError(fmt.Sprintf("Query rejected: %v\n\nSQL Query:\n%s", err, sqlQuery))
+				}
+			}
+
 			// Determine the limit to use
 			limit := 100 // default
 			if limitVal, ok := args["limit"]; ok {
@@ -172,22 +221,49 @@ To avoid rate limits (30,000 input tokens/minute):
 				}
 			}
 
-			// Track if query already had LIMIT/OFFSET clauses
-			upperQuery := strings.ToUpper(sqlQuery)
-			hasExistingLimit := strings.Contains(upperQuery, "LIMIT")
-			hasExistingOffset := strings.Contains(upperQuery, "OFFSET")
+			// Server-side row cap; max_rows may lower it for this call
+			maxRows := dbClient.MaxResultRows()
+			if val, ok := args["max_rows"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'max_rows' must be a positive integer")
+				}
+				if int(val) < maxRows {
+					maxRows = int(val)
+				}
+			}
 
-			// Only inject LIMIT/OFFSET if query doesn't already have them
-			// Fetch limit+1 to detect if more rows exist
-			if limit > 0 && !hasExistingLimit {
-				sqlQuery = fmt.Sprintf("%s LIMIT %d", sqlQuery, limit+1)
+			timeout, errResp := resolveQueryTimeout(dbClient, args)
+			if errResp != nil {
+				return *errResp, nil
 			}
-			if offset > 0 && !hasExistingOffset {
-				sqlQuery = fmt.Sprintf("%s OFFSET %d", sqlQuery, offset)
+
+			upperQuery := strings.ToUpper(sqlQuery)
+
+			// Apply limit (fetching limit+1 to detect if more rows exist)
+			// and offset to the query's result
+			sqlQuery, limited := limitQuery(sqlQuery, limit, offset)
+
+			// Dry run: the query has passed validation; show it without
+			// touching the database
+			if ValidateBoolParam(args, "dry_run", false) {
+				logging.Info("query_database_dry_run", "query", logging.SQL(sqlQuery))
+
+				var sb strings.Builder
+				if connectionMessage == "" {
+					sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+				} else {
+					sb.WriteString(connectionMessage)
+				}
+				sb.WriteString("DRY RUN: the query was NOT executed.\n\n")
+				sb.WriteString(fmt.Sprintf("SQL Query:\n%s\n\n", sqlQuery))
+				sb.WriteString(fmt.Sprintf("It would run in a read-only transaction with a %s timeout, returning at most %d rows. ", timeout, maxRows))
+				sb.WriteString("Call query_database again without dry_run to execute it.")
+				return mcp.NewToolSuccess(sb.String())
 			}
 
 			// Execute the SQL query on the appropriate connection in a read-only transaction
-			ctx := context.Background()
+			ctx, cancel := withQueryTimeout(requestContext(args), timeout)
+			defer cancel()
 			pool := dbClient.GetPoolFor(connStr)
 			if pool == nil {
 				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
@@ -221,8 +297,71 @@ To avoid rate limits (30,000 input tokens/minute):
 				return mcp.NewToolError(fmt.Sprintf("Failed to set transaction read-only: %v", err))
 			}
 
+			if err := checkReplica(ctx, dbClient, tx); err != nil {
+				return mcp.NewToolError(err.Error())
+			}
+
+			if err := setStatementTimeout(ctx, tx, timeout); err != nil {
+				return mcp.NewToolError(err.Error())
+			}
+
+			// Plan the query before running it; EXPLAIN of an EXPLAIN is
+			// not valid SQL, so those are left alone
+			var planText string
+			if explain && !strings.HasPrefix(upperQuery, "EXPLAIN") {
+				planText, err = explainQueryPlan(ctx, tx, sqlQuery)
+				if err != nil {
+					if isQueryTimeout(ctx, err) {
+						return mcp.NewToolError(fmt.Sprintf("%sSQL Query:\n%s\n\n%s", connectionMessage, sqlQuery, queryTimeoutMessage(timeout)))
+					}
+					return mcp.NewToolError(fmt.Sprintf("%sSQL Query:\n%s\n\nError executing EXPLAIN: %v", connectionMessage, sqlQuery, err))
+				}
+			}
+
+			// Refuse, or warn about, queries the planner expects to be
+			// too expensive before they start
+			var costWarning string
+			if maxCost := dbClient.MaxQueryCost(); maxCost > 0 && !strings.HasPrefix(upperQuery, "EXPLAIN") {
+				cost, err := estimatePlanCost(ctx, tx, sqlQuery)
+				if err != nil {
+					if isQueryTimeout(ctx, err) {
+						return mcp.NewToolError(fmt.Sprintf("%sSQL Query:\n%s\n\n%s", connectionMessage, sqlQuery, queryTimeoutMessage(timeout)))
+					}
+					return mcp.NewToolError(fmt.Sprintf("%sSQL Query:\n%s\n\nError estimating query cost: %v", connectionMessage, sqlQuery, err))
+				}
+				if cost > maxCost {
+					block := dbClient.BlocksCostlyQueries()
+					logging.Warn("query_database_cost_exceeded",
+						"estimated_cost", cost,
+						"max_query_cost", maxCost,
+						"blocked", block,
+						"query", logging.SQL(sqlQuery),
+					)
+					if block {
+						if planText == "" {
+							planText, err = explainQueryPlan(ctx, tx, sqlQuery)
+							if err != nil {
+								return mcp.NewToolError(fmt.Sprintf("%sSQL Query:\n%s\n\nError executing EXPLAIN: %v", connectionMessage, sqlQuery, err))
+							}
+						}
+						return mcp.NewToolError(connectionMessage + queryCostBlockedMessage(sqlQuery, cost, maxCost, planText))
+					}
+					costWarning = fmt.Sprintf("Warning: the estimated cost (%.0f) is above this database's max_query_cost of %.0f; the query ran anyway.\n\n", cost, maxCost)
+				}
+			}
+
+			// Columns matching redact_columns are masked in every row
+			redacted, err := dbClient.Redactor().ResultColumns(ctx, tx, sqlQuery)
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("%sSQL Query:\n%s\n\nError executing query: %v", connectionMessage, sqlQuery, err))
+			}
+
 			rows, err := tx.Query(ctx, sqlQuery)
 			if err != nil {
+				if isQueryTimeout(ctx, err) {
+					logging.Warn("query_database_timeout", "timeout", timeout.String(), "query", logging.SQL(sqlQuery))
+					return mcp.NewToolError(fmt.Sprintf("%sSQL Query:\n%s\n\n%s", connectionMessage, sqlQuery, queryTimeoutMessage(timeout)))
+				}
 				return mcp.NewToolError(fmt.Sprintf("%sSQL Query:\n%s\n\nError executing query: %v", connectionMessage, sqlQuery, err))
 			}
 			defer rows.Close()
@@ -234,29 +373,138 @@ To avoid rate limits (30,000 input tokens/minute):
 				columnNames = append(columnNames, string(fd.Name))
 			}
 
-			// Collect results as array of arrays for TSV formatting
+			// writeHeader writes what precedes the results: the database,
+			// the SQL and the plan
+			writeHeader := func(sb *strings.Builder) {
+				// Always show current database context (unless already shown via connection message)
+				if connectionMessage == "" {
+					sanitizedConn := database.SanitizeConnStr(connStr)
+					sb.WriteString(fmt.Sprintf("Database: %s\n\n", sanitizedConn))
+				} else {
+					sb.WriteString(connectionMessage)
+				}
+
+				sb.WriteString(fmt.Sprintf("SQL Query:\n%s\n\n", sqlQuery))
+				sb.WriteString(costWarning)
+
+				if planText != "" {
+					sb.WriteString(fmt.Sprintf("Estimated Plan:\n%s\n\n", planText))
+					if analysis := analyzeExplainOutput(planText); analysis != "" {
+						sb.WriteString("Analysis:\n")
+						sb.WriteString(analysis)
+						sb.WriteString("\n")
+					}
+				}
+			}
+
+			// When the transport streams results, send rows as they are
+			// read rather than collecting them all first
+			if stream := resultStream(args); stream != nil {
+				var sb strings.Builder
+				writeHeader(&sb)
+				sb.WriteString("Results:\n")
+				if err := stream.WriteText(sb.String()); err != nil {
+					return mcp.NewToolError(fmt.Sprintf("Failed to send results: %v", err))
+				}
+
+				streamer := newRowStreamer(stream, format, columnNames)
+				rowCount := 0
+				wasTruncated := false
+				hitRowCap := false
+				for rows.Next() {
+					if rowCount >= maxRows {
+						hitRowCap = true
+						break
+					}
+					// LIMIT was set to limit+1 to detect that more rows exist
+					if limited && limit > 0 && rowCount >= limit {
+						wasTruncated = true
+						break
+					}
+					values, err := rows.Values()
+					if err != nil {
+						return mcp.NewToolError(fmt.Sprintf("Error reading row: %v", err))
+					}
+					database.RedactRow(values, redacted)
+					if err := streamer.add(values); err != nil {
+						return mcp.NewToolError(fmt.Sprintf("Failed to send results: %v", err))
+					}
+					rowCount++
+				}
+				rows.Close()
+
+				if err := rows.Err(); err != nil {
+					if isQueryTimeout(ctx, err) {
+						logging.Warn("query_database_timeout", "timeout", timeout.String(), "query", logging.SQL(sqlQuery))
+						return mcp.NewToolError(queryTimeoutMessage(timeout))
+					}
+					return mcp.NewToolError(fmt.Sprintf("Error iterating rows: %v", err))
+				}
+				if err := streamer.flush(); err != nil {
+					return mcp.NewToolError(fmt.Sprintf("Failed to send results: %v", err))
+				}
+
+				if err := tx.Commit(ctx); err != nil {
+					return mcp.NewToolError(fmt.Sprintf("Failed to commit transaction: %v", err))
+				}
+				committed = true
+
+				logging.Info("query_database_executed",
+					"query", logging.SQL(sqlQuery),
+					"query_length", len(sqlQuery),
+					"rows_returned", rowCount,
+					"offset", offset,
+					"format", format,
+					"was_truncated", wasTruncated,
+					"hit_row_cap", hitRowCap,
+					"max_rows", maxRows,
+					"explain", planText != "",
+					"streamed", true,
+				)
+
+				return mcp.NewToolSuccess(streamedResultsSummary(rowCount, offset, limit, maxRows, wasTruncated, hitRowCap))
+			}
+
+			// Collect results as array of arrays for TSV formatting, stopping
+			// once the row cap is exceeded so the SQL never needs rewriting
 			var results [][]interface{}
+			hitRowCap := false
 			for rows.Next() {
+				if len(results) >= maxRows {
+					hitRowCap = true
+					break
+				}
 				values, err := rows.Values()
 				if err != nil {
 					return mcp.NewToolError(fmt.Sprintf("Error reading row: %v", err))
 				}
+				database.RedactRow(values, redacted)
 				results = append(results, values)
 			}
+			rows.Close()
 
 			if err := rows.Err(); err != nil {
+				if isQueryTimeout(ctx, err) {
+					logging.Warn("query_database_timeout", "timeout", timeout.String(), "query", logging.SQL(sqlQuery))
+					return mcp.NewToolError(fmt.Sprintf("%sSQL Query:\n%s\n\n%s", connectionMessage, sqlQuery, queryTimeoutMessage(timeout)))
+				}
 				return mcp.NewToolError(fmt.Sprintf("Error iterating rows: %v", err))
 			}
 
 			// Check if results were truncated (we fetched limit+1 to detect this)
 			wasTruncated := false
-			if !hasExistingLimit && limit > 0 && len(results) > limit {
+			if limited && limit > 0 && len(results) > limit {
 				wasTruncated = true
 				results = results[:limit] // Truncate to requested limit
 			}
 
-			// Format results as TSV (tab-separated values)
-			resultsTSV := FormatResultsAsTSV(columnNames, results)
+			// Format results as TSV (tab-separated values) or CSV
+			var resultsText string
+			if format == "csv" {
+				resultsText = FormatResultsAsCSV(columnNames, results)
+			} else {
+				resultsText = FormatResultsAsTSV(columnNames, results)
+			}
 
 			// Commit the read-only transaction
 			if err := tx.Commit(ctx); err != nil {
@@ -265,16 +513,7 @@ To avoid rate limits (30,000 input tokens/minute):
 			committed = true
 
 			var sb strings.Builder
-
-			// Always show current database context (unless already shown via connection message)
-			if connectionMessage == "" {
-				sanitizedConn := database.SanitizeConnStr(connStr)
-				sb.WriteString(fmt.Sprintf("Database: %s\n\n", sanitizedConn))
-			} else {
-				sb.WriteString(connectionMessage)
-			}
-
-			sb.WriteString(fmt.Sprintf("SQL Query:\n%s\n\n", sqlQuery))
+			writeHeader(&sb)
 
 			// Build the results header with pagination info
 			if offset > 0 {
@@ -283,27 +522,123 @@ To avoid rate limits (30,000 input tokens/minute):
 				endRow := offset + len(results)
 				if wasTruncated {
 					sb.WriteString(fmt.Sprintf("Results (rows %d-%d, more available - use offset=%d for next page):\n%s",
-						startRow, endRow, offset+limit, resultsTSV))
+						startRow, endRow, offset+limit, resultsText))
 				} else {
-					sb.WriteString(fmt.Sprintf("Results (rows %d-%d):\n%s", startRow, endRow, resultsTSV))
+					sb.WriteString(fmt.Sprintf("Results (rows %d-%d):\n%s", startRow, endRow, resultsText))
 				}
 			} else if wasTruncated {
 				sb.WriteString(fmt.Sprintf("Results (%d rows shown, more available - use offset=%d for next page or count_rows for total):\n%s",
-					len(results), limit, resultsTSV))
+					len(results), limit, resultsText))
 			} else {
-				sb.WriteString(fmt.Sprintf("Results (%d rows):\n%s", len(results), resultsTSV))
+				sb.WriteString(fmt.Sprintf("Results (%d rows):\n%s", len(results), resultsText))
+			}
+
+			if hitRowCap {
+				sb.WriteString(fmt.Sprintf("\n\nResults truncated at %d rows (query returned more). Add a WHERE clause or LIMIT, or page with limit/offset.", maxRows))
 			}
 
 			// Log execution metrics
 			logging.Info("query_database_executed",
+				"query", logging.SQL(sqlQuery),
 				"query_length", len(sqlQuery),
 				"rows_returned", len(results),
 				"offset", offset,
+				"format", format,
 				"was_truncated", wasTruncated,
-				"estimated_tokens", len(resultsTSV)/4,
+				"hit_row_cap", hitRowCap,
+				"max_rows", maxRows,
+				"explain", planText != "",
+				"estimated_tokens", len(resultsText)/4,
 			)
 
 			return mcp.NewToolSuccess(sb.String())
 		},
 	}
 }
+
+// limitQuery applies limit and offset to a query by running it as a
+// subquery, so nothing in its text (a trailing comment, or LIMIT in a
+// column name) can defeat them. limit+1 rows are fetched so the caller can
+// tell that more exist. Statements that can't be a subquery, such as
+// EXPLAIN or, with read_only off, writes, are returned unchanged with
+// limited false; the row cap still bounds what is collected from them.
+func limitQuery(sql string, limit, offset int) (string, bool) {
+	stmt, ok := database.TrimStatement(sql)
+	if !ok || database.ValidateReadOnlySQL(stmt) != nil || database.StatementType(stmt) == "EXPLAIN" {
+		return sql, false
+	}
+	if limit <= 0 && offset <= 0 {
+		return stmt, false
+	}
+
+	// The newline ends a trailing line comment inside the subquery
+	wrapped := fmt.Sprintf("SELECT * FROM (\n%s\n) AS _q", stmt)
+	if limit > 0 {
+		wrapped += fmt.Sprintf(" LIMIT %d", limit+1)
+	}
+	if offset > 0 {
+		wrapped += fmt.Sprintf(" OFFSET %d", offset)
+	}
+	return wrapped, true
+}
+
+// streamedResultsSummary returns the text that follows streamed results:
+// the row count and the same paging and truncation hints as a buffered
+// result
+func streamedResultsSummary(rowCount, offset, limit, maxRows int, wasTruncated, hitRowCap bool) string {
+	var summary string
+	switch {
+	case offset > 0 && wasTruncated:
+		summary = fmt.Sprintf("Rows %d-%d, more available - use offset=%d for next page", offset+1, offset+rowCount, offset+limit)
+	case offset > 0:
+		summary = fmt.Sprintf("Rows %d-%d", offset+1, offset+rowCount)
+	case wasTruncated:
+		summary = fmt.Sprintf("%d rows shown, more available - use offset=%d for next page or count_rows for total", rowCount, limit)
+	default:
+		summary = fmt.Sprintf("%d rows", rowCount)
+	}
+	summary = "\n\n(" + summary + ")"
+
+	if hitRowCap {
+		summary += fmt.Sprintf("\n\nResults truncated at %d rows (query returned more). Add a WHERE clause or LIMIT, or page with limit/offset.", maxRows)
+	}
+	return summary
+}
+
+// queryCostBlockedMessage explains why a query above max_query_cost was not
+// run, with its estimated plan so the caller can see what to narrow
+func queryCostBlockedMessage(sqlQuery string, cost, maxCost float64, planText string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("SQL Query:\n%s\n\n", sqlQuery))
+	sb.WriteString(fmt.Sprintf("Query NOT executed: the estimated cost (%.0f) is above this database's max_query_cost of %.0f. "+
+		"Narrow it with more selective WHERE clauses or joins, or aggregate in SQL instead of returning rows.\n\n", cost, maxCost))
+	sb.WriteString(fmt.Sprintf("Estimated Plan:\n%s\n", planText))
+	if analysis := analyzeExplainOutput(planText); analysis != "" {
+		sb.WriteString("\nAnalysis:\n")
+		sb.WriteString(analysis)
+	}
+	return sb.String()
+}
+
+// explainQueryPlan returns the text of a plain EXPLAIN of query, which plans
+// it without executing it
+func explainQueryPlan(ctx context.Context, tx pgx.Tx, query string) (string, error) {
+	rows, err := tx.Query(ctx, "EXPLAIN "+query)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -11,8 +11,11 @@
 package tools
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"pgedge-postgres-mcp/internal/database"
 )
 
 func TestFormatTSVValue(t *testing.T) {
@@ -77,9 +80,9 @@ func TestFormatTSVValue(t *testing.T) {
 			expected: "false",
 		},
 		{
-			name:     "byte slice",
+			name:     "byte slice is bytea hex",
 			input:    []byte("bytes"),
-			expected: "bytes",
+			expected: `\x6279746573`,
 		},
 		{
 			name:     "array",
@@ -176,3 +179,147 @@ func TestFormatResultsAsTSV(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryDatabaseTool_ReadOnlyGuard(t *testing.T) {
+	client := database.NewTestClient("postgres://localhost/test", map[string]database.TableInfo{})
+	tool := QueryDatabaseTool(client)
+
+	tests := []struct {
+		name     string
+		query    string
+		contains string
+	}{
+		{"insert", "INSERT INTO users (name) VALUES ('x')", "INSERT statements are not allowed"},
+		{"drop", "DROP TABLE users", "DROP statements are not allowed"},
+		{"stacked", "SELECT 1; DELETE FROM users", "multiple SQL statements"},
+		{"data-modifying CTE", "WITH d AS (DELETE FROM users RETURNING *) SELECT * FROM d", "data-modifying DELETE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := tool.Handler(map[string]interface{}{"query": tt.query})
+			if err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if !response.IsError {
+				t.Fatal("Expected error response")
+			}
+			if !strings.Contains(response.Content[0].Text, tt.contains) {
+				t.Errorf("Expected error to contain %q, got %q", tt.contains, response.Content[0].Text)
+			}
+		})
+	}
+}
+
+func TestQueryDatabaseTool_InvalidMaxRows(t *testing.T) {
+	client := database.NewTestClient("postgres://localhost/test", map[string]database.TableInfo{})
+	tool := QueryDatabaseTool(client)
+
+	for _, prop := range []string{"max_rows", "dry_run", "explain"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+
+	response, err := tool.Handler(map[string]interface{}{"query": "SELECT 1", "max_rows": float64(0)})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !response.IsError || !strings.Contains(response.Content[0].Text, "max_rows") {
+		t.Errorf("Expected max_rows validation error, got %+v", response)
+	}
+}
+
+func TestQueryDatabaseTool_InvalidFormat(t *testing.T) {
+	tool := QueryDatabaseTool(nil)
+
+	response, err := tool.Handler(map[string]interface{}{"query": "SELECT 1", "format": "xml"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !response.IsError || !strings.Contains(response.Content[0].Text, "Unsupported format") {
+		t.Errorf("Expected format validation error, got %+v", response)
+	}
+}
+
+func TestQueryDatabaseTool_DryRun(t *testing.T) {
+	// The test client has no pool, so reaching execution would fail
+	client := database.NewTestClient("postgres://localhost/test", map[string]database.TableInfo{})
+	tool := QueryDatabaseTool(client)
+
+	response, err := tool.Handler(map[string]interface{}{"query": "SELECT * FROM users", "dry_run": true})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.IsError {
+		t.Fatalf("Expected success, got %q", response.Content[0].Text)
+	}
+	text := response.Content[0].Text
+	if !strings.Contains(text, "NOT executed") || !strings.Contains(text, "SELECT * FROM (\nSELECT * FROM users\n) AS _q LIMIT 101") {
+		t.Errorf("Unexpected dry run output: %q", text)
+	}
+
+	// Validation still applies
+	response, err = tool.Handler(map[string]interface{}{"query": "DELETE FROM users", "dry_run": true})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !response.IsError {
+		t.Error("Expected dry run of DELETE to be rejected")
+	}
+}
+
+func TestLimitQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		limit   int
+		offset  int
+		want    string
+		limited bool
+	}{
+		{"plain select", "SELECT * FROM t", 10, 0,
+			"SELECT * FROM (\nSELECT * FROM t\n) AS _q LIMIT 11", true},
+		{"with offset", "SELECT * FROM t;", 10, 20,
+			"SELECT * FROM (\nSELECT * FROM t\n) AS _q LIMIT 11 OFFSET 20", true},
+		{"trailing comment", "SELECT * FROM t -- all rows", 5, 0,
+			"SELECT * FROM (\nSELECT * FROM t -- all rows\n) AS _q LIMIT 6", true},
+		{"comment after semicolon", "SELECT * FROM t; -- done", 5, 0,
+			"SELECT * FROM (\nSELECT * FROM t\n) AS _q LIMIT 6", true},
+		{"limit in a column name", "SELECT credit_limit FROM accounts", 5, 0,
+			"SELECT * FROM (\nSELECT credit_limit FROM accounts\n) AS _q LIMIT 6", true},
+		{"own limit", "SELECT * FROM t ORDER BY id LIMIT 500", 100, 0,
+			"SELECT * FROM (\nSELECT * FROM t ORDER BY id LIMIT 500\n) AS _q LIMIT 101", true},
+		{"cte", "WITH x AS (SELECT 1) SELECT * FROM x", 5, 0,
+			"SELECT * FROM (\nWITH x AS (SELECT 1) SELECT * FROM x\n) AS _q LIMIT 6", true},
+		{"explain is left alone", "EXPLAIN SELECT * FROM t", 5, 0, "EXPLAIN SELECT * FROM t", false},
+		{"write is left alone", "DELETE FROM t RETURNING *", 5, 0, "DELETE FROM t RETURNING *", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, limited := limitQuery(tt.sql, tt.limit, tt.offset)
+			if got != tt.want || limited != tt.limited {
+				t.Errorf("limitQuery(%q) = %q, %v; want %q, %v", tt.sql, got, limited, tt.want, tt.limited)
+			}
+		})
+	}
+}
+
+func TestQueryCostBlockedMessage(t *testing.T) {
+	plan := "Seq Scan on orders  (cost=0.00..2500000.00 rows=100000000 width=64)"
+	text := queryCostBlockedMessage("SELECT * FROM orders", 2500000, 100000, plan)
+
+	for _, want := range []string{
+		"SELECT * FROM orders",
+		"NOT executed",
+		"estimated cost (2500000)",
+		"max_query_cost of 100000",
+		"Estimated Plan:\n" + plan,
+		"Sequential scan",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in message, got:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// queryTimeoutGrace is added to the client-side deadline so PostgreSQL's
+// statement_timeout normally fires first; that cancels the statement on the
+// server and keeps the pooled connection usable
+const queryTimeoutGrace = 2 * time.Second
+
+// timeoutSecondsProperty returns the input schema for the per-call timeout
+// override, with the database's max_query_timeout as its maximum. The base
+// registry is built with a nil client, which advertises the default maximum.
+func timeoutSecondsProperty(dbClient *database.Client) map[string]interface{} {
+	maxSeconds := config.DefaultMaxQueryTimeout.Seconds()
+	if dbClient != nil {
+		maxSeconds = dbClient.MaxQueryTimeout().Seconds()
+	}
+	return map[string]interface{}{
+		"type": "number",
+		"description": fmt.Sprintf("Override the server's query timeout for this call, in seconds (default: the database's query_timeout, normally 30; at most %s). Use for known-heavy analytical queries.",
+			strconv.FormatFloat(maxSeconds, 'f', -1, 64)),
+		"minimum": 1,
+		"maximum": maxSeconds,
+	}
+}
+
+// resolveQueryTimeout returns the timeout for a tool call: the per-call
+// timeout_seconds argument if given, otherwise the database's query_timeout.
+// An override above the database's max_query_timeout is rejected.
+func resolveQueryTimeout(dbClient *database.Client, args map[string]interface{}) (time.Duration, *mcp.ToolResponse) {
+	if val, ok := args["timeout_seconds"].(float64); ok {
+		if val <= 0 {
+			resp, _ := mcp.NewToolError("Parameter 'timeout_seconds' must be a positive number")
+			return 0, &resp
+		}
+		maxTimeout := dbClient.MaxQueryTimeout()
+		if val > maxTimeout.Seconds() {
+			resp, _ := mcp.NewToolError(fmt.Sprintf("Parameter 'timeout_seconds' must be at most %s (the database's max_query_timeout of %s)",
+				strconv.FormatFloat(maxTimeout.Seconds(), 'f', -1, 64), maxTimeout))
+			return 0, &resp
+		}
+		return time.Duration(val * float64(time.Second)), nil
+	}
+	return dbClient.QueryTimeout(), nil
+}
+
+// withQueryTimeout returns a context whose deadline allows the server-side
+// statement_timeout to fire first. It is derived from the request's
+// context, so a cancelled tool call also cancels its query.
+func withQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, timeout+queryTimeoutGrace)
+}
+
+// setStatementTimeout applies the timeout to the current transaction so the
+// statement is cancelled on the server, not just abandoned by the client
+func setStatementTimeout(ctx context.Context, tx pgx.Tx, timeout time.Duration) error {
+	ms := timeout.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", ms)); err != nil {
+		return fmt.Errorf("failed to set statement timeout: %w", err)
+	}
+	return nil
+}
+
+// isQueryTimeout reports whether err was caused by the query timeout, either
+// PostgreSQL's statement_timeout or the client-side context deadline
+func isQueryTimeout(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "57014" {
+		// query_canceled is also raised by pg_cancel_backend(); only the
+		// statement_timeout variant is ours
+		return strings.Contains(pgErr.Message, "statement timeout")
+	}
+	return false
+}
+
+// queryTimeoutMessage describes a timed-out query for the tool response
+func queryTimeoutMessage(timeout time.Duration) string {
+	return fmt.Sprintf("Query timed out after %s and was cancelled on the server. "+
+		"Narrow the query (add WHERE conditions or a LIMIT), check it with execute_explain, "+
+		"or pass a larger timeout_seconds if the query is known to be heavy.", timeout)
+}
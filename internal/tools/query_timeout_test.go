@@ -0,0 +1,91 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Query Timeout Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/database"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestResolveQueryTimeout(t *testing.T) {
+	client := database.NewTestClient("postgres://localhost/test", map[string]database.TableInfo{})
+
+	timeout, errResp := resolveQueryTimeout(client, map[string]interface{}{})
+	if errResp != nil || timeout != config.DefaultQueryTimeout {
+		t.Errorf("Expected default timeout %v, got %v (%v)", config.DefaultQueryTimeout, timeout, errResp)
+	}
+
+	timeout, errResp = resolveQueryTimeout(client, map[string]interface{}{"timeout_seconds": float64(120)})
+	if errResp != nil || timeout != 2*time.Minute {
+		t.Errorf("Expected override of 2m, got %v (%v)", timeout, errResp)
+	}
+
+	_, errResp = resolveQueryTimeout(client, map[string]interface{}{"timeout_seconds": float64(0)})
+	if errResp == nil || !errResp.IsError {
+		t.Error("Expected error response for timeout_seconds=0")
+	}
+
+	// Overrides above max_query_timeout are rejected
+	_, errResp = resolveQueryTimeout(client, map[string]interface{}{"timeout_seconds": float64(86400)})
+	if errResp == nil || !errResp.IsError {
+		t.Error("Expected error response for timeout_seconds above the default max_query_timeout")
+	}
+
+	limited := database.NewClient(&config.NamedDatabaseConfig{Name: "db", MaxQueryTimeout: "1m"})
+	if _, errResp = resolveQueryTimeout(limited, map[string]interface{}{"timeout_seconds": float64(120)}); errResp == nil {
+		t.Error("Expected error response for timeout_seconds above max_query_timeout")
+	}
+	if timeout, errResp := resolveQueryTimeout(limited, map[string]interface{}{"timeout_seconds": float64(60)}); errResp != nil || timeout != time.Minute {
+		t.Errorf("Expected override of 1m, got %v (%v)", timeout, errResp)
+	}
+	if got := timeoutSecondsProperty(limited)["maximum"]; got != float64(60) {
+		t.Errorf("Expected schema maximum 60, got %v", got)
+	}
+}
+
+func TestIsQueryTimeout(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		expected bool
+	}{
+		{"nil error", context.Background(), nil, false},
+		{"statement timeout", context.Background(),
+			&pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}, true},
+		{"wrapped statement timeout", context.Background(),
+			fmt.Errorf("query failed: %w", &pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}), true},
+		{"user cancel", context.Background(),
+			&pgconn.PgError{Code: "57014", Message: "canceling statement due to user request"}, false},
+		{"deadline exceeded", context.Background(), context.DeadlineExceeded, true},
+		{"expired context", expired, errors.New("conn closed"), true},
+		{"other error", context.Background(), &pgconn.PgError{Code: "42P01", Message: "relation does not exist"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isQueryTimeout(tt.ctx, tt.err); got != tt.expected {
+				t.Errorf("isQueryTimeout() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -46,6 +46,12 @@ Use read_resource when:
    - PostgreSQL version, OS, architecture
    - Connection details (host, port, user, database)
    - Platform information for compatibility checks
+2. pg://stat_statements
+   - Slowest statements from pg_stat_statements
+   - Optional ?limit=N (1-100) and order_by=total_time|mean_time
+3. pg://blocking_locks
+   - Sessions waiting on locks, as blocking trees
+   - Lock mode, relation and wait time of each waiter
 </available_resources>
 
 <alternatives>
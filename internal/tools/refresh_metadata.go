@@ -0,0 +1,103 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// RefreshMetadataTool creates the refresh_metadata tool
+func RefreshMetadataTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "refresh_metadata",
+			Description: `Reload table, view and column metadata from the database.
+
+<usecase>
+Use when:
+- A table was created, dropped or altered after the server started
+- query_database, get_schema_info or similarity_search can't find a table
+  that exists
+</usecase>
+
+<what_it_returns>
+The table count before and after the refresh and the tables that were
+added or removed.
+</what_it_returns>
+
+<important>
+- Only the current connection's metadata is refreshed; other users'
+  sessions keep their own
+- Column changes to existing tables are picked up but not listed
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			connStr := dbClient.GetDefaultConnection()
+			if connStr == "" {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+			if dbClient.GetPoolFor(connStr) == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			before := dbClient.GetMetadataFor(connStr)
+			startTime := time.Now()
+			if err := dbClient.LoadMetadataFor(connStr); err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Failed to refresh metadata: %v", err))
+			}
+			duration := time.Since(startTime)
+			after := dbClient.GetMetadataFor(connStr)
+			added, removed := database.DiffTables(before, after)
+
+			logging.Info("refresh_metadata_executed",
+				"tables_before", len(before),
+				"tables_after", len(after),
+				"added", len(added),
+				"removed", len(removed),
+				"duration_ms", duration.Milliseconds(),
+			)
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(fmt.Sprintf("Metadata refreshed in %d ms.\n", duration.Milliseconds()))
+			sb.WriteString(fmt.Sprintf("Tables and views before: %d\n", len(before)))
+			sb.WriteString(fmt.Sprintf("Tables and views after: %d\n", len(after)))
+			if len(added) == 0 && len(removed) == 0 {
+				sb.WriteString("\nNo tables or views were added or removed.\n")
+			}
+			writeTableList(&sb, "Added", added)
+			writeTableList(&sb, "Removed", removed)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// writeTableList writes a titled list of table names, or nothing if empty
+func writeTableList(sb *strings.Builder, title string, tables []string) {
+	if len(tables) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n%s (%d):\n", title, len(tables)))
+	for _, table := range tables {
+		sb.WriteString("  " + table + "\n")
+	}
+}
@@ -0,0 +1,65 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Refresh Metadata Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/database"
+)
+
+func TestRefreshMetadataToolDefinition(t *testing.T) {
+	tool := RefreshMetadataTool(nil)
+
+	if tool.Definition.Name != "refresh_metadata" {
+		t.Errorf("Tool name = %v, want refresh_metadata", tool.Definition.Name)
+	}
+	if len(tool.Definition.InputSchema.Properties) != 0 {
+		t.Errorf("Expected no parameters, got %v", tool.Definition.InputSchema.Properties)
+	}
+}
+
+func TestRefreshMetadataNotConnected(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *database.Client
+		wantErr string
+	}{
+		{"no connection", database.NewClient(nil), "still initializing"},
+		{"no pool", database.NewTestClient("postgres://localhost/test", nil), "Connection pool not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := RefreshMetadataTool(tt.client).Handler(map[string]interface{}{})
+			if err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if !response.IsError || !strings.Contains(response.Content[0].Text, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %+v", tt.wantErr, response)
+			}
+		})
+	}
+}
+
+func TestWriteTableList(t *testing.T) {
+	var sb strings.Builder
+	writeTableList(&sb, "Added", nil)
+	if sb.Len() != 0 {
+		t.Errorf("Expected no output for an empty list, got %q", sb.String())
+	}
+
+	writeTableList(&sb, "Added", []string{"public.a", "sales.b"})
+	if got := sb.String(); got != "\nAdded (2):\n  public.a\n  sales.b\n" {
+		t.Errorf("writeTableList() = %q", got)
+	}
+}
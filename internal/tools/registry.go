@@ -57,7 +57,9 @@ func (r *Registry) List() []mcp.Tool {
 	return tools
 }
 
-// Execute runs a tool by name with the given arguments
+// Execute runs a tool by name with the given arguments. Arguments that
+// don't match the tool's InputSchema return an *mcp.InvalidParamsError
+// without calling the tool.
 func (r *Registry) Execute(ctx context.Context, name string, args map[string]interface{}) (mcp.ToolResponse, error) {
 	tool, exists := r.Get(name)
 	if !exists {
@@ -72,6 +74,12 @@ func (r *Registry) Execute(ctx context.Context, name string, args map[string]int
 		}, nil
 	}
 
+	// Check the arguments against the tool's schema, so handlers can rely
+	// on required arguments being present with the declared types
+	if problems := mcp.ValidateArguments(tool.Definition.InputSchema, args); len(problems) > 0 {
+		return mcp.ToolResponse{}, &mcp.InvalidParamsError{Tool: name, Problems: problems}
+	}
+
 	// Inject context into args with a special key for tools that need it
 	// This allows handlers to access the context without changing the Handler signature
 	// Create a copy of args to avoid mutating the caller's map (race condition)
@@ -85,3 +93,13 @@ func (r *Registry) Execute(ctx context.Context, name string, args map[string]int
 	// ContextAwareProvider uses context for per-token connection isolation in HTTP mode
 	return tool.Handler(argsCopy)
 }
+
+// requestContext returns the context Execute passed to the handler in
+// args, which is cancelled when the client cancels the call, or a
+// background context for handlers called directly
+func requestContext(args map[string]interface{}) context.Context {
+	if ctx, ok := args["__context"].(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
@@ -12,6 +12,8 @@ package tools
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"pgedge-postgres-mcp/internal/mcp"
@@ -311,3 +313,70 @@ func TestExecute(t *testing.T) {
 		}
 	})
 }
+
+func TestRegistryExecute_ValidatesArguments(t *testing.T) {
+	registry := NewRegistry()
+	called := false
+	registry.Register("lookup", Tool{
+		Definition: mcp.Tool{
+			Name: "lookup",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"table_name": map[string]interface{}{"type": "string"},
+					"limit":      map[string]interface{}{"type": "integer"},
+				},
+				Required: []string{"table_name"},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			called = true
+			return mcp.NewToolSuccess("ok")
+		},
+	})
+
+	for _, tt := range []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{}, "missing required argument 'table_name'"},
+		{map[string]interface{}{"table_name": ""}, "missing required argument 'table_name'"},
+		{map[string]interface{}{"table_name": 42.0}, "argument 'table_name' must be"},
+		{map[string]interface{}{"table_name": "orders", "limit": "10"}, "argument 'limit' must be"},
+	} {
+		_, err := registry.Execute(context.Background(), "lookup", tt.args)
+		var invalidParams *mcp.InvalidParamsError
+		if !errors.As(err, &invalidParams) || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("args %v: error = %v, want invalid params containing %q", tt.args, err, tt.want)
+		}
+	}
+	if called {
+		t.Error("handler should not run with invalid arguments")
+	}
+
+	response, err := registry.Execute(context.Background(), "lookup", map[string]interface{}{"table_name": "orders"})
+	if err != nil || response.IsError || !called {
+		t.Errorf("valid arguments: response = %+v, err = %v, called = %t", response, err, called)
+	}
+}
+
+// registeredToolError runs tool through a Registry, as the providers do, so
+// its arguments are checked against its schema before the handler runs. It
+// returns the text of the error the call failed with, or "" on success.
+func registeredToolError(t *testing.T, tool Tool, args map[string]interface{}) string {
+	t.Helper()
+	registry := NewRegistry()
+	registry.Register(tool.Definition.Name, tool)
+	response, err := registry.Execute(context.Background(), tool.Definition.Name, args)
+	var invalidParams *mcp.InvalidParamsError
+	if errors.As(err, &invalidParams) {
+		return err.Error()
+	}
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !response.IsError {
+		return ""
+	}
+	return response.Content[0].Text
+}
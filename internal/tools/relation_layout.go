@@ -0,0 +1,562 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// heapTupleOverhead is the per-row overhead on a heap page: the 23-byte
+	// tuple header (padded to 24) plus a 4-byte line pointer
+	heapTupleOverhead = 28
+
+	// btreeTupleOverhead is the per-entry overhead on a btree leaf page: the
+	// 8-byte index tuple header plus a 4-byte line pointer
+	btreeTupleOverhead = 12
+
+	// defaultBtreeFillfactor is the fillfactor of btree indexes that don't set one
+	defaultBtreeFillfactor = 90
+
+	// minLayoutPages is the size (in pages) below which a table is too
+	// small for its fragmentation to matter
+	minLayoutPages = 128
+
+	// moderateFragmentation and highFragmentation are the fractions of a
+	// table's pages estimated to be wasted at which it is flagged
+	moderateFragmentation = 0.20
+	highFragmentation     = 0.50
+)
+
+// Fragmentation assessments
+const (
+	layoutSmall    = "small"
+	layoutOK       = "ok"
+	layoutModerate = "moderate"
+	layoutHigh     = "high"
+	layoutUnknown  = "unknown"
+)
+
+// RelationLayoutTool creates the relation_layout tool
+func RelationLayoutTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "relation_layout",
+			Description: `Report how tables are laid out on disk: pages, fillfactor, free space and estimated fragmentation. Read-only.
+
+<usecase>
+Use when:
+- Deciding whether a table needs VACUUM FULL or pg_repack
+- A table is much larger on disk than its row count suggests
+- Reviewing tables after large deletes or updates
+</usecase>
+
+<what_it_returns>
+Per table (largest first):
+- Pages and size on disk, row estimate and fillfactor
+- Free space: from the free space map when pg_freespacemap is installed,
+  otherwise estimated from row count and average row width
+- Estimated fragmentation (share of the table that is wasted space beyond
+  the fillfactor reserve) and an assessment: small, ok, moderate, high
+- Recommendations for tables with high fragmentation or many dead rows
+- With include_indexes, the same estimate for each btree index, reported
+  separately
+</what_it_returns>
+
+<important>
+- Estimates rely on up-to-date planner statistics; run ANALYZE first if
+  the table changed a lot
+- Free space found by VACUUM is reused by new rows; only VACUUM FULL or
+  pg_repack return it to the operating system
+- VACUUM FULL takes an ACCESS EXCLUSIVE lock for the whole rewrite;
+  pg_repack works online but needs the extension installed
+- On large databases, pass min_bloat_percent to see only the worst
+  offenders, most wasted space first
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"schema_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Only report tables in this schema (default: all user schemas)",
+					},
+					"table_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Only report this table",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of tables (and indexes) to return: largest first, or most wasted space first with min_bloat_percent. Default: 20",
+						"default":     20,
+					},
+					"min_bloat_percent": map[string]interface{}{
+						"type":        "number",
+						"description": "Only report tables and indexes whose estimated fragmentation is at least this percentage (0-100), most wasted space first. Small relations are skipped.",
+					},
+					"include_indexes": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also estimate the bloat of btree indexes and report them in a separate list. Default: false",
+						"default":     false,
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			schemaName := ValidateOptionalStringParam(args, "schema_name", "")
+			tableName := ValidateOptionalStringParam(args, "table_name", "")
+
+			limit := 20
+			if val, ok := args["limit"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'limit' must be a positive integer")
+				}
+				limit = int(val)
+			}
+
+			minBloat := 0.0
+			if val, ok := args["min_bloat_percent"].(float64); ok {
+				if val < 0 || val > 100 {
+					return mcp.NewToolError("Parameter 'min_bloat_percent' must be between 0 and 100")
+				}
+				minBloat = val
+			}
+			includeIndexes := ValidateBoolParam(args, "include_indexes", false)
+
+			// Without a threshold the largest tables are reported; with one,
+			// every table that's big enough to matter has to be checked
+			// before the worst can be picked
+			var sqlLimit interface{} = limit
+			if minBloat > 0 {
+				sqlLimit = nil
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+
+			var tableLayouts, indexLayouts []relationBloat
+			useFSM := false
+
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				var blockSize int64
+				if err := tx.QueryRow(ctx, "SELECT current_setting('block_size')::bigint").Scan(&blockSize); err != nil {
+					return fmt.Errorf("failed to read block_size: %w", err)
+				}
+
+				// pg_freespace needs the extension and, for non-superusers,
+				// pg_stat_scan_tables membership; to_regprocedure is NULL
+				// when the extension isn't installed
+				if err := tx.QueryRow(ctx, `
+					SELECT COALESCE(has_function_privilege(
+					           to_regprocedure('pg_freespace(regclass)'), 'EXECUTE'), false)`).Scan(&useFSM); err != nil {
+					return fmt.Errorf("failed to check for pg_freespacemap: %w", err)
+				}
+
+				rows, err := tx.Query(ctx, `
+					SELECT c.oid, n.nspname, c.relname,
+					       pg_relation_size(c.oid) / current_setting('block_size')::bigint,
+					       c.reltuples::float8,
+					       COALESCE((SELECT option_value::int FROM pg_options_to_table(c.reloptions)
+					                 WHERE option_name = 'fillfactor'), 100),
+					       COALESCE((SELECT SUM(s.avg_width)::int FROM pg_stats s
+					                 WHERE s.schemaname = n.nspname AND s.tablename = c.relname), 0),
+					       COALESCE(st.n_dead_tup, 0)
+					FROM pg_class c
+					JOIN pg_namespace n ON n.oid = c.relnamespace
+					LEFT JOIN pg_stat_user_tables st ON st.relid = c.oid
+					WHERE c.relkind IN ('r', 'm')
+					  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+					  AND n.nspname NOT LIKE 'pg_toast%'
+					  AND ($1 = '' OR n.nspname = $1)
+					  AND ($2 = '' OR c.relname = $2)
+					  AND (NOT $4 OR pg_relation_size(c.oid) / current_setting('block_size')::bigint >= $5)
+					ORDER BY pg_relation_size(c.oid) DESC, n.nspname, c.relname
+					LIMIT $3`, schemaName, tableName, sqlLimit, minBloat > 0, minLayoutPages)
+				if err != nil {
+					return fmt.Errorf("failed to query tables: %w", err)
+				}
+
+				type tableLayout struct {
+					oid                  uint32
+					schema, table        string
+					pages                int64
+					reltuples            float64
+					fillfactor, avgWidth int
+					deadTuples           int64
+				}
+				var tables []tableLayout
+				for rows.Next() {
+					var t tableLayout
+					if err := rows.Scan(&t.oid, &t.schema, &t.table, &t.pages, &t.reltuples,
+						&t.fillfactor, &t.avgWidth, &t.deadTuples); err != nil {
+						rows.Close()
+						return fmt.Errorf("failed to scan table layout: %w", err)
+					}
+					tables = append(tables, t)
+				}
+				rows.Close()
+				if err := rows.Err(); err != nil {
+					return err
+				}
+
+				for _, t := range tables {
+					source := "estimate"
+					freeBytes := int64(-1)
+					fragmentation := -1.0
+
+					if useFSM {
+						if err := tx.QueryRow(ctx,
+							"SELECT COALESCE(SUM(avail), 0)::bigint FROM pg_freespace($1::oid::regclass)", t.oid).Scan(&freeBytes); err != nil {
+							return fmt.Errorf("failed to read free space map for %s.%s: %w", t.schema, t.table, err)
+						}
+						source = "fsm"
+						fragmentation = fsmFragmentation(freeBytes, t.pages, blockSize, t.fillfactor)
+					} else if expected, ok := estimateExpectedPages(t.reltuples, t.avgWidth, t.fillfactor, blockSize); ok {
+						fragmentation = estimatedFragmentation(expected, t.pages)
+						if t.pages > 0 {
+							freeBytes = int64(math.Round(fragmentation * float64(t.pages*blockSize)))
+						}
+					}
+
+					tableLayouts = append(tableLayouts, relationBloat{
+						schema: t.schema, table: t.table, pages: t.pages, bytes: t.pages * blockSize,
+						reltuples: t.reltuples, fillfactor: t.fillfactor, deadTuples: t.deadTuples,
+						freeBytes: freeBytes, fragmentation: fragmentation, source: source,
+					})
+				}
+
+				if includeIndexes {
+					indexLayouts, err = queryIndexBloat(ctx, tx, schemaName, tableName, blockSize)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Error reading relation layout: %v", err))
+			}
+
+			if minBloat > 0 {
+				tableLayouts = worstBloat(tableLayouts, minBloat, limit)
+			}
+			if includeIndexes {
+				if minBloat > 0 {
+					indexLayouts = worstBloat(indexLayouts, minBloat, limit)
+				} else if len(indexLayouts) > limit {
+					indexLayouts = indexLayouts[:limit]
+				}
+			}
+
+			var results [][]interface{}
+			var recs []string
+			counts := make(map[string]int)
+			for _, t := range tableLayouts {
+				assessment := classifyFragmentation(t.fragmentation, t.pages)
+				counts[assessment]++
+
+				name := t.schema + "." + t.table
+				switch assessment {
+				case layoutHigh:
+					recs = append(recs, fmt.Sprintf("%s: about %.0f%% of %s is wasted space; consider pg_repack (online) or VACUUM FULL (locks the table) to reclaim it",
+						name, t.fragmentation*100, formatBytes(t.bytes)))
+				case layoutModerate:
+					recs = append(recs, fmt.Sprintf("%s: about %.0f%% wasted space; new rows will reuse it after VACUUM, so a rewrite is only worth it if the table won't grow back",
+						name, t.fragmentation*100))
+				}
+				if t.reltuples > 0 && float64(t.deadTuples) > 0.2*t.reltuples {
+					recs = append(recs, fmt.Sprintf("%s: %d dead rows (over 20%% of live rows); run VACUUM before deciding on a rewrite",
+						name, t.deadTuples))
+				}
+
+				freeText := "unknown"
+				if t.freeBytes >= 0 {
+					freeText = formatBytes(t.freeBytes)
+				}
+				results = append(results, []interface{}{
+					t.schema, t.table, t.pages, formatBytes(t.bytes), t.rowEstimate(),
+					t.fillfactor, freeText, t.fragmentationText(), t.source, assessment,
+				})
+			}
+
+			var indexResults [][]interface{}
+			for _, idx := range indexLayouts {
+				assessment := classifyFragmentation(idx.fragmentation, idx.pages)
+				counts["index_"+assessment]++
+				if assessment == layoutHigh {
+					recs = append(recs, fmt.Sprintf("%s.%s: about %.0f%% of %s is wasted space; REINDEX INDEX CONCURRENTLY rebuilds it without blocking writes",
+						idx.schema, idx.index, idx.fragmentation*100, formatBytes(idx.bytes)))
+				}
+				indexResults = append(indexResults, []interface{}{
+					idx.schema, idx.table, idx.index, idx.pages, formatBytes(idx.bytes), idx.rowEstimate(),
+					idx.fillfactor, idx.fragmentationText(), assessment,
+				})
+			}
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+
+			if len(results) == 0 && len(indexResults) == 0 {
+				sb.WriteString("No tables found")
+				if tableName != "" {
+					sb.WriteString(fmt.Sprintf(" named '%s'", tableName))
+				}
+				if schemaName != "" {
+					sb.WriteString(fmt.Sprintf(" in schema '%s'", schemaName))
+				}
+				if minBloat > 0 {
+					sb.WriteString(fmt.Sprintf(" with at least %g%% estimated fragmentation", minBloat))
+				}
+				sb.WriteString(".\n")
+				return mcp.NewToolSuccess(sb.String())
+			}
+
+			order := "largest first"
+			if minBloat > 0 {
+				order = fmt.Sprintf("at least %g%% fragmentation, most wasted space first", minBloat)
+			}
+			if len(results) > 0 {
+				sb.WriteString(fmt.Sprintf("Table layout (%s):\n", order))
+				sb.WriteString(FormatResultsAsTSV(
+					[]string{"schema", "table", "pages", "size", "rows", "fillfactor", "free_space", "fragmentation", "source", "assessment"},
+					results))
+				sb.WriteString("\n")
+			}
+
+			if includeIndexes {
+				if len(indexResults) > 0 {
+					sb.WriteString(fmt.Sprintf("\nBtree index bloat (estimated, %s):\n", order))
+					sb.WriteString(FormatResultsAsTSV(
+						[]string{"schema", "table", "index", "pages", "size", "rows", "fillfactor", "fragmentation", "assessment"},
+						indexResults))
+					sb.WriteString("\n")
+				} else {
+					sb.WriteString("\nNo btree indexes matched.\n")
+				}
+			}
+
+			if !useFSM && len(results) > 0 {
+				sb.WriteString("\nFree space is estimated from planner statistics. Install pg_freespacemap " +
+					"(CREATE EXTENSION pg_freespacemap) for measured values.\n")
+			}
+
+			if len(recs) > 0 {
+				sb.WriteString("\n<recommendations>\n")
+				for _, r := range recs {
+					sb.WriteString("- " + r + "\n")
+				}
+				sb.WriteString("</recommendations>\n")
+			}
+
+			logging.Info("relation_layout_executed",
+				"schema", schemaName,
+				"table", tableName,
+				"tables", len(results),
+				"indexes", len(indexResults),
+				"min_bloat_percent", minBloat,
+				"source_fsm", useFSM,
+				"high", counts[layoutHigh],
+				"index_high", counts["index_"+layoutHigh],
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// relationBloat is the estimated layout of one table or index
+type relationBloat struct {
+	schema, table, index string
+	pages, bytes         int64
+	reltuples            float64
+	fillfactor           int
+	deadTuples           int64
+	freeBytes            int64   // -1 if unknown
+	fragmentation        float64 // -1 if unknown
+	source               string
+}
+
+// wastedBytes is the estimated space lost to fragmentation
+func (r relationBloat) wastedBytes() float64 {
+	if r.fragmentation <= 0 {
+		return 0
+	}
+	return r.fragmentation * float64(r.bytes)
+}
+
+// rowEstimate formats reltuples, which is -1 for never-analyzed relations
+func (r relationBloat) rowEstimate() string {
+	if r.reltuples < 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%.0f", r.reltuples)
+}
+
+// fragmentationText formats the fragmentation as a percentage
+func (r relationBloat) fragmentationText() string {
+	if r.fragmentation < 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%.1f%%", r.fragmentation*100)
+}
+
+// worstBloat keeps the relations at or above minPercent fragmentation that
+// are large enough to matter, most wasted space first, up to limit
+func worstBloat(relations []relationBloat, minPercent float64, limit int) []relationBloat {
+	var kept []relationBloat
+	for _, r := range relations {
+		if r.pages >= minLayoutPages && r.fragmentation*100 >= minPercent {
+			kept = append(kept, r)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].wastedBytes() > kept[j].wastedBytes()
+	})
+	if len(kept) > limit {
+		kept = kept[:limit]
+	}
+	return kept
+}
+
+// queryIndexBloat estimates the bloat of btree indexes from their entry
+// count and the average width of their columns, largest first. Indexes on
+// expressions have no column statistics and are reported as unknown.
+func queryIndexBloat(ctx context.Context, tx pgx.Tx, schemaName, tableName string, blockSize int64) ([]relationBloat, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT n.nspname, t.relname, i.relname,
+		       pg_relation_size(i.oid) / current_setting('block_size')::bigint,
+		       i.reltuples::float8,
+		       COALESCE((SELECT option_value::int FROM pg_options_to_table(i.reloptions)
+		                 WHERE option_name = 'fillfactor'), $3),
+		       CASE WHEN 0 = ANY(x.indkey::int2[]) THEN 0
+		            ELSE COALESCE((SELECT SUM(s.avg_width)::int
+		                           FROM pg_attribute a
+		                           JOIN pg_stats s ON s.schemaname = n.nspname
+		                                          AND s.tablename = t.relname
+		                                          AND s.attname = a.attname
+		                           WHERE a.attrelid = t.oid
+		                             AND a.attnum = ANY(x.indkey::int2[])), 0)
+		       END
+		FROM pg_index x
+		JOIN pg_class i ON i.oid = x.indexrelid
+		JOIN pg_class t ON t.oid = x.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_am am ON am.oid = i.relam
+		WHERE am.amname = 'btree'
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND n.nspname NOT LIKE 'pg_toast%'
+		  AND ($1 = '' OR n.nspname = $1)
+		  AND ($2 = '' OR t.relname = $2)
+		ORDER BY pg_relation_size(i.oid) DESC, n.nspname, i.relname`,
+		schemaName, tableName, defaultBtreeFillfactor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	var indexes []relationBloat
+	for rows.Next() {
+		var r relationBloat
+		var avgWidth int
+		if err := rows.Scan(&r.schema, &r.table, &r.index, &r.pages, &r.reltuples,
+			&r.fillfactor, &avgWidth); err != nil {
+			return nil, fmt.Errorf("failed to scan index layout: %w", err)
+		}
+		r.bytes = r.pages * blockSize
+		r.freeBytes = -1
+		r.fragmentation = -1
+		r.source = "estimate"
+		if expected, ok := estimateExpectedIndexPages(r.reltuples, avgWidth, r.fillfactor, blockSize); ok {
+			r.fragmentation = estimatedFragmentation(expected, r.pages)
+		}
+		indexes = append(indexes, r)
+	}
+	return indexes, rows.Err()
+}
+
+// estimateExpectedIndexPages estimates how many pages a freshly built btree
+// index would need, including its metapage. ok is false without statistics.
+func estimateExpectedIndexPages(reltuples float64, avgWidth, fillfactor int, blockSize int64) (pages float64, ok bool) {
+	if reltuples < 0 || avgWidth <= 0 || fillfactor <= 0 || blockSize <= 0 {
+		return 0, false
+	}
+	usable := float64(blockSize) * float64(fillfactor) / 100
+	return math.Ceil(reltuples*float64(avgWidth+btreeTupleOverhead)/usable) + 1, true
+}
+
+// estimateExpectedPages estimates how many pages a table would need if it
+// were freshly packed at its fillfactor. ok is false without statistics.
+func estimateExpectedPages(reltuples float64, avgWidth, fillfactor int, blockSize int64) (pages float64, ok bool) {
+	if reltuples < 0 || avgWidth <= 0 || fillfactor <= 0 || blockSize <= 0 {
+		return 0, false
+	}
+	usable := float64(blockSize) * float64(fillfactor) / 100
+	return math.Ceil(reltuples * float64(avgWidth+heapTupleOverhead) / usable), true
+}
+
+// estimatedFragmentation is the share of actual pages not needed for the
+// estimated live data
+func estimatedFragmentation(expectedPages float64, actualPages int64) float64 {
+	if actualPages <= 0 || expectedPages >= float64(actualPages) {
+		return 0
+	}
+	return 1 - expectedPages/float64(actualPages)
+}
+
+// fsmFragmentation is the share of the table that is free space beyond the
+// space its fillfactor deliberately leaves empty
+func fsmFragmentation(freeBytes, pages, blockSize int64, fillfactor int) float64 {
+	total := float64(pages * blockSize)
+	if total <= 0 {
+		return 0
+	}
+	reserved := total * float64(100-fillfactor) / 100
+	wasted := float64(freeBytes) - reserved
+	if wasted <= 0 {
+		return 0
+	}
+	return wasted / total
+}
+
+// classifyFragmentation assesses a table from its estimated fragmentation;
+// a negative fragmentation means it could not be estimated
+func classifyFragmentation(fragmentation float64, pages int64) string {
+	switch {
+	case pages < minLayoutPages:
+		return layoutSmall
+	case fragmentation < 0:
+		return layoutUnknown
+	case fragmentation >= highFragmentation:
+		return layoutHigh
+	case fragmentation >= moderateFragmentation:
+		return layoutModerate
+	default:
+		return layoutOK
+	}
+}
@@ -0,0 +1,168 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Relation Layout Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRelationLayoutToolDefinition(t *testing.T) {
+	tool := RelationLayoutTool(nil)
+
+	if tool.Definition.Name != "relation_layout" {
+		t.Errorf("Tool name = %v, want relation_layout", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"schema_name", "table_name", "limit", "min_bloat_percent", "include_indexes"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+}
+
+func TestRelationLayoutInvalidLimit(t *testing.T) {
+	tool := RelationLayoutTool(nil)
+
+	response, err := tool.Handler(map[string]interface{}{"limit": float64(0)})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !response.IsError {
+		t.Error("Expected error response for limit=0")
+	}
+}
+
+func TestRelationLayoutInvalidMinBloat(t *testing.T) {
+	tool := RelationLayoutTool(nil)
+
+	for _, val := range []float64{-1, 101} {
+		response, err := tool.Handler(map[string]interface{}{"min_bloat_percent": val})
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if !response.IsError {
+			t.Errorf("Expected error response for min_bloat_percent=%v", val)
+		}
+	}
+}
+
+func TestEstimateExpectedPages(t *testing.T) {
+	// 100,000 rows of 72 bytes + 28 overhead = 10,000,000 bytes at 100%
+	// fillfactor in 8 kB pages
+	pages, ok := estimateExpectedPages(100000, 72, 100, 8192)
+	if !ok {
+		t.Fatal("Expected an estimate")
+	}
+	if pages != math.Ceil(10000000.0/8192) {
+		t.Errorf("estimateExpectedPages() = %v, want %v", pages, math.Ceil(10000000.0/8192))
+	}
+
+	// Half the fillfactor needs twice the pages
+	halfFilled, _ := estimateExpectedPages(100000, 72, 50, 8192)
+	if halfFilled != math.Ceil(10000000.0/4096) {
+		t.Errorf("estimateExpectedPages() at fillfactor 50 = %v", halfFilled)
+	}
+
+	// Never analyzed (reltuples = -1) or no column statistics
+	if _, ok := estimateExpectedPages(-1, 72, 100, 8192); ok {
+		t.Error("Expected no estimate without reltuples")
+	}
+	if _, ok := estimateExpectedPages(1000, 0, 100, 8192); ok {
+		t.Error("Expected no estimate without avg_width")
+	}
+}
+
+func TestEstimatedFragmentation(t *testing.T) {
+	if got := estimatedFragmentation(250, 1000); got != 0.75 {
+		t.Errorf("estimatedFragmentation(250, 1000) = %v, want 0.75", got)
+	}
+	if got := estimatedFragmentation(1200, 1000); got != 0 {
+		t.Errorf("estimatedFragmentation() with stale stats = %v, want 0", got)
+	}
+	if got := estimatedFragmentation(10, 0); got != 0 {
+		t.Errorf("estimatedFragmentation() of empty table = %v, want 0", got)
+	}
+}
+
+func TestFSMFragmentation(t *testing.T) {
+	// 1000 pages of 8 kB; half free at fillfactor 100
+	if got := fsmFragmentation(500*8192, 1000, 8192, 100); got != 0.5 {
+		t.Errorf("fsmFragmentation() = %v, want 0.5", got)
+	}
+	// At fillfactor 80, the first 20% free is intentional
+	if got := fsmFragmentation(500*8192, 1000, 8192, 80); math.Abs(got-0.3) > 1e-9 {
+		t.Errorf("fsmFragmentation() at fillfactor 80 = %v, want 0.3", got)
+	}
+	if got := fsmFragmentation(100*8192, 1000, 8192, 80); got != 0 {
+		t.Errorf("fsmFragmentation() within reserve = %v, want 0", got)
+	}
+}
+
+func TestClassifyFragmentation(t *testing.T) {
+	tests := []struct {
+		name          string
+		fragmentation float64
+		pages         int64
+		expected      string
+	}{
+		{"small table", 0.9, 10, layoutSmall},
+		{"no estimate", -1, 1000, layoutUnknown},
+		{"compact", 0.05, 1000, layoutOK},
+		{"moderate", 0.3, 1000, layoutModerate},
+		{"high", 0.6, 1000, layoutHigh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyFragmentation(tt.fragmentation, tt.pages); got != tt.expected {
+				t.Errorf("classifyFragmentation() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEstimateExpectedIndexPages(t *testing.T) {
+	// 100,000 entries of 4 bytes + 12 overhead = 1,600,000 bytes at the
+	// default fillfactor, plus the metapage
+	pages, ok := estimateExpectedIndexPages(100000, 4, defaultBtreeFillfactor, 8192)
+	if !ok {
+		t.Fatal("Expected an estimate")
+	}
+	want := math.Ceil(1600000.0/(8192*0.9)) + 1
+	if pages != want {
+		t.Errorf("estimateExpectedIndexPages() = %v, want %v", pages, want)
+	}
+
+	// Expression indexes have no column width
+	if _, ok := estimateExpectedIndexPages(100000, 0, defaultBtreeFillfactor, 8192); ok {
+		t.Error("Expected no estimate without avg_width")
+	}
+}
+
+func TestWorstBloat(t *testing.T) {
+	relations := []relationBloat{
+		{table: "large_ok", pages: 10000, bytes: 10000 * 8192, fragmentation: 0.1},
+		{table: "large_bloated", pages: 10000, bytes: 10000 * 8192, fragmentation: 0.4},
+		{table: "medium_bloated", pages: 1000, bytes: 1000 * 8192, fragmentation: 0.9},
+		{table: "small_bloated", pages: 10, bytes: 10 * 8192, fragmentation: 0.9},
+		{table: "unknown", pages: 10000, bytes: 10000 * 8192, fragmentation: -1},
+	}
+
+	got := worstBloat(relations, 30, 10)
+	if len(got) != 2 || got[0].table != "large_bloated" || got[1].table != "medium_bloated" {
+		t.Errorf("worstBloat() = %+v, want large_bloated then medium_bloated", got)
+	}
+
+	if got := worstBloat(relations, 30, 1); len(got) != 1 || got[0].table != "large_bloated" {
+		t.Errorf("worstBloat() with limit 1 = %+v", got)
+	}
+}
@@ -0,0 +1,64 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// noReplicaError is returned when a database is configured with
+// replica_only and the server a tool ran against is not a hot standby
+type noReplicaError struct {
+	reason string
+}
+
+func (e *noReplicaError) Error() string {
+	return fmt.Sprintf("No replica available: %s. This database is configured with replica_only, "+
+		"so tools only run against a hot standby.", e.reason)
+}
+
+// checkReplica enforces the database's replica_only setting. It runs in the
+// tool's own transaction, so the server checked is the one the tool's
+// queries run on. It returns a *noReplicaError when replica_only is set and
+// the server is not a hot standby or could not be checked.
+func checkReplica(ctx context.Context, dbClient *database.Client, tx pgx.Tx) error {
+	if dbClient == nil || !dbClient.IsReplicaOnly() {
+		return nil
+	}
+
+	var inRecovery bool
+	if err := tx.QueryRow(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+		return &noReplicaError{reason: fmt.Sprintf("the server could not be checked (%v)", err)}
+	}
+	if !inRecovery {
+		return &noReplicaError{reason: "the connected server is a primary, not a standby"}
+	}
+
+	return nil
+}
+
+// noReplicaResponse returns the tool error for a *noReplicaError in err's
+// chain, or nil if there is none
+func noReplicaResponse(err error) *mcp.ToolResponse {
+	var noReplica *noReplicaError
+	if !errors.As(err, &noReplica) {
+		return nil
+	}
+	resp, _ := mcp.NewToolError(noReplica.Error())
+	return &resp
+}
@@ -0,0 +1,98 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Replica Only Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/database"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// recoveryTx answers pg_is_in_recovery() without a server
+type recoveryTx struct {
+	pgx.Tx
+	inRecovery bool
+	err        error
+}
+
+type recoveryRow struct {
+	inRecovery bool
+	err        error
+}
+
+func (r recoveryRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*bool) = r.inRecovery
+	return nil
+}
+
+func (tx recoveryTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return recoveryRow{inRecovery: tx.inRecovery, err: tx.err}
+}
+
+func TestCheckReplica(t *testing.T) {
+	connStr := "postgres://localhost/test"
+	ctx := context.Background()
+
+	// Without replica_only nothing is checked
+	client := database.NewTestClient(connStr, map[string]database.TableInfo{})
+	if err := checkReplica(ctx, client, nil); err != nil {
+		t.Errorf("Expected no error without replica_only, got %v", err)
+	}
+
+	client = database.NewTestClientWithConfig(connStr, map[string]database.TableInfo{},
+		&config.NamedDatabaseConfig{ReplicaOnly: true})
+
+	if err := checkReplica(ctx, client, recoveryTx{inRecovery: true}); err != nil {
+		t.Errorf("Expected no error on a standby, got %v", err)
+	}
+
+	tests := map[string]recoveryTx{
+		"a primary":   {inRecovery: false},
+		"check fails": {err: errors.New("connection reset")},
+	}
+	for name, tx := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkReplica(ctx, client, tx)
+			var noReplica *noReplicaError
+			if !errors.As(err, &noReplica) {
+				t.Fatalf("Expected *noReplicaError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "No replica available") {
+				t.Errorf("Expected 'No replica available' error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestNoReplicaResponse(t *testing.T) {
+	if resp := noReplicaResponse(errors.New("syntax error")); resp != nil {
+		t.Errorf("Expected nil for other errors, got %v", resp.Content)
+	}
+
+	wrapped := fmt.Errorf("failed: %w", &noReplicaError{reason: "the connected server is a primary, not a standby"})
+	resp := noReplicaResponse(wrapped)
+	if resp == nil || !resp.IsError {
+		t.Fatal("Expected an error response for a wrapped noReplicaError")
+	}
+	if !strings.HasPrefix(resp.Content[0].Text, "No replica available: the connected server is a primary") {
+		t.Errorf("Unexpected message: %s", resp.Content[0].Text)
+	}
+}
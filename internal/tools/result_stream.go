@@ -0,0 +1,102 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/mcp"
+	"pgedge-postgres-mcp/internal/tsv"
+)
+
+// streamBatchRows is how many rows are formatted before they are sent to
+// the result stream
+const streamBatchRows = 100
+
+// resultStream returns the stream the transport installed for this call,
+// or nil when results are returned in one piece
+func resultStream(args map[string]interface{}) mcp.ResultStream {
+	ctx, ok := args["__context"].(context.Context)
+	if !ok {
+		return nil
+	}
+	return mcp.ResultStreamFromContext(ctx)
+}
+
+// rowStreamer formats rows as TSV or CSV, in the same layout as
+// FormatResultsAsTSV and FormatResultsAsCSV, and sends them to a result
+// stream in batches so only one batch is held in memory
+type rowStreamer struct {
+	stream mcp.ResultStream
+	format string
+	buf    strings.Builder
+	record []string
+	rows   int
+}
+
+// newRowStreamer starts a result with the header row
+func newRowStreamer(stream mcp.ResultStream, format string, columnNames []string) *rowStreamer {
+	s := &rowStreamer{stream: stream, format: format, record: make([]string, len(columnNames))}
+	if format == "csv" {
+		s.buf.WriteString(csvLine(columnNames))
+	} else {
+		s.buf.WriteString(strings.Join(columnNames, "\t"))
+	}
+	return s
+}
+
+// add formats a row, sending the batch once it is full
+func (s *rowStreamer) add(row []interface{}) error {
+	for i := range s.record {
+		s.record[i] = ""
+		if i < len(row) {
+			if s.format == "csv" {
+				s.record[i] = tsv.StringValue(row[i])
+			} else {
+				s.record[i] = tsv.FormatValue(row[i])
+			}
+		}
+	}
+
+	s.buf.WriteString("\n")
+	if s.format == "csv" {
+		s.buf.WriteString(csvLine(s.record))
+	} else {
+		s.buf.WriteString(strings.Join(s.record, "\t"))
+	}
+	s.rows++
+
+	if s.rows%streamBatchRows == 0 {
+		return s.flush()
+	}
+	return nil
+}
+
+// flush sends the rows formatted so far
+func (s *rowStreamer) flush() error {
+	if s.buf.Len() == 0 {
+		return nil
+	}
+	err := s.stream.WriteText(s.buf.String())
+	s.buf.Reset()
+	return err
+}
+
+// csvLine encodes one CSV record without the line terminator
+func csvLine(record []string) string {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	_ = w.Write(record) //nolint:errcheck // strings.Builder never errors
+	w.Flush()
+	return strings.TrimSuffix(sb.String(), "\n")
+}
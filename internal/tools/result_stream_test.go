@@ -0,0 +1,98 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Result Streaming Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// recordingStream collects what is written to it
+type recordingStream struct {
+	writes []string
+}
+
+func (s *recordingStream) WriteText(text string) error {
+	s.writes = append(s.writes, text)
+	return nil
+}
+
+func TestRowStreamerMatchesBufferedFormat(t *testing.T) {
+	columns := []string{"id", "note"}
+	var rows [][]interface{}
+	for i := 0; i < streamBatchRows+5; i++ {
+		rows = append(rows, []interface{}{i, "a, \"b\"\tc"})
+	}
+	rows = append(rows, []interface{}{nil, nil})
+
+	for format, buffered := range map[string]string{
+		"tsv": FormatResultsAsTSV(columns, rows),
+		"csv": FormatResultsAsCSV(columns, rows),
+	} {
+		stream := &recordingStream{}
+		streamer := newRowStreamer(stream, format, columns)
+		for _, row := range rows {
+			if err := streamer.add(row); err != nil {
+				t.Fatalf("add failed: %v", err)
+			}
+		}
+		if err := streamer.flush(); err != nil {
+			t.Fatalf("flush failed: %v", err)
+		}
+
+		// One full batch, then the rest
+		if len(stream.writes) != 2 {
+			t.Errorf("%s: expected 2 writes, got %d", format, len(stream.writes))
+		}
+		if got := strings.Join(stream.writes, ""); got != buffered {
+			t.Errorf("%s: streamed output differs from buffered output:\n%q\n%q", format, got, buffered)
+		}
+	}
+}
+
+func TestResultStream(t *testing.T) {
+	if stream := resultStream(map[string]interface{}{}); stream != nil {
+		t.Error("expected no stream without a context")
+	}
+
+	stream := &recordingStream{}
+	ctx := mcp.WithResultStream(context.Background(), stream)
+	if got := resultStream(map[string]interface{}{"__context": ctx}); got != stream {
+		t.Error("expected the stream from the context")
+	}
+}
+
+func TestStreamedResultsSummary(t *testing.T) {
+	tests := []struct {
+		name                   string
+		rows, offset, limit    int
+		wasTruncated, hitLimit bool
+		want                   string
+	}{
+		{"all rows", 42, 0, 100, false, false, "(42 rows)"},
+		{"more available", 100, 0, 100, true, false, "(100 rows shown, more available - use offset=100"},
+		{"page", 50, 100, 100, false, false, "(Rows 101-150)"},
+		{"page with more", 100, 100, 100, true, false, "(Rows 101-200, more available - use offset=200"},
+		{"row cap", 1000, 0, 0, false, true, "Results truncated at 1000 rows"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := streamedResultsSummary(tt.rows, tt.offset, tt.limit, 1000, tt.wasTruncated, tt.hitLimit)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("streamedResultsSummary() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// maxExactCountRows is the estimated row count above which an exact
+	// count needs force=true
+	maxExactCountRows = 10000000
+
+	// Drift percentages at which the planner estimate is reported as off
+	rowcountDriftModerate = 10.0
+	rowcountDriftHigh     = 50.0
+)
+
+// rowcountStats is what PostgreSQL knows about a table's row count without
+// counting it
+type rowcountStats struct {
+	relTuples        float64
+	liveTuples       int64
+	modSinceAnalyze  int64
+	lastAnalyze      string
+	lastAutoAnalyze  string
+	hasActivityStats bool
+}
+
+// RowcountAccuracyTool creates the rowcount_accuracy tool
+func RowcountAccuracyTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "rowcount_accuracy",
+			Description: `Compare the planner's row estimate for a table with an exact COUNT(*).
+
+<usecase>
+Use when:
+- A query plan's row estimates look far from reality
+- Deciding whether a table needs ANALYZE or different autovacuum settings
+- Checking statistics health after a bulk load or delete
+</usecase>
+
+<what_it_returns>
+The planner estimate (pg_class.reltuples), the exact row count, the drift
+between them as a percentage, the statistics collector's live tuple count,
+rows modified since the last analyze, when the table was last analyzed,
+and an assessment (ok, moderate, high, never analyzed).
+</what_it_returns>
+
+<important>
+- COUNT(*) reads the whole table; tables estimated above 10,000,000 rows
+  are refused unless force=true
+- The count is cancelled at the query timeout (timeout_seconds overrides it)
+- Small drift is normal on busy tables; act on moderate or high drift
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"table_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Table to check, as 'schema.table' or 'table' (public schema)",
+					},
+					"force": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Count tables estimated above 10,000,000 rows anyway (default: false)",
+					},
+					"timeout_seconds": timeoutSecondsProperty(dbClient),
+				},
+				Required: []string{"table_name"},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			tableName, _ := args["table_name"].(string)
+			force, _ := args["force"].(bool)
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			timeout, errResp := resolveQueryTimeout(dbClient, args)
+			if errResp != nil {
+				return *errResp, nil
+			}
+
+			metadata := dbClient.GetMetadata()
+			tableInfo, err := findTableInMetadataMap(metadata, tableName)
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Table '%s' not found.\n\n<available_tables>\n%s</available_tables>",
+					tableName, listAvailableTables(metadata)))
+			}
+			if tableInfo.TableType == "VIEW" {
+				return mcp.NewToolError(fmt.Sprintf("%s.%s is a view; views have no planner row estimate",
+					tableInfo.SchemaName, tableInfo.TableName))
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			qualifiedName := quoteIdentifier(tableInfo.SchemaName) + "." + quoteIdentifier(tableInfo.TableName)
+			displayName := tableInfo.SchemaName + "." + tableInfo.TableName
+
+			ctx, cancel := withQueryTimeout(requestContext(args), timeout)
+			defer cancel()
+
+			var stats rowcountStats
+			var actual int64
+			tooLarge := false
+			err = executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				if err := setStatementTimeout(ctx, tx, timeout); err != nil {
+					return err
+				}
+
+				var lastAnalyze, lastAutoAnalyze *string
+				var liveTuples, modSinceAnalyze *int64
+				if err := tx.QueryRow(ctx, `
+					SELECT c.reltuples::float8, s.n_live_tup, s.n_mod_since_analyze,
+					       to_char(s.last_analyze, 'YYYY-MM-DD HH24:MI:SS'),
+					       to_char(s.last_autoanalyze, 'YYYY-MM-DD HH24:MI:SS')
+					FROM pg_class c
+					LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
+					WHERE c.oid = $1::regclass`, qualifiedName).Scan(
+					&stats.relTuples, &liveTuples, &modSinceAnalyze, &lastAnalyze, &lastAutoAnalyze); err != nil {
+					return fmt.Errorf("failed to read table statistics: %w", err)
+				}
+				if liveTuples != nil {
+					stats.hasActivityStats = true
+					stats.liveTuples = *liveTuples
+				}
+				if modSinceAnalyze != nil {
+					stats.modSinceAnalyze = *modSinceAnalyze
+				}
+				if lastAnalyze != nil {
+					stats.lastAnalyze = *lastAnalyze
+				}
+				if lastAutoAnalyze != nil {
+					stats.lastAutoAnalyze = *lastAutoAnalyze
+				}
+
+				if stats.relTuples > maxExactCountRows && !force {
+					tooLarge = true
+					return nil
+				}
+
+				return tx.QueryRow(ctx, "SELECT count(*) FROM "+qualifiedName).Scan(&actual)
+			})
+			if err != nil {
+				if isQueryTimeout(ctx, err) {
+					return mcp.NewToolError(queryTimeoutMessage(timeout))
+				}
+				return mcp.NewToolError(fmt.Sprintf("Error checking row count of %s: %v", displayName, err))
+			}
+
+			if tooLarge {
+				return mcp.NewToolError(fmt.Sprintf("%s has about %.0f rows (planner estimate); an exact COUNT(*) would read the whole table. "+
+					"Call again with force=true (and a larger timeout_seconds if needed) to count it anyway.", displayName, stats.relTuples))
+			}
+
+			drift, hasDrift := rowcountDrift(stats.relTuples, actual)
+			assessment := classifyRowcountDrift(stats.relTuples, drift, hasDrift)
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(fmt.Sprintf("Table: %s\n", displayName))
+			if stats.relTuples < 0 {
+				sb.WriteString("Planner estimate (reltuples): unknown (never analyzed)\n")
+			} else {
+				sb.WriteString(fmt.Sprintf("Planner estimate (reltuples): %.0f\n", stats.relTuples))
+			}
+			sb.WriteString(fmt.Sprintf("Actual rows (COUNT(*)): %d\n", actual))
+			if hasDrift {
+				sb.WriteString(fmt.Sprintf("Drift: %+.1f%%\n", drift))
+			}
+			if stats.hasActivityStats {
+				sb.WriteString(fmt.Sprintf("Live tuples (pg_stat_user_tables): %d\n", stats.liveTuples))
+				sb.WriteString(fmt.Sprintf("Rows modified since last analyze: %d\n", stats.modSinceAnalyze))
+			}
+			sb.WriteString(fmt.Sprintf("Last analyze: %s\n", orNever(stats.lastAnalyze)))
+			sb.WriteString(fmt.Sprintf("Last autoanalyze: %s\n", orNever(stats.lastAutoAnalyze)))
+			sb.WriteString(fmt.Sprintf("Assessment: %s\n", assessment))
+
+			switch assessment {
+			case "high", "moderate", "never analyzed":
+				sb.WriteString("\n<recommendations>\n")
+				sb.WriteString(fmt.Sprintf("- Run ANALYZE %s; so the planner works from current statistics\n", qualifiedName))
+				sb.WriteString("- If the drift returns, lower autovacuum_analyze_scale_factor for this table, e.g.\n")
+				sb.WriteString(fmt.Sprintf("  ALTER TABLE %s SET (autovacuum_analyze_scale_factor = 0.02);\n", qualifiedName))
+				sb.WriteString("</recommendations>\n")
+			}
+
+			logging.Info("rowcount_accuracy_executed",
+				"table", displayName,
+				"estimate", int64(stats.relTuples),
+				"actual", actual,
+				"assessment", assessment,
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// rowcountDrift returns how far the planner estimate is from the actual
+// count as a percentage of the actual count (positive when the planner
+// overestimates), and false if there is no estimate to compare
+func rowcountDrift(estimate float64, actual int64) (float64, bool) {
+	if estimate < 0 {
+		return 0, false
+	}
+	if actual == 0 {
+		if estimate == 0 {
+			return 0, true
+		}
+		return 100, true
+	}
+	return (estimate - float64(actual)) / float64(actual) * 100, true
+}
+
+// classifyRowcountDrift turns a drift percentage into an assessment
+func classifyRowcountDrift(estimate, drift float64, hasDrift bool) string {
+	switch {
+	case !hasDrift || estimate < 0:
+		return "never analyzed"
+	case math.Abs(drift) >= rowcountDriftHigh:
+		return "high"
+	case math.Abs(drift) >= rowcountDriftModerate:
+		return "moderate"
+	default:
+		return "ok"
+	}
+}
+
+// orNever returns s, or "never" if it is empty
+func orNever(s string) string {
+	if s == "" {
+		return "never"
+	}
+	return s
+}
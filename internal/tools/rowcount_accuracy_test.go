@@ -0,0 +1,121 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Rowcount Accuracy Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/database"
+)
+
+func TestRowcountAccuracyToolDefinition(t *testing.T) {
+	tool := RowcountAccuracyTool(nil)
+
+	if tool.Definition.Name != "rowcount_accuracy" {
+		t.Errorf("Tool name = %v, want rowcount_accuracy", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"table_name", "force", "timeout_seconds"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+}
+
+func TestRowcountAccuracyValidation(t *testing.T) {
+	connStr := "postgres://localhost/test"
+	client := database.NewTestClient(connStr, map[string]database.TableInfo{
+		"public.orders": {
+			SchemaName: "public",
+			TableName:  "orders",
+			TableType:  "TABLE",
+		},
+		"public.recent_orders": {
+			SchemaName: "public",
+			TableName:  "recent_orders",
+			TableType:  "VIEW",
+		},
+	})
+	tool := RowcountAccuracyTool(client)
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"missing table", map[string]interface{}{}, "table_name"},
+		{"unknown table", map[string]interface{}{"table_name": "nope"}, "not found"},
+		{"view", map[string]interface{}{"table_name": "recent_orders"}, "is a view"},
+		{"invalid timeout", map[string]interface{}{"table_name": "orders", "timeout_seconds": float64(-1)}, "timeout_seconds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := registeredToolError(t, tool, tt.args)
+			if text == "" {
+				t.Fatal("Expected error response")
+			}
+			if !strings.Contains(text, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, text)
+			}
+		})
+	}
+}
+
+func TestRowcountDrift(t *testing.T) {
+	tests := []struct {
+		estimate  float64
+		actual    int64
+		wantDrift float64
+		wantOK    bool
+	}{
+		{-1, 100, 0, false},
+		{0, 0, 0, true},
+		{50, 0, 100, true},
+		{1000, 1000, 0, true},
+		{1500, 1000, 50, true},
+		{800, 1000, -20, true},
+	}
+
+	for _, tt := range tests {
+		drift, ok := rowcountDrift(tt.estimate, tt.actual)
+		if ok != tt.wantOK || math.Abs(drift-tt.wantDrift) > 1e-9 {
+			t.Errorf("rowcountDrift(%v, %d) = (%v, %v), want (%v, %v)",
+				tt.estimate, tt.actual, drift, ok, tt.wantDrift, tt.wantOK)
+		}
+	}
+}
+
+func TestClassifyRowcountDrift(t *testing.T) {
+	tests := []struct {
+		estimate float64
+		drift    float64
+		hasDrift bool
+		want     string
+	}{
+		{-1, 0, false, "never analyzed"},
+		{1000, 0, true, "ok"},
+		{1000, -9.9, true, "ok"},
+		{1000, 10, true, "moderate"},
+		{1000, -49, true, "moderate"},
+		{1000, 50, true, "high"},
+		{1000, -75, true, "high"},
+	}
+
+	for _, tt := range tests {
+		if got := classifyRowcountDrift(tt.estimate, tt.drift, tt.hasDrift); got != tt.want {
+			t.Errorf("classifyRowcountDrift(%v, %v, %v) = %q, want %q",
+				tt.estimate, tt.drift, tt.hasDrift, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// currentConnection is what show_current_connection reports
+type currentConnection struct {
+	name           string // configured database name, if any
+	host           string
+	port           uint16
+	database       string
+	user           string
+	serverVersion  string
+	inRecovery     bool
+	readOnly       bool
+	metadataLoaded bool
+	tables         int
+}
+
+// ShowCurrentConnectionTool creates the show_current_connection tool
+func ShowCurrentConnectionTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "show_current_connection",
+			Description: `Show which database the other tools are currently using.
+
+<usecase>
+Use when:
+- Several databases are configured and it isn't clear which one is selected
+- Before running queries, to confirm the target database
+</usecase>
+
+<what_it_returns>
+The configured database name, host, port, database and user (never the
+password), the PostgreSQL server version, whether the server is a primary
+or standby, and how many tables and views are loaded in the schema
+metadata.
+</what_it_returns>
+
+<important>
+- With authentication enabled this is the connection for the caller's own
+  token; other users may be connected elsewhere
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			connStr := dbClient.GetDefaultConnection()
+			if connStr == "" {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			connConfig := pool.Config().ConnConfig
+			conn := currentConnection{
+				name:           dbClient.DatabaseName(),
+				host:           connConfig.Host,
+				port:           connConfig.Port,
+				database:       connConfig.Database,
+				user:           connConfig.User,
+				readOnly:       dbClient.IsReadOnly(),
+				metadataLoaded: dbClient.IsMetadataLoadedFor(connStr),
+				tables:         len(dbClient.GetMetadataFor(connStr)),
+			}
+
+			ctx, cancel := withQueryTimeout(requestContext(args), dbClient.QueryTimeout())
+			defer cancel()
+
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				return tx.QueryRow(ctx,
+					"SELECT current_setting('server_version'), current_user, current_database(), pg_is_in_recovery()",
+				).Scan(&conn.serverVersion, &conn.user, &conn.database, &conn.inRecovery)
+			})
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Database: %s\n\nFailed to query the server: %v", database.SanitizeConnStr(connStr), err))
+			}
+
+			logging.Info("show_current_connection_executed",
+				"database", conn.name,
+				"tables", conn.tables,
+			)
+
+			return mcp.NewToolSuccess(formatCurrentConnection(conn))
+		},
+	}
+}
+
+// formatCurrentConnection describes the current connection
+func formatCurrentConnection(conn currentConnection) string {
+	role := "primary"
+	if conn.inRecovery {
+		role = "standby"
+	}
+	mode := "read-write"
+	if conn.readOnly {
+		mode = "read-only"
+	}
+
+	var sb strings.Builder
+	if conn.name != "" {
+		sb.WriteString(fmt.Sprintf("Configured database: %s\n", conn.name))
+	}
+	sb.WriteString(fmt.Sprintf("Host: %s\n", conn.host))
+	sb.WriteString(fmt.Sprintf("Port: %d\n", conn.port))
+	sb.WriteString(fmt.Sprintf("Database: %s\n", conn.database))
+	sb.WriteString(fmt.Sprintf("User: %s\n", conn.user))
+	sb.WriteString(fmt.Sprintf("Server version: PostgreSQL %s (%s)\n", conn.serverVersion, role))
+	sb.WriteString(fmt.Sprintf("Access mode: %s\n", mode))
+	if conn.metadataLoaded {
+		sb.WriteString(fmt.Sprintf("Metadata: %d tables and views loaded", conn.tables))
+	} else {
+		sb.WriteString("Metadata: not loaded yet")
+	}
+	return sb.String()
+}
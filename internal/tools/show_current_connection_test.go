@@ -0,0 +1,84 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Show Current Connection Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/database"
+)
+
+func TestShowCurrentConnectionToolDefinition(t *testing.T) {
+	tool := ShowCurrentConnectionTool(nil)
+
+	if tool.Definition.Name != "show_current_connection" {
+		t.Errorf("Tool name = %v, want show_current_connection", tool.Definition.Name)
+	}
+	if len(tool.Definition.InputSchema.Properties) != 0 {
+		t.Errorf("Properties = %v, want none", tool.Definition.InputSchema.Properties)
+	}
+}
+
+func TestShowCurrentConnectionToolNotConnected(t *testing.T) {
+	tool := ShowCurrentConnectionTool(database.NewClient(nil))
+
+	response, err := tool.Handler(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !response.IsError {
+		t.Error("Expected error response without a connection")
+	}
+}
+
+func TestFormatCurrentConnection(t *testing.T) {
+	conn := currentConnection{
+		name:           "analytics",
+		host:           "olap.example.com",
+		port:           5432,
+		database:       "dw",
+		user:           "report",
+		serverVersion:  "17.2",
+		readOnly:       true,
+		metadataLoaded: true,
+		tables:         42,
+	}
+
+	out := formatCurrentConnection(conn)
+	for _, want := range []string{
+		"Configured database: analytics\n",
+		"Host: olap.example.com\n",
+		"Port: 5432\n",
+		"Database: dw\n",
+		"User: report\n",
+		"Server version: PostgreSQL 17.2 (primary)\n",
+		"Access mode: read-only\n",
+		"Metadata: 42 tables and views loaded",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+
+	conn.name = ""
+	conn.inRecovery = true
+	conn.metadataLoaded = false
+	out = formatCurrentConnection(conn)
+	if strings.Contains(out, "Configured database:") {
+		t.Errorf("Unexpected configured name:\n%s", out)
+	}
+	for _, want := range []string{"(standby)", "Metadata: not loaded yet"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+}
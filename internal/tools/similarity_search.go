@@ -13,7 +13,10 @@ package tools
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	"pgedge-postgres-mcp/internal/config"
 	"pgedge-postgres-mcp/internal/database"
@@ -21,6 +24,8 @@ import (
 	"pgedge-postgres-mcp/internal/logging"
 	"pgedge-postgres-mcp/internal/mcp"
 	"pgedge-postgres-mcp/internal/search"
+
+	"github.com/jackc/pgx/v5"
 )
 
 // SimilaritySearchTool creates the similarity_search tool for hybrid semantic + lexical search
@@ -70,7 +75,11 @@ Use similarity_search when you need:
 </usecase>
 
 <technical_details>
-- Hybrid: Vector similarity (pgvector) + BM25 lexical ranking
+- Hybrid: Vector similarity (pgvector) + BM25 lexical ranking of the chunks
+- mode="hybrid" also ranks rows by full-text match: the vector and keyword
+  rankings are fused (keyword_weight sets the keyword share), so exact terms,
+  names and codes that embeddings miss still surface. The top candidates of
+  each ranking are taken from the vector and full-text indexes when present
 - MMR diversity filtering (λ parameter: 0.0=max diversity, 1.0=max relevance)
 - Automatic intelligent chunking with token budgets
 - Smart column weighting (title columns vs content columns)
@@ -133,7 +142,7 @@ To avoid rate limits (30,000 input tokens/minute):
 					},
 					"top_n": map[string]interface{}{
 						"type":        "integer",
-						"description": "Number of rows to retrieve from vector search (default: 10)",
+						"description": "Number of rows to retrieve from vector search (default: 10; capped by the server, with a lower cap when the vector column has no HNSW/IVFFlat index)",
 					},
 					"chunk_size_tokens": map[string]interface{}{
 						"type":        "integer",
@@ -149,7 +158,7 @@ To avoid rate limits (30,000 input tokens/minute):
 					},
 					"distance_metric": map[string]interface{}{
 						"type":        "string",
-						"description": "Distance metric: 'cosine', 'l2', or 'inner_product' (default: 'cosine')",
+						"description": "Distance metric: 'cosine', 'l2', 'inner_product' or 'l1' (default: 'cosine'). 'l1' needs pgvector 0.7.0 or later",
 					},
 					"output_format": map[string]interface{}{
 						"type":        "string",
@@ -157,27 +166,53 @@ To avoid rate limits (30,000 input tokens/minute):
 						"description": "Output format: 'full'=complete chunks (default), 'summary'=titles+snippets only (~50 tokens total, 10x more results), 'ids_only'=just row IDs for progressive disclosure",
 						"default":     "full",
 					},
+					"mode": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{search.SearchModeVector, search.SearchModeHybrid},
+						"description": "Row ranking: 'vector'=vector distance only (default), 'hybrid'=top rows by vector distance fused with top rows by full-text rank of text_columns (each can use its index)",
+						"default":     search.SearchModeVector,
+					},
+					"text_columns": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Hybrid mode: text columns to keyword-match against query_text (default: the text columns paired with the vector columns)",
+					},
+					"keyword_weight": map[string]interface{}{
+						"type":        "number",
+						"description": "Hybrid mode: share of the score given to the keyword rank, 0.0-1.0 (default: server's similarity_search.keyword_weight, 0.3)",
+					},
+					"probes": map[string]interface{}{
+						"type":        "integer",
+						"description": "IVFFlat index lists to search (ivfflat.probes). Higher improves recall but is slower (default: the server's setting, normally 1)",
+						"minimum":     1,
+					},
+					"ef_search": map[string]interface{}{
+						"type":        "integer",
+						"description": "HNSW candidate list size (hnsw.ef_search), 1-1000. Higher improves recall but is slower, and it limits how many rows an HNSW scan can return (default: the server's setting, normally 40)",
+						"minimum":     1,
+						"maximum":     maxEFSearch,
+					},
+					"timeout_seconds": timeoutSecondsProperty(dbClient),
 				},
 				Required: []string{"table_name", "query_text"},
 			},
 		},
 		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
-			// Step 1: Validate and extract parameters
-			tableName, errResp := ValidateStringParam(args, "table_name")
-			if errResp != nil {
-				return *errResp, nil
-			}
-
-			queryText, errResp := ValidateStringParam(args, "query_text")
-			if errResp != nil {
-				return *errResp, nil
-			}
+			// Step 1: Extract parameters (the registry has checked the
+			// required ones against the schema)
+			tableName, _ := args["table_name"].(string)
+			queryText, _ := args["query_text"].(string)
 
 			queryText = strings.TrimSpace(queryText)
 			if queryText == "" {
 				return mcp.NewToolError("query_text cannot be empty")
 			}
 
+			timeout, errResp := resolveQueryTimeout(dbClient, args)
+			if errResp != nil {
+				return *errResp, nil
+			}
+
 			// Get search configuration with defaults
 			searchCfg := search.DefaultSearchConfig()
 			if topN, ok := args["top_n"].(float64); ok {
@@ -192,8 +227,11 @@ To avoid rate limits (30,000 input tokens/minute):
 			if maxTokens, ok := args["max_output_tokens"].(float64); ok {
 				searchCfg.MaxOutputTokens = int(maxTokens)
 			}
-			if metric, ok := args["distance_metric"].(string); ok {
-				searchCfg.DistanceMetric = metric
+			if metric, ok := args["distance_metric"].(string); ok && metric != "" {
+				searchCfg.DistanceMetric = normalizeDistanceMetric(metric)
+				if searchCfg.DistanceMetric == "" {
+					return mcp.NewToolError(fmt.Sprintf("Invalid distance_metric '%s': must be cosine, l2, inner_product or l1", metric))
+				}
 			}
 
 			// Get output format (default: "full")
@@ -202,6 +240,50 @@ To avoid rate limits (30,000 input tokens/minute):
 				outputFormat = format
 			}
 
+			if mode, ok := args["mode"].(string); ok {
+				searchCfg.Mode = mode
+			}
+			if searchCfg.Mode != search.SearchModeVector && searchCfg.Mode != search.SearchModeHybrid {
+				return mcp.NewToolError(fmt.Sprintf("Invalid mode '%s': must be vector or hybrid", searchCfg.Mode))
+			}
+			searchCfg.KeywordWeight = cfg.SimilaritySearch.KeywordWeight
+			if w, ok := args["keyword_weight"].(float64); ok {
+				if w < 0 || w > 1 {
+					return mcp.NewToolError("Parameter 'keyword_weight' must be between 0.0 and 1.0")
+				}
+				searchCfg.KeywordWeight = w
+			}
+			var ann annSearchParams
+			if val, ok := args["probes"]; ok {
+				n, ok := positiveIntArg(val)
+				if !ok {
+					return mcp.NewToolError("Parameter 'probes' must be a positive integer")
+				}
+				ann.probes = n
+			}
+			if val, ok := args["ef_search"]; ok {
+				n, ok := positiveIntArg(val)
+				if !ok || n > maxEFSearch {
+					return mcp.NewToolError(fmt.Sprintf("Parameter 'ef_search' must be an integer from 1 to %d", maxEFSearch))
+				}
+				ann.efSearch = n
+			}
+
+			var keywordCols []string
+			if raw, ok := args["text_columns"]; ok {
+				list, ok := raw.([]interface{})
+				if !ok {
+					return mcp.NewToolError("Parameter 'text_columns' must be an array of column names")
+				}
+				for _, v := range list {
+					s, ok := v.(string)
+					if !ok || s == "" {
+						return mcp.NewToolError("Parameter 'text_columns' must be an array of column names")
+					}
+					keywordCols = append(keywordCols, s)
+				}
+			}
+
 			// Step 2: Get table metadata and discover columns
 			metadataMap := dbClient.GetMetadata()
 			tableInfo, err := findTableInMetadataMap(metadataMap, tableName)
@@ -284,8 +366,45 @@ To avoid rate limits (30,000 input tokens/minute):
 				return mcp.NewToolError(errMsg.String())
 			}
 
+			var hybrid *hybridSearch
+			if searchCfg.Mode == search.SearchModeHybrid {
+				if len(keywordCols) == 0 {
+					keywordCols = textCols
+				}
+				if err := checkKeywordColumns(tableInfo, keywordCols); err != nil {
+					return mcp.NewToolError(fmt.Sprintf("Invalid text_columns: %v", err))
+				}
+				// Hybrid search matches rows between its two rankings by
+				// ctid, which views don't have
+				if tableInfo.TableType == "VIEW" {
+					return mcp.NewToolError(fmt.Sprintf("mode 'hybrid' needs a table or materialized view, and %s is a view. Use mode 'vector', or search the view's underlying table.", tableName))
+				}
+				hybrid = &hybridSearch{
+					queryText:        queryText,
+					textColumns:      keywordCols,
+					keywordWeight:    searchCfg.KeywordWeight,
+					textSearchConfig: cfg.SimilaritySearch.GetTextSearchConfig(),
+				}
+			}
+
+			// Cap top_n so a large request can't trigger an expensive scan,
+			// with a stricter cap when the vector columns have no ANN index
+			indexed, idxErr := hasVectorIndex(requestContext(args), dbClient, tableInfo.SchemaName, tableInfo.TableName, vectorCols)
+			if errResp := noReplicaResponse(idxErr); errResp != nil {
+				return *errResp, nil
+			}
+			if idxErr != nil {
+				// Can't tell; treat as unindexed so the stricter cap applies
+				logging.Warn("similarity_search_index_check_failed", "table", tableName, "error", idxErr.Error())
+			}
+			var capNote string
+			searchCfg.TopN, capNote = capTopN(searchCfg.TopN, cfg.SimilaritySearch.MaxTopN, cfg.SimilaritySearch.MaxTopNUnindexed, indexed)
+
 			// Step 3: Sample data for smart column type detection
-			sampleData, err := sampleTableData(dbClient, tableName, textCols, 3)
+			sampleData, err := sampleTableData(requestContext(args), dbClient, tableName, textCols, 3)
+			if errResp := noReplicaResponse(err); errResp != nil {
+				return *errResp, nil
+			}
 			if err != nil {
 				// Non-fatal: proceed with default weights
 				sampleData = make(map[string]string)
@@ -293,9 +412,15 @@ To avoid rate limits (30,000 input tokens/minute):
 
 			// Detect column types and weights
 			columnWeights := search.DetectColumnTypes(tableInfo, sampleData)
+			searchCols := searchVectorColumns(vectorCols, columnWeights)
+
+			if err := checkDistanceMetricSupport(requestContext(args), dbClient, searchCfg.DistanceMetric, searchCols); err != nil {
+				return mcp.NewToolError(err.Error())
+			}
 
-			// Step 4: Generate query embedding (use the global cfg variable, not the search config)
-			queryEmbedding, err := generateQueryEmbeddingWithConfig(cfg, queryText)
+			// Step 4: Generate the query embedding with each vector column's
+			// model (use the global cfg variable, not the search config)
+			queryEmbeddings, err := generateColumnEmbeddings(requestContext(args), cfg, tableInfo, searchCols, queryText)
 			if err != nil {
 				var errMsg strings.Builder
 				errMsg.WriteString(fmt.Sprintf("Failed to generate query embedding: %v\n\n", err))
@@ -306,6 +431,7 @@ To avoid rate limits (30,000 input tokens/minute):
 				errMsg.WriteString("3. Embedding service (Ollama) is not reachable\n")
 				errMsg.WriteString("4. Network connectivity issues\n")
 				errMsg.WriteString("5. Query text is empty or malformed\n")
+				errMsg.WriteString("6. A vector column was made by a different model (see embedding.table_models)\n")
 				errMsg.WriteString("</diagnosis>\n\n")
 				errMsg.WriteString("<next_steps>\n")
 				errMsg.WriteString("1. Contact server administrator to check embedding configuration\n\n")
@@ -318,16 +444,29 @@ To avoid rate limits (30,000 input tokens/minute):
 			}
 
 			// Step 5: Perform weighted vector search
+			searchCtx, cancel := withQueryTimeout(requestContext(args), timeout)
+			defer cancel()
 			results, err := performWeightedVectorSearch(
+				searchCtx,
+				timeout,
 				dbClient,
 				tableName,
-				vectorCols,
-				textCols,
-				queryEmbedding,
+				searchCols,
+				searchResultColumns(tableInfo, textCols),
+				queryEmbeddings,
 				columnWeights,
 				searchCfg.TopN,
 				searchCfg.DistanceMetric,
+				hybrid,
+				ann,
 			)
+			if isQueryTimeout(searchCtx, err) {
+				logging.Warn("similarity_search_timeout", "table", tableName, "timeout", timeout.String())
+				return mcp.NewToolError(queryTimeoutMessage(timeout))
+			}
+			if errResp := noReplicaResponse(err); errResp != nil {
+				return *errResp, nil
+			}
 			if err != nil {
 				var errMsg strings.Builder
 				errMsg.WriteString(fmt.Sprintf("Vector search failed: %v\n\n", err))
@@ -376,6 +515,8 @@ To avoid rate limits (30,000 input tokens/minute):
 				return mcp.NewToolSuccess(msg.String())
 			}
 
+			redactSearchResults(results, dbClient.Redactor(), tableInfo.SchemaName, tableInfo.TableName)
+
 			// Step 6: Chunk all results
 			allChunks := chunkResults(results, textCols, tableName, searchCfg.ChunkSizeTokens, searchCfg.OverlapTokens)
 
@@ -429,6 +570,9 @@ To avoid rate limits (30,000 input tokens/minute):
 			connStr := dbClient.GetDefaultConnection()
 			sanitizedConn := database.SanitizeConnStr(connStr)
 			result := fmt.Sprintf("Database: %s\nTable: %s\n\n%s", sanitizedConn, tableName, output)
+			if capNote != "" {
+				result = fmt.Sprintf("Database: %s\nTable: %s\n\n%s\n\n%s", sanitizedConn, tableName, capNote, output)
+			}
 
 			// Log execution metrics
 			totalTokens := 0
@@ -444,7 +588,11 @@ To avoid rate limits (30,000 input tokens/minute):
 				"total_tokens", totalTokens,
 				"token_budget", searchCfg.MaxOutputTokens,
 				"top_n", searchCfg.TopN,
+				"top_n_capped", capNote != "",
 				"lambda", searchCfg.Lambda,
+				"mode", searchCfg.Mode,
+				"probes", ann.probes,
+				"ef_search", ann.efSearch,
 			)
 
 			return mcp.NewToolSuccess(result)
@@ -539,6 +687,32 @@ func inferTextColumnName(vectorColName string) string {
 	return strings.TrimSuffix(name, "_")
 }
 
+// checkKeywordColumns reports an error unless every column exists in the
+// table and has a text type
+func checkKeywordColumns(tableInfo database.TableInfo, columns []string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("no text columns to match")
+	}
+	for _, name := range columns {
+		found := false
+		for i := range tableInfo.Columns {
+			col := &tableInfo.Columns[i]
+			if col.ColumnName != name {
+				continue
+			}
+			if !isTextDataType(col.DataType) {
+				return fmt.Errorf("column '%s' is %s, not a text column", name, col.DataType)
+			}
+			found = true
+			break
+		}
+		if !found {
+			return fmt.Errorf("column '%s' not found in table %s.%s", name, tableInfo.SchemaName, tableInfo.TableName)
+		}
+	}
+	return nil
+}
+
 func isTextDataType(dataType string) bool {
 	textTypes := []string{"text", "character varying", "varchar", "character", "char"}
 	lowerType := strings.ToLower(dataType)
@@ -550,7 +724,7 @@ func isTextDataType(dataType string) bool {
 	return false
 }
 
-func sampleTableData(dbClient *database.Client, tableName string, textCols []string, sampleSize int) (map[string]string, error) {
+func sampleTableData(ctx context.Context, dbClient *database.Client, tableName string, textCols []string, sampleSize int) (map[string]string, error) {
 	if len(textCols) == 0 {
 		return make(map[string]string), nil
 	}
@@ -561,41 +735,45 @@ func sampleTableData(dbClient *database.Client, tableName string, textCols []str
 		return nil, fmt.Errorf("no connection pool available")
 	}
 
-	ctx := context.Background()
-
 	// Build query to sample data
 	colList := strings.Join(textCols, ", ")
 	query := fmt.Sprintf("SELECT %s FROM %s LIMIT %d", colList, tableName, sampleSize)
 
-	rows, err := pool.Query(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
 	sampleData := make(map[string]string)
 	count := 0
 
-	for rows.Next() {
-		values, err := rows.Values()
+	err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query)
 		if err != nil {
-			continue
+			return err
 		}
+		defer rows.Close()
+
+		for rows.Next() {
+			values, err := rows.Values()
+			if err != nil {
+				continue
+			}
 
-		for i, col := range textCols {
-			if i < len(values) {
-				if str, ok := values[i].(string); ok {
-					// Accumulate sample text
-					existing := sampleData[col]
-					if existing == "" {
-						sampleData[col] = str
-					} else {
-						sampleData[col] = existing + " " + str
+			for i, col := range textCols {
+				if i < len(values) {
+					if str, ok := values[i].(string); ok {
+						// Accumulate sample text
+						existing := sampleData[col]
+						if existing == "" {
+							sampleData[col] = str
+						} else {
+							sampleData[col] = existing + " " + str
+						}
 					}
 				}
 			}
+			count++
 		}
-		count++
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate average lengths
@@ -608,14 +786,67 @@ func sampleTableData(dbClient *database.Client, tableName string, textCols []str
 	return sampleData, nil
 }
 
-func generateQueryEmbeddingWithConfig(serverCfg *config.Config, queryText string) ([]float64, error) {
+// searchVectorColumns returns the vector columns the weighted distance is
+// computed over: those with a weight, or all of them when none has one
+func searchVectorColumns(vectorCols []database.ColumnInfo, columnWeights []search.ColumnWeight) []database.ColumnInfo {
+	if len(columnWeights) == 0 {
+		return vectorCols
+	}
+
+	weighted := make(map[string]bool, len(columnWeights))
+	for _, weight := range columnWeights {
+		weighted[weight.VectorName] = true
+	}
+	var cols []database.ColumnInfo
+	for i := range vectorCols {
+		if weighted[vectorCols[i].ColumnName] {
+			cols = append(cols, vectorCols[i])
+		}
+	}
+	return cols
+}
+
+// generateColumnEmbeddings embeds the query text once for each distinct
+// model among the vector columns, using embedding.table_models to find a
+// column's model, and returns the embedding to use for each column
+func generateColumnEmbeddings(ctx context.Context, serverCfg *config.Config, tableInfo database.TableInfo, vectorCols []database.ColumnInfo, queryText string) (map[string][]float64, error) {
 	if !serverCfg.Embedding.Enabled {
 		return nil, fmt.Errorf("embedding generation is not enabled in server configuration")
 	}
 
+	byModel := make(map[config.EmbeddingModelConfig][]float64)
+	embeddings := make(map[string][]float64, len(vectorCols))
+	for i := range vectorCols {
+		col := &vectorCols[i]
+		model := serverCfg.Embedding.ModelFor(tableInfo.SchemaName + "." + tableInfo.TableName + "." + col.ColumnName)
+
+		vector, ok := byModel[model]
+		if !ok {
+			var err error
+			vector, err = generateQueryEmbeddingWithConfig(ctx, serverCfg, model, queryText)
+			if err != nil {
+				return nil, err
+			}
+			byModel[model] = vector
+		}
+
+		if col.VectorDimensions > 0 && len(vector) != col.VectorDimensions {
+			return nil, fmt.Errorf("%s model %q returned %d dimensions but column %s.%s.%s has %d",
+				model.Provider, model.Model, len(vector),
+				tableInfo.SchemaName, tableInfo.TableName, col.ColumnName, col.VectorDimensions)
+		}
+		embeddings[col.ColumnName] = vector
+	}
+
+	return embeddings, nil
+}
+
+// generateQueryEmbeddingWithConfig embeds the query text with the given
+// provider and model, using the server's embedding credentials
+func generateQueryEmbeddingWithConfig(ctx context.Context, serverCfg *config.Config, model config.EmbeddingModelConfig, queryText string) ([]float64, error) {
 	embCfg := embedding.Config{
-		Provider:     serverCfg.Embedding.Provider,
-		Model:        serverCfg.Embedding.Model,
+		Provider:     model.Provider,
+		Model:        model.Model,
 		VoyageAPIKey: serverCfg.Embedding.VoyageAPIKey,
 		OpenAIAPIKey: serverCfg.Embedding.OpenAIAPIKey,
 		OllamaURL:    serverCfg.Embedding.OllamaURL,
@@ -626,7 +857,6 @@ func generateQueryEmbeddingWithConfig(serverCfg *config.Config, queryText string
 		return nil, err
 	}
 
-	ctx := context.Background()
 	vector, err := provider.Embed(ctx, queryText)
 	if err != nil {
 		return nil, err
@@ -639,15 +869,137 @@ func generateQueryEmbeddingWithConfig(serverCfg *config.Config, queryText string
 	return vector, nil
 }
 
+// hasVectorIndex reports whether every vector column used by the search has
+// an HNSW or IVFFlat index. The table is looked up by its schema and name,
+// so names that need quoting are found.
+func hasVectorIndex(ctx context.Context, dbClient *database.Client, schemaName, tableName string, vectorCols []database.ColumnInfo) (bool, error) {
+	pool := dbClient.GetPoolFor(dbClient.GetDefaultConnection())
+	if pool == nil {
+		return false, fmt.Errorf("no connection pool available")
+	}
+
+	colNames := make([]string, len(vectorCols))
+	for i, col := range vectorCols {
+		colNames[i] = col.ColumnName
+	}
+
+	var indexedCount int
+	err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, `
+			SELECT count(DISTINCT a.attname)
+			FROM pg_index i
+			JOIN pg_class t ON t.oid = i.indrelid
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			JOIN pg_class ic ON ic.oid = i.indexrelid
+			JOIN pg_am am ON am.oid = ic.relam
+			JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+			WHERE n.nspname = $1
+			  AND t.relname = $2
+			  AND a.attname = ANY($3)
+			  AND am.amname IN ('hnsw', 'ivfflat')`, schemaName, tableName, colNames).Scan(&indexedCount)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return indexedCount == len(colNames), nil
+}
+
+// checkDistanceMetricSupport returns an error if the metric can't be used
+// on the search columns. pgvector added L1 distance (<+>) in 0.7.0 together
+// with halfvec, so halfvec columns support every metric, while vector
+// columns need the extension to be at least that version for l1.
+func checkDistanceMetricSupport(ctx context.Context, dbClient *database.Client, metric string, vectorCols []database.ColumnInfo) error {
+	if metric != "l1" {
+		return nil
+	}
+
+	var vectorTyped []string
+	for i := range vectorCols {
+		if vectorCastType(vectorCols[i].DataType) == "vector" {
+			vectorTyped = append(vectorTyped, vectorCols[i].ColumnName)
+		}
+	}
+	if len(vectorTyped) == 0 {
+		return nil
+	}
+
+	pool := dbClient.GetPoolFor(dbClient.GetDefaultConnection())
+	if pool == nil {
+		return fmt.Errorf("no connection pool available")
+	}
+	var version string
+	err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, "SELECT extversion FROM pg_extension WHERE extname = 'vector'").Scan(&version)
+	})
+	if err != nil {
+		// Let the search itself report what's wrong
+		logging.Warn("similarity_search_pgvector_version_failed", "error", err.Error())
+		return nil
+	}
+	if !pgvectorVersionAtLeast(version, 0, 7) {
+		return fmt.Errorf("distance_metric 'l1' is not supported for vector column(s) %s: pgvector %s is installed "+
+			"and L1 distance needs 0.7.0 or later. Use cosine, l2 or inner_product, or upgrade with ALTER EXTENSION vector UPDATE",
+			strings.Join(vectorTyped, ", "), version)
+	}
+	return nil
+}
+
+// pgvectorVersionAtLeast reports whether an extension version such as
+// "0.6.2" is at least major.minor. Unparseable versions are assumed recent.
+func pgvectorVersionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return true
+	}
+	gotMajor, err1 := strconv.Atoi(parts[0])
+	gotMinor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return true
+	}
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
+
+// capTopN limits the requested top_n to the configured maximum, or to the
+// stricter unindexed maximum when the vector columns have no ANN index.
+// Returns the effective value and a note for the response (empty if no cap
+// or warning applies). Non-positive limits are treated as unlimited.
+func capTopN(requested, maxTopN, maxUnindexed int, indexed bool) (int, string) {
+	limit := maxTopN
+	reason := fmt.Sprintf("server max_top_n is %d", maxTopN)
+	if !indexed && maxUnindexed > 0 && (limit <= 0 || maxUnindexed < limit) {
+		limit = maxUnindexed
+		reason = fmt.Sprintf("no HNSW/IVFFlat index on the vector column(s), so max_top_n_unindexed (%d) applies", maxUnindexed)
+	}
+
+	var notes []string
+	if limit > 0 && requested > limit {
+		notes = append(notes, fmt.Sprintf("Note: top_n reduced from %d to %d (%s).", requested, limit, reason))
+		requested = limit
+	}
+	if !indexed {
+		notes = append(notes, "Warning: the vector column(s) have no HNSW/IVFFlat index, so every search computes "+
+			"the distance to every row. Use check_vector_indexes to get the CREATE INDEX statement.")
+	}
+
+	return requested, strings.Join(notes, "\n")
+}
+
+// performWeightedVectorSearch runs the weighted distance query in a read-only
+// transaction, cancelling it on the server once timeout elapses
 func performWeightedVectorSearch(
+	ctx context.Context,
+	timeout time.Duration,
 	dbClient *database.Client,
 	tableName string,
 	vectorCols []database.ColumnInfo,
-	textCols []string,
-	queryEmbedding []float64,
+	resultCols []string,
+	queryEmbeddings map[string][]float64,
 	columnWeights []search.ColumnWeight,
 	topN int,
 	distanceMetric string,
+	hybrid *hybridSearch,
+	ann annSearchParams,
 ) ([]search.VectorSearchResult, error) {
 
 	connStr := dbClient.GetDefaultConnection()
@@ -656,89 +1008,311 @@ func performWeightedVectorSearch(
 		return nil, fmt.Errorf("no connection pool available")
 	}
 
-	ctx := context.Background()
+	query, queryArgs, weightMap := buildVectorSearchQuery(tableName, vectorCols, resultCols,
+		queryEmbeddings, columnWeights, topN, distanceMetric, hybrid)
+
+	var results []search.VectorSearchResult
+
+	err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+		if err := setStatementTimeout(ctx, tx, timeout); err != nil {
+			return err
+		}
+		if err := setANNSearchParams(ctx, tx, ann); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, query, queryArgs...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		fieldDescs := rows.FieldDescriptions()
+		columnNames := make([]string, len(fieldDescs))
+		for i, fd := range fieldDescs {
+			columnNames[i] = string(fd.Name)
+		}
+
+		for rows.Next() {
+			values, err := rows.Values()
+			if err != nil {
+				continue
+			}
+
+			rowData := make(map[string]interface{})
+			var distance, score float64
+
+			for i, colName := range columnNames {
+				if i < len(values) {
+					switch colName {
+					case "weighted_distance":
+						if dist, ok := values[i].(float64); ok {
+							distance = dist
+						}
+					case "hybrid_score":
+						if v, ok := values[i].(float64); ok {
+							score = v
+						}
+					default:
+						rowData[colName] = values[i]
+					}
+				}
+			}
+
+			result := search.VectorSearchResult{
+				RowData:       rowData,
+				Distance:      distance,
+				Score:         score,
+				VectorWeights: weightMap,
+			}
+			results = append(results, result)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// redactSearchResults replaces the values of columns matching
+// redact_columns in the search results, before they are chunked
+func redactSearchResults(results []search.VectorSearchResult, redactor *database.ColumnRedactor, schemaName, tableName string) {
+	if redactor == nil {
+		return
+	}
+	for _, result := range results {
+		for col := range result.RowData {
+			if redactor.Matches(schemaName, tableName, col) {
+				result.RowData[col] = database.RedactedValue
+			}
+		}
+	}
+}
+
+// maxEFSearch is the largest hnsw.ef_search pgvector accepts
+const maxEFSearch = 1000
 
-	// Build SQL query with weighted distance
+// annSearchParams holds per-search pgvector index settings; zero leaves
+// the server's setting in place
+type annSearchParams struct {
+	probes   int // ivfflat.probes
+	efSearch int // hnsw.ef_search
+}
+
+// setANNSearchParams applies the index settings for the rest of the
+// transaction. The values are validated integers, so they are formatted
+// into the statement as SET doesn't take parameters.
+func setANNSearchParams(ctx context.Context, tx pgx.Tx, ann annSearchParams) error {
+	if ann.probes > 0 {
+		if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL ivfflat.probes = %d", ann.probes)); err != nil {
+			return fmt.Errorf("failed to set ivfflat.probes: %w", err)
+		}
+	}
+	if ann.efSearch > 0 {
+		if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", ann.efSearch)); err != nil {
+			return fmt.Errorf("failed to set hnsw.ef_search: %w", err)
+		}
+	}
+	return nil
+}
+
+// positiveIntArg returns a JSON number argument as an int if it is a whole
+// number of at least 1
+func positiveIntArg(val interface{}) (int, bool) {
+	f, ok := val.(float64)
+	if !ok || f < 1 || f != math.Trunc(f) || f > math.MaxInt32 {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// hybridSearch holds the keyword half of a hybrid search
+type hybridSearch struct {
+	queryText        string
+	textColumns      []string // columns matched against the query text
+	keywordWeight    float64  // 0 ranks by vector distance only, 1 by keyword rank only
+	textSearchConfig string   // text search configuration name, validated with the configuration
+}
+
+// rrfK is the reciprocal rank fusion constant; larger values flatten the
+// difference between the top ranks of each list
+const rrfK = 60
+
+// hybridCandidateFactor sets how many candidates hybrid search takes from
+// each of the vector and keyword rankings, as a multiple of top_n, so rows
+// ranked well by both lists can rise above rows only one list found
+const hybridCandidateFactor = 2
+
+// searchResultColumns returns the columns a search reads back: the
+// primary key and any id column, which identify the rows, followed by the
+// text columns that are chunked. Vector and other columns are left out.
+func searchResultColumns(tableInfo database.TableInfo, textCols []string) []string {
+	var cols []string
+	seen := make(map[string]bool)
+	add := func(col string) {
+		if !seen[col] {
+			seen[col] = true
+			cols = append(cols, col)
+		}
+	}
+	for i := range tableInfo.Columns {
+		if tableInfo.Columns[i].IsPrimaryKey || tableInfo.Columns[i].ColumnName == "id" {
+			add(tableInfo.Columns[i].ColumnName)
+		}
+	}
+	for _, col := range textCols {
+		add(col)
+	}
+	return cols
+}
+
+// buildVectorSearchQuery builds the weighted distance query and its
+// arguments, returning the weight used for each vector column. Each
+// column's query embedding is bound as its own parameter, cast to the
+// column's type (vector or halfvec), since columns may come from different
+// embedding models. With a single vector column, rows are ordered by the
+// bare distance operator so an HNSW or IVFFlat index can serve the search.
+//
+// With hybrid set, the top candidates of the distance ranking and of the
+// full-text ranking of the text columns are taken separately, each able to
+// use its own index, and merged with reciprocal rank fusion into
+// hybrid_score. Only resultCols and the scores are read back.
+func buildVectorSearchQuery(
+	tableName string,
+	vectorCols []database.ColumnInfo,
+	resultCols []string,
+	queryEmbeddings map[string][]float64,
+	columnWeights []search.ColumnWeight,
+	topN int,
+	distanceMetric string,
+	hybrid *hybridSearch,
+) (string, []interface{}, map[string]float64) {
 	distOp := getDistanceOperator(distanceMetric)
 
 	// Build column list
-	allCols := append([]string{"*"}, textCols...)
-	colList := strings.Join(allCols, ", ")
+	quotedCols := make([]string, len(resultCols))
+	for i, col := range resultCols {
+		quotedCols[i] = pgx.Identifier{col}.Sanitize()
+	}
+	colList := strings.Join(quotedCols, ", ")
 
 	// Build weighted distance calculation
-	var weightedParts []string
+	var distanceParts, weightedParts []string
 	weightMap := make(map[string]float64)
 
+	castTypes := make(map[string]string, len(vectorCols))
+	params := make(map[string]int, len(vectorCols))
+	var queryArgs []interface{}
+	for i := range vectorCols {
+		name := vectorCols[i].ColumnName
+		castTypes[name] = vectorCastType(vectorCols[i].DataType)
+		queryArgs = append(queryArgs, formatEmbeddingForPostgres(queryEmbeddings[name]))
+		params[name] = len(queryArgs)
+	}
+
+	addPart := func(name string, weight float64) {
+		distance := fmt.Sprintf("%s %s $%d::%s", pgx.Identifier{name}.Sanitize(), distOp, params[name], castTypes[name])
+		distanceParts = append(distanceParts, distance)
+		weightedParts = append(weightedParts, fmt.Sprintf("(%s) * %f", distance, weight))
+		weightMap[name] = weight
+	}
 	for _, weight := range columnWeights {
-		weightedParts = append(weightedParts, fmt.Sprintf("(%s %s $1::vector) * %f", weight.VectorName, distOp, weight.Weight))
-		weightMap[weight.VectorName] = weight.Weight
+		addPart(weight.VectorName, weight.Weight)
 	}
 
 	// If no weights, use equal weighting
 	if len(weightedParts) == 0 {
 		for i := range vectorCols {
-			weight := 1.0 / float64(len(vectorCols))
-			weightedParts = append(weightedParts, fmt.Sprintf("(%s %s $1::vector) * %f", vectorCols[i].ColumnName, distOp, weight))
-			weightMap[vectorCols[i].ColumnName] = weight
+			addPart(vectorCols[i].ColumnName, 1.0/float64(len(vectorCols)))
 		}
 	}
 
 	weightedDistance := strings.Join(weightedParts, " + ")
 
-	query := fmt.Sprintf(`
-        SELECT %s, (%s) as weighted_distance
-        FROM %s
-        ORDER BY weighted_distance
-        LIMIT $2
-    `, colList, weightedDistance, tableName)
-
-	// Convert embedding to PostgreSQL array format
-	embeddingStr := formatEmbeddingForPostgres(queryEmbedding)
-
-	rows, err := pool.Query(ctx, query, embeddingStr, topN)
-	if err != nil {
-		return nil, err
+	// Scaling a single distance doesn't change the order, and pgvector
+	// indexes only serve ORDER BY on the operator itself
+	orderBy := "weighted_distance"
+	if len(distanceParts) == 1 {
+		orderBy = distanceParts[0]
 	}
-	defer rows.Close()
-
-	var results []search.VectorSearchResult
 
-	fieldDescs := rows.FieldDescriptions()
-	columnNames := make([]string, len(fieldDescs))
-	for i, fd := range fieldDescs {
-		columnNames[i] = string(fd.Name)
+	if hybrid == nil {
+		queryArgs = append(queryArgs, topN)
+		query := fmt.Sprintf(`
+        SELECT %s, (%s) AS weighted_distance
+        FROM %s
+        ORDER BY %s
+        LIMIT $%d
+    `, colList, weightedDistance, tableName, orderBy, len(queryArgs))
+		return query, queryArgs, weightMap
 	}
 
-	for rows.Next() {
-		values, err := rows.Values()
-		if err != nil {
-			continue
-		}
-
-		rowData := make(map[string]interface{})
-		var distance float64
+	textParts := make([]string, len(hybrid.textColumns))
+	for i, col := range hybrid.textColumns {
+		textParts[i] = fmt.Sprintf("coalesce(%s, '')", pgx.Identifier{col}.Sanitize())
+	}
+	document := fmt.Sprintf("to_tsvector('%s'::regconfig, %s)", hybrid.textSearchConfig, strings.Join(textParts, " || ' ' || "))
+
+	queryArgs = append(queryArgs, hybrid.queryText)
+	textParam := len(queryArgs)
+	queryArgs = append(queryArgs, hybrid.keywordWeight)
+	weightParam := len(queryArgs)
+	queryArgs = append(queryArgs, topN*hybridCandidateFactor)
+	candidatesParam := len(queryArgs)
+	queryArgs = append(queryArgs, topN)
+
+	tsQuery := fmt.Sprintf("plainto_tsquery('%s'::regconfig, $%d)", hybrid.textSearchConfig, textParam)
+	qualifiedCols := make([]string, len(quotedCols))
+	for i, col := range quotedCols {
+		qualifiedCols[i] = "src." + col
+	}
 
-		for i, colName := range columnNames {
-			if i < len(values) {
-				if colName == "weighted_distance" {
-					if dist, ok := values[i].(float64); ok {
-						distance = dist
-					}
-				} else {
-					rowData[colName] = values[i]
-				}
-			}
-		}
+	// Rows are matched between the two lists by tableoid and ctid, which
+	// identify a row within one statement even in a partitioned table
+	query := fmt.Sprintf(`
+        WITH vector_hits AS (
+            SELECT tableoid AS hit_rel, ctid AS hit_row, (%[1]s) AS weighted_distance
+            FROM %[2]s
+            ORDER BY %[3]s
+            LIMIT $%[4]d
+        ), keyword_hits AS (
+            SELECT tableoid AS hit_rel, ctid AS hit_row, ts_rank(%[5]s, %[6]s) AS keyword_score
+            FROM %[2]s
+            WHERE %[5]s @@ %[6]s
+            ORDER BY keyword_score DESC
+            LIMIT $%[4]d
+        ), fused AS (
+            SELECT coalesce(v.hit_rel, k.hit_rel) AS hit_rel,
+                coalesce(v.hit_row, k.hit_row) AS hit_row,
+                coalesce((1 - $%[7]d::float8) / (%[8]d + v.vector_rank), 0)
+                    + coalesce($%[7]d::float8 / (%[8]d + k.keyword_rank), 0) AS fused_score
+            FROM (SELECT hit_rel, hit_row, rank() OVER (ORDER BY weighted_distance) AS vector_rank
+                  FROM vector_hits) v
+            FULL JOIN (SELECT hit_rel, hit_row, rank() OVER (ORDER BY keyword_score DESC) AS keyword_rank
+                       FROM keyword_hits) k
+                ON k.hit_rel = v.hit_rel AND k.hit_row = v.hit_row
+        )
+        SELECT %[9]s, (%[1]s) AS weighted_distance, f.fused_score AS hybrid_score
+        FROM fused f
+        JOIN %[2]s src ON src.tableoid = f.hit_rel AND src.ctid = f.hit_row
+        ORDER BY f.fused_score DESC
+        LIMIT $%[10]d
+    `, weightedDistance, tableName, orderBy, candidatesParam, document, tsQuery,
+		weightParam, rrfK, strings.Join(qualifiedCols, ", "), len(queryArgs))
+	return query, queryArgs, weightMap
+}
 
-		result := search.VectorSearchResult{
-			RowData:       rowData,
-			Distance:      distance,
-			VectorWeights: weightMap,
-		}
-		results = append(results, result)
+// vectorCastType returns the type to cast the query embedding to for a
+// vector column with the given format_type output: halfvec for halfvec
+// columns, otherwise vector
+func vectorCastType(dataType string) string {
+	if strings.HasPrefix(dataType, "halfvec") || strings.Contains(dataType, ".halfvec") {
+		return "halfvec"
 	}
-
-	return results, nil
+	return "vector"
 }
 
 func getDistanceOperator(metric string) string {
@@ -747,15 +1321,22 @@ func getDistanceOperator(metric string) string {
 		return "<->"
 	case "inner_product", "inner":
 		return "<#>"
+	case "l1", "manhattan", "taxicab":
+		return "<+>"
 	default: // cosine
 		return "<=>"
 	}
 }
 
+// formatEmbeddingForPostgres renders an embedding in pgvector's text input
+// format, for binding as a query parameter. pgvector stores single
+// precision floats, so each value is written as the shortest string that
+// reads back as the same float32; a fixed number of decimals would round
+// small components to zero.
 func formatEmbeddingForPostgres(embedding []float64) string {
 	parts := make([]string, len(embedding))
 	for i, val := range embedding {
-		parts[i] = fmt.Sprintf("%f", val)
+		parts[i] = strconv.FormatFloat(val, 'g', -1, 32)
 	}
 	return "[" + strings.Join(parts, ",") + "]"
 }
@@ -810,6 +1391,9 @@ func formatSearchResults(
 	sb.WriteString(fmt.Sprintf("  - Chunking: %d tokens per chunk, %d token overlap\n", cfg.ChunkSizeTokens, cfg.OverlapTokens))
 	sb.WriteString(fmt.Sprintf("  - Diversity: λ=%.2f (%.0f%% relevance, %.0f%% diversity)\n", cfg.Lambda, cfg.Lambda*100, (1-cfg.Lambda)*100))
 	sb.WriteString(fmt.Sprintf("  - Distance Metric: %s\n", cfg.DistanceMetric))
+	if cfg.Mode == search.SearchModeHybrid {
+		sb.WriteString(fmt.Sprintf("  - Hybrid Ranking: %.0f%% vector, %.0f%% keyword\n", (1-cfg.KeywordWeight)*100, cfg.KeywordWeight*100))
+	}
 
 	// Show column weights
 	if len(columnWeights) > 0 {
@@ -903,7 +1487,11 @@ func formatSearchResultsIDsOnly(
 			rowID = id
 		}
 
-		sb.WriteString(fmt.Sprintf("%d. ID: %v | Distance: %.4f\n", i+1, rowID, result.Distance))
+		if cfg.Mode == search.SearchModeHybrid {
+			sb.WriteString(fmt.Sprintf("%d. ID: %v | Distance: %.4f | Hybrid Score: %.5f\n", i+1, rowID, result.Distance, result.Score))
+		} else {
+			sb.WriteString(fmt.Sprintf("%d. ID: %v | Distance: %.4f\n", i+1, rowID, result.Distance))
+		}
 	}
 
 	sb.WriteString("\n")
@@ -11,9 +11,17 @@
 package tools
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"pgedge-postgres-mcp/internal/config"
 	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/search"
 )
 
 func TestInferTextColumnName(t *testing.T) {
@@ -124,6 +132,8 @@ func TestGetDistanceOperator(t *testing.T) {
 		{"euclidean", "euclidean", "<->"},
 		{"inner_product", "inner_product", "<#>"},
 		{"inner", "inner", "<#>"},
+		{"l1", "l1", "<+>"},
+		{"manhattan", "manhattan", "<+>"},
 		{"empty defaults to cosine", "", "<=>"},
 		{"unknown defaults to cosine", "unknown", "<=>"},
 		{"uppercase L2", "L2", "<->"},
@@ -140,6 +150,20 @@ func TestGetDistanceOperator(t *testing.T) {
 	}
 }
 
+func TestVectorCastType(t *testing.T) {
+	tests := map[string]string{
+		"vector(1536)":            "vector",
+		"halfvec(768)":            "halfvec",
+		"extensions.halfvec(384)": "halfvec",
+		"vector":                  "vector",
+	}
+	for dataType, want := range tests {
+		if got := vectorCastType(dataType); got != want {
+			t.Errorf("vectorCastType(%q) = %q, want %q", dataType, got, want)
+		}
+	}
+}
+
 func TestFormatEmbeddingForPostgres(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -149,7 +173,7 @@ func TestFormatEmbeddingForPostgres(t *testing.T) {
 		{
 			name:      "simple embedding",
 			embedding: []float64{1.0, 2.0, 3.0},
-			want:      "[1.000000,2.000000,3.000000]",
+			want:      "[1,2,3]",
 		},
 		{
 			name:      "empty embedding",
@@ -159,17 +183,22 @@ func TestFormatEmbeddingForPostgres(t *testing.T) {
 		{
 			name:      "single value",
 			embedding: []float64{0.5},
-			want:      "[0.500000]",
+			want:      "[0.5]",
 		},
 		{
 			name:      "negative values",
 			embedding: []float64{-1.0, 0.0, 1.0},
-			want:      "[-1.000000,0.000000,1.000000]",
+			want:      "[-1,0,1]",
 		},
 		{
 			name:      "small values",
 			embedding: []float64{0.001, 0.002, 0.003},
-			want:      "[0.001000,0.002000,0.003000]",
+			want:      "[0.001,0.002,0.003]",
+		},
+		{
+			name:      "values below six decimals keep their precision",
+			embedding: []float64{1.5e-07, -0.0000042, 0.12345678},
+			want:      "[1.5e-07,-4.2e-06,0.12345678]",
 		},
 	}
 
@@ -383,3 +412,343 @@ func TestFindTableInMetadataMap(t *testing.T) {
 		})
 	}
 }
+
+func TestCapTopN(t *testing.T) {
+	tests := []struct {
+		name         string
+		requested    int
+		maxTopN      int
+		maxUnindexed int
+		indexed      bool
+		want         int
+		wantNote     string
+	}{
+		{"indexed under cap", 10, 100, 20, true, 10, ""},
+		{"indexed over cap", 500, 100, 20, true, 100, "reduced from 500 to 100"},
+		{"unindexed under cap warns", 10, 100, 20, false, 10, "no HNSW/IVFFlat index"},
+		{"unindexed over stricter cap", 50, 100, 20, false, 20, "max_top_n_unindexed (20)"},
+		{"unindexed cap higher than max", 150, 100, 200, false, 100, "reduced from 150 to 100"},
+		{"no limits", 1000, 0, 0, true, 1000, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, note := capTopN(tt.requested, tt.maxTopN, tt.maxUnindexed, tt.indexed)
+			if got != tt.want {
+				t.Errorf("capTopN() = %d, want %d", got, tt.want)
+			}
+			if tt.wantNote == "" && note != "" {
+				t.Errorf("expected no note, got %q", note)
+			}
+			if tt.wantNote != "" && !strings.Contains(note, tt.wantNote) {
+				t.Errorf("expected note to contain %q, got %q", tt.wantNote, note)
+			}
+		})
+	}
+}
+
+func TestHasVectorIndex_NoPool(t *testing.T) {
+	indexed, err := hasVectorIndex(context.Background(), database.NewClient(nil), "public", "docs",
+		[]database.ColumnInfo{{ColumnName: "embedding"}})
+	if err == nil {
+		t.Fatal("expected an error without a connection pool")
+	}
+	if indexed {
+		t.Error("expected a failed check to report the columns as unindexed")
+	}
+}
+
+func TestSearchVectorColumns(t *testing.T) {
+	vectorCols := []database.ColumnInfo{{ColumnName: "title_embedding"}, {ColumnName: "image_embedding"}}
+
+	if got := searchVectorColumns(vectorCols, nil); len(got) != 2 {
+		t.Errorf("without weights: got %d columns, want all 2", len(got))
+	}
+
+	got := searchVectorColumns(vectorCols, []search.ColumnWeight{{VectorName: "title_embedding", Weight: 1}})
+	if len(got) != 1 || got[0].ColumnName != "title_embedding" {
+		t.Errorf("with weights: got %+v, want only title_embedding", got)
+	}
+}
+
+func TestGenerateColumnEmbeddings(t *testing.T) {
+	// Fake Ollama server returning a vector whose size depends on the model
+	calls := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		calls[req.Model]++
+		dims := map[string]int{"small-model": 3, "large-model": 5}[req.Model]
+		vector := make([]string, dims)
+		for i := range vector {
+			vector[i] = "0.1"
+		}
+		fmt.Fprintf(w, `{"embeddings":[[%s]]}`, strings.Join(vector, ","))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Embedding: config.EmbeddingConfig{
+		Enabled:   true,
+		Provider:  "ollama",
+		Model:     "small-model",
+		OllamaURL: server.URL,
+		TableModels: map[string]config.EmbeddingModelConfig{
+			"public.docs.image_embedding": {Provider: "ollama", Model: "large-model"},
+		},
+	}}
+	table := database.TableInfo{SchemaName: "public", TableName: "docs"}
+	cols := []database.ColumnInfo{
+		{ColumnName: "title_embedding", VectorDimensions: 3},
+		{ColumnName: "body_embedding", VectorDimensions: 3},
+		{ColumnName: "image_embedding", VectorDimensions: 5},
+	}
+
+	embeddings, err := generateColumnEmbeddings(context.Background(), cfg, table, cols, "query")
+	if err != nil {
+		t.Fatalf("generateColumnEmbeddings() error: %v", err)
+	}
+	for col, want := range map[string]int{"title_embedding": 3, "body_embedding": 3, "image_embedding": 5} {
+		if len(embeddings[col]) != want {
+			t.Errorf("%s: got %d dimensions, want %d", col, len(embeddings[col]), want)
+		}
+	}
+	if calls["small-model"] != 1 || calls["large-model"] != 1 {
+		t.Errorf("expected one call per model, got %v", calls)
+	}
+
+	// Without the mapping, the default model doesn't fit the column
+	cfg.Embedding.TableModels = nil
+	_, err = generateColumnEmbeddings(context.Background(), cfg, table, cols, "query")
+	if err == nil || !strings.Contains(err.Error(), "returned 3 dimensions but column public.docs.image_embedding has 5") {
+		t.Errorf("expected dimension mismatch error, got %v", err)
+	}
+
+	cfg.Embedding.Enabled = false
+	if _, err := generateColumnEmbeddings(context.Background(), cfg, table, cols, "query"); err == nil {
+		t.Error("expected error with embedding disabled")
+	}
+}
+
+func TestBuildVectorSearchQuery(t *testing.T) {
+	vectorCols := []database.ColumnInfo{
+		{ColumnName: "title_embedding", DataType: "vector(3)"},
+		{ColumnName: "body_embedding", DataType: "halfvec(3)"},
+	}
+	embeddings := map[string][]float64{
+		"title_embedding": {0.1, 0.2, 0.3},
+		"body_embedding":  {0.4, 0.5, 0.6},
+	}
+	weights := []search.ColumnWeight{
+		{ColumnName: "title", VectorName: "title_embedding", Weight: 0.3},
+		{ColumnName: "body", VectorName: "body_embedding", Weight: 0.7},
+	}
+	resultCols := []string{"id", "title", "body"}
+
+	t.Run("vector", func(t *testing.T) {
+		query, args, weightMap := buildVectorSearchQuery("public.docs", vectorCols, resultCols,
+			embeddings, weights, 10, "cosine", nil)
+
+		for _, want := range []string{
+			`SELECT "id", "title", "body",`,
+			`("title_embedding" <=> $1::vector) * 0.300000`,
+			`("body_embedding" <=> $2::halfvec) * 0.700000`,
+			"ORDER BY weighted_distance",
+			"LIMIT $3",
+		} {
+			if !strings.Contains(query, want) {
+				t.Errorf("query missing %q:\n%s", want, query)
+			}
+		}
+		if strings.Contains(query, "*,") || strings.Contains(query, "hybrid_score") {
+			t.Errorf("vector query should read only the result columns:\n%s", query)
+		}
+		if len(args) != 3 || args[2] != 10 {
+			t.Errorf("unexpected args: %v", args)
+		}
+		if weightMap["body_embedding"] != 0.7 {
+			t.Errorf("unexpected weights: %v", weightMap)
+		}
+	})
+
+	t.Run("single column orders by the operator", func(t *testing.T) {
+		query, _, _ := buildVectorSearchQuery("public.docs", vectorCols[:1], resultCols,
+			embeddings, nil, 10, "l2", nil)
+		if !strings.Contains(query, `ORDER BY "title_embedding" <-> $1::vector`) {
+			t.Errorf("expected an index-friendly ORDER BY:\n%s", query)
+		}
+	})
+
+	t.Run("hybrid", func(t *testing.T) {
+		query, args, _ := buildVectorSearchQuery("public.docs", vectorCols, resultCols,
+			embeddings, weights, 10, "cosine", &hybridSearch{
+				queryText:        "error 42P01",
+				textColumns:      []string{"title", "Body Text"},
+				keywordWeight:    0.4,
+				textSearchConfig: "english",
+			})
+
+		for _, want := range []string{
+			`to_tsvector('english'::regconfig, coalesce("title", '') || ' ' || coalesce("Body Text", ''))`,
+			"@@ plainto_tsquery('english'::regconfig, $3)",
+			"ORDER BY weighted_distance\n            LIMIT $5",
+			"ORDER BY keyword_score DESC\n            LIMIT $5",
+			"FULL JOIN",
+			"coalesce((1 - $4::float8) / (60 + v.vector_rank), 0)",
+			"coalesce($4::float8 / (60 + k.keyword_rank), 0)",
+			`SELECT src."id", src."title", src."body",`,
+			"f.fused_score AS hybrid_score",
+			"ORDER BY f.fused_score DESC",
+			"LIMIT $6",
+		} {
+			if !strings.Contains(query, want) {
+				t.Errorf("query missing %q:\n%s", want, query)
+			}
+		}
+		if strings.Contains(query, "SELECT *") {
+			t.Errorf("hybrid query should read only the result columns:\n%s", query)
+		}
+		if len(args) != 6 || args[2] != "error 42P01" || args[3] != 0.4 || args[4] != 20 || args[5] != 10 {
+			t.Errorf("unexpected args: %v", args)
+		}
+	})
+}
+
+func TestSearchResultColumns(t *testing.T) {
+	table := database.TableInfo{
+		Columns: []database.ColumnInfo{
+			{ColumnName: "doc_id", IsPrimaryKey: true},
+			{ColumnName: "title", DataType: "text"},
+			{ColumnName: "body", DataType: "text"},
+			{ColumnName: "body_embedding", DataType: "vector(3)", IsVectorColumn: true},
+		},
+	}
+	got := strings.Join(searchResultColumns(table, []string{"title", "body", "title"}), ",")
+	if got != "doc_id,title,body" {
+		t.Errorf("searchResultColumns() = %q, want doc_id,title,body", got)
+	}
+}
+
+func TestCheckKeywordColumns(t *testing.T) {
+	table := database.TableInfo{
+		SchemaName: "public",
+		TableName:  "docs",
+		Columns: []database.ColumnInfo{
+			{ColumnName: "title", DataType: "text"},
+			{ColumnName: "code", DataType: "character varying(20)"},
+			{ColumnName: "id", DataType: "integer"},
+		},
+	}
+
+	if err := checkKeywordColumns(table, []string{"title", "code"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkKeywordColumns(table, []string{"id"}); err == nil || !strings.Contains(err.Error(), "not a text column") {
+		t.Errorf("expected non-text column error, got %v", err)
+	}
+	if err := checkKeywordColumns(table, []string{"missing"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected missing column error, got %v", err)
+	}
+	if err := checkKeywordColumns(table, nil); err == nil {
+		t.Error("expected error without columns")
+	}
+}
+
+func TestPositiveIntArg(t *testing.T) {
+	tests := []struct {
+		val    interface{}
+		want   int
+		wantOK bool
+	}{
+		{float64(10), 10, true},
+		{float64(1), 1, true},
+		{float64(0), 0, false},
+		{float64(-5), 0, false},
+		{2.5, 0, false},
+		{"10", 0, false},
+		{float64(1 << 40), 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := positiveIntArg(tt.val)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("positiveIntArg(%v) = (%d, %t), want (%d, %t)", tt.val, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSimilaritySearchANNParamValidation(t *testing.T) {
+	tool := SimilaritySearchTool(createMockClient(map[string]database.TableInfo{}), &config.Config{})
+
+	for _, tc := range []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"probes": float64(0)}, "'probes' must be a positive integer"},
+		{map[string]interface{}{"probes": 1.5}, "'probes' must be a positive integer"},
+		{map[string]interface{}{"ef_search": float64(1001)}, "'ef_search' must be an integer from 1 to 1000"},
+		{map[string]interface{}{"ef_search": float64(-1)}, "'ef_search' must be an integer from 1 to 1000"},
+	} {
+		tc.args["table_name"] = "docs"
+		tc.args["query_text"] = "query"
+		response, err := tool.Handler(tc.args)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if !response.IsError || !strings.Contains(response.Content[0].Text, tc.want) {
+			t.Errorf("args %v: expected error containing %q, got %q", tc.args, tc.want, response.Content[0].Text)
+		}
+	}
+}
+
+func TestSimilaritySearchDistanceMetricValidation(t *testing.T) {
+	tool := SimilaritySearchTool(createMockClient(map[string]database.TableInfo{}), &config.Config{})
+
+	response, err := tool.Handler(map[string]interface{}{
+		"table_name":      "docs",
+		"query_text":      "query",
+		"distance_metric": "hamming",
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !response.IsError || !strings.Contains(response.Content[0].Text, "must be cosine, l2, inner_product or l1") {
+		t.Errorf("Expected invalid distance_metric error, got %q", response.Content[0].Text)
+	}
+}
+
+func TestPgvectorVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"0.7.0", true},
+		{"0.8.1", true},
+		{"1.0", true},
+		{"0.6.2", false},
+		{"0.5", false},
+		{"dev", true},
+	}
+
+	for _, tt := range tests {
+		if got := pgvectorVersionAtLeast(tt.version, 0, 7); got != tt.want {
+			t.Errorf("pgvectorVersionAtLeast(%q, 0, 7) = %t, want %t", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestRedactSearchResults(t *testing.T) {
+	results := []search.VectorSearchResult{
+		{RowData: map[string]interface{}{"id": 1, "body": "public text", "author_email": "a@example.com"}},
+	}
+	redactSearchResults(results, database.NewColumnRedactor([]string{"docs.author_*"}), "public", "docs")
+	if results[0].RowData["author_email"] != database.RedactedValue {
+		t.Errorf("Expected author_email to be redacted, got %v", results[0].RowData["author_email"])
+	}
+	if results[0].RowData["body"] != "public text" || results[0].RowData["id"] != 1 {
+		t.Errorf("Expected other columns to be kept, got %v", results[0].RowData)
+	}
+}
@@ -0,0 +1,485 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// minSeqRowsPerScan is the average rows read per sequential scan at or
+	// above which a table is a candidate for an index
+	minSeqRowsPerScan = 1000
+
+	// minIndexCandidateRows skips tables small enough that a sequential
+	// scan is the best plan anyway
+	minIndexCandidateRows = 10000
+
+	// maxStatementsForIndexes is the number of most-called statements read
+	// from pg_stat_statements
+	maxStatementsForIndexes = 500
+
+	// maxIndexKeyColumns caps the columns in a suggested index
+	maxIndexKeyColumns = 3
+
+	// maxSuggestionsPerTable caps the indexes suggested for one table
+	maxSuggestionsPerTable = 2
+)
+
+// predicateRe matches a comparison that starts a WHERE, ON, AND or OR
+// condition: a (possibly qualified) column, the operator and, for joins,
+// the column on the other side
+var predicateRe = regexp.MustCompile(`(?i)(?:\b(?:WHERE|ON|AND|OR)\s+|\(\s*)` +
+	`(?:("[^"]+"|[a-z_][a-z0-9_$]*)\.)?("[^"]+"|[a-z_][a-z0-9_$]*)\s*` +
+	`(<=|>=|<>|!=|=|<|>|NOT\s+IN\b|IN\b|I?LIKE\b|BETWEEN\b|IS\s+NOT\b|IS\b)` +
+	`(?:\s*(?:("[^"]+"|[a-z_][a-z0-9_$]*)\.)?("[^"]+"|[a-z_][a-z0-9_$]*))?`)
+
+// aliasKeywords are words that can follow a table name in FROM or JOIN but
+// are not aliases
+var aliasKeywords = []string{"where", "join", "inner", "left", "right", "full", "cross", "natural",
+	"on", "using", "group", "order", "limit", "offset", "union", "except", "intersect",
+	"set", "returning", "for", "window", "having", "tablesample", "lateral", "fetch"}
+
+// indexCandidateTable is a table read mostly by sequential scans
+type indexCandidateTable struct {
+	schema     string
+	table      string
+	relid      uint32
+	liveTuples int64
+	seqScan    int64
+	idxScan    int64
+	seqTupRead int64
+	size       int64
+}
+
+// indexSuggestion is a candidate index key with the statements behind it
+type indexSuggestion struct {
+	columns    []string
+	calls      int64
+	statements int
+}
+
+// SuggestIndexesTool creates the suggest_indexes tool
+func SuggestIndexesTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "suggest_indexes",
+			Description: `Suggest candidate indexes for tables that are read mostly by sequential scans.
+
+<usecase>
+Use when:
+- Queries are slow and you suspect missing indexes
+- A table shows far more sequential than index scans
+- Reviewing a database's indexing after a workload change
+</usecase>
+
+<what_it_returns>
+- Tables with many large sequential scans relative to index scans
+  (from pg_stat_user_tables), with row counts, scan counts and size
+- When pg_stat_statements is available: CREATE INDEX CONCURRENTLY
+  statements for the columns that the most-called statements filter or
+  join on, with the reasoning (calls and statements behind each)
+</what_it_returns>
+
+<important>
+- These are SUGGESTIONS from statistics and query text heuristics; validate
+  each with execute_explain before creating it, and weigh the extra write
+  and storage cost
+- Columns used inside expressions (e.g. lower(email)) are not detected
+- Without pg_stat_statements only the tables are reported
+- Statistics are cumulative since the last stats reset
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"schema_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Only consider tables in this schema (default: all user schemas)",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of tables to consider, most sequentially read first. Default: 10",
+						"default":     10,
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			schemaName := ValidateOptionalStringParam(args, "schema_name", "")
+
+			limit := 10
+			if val, ok := args["limit"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'limit' must be a positive integer")
+				}
+				limit = int(val)
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			timeout := dbClient.QueryTimeout()
+			ctx, cancel := withQueryTimeout(requestContext(args), timeout)
+			defer cancel()
+
+			var tables []*indexCandidateTable
+			indexes := make(map[uint32][][]string)
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				if err := setStatementTimeout(ctx, tx, timeout); err != nil {
+					return err
+				}
+
+				rows, err := tx.Query(ctx, `
+					SELECT s.schemaname, s.relname, s.relid, s.n_live_tup, s.seq_scan,
+					       COALESCE(s.idx_scan, 0), s.seq_tup_read, pg_table_size(s.relid)
+					FROM pg_stat_user_tables s
+					WHERE ($1 = '' OR s.schemaname = $1)
+					  AND s.n_live_tup >= $2
+					  AND s.seq_scan > COALESCE(s.idx_scan, 0)
+					  AND s.seq_tup_read / GREATEST(s.seq_scan, 1) >= $3
+					ORDER BY s.seq_tup_read DESC
+					LIMIT $4`, schemaName, minIndexCandidateRows, minSeqRowsPerScan, limit)
+				if err != nil {
+					return fmt.Errorf("failed to query table statistics: %w", err)
+				}
+				defer rows.Close()
+
+				var relids []uint32
+				for rows.Next() {
+					var t indexCandidateTable
+					if err := rows.Scan(&t.schema, &t.table, &t.relid, &t.liveTuples, &t.seqScan,
+						&t.idxScan, &t.seqTupRead, &t.size); err != nil {
+						return fmt.Errorf("failed to scan table statistics: %w", err)
+					}
+					tables = append(tables, &t)
+					relids = append(relids, t.relid)
+				}
+				if err := rows.Err(); err != nil {
+					return err
+				}
+				if len(tables) == 0 {
+					return nil
+				}
+
+				// Key columns of existing indexes; expression columns come
+				// back as '' so they never match a suggested column
+				idxRows, err := tx.Query(ctx, `
+					SELECT i.indrelid, array_agg(COALESCE(a.attname, '') ORDER BY k.ord)
+					FROM pg_index i
+					CROSS JOIN LATERAL unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+					LEFT JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+					WHERE i.indrelid = ANY($1::oid[]) AND k.ord <= i.indnkeyatts
+					GROUP BY i.indexrelid, i.indrelid`, relids)
+				if err != nil {
+					return fmt.Errorf("failed to query existing indexes: %w", err)
+				}
+				defer idxRows.Close()
+
+				for idxRows.Next() {
+					var relid uint32
+					var cols []string
+					if err := idxRows.Scan(&relid, &cols); err != nil {
+						return fmt.Errorf("failed to scan index: %w", err)
+					}
+					indexes[relid] = append(indexes[relid], cols)
+				}
+				return idxRows.Err()
+			})
+			if err != nil {
+				if isQueryTimeout(ctx, err) {
+					return mcp.NewToolError(queryTimeoutMessage(timeout))
+				}
+				return mcp.NewToolError(fmt.Sprintf("Error reading table statistics: %v", err))
+			}
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+
+			if len(tables) == 0 {
+				sb.WriteString("No tables are read mostly by large sequential scans")
+				if schemaName != "" {
+					sb.WriteString(fmt.Sprintf(" in schema '%s'", schemaName))
+				}
+				sb.WriteString(fmt.Sprintf(" (at least %d rows, more sequential than index scans, %d+ rows per sequential scan).\n",
+					minIndexCandidateRows, minSeqRowsPerScan))
+				return mcp.NewToolSuccess(sb.String())
+			}
+
+			statements, statementsNote := readStatementsForIndexes(ctx, dbClient, pool)
+
+			var results [][]interface{}
+			for _, t := range tables {
+				results = append(results, []interface{}{
+					t.schema, t.table, t.liveTuples, t.seqScan, t.idxScan, t.seqTupRead,
+					t.seqTupRead / t.seqScan, formatBytes(t.size),
+				})
+			}
+			sb.WriteString("Tables read mostly by sequential scans (since stats reset):\n")
+			sb.WriteString(FormatResultsAsTSV(
+				[]string{"schema", "table", "live_rows", "seq_scan", "idx_scan", "seq_tup_read", "rows_per_seq_scan", "size"},
+				results))
+			sb.WriteString("\n")
+
+			suggested := 0
+			if statements == nil {
+				sb.WriteString(fmt.Sprintf("\n%s\n", statementsNote))
+			} else {
+				metadata := dbClient.GetMetadata()
+				sb.WriteString("\n<suggestions>\n")
+				for _, t := range tables {
+					var columns []string
+					if info, ok := metadata[t.schema+"."+t.table]; ok {
+						for _, c := range info.Columns {
+							columns = append(columns, c.ColumnName)
+						}
+					}
+
+					suggestions := suggestIndexesForTable(t.schema, t.table, columns, indexes[t.relid], statements)
+					qualifiedName := quoteIdentifier(t.schema) + "." + quoteIdentifier(t.table)
+					sb.WriteString(fmt.Sprintf("-- %s.%s: %d sequential scans read %d rows each on average (%d index scans)\n",
+						t.schema, t.table, t.seqScan, t.seqTupRead/t.seqScan, t.idxScan))
+					if len(suggestions) == 0 {
+						sb.WriteString("--   No recorded statement filters or joins on an unindexed column of this table;\n")
+						sb.WriteString("--   check the queries that read it with execute_explain.\n\n")
+						continue
+					}
+					for _, s := range suggestions {
+						quoted := make([]string, len(s.columns))
+						for i, c := range s.columns {
+							quoted[i] = quoteIdentifier(c)
+						}
+						sb.WriteString(fmt.Sprintf("--   %d call(s) across %d statement(s) filter or join on (%s)\n",
+							s.calls, s.statements, strings.Join(s.columns, ", ")))
+						sb.WriteString(fmt.Sprintf("CREATE INDEX CONCURRENTLY ON %s (%s);\n", qualifiedName, strings.Join(quoted, ", ")))
+						suggested++
+					}
+					sb.WriteString("\n")
+				}
+				sb.WriteString("</suggestions>\n")
+			}
+
+			sb.WriteString("\n<important>\n")
+			sb.WriteString("These are suggestions, not verified improvements. Before creating an index:\n")
+			sb.WriteString("- Run the affected queries with execute_explain and confirm a sequential scan is the problem\n")
+			sb.WriteString("- Put equality columns first and range columns last; an existing index may already serve the query\n")
+			sb.WriteString("- Every index slows down writes and takes space; drop indexes that end up unused (see index_efficiency)\n")
+			sb.WriteString("</important>\n")
+
+			logging.Info("suggest_indexes_executed",
+				"schema", schemaName,
+				"tables", len(tables),
+				"statements", len(statements),
+				"suggestions", suggested,
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// statementForIndexes is a statement text and how often it was called
+type statementForIndexes struct {
+	query string
+	calls int64
+}
+
+// readStatementsForIndexes returns the most-called statements of the current
+// database from pg_stat_statements, or nil and the reason they are missing
+func readStatementsForIndexes(ctx context.Context, dbClient *database.Client, pool *pgxpool.Pool) ([]statementForIndexes, string) {
+	var statements []statementForIndexes
+	unavailable := false
+	err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+		var readable bool
+		if err := tx.QueryRow(ctx, `SELECT COALESCE(has_table_privilege(to_regclass('pg_stat_statements'), 'SELECT'), false)`).
+			Scan(&readable); err != nil {
+			return err
+		}
+		if !readable {
+			unavailable = true
+			return nil
+		}
+
+		rows, err := tx.Query(ctx, `
+			SELECT query, calls
+			FROM pg_stat_statements
+			WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+			  AND query IS NOT NULL
+			ORDER BY calls DESC
+			LIMIT $1`, maxStatementsForIndexes)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var s statementForIndexes
+			if err := rows.Scan(&s.query, &s.calls); err != nil {
+				return err
+			}
+			statements = append(statements, s)
+		}
+		return rows.Err()
+	})
+	if unavailable {
+		return nil, "pg_stat_statements is not installed or not readable, so no index columns can be suggested. " +
+			"Install it (CREATE EXTENSION pg_stat_statements, with the library in shared_preload_libraries) " +
+			"or check the queries that read these tables with execute_explain."
+	}
+	if err != nil {
+		return nil, fmt.Sprintf("pg_stat_statements could not be read, so no index columns can be suggested: %v", err)
+	}
+	if statements == nil {
+		statements = []statementForIndexes{}
+	}
+	return statements, ""
+}
+
+// suggestIndexesForTable ranks the column sets that statements referencing
+// the table filter or join on, by total calls, skipping any that already
+// lead an existing index
+func suggestIndexesForTable(schema, table string, columns []string, indexes [][]string, statements []statementForIndexes) []indexSuggestion {
+	tableRe := tableReferenceRe(schema, table)
+	byKey := make(map[string]*indexSuggestion)
+	for _, s := range statements {
+		key := extractPredicateColumns(s.query, tableRe, table, columns)
+		if len(key) == 0 || isCoveredByIndex(key, indexes) {
+			continue
+		}
+		id := strings.Join(key, "\x00")
+		if byKey[id] == nil {
+			byKey[id] = &indexSuggestion{columns: key}
+		}
+		byKey[id].calls += s.calls
+		byKey[id].statements++
+	}
+
+	suggestions := make([]indexSuggestion, 0, len(byKey))
+	for _, s := range byKey {
+		suggestions = append(suggestions, *s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].calls != suggestions[j].calls {
+			return suggestions[i].calls > suggestions[j].calls
+		}
+		return strings.Join(suggestions[i].columns, ",") < strings.Join(suggestions[j].columns, ",")
+	})
+	if len(suggestions) > maxSuggestionsPerTable {
+		suggestions = suggestions[:maxSuggestionsPerTable]
+	}
+	return suggestions
+}
+
+// tableReferenceRe matches schema.table (schema optional, either part
+// quoted or not) after FROM, JOIN or UPDATE, capturing a following alias
+func tableReferenceRe(schema, table string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\b(?:FROM|JOIN|UPDATE)\s+(?:(?:"` + regexp.QuoteMeta(schema) + `"|` +
+		regexp.QuoteMeta(schema) + `)\.)?(?:"` + regexp.QuoteMeta(table) + `"|` + regexp.QuoteMeta(table) +
+		`\b)(?:\s+(?:AS\s+)?([a-z_][a-z0-9_$]*))?`)
+}
+
+// extractPredicateColumns returns the columns of a table that a query
+// compares in WHERE or JOIN conditions, equality columns before range
+// columns, or nil if the query doesn't reference the table (tableRe, from
+// tableReferenceRe). Qualified references must use the table name or one of
+// its aliases.
+func extractPredicateColumns(query string, tableRe *regexp.Regexp, table string, columns []string) []string {
+	refs := tableRe.FindAllStringSubmatch(query, -1)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	qualifiers := []string{strings.ToLower(table)}
+	for _, ref := range refs {
+		if alias := strings.ToLower(ref[1]); alias != "" && !slices.Contains(aliasKeywords, alias) {
+			qualifiers = append(qualifiers, alias)
+		}
+	}
+
+	var equality, rng []string
+	add := func(qualifier, column, op string) {
+		if qualifier != "" && !slices.Contains(qualifiers, strings.ToLower(unquoteIdentifier(qualifier))) {
+			return
+		}
+		name := unquoteIdentifier(column)
+		if !slices.Contains(columns, name) || slices.Contains(equality, name) || slices.Contains(rng, name) {
+			return
+		}
+		switch op {
+		case "=", "in", "is":
+			equality = append(equality, name)
+		case "<", ">", "<=", ">=", "between", "like", "ilike":
+			rng = append(rng, name)
+		}
+	}
+
+	for _, m := range predicateRe.FindAllStringSubmatch(query, -1) {
+		op := strings.ToLower(strings.Join(strings.Fields(m[3]), " "))
+		add(m[1], m[2], op)
+		if op == "=" && m[5] != "" {
+			add(m[4], m[5], op)
+		}
+	}
+
+	key := append(equality, rng...)
+	if len(key) > maxIndexKeyColumns {
+		key = key[:maxIndexKeyColumns]
+	}
+	return key
+}
+
+// unquoteIdentifier returns the name a SQL identifier refers to: quoted
+// identifiers as written, unquoted ones folded to lower case
+func unquoteIdentifier(ident string) string {
+	if len(ident) >= 2 && strings.HasPrefix(ident, `"`) && strings.HasSuffix(ident, `"`) {
+		return strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`)
+	}
+	return strings.ToLower(ident)
+}
+
+// isCoveredByIndex reports whether an existing index starts with the same
+// columns as key, in any order, so it can already serve those conditions
+func isCoveredByIndex(key []string, indexes [][]string) bool {
+	for _, cols := range indexes {
+		if len(cols) < len(key) {
+			continue
+		}
+		covered := true
+		for _, c := range key {
+			if !slices.Contains(cols[:len(key)], c) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Suggest Indexes Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSuggestIndexesToolDefinition(t *testing.T) {
+	tool := SuggestIndexesTool(nil)
+
+	if tool.Definition.Name != "suggest_indexes" {
+		t.Errorf("Tool name = %v, want suggest_indexes", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"schema_name", "limit"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+}
+
+func TestSuggestIndexesInvalidLimit(t *testing.T) {
+	tool := SuggestIndexesTool(nil)
+
+	response, err := tool.Handler(map[string]interface{}{"limit": float64(0)})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !response.IsError || !strings.Contains(response.Content[0].Text, "limit") {
+		t.Errorf("Expected limit error, got: %+v", response)
+	}
+}
+
+func TestExtractPredicateColumns(t *testing.T) {
+	columns := []string{"id", "customer_id", "status", "created_at", "Region"}
+	tableRe := tableReferenceRe("public", "orders")
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "equality and range",
+			query: "SELECT * FROM orders WHERE created_at > $1 AND status = $2",
+			want:  []string{"status", "created_at"},
+		},
+		{
+			name:  "alias and join",
+			query: "SELECT o.id FROM public.orders o JOIN customers c ON o.customer_id = c.id WHERE c.name = $1",
+			want:  []string{"customer_id"},
+		},
+		{
+			name:  "join with the table on the right",
+			query: "SELECT * FROM customers c JOIN orders AS o ON c.id = o.customer_id",
+			want:  []string{"customer_id"},
+		},
+		{
+			name:  "quoted identifiers",
+			query: `SELECT * FROM "public"."orders" WHERE "Region" IN ($1, $2)`,
+			want:  []string{"Region"},
+		},
+		{
+			name:  "not equal and expressions are ignored",
+			query: "SELECT * FROM orders WHERE status <> $1 AND lower(status) = $2",
+			want:  nil,
+		},
+		{
+			name:  "update set clause is not a predicate",
+			query: "UPDATE orders SET status = $1 WHERE customer_id = $2",
+			want:  []string{"customer_id"},
+		},
+		{
+			name:  "other table",
+			query: "SELECT * FROM orders_archive WHERE status = $1",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractPredicateColumns(tt.query, tableRe, "orders", columns)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractPredicateColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCoveredByIndex(t *testing.T) {
+	indexes := [][]string{{"id"}, {"status", "created_at"}, {"", "customer_id"}}
+
+	tests := []struct {
+		key  []string
+		want bool
+	}{
+		{[]string{"id"}, true},
+		{[]string{"status"}, true},
+		{[]string{"created_at", "status"}, true},
+		{[]string{"created_at"}, false},
+		{[]string{"customer_id"}, false},
+		{[]string{"status", "created_at", "id"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isCoveredByIndex(tt.key, indexes); got != tt.want {
+			t.Errorf("isCoveredByIndex(%v) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestIndexesForTable(t *testing.T) {
+	columns := []string{"id", "customer_id", "status", "created_at"}
+	statements := []statementForIndexes{
+		{query: "SELECT * FROM orders WHERE customer_id = $1", calls: 500},
+		{query: "SELECT id FROM orders WHERE customer_id = $1 LIMIT $2", calls: 300},
+		{query: "SELECT * FROM orders WHERE status = $1 AND created_at >= $2", calls: 900},
+		{query: "SELECT * FROM orders WHERE id = $1", calls: 10000},
+		{query: "SELECT * FROM orders WHERE created_at < $1", calls: 50},
+	}
+
+	got := suggestIndexesForTable("public", "orders", columns, [][]string{{"id"}}, statements)
+	if len(got) != maxSuggestionsPerTable {
+		t.Fatalf("Got %d suggestions, want %d: %+v", len(got), maxSuggestionsPerTable, got)
+	}
+	if !reflect.DeepEqual(got[0].columns, []string{"status", "created_at"}) || got[0].calls != 900 {
+		t.Errorf("First suggestion = %+v, want (status, created_at) with 900 calls", got[0])
+	}
+	if !reflect.DeepEqual(got[1].columns, []string{"customer_id"}) || got[1].calls != 800 || got[1].statements != 2 {
+		t.Errorf("Second suggestion = %+v, want (customer_id) with 800 calls in 2 statements", got[1])
+	}
+}
@@ -0,0 +1,452 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Operations table_maintenance can recommend and run
+const (
+	maintenanceVacuum     = "vacuum"
+	maintenanceVacuumFull = "vacuum_full"
+	maintenanceReindex    = "reindex"
+)
+
+const (
+	// maintenanceDeadRowRatio is the share of dead to live rows above which
+	// a VACUUM is recommended
+	maintenanceDeadRowRatio = 0.2
+
+	// maintenanceMinDeadRows avoids recommending VACUUM for a handful of
+	// dead rows in a small table
+	maintenanceMinDeadRows = 1000
+
+	// maintenanceStaleAnalyzeRatio is the share of rows modified since the
+	// last ANALYZE above which the statistics are considered stale
+	maintenanceStaleAnalyzeRatio = 0.1
+)
+
+// maintenanceState is what table_maintenance knows about a table
+type maintenanceState struct {
+	liveRows, deadRows, modsSinceAnalyze int64
+	lastVacuum, lastAnalyze              *time.Time
+	pages                                int64
+	fragmentation                        float64 // -1 if unknown
+	bloatedIndexes                       []string
+}
+
+// maintenanceAdvice is one recommended operation and why
+type maintenanceAdvice struct {
+	operation string
+	reason    string
+}
+
+// TableMaintenanceTool creates the table_maintenance tool
+func TableMaintenanceTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "table_maintenance",
+			Description: `Recommend VACUUM, VACUUM FULL or REINDEX for a table and, when asked, run it.
+
+<usecase>
+Use after relation_layout or a user's report of a slow, bloated table to:
+- Decide whether a table needs maintenance at all
+- Run VACUUM (VERBOSE, ANALYZE) or REINDEX TABLE CONCURRENTLY and see the
+  server's output
+</usecase>
+
+<what_it_returns>
+- Live and dead rows, rows modified since the last ANALYZE, and when the
+  table was last vacuumed and analyzed (manually or by autovacuum)
+- Estimated table fragmentation and btree indexes with high bloat
+- The recommended operations with reasons, or that none is needed
+- With execute=true: the VERBOSE output of the operation and how long it took
+</what_it_returns>
+
+<important>
+- Without execute=true nothing is run; always show the recommendation and
+  get the user's agreement before executing
+- execute=true requires operation and a token with the 'tune' scope
+- vacuum_full rewrites the table under an ACCESS EXCLUSIVE lock, blocking
+  all reads and writes until it finishes; it also requires
+  confirm_exclusive_lock=true. Prefer pg_repack on busy tables
+- reindex uses REINDEX TABLE CONCURRENTLY (PostgreSQL 12+), which doesn't
+  block writes but takes longer. It is not subject to the query timeout,
+  since cancelling it leaves invalid "_ccnew" indexes behind; if it fails
+  anyway, the leftover indexes are listed with the statements to drop them
+- vacuum and vacuum_full are subject to the query timeout; pass
+  timeout_seconds for large tables
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"schema_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Schema of the table (default: public)",
+						"default":     "public",
+					},
+					"table_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Table to check",
+					},
+					"execute": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Run the operation instead of only recommending. Default: false",
+						"default":     false,
+					},
+					"operation": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{maintenanceVacuum, maintenanceVacuumFull, maintenanceReindex},
+						"description": "Operation to run with execute=true: 'vacuum' (VACUUM (VERBOSE, ANALYZE)), 'vacuum_full' or 'reindex' (REINDEX TABLE CONCURRENTLY)",
+					},
+					"confirm_exclusive_lock": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Must be true to run vacuum_full, acknowledging that it locks the table for the whole rewrite",
+					},
+					"timeout_seconds": timeoutSecondsProperty(dbClient),
+				},
+				Required: []string{"table_name"},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			tableName, ok := args["table_name"].(string)
+			if !ok || tableName == "" {
+				return mcp.NewToolError("Missing or invalid 'table_name' parameter")
+			}
+			schemaName := ValidateOptionalStringParam(args, "schema_name", "public")
+			execute := ValidateBoolParam(args, "execute", false)
+			operation := ValidateOptionalStringParam(args, "operation", "")
+
+			if execute {
+				switch operation {
+				case maintenanceVacuum, maintenanceReindex:
+				case maintenanceVacuumFull:
+					if !ValidateBoolParam(args, "confirm_exclusive_lock", false) {
+						return mcp.NewToolError("Refusing to run VACUUM FULL without confirm_exclusive_lock=true: it blocks all " +
+							"reads and writes on the table until the rewrite finishes. Confirm with the user first.")
+					}
+				case "":
+					return mcp.NewToolError("Parameter 'operation' is required with execute=true: vacuum, vacuum_full or reindex")
+				default:
+					return mcp.NewToolError(fmt.Sprintf("Invalid operation '%s': must be vacuum, vacuum_full or reindex", operation))
+				}
+			}
+
+			timeout, errResp := resolveQueryTimeout(dbClient, args)
+			if errResp != nil {
+				return *errResp, nil
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+
+			state, err := readMaintenanceState(ctx, dbClient, pool, schemaName, tableName)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return mcp.NewToolError(fmt.Sprintf("Table '%s.%s' not found", schemaName, tableName))
+			}
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Error reading table statistics: %v", err))
+			}
+			advice := recommendMaintenance(state, time.Now())
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(fmt.Sprintf("Table: %s.%s\n", schemaName, tableName))
+			sb.WriteString(formatMaintenanceState(state))
+
+			if len(advice) == 0 {
+				sb.WriteString("\nRecommendation: no maintenance needed.\n")
+			} else {
+				sb.WriteString("\nRecommendations:\n")
+				for _, a := range advice {
+					sb.WriteString(fmt.Sprintf("- %s: %s\n", a.operation, a.reason))
+				}
+			}
+
+			if !execute {
+				logging.Info("table_maintenance_recommended",
+					"schema", schemaName,
+					"table", tableName,
+					"recommendations", len(advice),
+				)
+				if len(advice) > 0 {
+					sb.WriteString("\nNothing was run. To run an operation, call table_maintenance again with execute=true and operation.\n")
+				}
+				return mcp.NewToolSuccess(sb.String())
+			}
+
+			sql := maintenanceSQL(operation, pgx.Identifier{schemaName, tableName}.Sanitize())
+
+			// A cancelled REINDEX CONCURRENTLY leaves invalid indexes on the
+			// table, so it runs without the query timeout
+			execCtx := ctx
+			if operation != maintenanceReindex {
+				var cancel context.CancelFunc
+				execCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			conn, err := pool.Acquire(execCtx)
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Failed to acquire connection: %v", err))
+			}
+			defer conn.Release()
+
+			// VACUUM and REINDEX CONCURRENTLY can't run in a transaction
+			// block, so this runs on its own rather than via executeReadOnly
+			stopCapture := database.CaptureNotices(conn)
+			start := time.Now()
+			_, err = conn.Exec(execCtx, sql)
+			elapsed := time.Since(start)
+			output := stopCapture()
+
+			logging.Info("table_maintenance_executed",
+				"schema", schemaName,
+				"table", tableName,
+				"operation", operation,
+				"duration_ms", elapsed.Milliseconds(),
+				"success", err == nil,
+			)
+
+			sb.WriteString(fmt.Sprintf("\nExecuted: %s\n", sql))
+			if err != nil {
+				if operation == maintenanceReindex {
+					sb.WriteString(fmt.Sprintf("\nError: %v\n", err))
+					sb.WriteString(reportReindexLeftovers(ctx, dbClient, pool, schemaName, tableName))
+					return mcp.NewToolError(sb.String())
+				}
+				if execCtx.Err() != nil {
+					return mcp.NewToolError(fmt.Sprintf("%s\n%s was cancelled after %s. Pass a larger timeout_seconds for this table.",
+						sb.String(), operation, timeout))
+				}
+				return mcp.NewToolError(fmt.Sprintf("%s\nError: %v", sb.String(), err))
+			}
+			sb.WriteString(fmt.Sprintf("Completed in %s\n", elapsed.Round(time.Millisecond)))
+			if len(output) > 0 {
+				sb.WriteString("\nOutput:\n")
+				sb.WriteString(strings.Join(output, "\n"))
+				sb.WriteString("\n")
+			}
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// readMaintenanceState reads the vacuum statistics, estimated fragmentation
+// and bloated indexes of a table. It returns pgx.ErrNoRows if the table
+// doesn't exist.
+func readMaintenanceState(ctx context.Context, dbClient *database.Client, pool *pgxpool.Pool, schemaName, tableName string) (*maintenanceState, error) {
+	state := &maintenanceState{fragmentation: -1}
+	err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+		return readMaintenanceStateTx(ctx, tx, state, schemaName, tableName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// readMaintenanceStateTx fills in state within a read-only transaction
+func readMaintenanceStateTx(ctx context.Context, tx pgx.Tx, state *maintenanceState, schemaName, tableName string) error {
+	var blockSize int64
+	var reltuples float64
+	var fillfactor, avgWidth int
+	err := tx.QueryRow(ctx, `
+		SELECT current_setting('block_size')::bigint,
+		       pg_relation_size(c.oid) / current_setting('block_size')::bigint,
+		       c.reltuples::float8,
+		       COALESCE((SELECT option_value::int FROM pg_options_to_table(c.reloptions)
+		                 WHERE option_name = 'fillfactor'), 100),
+		       COALESCE((SELECT SUM(s.avg_width)::int FROM pg_stats s
+		                 WHERE s.schemaname = n.nspname AND s.tablename = c.relname), 0),
+		       COALESCE(st.n_live_tup, 0), COALESCE(st.n_dead_tup, 0),
+		       COALESCE(st.n_mod_since_analyze, 0),
+		       GREATEST(st.last_vacuum, st.last_autovacuum),
+		       GREATEST(st.last_analyze, st.last_autoanalyze)
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_stat_user_tables st ON st.relid = c.oid
+		WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('r', 'm')`,
+		schemaName, tableName).Scan(&blockSize, &state.pages, &reltuples, &fillfactor, &avgWidth,
+		&state.liveRows, &state.deadRows, &state.modsSinceAnalyze, &state.lastVacuum, &state.lastAnalyze)
+	if err != nil {
+		return err
+	}
+
+	if expected, ok := estimateExpectedPages(reltuples, avgWidth, fillfactor, blockSize); ok {
+		state.fragmentation = estimatedFragmentation(expected, state.pages)
+	}
+
+	indexes, err := queryIndexBloat(ctx, tx, schemaName, tableName, blockSize)
+	if err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		if classifyFragmentation(idx.fragmentation, idx.pages) == layoutHigh {
+			state.bloatedIndexes = append(state.bloatedIndexes,
+				fmt.Sprintf("%s (%.0f%% of %s)", idx.index, idx.fragmentation*100, formatBytes(idx.bytes)))
+		}
+	}
+
+	return nil
+}
+
+// recommendMaintenance decides which operations a table needs, most
+// disruptive first
+func recommendMaintenance(state *maintenanceState, now time.Time) []maintenanceAdvice {
+	var advice []maintenanceAdvice
+
+	if classifyFragmentation(state.fragmentation, state.pages) == layoutHigh {
+		advice = append(advice, maintenanceAdvice{maintenanceVacuumFull, fmt.Sprintf(
+			"about %.0f%% of the table is wasted space that VACUUM can't return to the operating system; "+
+				"VACUUM FULL locks the table while it rewrites it, so consider pg_repack on a busy table",
+			state.fragmentation*100)})
+	}
+
+	if len(state.bloatedIndexes) > 0 {
+		advice = append(advice, maintenanceAdvice{maintenanceReindex, fmt.Sprintf(
+			"highly bloated index(es): %s", strings.Join(state.bloatedIndexes, ", "))})
+	}
+
+	var vacuumReasons []string
+	if state.deadRows >= maintenanceMinDeadRows && float64(state.deadRows) > maintenanceDeadRowRatio*float64(state.liveRows) {
+		vacuumReasons = append(vacuumReasons, fmt.Sprintf("%d dead rows (%.0f%% of live rows)",
+			state.deadRows, 100*float64(state.deadRows)/float64(max(state.liveRows, 1))))
+	}
+	if state.liveRows > 0 {
+		switch {
+		case state.lastAnalyze == nil:
+			vacuumReasons = append(vacuumReasons, "the table has never been analyzed")
+		case float64(state.modsSinceAnalyze) > maintenanceStaleAnalyzeRatio*float64(state.liveRows):
+			vacuumReasons = append(vacuumReasons, fmt.Sprintf("%d rows modified since the last ANALYZE %s ago",
+				state.modsSinceAnalyze, now.Sub(*state.lastAnalyze).Round(time.Minute)))
+		}
+	}
+	// VACUUM FULL also analyzes and leaves no dead rows
+	if len(vacuumReasons) > 0 && (len(advice) == 0 || advice[0].operation != maintenanceVacuumFull) {
+		advice = append(advice, maintenanceAdvice{maintenanceVacuum, strings.Join(vacuumReasons, "; ")})
+	}
+
+	return advice
+}
+
+// formatMaintenanceState lists the statistics behind the recommendation
+func formatMaintenanceState(state *maintenanceState) string {
+	never := func(t *time.Time) string {
+		if t == nil {
+			return "never"
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	fragmentation := "unknown (run ANALYZE)"
+	if state.fragmentation >= 0 {
+		fragmentation = fmt.Sprintf("%.1f%%", state.fragmentation*100)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Live rows: %d\n", state.liveRows))
+	sb.WriteString(fmt.Sprintf("Dead rows: %d\n", state.deadRows))
+	sb.WriteString(fmt.Sprintf("Modified since last ANALYZE: %d\n", state.modsSinceAnalyze))
+	sb.WriteString(fmt.Sprintf("Last vacuum: %s\n", never(state.lastVacuum)))
+	sb.WriteString(fmt.Sprintf("Last analyze: %s\n", never(state.lastAnalyze)))
+	sb.WriteString(fmt.Sprintf("Estimated fragmentation: %s\n", fragmentation))
+	return sb.String()
+}
+
+// reportReindexLeftovers describes the invalid indexes a failed REINDEX
+// CONCURRENTLY left on a table and how to drop them. It uses its own
+// timeout, since the request may have been cancelled.
+func reportReindexLeftovers(ctx context.Context, dbClient *database.Client, pool *pgxpool.Pool, schemaName, tableName string) string {
+	lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dbClient.QueryTimeout())
+	defer cancel()
+
+	leftovers, err := queryReindexLeftovers(lookupCtx, dbClient, pool, schemaName, tableName)
+	if err != nil {
+		logging.Warn("table_maintenance_leftover_check_failed", "schema", schemaName, "table", tableName, "error", err)
+		return "\nCould not check for invalid indexes left by the interrupted REINDEX. " +
+			"Look for invalid indexes ending in _ccnew on this table and drop them with DROP INDEX CONCURRENTLY.\n"
+	}
+	return formatReindexLeftovers(leftovers)
+}
+
+// queryReindexLeftovers returns the quoted names of a table's invalid
+// indexes that an interrupted REINDEX CONCURRENTLY created (suffix _ccnew)
+// or failed to drop (suffix _ccold)
+func queryReindexLeftovers(ctx context.Context, dbClient *database.Client, pool *pgxpool.Pool, schemaName, tableName string) ([]string, error) {
+	var leftovers []string
+	err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT quote_ident(ni.nspname) || '.' || quote_ident(i.relname)
+			FROM pg_index x
+			JOIN pg_class i ON i.oid = x.indexrelid
+			JOIN pg_namespace ni ON ni.oid = i.relnamespace
+			JOIN pg_class c ON c.oid = x.indrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2
+			  AND NOT x.indisvalid
+			  AND i.relname ~ '_cc(new|old)[0-9]*$'
+			ORDER BY i.relname`, schemaName, tableName)
+		if err != nil {
+			return err
+		}
+		leftovers, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		return err
+	})
+	return leftovers, err
+}
+
+// formatReindexLeftovers lists leftover invalid indexes with the statements
+// that drop them
+func formatReindexLeftovers(leftovers []string) string {
+	if len(leftovers) == 0 {
+		return "\nNo invalid indexes were left on the table.\n"
+	}
+	var sb strings.Builder
+	sb.WriteString("\nThe interrupted REINDEX left invalid indexes that take space and slow down writes. " +
+		"Drop them before retrying:\n")
+	for _, name := range leftovers {
+		sb.WriteString(fmt.Sprintf("DROP INDEX CONCURRENTLY %s;\n", name))
+	}
+	return sb.String()
+}
+
+// maintenanceSQL returns the statement for an operation on a quoted table name
+func maintenanceSQL(operation, table string) string {
+	switch operation {
+	case maintenanceVacuumFull:
+		return "VACUUM (FULL, VERBOSE, ANALYZE) " + table
+	case maintenanceReindex:
+		return "REINDEX (VERBOSE) TABLE CONCURRENTLY " + table
+	default:
+		return "VACUUM (VERBOSE, ANALYZE) " + table
+	}
+}
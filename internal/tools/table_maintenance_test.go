@@ -0,0 +1,142 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Table Maintenance Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pgedge-postgres-mcp/internal/auth"
+)
+
+func TestTableMaintenanceToolDefinition(t *testing.T) {
+	tool := TableMaintenanceTool(nil)
+
+	if tool.Definition.Name != "table_maintenance" {
+		t.Errorf("Tool name = %v, want table_maintenance", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"schema_name", "table_name", "execute", "operation", "confirm_exclusive_lock", "timeout_seconds"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+}
+
+func TestTableMaintenanceValidation(t *testing.T) {
+	tool := TableMaintenanceTool(nil)
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"missing table", map[string]interface{}{}, "table_name"},
+		{"execute without operation", map[string]interface{}{"table_name": "orders", "execute": true}, "'operation' is required"},
+		{"unknown operation", map[string]interface{}{"table_name": "orders", "execute": true, "operation": "cluster"}, "Invalid operation"},
+		{"vacuum full without confirm", map[string]interface{}{"table_name": "orders", "execute": true, "operation": "vacuum_full"}, "confirm_exclusive_lock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := tool.Handler(tt.args)
+			if err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if !response.IsError {
+				t.Fatal("Expected error response")
+			}
+			if !strings.Contains(response.Content[0].Text, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, response.Content[0].Text)
+			}
+		})
+	}
+}
+
+func TestRecommendMaintenance(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Hour)
+
+	operations := func(advice []maintenanceAdvice) string {
+		var ops []string
+		for _, a := range advice {
+			ops = append(ops, a.operation)
+		}
+		return strings.Join(ops, ",")
+	}
+
+	tests := []struct {
+		name  string
+		state maintenanceState
+		want  string
+	}{
+		{"healthy", maintenanceState{liveRows: 100000, deadRows: 500, lastAnalyze: &recent, pages: 1000, fragmentation: 0.05}, ""},
+		{"dead rows", maintenanceState{liveRows: 100000, deadRows: 30000, lastAnalyze: &recent, pages: 1000, fragmentation: 0.05}, "vacuum"},
+		{"few dead rows in a small table", maintenanceState{liveRows: 100, deadRows: 900, lastAnalyze: &recent, pages: 10, fragmentation: -1}, ""},
+		{"never analyzed", maintenanceState{liveRows: 100, pages: 10, fragmentation: -1}, "vacuum"},
+		{"stale statistics", maintenanceState{liveRows: 100000, modsSinceAnalyze: 20000, lastAnalyze: &recent, pages: 1000, fragmentation: 0.05}, "vacuum"},
+		{"fragmented", maintenanceState{liveRows: 100000, deadRows: 30000, lastAnalyze: &recent, pages: 1000, fragmentation: 0.6}, "vacuum_full"},
+		{"fragmented but small", maintenanceState{liveRows: 100, lastAnalyze: &recent, pages: 10, fragmentation: 0.6}, ""},
+		{"bloated index", maintenanceState{liveRows: 100000, lastAnalyze: &recent, pages: 1000, fragmentation: 0.05,
+			bloatedIndexes: []string{"orders_pkey (55% of 40 MB)"}}, "reindex"},
+		{"everything", maintenanceState{liveRows: 100000, deadRows: 30000, pages: 1000, fragmentation: 0.6,
+			bloatedIndexes: []string{"orders_pkey (55% of 40 MB)"}}, "vacuum_full,reindex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			advice := recommendMaintenance(&tt.state, now)
+			if got := operations(advice); got != tt.want {
+				t.Errorf("recommendMaintenance() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaintenanceSQL(t *testing.T) {
+	table := `"public"."orders"`
+	tests := map[string]string{
+		maintenanceVacuum:     `VACUUM (VERBOSE, ANALYZE) "public"."orders"`,
+		maintenanceVacuumFull: `VACUUM (FULL, VERBOSE, ANALYZE) "public"."orders"`,
+		maintenanceReindex:    `REINDEX (VERBOSE) TABLE CONCURRENTLY "public"."orders"`,
+	}
+	for op, want := range tests {
+		if got := maintenanceSQL(op, table); got != want {
+			t.Errorf("maintenanceSQL(%q) = %q, want %q", op, got, want)
+		}
+	}
+}
+
+func TestFormatReindexLeftovers(t *testing.T) {
+	if got := formatReindexLeftovers(nil); !strings.Contains(got, "No invalid indexes") {
+		t.Errorf("Expected no-leftovers message, got %q", got)
+	}
+
+	got := formatReindexLeftovers([]string{`public.orders_pkey_ccnew`, `public."Orders_idx_ccnew1"`})
+	for _, want := range []string{
+		"DROP INDEX CONCURRENTLY public.orders_pkey_ccnew;",
+		`DROP INDEX CONCURRENTLY public."Orders_idx_ccnew1";`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in %q", want, got)
+		}
+	}
+}
+
+func TestTableMaintenanceRequiredScope(t *testing.T) {
+	if got := requiredScope("table_maintenance", map[string]interface{}{"table_name": "orders"}); got != auth.ScopeRead {
+		t.Errorf("recommendation scope = %q, want %q", got, auth.ScopeRead)
+	}
+	if got := requiredScope("table_maintenance", map[string]interface{}{"execute": true, "operation": "vacuum"}); got != auth.ScopeTune {
+		t.Errorf("execute scope = %q, want %q", got, auth.ScopeTune)
+	}
+}
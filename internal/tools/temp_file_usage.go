@@ -0,0 +1,370 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// tempFileLogTailBytes is how much of the end of the current server log
+	// is scanned for log_temp_files entries
+	tempFileLogTailBytes = 1024 * 1024
+
+	// maxRecommendedWorkMem is the largest global work_mem we will suggest;
+	// beyond this, per-session or per-role settings are safer
+	maxRecommendedWorkMem = 1024 * 1024 * 1024
+)
+
+var (
+	tempFileLogRegex  = regexp.MustCompile(`temporary file: path "[^"]*", size (\d+)`)
+	statementLogRegex = regexp.MustCompile(`STATEMENT:\s+(.*)$`)
+)
+
+// tempFileCulprit aggregates temp file activity for a single statement
+type tempFileCulprit struct {
+	Statement  string
+	Files      int64
+	TotalBytes int64
+	MaxBytes   int64
+}
+
+// TempFileUsageTool creates the temp_file_usage tool for diagnosing work_mem pressure
+func TempFileUsageTool(dbClient *database.Client) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "temp_file_usage",
+			Description: `Report temp file usage per database and identify queries spilling to disk.
+
+<usecase>
+Use when:
+- Queries are slow and you suspect sorts or hashes spilling to disk
+- Tuning work_mem and you need evidence for a new value
+- Following up on advice to enable log_temp_files
+</usecase>
+
+<what_it_returns>
+- Per-database temp_files and temp_bytes from pg_stat_database
+- Current work_mem and log_temp_files settings
+- When log_temp_files is enabled and the server log is readable, the
+  statements that generated the most temp file volume recently, with
+  literals replaced by $n placeholders and long text shortened
+- A work_mem recommendation based on the observed temp file sizes
+</what_it_returns>
+
+<important>
+- Reading the server log requires superuser or pg_read_server_files; if the
+  log cannot be read, the database-level statistics are still reported
+- Only the tail of the current log file is scanned
+- Statistics are cumulative since the last stats reset
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"include_log_analysis": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Scan the server log for statements that created temp files. Default: true",
+						"default":     true,
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of culprit statements to return. Default: 10",
+						"default":     10,
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			includeLogs := true
+			if val, ok := args["include_log_analysis"].(bool); ok {
+				includeLogs = val
+			}
+
+			limit := 10
+			if val, ok := args["limit"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'limit' must be a positive integer")
+				}
+				limit = int(val)
+			}
+
+			connStr := dbClient.GetDefaultConnection()
+			if !dbClient.IsMetadataLoadedFor(connStr) {
+				return mcp.NewToolError(mcp.DatabaseNotReadyError)
+			}
+
+			pool := dbClient.GetPoolFor(connStr)
+			if pool == nil {
+				return mcp.NewToolError(fmt.Sprintf("Connection pool not found for: %s", database.SanitizeConnStr(connStr)))
+			}
+
+			ctx := requestContext(args)
+
+			var statsRows [][]interface{}
+			var workMemBytes int64
+			var workMem, logTempFiles, currentDB string
+			var dbTempFiles, dbTempBytes int64
+
+			err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+				err := tx.QueryRow(ctx, `
+					SELECT current_setting('work_mem'),
+					       (SELECT setting::bigint * 1024 FROM pg_settings WHERE name = 'work_mem'),
+					       current_setting('log_temp_files'),
+					       current_database()`).Scan(&workMem, &workMemBytes, &logTempFiles, &currentDB)
+				if err != nil {
+					return fmt.Errorf("failed to read settings: %w", err)
+				}
+
+				rows, err := tx.Query(ctx, `
+					SELECT datname, temp_files, temp_bytes, pg_size_pretty(temp_bytes),
+					       COALESCE(stats_reset::text, '')
+					FROM pg_stat_database
+					WHERE datname IS NOT NULL
+					ORDER BY temp_bytes DESC, datname`)
+				if err != nil {
+					return fmt.Errorf("failed to query pg_stat_database: %w", err)
+				}
+				defer rows.Close()
+
+				for rows.Next() {
+					var datname, prettyBytes, statsReset string
+					var files, bytes int64
+					if err := rows.Scan(&datname, &files, &bytes, &prettyBytes, &statsReset); err != nil {
+						return fmt.Errorf("failed to scan pg_stat_database row: %w", err)
+					}
+					if datname == currentDB {
+						dbTempFiles, dbTempBytes = files, bytes
+					}
+					statsRows = append(statsRows, []interface{}{datname, files, bytes, prettyBytes, statsReset})
+				}
+				return rows.Err()
+			})
+			if err != nil {
+				return mcp.NewToolError(fmt.Sprintf("Error collecting temp file statistics: %v", err))
+			}
+
+			// Log analysis is best effort and runs in its own transaction so
+			// a permission failure doesn't discard the statistics above
+			var culprits []tempFileCulprit
+			logNote := ""
+			if includeLogs {
+				if logTempFiles == "-1" {
+					logNote = "log_temp_files is disabled (-1); set it to 0 or a size threshold to capture statements that create temp files"
+				} else {
+					culprits, logNote = readTempFileCulprits(ctx, dbClient, connStr)
+				}
+			}
+
+			var maxLogged int64
+			for _, c := range culprits {
+				if c.MaxBytes > maxLogged {
+					maxLogged = c.MaxBytes
+				}
+			}
+
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Database: %s\n\n", database.SanitizeConnStr(connStr)))
+			sb.WriteString(fmt.Sprintf("work_mem: %s\nlog_temp_files: %s\n\n", workMem, logTempFiles))
+
+			sb.WriteString("Temp file usage by database (since stats reset):\n")
+			sb.WriteString(FormatResultsAsTSV(
+				[]string{"database", "temp_files", "temp_bytes", "temp_size", "stats_reset"},
+				statsRows))
+			sb.WriteString("\n\n")
+
+			if includeLogs {
+				if len(culprits) > 0 {
+					if len(culprits) > limit {
+						culprits = culprits[:limit]
+					}
+					sb.WriteString("Statements creating temp files (from server log):\n")
+					results := make([][]interface{}, 0, len(culprits))
+					for _, c := range culprits {
+						results = append(results, []interface{}{c.Files, formatBytes(c.TotalBytes), formatBytes(c.MaxBytes), c.Statement})
+					}
+					sb.WriteString(FormatResultsAsTSV([]string{"files", "total_size", "largest_file", "statement"}, results))
+					sb.WriteString("\n\n")
+				} else if logNote == "" {
+					sb.WriteString("No temp file entries found in the recent server log.\n\n")
+				}
+				if logNote != "" {
+					sb.WriteString(fmt.Sprintf("Note: %s\n\n", logNote))
+				}
+			}
+
+			sb.WriteString("Recommendation:\n")
+			sb.WriteString(recommendWorkMem(workMemBytes, dbTempFiles, dbTempBytes, maxLogged))
+			sb.WriteString("\n")
+
+			logging.Info("temp_file_usage_executed",
+				"databases", len(statsRows),
+				"include_log_analysis", includeLogs,
+				"culprits", len(culprits),
+			)
+
+			return mcp.NewToolSuccess(sb.String())
+		},
+	}
+}
+
+// readTempFileCulprits reads the tail of the current server log and extracts
+// statements that generated temp files. Returns a note explaining why no
+// culprits could be collected when the log is unavailable.
+func readTempFileCulprits(ctx context.Context, dbClient *database.Client, connStr string) ([]tempFileCulprit, string) {
+	pool := dbClient.GetPoolFor(connStr)
+
+	var logText string
+	err := executeReadOnly(ctx, dbClient, pool, func(tx pgx.Tx) error {
+		var logFile *string
+		if err := tx.QueryRow(ctx, "SELECT pg_current_logfile('stderr')").Scan(&logFile); err != nil {
+			return err
+		}
+		if logFile == nil {
+			return fmt.Errorf("no stderr log file (logging_collector is off or log_destination does not include stderr)")
+		}
+
+		var size int64
+		if err := tx.QueryRow(ctx, "SELECT size FROM pg_stat_file($1)", *logFile).Scan(&size); err != nil {
+			return err
+		}
+
+		offset := size - tempFileLogTailBytes
+		if offset < 0 {
+			offset = 0
+		}
+		return tx.QueryRow(ctx, "SELECT pg_read_file($1, $2, $3)", *logFile, offset, size-offset).Scan(&logText)
+	})
+	if err != nil {
+		return nil, fmt.Sprintf("server log could not be read: %v", err)
+	}
+
+	return parseTempFileLog(logText), ""
+}
+
+// parseTempFileLog extracts "temporary file" entries and their associated
+// STATEMENT lines from PostgreSQL stderr log output, aggregated per statement
+// and sorted by total bytes descending. The log holds statements as other
+// sessions ran them, so literals are replaced by placeholders, which also
+// groups runs of the same query, and the text is shortened.
+func parseTempFileLog(logText string) []tempFileCulprit {
+	byStatement := make(map[string]*tempFileCulprit)
+	var pending []int64
+
+	lines := strings.Split(logText, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := tempFileLogRegex.FindStringSubmatch(line); m != nil {
+			if size, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+				pending = append(pending, size)
+			}
+			continue
+		}
+
+		if len(pending) == 0 {
+			continue
+		}
+
+		m := statementLogRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		// Multi-line statements continue on lines starting with a tab
+		statement := m[1]
+		for i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") {
+			i++
+			statement += " " + strings.TrimSpace(lines[i])
+		}
+		statement = logging.NormalizeSQL(statement)
+
+		c, ok := byStatement[statement]
+		if !ok {
+			c = &tempFileCulprit{Statement: statement}
+			byStatement[statement] = c
+		}
+		for _, size := range pending {
+			c.Files++
+			c.TotalBytes += size
+			if size > c.MaxBytes {
+				c.MaxBytes = size
+			}
+		}
+		pending = nil
+	}
+
+	culprits := make([]tempFileCulprit, 0, len(byStatement))
+	for _, c := range byStatement {
+		c.Statement = truncateActivityQuery(c.Statement)
+		culprits = append(culprits, *c)
+	}
+	sort.Slice(culprits, func(i, j int) bool {
+		if culprits[i].TotalBytes != culprits[j].TotalBytes {
+			return culprits[i].TotalBytes > culprits[j].TotalBytes
+		}
+		return culprits[i].Statement < culprits[j].Statement
+	})
+
+	return culprits
+}
+
+// recommendWorkMem suggests a work_mem value from the observed temp file sizes.
+// The average temp file size is used rather than the maximum, since work_mem
+// applies per sort/hash node and a single outlier should not drive a global change.
+func recommendWorkMem(workMemBytes, tempFiles, tempBytes, maxLoggedBytes int64) string {
+	if tempFiles == 0 {
+		return fmt.Sprintf("No temp files recorded for this database; work_mem (%s) appears sufficient.", formatBytes(workMemBytes))
+	}
+
+	avg := tempBytes / tempFiles
+	target := avg
+	if target <= workMemBytes {
+		// Operations spilling in batches can produce files smaller than
+		// work_mem; a modest increase is usually enough
+		target = workMemBytes * 2
+	}
+
+	// Round up to a power of two, with a floor of 1MB
+	suggested := int64(1024 * 1024)
+	for suggested < target {
+		suggested *= 2
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("- %d temp files averaging %s each (current work_mem: %s)\n",
+		tempFiles, formatBytes(avg), formatBytes(workMemBytes)))
+	if maxLoggedBytes > 0 {
+		sb.WriteString(fmt.Sprintf("- Largest temp file in the recent log: %s\n", formatBytes(maxLoggedBytes)))
+	}
+
+	if suggested > maxRecommendedWorkMem {
+		sb.WriteString(fmt.Sprintf("- Temp files are too large to eliminate with a global work_mem; cap work_mem at %s, "+
+			"optimize the culprit queries (indexes to avoid sorts, smaller hash inputs), and use "+
+			"SET work_mem for specific sessions or roles that need more\n", formatBytes(maxRecommendedWorkMem)))
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("- Consider raising work_mem to %dMB; remember it applies per sort/hash operation, "+
+		"per connection, so check that max_connections x work_mem fits in available memory\n",
+		suggested/(1024*1024)))
+	return sb.String()
+}
@@ -0,0 +1,161 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Temp File Usage Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTempFileUsageToolDefinition(t *testing.T) {
+	tool := TempFileUsageTool(nil)
+
+	if tool.Definition.Name != "temp_file_usage" {
+		t.Errorf("Tool name = %v, want temp_file_usage", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"include_log_analysis", "limit"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+
+	if len(tool.Definition.InputSchema.Required) != 0 {
+		t.Errorf("Expected no required parameters, got %v", tool.Definition.InputSchema.Required)
+	}
+}
+
+func TestTempFileUsageInvalidLimit(t *testing.T) {
+	tool := TempFileUsageTool(nil)
+
+	response, err := tool.Handler(map[string]interface{}{"limit": float64(0)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !response.IsError {
+		t.Error("Expected error response for limit=0")
+	}
+}
+
+func TestParseTempFileLog(t *testing.T) {
+	logText := `2025-01-10 10:00:00 UTC [101] LOG:  temporary file: path "base/pgsql_tmp/pgsql_tmp101.0", size 10485760
+2025-01-10 10:00:00 UTC [101] STATEMENT:  SELECT * FROM orders
+	ORDER BY total DESC
+2025-01-10 10:00:01 UTC [102] LOG:  checkpoint starting: time
+2025-01-10 10:00:02 UTC [103] LOG:  temporary file: path "base/pgsql_tmp/pgsql_tmp103.0", size 1048576
+2025-01-10 10:00:02 UTC [103] LOG:  temporary file: path "base/pgsql_tmp/pgsql_tmp103.1", size 2097152
+2025-01-10 10:00:02 UTC [103] STATEMENT:  SELECT user_id, count(*) FROM events GROUP BY 1
+2025-01-10 10:00:03 UTC [104] LOG:  temporary file: path "base/pgsql_tmp/pgsql_tmp104.0", size 20971520
+2025-01-10 10:00:03 UTC [104] STATEMENT:  SELECT * FROM   orders ORDER BY total DESC
+`
+
+	culprits := parseTempFileLog(logText)
+	if len(culprits) != 2 {
+		t.Fatalf("Expected 2 culprits, got %d: %+v", len(culprits), culprits)
+	}
+
+	// Multi-line and whitespace variants should be normalized to one statement
+	first := culprits[0]
+	if first.Statement != "SELECT * FROM orders ORDER BY total DESC" {
+		t.Errorf("Unexpected statement: %q", first.Statement)
+	}
+	if first.Files != 2 || first.TotalBytes != 31457280 || first.MaxBytes != 20971520 {
+		t.Errorf("Unexpected aggregation: %+v", first)
+	}
+
+	second := culprits[1]
+	if second.Files != 2 || second.TotalBytes != 3145728 {
+		t.Errorf("Unexpected aggregation: %+v", second)
+	}
+}
+
+func TestParseTempFileLog_StripsLiterals(t *testing.T) {
+	logText := `2025-01-10 10:00:00 UTC [101] LOG:  temporary file: path "base/pgsql_tmp/pgsql_tmp101.0", size 1048576
+2025-01-10 10:00:00 UTC [101] STATEMENT:  SELECT * FROM users WHERE email = 'alice@example.com' ORDER BY 1
+2025-01-10 10:00:01 UTC [102] LOG:  temporary file: path "base/pgsql_tmp/pgsql_tmp102.0", size 1048576
+2025-01-10 10:00:01 UTC [102] STATEMENT:  SELECT * FROM users WHERE email = 'bob@example.com' ORDER BY 1
+2025-01-10 10:00:02 UTC [103] LOG:  temporary file: path "base/pgsql_tmp/pgsql_tmp103.0", size 1048576
+2025-01-10 10:00:02 UTC [103] STATEMENT:  SELECT ` + strings.Repeat("x, ", 100) + `y FROM wide
+`
+
+	culprits := parseTempFileLog(logText)
+	if len(culprits) != 2 {
+		t.Fatalf("Expected 2 culprits, got %d: %+v", len(culprits), culprits)
+	}
+	for _, c := range culprits {
+		if strings.Contains(c.Statement, "example.com") {
+			t.Errorf("Statement still holds a literal: %q", c.Statement)
+		}
+		if len([]rune(c.Statement)) > longRunningQueryLength+3 {
+			t.Errorf("Statement not truncated: %d characters", len([]rune(c.Statement)))
+		}
+	}
+
+	byStatement := make(map[string]tempFileCulprit)
+	for _, c := range culprits {
+		byStatement[c.Statement] = c
+	}
+	if c, ok := byStatement["SELECT * FROM users WHERE email = $1 ORDER BY $2"]; !ok || c.Files != 2 {
+		t.Errorf("Expected both users queries under one normalized statement, got %+v", culprits)
+	}
+}
+
+func TestParseTempFileLog_NoEntries(t *testing.T) {
+	culprits := parseTempFileLog("2025-01-10 10:00:01 UTC [102] STATEMENT:  SELECT 1\n")
+	if len(culprits) != 0 {
+		t.Errorf("Expected no culprits, got %+v", culprits)
+	}
+}
+
+func TestRecommendWorkMem(t *testing.T) {
+	const mb = 1024 * 1024
+
+	tests := []struct {
+		name     string
+		workMem  int64
+		files    int64
+		bytes    int64
+		contains string
+	}{
+		{"no temp files", 4 * mb, 0, 0, "appears sufficient"},
+		{"average above work_mem", 4 * mb, 10, 200 * mb, "raising work_mem to 32MB"},
+		{"average below work_mem doubles", 4 * mb, 100, 100 * mb, "raising work_mem to 8MB"},
+		{"too large for global setting", 4 * mb, 1, 4096 * mb, "too large to eliminate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := recommendWorkMem(tt.workMem, tt.files, tt.bytes, 0)
+			if !strings.Contains(result, tt.contains) {
+				t.Errorf("Expected recommendation to contain %q, got %q", tt.contains, result)
+			}
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0 bytes"},
+		{512, "512 bytes"},
+		{2048, "2.0 kB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.input); got != tt.expected {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/database"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+const (
+	// testConnectionDefaultTimeout bounds the connect and ping when the
+	// caller doesn't pass timeout_seconds
+	testConnectionDefaultTimeout = 5 * time.Second
+	testConnectionMaxTimeout     = 30 * time.Second
+)
+
+// TestConnectionTool creates the test_connection tool, which checks that a
+// configured database (or a connection string) is reachable without
+// changing the session's current database
+func TestConnectionTool(clientManager *database.ClientManager, accessChecker *auth.DatabaseAccessChecker) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "test_connection",
+			Description: `Check that a database is reachable: connect, ping and report the server version and latency.
+
+<usecase>
+Use when:
+- A database can't be selected or queries fail with connection errors
+- Checking a configured database before switching to it
+- Verifying a connection string works
+</usecase>
+
+<what_it_returns>
+- Success or the connection error
+- PostgreSQL server version, connected user and database
+- Whether the server is a primary or a standby
+- Connect time and ping round-trip latency
+</what_it_returns>
+
+<important>
+- Opens a separate, short-lived connection; the current database and its
+  connection pool are not changed
+- Pass either database (a configured database name) or connection_string,
+  not both
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"database": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of a configured database to test",
+					},
+					"connection_string": map[string]interface{}{
+						"type":        "string",
+						"description": "PostgreSQL connection string to test, e.g. postgres://user@host:5432/db",
+					},
+					"timeout_seconds": map[string]interface{}{
+						"type":        "number",
+						"description": "Give up after this many seconds (default: 5, maximum: 30)",
+						"minimum":     1,
+						"maximum":     30,
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			name := strings.TrimSpace(ValidateOptionalStringParam(args, "database", ""))
+			connStr := strings.TrimSpace(ValidateOptionalStringParam(args, "connection_string", ""))
+			if name == "" && connStr == "" {
+				return mcp.NewToolError("Either 'database' or 'connection_string' is required")
+			}
+			if name != "" && connStr != "" {
+				return mcp.NewToolError("Pass either 'database' or 'connection_string', not both")
+			}
+
+			timeout := testConnectionDefaultTimeout
+			if val, ok := args["timeout_seconds"].(float64); ok {
+				if val < 1 {
+					return mcp.NewToolError("Parameter 'timeout_seconds' must be at least 1")
+				}
+				timeout = time.Duration(val * float64(time.Second))
+				if timeout > testConnectionMaxTimeout {
+					timeout = testConnectionMaxTimeout
+				}
+			}
+
+			ctx := requestContext(args)
+
+			var dbConfig *config.NamedDatabaseConfig
+			target := database.SanitizeConnStr(connStr)
+			if name != "" {
+				dbConfig = accessibleDatabaseConfig(ctx, clientManager, accessChecker, name)
+				if dbConfig == nil {
+					return mcp.NewToolError(fmt.Sprintf("Database '%s' not found. Configured databases: %s",
+						name, strings.Join(accessibleDatabaseNames(ctx, clientManager, accessChecker), ", ")))
+				}
+				connStr = dbConfig.BuildConnectionString()
+				target = fmt.Sprintf("%s (%s)", name, database.SanitizeConnStr(connStr))
+			}
+
+			pingCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			result, err := database.Ping(pingCtx, connStr, dbConfig)
+			if err != nil {
+				logging.Info("test_connection_executed", "target", target, "success", false)
+				if pingCtx.Err() == context.DeadlineExceeded {
+					return mcp.NewToolError(fmt.Sprintf("Connection test failed: %s\n\nNo response within %s", target, timeout))
+				}
+				return mcp.NewToolError(fmt.Sprintf("Connection test failed: %s\n\n%v", target, err))
+			}
+
+			logging.Info("test_connection_executed",
+				"target", target,
+				"success", true,
+				"connect_ms", result.ConnectTime.Milliseconds(),
+				"round_trip_ms", result.RoundTripTime.Milliseconds(),
+			)
+
+			return mcp.NewToolSuccess(formatPingResult(target, result))
+		},
+	}
+}
+
+// accessibleDatabaseConfig returns the named database's configuration, or
+// nil if it doesn't exist or the caller can't use it
+func accessibleDatabaseConfig(ctx context.Context, clientManager *database.ClientManager, accessChecker *auth.DatabaseAccessChecker, name string) *config.NamedDatabaseConfig {
+	if clientManager == nil {
+		return nil
+	}
+	cfg := clientManager.GetDatabaseConfig(name)
+	if cfg == nil || accessChecker == nil {
+		return cfg
+	}
+	for _, accessible := range accessChecker.GetAccessibleDatabases(ctx, []config.NamedDatabaseConfig{*cfg}) {
+		if accessible.Name == name {
+			return cfg
+		}
+	}
+	return nil
+}
+
+// accessibleDatabaseNames lists the configured databases the caller can
+// use, sorted
+func accessibleDatabaseNames(ctx context.Context, clientManager *database.ClientManager, accessChecker *auth.DatabaseAccessChecker) []string {
+	if clientManager == nil {
+		return nil
+	}
+	configs := clientManager.GetDatabaseConfigs()
+	if accessChecker != nil {
+		configs = accessChecker.GetAccessibleDatabases(ctx, configs)
+	}
+	names := make([]string, 0, len(configs))
+	for i := range configs {
+		names = append(names, configs[i].Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatPingResult describes a successful connection test
+func formatPingResult(target string, result *database.PingResult) string {
+	role := "primary"
+	if result.InRecoveryMode {
+		role = "standby"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Connection test succeeded: %s\n\n", target))
+	sb.WriteString(fmt.Sprintf("Server version: PostgreSQL %s (%s)\n", result.ServerVersion, role))
+	sb.WriteString(fmt.Sprintf("Connected as: %s to database %s\n", result.CurrentUser, result.CurrentDB))
+	sb.WriteString(fmt.Sprintf("Connect time: %s\n", formatLatency(result.ConnectTime)))
+	sb.WriteString(fmt.Sprintf("Round-trip latency: %s", formatLatency(result.RoundTripTime)))
+	return sb.String()
+}
+
+// formatLatency shows a duration in milliseconds with sub-millisecond
+// precision, since local round trips are often well under 1ms
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.2f ms", float64(d.Microseconds())/1000)
+}
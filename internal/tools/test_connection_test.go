@@ -0,0 +1,97 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Test Connection Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pgedge-postgres-mcp/internal/config"
+	"pgedge-postgres-mcp/internal/database"
+)
+
+func TestTestConnectionToolDefinition(t *testing.T) {
+	tool := TestConnectionTool(nil, nil)
+
+	if tool.Definition.Name != "test_connection" {
+		t.Errorf("Tool name = %v, want test_connection", tool.Definition.Name)
+	}
+
+	for _, prop := range []string{"database", "connection_string", "timeout_seconds"} {
+		if _, exists := tool.Definition.InputSchema.Properties[prop]; !exists {
+			t.Errorf("Missing property: %s", prop)
+		}
+	}
+
+	if len(tool.Definition.InputSchema.Required) != 0 {
+		t.Errorf("Required = %v, want none", tool.Definition.InputSchema.Required)
+	}
+}
+
+func TestTestConnectionToolValidation(t *testing.T) {
+	clientManager := database.NewClientManager([]config.NamedDatabaseConfig{
+		{Name: "prod", Host: "db.example.com", Port: 5432, Database: "app", User: "app"},
+		{Name: "analytics", Host: "olap.example.com", Port: 5432, Database: "dw", User: "report"},
+	})
+	tool := TestConnectionTool(clientManager, nil)
+
+	for _, tc := range []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{}, "Either 'database' or 'connection_string' is required"},
+		{map[string]interface{}{"database": "  "}, "Either 'database' or 'connection_string' is required"},
+		{map[string]interface{}{"database": "prod", "connection_string": "postgres://localhost/app"}, "not both"},
+		{map[string]interface{}{"database": "prod", "timeout_seconds": float64(0)}, "'timeout_seconds' must be at least 1"},
+		{map[string]interface{}{"database": "staging"}, "Database 'staging' not found. Configured databases: analytics, prod"},
+	} {
+		response, err := tool.Handler(tc.args)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if !response.IsError {
+			t.Errorf("Expected error response for %v", tc.args)
+			continue
+		}
+		if text := response.Content[0].Text; !strings.Contains(text, tc.want) {
+			t.Errorf("Response for %v = %q, want it to contain %q", tc.args, text, tc.want)
+		}
+	}
+}
+
+func TestFormatPingResult(t *testing.T) {
+	result := &database.PingResult{
+		ServerVersion: "17.2",
+		ConnectTime:   12500 * time.Microsecond,
+		RoundTripTime: 340 * time.Microsecond,
+		CurrentUser:   "app",
+		CurrentDB:     "appdb",
+	}
+
+	out := formatPingResult("prod (postgres://app@db.example.com:5432/appdb)", result)
+	for _, want := range []string{
+		"Connection test succeeded: prod (postgres://app@db.example.com:5432/appdb)\n",
+		"Server version: PostgreSQL 17.2 (primary)\n",
+		"Connected as: app to database appdb\n",
+		"Connect time: 12.50 ms\n",
+		"Round-trip latency: 0.34 ms",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+
+	result.InRecoveryMode = true
+	if out := formatPingResult("prod", result); !strings.Contains(out, "(standby)") {
+		t.Errorf("Expected standby role:\n%s", out)
+	}
+}
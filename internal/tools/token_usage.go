@@ -0,0 +1,120 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/logging"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// TokenUsageTool creates the token_usage admin tool
+func TokenUsageTool(tokenStore *auth.TokenStore, usageStore *auth.UsageStore) Tool {
+	return Tool{
+		Definition: mcp.Tool{
+			Name: "token_usage",
+			Description: `Show which tools each API token has called. Admin tool for security review.
+
+<usecase>
+Use when:
+- Reviewing whether tokens are used for their intended purpose
+- Finding tokens that repeatedly call tools they are refused
+- Identifying tokens that are no longer used
+</usecase>
+
+<what_it_returns>
+Per token: ID, annotation, total tool calls, refused calls, the last tool
+called and when, and the most called tools. Tokens with no recorded calls
+are listed last.
+</what_it_returns>
+
+<important>
+- Counts are kept since the usage file was created and are written to disk
+  in batches, so the last few seconds of calls may be missing after a crash
+- Token secrets and full hashes are never shown
+</important>`,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"token_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Only show this token",
+					},
+				},
+			},
+		},
+		Handler: func(args map[string]interface{}) (mcp.ToolResponse, error) {
+			tokenID := ValidateOptionalStringParam(args, "token_id", "")
+
+			if tokenStore == nil || usageStore == nil {
+				return mcp.NewToolError("Token usage is only recorded when HTTP authentication is enabled")
+			}
+
+			usageByID := usageStore.UsageByTokenID(tokenStore)
+			tokens := tokenStore.ListTokens()
+
+			// Most active tokens first, then unused tokens by ID
+			sort.Slice(tokens, func(i, j int) bool {
+				ui, uj := usageByID[tokens[i].ID], usageByID[tokens[j].ID]
+				switch {
+				case ui != nil && uj != nil:
+					return ui.LastCallAt.After(uj.LastCallAt)
+				case ui != nil || uj != nil:
+					return ui != nil
+				default:
+					return tokens[i].ID < tokens[j].ID
+				}
+			})
+
+			var results [][]interface{}
+			for _, token := range tokens {
+				if tokenID != "" && token.ID != tokenID {
+					continue
+				}
+
+				row := []interface{}{token.ID, token.Annotation, int64(0), int64(0), "", "", ""}
+				if usage := usageByID[token.ID]; usage != nil {
+					var top []string
+					for _, name := range usage.TopTools(3) {
+						top = append(top, fmt.Sprintf("%s=%d", name, usage.ToolCalls[name]))
+					}
+					row = []interface{}{
+						token.ID, token.Annotation, usage.TotalCalls(), usage.Denied,
+						usage.LastTool, usage.LastCallAt.Format(time.RFC3339), strings.Join(top, ", "),
+					}
+				}
+				results = append(results, row)
+			}
+
+			if tokenID != "" && len(results) == 0 {
+				return mcp.NewToolError(fmt.Sprintf("Token not found: %s", tokenID))
+			}
+
+			logging.Info("token_usage_executed",
+				"token_id", tokenID,
+				"tokens", len(results),
+			)
+
+			if len(results) == 0 {
+				return mcp.NewToolSuccess("No API tokens found.\n")
+			}
+
+			return mcp.NewToolSuccess("Tool calls per API token:\n" + FormatResultsAsTSV(
+				[]string{"id", "annotation", "calls", "denied", "last_tool", "last_call", "top_tools"},
+				results) + "\n")
+		},
+	}
+}
@@ -0,0 +1,84 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent - Token Usage Tool Tests
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"pgedge-postgres-mcp/internal/auth"
+)
+
+func TestTokenUsageTool(t *testing.T) {
+	tokenStore := auth.InitializeTokenStore()
+	if err := tokenStore.AddToken("token-1", auth.HashToken("secret-1"), "reader", nil, "", nil); err != nil {
+		t.Fatalf("AddToken failed: %v", err)
+	}
+	if err := tokenStore.AddToken("token-2", auth.HashToken("secret-2"), "unused", nil, "", nil); err != nil {
+		t.Fatalf("AddToken failed: %v", err)
+	}
+
+	usageStore, err := auth.LoadUsageStore("")
+	if err != nil {
+		t.Fatalf("LoadUsageStore failed: %v", err)
+	}
+	usageStore.RecordCall(auth.HashToken("secret-1"), "query_database", false)
+	usageStore.RecordCall(auth.HashToken("secret-1"), "query_database", false)
+	usageStore.RecordCall(auth.HashToken("secret-1"), "token_usage", true)
+
+	tool := TokenUsageTool(tokenStore, usageStore)
+
+	t.Run("lists all tokens, used first", func(t *testing.T) {
+		response, err := tool.Handler(map[string]interface{}{})
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.IsError {
+			t.Fatalf("Unexpected error response: %s", response.Content[0].Text)
+		}
+
+		text := response.Content[0].Text
+		if !strings.Contains(text, "token-1\treader\t3\t1\ttoken_usage\t") {
+			t.Errorf("Expected usage row for token-1, got:\n%s", text)
+		}
+		if !strings.Contains(text, "query_database=2") {
+			t.Errorf("Expected top tools for token-1, got:\n%s", text)
+		}
+		if strings.Index(text, "token-1") > strings.Index(text, "token-2") {
+			t.Errorf("Expected used token first, got:\n%s", text)
+		}
+		if strings.Contains(text, auth.HashToken("secret-1")) {
+			t.Error("Output must not contain token hashes")
+		}
+	})
+
+	t.Run("filters by token_id", func(t *testing.T) {
+		response, _ := tool.Handler(map[string]interface{}{"token_id": "token-2"})
+		text := response.Content[0].Text
+		if response.IsError || strings.Contains(text, "token-1") || !strings.Contains(text, "token-2") {
+			t.Errorf("Unexpected output for token_id filter:\n%s", text)
+		}
+	})
+
+	t.Run("unknown token_id", func(t *testing.T) {
+		response, _ := tool.Handler(map[string]interface{}{"token_id": "token-9"})
+		if !response.IsError {
+			t.Error("Expected error response for unknown token")
+		}
+	})
+
+	t.Run("without auth", func(t *testing.T) {
+		response, _ := TokenUsageTool(nil, nil).Handler(map[string]interface{}{})
+		if !response.IsError {
+			t.Error("Expected error response without token and usage stores")
+		}
+	})
+}
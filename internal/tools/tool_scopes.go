@@ -0,0 +1,58 @@
+/*-------------------------------------------------------------------------
+ *
+ * pgEdge Natural Language Agent
+ *
+ * Portions copyright (c) 2025, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package tools
+
+import (
+	"fmt"
+
+	"pgedge-postgres-mcp/internal/auth"
+	"pgedge-postgres-mcp/internal/mcp"
+)
+
+// adminTools are the tools that expose other tokens and need the admin scope
+var adminTools = map[string]bool{
+	"token_usage": true,
+}
+
+// requiredScope returns the token scope needed to call a tool with the
+// given arguments. Anything that changes the server or other sessions
+// needs tune; everything else only reads.
+func requiredScope(name string, args map[string]interface{}) string {
+	if adminTools[name] {
+		return auth.ScopeAdmin
+	}
+
+	if name == "long_running_queries" {
+		action, _ := args["action"].(string) //nolint:errcheck // missing or non-string means list
+		if action == longRunningActionCancel || action == longRunningActionTerminate {
+			return auth.ScopeTune
+		}
+	}
+
+	if name == "table_maintenance" && ValidateBoolParam(args, "execute", false) {
+		return auth.ScopeTune
+	}
+
+	return auth.ScopeRead
+}
+
+// permissionDenied builds the error returned when a token lacks a scope
+func permissionDenied(name, scope string) mcp.ToolResponse {
+	return mcp.ToolResponse{
+		Content: []mcp.ContentItem{
+			{
+				Type: "text",
+				Text: fmt.Sprintf("Permission denied: '%s' requires the '%s' scope, which this token does not have. Ask an administrator for a token with that scope.", name, scope),
+			},
+		},
+		IsError: true,
+	}
+}
@@ -11,15 +11,34 @@
 package tsv
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // FormatValue converts a value to a TSV-safe string.
 // Handles NULLs, special characters, and complex types.
 func FormatValue(v interface{}) string {
+	s := StringValue(v)
+
+	// Escape special characters that would break TSV parsing
+	// Replace tabs with \t and newlines with \n (literal backslash sequences)
+	s = strings.ReplaceAll(s, "\t", "\\t")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	s = strings.ReplaceAll(s, "\r", "\\r")
+
+	return s
+}
+
+// StringValue converts a value to its unescaped string form. NULLs become
+// empty strings and complex types are serialized as JSON.
+func StringValue(v interface{}) string {
+	v = Normalize(v)
 	if v == nil {
 		return "" // NULL represented as empty string
 	}
@@ -28,8 +47,6 @@ func FormatValue(v interface{}) string {
 	switch val := v.(type) {
 	case string:
 		s = val
-	case []byte:
-		s = string(val)
 	case time.Time:
 		s = val.Format(time.RFC3339)
 	case bool:
@@ -55,12 +72,111 @@ func FormatValue(v interface{}) string {
 		s = fmt.Sprintf("%v", val)
 	}
 
-	// Escape special characters that would break TSV parsing
-	// Replace tabs with \t and newlines with \n (literal backslash sequences)
-	s = strings.ReplaceAll(s, "\t", "\\t")
-	s = strings.ReplaceAll(s, "\n", "\\n")
-	s = strings.ReplaceAll(s, "\r", "\\r")
+	return s
+}
+
+// Normalize converts the values pgx returns for types that have no natural
+// Go or JSON form into strings that read the way PostgreSQL prints them:
+//
+//   - numeric: the exact decimal, e.g. "1234.50", or "NaN"/"Infinity"
+//   - interval: ISO 8601, e.g. "P1Y2M3DT4H5M6.5S"
+//   - time: "15:04:05", with microseconds when present
+//   - bytea: hex with a \x prefix, e.g. "\x4869"
+//   - uuid: the canonical 8-4-4-4-12 form
+//
+// Arrays and JSON objects are normalized element by element, so they can be
+// serialized as JSON. NULLs of these types become nil; any other value is
+// returned unchanged.
+func Normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case pgtype.Numeric:
+		if !val.Valid {
+			return nil
+		}
+		text, err := val.Value()
+		if err != nil || text == nil {
+			return nil
+		}
+		return text
+	case pgtype.Interval:
+		if !val.Valid {
+			return nil
+		}
+		return formatInterval(val)
+	case pgtype.Time:
+		if !val.Valid {
+			return nil
+		}
+		return formatTimeOfDay(val.Microseconds)
+	case []byte:
+		return `\x` + hex.EncodeToString(val)
+	case [16]byte:
+		return fmt.Sprintf("%x-%x-%x-%x-%x", val[0:4], val[4:6], val[6:8], val[8:10], val[10:16])
+	case []interface{}:
+		normalized := make([]interface{}, len(val))
+		for i, elem := range val {
+			normalized[i] = Normalize(elem)
+		}
+		return normalized
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(val))
+		for k, elem := range val {
+			normalized[k] = Normalize(elem)
+		}
+		return normalized
+	default:
+		return v
+	}
+}
 
+// formatInterval renders an interval the way PostgreSQL does with
+// IntervalStyle iso_8601: each non-zero field with its own sign, and PT0S
+// for an empty interval
+func formatInterval(iv pgtype.Interval) string {
+	var sb strings.Builder
+	sb.WriteString("P")
+	if years := iv.Months / 12; years != 0 {
+		sb.WriteString(fmt.Sprintf("%dY", years))
+	}
+	if months := iv.Months % 12; months != 0 {
+		sb.WriteString(fmt.Sprintf("%dM", months))
+	}
+	if iv.Days != 0 {
+		sb.WriteString(fmt.Sprintf("%dD", iv.Days))
+	}
+
+	us := iv.Microseconds
+	hours := us / int64(time.Hour/time.Microsecond)
+	us -= hours * int64(time.Hour/time.Microsecond)
+	minutes := us / int64(time.Minute/time.Microsecond)
+	us -= minutes * int64(time.Minute/time.Microsecond)
+	if hours != 0 || minutes != 0 || us != 0 {
+		sb.WriteString("T")
+		if hours != 0 {
+			sb.WriteString(fmt.Sprintf("%dH", hours))
+		}
+		if minutes != 0 {
+			sb.WriteString(fmt.Sprintf("%dM", minutes))
+		}
+		if us != 0 {
+			sb.WriteString(strconv.FormatFloat(float64(us)/1e6, 'f', -1, 64) + "S")
+		}
+	}
+
+	if sb.Len() == 1 {
+		return "PT0S"
+	}
+	return sb.String()
+}
+
+// formatTimeOfDay renders microseconds since midnight as hh:mm:ss, adding
+// the fractional seconds only when there are any
+func formatTimeOfDay(us int64) string {
+	seconds := us / 1e6
+	s := fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	if frac := us % 1e6; frac != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%06d", frac), "0")
+	}
 	return s
 }
 
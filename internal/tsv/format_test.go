@@ -13,6 +13,8 @@ package tsv
 import (
 	"testing"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func TestFormatValue(t *testing.T) {
@@ -33,7 +35,7 @@ func TestFormatValue(t *testing.T) {
 		{"float64", 3.14159, "3.14159"},
 		{"bool true", true, "true"},
 		{"bool false", false, "false"},
-		{"byte slice", []byte("bytes"), "bytes"},
+		{"byte slice is bytea hex", []byte("bytes"), `\x6279746573`},
 		{"array", []interface{}{"a", "b"}, `["a","b"]`},
 		{"map", map[string]interface{}{"key": "value"}, `{"key":"value"}`},
 	}
@@ -88,3 +90,55 @@ func TestBuildRow(t *testing.T) {
 		t.Errorf("BuildRow() = %q, want %q", result, expected)
 	}
 }
+
+func TestNormalize(t *testing.T) {
+	numeric := func(s string) pgtype.Numeric {
+		var n pgtype.Numeric
+		if err := n.Scan(s); err != nil {
+			t.Fatalf("Scan(%q): %v", s, err)
+		}
+		return n
+	}
+
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected interface{}
+	}{
+		{"numeric keeps scale", numeric("1234.50"), "1234.50"},
+		{"large numeric", numeric("123456789012345678901234.000001"), "123456789012345678901234.000001"},
+		{"negative numeric", numeric("-0.005"), "-0.005"},
+		{"numeric NaN", numeric("NaN"), "NaN"},
+		{"NULL numeric", pgtype.Numeric{}, nil},
+		{"interval", pgtype.Interval{Months: 14, Days: 3, Microseconds: 4*3600e6 + 5*60e6 + 6.5e6, Valid: true}, "P1Y2M3DT4H5M6.5S"},
+		{"days only", pgtype.Interval{Days: 7, Valid: true}, "P7D"},
+		{"negative time", pgtype.Interval{Microseconds: -90 * 60e6, Valid: true}, "PT-1H-30M"},
+		{"empty interval", pgtype.Interval{Valid: true}, "PT0S"},
+		{"NULL interval", pgtype.Interval{}, nil},
+		{"time", pgtype.Time{Microseconds: 13*3600e6 + 5*60e6 + 9e6, Valid: true}, "13:05:09"},
+		{"time with fraction", pgtype.Time{Microseconds: 9e6 + 250000, Valid: true}, "00:00:09.25"},
+		{"bytea", []byte{0xde, 0xad, 0x00}, `\xdead00`},
+		{"uuid", [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}, "123e4567-e89b-12d3-a456-426614174000"},
+		{"other values unchanged", int64(42), int64(42)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.input); got != tt.expected {
+				t.Errorf("Normalize(%v) = %#v, want %#v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatValue_NormalizedArray(t *testing.T) {
+	var price pgtype.Numeric
+	if err := price.Scan("19.90"); err != nil {
+		t.Fatal(err)
+	}
+	arr := []interface{}{price, nil, pgtype.Interval{Days: 1, Valid: true}}
+
+	if got, want := FormatValue(arr), `["19.90",null,"P1D"]`; got != want {
+		t.Errorf("FormatValue(array) = %q, want %q", got, want)
+	}
+}
@@ -492,9 +492,9 @@ func testHTTPListTools(t *testing.T, server *HTTPMCPServer) {
 		t.Fatal("tools array not found in result")
 	}
 
-	// We now have 7 tools (removed connection management tools, added execute_explain and count_rows)
-	if len(tools) != 7 {
-		t.Errorf("Expected exactly 7 tools, got %d", len(tools))
+	// We now have 29 tools (removed connection management tools, added diagnostic tools)
+	if len(tools) != 29 {
+		t.Errorf("Expected exactly 29 tools, got %d", len(tools))
 	}
 
 	t.Logf("HTTP ListTools test passed, found %d tools", len(tools))
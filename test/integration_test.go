@@ -348,20 +348,42 @@ func testListTools(t *testing.T, server *MCPServer) {
 		t.Fatal("tools array not found in result")
 	}
 
-	// With database connected at startup, all 7 tools should be available
-	if len(tools) != 7 {
-		t.Errorf("Expected exactly 7 tools with database connection, got %d", len(tools))
+	// With database connected at startup, all 29 tools should be available
+	if len(tools) != 29 {
+		t.Errorf("Expected exactly 29 tools with database connection, got %d", len(tools))
 	}
 
 	// Verify expected tools exist
 	expectedTools := map[string]bool{
-		"query_database":     false,
-		"get_schema_info":    false,
-		"similarity_search":  false,
-		"read_resource":      false,
-		"generate_embedding": false,
-		"execute_explain":    false,
-		"count_rows":         false,
+		"query_database":          false,
+		"get_schema_info":         false,
+		"similarity_search":       false,
+		"read_resource":           false,
+		"generate_embedding":      false,
+		"execute_explain":         false,
+		"count_rows":              false,
+		"temp_file_usage":         false,
+		"check_vector_indexes":    false,
+		"lock_wait_graph":         false,
+		"index_efficiency":        false,
+		"find_invalid_indexes":    false,
+		"get_table_sample":        false,
+		"backup_readiness":        false,
+		"describe_schema":         false,
+		"relation_layout":         false,
+		"partitioning_advisor":    false,
+		"find_large_values":       false,
+		"long_running_queries":    false,
+		"rowcount_accuracy":       false,
+		"suggest_indexes":         false,
+		"refresh_metadata":        false,
+		"table_maintenance":       false,
+		"describe_table":          false,
+		"test_connection":         false,
+		"show_current_connection": false,
+		"export_schema":           false,
+		"list_databases":          false,
+		"list_roles":              false,
 	}
 
 	for _, tool := range tools {